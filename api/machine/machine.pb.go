@@ -9,6 +9,7 @@ import (
 	math "math"
 
 	proto "github.com/golang/protobuf/proto"
+	duration "github.com/golang/protobuf/ptypes/duration"
 	empty "github.com/golang/protobuf/ptypes/empty"
 	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	grpc "google.golang.org/grpc"
@@ -29,6 +30,180 @@ var (
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type SequenceEventType int32
+
+const (
+	SequenceEventType_SEQUENCE_STARTED  SequenceEventType = 0
+	SequenceEventType_PHASE_STARTED     SequenceEventType = 1
+	SequenceEventType_TASK_STARTED      SequenceEventType = 2
+	SequenceEventType_TASK_FINISHED     SequenceEventType = 3
+	SequenceEventType_SEQUENCE_FINISHED SequenceEventType = 4
+	SequenceEventType_TASK_PROGRESS     SequenceEventType = 5
+	SequenceEventType_TASK_MESSAGE      SequenceEventType = 6
+)
+
+var SequenceEventType_name = map[int32]string{
+	0: "SEQUENCE_STARTED",
+	1: "PHASE_STARTED",
+	2: "TASK_STARTED",
+	3: "TASK_FINISHED",
+	4: "SEQUENCE_FINISHED",
+	5: "TASK_PROGRESS",
+	6: "TASK_MESSAGE",
+}
+
+var SequenceEventType_value = map[string]int32{
+	"SEQUENCE_STARTED":  0,
+	"PHASE_STARTED":     1,
+	"TASK_STARTED":      2,
+	"TASK_FINISHED":     3,
+	"SEQUENCE_FINISHED": 4,
+	"TASK_PROGRESS":     5,
+	"TASK_MESSAGE":      6,
+}
+
+func (x SequenceEventType) String() string {
+	return proto.EnumName(SequenceEventType_name, int32(x))
+}
+
+func (SequenceEventType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{0}
+}
+
+// rpc stepsequence
+// Controls the single-step mode, in which the sequences pause before each of
+// their phases until they are stepped. Enabling it requires debug to be
+// enabled in the config.
+type StepSequenceAction int32
+
+const (
+	// Resumes the sequence paused before a phase.
+	StepSequenceAction_STEP_NEXT StepSequenceAction = 0
+	// Enables the mode.
+	StepSequenceAction_STEP_ENABLE StepSequenceAction = 1
+	// Disables the mode, and resumes the paused sequences.
+	StepSequenceAction_STEP_DISABLE StepSequenceAction = 2
+)
+
+var StepSequenceAction_name = map[int32]string{
+	0: "STEP_NEXT",
+	1: "STEP_ENABLE",
+	2: "STEP_DISABLE",
+}
+
+var StepSequenceAction_value = map[string]int32{
+	"STEP_NEXT":    0,
+	"STEP_ENABLE":  1,
+	"STEP_DISABLE": 2,
+}
+
+func (x StepSequenceAction) String() string {
+	return proto.EnumName(StepSequenceAction_name, int32(x))
+}
+
+func (StepSequenceAction) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{1}
+}
+
+// rpc abortsequence
+// Aborts the running sequence. Only the install, upgrade, reset, rollback,
+// recover, and noop sequences can be aborted, until they reach their
+// point of no return, e.g. once a reset stops the services.
+type AbortSequence struct {
+	Metadata *common.Metadata `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// The sequence that was aborted.
+	Sequence             string   `protobuf:"bytes,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AbortSequence) Reset()         { *m = AbortSequence{} }
+func (m *AbortSequence) String() string { return proto.CompactTextString(m) }
+func (*AbortSequence) ProtoMessage()    {}
+func (*AbortSequence) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{0}
+}
+
+func (m *AbortSequence) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AbortSequence.Unmarshal(m, b)
+}
+
+func (m *AbortSequence) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AbortSequence.Marshal(b, m, deterministic)
+}
+
+func (m *AbortSequence) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AbortSequence.Merge(m, src)
+}
+
+func (m *AbortSequence) XXX_Size() int {
+	return xxx_messageInfo_AbortSequence.Size(m)
+}
+
+func (m *AbortSequence) XXX_DiscardUnknown() {
+	xxx_messageInfo_AbortSequence.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AbortSequence proto.InternalMessageInfo
+
+func (m *AbortSequence) GetMetadata() *common.Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *AbortSequence) GetSequence() string {
+	if m != nil {
+		return m.Sequence
+	}
+	return ""
+}
+
+type AbortSequenceResponse struct {
+	Messages             []*AbortSequence `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *AbortSequenceResponse) Reset()         { *m = AbortSequenceResponse{} }
+func (m *AbortSequenceResponse) String() string { return proto.CompactTextString(m) }
+func (*AbortSequenceResponse) ProtoMessage()    {}
+func (*AbortSequenceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{1}
+}
+
+func (m *AbortSequenceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AbortSequenceResponse.Unmarshal(m, b)
+}
+
+func (m *AbortSequenceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AbortSequenceResponse.Marshal(b, m, deterministic)
+}
+
+func (m *AbortSequenceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AbortSequenceResponse.Merge(m, src)
+}
+
+func (m *AbortSequenceResponse) XXX_Size() int {
+	return xxx_messageInfo_AbortSequenceResponse.Size(m)
+}
+
+func (m *AbortSequenceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AbortSequenceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AbortSequenceResponse proto.InternalMessageInfo
+
+func (m *AbortSequenceResponse) GetMessages() []*AbortSequence {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
 // rpc reboot
 // The reboot message containing the reboot status.
 type Reboot struct {
@@ -42,7 +217,7 @@ func (m *Reboot) Reset()         { *m = Reboot{} }
 func (m *Reboot) String() string { return proto.CompactTextString(m) }
 func (*Reboot) ProtoMessage()    {}
 func (*Reboot) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{0}
+	return fileDescriptor_84b4f59d98cc997c, []int{2}
 }
 
 func (m *Reboot) XXX_Unmarshal(b []byte) error {
@@ -85,7 +260,7 @@ func (m *RebootResponse) Reset()         { *m = RebootResponse{} }
 func (m *RebootResponse) String() string { return proto.CompactTextString(m) }
 func (*RebootResponse) ProtoMessage()    {}
 func (*RebootResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{1}
+	return fileDescriptor_84b4f59d98cc997c, []int{3}
 }
 
 func (m *RebootResponse) XXX_Unmarshal(b []byte) error {
@@ -119,8 +294,12 @@ func (m *RebootResponse) GetMessages() []*Reboot {
 
 // rpc reset
 type ResetRequest struct {
-	Graceful             bool     `protobuf:"varint,1,opt,name=graceful,proto3" json:"graceful,omitempty"`
-	Reboot               bool     `protobuf:"varint,2,opt,name=reboot,proto3" json:"reboot,omitempty"`
+	Graceful bool `protobuf:"varint,1,opt,name=graceful,proto3" json:"graceful,omitempty"`
+	Reboot   bool `protobuf:"varint,2,opt,name=reboot,proto3" json:"reboot,omitempty"`
+	// The partitions of the system disk to preserve, by label or by mount
+	// point, e.g. /boot. The other partitions are wiped, and formatted anew.
+	// Without partitions to preserve, the whole disk is wiped.
+	Preserve             []string `protobuf:"bytes,3,rep,name=preserve,proto3" json:"preserve,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -130,7 +309,7 @@ func (m *ResetRequest) Reset()         { *m = ResetRequest{} }
 func (m *ResetRequest) String() string { return proto.CompactTextString(m) }
 func (*ResetRequest) ProtoMessage()    {}
 func (*ResetRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{2}
+	return fileDescriptor_84b4f59d98cc997c, []int{4}
 }
 
 func (m *ResetRequest) XXX_Unmarshal(b []byte) error {
@@ -169,6 +348,13 @@ func (m *ResetRequest) GetReboot() bool {
 	return false
 }
 
+func (m *ResetRequest) GetPreserve() []string {
+	if m != nil {
+		return m.Preserve
+	}
+	return nil
+}
+
 // The reset message containing the restart status.
 type Reset struct {
 	Metadata             *common.Metadata `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
@@ -181,7 +367,7 @@ func (m *Reset) Reset()         { *m = Reset{} }
 func (m *Reset) String() string { return proto.CompactTextString(m) }
 func (*Reset) ProtoMessage()    {}
 func (*Reset) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{3}
+	return fileDescriptor_84b4f59d98cc997c, []int{5}
 }
 
 func (m *Reset) XXX_Unmarshal(b []byte) error {
@@ -224,7 +410,7 @@ func (m *ResetResponse) Reset()         { *m = ResetResponse{} }
 func (m *ResetResponse) String() string { return proto.CompactTextString(m) }
 func (*ResetResponse) ProtoMessage()    {}
 func (*ResetResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{4}
+	return fileDescriptor_84b4f59d98cc997c, []int{6}
 }
 
 func (m *ResetResponse) XXX_Unmarshal(b []byte) error {
@@ -256,144 +442,1635 @@ func (m *ResetResponse) GetMessages() []*Reset {
 	return nil
 }
 
-// rpc shutdown
-// The messages message containing the shutdown status.
-type Shutdown struct {
+// rpc runphase
+// Runs a single phase of a sequence in isolation. This is a debugging aid that
+// requires debug to be enabled in the machine config, and it can leave the
+// machine in an inconsistent state.
+type RunPhaseRequest struct {
+	Sequence string `protobuf:"bytes,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// The phase number (starting at 1) or the name of a task in the phase.
+	Phase                string   `protobuf:"bytes,2,opt,name=phase,proto3" json:"phase,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RunPhaseRequest) Reset()         { *m = RunPhaseRequest{} }
+func (m *RunPhaseRequest) String() string { return proto.CompactTextString(m) }
+func (*RunPhaseRequest) ProtoMessage()    {}
+func (*RunPhaseRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{7}
+}
+
+func (m *RunPhaseRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunPhaseRequest.Unmarshal(m, b)
+}
+
+func (m *RunPhaseRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunPhaseRequest.Marshal(b, m, deterministic)
+}
+
+func (m *RunPhaseRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunPhaseRequest.Merge(m, src)
+}
+
+func (m *RunPhaseRequest) XXX_Size() int {
+	return xxx_messageInfo_RunPhaseRequest.Size(m)
+}
+
+func (m *RunPhaseRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunPhaseRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunPhaseRequest proto.InternalMessageInfo
+
+func (m *RunPhaseRequest) GetSequence() string {
+	if m != nil {
+		return m.Sequence
+	}
+	return ""
+}
+
+func (m *RunPhaseRequest) GetPhase() string {
+	if m != nil {
+		return m.Phase
+	}
+	return ""
+}
+
+type RunPhase struct {
 	Metadata             *common.Metadata `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
 	XXX_unrecognized     []byte           `json:"-"`
 	XXX_sizecache        int32            `json:"-"`
 }
 
-func (m *Shutdown) Reset()         { *m = Shutdown{} }
-func (m *Shutdown) String() string { return proto.CompactTextString(m) }
-func (*Shutdown) ProtoMessage()    {}
-func (*Shutdown) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{5}
+func (m *RunPhase) Reset()         { *m = RunPhase{} }
+func (m *RunPhase) String() string { return proto.CompactTextString(m) }
+func (*RunPhase) ProtoMessage()    {}
+func (*RunPhase) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{8}
 }
 
-func (m *Shutdown) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Shutdown.Unmarshal(m, b)
+func (m *RunPhase) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunPhase.Unmarshal(m, b)
 }
 
-func (m *Shutdown) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Shutdown.Marshal(b, m, deterministic)
+func (m *RunPhase) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunPhase.Marshal(b, m, deterministic)
 }
 
-func (m *Shutdown) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Shutdown.Merge(m, src)
+func (m *RunPhase) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunPhase.Merge(m, src)
 }
 
-func (m *Shutdown) XXX_Size() int {
-	return xxx_messageInfo_Shutdown.Size(m)
+func (m *RunPhase) XXX_Size() int {
+	return xxx_messageInfo_RunPhase.Size(m)
 }
 
-func (m *Shutdown) XXX_DiscardUnknown() {
-	xxx_messageInfo_Shutdown.DiscardUnknown(m)
+func (m *RunPhase) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunPhase.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Shutdown proto.InternalMessageInfo
+var xxx_messageInfo_RunPhase proto.InternalMessageInfo
 
-func (m *Shutdown) GetMetadata() *common.Metadata {
+func (m *RunPhase) GetMetadata() *common.Metadata {
 	if m != nil {
 		return m.Metadata
 	}
 	return nil
 }
 
-type ShutdownResponse struct {
-	Messages             []*Shutdown `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+type RunPhaseResponse struct {
+	Messages             []*RunPhase `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
 	XXX_unrecognized     []byte      `json:"-"`
 	XXX_sizecache        int32       `json:"-"`
 }
 
-func (m *ShutdownResponse) Reset()         { *m = ShutdownResponse{} }
-func (m *ShutdownResponse) String() string { return proto.CompactTextString(m) }
-func (*ShutdownResponse) ProtoMessage()    {}
-func (*ShutdownResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{6}
+func (m *RunPhaseResponse) Reset()         { *m = RunPhaseResponse{} }
+func (m *RunPhaseResponse) String() string { return proto.CompactTextString(m) }
+func (*RunPhaseResponse) ProtoMessage()    {}
+func (*RunPhaseResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{9}
 }
 
-func (m *ShutdownResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ShutdownResponse.Unmarshal(m, b)
+func (m *RunPhaseResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunPhaseResponse.Unmarshal(m, b)
 }
 
-func (m *ShutdownResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ShutdownResponse.Marshal(b, m, deterministic)
+func (m *RunPhaseResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunPhaseResponse.Marshal(b, m, deterministic)
 }
 
-func (m *ShutdownResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ShutdownResponse.Merge(m, src)
+func (m *RunPhaseResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunPhaseResponse.Merge(m, src)
 }
 
-func (m *ShutdownResponse) XXX_Size() int {
-	return xxx_messageInfo_ShutdownResponse.Size(m)
+func (m *RunPhaseResponse) XXX_Size() int {
+	return xxx_messageInfo_RunPhaseResponse.Size(m)
 }
 
-func (m *ShutdownResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_ShutdownResponse.DiscardUnknown(m)
+func (m *RunPhaseResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunPhaseResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ShutdownResponse proto.InternalMessageInfo
+var xxx_messageInfo_RunPhaseResponse proto.InternalMessageInfo
 
-func (m *ShutdownResponse) GetMessages() []*Shutdown {
+func (m *RunPhaseResponse) GetMessages() []*RunPhase {
 	if m != nil {
 		return m.Messages
 	}
 	return nil
 }
 
-// rpc upgrade
-type UpgradeRequest struct {
-	Image                string   `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
-	Preserve             bool     `protobuf:"varint,2,opt,name=preserve,proto3" json:"preserve,omitempty"`
+// rpc runsequence
+// Runs a sequence by name: shutdown, reboot, reset, upgrade, rollback,
+// maintenance, or resume, which takes the machine out of maintenance. The
+// install and recover sequences only run at startup. At most one of the
+// params can be set, which must be those of the sequence, and they are
+// required for the reset and upgrade sequences.
+type RunSequenceRequest struct {
+	Sequence             string          `protobuf:"bytes,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Reset_               *ResetRequest   `protobuf:"bytes,2,opt,name=reset,proto3" json:"reset,omitempty"`
+	Upgrade              *UpgradeRequest `protobuf:"bytes,3,opt,name=upgrade,proto3" json:"upgrade,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *RunSequenceRequest) Reset()         { *m = RunSequenceRequest{} }
+func (m *RunSequenceRequest) String() string { return proto.CompactTextString(m) }
+func (*RunSequenceRequest) ProtoMessage()    {}
+func (*RunSequenceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{10}
+}
+
+func (m *RunSequenceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunSequenceRequest.Unmarshal(m, b)
+}
+
+func (m *RunSequenceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunSequenceRequest.Marshal(b, m, deterministic)
+}
+
+func (m *RunSequenceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunSequenceRequest.Merge(m, src)
+}
+
+func (m *RunSequenceRequest) XXX_Size() int {
+	return xxx_messageInfo_RunSequenceRequest.Size(m)
+}
+
+func (m *RunSequenceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunSequenceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunSequenceRequest proto.InternalMessageInfo
+
+func (m *RunSequenceRequest) GetSequence() string {
+	if m != nil {
+		return m.Sequence
+	}
+	return ""
+}
+
+func (m *RunSequenceRequest) GetReset_() *ResetRequest {
+	if m != nil {
+		return m.Reset_
+	}
+	return nil
+}
+
+func (m *RunSequenceRequest) GetUpgrade() *UpgradeRequest {
+	if m != nil {
+		return m.Upgrade
+	}
+	return nil
+}
+
+type RunSequence struct {
+	Metadata *common.Metadata `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// The sequence that was started.
+	Sequence             string   `protobuf:"bytes,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *UpgradeRequest) Reset()         { *m = UpgradeRequest{} }
-func (m *UpgradeRequest) String() string { return proto.CompactTextString(m) }
-func (*UpgradeRequest) ProtoMessage()    {}
-func (*UpgradeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{7}
+func (m *RunSequence) Reset()         { *m = RunSequence{} }
+func (m *RunSequence) String() string { return proto.CompactTextString(m) }
+func (*RunSequence) ProtoMessage()    {}
+func (*RunSequence) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{11}
 }
 
-func (m *UpgradeRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_UpgradeRequest.Unmarshal(m, b)
+func (m *RunSequence) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunSequence.Unmarshal(m, b)
 }
 
-func (m *UpgradeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_UpgradeRequest.Marshal(b, m, deterministic)
+func (m *RunSequence) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunSequence.Marshal(b, m, deterministic)
 }
 
-func (m *UpgradeRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_UpgradeRequest.Merge(m, src)
+func (m *RunSequence) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunSequence.Merge(m, src)
 }
 
-func (m *UpgradeRequest) XXX_Size() int {
-	return xxx_messageInfo_UpgradeRequest.Size(m)
+func (m *RunSequence) XXX_Size() int {
+	return xxx_messageInfo_RunSequence.Size(m)
 }
 
-func (m *UpgradeRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_UpgradeRequest.DiscardUnknown(m)
+func (m *RunSequence) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunSequence.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_UpgradeRequest proto.InternalMessageInfo
+var xxx_messageInfo_RunSequence proto.InternalMessageInfo
 
-func (m *UpgradeRequest) GetImage() string {
+func (m *RunSequence) GetMetadata() *common.Metadata {
 	if m != nil {
-		return m.Image
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *RunSequence) GetSequence() string {
+	if m != nil {
+		return m.Sequence
 	}
 	return ""
 }
 
-func (m *UpgradeRequest) GetPreserve() bool {
+type RunSequenceResponse struct {
+	Messages             []*RunSequence `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *RunSequenceResponse) Reset()         { *m = RunSequenceResponse{} }
+func (m *RunSequenceResponse) String() string { return proto.CompactTextString(m) }
+func (*RunSequenceResponse) ProtoMessage()    {}
+func (*RunSequenceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{12}
+}
+
+func (m *RunSequenceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunSequenceResponse.Unmarshal(m, b)
+}
+
+func (m *RunSequenceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunSequenceResponse.Marshal(b, m, deterministic)
+}
+
+func (m *RunSequenceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunSequenceResponse.Merge(m, src)
+}
+
+func (m *RunSequenceResponse) XXX_Size() int {
+	return xxx_messageInfo_RunSequenceResponse.Size(m)
+}
+
+func (m *RunSequenceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunSequenceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunSequenceResponse proto.InternalMessageInfo
+
+func (m *RunSequenceResponse) GetMessages() []*RunSequence {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+// rpc preflight
+// Runs the pre-flight checks of a sequence, without running the sequence. The
+// sequence is requested as with RunSequence.
+type PreflightCheck struct {
+	Name     string             `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Duration *duration.Duration `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	// The reason the check failed, empty if it passed.
+	Error                string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PreflightCheck) Reset()         { *m = PreflightCheck{} }
+func (m *PreflightCheck) String() string { return proto.CompactTextString(m) }
+func (*PreflightCheck) ProtoMessage()    {}
+func (*PreflightCheck) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{13}
+}
+
+func (m *PreflightCheck) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PreflightCheck.Unmarshal(m, b)
+}
+
+func (m *PreflightCheck) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PreflightCheck.Marshal(b, m, deterministic)
+}
+
+func (m *PreflightCheck) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PreflightCheck.Merge(m, src)
+}
+
+func (m *PreflightCheck) XXX_Size() int {
+	return xxx_messageInfo_PreflightCheck.Size(m)
+}
+
+func (m *PreflightCheck) XXX_DiscardUnknown() {
+	xxx_messageInfo_PreflightCheck.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PreflightCheck proto.InternalMessageInfo
+
+func (m *PreflightCheck) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *PreflightCheck) GetDuration() *duration.Duration {
+	if m != nil {
+		return m.Duration
+	}
+	return nil
+}
+
+func (m *PreflightCheck) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type Preflight struct {
+	Metadata *common.Metadata  `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Sequence string            `protobuf:"bytes,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Checks   []*PreflightCheck `protobuf:"bytes,3,rep,name=checks,proto3" json:"checks,omitempty"`
+	// Whether all of the checks passed.
+	Ready                bool     `protobuf:"varint,4,opt,name=ready,proto3" json:"ready,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Preflight) Reset()         { *m = Preflight{} }
+func (m *Preflight) String() string { return proto.CompactTextString(m) }
+func (*Preflight) ProtoMessage()    {}
+func (*Preflight) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{14}
+}
+
+func (m *Preflight) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Preflight.Unmarshal(m, b)
+}
+
+func (m *Preflight) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Preflight.Marshal(b, m, deterministic)
+}
+
+func (m *Preflight) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Preflight.Merge(m, src)
+}
+
+func (m *Preflight) XXX_Size() int {
+	return xxx_messageInfo_Preflight.Size(m)
+}
+
+func (m *Preflight) XXX_DiscardUnknown() {
+	xxx_messageInfo_Preflight.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Preflight proto.InternalMessageInfo
+
+func (m *Preflight) GetMetadata() *common.Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *Preflight) GetSequence() string {
+	if m != nil {
+		return m.Sequence
+	}
+	return ""
+}
+
+func (m *Preflight) GetChecks() []*PreflightCheck {
+	if m != nil {
+		return m.Checks
+	}
+	return nil
+}
+
+func (m *Preflight) GetReady() bool {
+	if m != nil {
+		return m.Ready
+	}
+	return false
+}
+
+type PreflightResponse struct {
+	Messages             []*Preflight `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *PreflightResponse) Reset()         { *m = PreflightResponse{} }
+func (m *PreflightResponse) String() string { return proto.CompactTextString(m) }
+func (*PreflightResponse) ProtoMessage()    {}
+func (*PreflightResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{15}
+}
+
+func (m *PreflightResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PreflightResponse.Unmarshal(m, b)
+}
+
+func (m *PreflightResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PreflightResponse.Marshal(b, m, deterministic)
+}
+
+func (m *PreflightResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PreflightResponse.Merge(m, src)
+}
+
+func (m *PreflightResponse) XXX_Size() int {
+	return xxx_messageInfo_PreflightResponse.Size(m)
+}
+
+func (m *PreflightResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PreflightResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PreflightResponse proto.InternalMessageInfo
+
+func (m *PreflightResponse) GetMessages() []*Preflight {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+// rpc sequenceresult
+// The timing breakdown of the most recent sequence. The start times of the
+// phases and tasks are relative to the start of the sequence.
+type TaskResult struct {
+	Name     string             `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Start    *duration.Duration `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	Duration *duration.Duration `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"`
+	Error    string             `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	// The reason the task completed degraded, e.g. the services that did not
+	// become healthy in time.
+	Degraded             string   `protobuf:"bytes,5,opt,name=degraded,proto3" json:"degraded,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TaskResult) Reset()         { *m = TaskResult{} }
+func (m *TaskResult) String() string { return proto.CompactTextString(m) }
+func (*TaskResult) ProtoMessage()    {}
+func (*TaskResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{16}
+}
+
+func (m *TaskResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TaskResult.Unmarshal(m, b)
+}
+
+func (m *TaskResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TaskResult.Marshal(b, m, deterministic)
+}
+
+func (m *TaskResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TaskResult.Merge(m, src)
+}
+
+func (m *TaskResult) XXX_Size() int {
+	return xxx_messageInfo_TaskResult.Size(m)
+}
+
+func (m *TaskResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_TaskResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TaskResult proto.InternalMessageInfo
+
+func (m *TaskResult) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *TaskResult) GetStart() *duration.Duration {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *TaskResult) GetDuration() *duration.Duration {
+	if m != nil {
+		return m.Duration
+	}
+	return nil
+}
+
+func (m *TaskResult) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *TaskResult) GetDegraded() string {
+	if m != nil {
+		return m.Degraded
+	}
+	return ""
+}
+
+type PhaseResult struct {
+	Start                *duration.Duration `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	Duration             *duration.Duration `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	Tasks                []*TaskResult      `protobuf:"bytes,3,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *PhaseResult) Reset()         { *m = PhaseResult{} }
+func (m *PhaseResult) String() string { return proto.CompactTextString(m) }
+func (*PhaseResult) ProtoMessage()    {}
+func (*PhaseResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{17}
+}
+
+func (m *PhaseResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PhaseResult.Unmarshal(m, b)
+}
+
+func (m *PhaseResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PhaseResult.Marshal(b, m, deterministic)
+}
+
+func (m *PhaseResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PhaseResult.Merge(m, src)
+}
+
+func (m *PhaseResult) XXX_Size() int {
+	return xxx_messageInfo_PhaseResult.Size(m)
+}
+
+func (m *PhaseResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_PhaseResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PhaseResult proto.InternalMessageInfo
+
+func (m *PhaseResult) GetStart() *duration.Duration {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *PhaseResult) GetDuration() *duration.Duration {
+	if m != nil {
+		return m.Duration
+	}
+	return nil
+}
+
+func (m *PhaseResult) GetTasks() []*TaskResult {
+	if m != nil {
+		return m.Tasks
+	}
+	return nil
+}
+
+type SequenceResult struct {
+	Metadata             *common.Metadata     `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Sequence             string               `protobuf:"bytes,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Start                *timestamp.Timestamp `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
+	Duration             *duration.Duration   `protobuf:"bytes,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	Error                string               `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Phases               []*PhaseResult       `protobuf:"bytes,6,rep,name=phases,proto3" json:"phases,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *SequenceResult) Reset()         { *m = SequenceResult{} }
+func (m *SequenceResult) String() string { return proto.CompactTextString(m) }
+func (*SequenceResult) ProtoMessage()    {}
+func (*SequenceResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{18}
+}
+
+func (m *SequenceResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SequenceResult.Unmarshal(m, b)
+}
+
+func (m *SequenceResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SequenceResult.Marshal(b, m, deterministic)
+}
+
+func (m *SequenceResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SequenceResult.Merge(m, src)
+}
+
+func (m *SequenceResult) XXX_Size() int {
+	return xxx_messageInfo_SequenceResult.Size(m)
+}
+
+func (m *SequenceResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_SequenceResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SequenceResult proto.InternalMessageInfo
+
+func (m *SequenceResult) GetMetadata() *common.Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *SequenceResult) GetSequence() string {
+	if m != nil {
+		return m.Sequence
+	}
+	return ""
+}
+
+func (m *SequenceResult) GetStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *SequenceResult) GetDuration() *duration.Duration {
+	if m != nil {
+		return m.Duration
+	}
+	return nil
+}
+
+func (m *SequenceResult) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *SequenceResult) GetPhases() []*PhaseResult {
+	if m != nil {
+		return m.Phases
+	}
+	return nil
+}
+
+type SequenceResultResponse struct {
+	Messages             []*SequenceResult `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *SequenceResultResponse) Reset()         { *m = SequenceResultResponse{} }
+func (m *SequenceResultResponse) String() string { return proto.CompactTextString(m) }
+func (*SequenceResultResponse) ProtoMessage()    {}
+func (*SequenceResultResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{19}
+}
+
+func (m *SequenceResultResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SequenceResultResponse.Unmarshal(m, b)
+}
+
+func (m *SequenceResultResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SequenceResultResponse.Marshal(b, m, deterministic)
+}
+
+func (m *SequenceResultResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SequenceResultResponse.Merge(m, src)
+}
+
+func (m *SequenceResultResponse) XXX_Size() int {
+	return xxx_messageInfo_SequenceResultResponse.Size(m)
+}
+
+func (m *SequenceResultResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SequenceResultResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SequenceResultResponse proto.InternalMessageInfo
+
+func (m *SequenceResultResponse) GetMessages() []*SequenceResult {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+// rpc sequencehistory
+// The results of the most recent sequences, the oldest first.
+type SequenceHistory struct {
+	Metadata             *common.Metadata  `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Results              []*SequenceResult `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *SequenceHistory) Reset()         { *m = SequenceHistory{} }
+func (m *SequenceHistory) String() string { return proto.CompactTextString(m) }
+func (*SequenceHistory) ProtoMessage()    {}
+func (*SequenceHistory) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{20}
+}
+
+func (m *SequenceHistory) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SequenceHistory.Unmarshal(m, b)
+}
+
+func (m *SequenceHistory) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SequenceHistory.Marshal(b, m, deterministic)
+}
+
+func (m *SequenceHistory) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SequenceHistory.Merge(m, src)
+}
+
+func (m *SequenceHistory) XXX_Size() int {
+	return xxx_messageInfo_SequenceHistory.Size(m)
+}
+
+func (m *SequenceHistory) XXX_DiscardUnknown() {
+	xxx_messageInfo_SequenceHistory.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SequenceHistory proto.InternalMessageInfo
+
+func (m *SequenceHistory) GetMetadata() *common.Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *SequenceHistory) GetResults() []*SequenceResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type SequenceHistoryResponse struct {
+	Messages             []*SequenceHistory `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *SequenceHistoryResponse) Reset()         { *m = SequenceHistoryResponse{} }
+func (m *SequenceHistoryResponse) String() string { return proto.CompactTextString(m) }
+func (*SequenceHistoryResponse) ProtoMessage()    {}
+func (*SequenceHistoryResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{21}
+}
+
+func (m *SequenceHistoryResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SequenceHistoryResponse.Unmarshal(m, b)
+}
+
+func (m *SequenceHistoryResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SequenceHistoryResponse.Marshal(b, m, deterministic)
+}
+
+func (m *SequenceHistoryResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SequenceHistoryResponse.Merge(m, src)
+}
+
+func (m *SequenceHistoryResponse) XXX_Size() int {
+	return xxx_messageInfo_SequenceHistoryResponse.Size(m)
+}
+
+func (m *SequenceHistoryResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SequenceHistoryResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SequenceHistoryResponse proto.InternalMessageInfo
+
+func (m *SequenceHistoryResponse) GetMessages() []*SequenceHistory {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+// rpc eventsstream
+// Streams the lifecycle events of the sequences run by the machine, starting
+// with the events published after the request.
+type EventsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EventsRequest) Reset()         { *m = EventsRequest{} }
+func (m *EventsRequest) String() string { return proto.CompactTextString(m) }
+func (*EventsRequest) ProtoMessage()    {}
+func (*EventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{22}
+}
+
+func (m *EventsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventsRequest.Unmarshal(m, b)
+}
+
+func (m *EventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventsRequest.Marshal(b, m, deterministic)
+}
+
+func (m *EventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventsRequest.Merge(m, src)
+}
+
+func (m *EventsRequest) XXX_Size() int {
+	return xxx_messageInfo_EventsRequest.Size(m)
+}
+
+func (m *EventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventsRequest proto.InternalMessageInfo
+
+// The progress of a long running task. The total is zero if it is not known.
+type TaskProgress struct {
+	Current              int64    `protobuf:"varint,1,opt,name=current,proto3" json:"current,omitempty"`
+	Total                int64    `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Unit                 string   `protobuf:"bytes,3,opt,name=unit,proto3" json:"unit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TaskProgress) Reset()         { *m = TaskProgress{} }
+func (m *TaskProgress) String() string { return proto.CompactTextString(m) }
+func (*TaskProgress) ProtoMessage()    {}
+func (*TaskProgress) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{23}
+}
+
+func (m *TaskProgress) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TaskProgress.Unmarshal(m, b)
+}
+
+func (m *TaskProgress) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TaskProgress.Marshal(b, m, deterministic)
+}
+
+func (m *TaskProgress) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TaskProgress.Merge(m, src)
+}
+
+func (m *TaskProgress) XXX_Size() int {
+	return xxx_messageInfo_TaskProgress.Size(m)
+}
+
+func (m *TaskProgress) XXX_DiscardUnknown() {
+	xxx_messageInfo_TaskProgress.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TaskProgress proto.InternalMessageInfo
+
+func (m *TaskProgress) GetCurrent() int64 {
+	if m != nil {
+		return m.Current
+	}
+	return 0
+}
+
+func (m *TaskProgress) GetTotal() int64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *TaskProgress) GetUnit() string {
+	if m != nil {
+		return m.Unit
+	}
+	return ""
+}
+
+// Phase and task numbers start at 1. The elapsed time is only set for the
+// finished events, the progress for the progress events, and the message for
+// the message events.
+type SequenceEvent struct {
+	Metadata             *common.Metadata   `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Type                 SequenceEventType  `protobuf:"varint,2,opt,name=type,proto3,enum=machine.SequenceEventType" json:"type,omitempty"`
+	Sequence             string             `protobuf:"bytes,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Phase                int32              `protobuf:"varint,4,opt,name=phase,proto3" json:"phase,omitempty"`
+	PhaseTotal           int32              `protobuf:"varint,5,opt,name=phase_total,json=phaseTotal,proto3" json:"phase_total,omitempty"`
+	Task                 int32              `protobuf:"varint,6,opt,name=task,proto3" json:"task,omitempty"`
+	TaskTotal            int32              `protobuf:"varint,7,opt,name=task_total,json=taskTotal,proto3" json:"task_total,omitempty"`
+	TaskName             string             `protobuf:"bytes,8,opt,name=task_name,json=taskName,proto3" json:"task_name,omitempty"`
+	Elapsed              *duration.Duration `protobuf:"bytes,9,opt,name=elapsed,proto3" json:"elapsed,omitempty"`
+	Error                string             `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	Progress             *TaskProgress      `protobuf:"bytes,11,opt,name=progress,proto3" json:"progress,omitempty"`
+	Message              string             `protobuf:"bytes,12,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *SequenceEvent) Reset()         { *m = SequenceEvent{} }
+func (m *SequenceEvent) String() string { return proto.CompactTextString(m) }
+func (*SequenceEvent) ProtoMessage()    {}
+func (*SequenceEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{24}
+}
+
+func (m *SequenceEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SequenceEvent.Unmarshal(m, b)
+}
+
+func (m *SequenceEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SequenceEvent.Marshal(b, m, deterministic)
+}
+
+func (m *SequenceEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SequenceEvent.Merge(m, src)
+}
+
+func (m *SequenceEvent) XXX_Size() int {
+	return xxx_messageInfo_SequenceEvent.Size(m)
+}
+
+func (m *SequenceEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_SequenceEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SequenceEvent proto.InternalMessageInfo
+
+func (m *SequenceEvent) GetMetadata() *common.Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *SequenceEvent) GetType() SequenceEventType {
+	if m != nil {
+		return m.Type
+	}
+	return SequenceEventType_SEQUENCE_STARTED
+}
+
+func (m *SequenceEvent) GetSequence() string {
+	if m != nil {
+		return m.Sequence
+	}
+	return ""
+}
+
+func (m *SequenceEvent) GetPhase() int32 {
+	if m != nil {
+		return m.Phase
+	}
+	return 0
+}
+
+func (m *SequenceEvent) GetPhaseTotal() int32 {
+	if m != nil {
+		return m.PhaseTotal
+	}
+	return 0
+}
+
+func (m *SequenceEvent) GetTask() int32 {
+	if m != nil {
+		return m.Task
+	}
+	return 0
+}
+
+func (m *SequenceEvent) GetTaskTotal() int32 {
+	if m != nil {
+		return m.TaskTotal
+	}
+	return 0
+}
+
+func (m *SequenceEvent) GetTaskName() string {
+	if m != nil {
+		return m.TaskName
+	}
+	return ""
+}
+
+func (m *SequenceEvent) GetElapsed() *duration.Duration {
+	if m != nil {
+		return m.Elapsed
+	}
+	return nil
+}
+
+func (m *SequenceEvent) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *SequenceEvent) GetProgress() *TaskProgress {
+	if m != nil {
+		return m.Progress
+	}
+	return nil
+}
+
+func (m *SequenceEvent) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+// rpc shutdown
+// The messages message containing the shutdown status.
+type Shutdown struct {
+	Metadata             *common.Metadata `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *Shutdown) Reset()         { *m = Shutdown{} }
+func (m *Shutdown) String() string { return proto.CompactTextString(m) }
+func (*Shutdown) ProtoMessage()    {}
+func (*Shutdown) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{25}
+}
+
+func (m *Shutdown) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Shutdown.Unmarshal(m, b)
+}
+
+func (m *Shutdown) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Shutdown.Marshal(b, m, deterministic)
+}
+
+func (m *Shutdown) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Shutdown.Merge(m, src)
+}
+
+func (m *Shutdown) XXX_Size() int {
+	return xxx_messageInfo_Shutdown.Size(m)
+}
+
+func (m *Shutdown) XXX_DiscardUnknown() {
+	xxx_messageInfo_Shutdown.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Shutdown proto.InternalMessageInfo
+
+func (m *Shutdown) GetMetadata() *common.Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+type ShutdownResponse struct {
+	Messages             []*Shutdown `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ShutdownResponse) Reset()         { *m = ShutdownResponse{} }
+func (m *ShutdownResponse) String() string { return proto.CompactTextString(m) }
+func (*ShutdownResponse) ProtoMessage()    {}
+func (*ShutdownResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{26}
+}
+
+func (m *ShutdownResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShutdownResponse.Unmarshal(m, b)
+}
+
+func (m *ShutdownResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShutdownResponse.Marshal(b, m, deterministic)
+}
+
+func (m *ShutdownResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShutdownResponse.Merge(m, src)
+}
+
+func (m *ShutdownResponse) XXX_Size() int {
+	return xxx_messageInfo_ShutdownResponse.Size(m)
+}
+
+func (m *ShutdownResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShutdownResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShutdownResponse proto.InternalMessageInfo
+
+func (m *ShutdownResponse) GetMessages() []*Shutdown {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+type StepSequenceRequest struct {
+	Action               StepSequenceAction `protobuf:"varint,1,opt,name=action,proto3,enum=machine.StepSequenceAction" json:"action,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *StepSequenceRequest) Reset()         { *m = StepSequenceRequest{} }
+func (m *StepSequenceRequest) String() string { return proto.CompactTextString(m) }
+func (*StepSequenceRequest) ProtoMessage()    {}
+func (*StepSequenceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{27}
+}
+
+func (m *StepSequenceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StepSequenceRequest.Unmarshal(m, b)
+}
+
+func (m *StepSequenceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StepSequenceRequest.Marshal(b, m, deterministic)
+}
+
+func (m *StepSequenceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StepSequenceRequest.Merge(m, src)
+}
+
+func (m *StepSequenceRequest) XXX_Size() int {
+	return xxx_messageInfo_StepSequenceRequest.Size(m)
+}
+
+func (m *StepSequenceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StepSequenceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StepSequenceRequest proto.InternalMessageInfo
+
+func (m *StepSequenceRequest) GetAction() StepSequenceAction {
+	if m != nil {
+		return m.Action
+	}
+	return StepSequenceAction_STEP_NEXT
+}
+
+type StepSequence struct {
+	Metadata             *common.Metadata `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *StepSequence) Reset()         { *m = StepSequence{} }
+func (m *StepSequence) String() string { return proto.CompactTextString(m) }
+func (*StepSequence) ProtoMessage()    {}
+func (*StepSequence) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{28}
+}
+
+func (m *StepSequence) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StepSequence.Unmarshal(m, b)
+}
+
+func (m *StepSequence) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StepSequence.Marshal(b, m, deterministic)
+}
+
+func (m *StepSequence) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StepSequence.Merge(m, src)
+}
+
+func (m *StepSequence) XXX_Size() int {
+	return xxx_messageInfo_StepSequence.Size(m)
+}
+
+func (m *StepSequence) XXX_DiscardUnknown() {
+	xxx_messageInfo_StepSequence.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StepSequence proto.InternalMessageInfo
+
+func (m *StepSequence) GetMetadata() *common.Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+type StepSequenceResponse struct {
+	Messages             []*StepSequence `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *StepSequenceResponse) Reset()         { *m = StepSequenceResponse{} }
+func (m *StepSequenceResponse) String() string { return proto.CompactTextString(m) }
+func (*StepSequenceResponse) ProtoMessage()    {}
+func (*StepSequenceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{29}
+}
+
+func (m *StepSequenceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StepSequenceResponse.Unmarshal(m, b)
+}
+
+func (m *StepSequenceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StepSequenceResponse.Marshal(b, m, deterministic)
+}
+
+func (m *StepSequenceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StepSequenceResponse.Merge(m, src)
+}
+
+func (m *StepSequenceResponse) XXX_Size() int {
+	return xxx_messageInfo_StepSequenceResponse.Size(m)
+}
+
+func (m *StepSequenceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StepSequenceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StepSequenceResponse proto.InternalMessageInfo
+
+func (m *StepSequenceResponse) GetMessages() []*StepSequence {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+// rpc sequences
+// Describes the phases and tasks of all the sequences, for the current runtime
+// and config. The sequences depending on a request, such as an upgrade, are
+// described for the request with the default options.
+type SequencePlanTask struct {
+	// The name of the function implementing the task.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The human friendly description of the task, if any.
+	Label                string   `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SequencePlanTask) Reset()         { *m = SequencePlanTask{} }
+func (m *SequencePlanTask) String() string { return proto.CompactTextString(m) }
+func (*SequencePlanTask) ProtoMessage()    {}
+func (*SequencePlanTask) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{30}
+}
+
+func (m *SequencePlanTask) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SequencePlanTask.Unmarshal(m, b)
+}
+
+func (m *SequencePlanTask) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SequencePlanTask.Marshal(b, m, deterministic)
+}
+
+func (m *SequencePlanTask) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SequencePlanTask.Merge(m, src)
+}
+
+func (m *SequencePlanTask) XXX_Size() int {
+	return xxx_messageInfo_SequencePlanTask.Size(m)
+}
+
+func (m *SequencePlanTask) XXX_DiscardUnknown() {
+	xxx_messageInfo_SequencePlanTask.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SequencePlanTask proto.InternalMessageInfo
+
+func (m *SequencePlanTask) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SequencePlanTask) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
+
+type SequencePlanPhase struct {
+	Number int32 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	// The tasks of the phase, which run concurrently.
+	Tasks []*SequencePlanTask `protobuf:"bytes,2,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	// Whether the phase runs concurrently with the next phase.
+	Overlap              bool     `protobuf:"varint,3,opt,name=overlap,proto3" json:"overlap,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SequencePlanPhase) Reset()         { *m = SequencePlanPhase{} }
+func (m *SequencePlanPhase) String() string { return proto.CompactTextString(m) }
+func (*SequencePlanPhase) ProtoMessage()    {}
+func (*SequencePlanPhase) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{31}
+}
+
+func (m *SequencePlanPhase) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SequencePlanPhase.Unmarshal(m, b)
+}
+
+func (m *SequencePlanPhase) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SequencePlanPhase.Marshal(b, m, deterministic)
+}
+
+func (m *SequencePlanPhase) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SequencePlanPhase.Merge(m, src)
+}
+
+func (m *SequencePlanPhase) XXX_Size() int {
+	return xxx_messageInfo_SequencePlanPhase.Size(m)
+}
+
+func (m *SequencePlanPhase) XXX_DiscardUnknown() {
+	xxx_messageInfo_SequencePlanPhase.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SequencePlanPhase proto.InternalMessageInfo
+
+func (m *SequencePlanPhase) GetNumber() int32 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
+}
+
+func (m *SequencePlanPhase) GetTasks() []*SequencePlanTask {
+	if m != nil {
+		return m.Tasks
+	}
+	return nil
+}
+
+func (m *SequencePlanPhase) GetOverlap() bool {
+	if m != nil {
+		return m.Overlap
+	}
+	return false
+}
+
+type SequencePlan struct {
+	Sequence             string               `protobuf:"bytes,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Phases               []*SequencePlanPhase `protobuf:"bytes,2,rep,name=phases,proto3" json:"phases,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *SequencePlan) Reset()         { *m = SequencePlan{} }
+func (m *SequencePlan) String() string { return proto.CompactTextString(m) }
+func (*SequencePlan) ProtoMessage()    {}
+func (*SequencePlan) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{32}
+}
+
+func (m *SequencePlan) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SequencePlan.Unmarshal(m, b)
+}
+
+func (m *SequencePlan) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SequencePlan.Marshal(b, m, deterministic)
+}
+
+func (m *SequencePlan) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SequencePlan.Merge(m, src)
+}
+
+func (m *SequencePlan) XXX_Size() int {
+	return xxx_messageInfo_SequencePlan.Size(m)
+}
+
+func (m *SequencePlan) XXX_DiscardUnknown() {
+	xxx_messageInfo_SequencePlan.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SequencePlan proto.InternalMessageInfo
+
+func (m *SequencePlan) GetSequence() string {
+	if m != nil {
+		return m.Sequence
+	}
+	return ""
+}
+
+func (m *SequencePlan) GetPhases() []*SequencePlanPhase {
+	if m != nil {
+		return m.Phases
+	}
+	return nil
+}
+
+type Sequences struct {
+	Metadata             *common.Metadata `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Plans                []*SequencePlan  `protobuf:"bytes,2,rep,name=plans,proto3" json:"plans,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *Sequences) Reset()         { *m = Sequences{} }
+func (m *Sequences) String() string { return proto.CompactTextString(m) }
+func (*Sequences) ProtoMessage()    {}
+func (*Sequences) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{33}
+}
+
+func (m *Sequences) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Sequences.Unmarshal(m, b)
+}
+
+func (m *Sequences) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Sequences.Marshal(b, m, deterministic)
+}
+
+func (m *Sequences) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Sequences.Merge(m, src)
+}
+
+func (m *Sequences) XXX_Size() int {
+	return xxx_messageInfo_Sequences.Size(m)
+}
+
+func (m *Sequences) XXX_DiscardUnknown() {
+	xxx_messageInfo_Sequences.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Sequences proto.InternalMessageInfo
+
+func (m *Sequences) GetMetadata() *common.Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *Sequences) GetPlans() []*SequencePlan {
+	if m != nil {
+		return m.Plans
+	}
+	return nil
+}
+
+type SequencesResponse struct {
+	Messages             []*Sequences `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *SequencesResponse) Reset()         { *m = SequencesResponse{} }
+func (m *SequencesResponse) String() string { return proto.CompactTextString(m) }
+func (*SequencesResponse) ProtoMessage()    {}
+func (*SequencesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{34}
+}
+
+func (m *SequencesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SequencesResponse.Unmarshal(m, b)
+}
+
+func (m *SequencesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SequencesResponse.Marshal(b, m, deterministic)
+}
+
+func (m *SequencesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SequencesResponse.Merge(m, src)
+}
+
+func (m *SequencesResponse) XXX_Size() int {
+	return xxx_messageInfo_SequencesResponse.Size(m)
+}
+
+func (m *SequencesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SequencesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SequencesResponse proto.InternalMessageInfo
+
+func (m *SequencesResponse) GetMessages() []*Sequences {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+// rpc upgrade
+type UpgradeRequest struct {
+	Image    string `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	Preserve bool   `protobuf:"varint,2,opt,name=preserve,proto3" json:"preserve,omitempty"`
+	// stage defers the upgrade to the next reboot.
+	Stage bool `protobuf:"varint,3,opt,name=stage,proto3" json:"stage,omitempty"`
+	// signature is a detached signature of the manifest digest of the image,
+	// e.g. `sha256:...`. Without it, the cosign signatures stored in the
+	// registry are verified instead, if there is a public key.
+	Signature []byte `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	// public_key is the PEM encoded key that the image is verified against, if
+	// the config has no image verification keys.
+	PublicKey            string   `protobuf:"bytes,5,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpgradeRequest) Reset()         { *m = UpgradeRequest{} }
+func (m *UpgradeRequest) String() string { return proto.CompactTextString(m) }
+func (*UpgradeRequest) ProtoMessage()    {}
+func (*UpgradeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_84b4f59d98cc997c, []int{35}
+}
+
+func (m *UpgradeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpgradeRequest.Unmarshal(m, b)
+}
+
+func (m *UpgradeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpgradeRequest.Marshal(b, m, deterministic)
+}
+
+func (m *UpgradeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpgradeRequest.Merge(m, src)
+}
+
+func (m *UpgradeRequest) XXX_Size() int {
+	return xxx_messageInfo_UpgradeRequest.Size(m)
+}
+
+func (m *UpgradeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpgradeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpgradeRequest proto.InternalMessageInfo
+
+func (m *UpgradeRequest) GetImage() string {
+	if m != nil {
+		return m.Image
+	}
+	return ""
+}
+
+func (m *UpgradeRequest) GetPreserve() bool {
+	if m != nil {
+		return m.Preserve
+	}
+	return false
+}
+
+func (m *UpgradeRequest) GetStage() bool {
+	if m != nil {
+		return m.Stage
+	}
+	return false
+}
+
+func (m *UpgradeRequest) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *UpgradeRequest) GetPublicKey() string {
 	if m != nil {
-		return m.Preserve
+		return m.PublicKey
 	}
-	return false
+	return ""
 }
 
 type Upgrade struct {
@@ -408,7 +2085,7 @@ func (m *Upgrade) Reset()         { *m = Upgrade{} }
 func (m *Upgrade) String() string { return proto.CompactTextString(m) }
 func (*Upgrade) ProtoMessage()    {}
 func (*Upgrade) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{8}
+	return fileDescriptor_84b4f59d98cc997c, []int{36}
 }
 
 func (m *Upgrade) XXX_Unmarshal(b []byte) error {
@@ -458,7 +2135,7 @@ func (m *UpgradeResponse) Reset()         { *m = UpgradeResponse{} }
 func (m *UpgradeResponse) String() string { return proto.CompactTextString(m) }
 func (*UpgradeResponse) ProtoMessage()    {}
 func (*UpgradeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{9}
+	return fileDescriptor_84b4f59d98cc997c, []int{37}
 }
 
 func (m *UpgradeResponse) XXX_Unmarshal(b []byte) error {
@@ -503,7 +2180,7 @@ func (m *ServiceList) Reset()         { *m = ServiceList{} }
 func (m *ServiceList) String() string { return proto.CompactTextString(m) }
 func (*ServiceList) ProtoMessage()    {}
 func (*ServiceList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{10}
+	return fileDescriptor_84b4f59d98cc997c, []int{38}
 }
 
 func (m *ServiceList) XXX_Unmarshal(b []byte) error {
@@ -553,7 +2230,7 @@ func (m *ServiceListResponse) Reset()         { *m = ServiceListResponse{} }
 func (m *ServiceListResponse) String() string { return proto.CompactTextString(m) }
 func (*ServiceListResponse) ProtoMessage()    {}
 func (*ServiceListResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{11}
+	return fileDescriptor_84b4f59d98cc997c, []int{39}
 }
 
 func (m *ServiceListResponse) XXX_Unmarshal(b []byte) error {
@@ -599,7 +2276,7 @@ func (m *ServiceInfo) Reset()         { *m = ServiceInfo{} }
 func (m *ServiceInfo) String() string { return proto.CompactTextString(m) }
 func (*ServiceInfo) ProtoMessage()    {}
 func (*ServiceInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{12}
+	return fileDescriptor_84b4f59d98cc997c, []int{40}
 }
 
 func (m *ServiceInfo) XXX_Unmarshal(b []byte) error {
@@ -663,7 +2340,7 @@ func (m *ServiceEvents) Reset()         { *m = ServiceEvents{} }
 func (m *ServiceEvents) String() string { return proto.CompactTextString(m) }
 func (*ServiceEvents) ProtoMessage()    {}
 func (*ServiceEvents) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{13}
+	return fileDescriptor_84b4f59d98cc997c, []int{41}
 }
 
 func (m *ServiceEvents) XXX_Unmarshal(b []byte) error {
@@ -708,7 +2385,7 @@ func (m *ServiceEvent) Reset()         { *m = ServiceEvent{} }
 func (m *ServiceEvent) String() string { return proto.CompactTextString(m) }
 func (*ServiceEvent) ProtoMessage()    {}
 func (*ServiceEvent) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{14}
+	return fileDescriptor_84b4f59d98cc997c, []int{42}
 }
 
 func (m *ServiceEvent) XXX_Unmarshal(b []byte) error {
@@ -768,7 +2445,7 @@ func (m *ServiceHealth) Reset()         { *m = ServiceHealth{} }
 func (m *ServiceHealth) String() string { return proto.CompactTextString(m) }
 func (*ServiceHealth) ProtoMessage()    {}
 func (*ServiceHealth) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{15}
+	return fileDescriptor_84b4f59d98cc997c, []int{43}
 }
 
 func (m *ServiceHealth) XXX_Unmarshal(b []byte) error {
@@ -833,7 +2510,7 @@ func (m *ServiceStartRequest) Reset()         { *m = ServiceStartRequest{} }
 func (m *ServiceStartRequest) String() string { return proto.CompactTextString(m) }
 func (*ServiceStartRequest) ProtoMessage()    {}
 func (*ServiceStartRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{16}
+	return fileDescriptor_84b4f59d98cc997c, []int{44}
 }
 
 func (m *ServiceStartRequest) XXX_Unmarshal(b []byte) error {
@@ -877,7 +2554,7 @@ func (m *ServiceStart) Reset()         { *m = ServiceStart{} }
 func (m *ServiceStart) String() string { return proto.CompactTextString(m) }
 func (*ServiceStart) ProtoMessage()    {}
 func (*ServiceStart) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{17}
+	return fileDescriptor_84b4f59d98cc997c, []int{45}
 }
 
 func (m *ServiceStart) XXX_Unmarshal(b []byte) error {
@@ -927,7 +2604,7 @@ func (m *ServiceStartResponse) Reset()         { *m = ServiceStartResponse{} }
 func (m *ServiceStartResponse) String() string { return proto.CompactTextString(m) }
 func (*ServiceStartResponse) ProtoMessage()    {}
 func (*ServiceStartResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{18}
+	return fileDescriptor_84b4f59d98cc997c, []int{46}
 }
 
 func (m *ServiceStartResponse) XXX_Unmarshal(b []byte) error {
@@ -970,7 +2647,7 @@ func (m *ServiceStopRequest) Reset()         { *m = ServiceStopRequest{} }
 func (m *ServiceStopRequest) String() string { return proto.CompactTextString(m) }
 func (*ServiceStopRequest) ProtoMessage()    {}
 func (*ServiceStopRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{19}
+	return fileDescriptor_84b4f59d98cc997c, []int{47}
 }
 
 func (m *ServiceStopRequest) XXX_Unmarshal(b []byte) error {
@@ -1014,7 +2691,7 @@ func (m *ServiceStop) Reset()         { *m = ServiceStop{} }
 func (m *ServiceStop) String() string { return proto.CompactTextString(m) }
 func (*ServiceStop) ProtoMessage()    {}
 func (*ServiceStop) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{20}
+	return fileDescriptor_84b4f59d98cc997c, []int{48}
 }
 
 func (m *ServiceStop) XXX_Unmarshal(b []byte) error {
@@ -1064,7 +2741,7 @@ func (m *ServiceStopResponse) Reset()         { *m = ServiceStopResponse{} }
 func (m *ServiceStopResponse) String() string { return proto.CompactTextString(m) }
 func (*ServiceStopResponse) ProtoMessage()    {}
 func (*ServiceStopResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{21}
+	return fileDescriptor_84b4f59d98cc997c, []int{49}
 }
 
 func (m *ServiceStopResponse) XXX_Unmarshal(b []byte) error {
@@ -1107,7 +2784,7 @@ func (m *ServiceRestartRequest) Reset()         { *m = ServiceRestartRequest{} }
 func (m *ServiceRestartRequest) String() string { return proto.CompactTextString(m) }
 func (*ServiceRestartRequest) ProtoMessage()    {}
 func (*ServiceRestartRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{22}
+	return fileDescriptor_84b4f59d98cc997c, []int{50}
 }
 
 func (m *ServiceRestartRequest) XXX_Unmarshal(b []byte) error {
@@ -1151,7 +2828,7 @@ func (m *ServiceRestart) Reset()         { *m = ServiceRestart{} }
 func (m *ServiceRestart) String() string { return proto.CompactTextString(m) }
 func (*ServiceRestart) ProtoMessage()    {}
 func (*ServiceRestart) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{23}
+	return fileDescriptor_84b4f59d98cc997c, []int{51}
 }
 
 func (m *ServiceRestart) XXX_Unmarshal(b []byte) error {
@@ -1201,7 +2878,7 @@ func (m *ServiceRestartResponse) Reset()         { *m = ServiceRestartResponse{}
 func (m *ServiceRestartResponse) String() string { return proto.CompactTextString(m) }
 func (*ServiceRestartResponse) ProtoMessage()    {}
 func (*ServiceRestartResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{24}
+	return fileDescriptor_84b4f59d98cc997c, []int{52}
 }
 
 func (m *ServiceRestartResponse) XXX_Unmarshal(b []byte) error {
@@ -1245,7 +2922,7 @@ func (m *StartRequest) Reset()         { *m = StartRequest{} }
 func (m *StartRequest) String() string { return proto.CompactTextString(m) }
 func (*StartRequest) ProtoMessage()    {}
 func (*StartRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{25}
+	return fileDescriptor_84b4f59d98cc997c, []int{53}
 }
 
 func (m *StartRequest) XXX_Unmarshal(b []byte) error {
@@ -1289,7 +2966,7 @@ func (m *StartResponse) Reset()         { *m = StartResponse{} }
 func (m *StartResponse) String() string { return proto.CompactTextString(m) }
 func (*StartResponse) ProtoMessage()    {}
 func (*StartResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{26}
+	return fileDescriptor_84b4f59d98cc997c, []int{54}
 }
 
 func (m *StartResponse) XXX_Unmarshal(b []byte) error {
@@ -1333,7 +3010,7 @@ func (m *StopRequest) Reset()         { *m = StopRequest{} }
 func (m *StopRequest) String() string { return proto.CompactTextString(m) }
 func (*StopRequest) ProtoMessage()    {}
 func (*StopRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{27}
+	return fileDescriptor_84b4f59d98cc997c, []int{55}
 }
 
 func (m *StopRequest) XXX_Unmarshal(b []byte) error {
@@ -1377,7 +3054,7 @@ func (m *StopResponse) Reset()         { *m = StopResponse{} }
 func (m *StopResponse) String() string { return proto.CompactTextString(m) }
 func (*StopResponse) ProtoMessage()    {}
 func (*StopResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{28}
+	return fileDescriptor_84b4f59d98cc997c, []int{56}
 }
 
 func (m *StopResponse) XXX_Unmarshal(b []byte) error {
@@ -1424,7 +3101,7 @@ func (m *CopyRequest) Reset()         { *m = CopyRequest{} }
 func (m *CopyRequest) String() string { return proto.CompactTextString(m) }
 func (*CopyRequest) ProtoMessage()    {}
 func (*CopyRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{29}
+	return fileDescriptor_84b4f59d98cc997c, []int{57}
 }
 
 func (m *CopyRequest) XXX_Unmarshal(b []byte) error {
@@ -1475,7 +3152,7 @@ func (m *ListRequest) Reset()         { *m = ListRequest{} }
 func (m *ListRequest) String() string { return proto.CompactTextString(m) }
 func (*ListRequest) ProtoMessage()    {}
 func (*ListRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{30}
+	return fileDescriptor_84b4f59d98cc997c, []int{58}
 }
 
 func (m *ListRequest) XXX_Unmarshal(b []byte) error {
@@ -1550,7 +3227,7 @@ func (m *FileInfo) Reset()         { *m = FileInfo{} }
 func (m *FileInfo) String() string { return proto.CompactTextString(m) }
 func (*FileInfo) ProtoMessage()    {}
 func (*FileInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{31}
+	return fileDescriptor_84b4f59d98cc997c, []int{59}
 }
 
 func (m *FileInfo) XXX_Unmarshal(b []byte) error {
@@ -1651,7 +3328,7 @@ func (m *Mounts) Reset()         { *m = Mounts{} }
 func (m *Mounts) String() string { return proto.CompactTextString(m) }
 func (*Mounts) ProtoMessage()    {}
 func (*Mounts) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{32}
+	return fileDescriptor_84b4f59d98cc997c, []int{60}
 }
 
 func (m *Mounts) XXX_Unmarshal(b []byte) error {
@@ -1701,7 +3378,7 @@ func (m *MountsResponse) Reset()         { *m = MountsResponse{} }
 func (m *MountsResponse) String() string { return proto.CompactTextString(m) }
 func (*MountsResponse) ProtoMessage()    {}
 func (*MountsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{33}
+	return fileDescriptor_84b4f59d98cc997c, []int{61}
 }
 
 func (m *MountsResponse) XXX_Unmarshal(b []byte) error {
@@ -1748,7 +3425,7 @@ func (m *MountStat) Reset()         { *m = MountStat{} }
 func (m *MountStat) String() string { return proto.CompactTextString(m) }
 func (*MountStat) ProtoMessage()    {}
 func (*MountStat) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{34}
+	return fileDescriptor_84b4f59d98cc997c, []int{62}
 }
 
 func (m *MountStat) XXX_Unmarshal(b []byte) error {
@@ -1814,7 +3491,7 @@ func (m *Version) Reset()         { *m = Version{} }
 func (m *Version) String() string { return proto.CompactTextString(m) }
 func (*Version) ProtoMessage()    {}
 func (*Version) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{35}
+	return fileDescriptor_84b4f59d98cc997c, []int{63}
 }
 
 func (m *Version) XXX_Unmarshal(b []byte) error {
@@ -1871,7 +3548,7 @@ func (m *VersionResponse) Reset()         { *m = VersionResponse{} }
 func (m *VersionResponse) String() string { return proto.CompactTextString(m) }
 func (*VersionResponse) ProtoMessage()    {}
 func (*VersionResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{36}
+	return fileDescriptor_84b4f59d98cc997c, []int{64}
 }
 
 func (m *VersionResponse) XXX_Unmarshal(b []byte) error {
@@ -1919,7 +3596,7 @@ func (m *VersionInfo) Reset()         { *m = VersionInfo{} }
 func (m *VersionInfo) String() string { return proto.CompactTextString(m) }
 func (*VersionInfo) ProtoMessage()    {}
 func (*VersionInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{37}
+	return fileDescriptor_84b4f59d98cc997c, []int{65}
 }
 
 func (m *VersionInfo) XXX_Unmarshal(b []byte) error {
@@ -1998,7 +3675,7 @@ func (m *PlatformInfo) Reset()         { *m = PlatformInfo{} }
 func (m *PlatformInfo) String() string { return proto.CompactTextString(m) }
 func (*PlatformInfo) ProtoMessage()    {}
 func (*PlatformInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{38}
+	return fileDescriptor_84b4f59d98cc997c, []int{66}
 }
 
 func (m *PlatformInfo) XXX_Unmarshal(b []byte) error {
@@ -2055,7 +3732,7 @@ func (m *LogsRequest) Reset()         { *m = LogsRequest{} }
 func (m *LogsRequest) String() string { return proto.CompactTextString(m) }
 func (*LogsRequest) ProtoMessage()    {}
 func (*LogsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{39}
+	return fileDescriptor_84b4f59d98cc997c, []int{67}
 }
 
 func (m *LogsRequest) XXX_Unmarshal(b []byte) error {
@@ -2126,7 +3803,7 @@ func (m *ReadRequest) Reset()         { *m = ReadRequest{} }
 func (m *ReadRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadRequest) ProtoMessage()    {}
 func (*ReadRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_84b4f59d98cc997c, []int{40}
+	return fileDescriptor_84b4f59d98cc997c, []int{68}
 }
 
 func (m *ReadRequest) XXX_Unmarshal(b []byte) error {
@@ -2159,13 +3836,43 @@ func (m *ReadRequest) GetPath() string {
 }
 
 func init() {
+	proto.RegisterEnum("machine.SequenceEventType", SequenceEventType_name, SequenceEventType_value)
+	proto.RegisterEnum("machine.StepSequenceAction", StepSequenceAction_name, StepSequenceAction_value)
+	proto.RegisterType((*AbortSequence)(nil), "machine.AbortSequence")
+	proto.RegisterType((*AbortSequenceResponse)(nil), "machine.AbortSequenceResponse")
 	proto.RegisterType((*Reboot)(nil), "machine.Reboot")
 	proto.RegisterType((*RebootResponse)(nil), "machine.RebootResponse")
 	proto.RegisterType((*ResetRequest)(nil), "machine.ResetRequest")
 	proto.RegisterType((*Reset)(nil), "machine.Reset")
 	proto.RegisterType((*ResetResponse)(nil), "machine.ResetResponse")
+	proto.RegisterType((*RunPhaseRequest)(nil), "machine.RunPhaseRequest")
+	proto.RegisterType((*RunPhase)(nil), "machine.RunPhase")
+	proto.RegisterType((*RunPhaseResponse)(nil), "machine.RunPhaseResponse")
+	proto.RegisterType((*RunSequenceRequest)(nil), "machine.RunSequenceRequest")
+	proto.RegisterType((*RunSequence)(nil), "machine.RunSequence")
+	proto.RegisterType((*RunSequenceResponse)(nil), "machine.RunSequenceResponse")
+	proto.RegisterType((*PreflightCheck)(nil), "machine.PreflightCheck")
+	proto.RegisterType((*Preflight)(nil), "machine.Preflight")
+	proto.RegisterType((*PreflightResponse)(nil), "machine.PreflightResponse")
+	proto.RegisterType((*TaskResult)(nil), "machine.TaskResult")
+	proto.RegisterType((*PhaseResult)(nil), "machine.PhaseResult")
+	proto.RegisterType((*SequenceResult)(nil), "machine.SequenceResult")
+	proto.RegisterType((*SequenceResultResponse)(nil), "machine.SequenceResultResponse")
+	proto.RegisterType((*SequenceHistory)(nil), "machine.SequenceHistory")
+	proto.RegisterType((*SequenceHistoryResponse)(nil), "machine.SequenceHistoryResponse")
+	proto.RegisterType((*EventsRequest)(nil), "machine.EventsRequest")
+	proto.RegisterType((*TaskProgress)(nil), "machine.TaskProgress")
+	proto.RegisterType((*SequenceEvent)(nil), "machine.SequenceEvent")
 	proto.RegisterType((*Shutdown)(nil), "machine.Shutdown")
 	proto.RegisterType((*ShutdownResponse)(nil), "machine.ShutdownResponse")
+	proto.RegisterType((*StepSequenceRequest)(nil), "machine.StepSequenceRequest")
+	proto.RegisterType((*StepSequence)(nil), "machine.StepSequence")
+	proto.RegisterType((*StepSequenceResponse)(nil), "machine.StepSequenceResponse")
+	proto.RegisterType((*SequencePlanTask)(nil), "machine.SequencePlanTask")
+	proto.RegisterType((*SequencePlanPhase)(nil), "machine.SequencePlanPhase")
+	proto.RegisterType((*SequencePlan)(nil), "machine.SequencePlan")
+	proto.RegisterType((*Sequences)(nil), "machine.Sequences")
+	proto.RegisterType((*SequencesResponse)(nil), "machine.SequencesResponse")
 	proto.RegisterType((*UpgradeRequest)(nil), "machine.UpgradeRequest")
 	proto.RegisterType((*Upgrade)(nil), "machine.Upgrade")
 	proto.RegisterType((*UpgradeResponse)(nil), "machine.UpgradeResponse")
@@ -2205,100 +3912,168 @@ func init() {
 func init() { proto.RegisterFile("machine/machine.proto", fileDescriptor_84b4f59d98cc997c) }
 
 var fileDescriptor_84b4f59d98cc997c = []byte{
-	// 1484 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0x5b, 0x73, 0xdb, 0x44,
-	0x14, 0x1e, 0x39, 0x89, 0x2f, 0xc7, 0x89, 0x13, 0xd4, 0x26, 0x11, 0x69, 0x7a, 0x13, 0x97, 0x76,
-	0x42, 0xeb, 0xa4, 0x29, 0x74, 0x80, 0x52, 0x98, 0x36, 0x29, 0x6d, 0xa7, 0x0d, 0x0d, 0x0a, 0xf0,
-	0xc0, 0x8b, 0x59, 0x5b, 0x6b, 0x79, 0xa7, 0x92, 0x56, 0x68, 0xd7, 0xe9, 0x84, 0xe1, 0x17, 0xf0,
-	0xca, 0x5b, 0x5f, 0xf9, 0x8f, 0x3c, 0x33, 0x7b, 0x95, 0x62, 0xd9, 0xa5, 0x9e, 0xe9, 0x93, 0x77,
-	0xcf, 0xf9, 0xf6, 0xdc, 0x75, 0xf6, 0xac, 0x61, 0x3d, 0x41, 0x83, 0x11, 0x49, 0xf1, 0xae, 0xfe,
-	0xed, 0x66, 0x39, 0xe5, 0xd4, 0x6d, 0xe8, 0xed, 0xd6, 0xa5, 0x88, 0xd2, 0x28, 0xc6, 0xbb, 0x92,
-	0xdc, 0x1f, 0x0f, 0x77, 0x71, 0x92, 0xf1, 0x33, 0x85, 0xda, 0xba, 0x3a, 0xc9, 0xe4, 0x24, 0xc1,
-	0x8c, 0xa3, 0x24, 0xd3, 0x80, 0x0b, 0x03, 0x9a, 0x24, 0x34, 0xdd, 0x55, 0x3f, 0x8a, 0xe8, 0xdf,
-	0x83, 0x7a, 0x80, 0xfb, 0x94, 0x72, 0xf7, 0x16, 0x34, 0x13, 0xcc, 0x51, 0x88, 0x38, 0xf2, 0x9c,
-	0x6b, 0xce, 0xcd, 0xf6, 0xfe, 0x5a, 0x57, 0x43, 0x8f, 0x34, 0x3d, 0xb0, 0x08, 0xff, 0x01, 0x74,
-	0xd4, 0xb9, 0x00, 0xb3, 0x8c, 0xa6, 0x0c, 0xbb, 0x9f, 0x89, 0xf3, 0x8c, 0xa1, 0x08, 0x33, 0xcf,
-	0xb9, 0xb6, 0x70, 0xb3, 0xbd, 0xbf, 0xda, 0x35, 0x7e, 0x68, 0xa8, 0x05, 0xf8, 0x8f, 0x60, 0x39,
-	0xc0, 0x0c, 0xf3, 0x00, 0xff, 0x3e, 0xc6, 0x8c, 0xbb, 0x5b, 0xd0, 0x8c, 0x72, 0x34, 0xc0, 0xc3,
-	0x71, 0x2c, 0x95, 0x37, 0x03, 0xbb, 0x77, 0x37, 0xa0, 0x9e, 0xcb, 0xf3, 0x5e, 0x4d, 0x72, 0xf4,
-	0xce, 0xff, 0x02, 0x96, 0xa4, 0x8c, 0x39, 0x2d, 0xbf, 0x0f, 0x2b, 0x5a, 0xb5, 0x36, 0x7c, 0xa7,
-	0x62, 0x78, 0xa7, 0x64, 0xb8, 0x40, 0x16, 0x76, 0x7f, 0x09, 0xcd, 0x93, 0xd1, 0x98, 0x87, 0xf4,
-	0x75, 0x3a, 0xa7, 0xda, 0x87, 0xb0, 0x66, 0x4e, 0x5a, 0xcd, 0xb7, 0x2b, 0x9a, 0x3f, 0xb0, 0x9a,
-	0x2d, 0xb8, 0x1c, 0xb4, 0xce, 0xcf, 0x59, 0x94, 0xa3, 0x10, 0x9b, 0xb0, 0x5d, 0x84, 0x25, 0x92,
-	0xa0, 0x08, 0x4b, 0xfd, 0xad, 0x40, 0x6d, 0x44, 0x30, 0xb3, 0x1c, 0x33, 0x9c, 0x9f, 0x62, 0x1d,
-	0x32, 0xbb, 0xf7, 0x9f, 0x41, 0x43, 0xcb, 0x98, 0xcf, 0x7e, 0x77, 0x0d, 0x16, 0xd0, 0xe0, 0x95,
-	0x94, 0xd7, 0x0a, 0xc4, 0xd2, 0xff, 0x0e, 0x56, 0xad, 0x39, 0xda, 0xa1, 0x5b, 0x15, 0x87, 0xd6,
-	0xac, 0x43, 0x06, 0x5b, 0xf8, 0x93, 0x40, 0xfb, 0x04, 0xe7, 0xa7, 0x64, 0x80, 0x5f, 0x10, 0x36,
-	0x67, 0x1a, 0xdd, 0x3d, 0x68, 0x32, 0x75, 0x98, 0x79, 0x35, 0xa9, 0xea, 0x62, 0x11, 0x3b, 0xc5,
-	0x78, 0x96, 0x0e, 0x69, 0x60, 0x51, 0xfe, 0x13, 0xb8, 0x50, 0x52, 0x67, 0x6d, 0xde, 0xab, 0xd8,
-	0x5c, 0x11, 0x24, 0xf1, 0x85, 0xdd, 0x7f, 0x3b, 0xd6, 0x70, 0xa1, 0xc2, 0xed, 0x40, 0x8d, 0x84,
-	0x3a, 0x05, 0x35, 0x12, 0x8a, 0xac, 0x30, 0x8e, 0x38, 0xd6, 0xc1, 0x52, 0x1b, 0xb7, 0x0b, 0x75,
-	0x7c, 0x8a, 0x53, 0xce, 0xbc, 0x05, 0xe9, 0xdc, 0xc6, 0xa4, 0x96, 0xc7, 0x92, 0x1b, 0x68, 0x94,
-	0xc0, 0x8f, 0x30, 0x8a, 0xf9, 0xc8, 0x5b, 0x9c, 0x8e, 0x7f, 0x2a, 0xb9, 0x81, 0x46, 0xf9, 0xdf,
-	0xc2, 0xca, 0x39, 0x41, 0xee, 0x6d, 0xab, 0x50, 0xb9, 0xb5, 0x3e, 0x55, 0xa1, 0xd1, 0xe7, 0xf7,
-	0x61, 0xb9, 0x4c, 0x17, 0x09, 0x4f, 0x58, 0xa4, 0xdd, 0x12, 0xcb, 0x19, 0x7e, 0xed, 0x40, 0xcd,
-	0xfa, 0xb4, 0xd5, 0x55, 0x4d, 0xa8, 0x6b, 0x9a, 0x50, 0xf7, 0x27, 0xd3, 0x84, 0x82, 0x1a, 0x67,
-	0xfe, 0x3f, 0x8e, 0x35, 0x52, 0x59, 0xef, 0x7a, 0xd0, 0x18, 0xa7, 0xaf, 0x52, 0xfa, 0x3a, 0xd5,
-	0xdf, 0xbd, 0xd9, 0x0a, 0x8e, 0xf2, 0xec, 0x4c, 0x17, 0xb1, 0xd9, 0xba, 0xd7, 0x61, 0x39, 0x46,
-	0x8c, 0xf7, 0x74, 0x42, 0xa4, 0xee, 0x56, 0xd0, 0x16, 0xb4, 0x23, 0x45, 0x72, 0xef, 0x83, 0xdc,
-	0xf6, 0x06, 0x23, 0x94, 0x46, 0x58, 0x47, 0xf0, 0x6d, 0xd6, 0x81, 0x80, 0x1f, 0x48, 0xb4, 0xff,
-	0x89, 0x2d, 0x94, 0x13, 0x8e, 0x72, 0xdb, 0xa3, 0x26, 0xd2, 0xec, 0x1f, 0xdb, 0x80, 0x49, 0xd8,
-	0x9c, 0xf5, 0xeb, 0xc2, 0x62, 0x8e, 0x59, 0xa6, 0x63, 0x29, 0xd7, 0xfe, 0x33, 0xb8, 0x78, 0x5e,
-	0xb1, 0x2e, 0xd1, 0x3b, 0x95, 0x12, 0xad, 0xe4, 0x52, 0x1d, 0x28, 0x6a, 0xf4, 0x63, 0x70, 0x2d,
-	0x87, 0x66, 0xb3, 0x5c, 0x78, 0x69, 0x0b, 0x59, 0xa0, 0xde, 0x83, 0x07, 0x4f, 0x4a, 0xa1, 0x13,
-	0x6a, 0xdf, 0xfd, 0x1b, 0x93, 0xf8, 0xc2, 0xfe, 0x1b, 0xb0, 0xae, 0x19, 0x81, 0xc8, 0xd0, 0xec,
-	0x2c, 0x04, 0xd0, 0x39, 0x0f, 0x7c, 0x0f, 0x5e, 0x1c, 0xc1, 0xc6, 0xa4, 0x72, 0xed, 0xc8, 0xdd,
-	0x8a, 0x23, 0x9b, 0x93, 0x8e, 0x98, 0x23, 0x85, 0x2f, 0x3e, 0x2c, 0xbf, 0xad, 0x90, 0xbe, 0xae,
-	0x79, 0x8e, 0x7f, 0x03, 0x56, 0xce, 0xe7, 0xdc, 0xd8, 0xe5, 0x14, 0x76, 0x49, 0xe0, 0x75, 0x68,
-	0xbf, 0x25, 0xa3, 0x12, 0xf2, 0xa9, 0xd0, 0x57, 0x8a, 0xfe, 0x2c, 0x51, 0x3b, 0xd0, 0x3e, 0xa0,
-	0xd9, 0x99, 0x11, 0x75, 0x09, 0x5a, 0x39, 0xa5, 0xbc, 0x97, 0x21, 0x3e, 0xd2, 0xd8, 0xa6, 0x20,
-	0x1c, 0x23, 0x3e, 0xf2, 0x43, 0x68, 0xab, 0xae, 0xa9, 0xb0, 0x42, 0xa4, 0xb8, 0x91, 0x8d, 0x48,
-	0x31, 0x40, 0x78, 0xd0, 0xc8, 0xf1, 0x60, 0x9c, 0x33, 0x73, 0xeb, 0x98, 0xad, 0x7b, 0x03, 0x56,
-	0xd5, 0x92, 0xd0, 0xb4, 0x17, 0xe2, 0x8c, 0x8f, 0xe4, 0x37, 0xbb, 0x14, 0x74, 0x2c, 0xf9, 0x50,
-	0x50, 0xfd, 0x7f, 0x1d, 0x68, 0x7e, 0x4f, 0x62, 0xd5, 0x56, 0xe7, 0xce, 0x63, 0x8a, 0x12, 0xd3,
-	0x9b, 0xe4, 0x5a, 0xd0, 0x18, 0xf9, 0x43, 0x35, 0x88, 0x85, 0x40, 0xae, 0x05, 0x2d, 0xa1, 0xa1,
-	0x6a, 0x09, 0x2b, 0x81, 0x5c, 0x8b, 0x0b, 0x33, 0xa1, 0x21, 0x19, 0x12, 0x1c, 0x7a, 0x4b, 0x12,
-	0x6b, 0xf7, 0xee, 0x3a, 0xd4, 0x09, 0xeb, 0x85, 0x24, 0xf7, 0xea, 0xd2, 0xa9, 0x25, 0xc2, 0x0e,
-	0x49, 0x2e, 0x7a, 0x21, 0xce, 0x73, 0x9a, 0x7b, 0x0d, 0xd5, 0x0b, 0xe5, 0x46, 0x08, 0x8f, 0x49,
-	0xfa, 0xca, 0x6b, 0x2a, 0x23, 0xc4, 0xda, 0xfd, 0x08, 0x56, 0x72, 0x1c, 0x23, 0x4e, 0x4e, 0x71,
-	0x4f, 0x5a, 0xd8, 0x92, 0xcc, 0x65, 0x43, 0xfc, 0x01, 0x25, 0xd8, 0xff, 0x0d, 0xea, 0x47, 0x74,
-	0x2c, 0xba, 0xf6, 0x7c, 0x5e, 0xdf, 0x54, 0x2d, 0xd9, 0x5c, 0x81, 0xae, 0x2d, 0x46, 0x29, 0xed,
-	0x84, 0x23, 0xae, 0xda, 0x34, 0x13, 0x03, 0x9b, 0xd2, 0xf0, 0x4e, 0x03, 0x9b, 0x86, 0x16, 0x35,
-	0xfc, 0x27, 0xb4, 0xac, 0x48, 0xf7, 0x0a, 0xc0, 0x90, 0xc4, 0x98, 0x9d, 0x31, 0x8e, 0x13, 0x5d,
-	0x03, 0x25, 0x8a, 0x8d, 0xbb, 0xc8, 0xc5, 0xa2, 0x8e, 0xfb, 0x36, 0xb4, 0xd0, 0x29, 0x22, 0x31,
-	0xea, 0xc7, 0x2a, 0x21, 0x8b, 0x41, 0x41, 0x70, 0x2f, 0x03, 0x24, 0x42, 0x3c, 0x0e, 0x7b, 0x34,
-	0x95, 0xb9, 0x69, 0x05, 0x2d, 0x4d, 0x79, 0x99, 0xfa, 0x6f, 0x1c, 0x68, 0xfc, 0x82, 0x65, 0xa1,
-	0xcc, 0x19, 0xa0, 0x2e, 0x34, 0x4e, 0xd5, 0x41, 0x69, 0x4d, 0xb9, 0xf1, 0x68, 0x81, 0x72, 0x4a,
-	0x30, 0x20, 0xd1, 0x6a, 0xb3, 0x18, 0xf1, 0x21, 0xcd, 0x13, 0x7d, 0xa7, 0x15, 0xad, 0xf6, 0x58,
-	0x33, 0xd4, 0x5c, 0x61, 0x60, 0x62, 0x0e, 0xd2, 0xa2, 0xde, 0x69, 0x0e, 0x32, 0xd8, 0x22, 0xb6,
-	0x7f, 0x39, 0xd0, 0x2e, 0x19, 0x23, 0x6e, 0x5e, 0x8e, 0xec, 0xcd, 0xcb, 0x51, 0x24, 0x28, 0x6c,
-	0x84, 0xcc, 0xf0, 0xc5, 0x46, 0x48, 0xd4, 0x5f, 0x7f, 0x4c, 0x62, 0xae, 0x2f, 0x3f, 0xb5, 0x11,
-	0x61, 0x8c, 0x68, 0xcf, 0x38, 0xac, 0xc3, 0x18, 0x51, 0x13, 0xba, 0x0e, 0xd4, 0x28, 0x93, 0x15,
-	0xde, 0x0a, 0x6a, 0x94, 0x89, 0x3c, 0xa1, 0x7c, 0x30, 0x92, 0x95, 0xdd, 0x0a, 0xe4, 0xda, 0xbf,
-	0x07, 0xcb, 0x65, 0x3f, 0xed, 0x77, 0xe5, 0x9c, 0xff, 0xae, 0xe4, 0x37, 0xa4, 0xbf, 0x35, 0xb1,
-	0x16, 0x57, 0x7b, 0xfb, 0x05, 0x8d, 0x98, 0xe9, 0x10, 0xdb, 0xd0, 0x12, 0x58, 0x96, 0xa1, 0x81,
-	0x39, 0x5c, 0x10, 0x74, 0xdb, 0xaa, 0xd9, 0x91, 0x69, 0x17, 0xea, 0x61, 0x4e, 0x4e, 0x71, 0x2e,
-	0xfd, 0xe9, 0xec, 0x6f, 0x9a, 0x94, 0x1e, 0xd0, 0x94, 0x23, 0x92, 0xe2, 0xfc, 0x50, 0xb2, 0x03,
-	0x0d, 0x13, 0x8f, 0x82, 0x21, 0x8d, 0x63, 0xfa, 0x5a, 0x7a, 0xd9, 0x0c, 0xf4, 0x4e, 0x44, 0x80,
-	0x23, 0x12, 0xf7, 0x62, 0x92, 0x62, 0xe5, 0xea, 0x52, 0xd0, 0x12, 0x94, 0x17, 0x82, 0x20, 0xba,
-	0x67, 0x80, 0x51, 0x58, 0x6a, 0x63, 0xa5, 0x6e, 0x27, 0xd7, 0xfb, 0x6f, 0x1a, 0xd0, 0x39, 0x52,
-	0xb9, 0xd2, 0x1d, 0xdd, 0xbd, 0x05, 0x8b, 0xa2, 0x51, 0xba, 0x45, 0xed, 0x94, 0xfa, 0xe6, 0xd6,
-	0xb2, 0xb1, 0xf5, 0x10, 0x71, 0xb4, 0xe7, 0xb8, 0x9f, 0x03, 0x3c, 0x1f, 0xf7, 0xf1, 0x80, 0xa6,
-	0x43, 0x12, 0xb9, 0x1b, 0x95, 0xa1, 0xe3, 0xb1, 0x78, 0xb4, 0x55, 0x4e, 0xdd, 0x81, 0x45, 0x39,
-	0x05, 0x17, 0x3a, 0x4a, 0xfd, 0x76, 0xab, 0x78, 0x17, 0x98, 0xf6, 0xb8, 0xe7, 0x08, 0xb3, 0x44,
-	0xc4, 0xcb, 0x47, 0x8a, 0x04, 0x54, 0x14, 0x7c, 0x65, 0x5b, 0xcc, 0x2c, 0x93, 0x36, 0x27, 0x3f,
-	0xff, 0xa2, 0x9c, 0x17, 0x45, 0xd4, 0x4a, 0x8a, 0x4a, 0x41, 0x9c, 0xa6, 0x48, 0x3f, 0x29, 0xff,
-	0x5f, 0xd1, 0xc4, 0x1b, 0xf2, 0x9e, 0x79, 0xd2, 0xad, 0x4f, 0xbc, 0xc0, 0xb4, 0xaa, 0x8d, 0x49,
-	0xb2, 0x3e, 0x77, 0x70, 0xfe, 0x25, 0x31, 0x4b, 0xef, 0xf6, 0xd4, 0xc1, 0xde, 0x08, 0xf9, 0xb1,
-	0x32, 0x49, 0x5c, 0x99, 0x75, 0xb7, 0x6b, 0x73, 0xae, 0xce, 0xe4, 0x6b, 0x91, 0xcf, 0x27, 0x46,
-	0xc4, 0xed, 0xe9, 0x63, 0x9b, 0x16, 0x77, 0x79, 0x06, 0x57, 0x0b, 0x7b, 0x7a, 0x7e, 0x58, 0xbb,
-	0x34, 0x75, 0x82, 0xd2, 0xa2, 0xb6, 0xa7, 0x33, 0xb5, 0xa4, 0x07, 0xa5, 0x57, 0xec, 0xac, 0x58,
-	0x7d, 0x58, 0x7d, 0x89, 0x9a, 0xe3, 0xdf, 0x14, 0x6f, 0xc8, 0xcd, 0xca, 0xf3, 0x4e, 0x1b, 0xe0,
-	0x55, 0x19, 0xfa, 0xf4, 0xfd, 0xa2, 0x95, 0xcf, 0xd2, 0xed, 0x55, 0x9a, 0xa5, 0x3e, 0xfc, 0xe8,
-	0x39, 0xac, 0x0e, 0x68, 0x62, 0xd9, 0x28, 0x23, 0x8f, 0x40, 0x7f, 0xac, 0x0f, 0x33, 0x72, 0xec,
-	0xfc, 0xba, 0x13, 0x11, 0x3e, 0x1a, 0xf7, 0x45, 0x49, 0xee, 0x72, 0x14, 0x53, 0x76, 0x5b, 0xdd,
-	0x49, 0x4c, 0xed, 0x76, 0x51, 0x46, 0xcc, 0xbf, 0x2b, 0xfd, 0xba, 0x54, 0x7b, 0xf7, 0xbf, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0xe9, 0x7e, 0xca, 0xfb, 0x77, 0x11, 0x00, 0x00,
+	// 2563 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x3a, 0x5b, 0x6f, 0x1b, 0xc7,
+	0xd5, 0x59, 0xde, 0x24, 0x1e, 0x52, 0x14, 0xbd, 0x96, 0x64, 0x9a, 0x52, 0x6c, 0x67, 0xbf, 0xaf,
+	0x8d, 0xab, 0xc4, 0x92, 0x2c, 0x27, 0x46, 0x5b, 0x3b, 0x4d, 0x65, 0x89, 0xb6, 0x55, 0x59, 0xb6,
+	0xb2, 0x54, 0x7a, 0x7b, 0x28, 0x3b, 0x24, 0x47, 0xe4, 0x42, 0xcb, 0xdd, 0xcd, 0xce, 0x50, 0x86,
+	0x8a, 0xbe, 0x17, 0xe8, 0x53, 0x81, 0xa2, 0x2f, 0x45, 0x0a, 0x14, 0xe8, 0x4b, 0x7f, 0x43, 0x7f,
+	0x58, 0x9f, 0x8b, 0xb9, 0xee, 0x90, 0xbb, 0xb4, 0x44, 0xc3, 0x4f, 0x9c, 0x39, 0x73, 0xe6, 0xdc,
+	0xe7, 0x9c, 0x33, 0xb3, 0x84, 0xd5, 0x11, 0xea, 0x0d, 0xbd, 0x00, 0x6f, 0xcb, 0xdf, 0xad, 0x28,
+	0x0e, 0x69, 0x68, 0x2f, 0xc8, 0x69, 0xf3, 0xce, 0x20, 0x0c, 0x07, 0x3e, 0xde, 0xe6, 0xe0, 0xee,
+	0xf8, 0x6c, 0xbb, 0x3f, 0x8e, 0x11, 0xf5, 0xc2, 0x40, 0x20, 0x36, 0xd7, 0xa7, 0xd7, 0xf1, 0x28,
+	0xa2, 0x97, 0x72, 0xf1, 0xee, 0xf4, 0x22, 0xf5, 0x46, 0x98, 0x50, 0x34, 0x8a, 0x24, 0xc2, 0xcd,
+	0x5e, 0x38, 0x1a, 0x85, 0xc1, 0xb6, 0xf8, 0x11, 0x40, 0xe7, 0x37, 0xb0, 0xb4, 0xd7, 0x0d, 0x63,
+	0xda, 0xc6, 0xdf, 0x8d, 0x71, 0xd0, 0xc3, 0xf6, 0xe7, 0xb0, 0x38, 0xc2, 0x14, 0xf5, 0x11, 0x45,
+	0x0d, 0xeb, 0x9e, 0x75, 0xbf, 0xb2, 0x5b, 0xdf, 0x92, 0x3b, 0x8e, 0x25, 0xdc, 0xd5, 0x18, 0x76,
+	0x13, 0x16, 0x89, 0xdc, 0xd9, 0xc8, 0xdd, 0xb3, 0xee, 0x97, 0x5d, 0x3d, 0x77, 0x8e, 0x60, 0x75,
+	0x82, 0xb4, 0x8b, 0x49, 0x14, 0x06, 0x04, 0xdb, 0xbb, 0x8c, 0x05, 0x21, 0x68, 0x80, 0x49, 0xc3,
+	0xba, 0x97, 0xbf, 0x5f, 0xd9, 0x5d, 0xdb, 0x52, 0x16, 0x99, 0xdc, 0xa1, 0xf1, 0x9c, 0xc7, 0x50,
+	0x72, 0x71, 0x37, 0x0c, 0xe9, 0x7c, 0x02, 0x3a, 0x5f, 0x41, 0x4d, 0xec, 0xd3, 0xdc, 0x3f, 0x4b,
+	0x71, 0x5f, 0xd6, 0xdc, 0x25, 0x6a, 0xc2, 0xf6, 0x77, 0x50, 0x75, 0x31, 0xc1, 0xd4, 0x65, 0x12,
+	0x11, 0xca, 0xf4, 0x1d, 0xc4, 0xa8, 0x87, 0xcf, 0xc6, 0x3e, 0x67, 0xbe, 0xe8, 0xea, 0xb9, 0xbd,
+	0x06, 0xa5, 0x98, 0xef, 0xe7, 0x96, 0x58, 0x74, 0xe5, 0x8c, 0xed, 0x89, 0x62, 0x4c, 0x70, 0x7c,
+	0x81, 0x1b, 0xf9, 0x7b, 0x79, 0x66, 0x23, 0x35, 0x77, 0xbe, 0x84, 0x22, 0xa7, 0x3f, 0xa7, 0x56,
+	0x4f, 0x60, 0x49, 0x8a, 0x25, 0x95, 0xda, 0x4c, 0x29, 0x55, 0x33, 0x94, 0x62, 0x98, 0x89, 0x4e,
+	0xfb, 0xb0, 0xec, 0x8e, 0x83, 0x93, 0x21, 0x22, 0xd8, 0x50, 0x4b, 0xbb, 0xd1, 0x9a, 0x74, 0xa3,
+	0xbd, 0x02, 0xc5, 0x88, 0xe1, 0x4a, 0xff, 0x8a, 0x89, 0xf3, 0x63, 0x58, 0x54, 0x44, 0xe6, 0x94,
+	0x7d, 0x0f, 0xea, 0x09, 0x7b, 0x29, 0xfe, 0x83, 0x94, 0xf8, 0x37, 0x12, 0xf1, 0x15, 0x72, 0xa2,
+	0xc1, 0x5f, 0x2c, 0xb0, 0xdd, 0x71, 0x90, 0x04, 0xd6, 0xd5, 0x5a, 0x7c, 0x06, 0x45, 0x66, 0x73,
+	0xe1, 0x9b, 0xca, 0xee, 0xea, 0x94, 0x75, 0x04, 0x05, 0x57, 0xe0, 0xd8, 0x0f, 0x61, 0x61, 0x1c,
+	0x0d, 0x62, 0xd4, 0x67, 0x0e, 0x63, 0xe8, 0xb7, 0x34, 0xfa, 0xb7, 0x02, 0xae, 0x36, 0x28, 0x3c,
+	0xe7, 0x57, 0x50, 0x31, 0x24, 0xfa, 0x80, 0xa7, 0xe8, 0x05, 0xdc, 0x9c, 0x50, 0x55, 0x5a, 0x6c,
+	0x27, 0x65, 0xb1, 0x15, 0xd3, 0x62, 0x19, 0x27, 0xe8, 0x3b, 0xa8, 0x9d, 0xc4, 0xf8, 0xcc, 0xf7,
+	0x06, 0x43, 0xba, 0x3f, 0xc4, 0xbd, 0x73, 0xdb, 0x86, 0x42, 0x80, 0x46, 0xca, 0x56, 0x7c, 0x6c,
+	0x7f, 0x09, 0x8b, 0x2a, 0xe9, 0x48, 0x53, 0xdd, 0xde, 0x12, 0x89, 0x65, 0x4b, 0x25, 0x96, 0xad,
+	0x03, 0x89, 0xe0, 0x6a, 0x54, 0x16, 0x24, 0x38, 0x8e, 0xc3, 0x98, 0xdb, 0xab, 0xec, 0x8a, 0x89,
+	0xf3, 0xbd, 0x05, 0x65, 0xcd, 0xf3, 0xc3, 0xd9, 0xc4, 0xde, 0x86, 0x52, 0x8f, 0x69, 0x40, 0xf8,
+	0x79, 0x32, 0xdd, 0x33, 0xa9, 0xa1, 0x2b, 0xd1, 0x98, 0x78, 0x31, 0x46, 0xfd, 0xcb, 0x46, 0x81,
+	0x9f, 0x4c, 0x31, 0x71, 0xf6, 0xe1, 0x86, 0xc6, 0xd7, 0x86, 0xdd, 0x4a, 0x19, 0xd6, 0x4e, 0x53,
+	0x37, 0xcc, 0xfa, 0x1f, 0x0b, 0xe0, 0x14, 0x91, 0x73, 0x17, 0x93, 0xb1, 0x4f, 0x33, 0x6d, 0xba,
+	0x0d, 0x45, 0x42, 0x51, 0x4c, 0xaf, 0x36, 0xa8, 0xc0, 0x9b, 0x70, 0x42, 0xfe, 0x3d, 0x9c, 0x50,
+	0x30, 0x9c, 0xc0, 0x0c, 0xd9, 0xc7, 0x3c, 0x48, 0xfb, 0x8d, 0xa2, 0x30, 0xa4, 0x9a, 0x3b, 0xff,
+	0xb4, 0xa0, 0xa2, 0x4e, 0x22, 0x93, 0x5e, 0x4b, 0x6a, 0xbd, 0x87, 0xa4, 0x73, 0x84, 0xcb, 0x8f,
+	0xa0, 0x48, 0x11, 0xd1, 0xfe, 0xbb, 0xa9, 0x2d, 0x9c, 0x58, 0xd2, 0x15, 0x18, 0xce, 0x9f, 0x72,
+	0x50, 0x33, 0xa2, 0x7f, 0xec, 0x7f, 0xc8, 0x40, 0xda, 0x51, 0xfa, 0x0a, 0x2b, 0x37, 0x53, 0xb2,
+	0x9f, 0xaa, 0x1a, 0x9a, 0xa5, 0x70, 0xe1, 0x3d, 0x5c, 0x53, 0x34, 0x5d, 0xf3, 0x39, 0x94, 0x78,
+	0x36, 0x25, 0x8d, 0xd2, 0xd4, 0x11, 0x36, 0x9c, 0xe2, 0x4a, 0x1c, 0xe7, 0x18, 0xd6, 0x26, 0x0d,
+	0xa1, 0x63, 0xf6, 0x51, 0x2a, 0x66, 0x93, 0x13, 0x31, 0xb5, 0x25, 0x09, 0xdc, 0x18, 0x96, 0xd5,
+	0xda, 0x4b, 0x8f, 0xd0, 0x30, 0xbe, 0x9c, 0xd3, 0xb0, 0x0f, 0x61, 0x21, 0xe6, 0x44, 0x49, 0x23,
+	0xf7, 0x6e, 0xa6, 0x0a, 0xcf, 0x79, 0x03, 0xb7, 0xa6, 0x78, 0x6a, 0x1d, 0xbe, 0x48, 0xe9, 0xd0,
+	0x48, 0x91, 0x53, 0x7b, 0x12, 0x25, 0x96, 0x61, 0xa9, 0x75, 0x81, 0x03, 0x4a, 0x64, 0x42, 0x76,
+	0x5c, 0xa8, 0xb2, 0x18, 0x3a, 0x89, 0xc3, 0x41, 0x8c, 0x09, 0xb1, 0x1b, 0xb0, 0xd0, 0x1b, 0xc7,
+	0x31, 0x0e, 0x44, 0x4c, 0xe7, 0x5d, 0x35, 0x65, 0x2e, 0xa1, 0x21, 0x45, 0x3e, 0x0f, 0x8a, 0xbc,
+	0x2b, 0x26, 0xec, 0xfc, 0x8e, 0x03, 0x8f, 0xca, 0x3c, 0xc6, 0xc7, 0xce, 0xbf, 0xf3, 0xb0, 0xa4,
+	0x44, 0xe0, 0xdc, 0xe6, 0x34, 0xd4, 0x16, 0x14, 0xe8, 0x65, 0x24, 0xa2, 0xaf, 0xb6, 0xdb, 0x4c,
+	0xa9, 0xc5, 0x69, 0x9e, 0x5e, 0x46, 0xd8, 0xe5, 0x78, 0x13, 0x11, 0x9b, 0x9f, 0x55, 0x8d, 0x59,
+	0xf0, 0x15, 0x65, 0x35, 0xb6, 0xef, 0x42, 0x85, 0x0f, 0x3a, 0x42, 0xa3, 0x22, 0x5f, 0x03, 0x0e,
+	0x3a, 0x55, 0x6a, 0xb1, 0xe3, 0xd4, 0x28, 0xf1, 0x15, 0x3e, 0xb6, 0x3f, 0x06, 0x60, 0xbf, 0x72,
+	0xcf, 0x02, 0x5f, 0x29, 0x33, 0x88, 0xd8, 0xb2, 0x0e, 0x7c, 0xd2, 0xe1, 0xe9, 0x6c, 0x51, 0x88,
+	0xc1, 0x00, 0xaf, 0x59, 0x4a, 0x7b, 0x04, 0x0b, 0xd8, 0x47, 0x11, 0xc1, 0xfd, 0x46, 0xf9, 0xaa,
+	0x53, 0xa0, 0x30, 0x93, 0x43, 0x00, 0xe6, 0x21, 0x78, 0xc8, 0xda, 0x23, 0xe1, 0xad, 0x46, 0x65,
+	0xaa, 0x38, 0x9b, 0xae, 0x74, 0x17, 0x23, 0xc3, 0xa9, 0x32, 0x02, 0x1a, 0x55, 0x4e, 0x4a, 0x4d,
+	0x59, 0x5b, 0xd2, 0x1e, 0x8e, 0x69, 0x3f, 0x7c, 0x1b, 0xcc, 0xdf, 0x96, 0xa8, 0x9d, 0xd7, 0x6a,
+	0x4b, 0x34, 0x72, 0x12, 0x8c, 0xbf, 0x80, 0x9b, 0x6d, 0x8a, 0xa3, 0xe9, 0xb6, 0xe4, 0x11, 0x94,
+	0x50, 0x8f, 0x27, 0x0c, 0x8b, 0x07, 0xc0, 0x7a, 0x42, 0xc3, 0xc0, 0xde, 0xe3, 0x28, 0xae, 0x44,
+	0x75, 0x9e, 0x42, 0xd5, 0x5c, 0x9d, 0x53, 0x99, 0x43, 0x58, 0x99, 0x94, 0x44, 0x2a, 0xf4, 0x30,
+	0xa5, 0xd0, 0x6a, 0xa6, 0x30, 0x86, 0x52, 0x4f, 0xa1, 0xae, 0xa0, 0x27, 0x3e, 0x0a, 0x98, 0x47,
+	0x32, 0x8b, 0xdc, 0x0a, 0x14, 0x7d, 0xd4, 0xc5, 0xbe, 0x6a, 0x13, 0xf9, 0xc4, 0xb9, 0x80, 0x1b,
+	0xe6, 0x6e, 0xd1, 0x2f, 0xae, 0x41, 0x29, 0x18, 0x8f, 0xba, 0x38, 0xe6, 0x04, 0x8a, 0xae, 0x9c,
+	0xb1, 0xea, 0x23, 0xaa, 0x82, 0x48, 0x27, 0xb7, 0x53, 0x07, 0x45, 0x09, 0x20, 0x6b, 0x03, 0x8b,
+	0x83, 0xf0, 0x02, 0xc7, 0x3e, 0x8a, 0xf8, 0x39, 0x59, 0x74, 0xd5, 0x94, 0xf5, 0xed, 0xe6, 0xa6,
+	0x77, 0xb6, 0x86, 0xbb, 0x3a, 0x0b, 0x0b, 0xbe, 0xcd, 0x4c, 0xbe, 0x22, 0x23, 0xab, 0x5c, 0x7c,
+	0x06, 0x65, 0xb5, 0x48, 0xe6, 0xcc, 0x06, 0x9f, 0x41, 0x31, 0xf2, 0x51, 0xa0, 0xb8, 0xad, 0x66,
+	0x72, 0x73, 0x05, 0x0e, 0x6b, 0x51, 0x34, 0x9f, 0x6b, 0xb5, 0x28, 0x09, 0x76, 0xe2, 0xc2, 0xbf,
+	0x59, 0x50, 0x9b, 0xec, 0x5b, 0x99, 0xb7, 0xbc, 0x11, 0x3b, 0x3f, 0xc2, 0x18, 0x62, 0x32, 0x71,
+	0x53, 0x11, 0x77, 0x18, 0x3d, 0x67, 0x3b, 0x08, 0x65, 0x3b, 0x84, 0xa5, 0xc5, 0xc4, 0xde, 0x80,
+	0x32, 0xf1, 0x06, 0x01, 0xa2, 0xe3, 0x58, 0xa4, 0xa4, 0xaa, 0x9b, 0x00, 0x58, 0x86, 0x89, 0xc6,
+	0x5d, 0xdf, 0xeb, 0x75, 0xce, 0xf1, 0xa5, 0x2c, 0x7d, 0x65, 0x01, 0x39, 0xc2, 0x97, 0xce, 0x21,
+	0x2c, 0x48, 0xb1, 0xe6, 0x34, 0x61, 0x1d, 0xf2, 0xa8, 0x77, 0x2e, 0x23, 0x8d, 0x0d, 0x9d, 0xaf,
+	0x61, 0x59, 0x6b, 0x28, 0xad, 0xf4, 0x79, 0xca, 0x4a, 0xf5, 0x54, 0x17, 0x9f, 0xd8, 0x68, 0x04,
+	0x95, 0x36, 0x8e, 0x2f, 0xbc, 0x1e, 0x7e, 0xe5, 0x91, 0x79, 0x13, 0xfc, 0x0e, 0x8b, 0x2e, 0xbe,
+	0x59, 0x79, 0x75, 0xc5, 0x70, 0x08, 0x5f, 0x38, 0x0c, 0xce, 0x42, 0x57, 0x63, 0xb1, 0xae, 0xde,
+	0x60, 0x77, 0xad, 0xae, 0xde, 0xc4, 0x4f, 0xe4, 0xfe, 0xab, 0x05, 0x15, 0x83, 0x85, 0x5d, 0x83,
+	0x9c, 0xd7, 0x97, 0x5e, 0xcd, 0x79, 0x7d, 0xe9, 0x36, 0xaa, 0x6f, 0x6f, 0x7c, 0x62, 0x6f, 0x41,
+	0x09, 0xf3, 0xb2, 0x29, 0x1b, 0x9f, 0xb5, 0x69, 0x2e, 0xb2, 0xa8, 0x4a, 0x2c, 0x86, 0x3f, 0xc4,
+	0xc8, 0xa7, 0xc3, 0x46, 0x21, 0x1b, 0xff, 0x25, 0x5f, 0x75, 0x25, 0x96, 0xf3, 0x33, 0x58, 0x92,
+	0x0b, 0x82, 0x90, 0xfd, 0x40, 0x33, 0x4c, 0xa5, 0x1d, 0x03, 0x4f, 0xf1, 0x73, 0xba, 0x50, 0x35,
+	0xe1, 0xcc, 0xe1, 0x23, 0x32, 0x90, 0x6a, 0xb1, 0xe1, 0x0c, 0xbd, 0x36, 0x21, 0x47, 0xc9, 0x35,
+	0x9a, 0xb9, 0x1c, 0x25, 0xce, 0xbf, 0x2c, 0x58, 0x9a, 0x90, 0x9e, 0xa5, 0x93, 0x71, 0x70, 0x1e,
+	0x84, 0x6f, 0x03, 0x79, 0xb7, 0x57, 0x53, 0xb6, 0x22, 0x34, 0xbb, 0x94, 0xe7, 0x42, 0x4d, 0xed,
+	0x4f, 0xa0, 0xea, 0x23, 0x42, 0x3b, 0xaa, 0x1e, 0x89, 0x7a, 0x5d, 0x61, 0xb0, 0x63, 0x01, 0xb2,
+	0x9f, 0x00, 0x9f, 0x76, 0x7a, 0x43, 0x14, 0x0c, 0x70, 0xa3, 0x70, 0xa5, 0x74, 0xc0, 0xd0, 0xf7,
+	0x39, 0xb6, 0xf3, 0x03, 0x1d, 0x28, 0x6d, 0x8a, 0x62, 0xfd, 0x0e, 0x31, 0xe5, 0x66, 0xe7, 0x04,
+	0xaa, 0x26, 0xda, 0x9c, 0xf1, 0x6b, 0x43, 0x21, 0xc6, 0x24, 0x92, 0xb6, 0xe4, 0x63, 0x5e, 0x42,
+	0x26, 0x18, 0x5f, 0xa7, 0x84, 0x98, 0x1b, 0x92, 0x18, 0xfd, 0x7f, 0xb0, 0xf5, 0x4a, 0x18, 0xcd,
+	0x52, 0xe1, 0x0d, 0x54, 0x0c, 0xac, 0x0f, 0xa0, 0xc1, 0x0b, 0xb8, 0x39, 0xc1, 0xf6, 0xfa, 0x67,
+	0x8c, 0xe3, 0x27, 0xf2, 0x7f, 0x0a, 0xab, 0x72, 0xc1, 0xc5, 0xe4, 0x5d, 0x5e, 0x70, 0xa1, 0x36,
+	0x89, 0xf8, 0x01, 0xb4, 0xe0, 0x5d, 0xff, 0x24, 0xf3, 0x6b, 0x75, 0xfd, 0x13, 0x5b, 0x12, 0x5d,
+	0x1c, 0xd6, 0x57, 0xcc, 0x56, 0xe1, 0xa7, 0xb9, 0x86, 0xe5, 0x7c, 0x0a, 0x4b, 0x93, 0x3e, 0x57,
+	0x72, 0x59, 0x89, 0x5c, 0x1c, 0xf1, 0x13, 0xa8, 0xbc, 0xc3, 0xa3, 0x1c, 0xe5, 0x87, 0x50, 0x15,
+	0x28, 0x57, 0x90, 0xda, 0x84, 0xca, 0x7e, 0x18, 0x5d, 0x2a, 0x52, 0xeb, 0x50, 0x8e, 0xc3, 0x90,
+	0x76, 0x22, 0x44, 0x87, 0xaa, 0x60, 0x33, 0xc0, 0x09, 0xa2, 0x43, 0xa7, 0x0f, 0x15, 0x91, 0x35,
+	0x05, 0x2e, 0x23, 0xc9, 0x5e, 0xdd, 0x14, 0x49, 0xf6, 0xe6, 0xd6, 0x60, 0x77, 0x93, 0xde, 0x38,
+	0x26, 0xaa, 0x90, 0xa9, 0xa9, 0xfd, 0x29, 0x2c, 0x8b, 0xa1, 0x17, 0x06, 0x9d, 0x3e, 0x8e, 0xe8,
+	0x90, 0x9f, 0xd9, 0xa2, 0x5b, 0xd3, 0xe0, 0x03, 0x06, 0x75, 0xfe, 0x6b, 0xc1, 0xe2, 0x73, 0xcf,
+	0x17, 0x69, 0x75, 0x6e, 0x3f, 0xf2, 0xfe, 0x28, 0x67, 0xf4, 0x47, 0x36, 0x14, 0x88, 0xf7, 0x07,
+	0x91, 0x20, 0xf2, 0x2e, 0x1f, 0x33, 0xd8, 0x28, 0xec, 0x8b, 0x94, 0xb0, 0xe4, 0xf2, 0x31, 0xab,
+	0xc1, 0xa3, 0xb0, 0xef, 0x9d, 0x79, 0xf2, 0xba, 0x9e, 0x77, 0xf5, 0xdc, 0x5e, 0x85, 0x92, 0x47,
+	0x3a, 0x7d, 0x2f, 0xe6, 0x7d, 0xfc, 0xa2, 0x5b, 0xf4, 0xc8, 0x81, 0x17, 0x27, 0x7d, 0xf5, 0x82,
+	0xd9, 0x57, 0xdb, 0x50, 0xf0, 0xbd, 0xe0, 0x5c, 0xb6, 0xee, 0x7c, 0x6c, 0xff, 0x1f, 0x2c, 0xc5,
+	0xd8, 0x47, 0xd4, 0xbb, 0xc0, 0xa2, 0xaf, 0x2f, 0xf3, 0xc5, 0xaa, 0x02, 0xb2, 0xde, 0xde, 0xf9,
+	0x3d, 0x94, 0x8e, 0xc3, 0x31, 0xcb, 0xda, 0xf3, 0x69, 0x7d, 0x5f, 0xa4, 0x64, 0x55, 0x02, 0x93,
+	0x9e, 0x84, 0x53, 0x6b, 0x53, 0x44, 0x45, 0x9a, 0x26, 0xec, 0x51, 0x56, 0x70, 0xb8, 0xd6, 0xa3,
+	0xac, 0x44, 0x4d, 0x62, 0xf8, 0x8f, 0x50, 0xd6, 0x24, 0xed, 0x3b, 0x00, 0x67, 0x9e, 0x8f, 0xc9,
+	0x25, 0xa1, 0x78, 0x24, 0x63, 0xc0, 0x80, 0x68, 0xbb, 0x33, 0x5f, 0x14, 0xa4, 0xdd, 0x37, 0xa0,
+	0x8c, 0x2e, 0x90, 0xe7, 0xa3, 0xae, 0x2f, 0x1c, 0x52, 0x70, 0x13, 0x00, 0xeb, 0x5a, 0x46, 0x8c,
+	0x3c, 0xee, 0x77, 0xe4, 0x25, 0xbf, 0xec, 0x96, 0x25, 0xe4, 0x4d, 0xe0, 0xfc, 0xdd, 0x82, 0x85,
+	0x5f, 0x62, 0x1e, 0x28, 0x73, 0xdf, 0x03, 0x17, 0x2e, 0xc4, 0x46, 0xf9, 0x56, 0x92, 0x24, 0x1e,
+	0x49, 0x90, 0x77, 0x09, 0x0a, 0x89, 0xdf, 0x8c, 0x7c, 0x44, 0xcf, 0xc2, 0x78, 0x24, 0x6b, 0x5a,
+	0x92, 0x6a, 0x4f, 0xe4, 0x02, 0xdf, 0xa1, 0xd1, 0x58, 0x1f, 0x24, 0x49, 0x5d, 0xab, 0x0f, 0x52,
+	0xb8, 0x89, 0x6d, 0xff, 0x6c, 0x41, 0xc5, 0x10, 0x86, 0x55, 0x5e, 0x8a, 0x74, 0xe5, 0xa5, 0x68,
+	0xc0, 0x20, 0x64, 0x88, 0x54, 0xf3, 0x45, 0x86, 0x88, 0xc5, 0x5f, 0x77, 0xec, 0xf9, 0xea, 0xd2,
+	0x2c, 0x26, 0xcc, 0x8c, 0x83, 0xb0, 0xa3, 0x14, 0x96, 0x66, 0x1c, 0x84, 0xca, 0x74, 0x35, 0xc8,
+	0x85, 0x44, 0xf6, 0x84, 0xb9, 0x90, 0x30, 0x3f, 0xa1, 0xb8, 0x37, 0xe4, 0x91, 0x5d, 0x76, 0xf9,
+	0xd8, 0x79, 0x0c, 0x55, 0x53, 0xcf, 0xcc, 0x7b, 0x87, 0x3a, 0x43, 0xf2, 0xac, 0xb1, 0x31, 0x2b,
+	0xed, 0x95, 0x57, 0xe1, 0x40, 0x3d, 0x0a, 0x30, 0x7f, 0x33, 0x5c, 0x12, 0x21, 0xdd, 0xfe, 0x27,
+	0x00, 0x99, 0xb6, 0x72, 0xba, 0x65, 0xda, 0x86, 0x52, 0x3f, 0xf6, 0x2e, 0xb0, 0x78, 0xcc, 0xac,
+	0xed, 0xde, 0x52, 0x2e, 0xdd, 0x0f, 0x03, 0x8a, 0xbc, 0x00, 0xc7, 0x07, 0x7c, 0xd9, 0x95, 0x68,
+	0xec, 0x3e, 0x73, 0x16, 0xfa, 0x7e, 0xf8, 0x56, 0x3e, 0x2f, 0xca, 0x99, 0xb8, 0x60, 0x7b, 0x7e,
+	0xc7, 0xf7, 0x02, 0x4c, 0xe4, 0xa5, 0xbc, 0xcc, 0x20, 0xaf, 0x18, 0x80, 0x65, 0x4f, 0x17, 0xa3,
+	0xbe, 0x91, 0xc6, 0x8c, 0x6c, 0xc7, 0xc7, 0x9b, 0xff, 0xb0, 0x92, 0xfe, 0x5f, 0xbf, 0x12, 0xd8,
+	0x2b, 0x50, 0x6f, 0xb7, 0xbe, 0xf9, 0xb6, 0xf5, 0x7a, 0xbf, 0xd5, 0x69, 0x9f, 0xee, 0xb9, 0xa7,
+	0xad, 0x83, 0xfa, 0x47, 0xf6, 0x0d, 0x58, 0x3a, 0x79, 0xb9, 0xd7, 0x4e, 0x40, 0x96, 0x5d, 0x87,
+	0xea, 0xe9, 0x5e, 0xfb, 0x48, 0x43, 0x72, 0x0c, 0x89, 0x43, 0x9e, 0x1f, 0xbe, 0x3e, 0x6c, 0xbf,
+	0x6c, 0x1d, 0xd4, 0xf3, 0xf6, 0x2a, 0xdc, 0xd0, 0xd4, 0x34, 0xb8, 0xa0, 0x31, 0x4f, 0xdc, 0x37,
+	0x2f, 0xdc, 0x56, 0xbb, 0x5d, 0x2f, 0x6a, 0x72, 0xc7, 0xad, 0x76, 0x7b, 0xef, 0x45, 0xab, 0x5e,
+	0xda, 0x7c, 0x0e, 0x76, 0xfa, 0x0e, 0x6b, 0x2f, 0x41, 0xb9, 0x7d, 0xda, 0x3a, 0xe9, 0xbc, 0x6e,
+	0xfd, 0xfa, 0xb4, 0xfe, 0x91, 0xbd, 0x0c, 0x15, 0x3e, 0x6d, 0xbd, 0xde, 0x7b, 0xf6, 0xaa, 0x25,
+	0xc4, 0xe2, 0x80, 0x83, 0xc3, 0x36, 0x87, 0xe4, 0x76, 0xbf, 0xaf, 0x42, 0xed, 0x58, 0xc4, 0xa4,
+	0xac, 0x5c, 0xf6, 0x8b, 0xe9, 0x0f, 0x53, 0x6b, 0xa9, 0x8e, 0xa9, 0xc5, 0xbe, 0x7e, 0x35, 0xef,
+	0xcc, 0xf8, 0x76, 0x94, 0xc4, 0x7f, 0x81, 0x55, 0x16, 0x3b, 0x39, 0x6c, 0x46, 0xa1, 0x69, 0x56,
+	0x95, 0x73, 0x0f, 0x10, 0x45, 0x3b, 0x96, 0xfd, 0x73, 0xa8, 0x8a, 0x96, 0xb5, 0x4d, 0x63, 0x8c,
+	0x46, 0x76, 0xd2, 0xe9, 0x4e, 0xbc, 0x33, 0x35, 0xd7, 0xb2, 0x5f, 0x71, 0x76, 0x2c, 0xfb, 0x0b,
+	0x80, 0xa3, 0x71, 0x17, 0xf7, 0xc2, 0xe0, 0xcc, 0x1b, 0xcc, 0x94, 0x7a, 0x9a, 0xef, 0x43, 0x28,
+	0xf0, 0x8b, 0x47, 0x22, 0xa5, 0x51, 0xe2, 0x9a, 0xc9, 0xb3, 0x83, 0xaa, 0x48, 0x3b, 0x16, 0x53,
+	0x8c, 0x05, 0xb9, 0xb9, 0x25, 0x89, 0xf9, 0x14, 0x83, 0x9f, 0xe8, 0xac, 0x3e, 0x4b, 0xa4, 0x5b,
+	0xd3, 0x19, 0x57, 0x59, 0xf0, 0xc0, 0x7c, 0xc5, 0x5f, 0xcf, 0xfc, 0xcc, 0x20, 0x99, 0x36, 0x33,
+	0x9e, 0xca, 0x0d, 0x3f, 0xb0, 0x70, 0x37, 0xc4, 0x35, 0xa2, 0x3f, 0x4b, 0x5c, 0xf9, 0xbd, 0xef,
+	0x6a, 0x71, 0xa7, 0x3e, 0xf0, 0x3d, 0x56, 0xdf, 0xd4, 0xb2, 0x3f, 0xf2, 0x34, 0xd7, 0xa6, 0xc1,
+	0x72, 0xdf, 0xd7, 0xc6, 0x27, 0xad, 0x46, 0xfa, 0xf3, 0x93, 0xdc, 0x7d, 0x3b, 0x63, 0x45, 0x12,
+	0x78, 0x39, 0xf9, 0x0d, 0xe8, 0x9d, 0x96, 0xda, 0xc8, 0x5e, 0x94, 0x94, 0x8e, 0xd2, 0x6f, 0xb3,
+	0xb3, 0xcc, 0x70, 0x6f, 0xe6, 0x2b, 0xa9, 0x22, 0x76, 0x98, 0x7a, 0x40, 0x9f, 0x45, 0xeb, 0xee,
+	0xac, 0x07, 0xdc, 0xc4, 0x44, 0xc6, 0xb3, 0xc7, 0x2c, 0x2a, 0xcd, 0x8c, 0xc7, 0x08, 0x45, 0x60,
+	0x7f, 0xf2, 0x9a, 0x3d, 0x8b, 0xc4, 0x46, 0xe6, 0xad, 0x57, 0x11, 0xf9, 0x26, 0xd5, 0x66, 0xdf,
+	0x99, 0xd5, 0xf8, 0x4a, 0x6b, 0xdf, 0x9d, 0xb9, 0xae, 0x0d, 0x3e, 0x79, 0x7f, 0xda, 0xc8, 0xbe,
+	0xd3, 0x48, 0x72, 0x1f, 0xcf, 0x58, 0x4d, 0xe2, 0xc0, 0xbc, 0xc9, 0xac, 0x67, 0x5e, 0x2f, 0x52,
+	0x71, 0x90, 0x75, 0x57, 0xf9, 0xca, 0x78, 0xce, 0x9c, 0x65, 0xab, 0xdb, 0xe9, 0x27, 0x49, 0x53,
+	0x2b, 0xf3, 0x11, 0x71, 0x23, 0xfb, 0xb1, 0x2f, 0xad, 0x55, 0xd6, 0xdb, 0xe1, 0xd3, 0xe4, 0xb5,
+	0x66, 0xd6, 0xe7, 0xd0, 0x66, 0x23, 0xbd, 0x20, 0x77, 0x3f, 0x49, 0x9a, 0xa6, 0x59, 0x8a, 0x34,
+	0x52, 0x6d, 0x89, 0xdc, 0xfc, 0xec, 0x08, 0x96, 0x7b, 0xe1, 0x48, 0x2f, 0xa3, 0xc8, 0x7b, 0x06,
+	0xb2, 0x5c, 0xec, 0x45, 0xde, 0x89, 0xf5, 0xdb, 0xcd, 0x81, 0x47, 0x87, 0xe3, 0x2e, 0xcb, 0x21,
+	0xdb, 0x14, 0xf9, 0x21, 0x79, 0x20, 0xba, 0x3f, 0x22, 0x66, 0xdb, 0x28, 0xf2, 0xd4, 0x7f, 0x2e,
+	0xba, 0x25, 0xce, 0xf6, 0xd1, 0xff, 0x02, 0x00, 0x00, 0xff, 0xff, 0xc9, 0x7f, 0xa3, 0x9f, 0x8d,
+	0x21, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -2315,19 +4090,28 @@ const _ = grpc.SupportPackageIsVersion4
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type MachineServiceClient interface {
+	AbortSequence(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*AbortSequenceResponse, error)
 	Copy(ctx context.Context, in *CopyRequest, opts ...grpc.CallOption) (MachineService_CopyClient, error)
+	EventsStream(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (MachineService_EventsStreamClient, error)
 	Kubeconfig(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (MachineService_KubeconfigClient, error)
 	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (MachineService_ListClient, error)
 	Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (MachineService_LogsClient, error)
 	Mounts(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*MountsResponse, error)
+	Preflight(ctx context.Context, in *RunSequenceRequest, opts ...grpc.CallOption) (*PreflightResponse, error)
 	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (MachineService_ReadClient, error)
 	Reboot(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*RebootResponse, error)
 	Reset(ctx context.Context, in *ResetRequest, opts ...grpc.CallOption) (*ResetResponse, error)
+	RunPhase(ctx context.Context, in *RunPhaseRequest, opts ...grpc.CallOption) (*RunPhaseResponse, error)
+	RunSequence(ctx context.Context, in *RunSequenceRequest, opts ...grpc.CallOption) (*RunSequenceResponse, error)
+	SequenceHistory(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*SequenceHistoryResponse, error)
+	SequenceResult(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*SequenceResultResponse, error)
+	Sequences(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*SequencesResponse, error)
 	ServiceList(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ServiceListResponse, error)
 	ServiceRestart(ctx context.Context, in *ServiceRestartRequest, opts ...grpc.CallOption) (*ServiceRestartResponse, error)
 	ServiceStart(ctx context.Context, in *ServiceStartRequest, opts ...grpc.CallOption) (*ServiceStartResponse, error)
 	ServiceStop(ctx context.Context, in *ServiceStopRequest, opts ...grpc.CallOption) (*ServiceStopResponse, error)
 	Shutdown(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ShutdownResponse, error)
+	StepSequence(ctx context.Context, in *StepSequenceRequest, opts ...grpc.CallOption) (*StepSequenceResponse, error)
 	Upgrade(ctx context.Context, in *UpgradeRequest, opts ...grpc.CallOption) (*UpgradeResponse, error)
 	Version(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*VersionResponse, error)
 }
@@ -2340,6 +4124,15 @@ func NewMachineServiceClient(cc *grpc.ClientConn) MachineServiceClient {
 	return &machineServiceClient{cc}
 }
 
+func (c *machineServiceClient) AbortSequence(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*AbortSequenceResponse, error) {
+	out := new(AbortSequenceResponse)
+	err := c.cc.Invoke(ctx, "/machine.MachineService/AbortSequence", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *machineServiceClient) Copy(ctx context.Context, in *CopyRequest, opts ...grpc.CallOption) (MachineService_CopyClient, error) {
 	stream, err := c.cc.NewStream(ctx, &_MachineService_serviceDesc.Streams[0], "/machine.MachineService/Copy", opts...)
 	if err != nil {
@@ -2372,8 +4165,40 @@ func (x *machineServiceCopyClient) Recv() (*common.Data, error) {
 	return m, nil
 }
 
+func (c *machineServiceClient) EventsStream(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (MachineService_EventsStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_MachineService_serviceDesc.Streams[1], "/machine.MachineService/EventsStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &machineServiceEventsStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MachineService_EventsStreamClient interface {
+	Recv() (*SequenceEvent, error)
+	grpc.ClientStream
+}
+
+type machineServiceEventsStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *machineServiceEventsStreamClient) Recv() (*SequenceEvent, error) {
+	m := new(SequenceEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *machineServiceClient) Kubeconfig(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (MachineService_KubeconfigClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_MachineService_serviceDesc.Streams[1], "/machine.MachineService/Kubeconfig", opts...)
+	stream, err := c.cc.NewStream(ctx, &_MachineService_serviceDesc.Streams[2], "/machine.MachineService/Kubeconfig", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -2405,7 +4230,7 @@ func (x *machineServiceKubeconfigClient) Recv() (*common.Data, error) {
 }
 
 func (c *machineServiceClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (MachineService_ListClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_MachineService_serviceDesc.Streams[2], "/machine.MachineService/List", opts...)
+	stream, err := c.cc.NewStream(ctx, &_MachineService_serviceDesc.Streams[3], "/machine.MachineService/List", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -2437,7 +4262,7 @@ func (x *machineServiceListClient) Recv() (*FileInfo, error) {
 }
 
 func (c *machineServiceClient) Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (MachineService_LogsClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_MachineService_serviceDesc.Streams[3], "/machine.MachineService/Logs", opts...)
+	stream, err := c.cc.NewStream(ctx, &_MachineService_serviceDesc.Streams[4], "/machine.MachineService/Logs", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -2477,8 +4302,17 @@ func (c *machineServiceClient) Mounts(ctx context.Context, in *empty.Empty, opts
 	return out, nil
 }
 
+func (c *machineServiceClient) Preflight(ctx context.Context, in *RunSequenceRequest, opts ...grpc.CallOption) (*PreflightResponse, error) {
+	out := new(PreflightResponse)
+	err := c.cc.Invoke(ctx, "/machine.MachineService/Preflight", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *machineServiceClient) Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (MachineService_ReadClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_MachineService_serviceDesc.Streams[4], "/machine.MachineService/Read", opts...)
+	stream, err := c.cc.NewStream(ctx, &_MachineService_serviceDesc.Streams[5], "/machine.MachineService/Read", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -2527,6 +4361,51 @@ func (c *machineServiceClient) Reset(ctx context.Context, in *ResetRequest, opts
 	return out, nil
 }
 
+func (c *machineServiceClient) RunPhase(ctx context.Context, in *RunPhaseRequest, opts ...grpc.CallOption) (*RunPhaseResponse, error) {
+	out := new(RunPhaseResponse)
+	err := c.cc.Invoke(ctx, "/machine.MachineService/RunPhase", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *machineServiceClient) RunSequence(ctx context.Context, in *RunSequenceRequest, opts ...grpc.CallOption) (*RunSequenceResponse, error) {
+	out := new(RunSequenceResponse)
+	err := c.cc.Invoke(ctx, "/machine.MachineService/RunSequence", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *machineServiceClient) SequenceHistory(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*SequenceHistoryResponse, error) {
+	out := new(SequenceHistoryResponse)
+	err := c.cc.Invoke(ctx, "/machine.MachineService/SequenceHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *machineServiceClient) SequenceResult(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*SequenceResultResponse, error) {
+	out := new(SequenceResultResponse)
+	err := c.cc.Invoke(ctx, "/machine.MachineService/SequenceResult", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *machineServiceClient) Sequences(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*SequencesResponse, error) {
+	out := new(SequencesResponse)
+	err := c.cc.Invoke(ctx, "/machine.MachineService/Sequences", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *machineServiceClient) ServiceList(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ServiceListResponse, error) {
 	out := new(ServiceListResponse)
 	err := c.cc.Invoke(ctx, "/machine.MachineService/ServiceList", in, out, opts...)
@@ -2572,6 +4451,15 @@ func (c *machineServiceClient) Shutdown(ctx context.Context, in *empty.Empty, op
 	return out, nil
 }
 
+func (c *machineServiceClient) StepSequence(ctx context.Context, in *StepSequenceRequest, opts ...grpc.CallOption) (*StepSequenceResponse, error) {
+	out := new(StepSequenceResponse)
+	err := c.cc.Invoke(ctx, "/machine.MachineService/StepSequence", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *machineServiceClient) Upgrade(ctx context.Context, in *UpgradeRequest, opts ...grpc.CallOption) (*UpgradeResponse, error) {
 	out := new(UpgradeResponse)
 	err := c.cc.Invoke(ctx, "/machine.MachineService/Upgrade", in, out, opts...)
@@ -2592,19 +4480,28 @@ func (c *machineServiceClient) Version(ctx context.Context, in *empty.Empty, opt
 
 // MachineServiceServer is the server API for MachineService service.
 type MachineServiceServer interface {
+	AbortSequence(context.Context, *empty.Empty) (*AbortSequenceResponse, error)
 	Copy(*CopyRequest, MachineService_CopyServer) error
+	EventsStream(*EventsRequest, MachineService_EventsStreamServer) error
 	Kubeconfig(*empty.Empty, MachineService_KubeconfigServer) error
 	List(*ListRequest, MachineService_ListServer) error
 	Logs(*LogsRequest, MachineService_LogsServer) error
 	Mounts(context.Context, *empty.Empty) (*MountsResponse, error)
+	Preflight(context.Context, *RunSequenceRequest) (*PreflightResponse, error)
 	Read(*ReadRequest, MachineService_ReadServer) error
 	Reboot(context.Context, *empty.Empty) (*RebootResponse, error)
 	Reset(context.Context, *ResetRequest) (*ResetResponse, error)
+	RunPhase(context.Context, *RunPhaseRequest) (*RunPhaseResponse, error)
+	RunSequence(context.Context, *RunSequenceRequest) (*RunSequenceResponse, error)
+	SequenceHistory(context.Context, *empty.Empty) (*SequenceHistoryResponse, error)
+	SequenceResult(context.Context, *empty.Empty) (*SequenceResultResponse, error)
+	Sequences(context.Context, *empty.Empty) (*SequencesResponse, error)
 	ServiceList(context.Context, *empty.Empty) (*ServiceListResponse, error)
 	ServiceRestart(context.Context, *ServiceRestartRequest) (*ServiceRestartResponse, error)
 	ServiceStart(context.Context, *ServiceStartRequest) (*ServiceStartResponse, error)
 	ServiceStop(context.Context, *ServiceStopRequest) (*ServiceStopResponse, error)
 	Shutdown(context.Context, *empty.Empty) (*ShutdownResponse, error)
+	StepSequence(context.Context, *StepSequenceRequest) (*StepSequenceResponse, error)
 	Upgrade(context.Context, *UpgradeRequest) (*UpgradeResponse, error)
 	Version(context.Context, *empty.Empty) (*VersionResponse, error)
 }
@@ -2613,6 +4510,24 @@ func RegisterMachineServiceServer(s *grpc.Server, srv MachineServiceServer) {
 	s.RegisterService(&_MachineService_serviceDesc, srv)
 }
 
+func _MachineService_AbortSequence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).AbortSequence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/machine.MachineService/AbortSequence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).AbortSequence(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MachineService_Copy_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(CopyRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -2634,6 +4549,27 @@ func (x *machineServiceCopyServer) Send(m *common.Data) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _MachineService_EventsStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MachineServiceServer).EventsStream(m, &machineServiceEventsStreamServer{stream})
+}
+
+type MachineService_EventsStreamServer interface {
+	Send(*SequenceEvent) error
+	grpc.ServerStream
+}
+
+type machineServiceEventsStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *machineServiceEventsStreamServer) Send(m *SequenceEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _MachineService_Kubeconfig_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(empty.Empty)
 	if err := stream.RecvMsg(m); err != nil {
@@ -2715,6 +4651,24 @@ func _MachineService_Mounts_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MachineService_Preflight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunSequenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).Preflight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/machine.MachineService/Preflight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).Preflight(ctx, req.(*RunSequenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MachineService_Read_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(ReadRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -2772,6 +4726,96 @@ func _MachineService_Reset_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MachineService_RunPhase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunPhaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).RunPhase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/machine.MachineService/RunPhase",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).RunPhase(ctx, req.(*RunPhaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MachineService_RunSequence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunSequenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).RunSequence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/machine.MachineService/RunSequence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).RunSequence(ctx, req.(*RunSequenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MachineService_SequenceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).SequenceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/machine.MachineService/SequenceHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).SequenceHistory(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MachineService_SequenceResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).SequenceResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/machine.MachineService/SequenceResult",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).SequenceResult(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MachineService_Sequences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).Sequences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/machine.MachineService/Sequences",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).Sequences(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MachineService_ServiceList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(empty.Empty)
 	if err := dec(in); err != nil {
@@ -2862,6 +4906,24 @@ func _MachineService_Shutdown_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MachineService_StepSequence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StepSequenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).StepSequence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/machine.MachineService/StepSequence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).StepSequence(ctx, req.(*StepSequenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MachineService_Upgrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpgradeRequest)
 	if err := dec(in); err != nil {
@@ -2902,10 +4964,18 @@ var _MachineService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "machine.MachineService",
 	HandlerType: (*MachineServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AbortSequence",
+			Handler:    _MachineService_AbortSequence_Handler,
+		},
 		{
 			MethodName: "Mounts",
 			Handler:    _MachineService_Mounts_Handler,
 		},
+		{
+			MethodName: "Preflight",
+			Handler:    _MachineService_Preflight_Handler,
+		},
 		{
 			MethodName: "Reboot",
 			Handler:    _MachineService_Reboot_Handler,
@@ -2914,6 +4984,26 @@ var _MachineService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Reset",
 			Handler:    _MachineService_Reset_Handler,
 		},
+		{
+			MethodName: "RunPhase",
+			Handler:    _MachineService_RunPhase_Handler,
+		},
+		{
+			MethodName: "RunSequence",
+			Handler:    _MachineService_RunSequence_Handler,
+		},
+		{
+			MethodName: "SequenceHistory",
+			Handler:    _MachineService_SequenceHistory_Handler,
+		},
+		{
+			MethodName: "SequenceResult",
+			Handler:    _MachineService_SequenceResult_Handler,
+		},
+		{
+			MethodName: "Sequences",
+			Handler:    _MachineService_Sequences_Handler,
+		},
 		{
 			MethodName: "ServiceList",
 			Handler:    _MachineService_ServiceList_Handler,
@@ -2934,6 +5024,10 @@ var _MachineService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Shutdown",
 			Handler:    _MachineService_Shutdown_Handler,
 		},
+		{
+			MethodName: "StepSequence",
+			Handler:    _MachineService_StepSequence_Handler,
+		},
 		{
 			MethodName: "Upgrade",
 			Handler:    _MachineService_Upgrade_Handler,
@@ -2949,6 +5043,11 @@ var _MachineService_serviceDesc = grpc.ServiceDesc{
 			Handler:       _MachineService_Copy_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "EventsStream",
+			Handler:       _MachineService_EventsStream_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "Kubeconfig",
 			Handler:       _MachineService_Kubeconfig_Handler,
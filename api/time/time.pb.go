@@ -9,6 +9,7 @@ import (
 	math "math"
 
 	proto "github.com/golang/protobuf/proto"
+	duration "github.com/golang/protobuf/ptypes/duration"
 	empty "github.com/golang/protobuf/ptypes/empty"
 	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	grpc "google.golang.org/grpc"
@@ -29,155 +30,1088 @@ var (
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// The additional formats the time can be returned in. The protobuf timestamps
+// are always returned.
+type TimeFormat int32
+
+const (
+	TimeFormat_TIMESTAMP TimeFormat = 0
+	TimeFormat_RFC3339   TimeFormat = 1
+	TimeFormat_UNIX      TimeFormat = 2
+)
+
+var TimeFormat_name = map[int32]string{
+	0: "TIMESTAMP",
+	1: "RFC3339",
+	2: "UNIX",
+}
+
+var TimeFormat_value = map[string]int32{
+	"TIMESTAMP": 0,
+	"RFC3339":   1,
+	"UNIX":      2,
+}
+
+func (x TimeFormat) String() string {
+	return proto.EnumName(TimeFormat_name, int32(x))
+}
+
+func (TimeFormat) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{0}
+}
+
+// The leap second warning of an ntp server, announcing a leap second at the
+// end of the current month
+type LeapIndicator int32
+
+const (
+	LeapIndicator_NO_WARNING    LeapIndicator = 0
+	LeapIndicator_ADD_SECOND    LeapIndicator = 1
+	LeapIndicator_DELETE_SECOND LeapIndicator = 2
+	// The clock of the server is not synchronized
+	LeapIndicator_NOT_IN_SYNC LeapIndicator = 3
+)
+
+var LeapIndicator_name = map[int32]string{
+	0: "NO_WARNING",
+	1: "ADD_SECOND",
+	2: "DELETE_SECOND",
+	3: "NOT_IN_SYNC",
+}
+
+var LeapIndicator_value = map[string]int32{
+	"NO_WARNING":    0,
+	"ADD_SECOND":    1,
+	"DELETE_SECOND": 2,
+	"NOT_IN_SYNC":   3,
+}
+
+func (x LeapIndicator) String() string {
+	return proto.EnumName(LeapIndicator_name, int32(x))
+}
+
+func (LeapIndicator) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{1}
+}
+
+// The kind of a time source
+type TimeSource int32
+
+const (
+	TimeSource_NTP TimeSource = 0
+	// A PTP hardware clock
+	TimeSource_PHC TimeSource = 1
+)
+
+var TimeSource_name = map[int32]string{
+	0: "NTP",
+	1: "PHC",
+}
+
+var TimeSource_value = map[string]int32{
+	"NTP": 0,
+	"PHC": 1,
+}
+
+func (x TimeSource) String() string {
+	return proto.EnumName(TimeSource_name, int32(x))
+}
+
+func (TimeSource) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{2}
+}
+
+// The request message containing the additional time formats
+type TimeFormatRequest struct {
+	Formats              []TimeFormat `protobuf:"varint,1,rep,packed,name=formats,proto3,enum=time.TimeFormat" json:"formats,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *TimeFormatRequest) Reset()         { *m = TimeFormatRequest{} }
+func (m *TimeFormatRequest) String() string { return proto.CompactTextString(m) }
+func (*TimeFormatRequest) ProtoMessage()    {}
+func (*TimeFormatRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{0}
+}
+
+func (m *TimeFormatRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TimeFormatRequest.Unmarshal(m, b)
+}
+
+func (m *TimeFormatRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TimeFormatRequest.Marshal(b, m, deterministic)
+}
+
+func (m *TimeFormatRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TimeFormatRequest.Merge(m, src)
+}
+
+func (m *TimeFormatRequest) XXX_Size() int {
+	return xxx_messageInfo_TimeFormatRequest.Size(m)
+}
+
+func (m *TimeFormatRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TimeFormatRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TimeFormatRequest proto.InternalMessageInfo
+
+func (m *TimeFormatRequest) GetFormats() []TimeFormat {
+	if m != nil {
+		return m.Formats
+	}
+	return nil
+}
+
 // The response message containing the ntp server
 type TimeRequest struct {
-	Server               string   `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	Server  string       `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	Formats []TimeFormat `protobuf:"varint,2,rep,packed,name=formats,proto3,enum=time.TimeFormat" json:"formats,omitempty"`
+	// Returns the details of the ntp response packet
+	Verbose bool `protobuf:"varint,3,opt,name=verbose,proto3" json:"verbose,omitempty"`
+	// Compares the time of several servers, the unreachable servers are
+	// returned with an error
+	Servers              []string `protobuf:"bytes,5,rep,name=servers,proto3" json:"servers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TimeRequest) Reset()         { *m = TimeRequest{} }
+func (m *TimeRequest) String() string { return proto.CompactTextString(m) }
+func (*TimeRequest) ProtoMessage()    {}
+func (*TimeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{1}
+}
+
+func (m *TimeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TimeRequest.Unmarshal(m, b)
+}
+
+func (m *TimeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TimeRequest.Marshal(b, m, deterministic)
+}
+
+func (m *TimeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TimeRequest.Merge(m, src)
+}
+
+func (m *TimeRequest) XXX_Size() int {
+	return xxx_messageInfo_TimeRequest.Size(m)
+}
+
+func (m *TimeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TimeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TimeRequest proto.InternalMessageInfo
+
+func (m *TimeRequest) GetServer() string {
+	if m != nil {
+		return m.Server
+	}
+	return ""
+}
+
+func (m *TimeRequest) GetFormats() []TimeFormat {
+	if m != nil {
+		return m.Formats
+	}
+	return nil
+}
+
+func (m *TimeRequest) GetVerbose() bool {
+	if m != nil {
+		return m.Verbose
+	}
+	return false
+}
+
+func (m *TimeRequest) GetServers() []string {
+	if m != nil {
+		return m.Servers
+	}
+	return nil
+}
+
+// The details of the ntp response packet
+type NTPPacket struct {
+	Originate            *timestamp.Timestamp `protobuf:"bytes,1,opt,name=originate,proto3" json:"originate,omitempty"`
+	Receive              *timestamp.Timestamp `protobuf:"bytes,2,opt,name=receive,proto3" json:"receive,omitempty"`
+	Transmit             *timestamp.Timestamp `protobuf:"bytes,3,opt,name=transmit,proto3" json:"transmit,omitempty"`
+	Reference            *timestamp.Timestamp `protobuf:"bytes,4,opt,name=reference,proto3" json:"reference,omitempty"`
+	Precision            *duration.Duration   `protobuf:"bytes,5,opt,name=precision,proto3" json:"precision,omitempty"`
+	RootDelay            *duration.Duration   `protobuf:"bytes,6,opt,name=root_delay,json=rootDelay,proto3" json:"root_delay,omitempty"`
+	RootDispersion       *duration.Duration   `protobuf:"bytes,7,opt,name=root_dispersion,json=rootDispersion,proto3" json:"root_dispersion,omitempty"`
+	Poll                 *duration.Duration   `protobuf:"bytes,8,opt,name=poll,proto3" json:"poll,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *NTPPacket) Reset()         { *m = NTPPacket{} }
+func (m *NTPPacket) String() string { return proto.CompactTextString(m) }
+func (*NTPPacket) ProtoMessage()    {}
+func (*NTPPacket) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{2}
+}
+
+func (m *NTPPacket) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NTPPacket.Unmarshal(m, b)
+}
+
+func (m *NTPPacket) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NTPPacket.Marshal(b, m, deterministic)
+}
+
+func (m *NTPPacket) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NTPPacket.Merge(m, src)
+}
+
+func (m *NTPPacket) XXX_Size() int {
+	return xxx_messageInfo_NTPPacket.Size(m)
+}
+
+func (m *NTPPacket) XXX_DiscardUnknown() {
+	xxx_messageInfo_NTPPacket.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NTPPacket proto.InternalMessageInfo
+
+func (m *NTPPacket) GetOriginate() *timestamp.Timestamp {
+	if m != nil {
+		return m.Originate
+	}
+	return nil
+}
+
+func (m *NTPPacket) GetReceive() *timestamp.Timestamp {
+	if m != nil {
+		return m.Receive
+	}
+	return nil
+}
+
+func (m *NTPPacket) GetTransmit() *timestamp.Timestamp {
+	if m != nil {
+		return m.Transmit
+	}
+	return nil
+}
+
+func (m *NTPPacket) GetReference() *timestamp.Timestamp {
+	if m != nil {
+		return m.Reference
+	}
+	return nil
+}
+
+func (m *NTPPacket) GetPrecision() *duration.Duration {
+	if m != nil {
+		return m.Precision
+	}
+	return nil
+}
+
+func (m *NTPPacket) GetRootDelay() *duration.Duration {
+	if m != nil {
+		return m.RootDelay
+	}
+	return nil
+}
+
+func (m *NTPPacket) GetRootDispersion() *duration.Duration {
+	if m != nil {
+		return m.RootDispersion
+	}
+	return nil
+}
+
+func (m *NTPPacket) GetPoll() *duration.Duration {
+	if m != nil {
+		return m.Poll
+	}
+	return nil
+}
+
+type Time struct {
+	Metadata          *common.Metadata     `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Server            string               `protobuf:"bytes,2,opt,name=server,proto3" json:"server,omitempty"`
+	Localtime         *timestamp.Timestamp `protobuf:"bytes,3,opt,name=localtime,proto3" json:"localtime,omitempty"`
+	Remotetime        *timestamp.Timestamp `protobuf:"bytes,4,opt,name=remotetime,proto3" json:"remotetime,omitempty"`
+	LocaltimeRfc3339  string               `protobuf:"bytes,5,opt,name=localtime_rfc3339,json=localtimeRfc3339,proto3" json:"localtime_rfc3339,omitempty"`
+	RemotetimeRfc3339 string               `protobuf:"bytes,6,opt,name=remotetime_rfc3339,json=remotetimeRfc3339,proto3" json:"remotetime_rfc3339,omitempty"`
+	LocaltimeUnix     string               `protobuf:"bytes,7,opt,name=localtime_unix,json=localtimeUnix,proto3" json:"localtime_unix,omitempty"`
+	RemotetimeUnix    string               `protobuf:"bytes,8,opt,name=remotetime_unix,json=remotetimeUnix,proto3" json:"remotetime_unix,omitempty"`
+	Packet            *NTPPacket           `protobuf:"bytes,9,opt,name=packet,proto3" json:"packet,omitempty"`
+	// Whether the sample of this server was selected to set the time
+	Selected bool `protobuf:"varint,10,opt,name=selected,proto3" json:"selected,omitempty"`
+	// Whether the clock offset of this server disagrees with the other servers
+	Outlier bool `protobuf:"varint,11,opt,name=outlier,proto3" json:"outlier,omitempty"`
+	// The clock offset of the server, the remote time minus the local time,
+	// measured by the query and compensated for the network delay, so that
+	// clients do not subtract the timestamps
+	Offset *duration.Duration `protobuf:"bytes,12,opt,name=offset,proto3" json:"offset,omitempty"`
+	// The error querying this server, if any
+	Error string `protobuf:"bytes,13,opt,name=error,proto3" json:"error,omitempty"`
+	// The round-trip delay of the query
+	Rtt *duration.Duration `protobuf:"bytes,14,opt,name=rtt,proto3" json:"rtt,omitempty"`
+	// The jitter of the clock offsets measured by the recent syncs, only set
+	// for the selected server
+	Jitter *duration.Duration `protobuf:"bytes,15,opt,name=jitter,proto3" json:"jitter,omitempty"`
+	// The clock offset of this server relative to the selected server, only set
+	// when several servers are queried
+	RelativeOffset *duration.Duration `protobuf:"bytes,17,opt,name=relative_offset,json=relativeOffset,proto3" json:"relative_offset,omitempty"`
+	// The leap second warning of the server
+	Leap LeapIndicator `protobuf:"varint,18,opt,name=leap,proto3,enum=time.LeapIndicator" json:"leap,omitempty"`
+	// Whether the clock offset of this server exceeds the maximum step, above
+	// which the clock is not adjusted to the time of the server
+	ExceedsMaxStep bool `protobuf:"varint,19,opt,name=exceeds_max_step,json=exceedsMaxStep,proto3" json:"exceeds_max_step,omitempty"`
+	// Whether the client has not synced yet, and keeps retrying the servers,
+	// which may fail while the network is coming up
+	Retrying bool `protobuf:"varint,20,opt,name=retrying,proto3" json:"retrying,omitempty"`
+	// The kind of the time source, the server being the device of a PTP
+	// hardware clock
+	Source TimeSource `protobuf:"varint,21,opt,name=source,proto3,enum=time.TimeSource" json:"source,omitempty"`
+	// The stratum of the server, 16 meaning that the server is not
+	// synchronized. It is also set along with the error of a response that
+	// failed validation
+	Stratum uint32 `protobuf:"varint,22,opt,name=stratum,proto3" json:"stratum,omitempty"`
+	// The reference identifier of the server: the reference clock of a
+	// stratum 1 server (e.g. GPS), the kiss code of a stratum 0 response (e.g.
+	// RATE), or else the address of the upstream server
+	ReferenceId string `protobuf:"bytes,23,opt,name=reference_id,json=referenceId,proto3" json:"reference_id,omitempty"`
+	// The maximum error of the remote time, corrected for the offset: half of
+	// the round trips from the primary reference, plus the root dispersion,
+	// which includes the precision of the server and the drift of the clocks
+	// over the round trip. The time is accurate to within plus or minus
+	// max_error
+	MaxError *duration.Duration `protobuf:"bytes,25,opt,name=max_error,json=maxError,proto3" json:"max_error,omitempty"`
+	// The result of cross-checking the time of the most recent sync against the
+	// sanity check endpoint, only set for the selected server when an endpoint
+	// is configured
+	SanityCheck *SanityCheck `protobuf:"bytes,26,opt,name=sanity_check,json=sanityCheck,proto3" json:"sanity_check,omitempty"`
+	// The frequency correction of the local clock, only set for the selected
+	// server once the client has synced
+	Drift                *Drift   `protobuf:"bytes,27,opt,name=drift,proto3" json:"drift,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Time) Reset()         { *m = Time{} }
+func (m *Time) String() string { return proto.CompactTextString(m) }
+func (*Time) ProtoMessage()    {}
+func (*Time) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{3}
+}
+
+func (m *Time) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Time.Unmarshal(m, b)
+}
+
+func (m *Time) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Time.Marshal(b, m, deterministic)
+}
+
+func (m *Time) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Time.Merge(m, src)
+}
+
+func (m *Time) XXX_Size() int {
+	return xxx_messageInfo_Time.Size(m)
+}
+
+func (m *Time) XXX_DiscardUnknown() {
+	xxx_messageInfo_Time.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Time proto.InternalMessageInfo
+
+func (m *Time) GetMetadata() *common.Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *Time) GetServer() string {
+	if m != nil {
+		return m.Server
+	}
+	return ""
+}
+
+func (m *Time) GetLocaltime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Localtime
+	}
+	return nil
+}
+
+func (m *Time) GetRemotetime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Remotetime
+	}
+	return nil
+}
+
+func (m *Time) GetLocaltimeRfc3339() string {
+	if m != nil {
+		return m.LocaltimeRfc3339
+	}
+	return ""
+}
+
+func (m *Time) GetRemotetimeRfc3339() string {
+	if m != nil {
+		return m.RemotetimeRfc3339
+	}
+	return ""
+}
+
+func (m *Time) GetLocaltimeUnix() string {
+	if m != nil {
+		return m.LocaltimeUnix
+	}
+	return ""
+}
+
+func (m *Time) GetRemotetimeUnix() string {
+	if m != nil {
+		return m.RemotetimeUnix
+	}
+	return ""
+}
+
+func (m *Time) GetPacket() *NTPPacket {
+	if m != nil {
+		return m.Packet
+	}
+	return nil
+}
+
+func (m *Time) GetSelected() bool {
+	if m != nil {
+		return m.Selected
+	}
+	return false
+}
+
+func (m *Time) GetOutlier() bool {
+	if m != nil {
+		return m.Outlier
+	}
+	return false
+}
+
+func (m *Time) GetOffset() *duration.Duration {
+	if m != nil {
+		return m.Offset
+	}
+	return nil
+}
+
+func (m *Time) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *Time) GetRtt() *duration.Duration {
+	if m != nil {
+		return m.Rtt
+	}
+	return nil
+}
+
+func (m *Time) GetJitter() *duration.Duration {
+	if m != nil {
+		return m.Jitter
+	}
+	return nil
+}
+
+func (m *Time) GetRelativeOffset() *duration.Duration {
+	if m != nil {
+		return m.RelativeOffset
+	}
+	return nil
+}
+
+func (m *Time) GetLeap() LeapIndicator {
+	if m != nil {
+		return m.Leap
+	}
+	return LeapIndicator_NO_WARNING
+}
+
+func (m *Time) GetExceedsMaxStep() bool {
+	if m != nil {
+		return m.ExceedsMaxStep
+	}
+	return false
+}
+
+func (m *Time) GetRetrying() bool {
+	if m != nil {
+		return m.Retrying
+	}
+	return false
+}
+
+func (m *Time) GetSource() TimeSource {
+	if m != nil {
+		return m.Source
+	}
+	return TimeSource_NTP
+}
+
+func (m *Time) GetStratum() uint32 {
+	if m != nil {
+		return m.Stratum
+	}
+	return 0
+}
+
+func (m *Time) GetReferenceId() string {
+	if m != nil {
+		return m.ReferenceId
+	}
+	return ""
+}
+
+func (m *Time) GetMaxError() *duration.Duration {
+	if m != nil {
+		return m.MaxError
+	}
+	return nil
+}
+
+func (m *Time) GetSanityCheck() *SanityCheck {
+	if m != nil {
+		return m.SanityCheck
+	}
+	return nil
+}
+
+func (m *Time) GetDrift() *Drift {
+	if m != nil {
+		return m.Drift
+	}
+	return nil
+}
+
+// The frequency correction the kernel applies to the local clock to
+// compensate for the drift rate of its oscillator
+type Drift struct {
+	// The current frequency correction, in parts per million
+	Ppm float64 `protobuf:"fixed64,1,opt,name=ppm,proto3" json:"ppm,omitempty"`
+	// The change of the frequency correction over the window, in ppm
+	Change float64 `protobuf:"fixed64,2,opt,name=change,proto3" json:"change,omitempty"`
+	// The interval the change is measured over, up to a day
+	Window *duration.Duration `protobuf:"bytes,3,opt,name=window,proto3" json:"window,omitempty"`
+	// Whether the magnitude of the frequency correction exceeds the threshold,
+	// which may indicate a failing oscillator
+	Warning bool `protobuf:"varint,4,opt,name=warning,proto3" json:"warning,omitempty"`
+	// The warning threshold, in ppm, 0 disabling the warning
+	Threshold            float64  `protobuf:"fixed64,5,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Drift) Reset()         { *m = Drift{} }
+func (m *Drift) String() string { return proto.CompactTextString(m) }
+func (*Drift) ProtoMessage()    {}
+func (*Drift) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{4}
+}
+
+func (m *Drift) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Drift.Unmarshal(m, b)
+}
+
+func (m *Drift) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Drift.Marshal(b, m, deterministic)
+}
+
+func (m *Drift) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Drift.Merge(m, src)
+}
+
+func (m *Drift) XXX_Size() int {
+	return xxx_messageInfo_Drift.Size(m)
+}
+
+func (m *Drift) XXX_DiscardUnknown() {
+	xxx_messageInfo_Drift.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Drift proto.InternalMessageInfo
+
+func (m *Drift) GetPpm() float64 {
+	if m != nil {
+		return m.Ppm
+	}
+	return 0
+}
+
+func (m *Drift) GetChange() float64 {
+	if m != nil {
+		return m.Change
+	}
+	return 0
+}
+
+func (m *Drift) GetWindow() *duration.Duration {
+	if m != nil {
+		return m.Window
+	}
+	return nil
+}
+
+func (m *Drift) GetWarning() bool {
+	if m != nil {
+		return m.Warning
+	}
+	return false
+}
+
+func (m *Drift) GetThreshold() float64 {
+	if m != nil {
+		return m.Threshold
+	}
+	return 0
+}
+
+// The result of cross-checking the time of the servers against the Date
+// header of a trusted HTTPS endpoint
+type SanityCheck struct {
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// The time of the endpoint minus the time of the servers
+	Offset *duration.Duration `protobuf:"bytes,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// The offset above which the clock is not adjusted
+	Threshold *duration.Duration `protobuf:"bytes,3,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	Passed    bool               `protobuf:"varint,4,opt,name=passed,proto3" json:"passed,omitempty"`
+	// The error querying the endpoint, or the disagreement of the times
+	Error                string   `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SanityCheck) Reset()         { *m = SanityCheck{} }
+func (m *SanityCheck) String() string { return proto.CompactTextString(m) }
+func (*SanityCheck) ProtoMessage()    {}
+func (*SanityCheck) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{5}
+}
+
+func (m *SanityCheck) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SanityCheck.Unmarshal(m, b)
+}
+
+func (m *SanityCheck) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SanityCheck.Marshal(b, m, deterministic)
+}
+
+func (m *SanityCheck) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SanityCheck.Merge(m, src)
+}
+
+func (m *SanityCheck) XXX_Size() int {
+	return xxx_messageInfo_SanityCheck.Size(m)
+}
+
+func (m *SanityCheck) XXX_DiscardUnknown() {
+	xxx_messageInfo_SanityCheck.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SanityCheck proto.InternalMessageInfo
+
+func (m *SanityCheck) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *SanityCheck) GetOffset() *duration.Duration {
+	if m != nil {
+		return m.Offset
+	}
+	return nil
+}
+
+func (m *SanityCheck) GetThreshold() *duration.Duration {
+	if m != nil {
+		return m.Threshold
+	}
+	return nil
+}
+
+func (m *SanityCheck) GetPassed() bool {
+	if m != nil {
+		return m.Passed
+	}
+	return false
+}
+
+func (m *SanityCheck) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+// The response message containing the ntp server, time, and offset. When
+// several servers are queried, the selected server is returned first.
+type TimeResponse struct {
+	Messages             []*Time  `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *TimeRequest) Reset()         { *m = TimeRequest{} }
-func (m *TimeRequest) String() string { return proto.CompactTextString(m) }
-func (*TimeRequest) ProtoMessage()    {}
-func (*TimeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_e7ed1ef5b20ef4ce, []int{0}
+func (m *TimeResponse) Reset()         { *m = TimeResponse{} }
+func (m *TimeResponse) String() string { return proto.CompactTextString(m) }
+func (*TimeResponse) ProtoMessage()    {}
+func (*TimeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{6}
 }
 
-func (m *TimeRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_TimeRequest.Unmarshal(m, b)
+func (m *TimeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TimeResponse.Unmarshal(m, b)
 }
 
-func (m *TimeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_TimeRequest.Marshal(b, m, deterministic)
+func (m *TimeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TimeResponse.Marshal(b, m, deterministic)
 }
 
-func (m *TimeRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_TimeRequest.Merge(m, src)
+func (m *TimeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TimeResponse.Merge(m, src)
 }
 
-func (m *TimeRequest) XXX_Size() int {
-	return xxx_messageInfo_TimeRequest.Size(m)
+func (m *TimeResponse) XXX_Size() int {
+	return xxx_messageInfo_TimeResponse.Size(m)
 }
 
-func (m *TimeRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_TimeRequest.DiscardUnknown(m)
+func (m *TimeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TimeResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_TimeRequest proto.InternalMessageInfo
+var xxx_messageInfo_TimeResponse proto.InternalMessageInfo
 
-func (m *TimeRequest) GetServer() string {
+func (m *TimeResponse) GetMessages() []*Time {
 	if m != nil {
-		return m.Server
+		return m.Messages
 	}
-	return ""
+	return nil
 }
 
-type Time struct {
-	Metadata             *common.Metadata     `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
-	Server               string               `protobuf:"bytes,2,opt,name=server,proto3" json:"server,omitempty"`
-	Localtime            *timestamp.Timestamp `protobuf:"bytes,3,opt,name=localtime,proto3" json:"localtime,omitempty"`
-	Remotetime           *timestamp.Timestamp `protobuf:"bytes,4,opt,name=remotetime,proto3" json:"remotetime,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+type SyncTolerance struct {
+	Metadata             *common.Metadata   `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Tolerance            *duration.Duration `protobuf:"bytes,2,opt,name=tolerance,proto3" json:"tolerance,omitempty"`
+	Offset               *duration.Duration `protobuf:"bytes,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Synced               bool               `protobuf:"varint,4,opt,name=synced,proto3" json:"synced,omitempty"`
+	PollInterval         *duration.Duration `protobuf:"bytes,5,opt,name=poll_interval,json=pollInterval,proto3" json:"poll_interval,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
 }
 
-func (m *Time) Reset()         { *m = Time{} }
-func (m *Time) String() string { return proto.CompactTextString(m) }
-func (*Time) ProtoMessage()    {}
-func (*Time) Descriptor() ([]byte, []int) {
-	return fileDescriptor_e7ed1ef5b20ef4ce, []int{1}
+func (m *SyncTolerance) Reset()         { *m = SyncTolerance{} }
+func (m *SyncTolerance) String() string { return proto.CompactTextString(m) }
+func (*SyncTolerance) ProtoMessage()    {}
+func (*SyncTolerance) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{7}
 }
 
-func (m *Time) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Time.Unmarshal(m, b)
+func (m *SyncTolerance) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SyncTolerance.Unmarshal(m, b)
 }
 
-func (m *Time) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Time.Marshal(b, m, deterministic)
+func (m *SyncTolerance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SyncTolerance.Marshal(b, m, deterministic)
 }
 
-func (m *Time) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Time.Merge(m, src)
+func (m *SyncTolerance) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SyncTolerance.Merge(m, src)
 }
 
-func (m *Time) XXX_Size() int {
-	return xxx_messageInfo_Time.Size(m)
+func (m *SyncTolerance) XXX_Size() int {
+	return xxx_messageInfo_SyncTolerance.Size(m)
 }
 
-func (m *Time) XXX_DiscardUnknown() {
-	xxx_messageInfo_Time.DiscardUnknown(m)
+func (m *SyncTolerance) XXX_DiscardUnknown() {
+	xxx_messageInfo_SyncTolerance.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Time proto.InternalMessageInfo
+var xxx_messageInfo_SyncTolerance proto.InternalMessageInfo
 
-func (m *Time) GetMetadata() *common.Metadata {
+func (m *SyncTolerance) GetMetadata() *common.Metadata {
 	if m != nil {
 		return m.Metadata
 	}
 	return nil
 }
 
-func (m *Time) GetServer() string {
+func (m *SyncTolerance) GetTolerance() *duration.Duration {
+	if m != nil {
+		return m.Tolerance
+	}
+	return nil
+}
+
+func (m *SyncTolerance) GetOffset() *duration.Duration {
+	if m != nil {
+		return m.Offset
+	}
+	return nil
+}
+
+func (m *SyncTolerance) GetSynced() bool {
+	if m != nil {
+		return m.Synced
+	}
+	return false
+}
+
+func (m *SyncTolerance) GetPollInterval() *duration.Duration {
+	if m != nil {
+		return m.PollInterval
+	}
+	return nil
+}
+
+// The response message containing the sync tolerance, last measured offset,
+// whether the offset is within the tolerance, and the current poll interval
+type SyncToleranceResponse struct {
+	Messages             []*SyncTolerance `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *SyncToleranceResponse) Reset()         { *m = SyncToleranceResponse{} }
+func (m *SyncToleranceResponse) String() string { return proto.CompactTextString(m) }
+func (*SyncToleranceResponse) ProtoMessage()    {}
+func (*SyncToleranceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{8}
+}
+
+func (m *SyncToleranceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SyncToleranceResponse.Unmarshal(m, b)
+}
+
+func (m *SyncToleranceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SyncToleranceResponse.Marshal(b, m, deterministic)
+}
+
+func (m *SyncToleranceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SyncToleranceResponse.Merge(m, src)
+}
+
+func (m *SyncToleranceResponse) XXX_Size() int {
+	return xxx_messageInfo_SyncToleranceResponse.Size(m)
+}
+
+func (m *SyncToleranceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SyncToleranceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SyncToleranceResponse proto.InternalMessageInfo
+
+func (m *SyncToleranceResponse) GetMessages() []*SyncTolerance {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+// The sync state, sent when the watch starts and after every sync
+type TimeSyncState struct {
+	Metadata     *common.Metadata `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Synchronized bool             `protobuf:"varint,2,opt,name=synchronized,proto3" json:"synchronized,omitempty"`
+	// The clock offset measured by the last successful sync
+	Offset *duration.Duration `protobuf:"bytes,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	// The server of the last successful sync
+	Server string `protobuf:"bytes,4,opt,name=server,proto3" json:"server,omitempty"`
+	// The time since the last successful sync, unset before the first one
+	SinceLastSync *duration.Duration `protobuf:"bytes,5,opt,name=since_last_sync,json=sinceLastSync,proto3" json:"since_last_sync,omitempty"`
+	// The maximum error of the time of the last successful sync, plus the
+	// drift of the clock since
+	MaxError             *duration.Duration `protobuf:"bytes,6,opt,name=max_error,json=maxError,proto3" json:"max_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *TimeSyncState) Reset()         { *m = TimeSyncState{} }
+func (m *TimeSyncState) String() string { return proto.CompactTextString(m) }
+func (*TimeSyncState) ProtoMessage()    {}
+func (*TimeSyncState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{9}
+}
+
+func (m *TimeSyncState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TimeSyncState.Unmarshal(m, b)
+}
+
+func (m *TimeSyncState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TimeSyncState.Marshal(b, m, deterministic)
+}
+
+func (m *TimeSyncState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TimeSyncState.Merge(m, src)
+}
+
+func (m *TimeSyncState) XXX_Size() int {
+	return xxx_messageInfo_TimeSyncState.Size(m)
+}
+
+func (m *TimeSyncState) XXX_DiscardUnknown() {
+	xxx_messageInfo_TimeSyncState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TimeSyncState proto.InternalMessageInfo
+
+func (m *TimeSyncState) GetMetadata() *common.Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *TimeSyncState) GetSynchronized() bool {
+	if m != nil {
+		return m.Synchronized
+	}
+	return false
+}
+
+func (m *TimeSyncState) GetOffset() *duration.Duration {
+	if m != nil {
+		return m.Offset
+	}
+	return nil
+}
+
+func (m *TimeSyncState) GetServer() string {
 	if m != nil {
 		return m.Server
 	}
 	return ""
 }
 
-func (m *Time) GetLocaltime() *timestamp.Timestamp {
+func (m *TimeSyncState) GetSinceLastSync() *duration.Duration {
 	if m != nil {
-		return m.Localtime
+		return m.SinceLastSync
 	}
 	return nil
 }
 
-func (m *Time) GetRemotetime() *timestamp.Timestamp {
+func (m *TimeSyncState) GetMaxError() *duration.Duration {
 	if m != nil {
-		return m.Remotetime
+		return m.MaxError
 	}
 	return nil
 }
 
-// The response message containing the ntp server, time, and offset
-type TimeResponse struct {
-	Messages             []*Time  `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+// Whether the initial time sync completed, and the clock was within the
+// tolerance after the most recent sync
+type TimeReady struct {
+	Metadata             *common.Metadata `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Ready                bool             `protobuf:"varint,2,opt,name=ready,proto3" json:"ready,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
-func (m *TimeResponse) Reset()         { *m = TimeResponse{} }
-func (m *TimeResponse) String() string { return proto.CompactTextString(m) }
-func (*TimeResponse) ProtoMessage()    {}
-func (*TimeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_e7ed1ef5b20ef4ce, []int{2}
+func (m *TimeReady) Reset()         { *m = TimeReady{} }
+func (m *TimeReady) String() string { return proto.CompactTextString(m) }
+func (*TimeReady) ProtoMessage()    {}
+func (*TimeReady) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{10}
 }
 
-func (m *TimeResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_TimeResponse.Unmarshal(m, b)
+func (m *TimeReady) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TimeReady.Unmarshal(m, b)
 }
 
-func (m *TimeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_TimeResponse.Marshal(b, m, deterministic)
+func (m *TimeReady) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TimeReady.Marshal(b, m, deterministic)
 }
 
-func (m *TimeResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_TimeResponse.Merge(m, src)
+func (m *TimeReady) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TimeReady.Merge(m, src)
 }
 
-func (m *TimeResponse) XXX_Size() int {
-	return xxx_messageInfo_TimeResponse.Size(m)
+func (m *TimeReady) XXX_Size() int {
+	return xxx_messageInfo_TimeReady.Size(m)
 }
 
-func (m *TimeResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_TimeResponse.DiscardUnknown(m)
+func (m *TimeReady) XXX_DiscardUnknown() {
+	xxx_messageInfo_TimeReady.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_TimeResponse proto.InternalMessageInfo
+var xxx_messageInfo_TimeReady proto.InternalMessageInfo
 
-func (m *TimeResponse) GetMessages() []*Time {
+func (m *TimeReady) GetMetadata() *common.Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *TimeReady) GetReady() bool {
+	if m != nil {
+		return m.Ready
+	}
+	return false
+}
+
+type TimeReadyResponse struct {
+	Messages             []*TimeReady `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *TimeReadyResponse) Reset()         { *m = TimeReadyResponse{} }
+func (m *TimeReadyResponse) String() string { return proto.CompactTextString(m) }
+func (*TimeReadyResponse) ProtoMessage()    {}
+func (*TimeReadyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7ed1ef5b20ef4ce, []int{11}
+}
+
+func (m *TimeReadyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TimeReadyResponse.Unmarshal(m, b)
+}
+
+func (m *TimeReadyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TimeReadyResponse.Marshal(b, m, deterministic)
+}
+
+func (m *TimeReadyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TimeReadyResponse.Merge(m, src)
+}
+
+func (m *TimeReadyResponse) XXX_Size() int {
+	return xxx_messageInfo_TimeReadyResponse.Size(m)
+}
+
+func (m *TimeReadyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TimeReadyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TimeReadyResponse proto.InternalMessageInfo
+
+func (m *TimeReadyResponse) GetMessages() []*TimeReady {
 	if m != nil {
 		return m.Messages
 	}
@@ -185,36 +1119,113 @@ func (m *TimeResponse) GetMessages() []*Time {
 }
 
 func init() {
+	proto.RegisterEnum("time.TimeFormat", TimeFormat_name, TimeFormat_value)
+	proto.RegisterEnum("time.LeapIndicator", LeapIndicator_name, LeapIndicator_value)
+	proto.RegisterEnum("time.TimeSource", TimeSource_name, TimeSource_value)
+	proto.RegisterType((*TimeFormatRequest)(nil), "time.TimeFormatRequest")
 	proto.RegisterType((*TimeRequest)(nil), "time.TimeRequest")
+	proto.RegisterType((*NTPPacket)(nil), "time.NTPPacket")
 	proto.RegisterType((*Time)(nil), "time.Time")
+	proto.RegisterType((*Drift)(nil), "time.Drift")
+	proto.RegisterType((*SanityCheck)(nil), "time.SanityCheck")
 	proto.RegisterType((*TimeResponse)(nil), "time.TimeResponse")
+	proto.RegisterType((*SyncTolerance)(nil), "time.SyncTolerance")
+	proto.RegisterType((*SyncToleranceResponse)(nil), "time.SyncToleranceResponse")
+	proto.RegisterType((*TimeSyncState)(nil), "time.TimeSyncState")
+	proto.RegisterType((*TimeReady)(nil), "time.TimeReady")
+	proto.RegisterType((*TimeReadyResponse)(nil), "time.TimeReadyResponse")
 }
 
 func init() { proto.RegisterFile("time/time.proto", fileDescriptor_e7ed1ef5b20ef4ce) }
 
 var fileDescriptor_e7ed1ef5b20ef4ce = []byte{
-	// 333 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x91, 0xc1, 0x4b, 0xc3, 0x30,
-	0x14, 0xc6, 0xa9, 0x1b, 0xd3, 0xbd, 0x0d, 0xd4, 0x08, 0x63, 0xd4, 0x83, 0x63, 0xa0, 0xee, 0xa0,
-	0x2d, 0x54, 0x10, 0xf1, 0xe6, 0xc4, 0xa3, 0x20, 0x75, 0x27, 0x6f, 0x59, 0x7d, 0x76, 0xc1, 0x66,
-	0x89, 0x7d, 0xe9, 0x60, 0xff, 0x9e, 0x7f, 0x99, 0x24, 0xe9, 0x66, 0x51, 0xc1, 0xcb, 0xd6, 0xf7,
-	0xde, 0xef, 0x7d, 0xf9, 0xf2, 0x05, 0xf6, 0x8d, 0x90, 0x18, 0xdb, 0x9f, 0x48, 0x97, 0xca, 0x28,
-	0xd6, 0xb6, 0xdf, 0xe1, 0x71, 0xae, 0x54, 0x5e, 0x60, 0xec, 0x7a, 0xf3, 0xea, 0x2d, 0x46, 0xa9,
-	0xcd, 0xda, 0x23, 0xe1, 0xc9, 0xcf, 0xa1, 0x5d, 0x21, 0xc3, 0xa5, 0xae, 0x81, 0xa3, 0x4c, 0x49,
-	0xa9, 0x96, 0xb1, 0xff, 0xf3, 0xcd, 0xf1, 0x29, 0xf4, 0x66, 0x42, 0x62, 0x8a, 0x1f, 0x15, 0x92,
-	0x61, 0x03, 0xe8, 0x10, 0x96, 0x2b, 0x2c, 0x87, 0xc1, 0x28, 0x98, 0x74, 0xd3, 0xba, 0x1a, 0x7f,
-	0x06, 0xd0, 0xb6, 0x1c, 0xbb, 0x80, 0x3d, 0x89, 0x86, 0xbf, 0x72, 0xc3, 0x1d, 0xd2, 0x4b, 0x0e,
-	0xa2, 0x5a, 0xf0, 0xb1, 0xee, 0xa7, 0x5b, 0xa2, 0x21, 0xb7, 0xd3, 0x94, 0x63, 0x37, 0xd0, 0x2d,
-	0x54, 0xc6, 0x0b, 0x6b, 0x71, 0xd8, 0x72, 0x32, 0x61, 0xe4, 0xfd, 0x47, 0x1b, 0xff, 0xd1, 0x6c,
-	0xe3, 0x3f, 0xfd, 0x86, 0xd9, 0x2d, 0x40, 0x89, 0x52, 0x19, 0x74, 0xab, 0xed, 0x7f, 0x57, 0x1b,
-	0xf4, 0xf8, 0x1a, 0xfa, 0xfe, 0xae, 0xa4, 0xd5, 0x92, 0x90, 0x9d, 0xd9, 0xbb, 0x10, 0xf1, 0x1c,
-	0x69, 0x18, 0x8c, 0x5a, 0x93, 0x5e, 0x02, 0x91, 0xcb, 0xdc, 0x51, 0xdb, 0x59, 0x52, 0xf9, 0x8c,
-	0x9e, 0xb1, 0x5c, 0x89, 0x0c, 0x59, 0x52, 0x47, 0x31, 0xf8, 0x75, 0xec, 0x83, 0x7d, 0x8e, 0x90,
-	0x35, 0x44, 0x36, 0x47, 0x25, 0xd0, 0xb5, 0xf5, 0xfd, 0x02, 0xb3, 0x77, 0x76, 0xd8, 0x04, 0x5c,
-	0xee, 0x7f, 0xed, 0x4c, 0xa7, 0xd0, 0xcf, 0x94, 0xf4, 0x03, 0xae, 0xc5, 0x74, 0xd7, 0x4e, 0xef,
-	0xb4, 0x78, 0x0a, 0x5e, 0xce, 0x73, 0x61, 0x16, 0xd5, 0xdc, 0x26, 0x1f, 0x1b, 0x5e, 0x28, 0xba,
-	0xa4, 0x35, 0x19, 0x94, 0xe4, 0xab, 0x98, 0x6b, 0xe1, 0x9e, 0x7f, 0xde, 0x71, 0xde, 0xae, 0xbe,
-	0x02, 0x00, 0x00, 0xff, 0xff, 0x0f, 0xc9, 0x4e, 0xf0, 0x51, 0x02, 0x00, 0x00,
+	// 1372 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x57, 0xcb, 0x52, 0x1b, 0x47,
+	0x17, 0xf6, 0x48, 0x42, 0x97, 0x23, 0x74, 0x6b, 0x7c, 0x19, 0xe3, 0xbf, 0xfc, 0x63, 0x55, 0xfd,
+	0xbf, 0x55, 0x38, 0x86, 0x04, 0x5c, 0xb6, 0x93, 0x4a, 0x39, 0x01, 0x84, 0x63, 0xaa, 0x8c, 0xa0,
+	0x46, 0x72, 0x39, 0xc9, 0x46, 0xd5, 0x8c, 0x1a, 0xd4, 0xf1, 0xcc, 0xf4, 0xa4, 0xbb, 0x85, 0x51,
+	0x76, 0x59, 0xe4, 0x2d, 0xb2, 0xc9, 0x2a, 0xcf, 0x90, 0xbc, 0x46, 0x5e, 0x28, 0xd5, 0xdd, 0x73,
+	0x13, 0xc4, 0x35, 0x38, 0x1b, 0x98, 0x73, 0xce, 0x77, 0xfa, 0xf2, 0x9d, 0x5b, 0x0b, 0x5a, 0x92,
+	0xfa, 0x64, 0x53, 0xfd, 0xd9, 0x08, 0x39, 0x93, 0x0c, 0x95, 0xd4, 0xf7, 0xea, 0xfd, 0x33, 0xc6,
+	0xce, 0x3c, 0xb2, 0xa9, 0x75, 0x27, 0xb3, 0xd3, 0xcd, 0xc9, 0x8c, 0x63, 0x49, 0x59, 0x60, 0x50,
+	0xab, 0xf7, 0x2e, 0xdb, 0x89, 0x1f, 0xca, 0x79, 0x64, 0xfc, 0xef, 0x65, 0xa3, 0x5a, 0x52, 0x48,
+	0xec, 0x87, 0x11, 0x60, 0xc5, 0x65, 0xbe, 0xcf, 0x82, 0x4d, 0xf3, 0xcf, 0x28, 0xbb, 0x5f, 0x41,
+	0x67, 0x44, 0x7d, 0xf2, 0x92, 0x71, 0x1f, 0x4b, 0x87, 0xfc, 0x38, 0x23, 0x42, 0xa2, 0x75, 0xa8,
+	0x9c, 0x6a, 0x85, 0xb0, 0xad, 0xb5, 0x62, 0xaf, 0xb9, 0xd5, 0xde, 0xd0, 0x67, 0xcd, 0x20, 0x63,
+	0x40, 0xf7, 0x17, 0x0b, 0xea, 0x4a, 0x1f, 0xfb, 0xde, 0x86, 0xb2, 0x20, 0xfc, 0x9c, 0x70, 0xdb,
+	0x5a, 0xb3, 0x7a, 0x35, 0x27, 0x92, 0xb2, 0x6b, 0x16, 0x72, 0xd6, 0x44, 0x36, 0x54, 0xce, 0x09,
+	0x3f, 0x61, 0x82, 0xd8, 0xc5, 0x35, 0xab, 0x57, 0x75, 0x62, 0x51, 0x59, 0xcc, 0x7a, 0xc2, 0x5e,
+	0x5a, 0x2b, 0xf6, 0x6a, 0x4e, 0x2c, 0x76, 0xff, 0x2a, 0x42, 0x6d, 0x30, 0x3a, 0x3e, 0xc6, 0xee,
+	0x3b, 0x22, 0xd1, 0x73, 0xa8, 0x31, 0x4e, 0xcf, 0x68, 0x80, 0x25, 0xd1, 0x07, 0xa9, 0x6f, 0xad,
+	0x6e, 0x18, 0x82, 0x36, 0x62, 0x82, 0x36, 0x46, 0x31, 0x41, 0x4e, 0x0a, 0x46, 0x4f, 0xa0, 0xc2,
+	0x89, 0x4b, 0xe8, 0x39, 0xb1, 0x0b, 0xb9, 0x7e, 0x31, 0x14, 0x3d, 0x85, 0xaa, 0xe4, 0x38, 0x10,
+	0x3e, 0x95, 0x76, 0x31, 0xd7, 0x2d, 0xc1, 0xaa, 0x73, 0x72, 0x72, 0x4a, 0x38, 0x09, 0x5c, 0x62,
+	0x97, 0xf2, 0xcf, 0x99, 0x80, 0xd1, 0x33, 0xa8, 0x85, 0x9c, 0xb8, 0x54, 0x50, 0x16, 0xd8, 0x4b,
+	0xda, 0xf3, 0xee, 0x15, 0xcf, 0x7e, 0x94, 0x3f, 0x4e, 0x8a, 0x45, 0xcf, 0x01, 0x38, 0x63, 0x72,
+	0x3c, 0x21, 0x1e, 0x9e, 0xdb, 0xe5, 0x5c, 0x4f, 0x05, 0xee, 0x2b, 0x2c, 0xda, 0x85, 0x96, 0xf1,
+	0xa4, 0x22, 0x24, 0x5c, 0x6f, 0x5c, 0xc9, 0x73, 0x6f, 0x6a, 0xf7, 0xc4, 0x01, 0x3d, 0x86, 0x52,
+	0xc8, 0x3c, 0xcf, 0xae, 0xe6, 0x39, 0x6a, 0x58, 0xf7, 0xb7, 0x2a, 0x94, 0xd4, 0xf5, 0xd1, 0x27,
+	0x50, 0xf5, 0x89, 0xc4, 0x13, 0x2c, 0x71, 0x14, 0xcf, 0xf6, 0x46, 0x94, 0xc8, 0x87, 0x91, 0xde,
+	0x49, 0x10, 0x99, 0x24, 0x2c, 0x2c, 0x24, 0xe1, 0x73, 0xa8, 0x79, 0xcc, 0xc5, 0x9e, 0xca, 0xbc,
+	0x6b, 0xc4, 0x29, 0x05, 0xa3, 0x2f, 0x00, 0x38, 0xf1, 0x99, 0x24, 0xda, 0x35, 0x3f, 0x52, 0x19,
+	0x34, 0x7a, 0x04, 0x9d, 0x64, 0xa1, 0x31, 0x3f, 0x75, 0xb7, 0xb7, 0xb7, 0x3f, 0xd7, 0x21, 0xab,
+	0x39, 0xed, 0xc4, 0xe0, 0x18, 0x3d, 0x7a, 0x0c, 0x28, 0x75, 0x4d, 0xd0, 0x65, 0x8d, 0xee, 0xa4,
+	0x96, 0x18, 0xfe, 0x3f, 0x68, 0xa6, 0x6b, 0xcf, 0x02, 0x7a, 0xa1, 0x43, 0x52, 0x73, 0x1a, 0x89,
+	0xf6, 0x4d, 0x40, 0x2f, 0xd0, 0x43, 0x68, 0x65, 0x56, 0xd5, 0xb8, 0xaa, 0xc6, 0x35, 0x53, 0x75,
+	0x04, 0x2c, 0x87, 0xba, 0x84, 0xec, 0x9a, 0xbe, 0x63, 0xcb, 0x54, 0x69, 0x52, 0x59, 0x4e, 0x64,
+	0x46, 0xab, 0x50, 0x15, 0xc4, 0x23, 0xae, 0x24, 0x13, 0x1b, 0x74, 0x91, 0x26, 0xb2, 0xaa, 0x52,
+	0x36, 0x93, 0x1e, 0x25, 0xdc, 0xae, 0x9b, 0xfa, 0x8d, 0x44, 0xf4, 0x19, 0x94, 0xd9, 0xe9, 0xa9,
+	0x20, 0xd2, 0x5e, 0xce, 0x4b, 0x80, 0x08, 0x88, 0x6e, 0xc2, 0x12, 0xe1, 0x9c, 0x71, 0xbb, 0xa1,
+	0x0f, 0x6c, 0x04, 0xf4, 0x08, 0x8a, 0x5c, 0x4a, 0xbb, 0x99, 0xb7, 0x8a, 0x42, 0xa9, 0x5d, 0x7f,
+	0xa0, 0x52, 0x12, 0x6e, 0xb7, 0x72, 0x77, 0x35, 0x40, 0x9d, 0xeb, 0xc4, 0xc3, 0x92, 0x9e, 0x93,
+	0x71, 0x74, 0xe2, 0x4e, 0x7e, 0xae, 0x47, 0x1e, 0x47, 0xe6, 0xe4, 0x0f, 0xa1, 0xe4, 0x11, 0x1c,
+	0xda, 0x68, 0xcd, 0xea, 0x35, 0xb7, 0x56, 0x0c, 0x93, 0xaf, 0x09, 0x0e, 0x0f, 0x82, 0x09, 0x75,
+	0xb1, 0x64, 0xdc, 0xd1, 0x00, 0xd4, 0x83, 0x36, 0xb9, 0x70, 0x09, 0x99, 0x88, 0xb1, 0x8f, 0x2f,
+	0xc6, 0x42, 0x92, 0xd0, 0x5e, 0xd1, 0xc4, 0x35, 0x23, 0xfd, 0x21, 0xbe, 0x18, 0x4a, 0x12, 0x2a,
+	0xd6, 0x39, 0x91, 0x7c, 0x4e, 0x83, 0x33, 0xfb, 0xa6, 0x61, 0x3d, 0x96, 0x51, 0x0f, 0xca, 0x82,
+	0xcd, 0xb8, 0x4b, 0xec, 0x5b, 0x6b, 0xd6, 0x62, 0x83, 0x1d, 0x6a, 0xbd, 0x13, 0xd9, 0x75, 0x17,
+	0x95, 0x1c, 0xcb, 0x99, 0x6f, 0xdf, 0x5e, 0xb3, 0x7a, 0x0d, 0x27, 0x16, 0xd1, 0x03, 0x58, 0x4e,
+	0x5a, 0xcc, 0x98, 0x4e, 0xec, 0x3b, 0x9a, 0xf3, 0x7a, 0xa2, 0x3b, 0x98, 0xa0, 0xa7, 0x50, 0x53,
+	0x87, 0x34, 0x31, 0xb9, 0x9b, 0xc7, 0x49, 0xd5, 0xc7, 0x17, 0xfb, 0x3a, 0x62, 0x4f, 0x60, 0x59,
+	0xe0, 0x80, 0xca, 0xf9, 0xd8, 0x9d, 0x12, 0xf7, 0x9d, 0xbd, 0xaa, 0x5d, 0x3b, 0xe6, 0x90, 0x43,
+	0x6d, 0xd9, 0x53, 0x06, 0xa7, 0x2e, 0x52, 0x01, 0x3d, 0x80, 0xa5, 0x09, 0xa7, 0xa7, 0xd2, 0xbe,
+	0xa7, 0xe1, 0x75, 0x03, 0xef, 0x2b, 0x95, 0x63, 0x2c, 0xdd, 0x5f, 0x2d, 0x58, 0xd2, 0x0a, 0xd4,
+	0x86, 0x62, 0x18, 0xfa, 0xba, 0x3f, 0x58, 0x8e, 0xfa, 0x54, 0x8d, 0xc0, 0x9d, 0xe2, 0xe0, 0xcc,
+	0x34, 0x73, 0xcb, 0x89, 0x24, 0x95, 0x11, 0xef, 0x69, 0x30, 0x61, 0xef, 0xed, 0x62, 0xde, 0x0d,
+	0x22, 0xa0, 0x22, 0xed, 0x3d, 0xe6, 0x81, 0x62, 0xbe, 0x64, 0x92, 0x3a, 0x12, 0xd1, 0x7f, 0xa0,
+	0x26, 0xa7, 0x9c, 0x88, 0x29, 0xf3, 0x26, 0xba, 0xae, 0x2d, 0x27, 0x55, 0x74, 0xff, 0xb0, 0xa0,
+	0x9e, 0xb9, 0x9e, 0x3a, 0xe4, 0x8c, 0x7b, 0xd1, 0x74, 0x54, 0x9f, 0x99, 0xa2, 0x28, 0x5c, 0xb7,
+	0x28, 0x9e, 0x65, 0xb7, 0xcc, 0xbd, 0x42, 0x8a, 0x55, 0x84, 0x84, 0x58, 0x08, 0x32, 0x89, 0x2e,
+	0x11, 0x49, 0x69, 0x95, 0x2d, 0x65, 0xaa, 0xac, 0xfb, 0x14, 0x96, 0xcd, 0x6c, 0x17, 0x21, 0x0b,
+	0x04, 0x41, 0xff, 0x57, 0x5d, 0x58, 0x08, 0x7c, 0x46, 0xcc, 0xcb, 0xa0, 0xbe, 0x05, 0x69, 0x92,
+	0x39, 0x89, 0xad, 0xfb, 0x73, 0x01, 0x1a, 0xc3, 0x79, 0xe0, 0x8e, 0x98, 0x47, 0x38, 0x0e, 0xdc,
+	0x8f, 0xed, 0xdf, 0xea, 0x7a, 0xb1, 0x6b, 0x3e, 0x29, 0x29, 0x36, 0x43, 0x65, 0xf1, 0xba, 0x54,
+	0xaa, 0x59, 0x31, 0x0f, 0xdc, 0x94, 0x11, 0x23, 0xa1, 0x17, 0xd0, 0x50, 0x23, 0x68, 0x4c, 0x03,
+	0x49, 0xf8, 0x39, 0xf6, 0xf2, 0x87, 0xec, 0xb2, 0xc2, 0x1f, 0x44, 0xf0, 0xee, 0x2b, 0xb8, 0xb5,
+	0x40, 0x41, 0x42, 0xe2, 0xe6, 0x15, 0x12, 0xa3, 0xd6, 0xb0, 0x08, 0x4f, 0xd9, 0xfc, 0xbd, 0x00,
+	0x0d, 0x5d, 0xc5, 0xf3, 0xc0, 0x1d, 0x4a, 0x2c, 0x3f, 0x96, 0xcd, 0x2e, 0x2c, 0xab, 0x3b, 0x4d,
+	0x39, 0x0b, 0xe8, 0x4f, 0x64, 0xa2, 0x09, 0xad, 0x3a, 0x0b, 0xba, 0x7f, 0x4b, 0x9c, 0x19, 0xb2,
+	0xa5, 0x85, 0x21, 0xbb, 0x03, 0x2d, 0x41, 0x55, 0xff, 0xf0, 0xb0, 0x90, 0x63, 0xb5, 0x4b, 0x3e,
+	0x75, 0x0d, 0xed, 0xf1, 0x1a, 0x0b, 0xa9, 0x2e, 0xb9, 0xd8, 0x63, 0xca, 0xd7, 0xee, 0x31, 0xdd,
+	0x23, 0xa8, 0x99, 0x7c, 0xc5, 0x93, 0xf9, 0x47, 0x92, 0x74, 0x13, 0x96, 0xb8, 0x72, 0x8b, 0xd8,
+	0x31, 0x42, 0xf7, 0x6b, 0xe8, 0x24, 0x0b, 0x26, 0x01, 0x7c, 0x74, 0x25, 0x80, 0xad, 0x4c, 0x15,
+	0x68, 0x68, 0x02, 0x58, 0xdf, 0x02, 0x48, 0x9f, 0xb8, 0xa8, 0x01, 0xb5, 0xd1, 0xc1, 0xe1, 0xfe,
+	0x70, 0xb4, 0x73, 0x78, 0xdc, 0xbe, 0x81, 0xea, 0x50, 0x71, 0x5e, 0xee, 0xa9, 0x41, 0xde, 0xb6,
+	0x50, 0x15, 0x4a, 0x6f, 0x06, 0x07, 0xdf, 0xb6, 0x0b, 0xeb, 0x43, 0x68, 0x2c, 0x8c, 0x09, 0xd4,
+	0x04, 0x18, 0x1c, 0x8d, 0xdf, 0xee, 0x38, 0x83, 0x83, 0xc1, 0x37, 0xed, 0x1b, 0x4a, 0xde, 0xe9,
+	0xf7, 0xc7, 0xc3, 0xfd, 0xbd, 0xa3, 0x41, 0xbf, 0x6d, 0xa1, 0x0e, 0x34, 0xfa, 0xfb, 0xaf, 0xf7,
+	0x47, 0xfb, 0xb1, 0xaa, 0x80, 0x5a, 0x50, 0x1f, 0x1c, 0x8d, 0xc6, 0x07, 0x83, 0xf1, 0xf0, 0xbb,
+	0xc1, 0x5e, 0xbb, 0xb8, 0x7e, 0x1f, 0x20, 0x1d, 0x05, 0xa8, 0x02, 0xc5, 0xc1, 0x48, 0x1d, 0xa1,
+	0x02, 0xc5, 0xe3, 0x57, 0x7b, 0x6d, 0x6b, 0xeb, 0xcf, 0x82, 0x79, 0xc8, 0x0f, 0x09, 0x3f, 0xa7,
+	0x2e, 0x41, 0xdb, 0xd1, 0xcb, 0xeb, 0xce, 0x95, 0x77, 0xba, 0x79, 0xe9, 0xaf, 0xa2, 0xec, 0xa5,
+	0x23, 0x6a, 0xb6, 0x4c, 0x00, 0x4c, 0xa7, 0xeb, 0x64, 0x01, 0x1f, 0xf6, 0xe9, 0x5f, 0xee, 0x15,
+	0xb7, 0xaf, 0x84, 0x7a, 0x5f, 0xfd, 0xce, 0x59, 0xbd, 0xf7, 0x4f, 0x65, 0x12, 0xaf, 0xf2, 0x02,
+	0x1a, 0x6f, 0xb1, 0x74, 0xa7, 0x71, 0xa1, 0x7c, 0x70, 0x95, 0x95, 0xcc, 0x58, 0x8c, 0x0b, 0xea,
+	0x53, 0x0b, 0x7d, 0x99, 0x4d, 0x9d, 0x0f, 0xf9, 0xde, 0xb9, 0x1c, 0xe7, 0x68, 0xf7, 0xdd, 0x5d,
+	0x58, 0x76, 0x99, 0x6f, 0xac, 0x38, 0xa4, 0xbb, 0x15, 0x05, 0xd9, 0x09, 0xe9, 0xb1, 0xf5, 0xfd,
+	0xc3, 0x33, 0x2a, 0xa7, 0xb3, 0x13, 0x95, 0x7a, 0x9b, 0x12, 0x7b, 0x4c, 0x3c, 0x16, 0x73, 0x21,
+	0x89, 0x2f, 0x8c, 0xb4, 0x89, 0x43, 0xaa, 0x7f, 0xaa, 0x9d, 0x94, 0xf5, 0x66, 0xdb, 0x7f, 0x07,
+	0x00, 0x00, 0xff, 0xff, 0x42, 0x60, 0x34, 0xb2, 0x1d, 0x0e, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -231,8 +1242,11 @@ const _ = grpc.SupportPackageIsVersion4
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type TimeServiceClient interface {
-	Time(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*TimeResponse, error)
+	Time(ctx context.Context, in *TimeFormatRequest, opts ...grpc.CallOption) (*TimeResponse, error)
 	TimeCheck(ctx context.Context, in *TimeRequest, opts ...grpc.CallOption) (*TimeResponse, error)
+	SyncTolerance(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*SyncToleranceResponse, error)
+	WatchTimeSync(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (TimeService_WatchTimeSyncClient, error)
+	TimeReady(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*TimeReadyResponse, error)
 }
 
 type timeServiceClient struct {
@@ -243,7 +1257,7 @@ func NewTimeServiceClient(cc *grpc.ClientConn) TimeServiceClient {
 	return &timeServiceClient{cc}
 }
 
-func (c *timeServiceClient) Time(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*TimeResponse, error) {
+func (c *timeServiceClient) Time(ctx context.Context, in *TimeFormatRequest, opts ...grpc.CallOption) (*TimeResponse, error) {
 	out := new(TimeResponse)
 	err := c.cc.Invoke(ctx, "/time.TimeService/Time", in, out, opts...)
 	if err != nil {
@@ -261,10 +1275,63 @@ func (c *timeServiceClient) TimeCheck(ctx context.Context, in *TimeRequest, opts
 	return out, nil
 }
 
+func (c *timeServiceClient) SyncTolerance(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*SyncToleranceResponse, error) {
+	out := new(SyncToleranceResponse)
+	err := c.cc.Invoke(ctx, "/time.TimeService/SyncTolerance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *timeServiceClient) WatchTimeSync(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (TimeService_WatchTimeSyncClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TimeService_serviceDesc.Streams[0], "/time.TimeService/WatchTimeSync", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &timeServiceWatchTimeSyncClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TimeService_WatchTimeSyncClient interface {
+	Recv() (*TimeSyncState, error)
+	grpc.ClientStream
+}
+
+type timeServiceWatchTimeSyncClient struct {
+	grpc.ClientStream
+}
+
+func (x *timeServiceWatchTimeSyncClient) Recv() (*TimeSyncState, error) {
+	m := new(TimeSyncState)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *timeServiceClient) TimeReady(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*TimeReadyResponse, error) {
+	out := new(TimeReadyResponse)
+	err := c.cc.Invoke(ctx, "/time.TimeService/TimeReady", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TimeServiceServer is the server API for TimeService service.
 type TimeServiceServer interface {
-	Time(context.Context, *empty.Empty) (*TimeResponse, error)
+	Time(context.Context, *TimeFormatRequest) (*TimeResponse, error)
 	TimeCheck(context.Context, *TimeRequest) (*TimeResponse, error)
+	SyncTolerance(context.Context, *empty.Empty) (*SyncToleranceResponse, error)
+	WatchTimeSync(*empty.Empty, TimeService_WatchTimeSyncServer) error
+	TimeReady(context.Context, *empty.Empty) (*TimeReadyResponse, error)
 }
 
 func RegisterTimeServiceServer(s *grpc.Server, srv TimeServiceServer) {
@@ -272,7 +1339,7 @@ func RegisterTimeServiceServer(s *grpc.Server, srv TimeServiceServer) {
 }
 
 func _TimeService_Time_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+	in := new(TimeFormatRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -284,7 +1351,7 @@ func _TimeService_Time_Handler(srv interface{}, ctx context.Context, dec func(in
 		FullMethod: "/time.TimeService/Time",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TimeServiceServer).Time(ctx, req.(*empty.Empty))
+		return srv.(TimeServiceServer).Time(ctx, req.(*TimeFormatRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -307,6 +1374,63 @@ func _TimeService_TimeCheck_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TimeService_SyncTolerance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeServiceServer).SyncTolerance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/time.TimeService/SyncTolerance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeServiceServer).SyncTolerance(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TimeService_WatchTimeSync_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(empty.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TimeServiceServer).WatchTimeSync(m, &timeServiceWatchTimeSyncServer{stream})
+}
+
+type TimeService_WatchTimeSyncServer interface {
+	Send(*TimeSyncState) error
+	grpc.ServerStream
+}
+
+type timeServiceWatchTimeSyncServer struct {
+	grpc.ServerStream
+}
+
+func (x *timeServiceWatchTimeSyncServer) Send(m *TimeSyncState) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TimeService_TimeReady_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeServiceServer).TimeReady(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/time.TimeService/TimeReady",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeServiceServer).TimeReady(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _TimeService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "time.TimeService",
 	HandlerType: (*TimeServiceServer)(nil),
@@ -319,7 +1443,21 @@ var _TimeService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "TimeCheck",
 			Handler:    _TimeService_TimeCheck_Handler,
 		},
+		{
+			MethodName: "SyncTolerance",
+			Handler:    _TimeService_SyncTolerance_Handler,
+		},
+		{
+			MethodName: "TimeReady",
+			Handler:    _TimeService_TimeReady_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTimeSync",
+			Handler:       _TimeService_WatchTimeSync_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "time/time.proto",
 }
@@ -0,0 +1,111 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	machineapi "github.com/talos-systems/talos/api/machine"
+	"github.com/talos-systems/talos/cmd/talosctl/pkg/talos/helpers"
+	"github.com/talos-systems/talos/pkg/client"
+)
+
+// eventsCmd represents the events command
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Stream the progress of sequences",
+	Long:  `Streams the lifecycle events of the sequences (e.g. upgrade, reboot) run by the nodes, until interrupted.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return WithClient(func(ctx context.Context, c *client.Client) error {
+			stream, err := c.EventsStream(ctx)
+			if err != nil {
+				return fmt.Errorf("error getting events: %w", err)
+			}
+
+			defaultNode := helpers.RemotePeer(stream.Context())
+
+			for {
+				resp, err := stream.Recv()
+				if err != nil {
+					if err == io.EOF || status.Code(err) == codes.Canceled {
+						break
+					}
+
+					return fmt.Errorf("error reading from stream: %w", err)
+				}
+
+				node := defaultNode
+				if resp.Metadata != nil {
+					node = resp.Metadata.Hostname
+
+					if resp.Metadata.Error != "" {
+						fmt.Fprintf(os.Stderr, "%s: %s\n", node, resp.Metadata.Error)
+
+						continue
+					}
+				}
+
+				fmt.Printf("%s: %s\n", node, formatSequenceEvent(resp))
+			}
+
+			return nil
+		})
+	},
+}
+
+func formatSequenceEvent(e *machineapi.SequenceEvent) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s %s", e.Sequence, e.Type)
+
+	switch e.Type {
+	case machineapi.SequenceEventType_PHASE_STARTED:
+		fmt.Fprintf(&sb, " phase %d/%d", e.Phase, e.PhaseTotal)
+	case machineapi.SequenceEventType_TASK_STARTED, machineapi.SequenceEventType_TASK_FINISHED:
+		fmt.Fprintf(&sb, " phase %d/%d task %d/%d %s", e.Phase, e.PhaseTotal, e.Task, e.TaskTotal, e.TaskName)
+	case machineapi.SequenceEventType_TASK_PROGRESS:
+		fmt.Fprintf(&sb, " phase %d/%d task %d/%d %s: %s", e.Phase, e.PhaseTotal, e.Task, e.TaskTotal, e.TaskName, formatTaskProgress(e.Progress))
+	case machineapi.SequenceEventType_TASK_MESSAGE:
+		fmt.Fprintf(&sb, " phase %d/%d task %d/%d %s: %s", e.Phase, e.PhaseTotal, e.Task, e.TaskTotal, e.TaskName, e.Message)
+	}
+
+	if elapsed, err := ptypes.Duration(e.Elapsed); err == nil && elapsed > 0 {
+		fmt.Fprintf(&sb, " (%s)", elapsed)
+	}
+
+	if e.Error != "" {
+		fmt.Fprintf(&sb, ": %s", e.Error)
+	}
+
+	return sb.String()
+}
+
+// formatTaskProgress formats the progress as e.g. `42% (420/1000 bytes)`, or
+// without the percentage if the total is not known.
+func formatTaskProgress(p *machineapi.TaskProgress) string {
+	if p == nil {
+		return "?"
+	}
+
+	if p.Total <= 0 {
+		return strings.TrimSpace(fmt.Sprintf("%d %s", p.Current, p.Unit))
+	}
+
+	return fmt.Sprintf("%d%% (%s)", p.Current*100/p.Total, strings.TrimSpace(fmt.Sprintf("%d/%d %s", p.Current, p.Total, p.Unit)))
+}
+
+func init() {
+	addCommand(eventsCmd)
+}
@@ -0,0 +1,46 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	machineapi "github.com/talos-systems/talos/api/machine"
+	"github.com/talos-systems/talos/pkg/client"
+)
+
+var maintenanceCmdFlags struct {
+	resume bool
+}
+
+// maintenanceCmd represents the maintenance command.
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Stop the workloads of the nodes, keeping them up for maintenance",
+	Long:  `Drains the nodes, and stops the Kubernetes workloads along with the services of the maintenance config, while the API keeps running. The nodes stay in maintenance until resumed with --resume, which starts the services again, and uncordons the nodes.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sequence := "maintenance"
+		if maintenanceCmdFlags.resume {
+			sequence = "resume"
+		}
+
+		return WithClient(func(ctx context.Context, c *client.Client) error {
+			if _, err := c.RunSequence(ctx, &machineapi.RunSequenceRequest{Sequence: sequence}); err != nil {
+				return fmt.Errorf("error executing %s: %s", sequence, err)
+			}
+
+			return nil
+		})
+	},
+}
+
+func init() {
+	maintenanceCmd.Flags().BoolVarP(&maintenanceCmdFlags.resume, "resume", "r", false, "resume the nodes from maintenance")
+	addCommand(maintenanceCmd)
+}
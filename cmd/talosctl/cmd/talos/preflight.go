@@ -0,0 +1,121 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	machineapi "github.com/talos-systems/talos/api/machine"
+	"github.com/talos-systems/talos/cmd/talosctl/pkg/talos/helpers"
+	"github.com/talos-systems/talos/pkg/cli"
+	"github.com/talos-systems/talos/pkg/client"
+)
+
+var preflightCmdFlags struct {
+	image    string
+	preserve bool
+	stage    bool
+	graceful bool
+}
+
+// preflightCmd represents the preflight command.
+var preflightCmd = &cobra.Command{
+	Use:   "preflight <sequence>",
+	Short: "Check that the nodes are ready to run a sequence",
+	Long:  `Runs the pre-flight checks of a sequence (e.g. upgrade) on the nodes, without running the sequence, and lists the outcome of each check. The upgrade and reset sequences are checked for the request set by the flags, as with the upgrade and reset commands.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := &machineapi.RunSequenceRequest{
+			Sequence: args[0],
+		}
+
+		switch args[0] {
+		case "upgrade":
+			req.Upgrade = &machineapi.UpgradeRequest{
+				Image:    preflightCmdFlags.image,
+				Preserve: preflightCmdFlags.preserve,
+				Stage:    preflightCmdFlags.stage,
+			}
+		case "reset":
+			req.Reset_ = &machineapi.ResetRequest{
+				Graceful: preflightCmdFlags.graceful,
+			}
+		}
+
+		return WithClient(func(ctx context.Context, c *client.Client) error {
+			var remotePeer peer.Peer
+
+			resp, err := c.Preflight(ctx, req, grpc.Peer(&remotePeer))
+			if err != nil {
+				if resp == nil {
+					return fmt.Errorf("error running pre-flight checks: %s", err)
+				}
+
+				cli.Warning("%s", err)
+			}
+
+			return preflightRender(&remotePeer, resp)
+		})
+	},
+}
+
+// preflightRender renders the outcome of the checks, and returns an error if
+// any of the nodes is not ready.
+func preflightRender(remotePeer *peer.Peer, resp *machineapi.PreflightResponse) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tSEQUENCE\tCHECK\tDURATION\tRESULT")
+
+	defaultNode := helpers.AddrFromPeer(remotePeer)
+
+	ready := true
+
+	for _, msg := range resp.Messages {
+		node := defaultNode
+
+		if msg.Metadata != nil {
+			node = msg.Metadata.Hostname
+		}
+
+		ready = ready && msg.Ready
+
+		for _, check := range msg.Checks {
+			elapsed, _ := ptypes.Duration(check.Duration) //nolint: errcheck
+
+			result := "passed"
+			if check.Error != "" {
+				result = "failed: " + check.Error
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", node, msg.Sequence, check.Name, elapsed, result)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !ready {
+		return errors.New("pre-flight checks failed")
+	}
+
+	return nil
+}
+
+func init() {
+	preflightCmd.Flags().StringVarP(&preflightCmdFlags.image, "image", "i", "", "the container image of the upgrade")
+	preflightCmd.Flags().BoolVarP(&preflightCmdFlags.preserve, "preserve", "p", false, "preserve data on upgrade")
+	preflightCmd.Flags().BoolVarP(&preflightCmdFlags.stage, "stage", "s", false, "stage the upgrade to perform it on the next reboot")
+	preflightCmd.Flags().BoolVar(&preflightCmdFlags.graceful, "graceful", true, "cordon/drain the node and leave etcd on reset")
+	addCommand(preflightCmd)
+}
@@ -16,6 +16,7 @@ import (
 var (
 	graceful bool
 	reboot   bool
+	preserve []string
 )
 
 // resetCmd represents the reset command
@@ -26,7 +27,7 @@ var resetCmd = &cobra.Command{
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return WithClient(func(ctx context.Context, c *client.Client) error {
-			if err := c.Reset(ctx, graceful, reboot); err != nil {
+			if err := c.ResetPreserving(ctx, graceful, reboot, preserve); err != nil {
 				return fmt.Errorf("error executing reset: %s", err)
 			}
 
@@ -38,5 +39,6 @@ var resetCmd = &cobra.Command{
 func init() {
 	resetCmd.Flags().BoolVar(&graceful, "graceful", true, "if true, attempt to cordon/drain node and leave etcd (if applicable)")
 	resetCmd.Flags().BoolVar(&reboot, "reboot", false, "if true, reboot the node after resetting instead of shutting down")
+	resetCmd.Flags().StringSliceVar(&preserve, "preserve", nil, "the partitions of the system disk to preserve, by label or mount point (e.g. /boot), wiping the others")
 	addCommand(resetCmd)
 }
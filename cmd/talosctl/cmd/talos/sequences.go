@@ -0,0 +1,92 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	machineapi "github.com/talos-systems/talos/api/machine"
+	"github.com/talos-systems/talos/cmd/talosctl/pkg/talos/helpers"
+	"github.com/talos-systems/talos/pkg/cli"
+	"github.com/talos-systems/talos/pkg/client"
+)
+
+// sequencesCmd represents the sequences command.
+var sequencesCmd = &cobra.Command{
+	Use:   "sequences [<sequence>]",
+	Short: "List the phases and tasks of the sequences",
+	Long:  `Lists the phases and tasks that each sequence (e.g. upgrade) would run on the nodes, in order. The tasks of a phase run concurrently. The sequences depending on a request are listed for the request with the default options.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return WithClient(func(ctx context.Context, c *client.Client) error {
+			var remotePeer peer.Peer
+
+			resp, err := c.Sequences(ctx, grpc.Peer(&remotePeer))
+			if err != nil {
+				if resp == nil {
+					return fmt.Errorf("error getting sequences: %s", err)
+				}
+
+				cli.Warning("%s", err)
+			}
+
+			var sequence string
+
+			if len(args) > 0 {
+				sequence = args[0]
+			}
+
+			return sequencesRender(&remotePeer, resp, sequence)
+		})
+	},
+}
+
+// sequencesRender renders the plans of the sequences, or of the named
+// sequence only.
+func sequencesRender(remotePeer *peer.Peer, resp *machineapi.SequencesResponse, sequence string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tSEQUENCE\tPHASE\tTASK\tDESCRIPTION")
+
+	defaultNode := helpers.AddrFromPeer(remotePeer)
+
+	for _, msg := range resp.Messages {
+		node := defaultNode
+
+		if msg.Metadata != nil {
+			node = msg.Metadata.Hostname
+		}
+
+		for _, plan := range msg.Plans {
+			if sequence != "" && plan.Sequence != sequence {
+				continue
+			}
+
+			for _, phase := range plan.Phases {
+				number := fmt.Sprintf("%d/%d", phase.Number, len(plan.Phases))
+
+				if phase.Overlap {
+					number += " (overlaps)"
+				}
+
+				for _, task := range phase.Tasks {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", node, plan.Sequence, number, task.Name, task.Label)
+				}
+			}
+		}
+	}
+
+	return w.Flush()
+}
+
+func init() {
+	addCommand(sequencesCmd)
+}
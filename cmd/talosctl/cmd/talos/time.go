@@ -6,15 +6,22 @@ package talos
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 
 	timeapi "github.com/talos-systems/talos/api/time"
 	"github.com/talos-systems/talos/cmd/talosctl/pkg/talos/helpers"
@@ -24,26 +31,75 @@ import (
 
 // timeCmd represents the time command
 var timeCmd = &cobra.Command{
-	Use:   "time [--check server]",
+	Use:   "time [--check server,...] [--format rfc3339|unix] [--verbose] [--watch]",
 	Short: "Gets current server time",
 	Long:  ``,
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return WithClient(func(ctx context.Context, c *client.Client) error {
-			server, err := cmd.Flags().GetString("check")
+			servers, err := cmd.Flags().GetStringSlice("check")
 			if err != nil {
 				return fmt.Errorf("failed to parse check flag: %w", err)
 			}
 
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return fmt.Errorf("failed to parse format flag: %w", err)
+			}
+
+			verbose, err := cmd.Flags().GetBool("verbose")
+			if err != nil {
+				return fmt.Errorf("failed to parse verbose flag: %w", err)
+			}
+
+			watch, err := cmd.Flags().GetBool("watch")
+			if err != nil {
+				return fmt.Errorf("failed to parse watch flag: %w", err)
+			}
+
+			if watch {
+				return watchTimeSync(ctx, c)
+			}
+
+			var formats []timeapi.TimeFormat
+
+			switch format {
+			case "":
+			case "rfc3339":
+				formats = append(formats, timeapi.TimeFormat_RFC3339)
+			case "unix":
+				formats = append(formats, timeapi.TimeFormat_UNIX)
+			default:
+				return fmt.Errorf("unsupported format: %q", format)
+			}
+
 			var (
 				resp       *timeapi.TimeResponse
 				remotePeer peer.Peer
 			)
 
-			if server == "" {
-				resp, err = c.Time(ctx, grpc.Peer(&remotePeer))
-			} else {
-				resp, err = c.TimeCheck(ctx, server, grpc.Peer(&remotePeer))
+			req := &timeapi.TimeRequest{Formats: formats, Verbose: verbose}
+
+			for _, server := range servers {
+				if server != "" {
+					req.Servers = append(req.Servers, server)
+				}
+			}
+
+			switch len(req.Servers) {
+			case 0:
+				// The packets are only reported by the checks.
+				if verbose {
+					return errors.New("--verbose requires --check")
+				}
+
+				resp, err = c.TimeFormatted(ctx, formats, grpc.Peer(&remotePeer))
+			case 1:
+				req.Server, req.Servers = req.Servers[0], nil
+
+				fallthrough
+			default:
+				resp, err = c.TimeCheckWithRequest(ctx, req, grpc.Peer(&remotePeer))
 			}
 
 			if err != nil {
@@ -55,7 +111,7 @@ var timeCmd = &cobra.Command{
 			}
 
 			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-			fmt.Fprintln(w, "NODE\tNTP-SERVER\tLOCAL-TIME\tREMOTE-TIME")
+			fmt.Fprintln(w, "NODE\tNTP-SERVER\tLOCAL-TIME\tREMOTE-TIME\tOFFSET\tRTT\tJITTER\tMAX-ERROR\tSTATUS")
 
 			defaultNode := helpers.AddrFromPeer(&remotePeer)
 
@@ -71,20 +127,256 @@ var timeCmd = &cobra.Command{
 				if err != nil {
 					return fmt.Errorf("error parsing local time: %w", err)
 				}
+
+				if msg.Error != "" {
+					status := "error: " + msg.Error
+
+					switch {
+					case msg.Retrying:
+						status = "not yet synced, retrying: " + msg.Error
+					case msg.Stratum >= 16:
+						status = "server not synchronized (stratum 16)"
+					case msg.Stratum == 0 && msg.ReferenceId != "":
+						status = "rejected by the server, kiss code " + msg.ReferenceId
+					}
+
+					// The server did not respond, so there is no remote time.
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", node, msg.Server, localtime.String(), "", "", "", "", "", status)
+
+					continue
+				}
+
 				remotetime, err = ptypes.Timestamp(msg.Remotetime)
 				if err != nil {
 					return fmt.Errorf("error parsing remote time: %w", err)
 				}
 
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", node, msg.Server, localtime.String(), remotetime.String())
+				local, remote := localtime.String(), remotetime.String()
+
+				switch format {
+				case "rfc3339":
+					local, remote = msg.LocaltimeRfc3339, msg.RemotetimeRfc3339
+				case "unix":
+					local, remote = msg.LocaltimeUnix, msg.RemotetimeUnix
+				}
+
+				var offset, rtt, jitter, maxError, status string
+
+				if offset, err = formatDuration(msg.Offset); err != nil {
+					return fmt.Errorf("error parsing offset: %w", err)
+				}
+
+				if rtt, err = formatDuration(msg.Rtt); err != nil {
+					return fmt.Errorf("error parsing rtt: %w", err)
+				}
+
+				if jitter, err = formatDuration(msg.Jitter); err != nil {
+					return fmt.Errorf("error parsing jitter: %w", err)
+				}
+
+				if maxError, err = formatDuration(msg.MaxError); err != nil {
+					return fmt.Errorf("error parsing max error: %w", err)
+				}
+
+				if maxError != "" {
+					maxError = "±" + maxError
+				}
+
+				switch {
+				case msg.Selected:
+					status = "selected"
+				case msg.Outlier:
+					status = "outlier"
+				}
+
+				if msg.Source == timeapi.TimeSource_PHC {
+					status = strings.TrimPrefix(status+", PTP hardware clock", ", ")
+				}
+
+				switch msg.Leap {
+				case timeapi.LeapIndicator_ADD_SECOND:
+					status = strings.TrimPrefix(status+", leap second pending", ", ")
+				case timeapi.LeapIndicator_DELETE_SECOND:
+					status = strings.TrimPrefix(status+", negative leap second pending", ", ")
+				case timeapi.LeapIndicator_NO_WARNING, timeapi.LeapIndicator_NOT_IN_SYNC:
+				}
+
+				if msg.ExceedsMaxStep {
+					status = strings.TrimPrefix(status+", exceeds max step", ", ")
+				}
+
+				if check := msg.SanityCheck; check != nil {
+					if check.Passed {
+						status = strings.TrimPrefix(status+", sanity check passed", ", ")
+					} else {
+						status = strings.TrimPrefix(status+", sanity check failed: "+check.Error, ", ")
+					}
+				}
+
+				if drift := msg.Drift; drift != nil {
+					status = strings.TrimPrefix(fmt.Sprintf("%s, drift %.3f ppm", status, drift.Ppm), ", ")
+
+					if drift.Warning {
+						status += fmt.Sprintf(" (exceeds %g ppm, the oscillator may be failing)", drift.Threshold)
+					}
+				}
+
+				if msg.RelativeOffset != nil && !msg.Selected {
+					var relative string
+
+					if relative, err = formatDuration(msg.RelativeOffset); err != nil {
+						return fmt.Errorf("error parsing relative offset: %w", err)
+					}
+
+					status = strings.TrimPrefix(status+", "+relative+" from selected", ", ")
+				}
+
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", node, msg.Server, local, remote, offset, rtt, jitter, maxError, status)
+			}
+
+			if err = w.Flush(); err != nil {
+				return err
 			}
 
-			return w.Flush()
+			if verbose {
+				return printNTPPackets(resp, defaultNode)
+			}
+
+			return nil
 		})
 	},
 }
 
+// formatDuration returns the string representation of a duration, or an empty
+// string if it is not set.
+func formatDuration(d *duration.Duration) (string, error) {
+	if d == nil {
+		return "", nil
+	}
+
+	v, err := ptypes.Duration(d)
+	if err != nil {
+		return "", err
+	}
+
+	return v.String(), nil
+}
+
+func watchTimeSync(ctx context.Context, c *client.Client) error {
+	stream, err := c.WatchTimeSync(ctx)
+	if err != nil {
+		return fmt.Errorf("error watching time sync: %w", err)
+	}
+
+	defaultNode := helpers.RemotePeer(stream.Context())
+
+	for {
+		var (
+			resp                    *timeapi.TimeSyncState
+			offset, since, maxError string
+		)
+
+		if resp, err = stream.Recv(); err != nil {
+			if err == io.EOF || status.Code(err) == codes.Canceled {
+				return nil
+			}
+
+			return fmt.Errorf("error reading from stream: %w", err)
+		}
+
+		node := defaultNode
+		if resp.Metadata != nil {
+			node = resp.Metadata.Hostname
+
+			if resp.Metadata.Error != "" {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", node, resp.Metadata.Error)
+
+				continue
+			}
+		}
+
+		if resp.SinceLastSync == nil {
+			fmt.Printf("%s: not synced yet\n", node)
+
+			continue
+		}
+
+		if offset, err = formatDuration(resp.Offset); err != nil {
+			return fmt.Errorf("error parsing offset: %w", err)
+		}
+
+		if since, err = formatDuration(resp.SinceLastSync); err != nil {
+			return fmt.Errorf("error parsing time since last sync: %w", err)
+		}
+
+		if maxError, err = formatDuration(resp.MaxError); err != nil {
+			return fmt.Errorf("error parsing max error: %w", err)
+		}
+
+		// Servers which predate the max error do not report it.
+		if maxError != "" {
+			maxError = " max-error=±" + maxError
+		}
+
+		fmt.Printf("%s: synchronized=%t offset=%s%s server=%s last sync %s ago\n", node, resp.Synchronized, offset, maxError, resp.Server, since)
+	}
+}
+
+func printNTPPackets(resp *timeapi.TimeResponse, defaultNode string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tSTRATUM\tREFID\tORIGINATE\tRECEIVE\tTRANSMIT\tREFERENCE\tPRECISION\tROOT-DELAY\tROOT-DISPERSION\tPOLL")
+
+	for _, msg := range resp.Messages {
+		if msg.Packet == nil {
+			continue
+		}
+
+		node := defaultNode
+
+		if msg.Metadata != nil {
+			node = msg.Metadata.Hostname
+		}
+
+		var (
+			timestamps [4]time.Time
+			durations  [4]time.Duration
+			err        error
+		)
+
+		for i, ts := range []*timestamp.Timestamp{msg.Packet.Originate, msg.Packet.Receive, msg.Packet.Transmit, msg.Packet.Reference} {
+			if timestamps[i], err = ptypes.Timestamp(ts); err != nil {
+				return fmt.Errorf("error parsing packet timestamp: %w", err)
+			}
+		}
+
+		for i, d := range []*duration.Duration{msg.Packet.Precision, msg.Packet.RootDelay, msg.Packet.RootDispersion, msg.Packet.Poll} {
+			if durations[i], err = ptypes.Duration(d); err != nil {
+				return fmt.Errorf("error parsing packet duration: %w", err)
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			node,
+			msg.Stratum,
+			msg.ReferenceId,
+			timestamps[0].Format(time.RFC3339Nano),
+			timestamps[1].Format(time.RFC3339Nano),
+			timestamps[2].Format(time.RFC3339Nano),
+			timestamps[3].Format(time.RFC3339Nano),
+			durations[0],
+			durations[1],
+			durations[2],
+			durations[3],
+		)
+	}
+
+	return w.Flush()
+}
+
 func init() {
-	timeCmd.Flags().StringP("check", "c", "pool.ntp.org", "checks server time against specified ntp server")
+	timeCmd.Flags().StringSliceP("check", "c", []string{"pool.ntp.org"}, "checks server time against specified ntp server, several servers are compared with each other")
+	timeCmd.Flags().String("format", "", "prints the times in the specified format (rfc3339, unix)")
+	timeCmd.Flags().BoolP("verbose", "v", false, "prints the details of the ntp response packet of the check servers")
+	timeCmd.Flags().BoolP("watch", "w", false, "streams the time sync state after every sync, until interrupted")
 	addCommand(timeCmd)
 }
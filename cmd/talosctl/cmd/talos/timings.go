@@ -0,0 +1,132 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	machineapi "github.com/talos-systems/talos/api/machine"
+	"github.com/talos-systems/talos/cmd/talosctl/pkg/talos/helpers"
+	"github.com/talos-systems/talos/pkg/cli"
+	"github.com/talos-systems/talos/pkg/client"
+)
+
+var timingsHistory bool
+
+// timingsCmd represents the timings command.
+var timingsCmd = &cobra.Command{
+	Use:   "timings",
+	Short: "Show the timing breakdown of the last sequence",
+	Long:  `Lists the phases and tasks of the most recent sequence (e.g. boot) run by the nodes, with the time each took and any error. With --history, the breakdowns of the recent sequences kept by the nodes are listed, the oldest first.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return WithClient(func(ctx context.Context, c *client.Client) error {
+			var remotePeer peer.Peer
+
+			if timingsHistory {
+				resp, err := c.SequenceHistory(ctx, grpc.Peer(&remotePeer))
+				if err != nil {
+					if resp == nil {
+						return fmt.Errorf("error getting sequence history: %s", err)
+					}
+
+					cli.Warning("%s", err)
+				}
+
+				return historyRender(&remotePeer, resp)
+			}
+
+			resp, err := c.SequenceResult(ctx, grpc.Peer(&remotePeer))
+			if err != nil {
+				if resp == nil {
+					return fmt.Errorf("error getting sequence result: %s", err)
+				}
+
+				cli.Warning("%s", err)
+			}
+
+			return timingsRender(&remotePeer, resp)
+		})
+	},
+}
+
+func timingsRender(remotePeer *peer.Peer, resp *machineapi.SequenceResultResponse) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tSEQUENCE\tPHASE\tTASK\tSTART\tDURATION\tERROR")
+
+	defaultNode := helpers.AddrFromPeer(remotePeer)
+
+	for _, msg := range resp.Messages {
+		node := defaultNode
+
+		if msg.Metadata != nil {
+			node = msg.Metadata.Hostname
+		}
+
+		resultRender(w, node, msg, "")
+	}
+
+	return w.Flush()
+}
+
+// historyRender renders the sequences like timingsRender, with the time each
+// sequence started at.
+func historyRender(remotePeer *peer.Peer, resp *machineapi.SequenceHistoryResponse) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tSEQUENCE\tPHASE\tTASK\tSTART\tDURATION\tERROR")
+
+	defaultNode := helpers.AddrFromPeer(remotePeer)
+
+	for _, msg := range resp.Messages {
+		node := defaultNode
+
+		if msg.Metadata != nil {
+			node = msg.Metadata.Hostname
+		}
+
+		for _, result := range msg.Results {
+			start, _ := ptypes.Timestamp(result.Start) //nolint: errcheck
+
+			resultRender(w, node, result, start.Format(time.RFC3339))
+		}
+	}
+
+	return w.Flush()
+}
+
+func resultRender(w io.Writer, node string, msg *machineapi.SequenceResult, start string) {
+	total, _ := ptypes.Duration(msg.Duration) //nolint: errcheck
+
+	fmt.Fprintf(w, "%s\t%s\t\t\t%s\t%s\t%s\n", node, msg.Sequence, start, total, msg.Error)
+
+	for i, phase := range msg.Phases {
+		for _, task := range phase.Tasks {
+			start, _ := ptypes.Duration(task.Start)      //nolint: errcheck
+			elapsed, _ := ptypes.Duration(task.Duration) //nolint: errcheck
+
+			status := task.Error
+			if task.Degraded != "" {
+				status = "degraded: " + task.Degraded
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%d/%d\t%s\t%s\t%s\t%s\n", node, msg.Sequence, i+1, len(msg.Phases), task.Name, start, elapsed, status)
+		}
+	}
+}
+
+func init() {
+	timingsCmd.Flags().BoolVar(&timingsHistory, "history", false, "list the recent sequences kept by the nodes")
+	addCommand(timingsCmd)
+}
@@ -7,6 +7,7 @@ package talos
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"text/tabwriter"
 	"time"
@@ -15,14 +16,18 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/peer"
 
+	machineapi "github.com/talos-systems/talos/api/machine"
 	"github.com/talos-systems/talos/cmd/talosctl/pkg/talos/helpers"
 	"github.com/talos-systems/talos/pkg/cli"
 	"github.com/talos-systems/talos/pkg/client"
 )
 
 var (
-	upgradeImage string
-	preserve     bool
+	upgradeImage         string
+	preserve             bool
+	stage                bool
+	upgradeSignatureFile string
+	upgradePublicKeyFile string
 )
 
 // upgradeCmd represents the processes command
@@ -39,16 +44,43 @@ var upgradeCmd = &cobra.Command{
 func init() {
 	upgradeCmd.Flags().StringVarP(&upgradeImage, "image", "i", "", "the container image to use for performing the install")
 	upgradeCmd.Flags().BoolVarP(&preserve, "preserve", "p", false, "preserve data")
+	upgradeCmd.Flags().BoolVarP(&stage, "stage", "s", false, "stage the upgrade to perform it on the next reboot, which requires --preserve")
+	upgradeCmd.Flags().StringVar(&upgradeSignatureFile, "signature", "", "the file with a detached signature of the manifest digest of the image, to verify instead of its cosign signatures")
+	upgradeCmd.Flags().StringVar(&upgradePublicKeyFile, "public-key", "", "the file with the PEM encoded public key to verify the image against, if the config has no image verification keys")
 	addCommand(upgradeCmd)
 }
 
 func upgrade() error {
+	req := &machineapi.UpgradeRequest{
+		Image:    upgradeImage,
+		Preserve: preserve,
+		Stage:    stage,
+	}
+
+	if upgradeSignatureFile != "" {
+		signature, err := ioutil.ReadFile(upgradeSignatureFile)
+		if err != nil {
+			return fmt.Errorf("error reading the signature: %w", err)
+		}
+
+		req.Signature = signature
+	}
+
+	if upgradePublicKeyFile != "" {
+		key, err := ioutil.ReadFile(upgradePublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("error reading the public key: %w", err)
+		}
+
+		req.PublicKey = string(key)
+	}
+
 	return WithClient(func(ctx context.Context, c *client.Client) error {
 		var remotePeer peer.Peer
 
 		// TODO: See if we can validate version and prevent starting upgrades to
 		// an unknown version
-		resp, err := c.Upgrade(ctx, upgradeImage, preserve, grpc.Peer(&remotePeer))
+		resp, err := c.UpgradeWithRequest(ctx, req, grpc.Peer(&remotePeer))
 		if err != nil {
 			if resp == nil {
 				return fmt.Errorf("error performing upgrade: %s", err)
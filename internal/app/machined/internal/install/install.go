@@ -40,6 +40,10 @@ func RunInstallerContainer(disk, platform, ref string, reg runtime.Registries, o
 
 	ctx := namespaces.WithNamespace(context.Background(), constants.SystemContainerdNamespace)
 
+	if options.Progress != nil {
+		ctx = runtime.WithProgress(ctx, options.Progress)
+	}
+
 	client, err := containerd.New(constants.SystemContainerdAddress)
 	if err != nil {
 		return err
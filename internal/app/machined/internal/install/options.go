@@ -4,6 +4,8 @@
 
 package install
 
+import "github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+
 // Option is a functional option.
 type Option func(o *Options) error
 
@@ -14,6 +16,8 @@ type Options struct {
 	Upgrade         bool
 	Zero            bool
 	ExtraKernelArgs []string
+	// Progress receives the progress of the pull of the installer image.
+	Progress runtime.ProgressFunc
 }
 
 // DefaultInstallOptions returns default options.
@@ -67,3 +71,12 @@ func WithExtraKernelArgs(s []string) Option {
 		return nil
 	}
 }
+
+// WithProgress sets the receiver of the progress of the pull.
+func WithProgress(f runtime.ProgressFunc) Option {
+	return func(o *Options) error {
+		o.Progress = f
+
+		return nil
+	}
+}
@@ -24,20 +24,24 @@ import (
 	"github.com/containerd/containerd/namespaces"
 	"github.com/containerd/containerd/oci"
 	criconstants "github.com/containerd/cri/pkg/constants"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/hashicorp/go-multierror"
+	"github.com/opencontainers/go-digest"
 	"golang.org/x/sys/unix"
 	"google.golang.org/grpc"
 
 	"github.com/talos-systems/talos/api/common"
 	"github.com/talos-systems/talos/api/machine"
 	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	v1alpha1runtime "github.com/talos-systems/talos/internal/app/machined/pkg/runtime/v1alpha1"
 	"github.com/talos-systems/talos/internal/app/machined/pkg/system"
 	"github.com/talos-systems/talos/internal/pkg/containers"
 	taloscontainerd "github.com/talos-systems/talos/internal/pkg/containers/containerd"
 	"github.com/talos-systems/talos/internal/pkg/containers/cri"
 	"github.com/talos-systems/talos/internal/pkg/containers/image"
 	"github.com/talos-systems/talos/internal/pkg/etcd"
+	"github.com/talos-systems/talos/internal/pkg/imagesig"
 	"github.com/talos-systems/talos/internal/pkg/kubeconfig"
 	"github.com/talos-systems/talos/internal/pkg/tail"
 	"github.com/talos-systems/talos/pkg/archiver"
@@ -91,6 +95,332 @@ func (s *Server) Reboot(ctx context.Context, in *empty.Empty) (reply *machine.Re
 	return reply, nil
 }
 
+// RunPhase implements the machine.MachineServer interface.
+//
+// This is a debugging aid, and is refused unless debug is enabled in the
+// config.
+func (s *Server) RunPhase(ctx context.Context, in *machine.RunPhaseRequest) (reply *machine.RunPhaseResponse, err error) {
+	seq, err := runtime.ParseSequence(in.Sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("run phase %q of the %s sequence via API received", in.Phase, seq)
+
+	if err = s.Controller.RunPhase(seq, in.Phase, nil); err != nil {
+		return nil, err
+	}
+
+	reply = &machine.RunPhaseResponse{
+		Messages: []*machine.RunPhase{
+			{},
+		},
+	}
+
+	return reply, nil
+}
+
+// runnableSequences are the sequences that RunSequence runs. The install and
+// recover sequences are left out, since they run at startup, before the
+// machine boots, and would wipe or remount the disks of the running system.
+var runnableSequences = []runtime.Sequence{
+	runtime.SequenceShutdown,
+	runtime.SequenceReboot,
+	runtime.SequenceReset,
+	runtime.SequenceUpgrade,
+	runtime.SequenceRollback,
+	runtime.SequenceMaintenance,
+	runtime.SequenceResume,
+}
+
+// RunSequence implements the machine.MachineServer interface.
+//
+// The shutdown, reboot, reset, and upgrade sequences are handled as by their
+// dedicated RPCs, the others run in the background.
+func (s *Server) RunSequence(ctx context.Context, in *machine.RunSequenceRequest) (reply *machine.RunSequenceResponse, err error) {
+	seq, data, err := sequenceRequest(in)
+	if err != nil {
+		return nil, err
+	}
+
+	switch seq {
+	case runtime.SequenceShutdown:
+		_, err = s.Shutdown(ctx, &empty.Empty{})
+	case runtime.SequenceReboot:
+		_, err = s.Reboot(ctx, &empty.Empty{})
+	case runtime.SequenceReset:
+		_, err = s.Reset(ctx, in.GetReset_())
+	case runtime.SequenceUpgrade:
+		_, err = s.Upgrade(ctx, in.GetUpgrade())
+	default:
+		log.Printf("%s via API received", seq)
+
+		go func() {
+			if err := s.Controller.Run(seq, data); err != nil {
+				log.Printf("%s failed: %v", seq, err)
+			}
+		}()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	reply = &machine.RunSequenceResponse{
+		Messages: []*machine.RunSequence{
+			{
+				Sequence: seq.String(),
+			},
+		},
+	}
+
+	return reply, nil
+}
+
+// sequenceRequest returns the sequence named by the request, and its data.
+// The params of the request must be those of the sequence, if any.
+func sequenceRequest(in *machine.RunSequenceRequest) (seq runtime.Sequence, data interface{}, err error) {
+	runnable := false
+
+	if seq, err = runtime.ParseSequence(in.GetSequence()); err == nil {
+		for _, s := range runnableSequences {
+			runnable = runnable || s == seq
+		}
+	}
+
+	if !runnable {
+		names := make([]string, 0, len(runnableSequences))
+
+		for _, s := range runnableSequences {
+			names = append(names, s.String())
+		}
+
+		return seq, nil, fmt.Errorf("sequence %q can not be run, valid sequences are: %s", in.GetSequence(), strings.Join(names, ", "))
+	}
+
+	params := map[runtime.Sequence]bool{
+		runtime.SequenceReset:   in.GetReset_() != nil,
+		runtime.SequenceUpgrade: in.GetUpgrade() != nil,
+	}
+
+	for s, set := range params {
+		if set && s != seq {
+			return seq, nil, fmt.Errorf("the %s sequence does not take the params of the %s sequence", seq, s)
+		}
+	}
+
+	switch seq {
+	case runtime.SequenceReset:
+		if in.GetReset_() == nil {
+			return seq, nil, fmt.Errorf("the %s sequence requires the reset params", seq)
+		}
+
+		data = in.GetReset_()
+	case runtime.SequenceUpgrade:
+		if in.GetUpgrade() == nil {
+			return seq, nil, fmt.Errorf("the %s sequence requires the upgrade params", seq)
+		}
+
+		data = in.GetUpgrade()
+	}
+
+	return seq, data, nil
+}
+
+// AbortSequence implements the machine.MachineServer interface.
+func (s *Server) AbortSequence(ctx context.Context, in *empty.Empty) (reply *machine.AbortSequenceResponse, err error) {
+	log.Printf("abort sequence via API received")
+
+	seq, err := s.Controller.Abort()
+	if err != nil {
+		return nil, err
+	}
+
+	reply = &machine.AbortSequenceResponse{
+		Messages: []*machine.AbortSequence{
+			{
+				Sequence: seq.String(),
+			},
+		},
+	}
+
+	return reply, nil
+}
+
+// StepSequence implements the machine.MachineServer interface.
+func (s *Server) StepSequence(ctx context.Context, in *machine.StepSequenceRequest) (reply *machine.StepSequenceResponse, err error) {
+	switch in.GetAction() {
+	case machine.StepSequenceAction_STEP_ENABLE:
+		log.Printf("single-step mode via API enabled")
+
+		err = s.Controller.SetSingleStep(true)
+	case machine.StepSequenceAction_STEP_DISABLE:
+		log.Printf("single-step mode via API disabled")
+
+		err = s.Controller.SetSingleStep(false)
+	default:
+		err = s.Controller.Step()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	reply = &machine.StepSequenceResponse{
+		Messages: []*machine.StepSequence{
+			{},
+		},
+	}
+
+	return reply, nil
+}
+
+// SequenceResult implements the machine.MachineServer interface.
+func (s *Server) SequenceResult(ctx context.Context, in *empty.Empty) (reply *machine.SequenceResultResponse, err error) {
+	result, ok := s.Controller.LastResult()
+	if !ok {
+		return nil, errors.New("no sequence has run yet")
+	}
+
+	reply = &machine.SequenceResultResponse{
+		Messages: []*machine.SequenceResult{
+			sequenceResult(result),
+		},
+	}
+
+	return reply, nil
+}
+
+// Preflight implements the machine.MachineServer interface.
+func (s *Server) Preflight(ctx context.Context, in *machine.RunSequenceRequest) (reply *machine.PreflightResponse, err error) {
+	seq, data, err := sequenceRequest(in)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.Controller.Preflight(seq, data)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &machine.Preflight{
+		Sequence: seq.String(),
+		Ready:    runtime.CheckPreflight(seq, results) == nil,
+	}
+
+	for _, result := range results {
+		check := &machine.PreflightCheck{
+			Name:     result.Name,
+			Duration: ptypes.DurationProto(result.Duration),
+		}
+
+		if result.Err != nil {
+			check.Error = result.Err.Error()
+		}
+
+		msg.Checks = append(msg.Checks, check)
+	}
+
+	reply = &machine.PreflightResponse{
+		Messages: []*machine.Preflight{
+			msg,
+		},
+	}
+
+	return reply, nil
+}
+
+// Sequences implements the machine.MachineServer interface.
+func (s *Server) Sequences(ctx context.Context, in *empty.Empty) (reply *machine.SequencesResponse, err error) {
+	plans, err := s.Controller.Describe()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &machine.Sequences{}
+
+	for _, plan := range plans {
+		p := &machine.SequencePlan{
+			Sequence: plan.Sequence,
+		}
+
+		for _, phase := range plan.Phases {
+			ph := &machine.SequencePlanPhase{
+				Number:  int32(phase.Number),
+				Overlap: phase.Overlap,
+			}
+
+			for _, task := range phase.Tasks {
+				ph.Tasks = append(ph.Tasks, &machine.SequencePlanTask{
+					Name:  task.Name,
+					Label: task.Label,
+				})
+			}
+
+			p.Phases = append(p.Phases, ph)
+		}
+
+		msg.Plans = append(msg.Plans, p)
+	}
+
+	reply = &machine.SequencesResponse{
+		Messages: []*machine.Sequences{
+			msg,
+		},
+	}
+
+	return reply, nil
+}
+
+// SequenceHistory implements the machine.MachineServer interface.
+func (s *Server) SequenceHistory(ctx context.Context, in *empty.Empty) (reply *machine.SequenceHistoryResponse, err error) {
+	history := &machine.SequenceHistory{}
+
+	for _, result := range s.Controller.History() {
+		history.Results = append(history.Results, sequenceResult(result))
+	}
+
+	reply = &machine.SequenceHistoryResponse{
+		Messages: []*machine.SequenceHistory{
+			history,
+		},
+	}
+
+	return reply, nil
+}
+
+func sequenceResult(r runtime.SequenceResult) *machine.SequenceResult {
+	result := &machine.SequenceResult{
+		Sequence: r.Sequence.String(),
+		Duration: ptypes.DurationProto(r.Duration),
+		Error:    r.Error,
+	}
+
+	// nolint: errcheck
+	result.Start, _ = ptypes.TimestampProto(r.Start)
+
+	for _, p := range r.Phases {
+		phase := &machine.PhaseResult{
+			Start:    ptypes.DurationProto(p.Start),
+			Duration: ptypes.DurationProto(p.Duration),
+		}
+
+		for _, t := range p.Tasks {
+			phase.Tasks = append(phase.Tasks, &machine.TaskResult{
+				Name:     t.Name,
+				Start:    ptypes.DurationProto(t.Start),
+				Duration: ptypes.DurationProto(t.Duration),
+				Error:    t.Error,
+				Degraded: t.Degraded,
+			})
+		}
+
+		result.Phases = append(result.Phases, phase)
+	}
+
+	return result
+}
+
 // Shutdown implements the machine.MachineServer interface.
 //
 // nolint: dupl
@@ -125,19 +455,85 @@ func (s *Server) Upgrade(ctx context.Context, in *machine.UpgradeRequest) (reply
 
 	log.Printf("validating %q", in.GetImage())
 
-	if err = pullAndValidateInstallerImage(ctx, s.Controller.Runtime().Config().Machine().Registries(), in.GetImage()); err != nil {
+	if len(in.GetSignature()) > 0 && in.GetPublicKey() == "" && len(s.Controller.Runtime().Config().Machine().Install().ImageVerificationKeys()) == 0 {
+		return nil, errors.New("a signature of the upgrade image requires a public key in the request or the config")
+	}
+
+	// The staged upgrade is performed on the next boot, before the node joins
+	// the cluster, so it can neither leave etcd nor drain the node.
+	if in.GetStage() && !in.GetPreserve() {
+		return nil, errors.New("staging an upgrade requires preserving the data")
+	}
+
+	// The reference is checked before the image is pulled, rather than by the
+	// pre-flight checks, which run after.
+	if err = v1alpha1runtime.ValidateInstallerImage(in.GetImage()); err != nil {
 		return nil, err
 	}
 
+	ref := in.GetImage()
+
+	// The image is verified before anything runs from it, so that the help
+	// command of the installer does not run from an image that fails
+	// verification.
+	verify := func(dgst digest.Digest) (err error) {
+		var result *imagesig.Result
+
+		if result, err = v1alpha1runtime.VerifyUpgradeImage(ctx, s.Controller.Runtime(), in, dgst); err != nil {
+			return fmt.Errorf("verification of %q failed: %w", ref, err)
+		}
+
+		if result != nil {
+			log.Printf("%s: %s", ref, result)
+		}
+
+		return nil
+	}
+
 	if err = etcd.ValidateForUpgrade(in.GetPreserve()); err != nil {
 		return nil, err
 	}
 
+	reg := s.Controller.Runtime().Config().Machine().Registries()
+
+	// Staging only writes the upgrade for the next boot, so it runs while the
+	// request waits, and a failure leaves the node running. The image is
+	// pulled on the next boot, so only its digest is resolved to verify it.
+	if in.GetStage() {
+		var dgst digest.Digest
+
+		if dgst, err = resolveInstallerImage(ctx, reg, ref); err != nil {
+			return nil, err
+		}
+
+		if err = verify(dgst); err != nil {
+			return nil, err
+		}
+
+		if err = s.Controller.Run(runtime.SequenceUpgrade, in); err != nil {
+			return nil, fmt.Errorf("failed to stage upgrade: %w", err)
+		}
+
+		return &machine.UpgradeResponse{
+			Messages: []*machine.Upgrade{
+				{
+					Ack: "Upgrade staged for the next reboot",
+				},
+			},
+		}, nil
+	}
+
+	if err = pullAndValidateInstallerImage(ctx, reg, ref, verify); err != nil {
+		return nil, err
+	}
+
 	go func() {
 		if err := s.Controller.Run(runtime.SequenceUpgrade, in); err != nil {
 			log.Println("upgrade failed:", err)
 
-			if err != runtime.ErrLocked {
+			// The pre-flight checks run before the node is changed, so their
+			// failures leave the node running.
+			if err != runtime.ErrLocked && !errors.Is(err, runtime.ErrPreflight) {
 				// NB: Stopping the gRPC server will trigger machined's reboot mechanism.
 				s.server.GracefulStop()
 			}
@@ -161,11 +557,15 @@ func (s *Server) Upgrade(ctx context.Context, in *machine.UpgradeRequest) (reply
 func (s *Server) Reset(ctx context.Context, in *machine.ResetRequest) (reply *machine.ResetResponse, err error) {
 	log.Printf("reset request received")
 
+	if _, err = runtime.PartitionsToWipe(in.GetPreserve()); err != nil {
+		return nil, err
+	}
+
 	go func() {
 		if err := s.Controller.Run(runtime.SequenceReset, in); err != nil {
 			log.Println("reset failed:", err)
 
-			if err != runtime.ErrLocked {
+			if err != runtime.ErrLocked && !errors.Is(err, runtime.ErrPreflight) {
 				// NB: Stopping the gRPC server will trigger machined's reboot mechanism.
 				s.server.GracefulStop()
 			}
@@ -538,6 +938,52 @@ func (s *Server) Logs(req *machine.LogsRequest, l machine.MachineService_LogsSer
 	return nil
 }
 
+// EventsStream implements the machine.MachineServer interface.
+func (s *Server) EventsStream(req *machine.EventsRequest, l machine.MachineService_EventsStreamServer) error {
+	events, cancel := s.Controller.Events().Subscribe()
+	defer cancel()
+
+	for {
+		select {
+		case <-l.Context().Done():
+			return nil
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			if err := l.Send(sequenceEvent(e)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func sequenceEvent(e runtime.Event) *machine.SequenceEvent {
+	event := &machine.SequenceEvent{
+		Type:       machine.SequenceEventType(e.Type),
+		Sequence:   e.Sequence.String(),
+		Phase:      int32(e.Phase),
+		PhaseTotal: int32(e.PhaseTotal),
+		Task:       int32(e.Task),
+		TaskTotal:  int32(e.TaskTotal),
+		TaskName:   e.TaskName,
+		Elapsed:    ptypes.DurationProto(e.Elapsed),
+		Error:      e.Error,
+		Message:    e.Message,
+	}
+
+	if e.Type == runtime.EventTaskProgress {
+		event.Progress = &machine.TaskProgress{
+			Current: e.Progress.Current,
+			Total:   e.Progress.Total,
+			Unit:    e.Progress.Unit,
+		}
+	}
+
+	return event
+}
+
 func k8slogs(ctx context.Context, req *machine.LogsRequest) (chunker.Chunker, io.Closer, error) {
 	inspector, err := getContainerInspector(ctx, req.Namespace, req.Driver)
 	if err != nil {
@@ -613,7 +1059,42 @@ func (s *Server) Read(in *machine.ReadRequest, srv machine.MachineService_ReadSe
 	}
 }
 
-func pullAndValidateInstallerImage(ctx context.Context, reg runtime.Registries, ref string) error {
+// pullProgressInterval is the minimum interval between the logged progress of
+// the pull of an installer image.
+const pullProgressInterval = 5 * time.Second
+
+// logPullProgress returns the receiver of the progress of the pull of the
+// image, which logs it at most once per interval.
+func logPullProgress(ref string) runtime.ProgressFunc {
+	var last time.Time
+
+	return func(p runtime.Progress) {
+		if time.Since(last) < pullProgressInterval {
+			return
+		}
+
+		last = time.Now()
+
+		if percent := p.Percent(); percent >= 0 {
+			log.Printf("pulling %q: %.0f%% of %d %s", ref, percent, p.Total, p.Unit)
+		} else {
+			log.Printf("pulling %q: %d %s", ref, p.Current, p.Unit)
+		}
+	}
+}
+
+// resolveInstallerImage resolves the manifest digest of the image without
+// pulling it.
+func resolveInstallerImage(ctx context.Context, reg runtime.Registries, ref string) (digest.Digest, error) {
+	_, desc, err := image.NewResolver(reg).Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve image %q: %w", ref, err)
+	}
+
+	return desc.Digest, nil
+}
+
+func pullAndValidateInstallerImage(ctx context.Context, reg runtime.Registries, ref string, verify func(digest.Digest) error) error {
 	// Pull down specified installer image early so we can bail if it doesn't exist in the upstream registry
 	containerdctx := namespaces.WithNamespace(ctx, constants.SystemContainerdNamespace)
 
@@ -622,11 +1103,19 @@ func pullAndValidateInstallerImage(ctx context.Context, reg runtime.Registries,
 		return err
 	}
 
+	// The sequence has not started yet, so the progress of the pull is logged
+	// rather than published as the events of a task.
+	containerdctx = runtime.WithProgress(containerdctx, logPullProgress(ref))
+
 	img, err := image.Pull(containerdctx, reg, client, ref)
 	if err != nil {
 		return err
 	}
 
+	if err = verify(img.Target().Digest); err != nil {
+		return err
+	}
+
 	// Launch the container with a known help command for a simple check to make sure the image is valid
 	args := []string{
 		"/bin/installer",
@@ -6,12 +6,14 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -92,7 +94,7 @@ func handle(err error) {
 		time.Sleep(1 * time.Second)
 	}
 
-	v1alpha1runtime.SyncNonVolatileStorageBuffers()
+	v1alpha1runtime.SyncNonVolatileStorageBuffers(constants.DefaultShutdownSyncTimeout)
 
 	if unix.Reboot(unix.LINUX_REBOOT_CMD_RESTART) == nil {
 		// Wait forever.
@@ -100,6 +102,37 @@ func handle(err error) {
 	}
 }
 
+func startServices(c *v1alpha1runtime.Controller) {
+	// Start event listeners.
+	go func() {
+		if e := c.ListenForEvents(); e != nil {
+			log.Printf("WARNING: event listener stopped, signals and ACPI events will be ignored: %+v", e)
+		}
+	}()
+
+	// Start the API server.
+	go func() {
+		server := &v1alpha1server.Server{
+			Controller: c,
+		}
+
+		e := factory.ListenAndServe(server, factory.Network("unix"), factory.SocketPath(constants.MachineSocketPath))
+
+		handle(e)
+	}()
+
+	// Start the metrics server. It is not authenticated, so it only listens on
+	// the loopback interface.
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", c.Metrics())
+
+		if e := http.ListenAndServe(fmt.Sprintf("127.0.0.1:%d", constants.MachinedMetricsPort), mux); e != nil {
+			log.Printf("WARNING: metrics will not be served: %v", e)
+		}
+	}()
+}
+
 // nolint: gocyclo
 func main() {
 	// Setup panic handler.
@@ -119,43 +152,89 @@ func main() {
 		handle(errors.New("error setting PATH"))
 	}
 
+	// Determine the sequences to run at startup.
+	plan := runtime.DefaultStartupPlan
+
+	if p := procfs.ProcCmdline().Get(constants.KernelParamStartup).First(); p != nil {
+		var err error
+
+		if plan, err = runtime.ParseStartupPlan(*p); err != nil {
+			handle(fmt.Errorf("invalid %s kernel parameter: %w", constants.KernelParamStartup, err))
+		}
+	}
+
 	// Initialize the controller without a config.
 	c, err := v1alpha1runtime.NewController(nil)
 	if err != nil {
 		handle(err)
 	}
 
-	// Initialize the machine.
-	if err = c.Run(runtime.SequenceInitialize, nil); err != nil {
-		handle(err)
+	if p := procfs.ProcCmdline().Get(constants.KernelParamMaxParallelTasks).First(); p != nil {
+		var n int
+
+		if n, err = strconv.Atoi(*p); err != nil || n < 0 {
+			handle(fmt.Errorf("invalid %s kernel parameter: %q", constants.KernelParamMaxParallelTasks, *p))
+		}
+
+		c.SetMaxParallelTasks(n)
 	}
 
-	// Start event listeners.
-	go func() {
-		if e := c.ListenForEvents(); e != nil {
-			log.Printf("WARNING: signals and ACPI events will be ignored: %+v", e)
+	if p := procfs.ProcCmdline().Get(constants.KernelParamSequenceHistory).First(); p != nil {
+		var n int
+
+		if n, err = strconv.Atoi(*p); err != nil || n < 1 {
+			handle(fmt.Errorf("invalid %s kernel parameter: %q", constants.KernelParamSequenceHistory, *p))
 		}
-	}()
 
-	// Start the API server.
-	go func() {
-		server := &v1alpha1server.Server{
-			Controller: c,
+		c.SetHistorySize(n)
+	}
+
+	if p := procfs.ProcCmdline().Get(constants.KernelParamSIGTERMGracePeriod).First(); p != nil {
+		var d time.Duration
+
+		if d, err = time.ParseDuration(*p); err != nil || d < 0 {
+			handle(fmt.Errorf("invalid %s kernel parameter: %q", constants.KernelParamSIGTERMGracePeriod, *p))
 		}
 
-		e := factory.ListenAndServe(server, factory.Network("unix"), factory.SocketPath(constants.MachineSocketPath))
+		c.SetSIGTERMGracePeriod(d)
+	}
 
-		handle(e)
-	}()
+	if p := procfs.ProcCmdline().Get(constants.KernelParamPreflight).First(); p != nil {
+		var enabled bool
 
-	// Perform an installation if required.
-	if err = c.Run(runtime.SequenceInstall, nil); err != nil {
-		handle(err)
+		if enabled, err = strconv.ParseBool(*p); err != nil {
+			handle(fmt.Errorf("invalid %s kernel parameter: %q", constants.KernelParamPreflight, *p))
+		}
+
+		c.SetPreflight(enabled)
 	}
 
-	// Boot the machine.
-	if err = c.Run(runtime.SequenceBoot, nil); err != nil {
-		handle(err)
+	for _, seq := range plan {
+		// The config is only left to the recover sequence if the plan runs it.
+		var data interface{}
+		if seq == runtime.SequenceInitialize {
+			data = plan
+		}
+
+		if err = c.Run(seq, data); err != nil {
+			if errors.Is(err, runtime.ErrMaintenance) {
+				log.Printf("entering maintenance mode: %v", err)
+
+				break
+			}
+
+			handle(err)
+		}
+
+		// The extensions of the config, the event listeners and API server
+		// depend on the machine being initialized.
+		if seq == runtime.SequenceInitialize {
+			if err = c.RegisterConfigExtensions(); err != nil {
+				handle(err)
+			}
+
+			startServices(c)
+		}
 	}
 
 	// Wait forever.
@@ -0,0 +1,90 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// CleanupFunc brings the machine back to a consistent state after a task was
+// interrupted, e.g. by remounting a partially unmounted filesystem read-only.
+// It is passed a context of its own, since the context of the sequence is
+// canceled by then.
+type CleanupFunc func(ctx context.Context) error
+
+// Cleanups are the cleanups registered by the tasks of a sequence, which the
+// controller runs if the sequence fails or is aborted. It is safe for
+// concurrent use, by the tasks of a phase.
+type Cleanups struct {
+	mu    sync.Mutex
+	names []string
+	funcs map[string]CleanupFunc
+}
+
+// Register adds a cleanup. A cleanup of the same name as a registered cleanup
+// is ignored, so that the tasks sharing a cleanup can all register it.
+func (c *Cleanups) Register(name string, f CleanupFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.funcs[name]; ok {
+		return
+	}
+
+	if c.funcs == nil {
+		c.funcs = map[string]CleanupFunc{}
+	}
+
+	c.names = append(c.names, name)
+	c.funcs[name] = f
+}
+
+// Run runs the cleanups in the reverse order of their registration, and
+// clears them. A failing cleanup does not stop the others, and its error is
+// returned along with theirs.
+func (c *Cleanups) Run(ctx context.Context, report func(name string, err error)) error {
+	c.mu.Lock()
+	names, funcs := c.names, c.funcs
+	c.names, c.funcs = nil, nil
+	c.mu.Unlock()
+
+	var result *multierror.Error
+
+	for i := len(names) - 1; i >= 0; i-- {
+		err := funcs[names[i]](ctx)
+		if err != nil {
+			err = fmt.Errorf("cleanup %q: %w", names[i], err)
+
+			result = multierror.Append(result, err)
+		}
+
+		if report != nil {
+			report(names[i], err)
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+type cleanupsKey struct{}
+
+// WithCleanups returns a context that carries the cleanups registered with
+// RegisterCleanup. The controller sets it for the sequences it runs.
+func WithCleanups(ctx context.Context, c *Cleanups) context.Context {
+	return context.WithValue(ctx, cleanupsKey{}, c)
+}
+
+// RegisterCleanup registers a cleanup that runs if the running sequence fails
+// or is aborted, even if the task registering it completed. It does nothing
+// if the context does not carry cleanups, e.g. outside of a sequence.
+func RegisterCleanup(ctx context.Context, name string, f CleanupFunc) {
+	if c, ok := ctx.Value(cleanupsKey{}).(*Cleanups); ok && c != nil {
+		c.Register(name, f)
+	}
+}
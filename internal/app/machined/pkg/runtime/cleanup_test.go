@@ -0,0 +1,61 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCleanups(t *testing.T) {
+	// Without cleanups, the registration is dropped.
+	RegisterCleanup(context.Background(), "dropped", func(context.Context) error {
+		t.Error("cleanup registered outside of a sequence ran")
+
+		return nil
+	})
+
+	var ran []string
+
+	cleanup := func(name string, err error) CleanupFunc {
+		return func(context.Context) error {
+			ran = append(ran, name)
+
+			return err
+		}
+	}
+
+	c := &Cleanups{}
+	ctx := WithCleanups(context.Background(), c)
+
+	RegisterCleanup(ctx, "sync", cleanup("sync", nil))
+	RegisterCleanup(ctx, "remount", cleanup("remount", errors.New("device busy")))
+	RegisterCleanup(ctx, "sync", cleanup("sync again", nil))
+
+	var reported []string
+
+	err := c.Run(context.Background(), func(name string, err error) {
+		reported = append(reported, name)
+	})
+	if err == nil || !strings.Contains(err.Error(), "device busy") {
+		t.Errorf("Run() = %v, want the error of the remount", err)
+	}
+
+	if want := []string{"remount", "sync"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("Run() ran %v, want %v", ran, want)
+	}
+
+	if !reflect.DeepEqual(reported, ran) {
+		t.Errorf("Run() reported %v, want %v", reported, ran)
+	}
+
+	// The cleanups run once.
+	if err = c.Run(context.Background(), nil); err != nil || len(ran) != 2 {
+		t.Errorf("Run() again = %v, ran %v, want nothing", err, ran)
+	}
+}
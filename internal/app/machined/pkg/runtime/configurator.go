@@ -15,6 +15,7 @@ import (
 
 	"github.com/opencontainers/runtime-spec/specs-go"
 
+	"github.com/talos-systems/talos/api/machine"
 	"github.com/talos-systems/talos/pkg/blockdevice/probe"
 	"github.com/talos-systems/talos/pkg/client/config"
 	"github.com/talos-systems/talos/pkg/crypto/x509"
@@ -51,6 +52,11 @@ type MachineState interface {
 	Disk() *probe.ProbedBlockDevice
 	Close() error
 	Installed() bool
+	ExistingInstallation(string) ([]string, error)
+	StagedUpgrade() *machine.UpgradeRequest
+	SetStagedUpgrade(*machine.UpgradeRequest)
+	Maintenance() bool
+	SetMaintenance(bool)
 }
 
 // MachineType represents a machine type.
@@ -104,6 +110,17 @@ type MachineConfig interface {
 	Kubelet() Kubelet
 	Sysctls() map[string]string
 	Registries() Registries
+	WaitFor() WaitFor
+	HealthCheck() HealthCheck
+	Sequences() Sequences
+	TaskTimeouts() map[string]time.Duration
+	Shutdown() Shutdown
+	Outcomes() Outcomes
+	ACPI() ACPI
+	Watchdog() Watchdog
+	Maintenance() Maintenance
+	Extensions() []Extension
+	TaskLogs() TaskLogs
 }
 
 // Env represents a set of environment variables.
@@ -209,8 +226,25 @@ type Install interface {
 	Zero() bool
 	Force() bool
 	WithBootloader() bool
+	Existing() InstallExistingAction
+	WaitForUSB() bool
+	USBDelay() time.Duration
+	ImageVerificationKeys() []string
 }
 
+// InstallExistingAction represents the action taken when the install disk
+// already contains an installation. A forced install always reinstalls.
+type InstallExistingAction string
+
+const (
+	// InstallExistingActionAbort fails the install sequence.
+	InstallExistingActionAbort InstallExistingAction = "abort"
+	// InstallExistingActionSkip leaves the existing installation in place.
+	InstallExistingActionSkip InstallExistingAction = "skip"
+	// InstallExistingActionForce reinstalls over the existing installation.
+	InstallExistingActionForce InstallExistingAction = "force"
+)
+
 // Disk represents the options available for partitioning, formatting, and
 // mounting extra disks.
 type Disk struct {
@@ -224,10 +258,186 @@ type Partition struct {
 	MountPoint string `yaml:"mountpoint,omitempty"`
 }
 
+// WaitFor defines the requirements for a config that pertains to the endpoints
+// that must be reachable before services are started.
+type WaitFor interface {
+	Endpoints() []string
+	Timeout() time.Duration
+	OnTimeout() WaitForAction
+}
+
+// HealthCheck defines the requirements for a config that pertains to the
+// services that must be healthy for the boot sequence to complete.
+type HealthCheck interface {
+	// Skip returns true if the boot sequence completes without waiting for
+	// the services.
+	Skip() bool
+	// Services returns the IDs of the services to wait for, or none for the
+	// default services of the machine type.
+	Services() []string
+	// Timeout returns the time the services are given to become healthy,
+	// after which the boot sequence completes degraded.
+	Timeout() time.Duration
+}
+
+// WaitForAction represents the action taken when the endpoints are not
+// reachable in time.
+type WaitForAction string
+
+const (
+	// WaitForActionFail fails the boot sequence.
+	WaitForActionFail WaitForAction = "fail"
+	// WaitForActionMaintenance halts the boot sequence, leaving the machine API
+	// running so that the machine can be inspected.
+	WaitForActionMaintenance WaitForAction = "maintenance"
+)
+
+// Sequences defines the requirements for a config that pertains to the
+// execution of sequences.
+type Sequences interface {
+	// MaxParallelTasks returns the maximum number of tasks of a phase that are
+	// run concurrently in the specified sequence. Zero means no limit.
+	MaxParallelTasks(Sequence) int
+	// Timeout returns the maximum total duration of the specified sequence,
+	// after which it is aborted. Zero means no limit.
+	Timeout(Sequence) time.Duration
+}
+
+// Shutdown defines the requirements for a config that pertains to the time
+// allowed for each stage of a shutdown or reboot.
+type Shutdown interface {
+	// GracePeriod returns the time services are given to stop.
+	GracePeriod() time.Duration
+	// SkipDrain returns true if the node is not cordoned and drained before a
+	// shutdown or reboot.
+	SkipDrain() bool
+	// DrainTimeout returns the time allowed to cordon and drain the node.
+	DrainTimeout() time.Duration
+	// SyncTimeout returns the time allowed to flush filesystem buffers.
+	SyncTimeout() time.Duration
+	// UnmountTimeout returns the time allowed for each unmount task.
+	UnmountTimeout() time.Duration
+	// Kexec returns true if the machine reboots with kexec rather than
+	// through the firmware.
+	Kexec() bool
+}
+
+// Outcomes defines the requirements for a config that pertains to the
+// reporting of sequence outcomes.
+type Outcomes interface {
+	// Webhook returns the URL that outcomes are posted to. An empty string
+	// disables reporting.
+	Webhook() string
+}
+
+// ACPI defines the requirements for a config that pertains to the handling of
+// ACPI events.
+type ACPI interface {
+	// Actions returns the actions taken in response to ACPI events, keyed by
+	// event name (e.g. `button/lid`). They override the default actions.
+	Actions() map[string]ACPIAction
+}
+
+// Watchdog defines the requirements for a config that pertains to the
+// hardware watchdog, which is pet while a sequence runs.
+type Watchdog interface {
+	// Enabled reports whether the watchdog is pet during sequences.
+	Enabled() bool
+	// Device returns the path of the watchdog device.
+	Device() string
+	// Timeout returns the time without a keepalive after which the watchdog
+	// resets the machine.
+	Timeout() time.Duration
+}
+
+// Extension defines the requirements for a config that pertains to an
+// extension, a command run at an extension point of the sequences.
+type Extension interface {
+	// Name returns the name the extension is reported with.
+	Name() string
+	// Point returns the extension point the command runs at (e.g.
+	// `before-services`).
+	Point() string
+	// Command returns the path of the command, followed by its arguments.
+	Command() []string
+	// Env returns the environment variables of the command, which does not
+	// inherit the ones of machined.
+	Env() Env
+	// Timeout returns the time after which the command is killed, zero
+	// meaning no limit.
+	Timeout() time.Duration
+}
+
+// Maintenance defines the requirements for a config that pertains to the
+// maintenance sequence, which stops the workloads while the machine API keeps
+// running, and the resume sequence, which starts them again.
+type Maintenance interface {
+	// StopServices returns the IDs of the services that are stopped, in
+	// order. The pods are removed once the kubelet is stopped.
+	StopServices() []string
+	// SkipDrain returns true if the node is not drained before the services
+	// are stopped, nor uncordoned once they are started again.
+	SkipDrain() bool
+}
+
+// TaskLogs defines the requirements for a config that pertains to the logs of
+// the tasks.
+type TaskLogs interface {
+	// RateLimit returns the maximum number of lines per second that each
+	// task writes to the kernel log. Zero means no limit.
+	RateLimit() int
+	// Stderr returns true if the logs of the tasks are written to stderr too.
+	Stderr() bool
+}
+
+// maintenancePreservedServices are the services that keep running in
+// maintenance, since the machine is managed through them.
+var maintenancePreservedServices = map[string]bool{
+	"apid":       true,
+	"containerd": true,
+	"networkd":   true,
+	"osd":        true,
+	"routerd":    true,
+}
+
+// MaintenancePreserved reports whether the service keeps running in
+// maintenance, whatever the config.
+func MaintenancePreserved(id string) bool {
+	return maintenancePreservedServices[id]
+}
+
+// ACPIAction represents the action taken in response to an ACPI event.
+type ACPIAction string
+
+const (
+	// ACPIActionIgnore logs the event and otherwise ignores it.
+	ACPIActionIgnore ACPIAction = "ignore"
+	// ACPIActionShutdown shuts the machine down.
+	ACPIActionShutdown ACPIAction = "shutdown"
+	// ACPIActionReboot reboots the machine.
+	ACPIActionReboot ACPIAction = "reboot"
+	// ACPIActionMaintenance stops the workloads, e.g. in response to a
+	// thermal event.
+	ACPIActionMaintenance ACPIAction = "maintenance"
+	// ACPIActionResume starts the workloads again.
+	ACPIActionResume ACPIAction = "resume"
+)
+
 // Time defines the requirements for a config that pertains to time related
 // options.
 type Time interface {
 	Servers() []string
+	Tolerance() time.Duration
+	MinPoll() time.Duration
+	MaxPoll() time.Duration
+	DriftFile() string
+	DriftWarningThreshold() float64
+	PHC() string
+	SanityCheckURL() string
+	SanityCheckThreshold() time.Duration
+	SanityCheckRequired() bool
+	WaitForSync() bool
+	WaitForSyncTimeout() time.Duration
 }
 
 // Kubelet defines the requirements for a config that pertains to kubelet
@@ -0,0 +1,66 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+// ConflictAction is how a sequence requested while another sequence runs is
+// handled.
+type ConflictAction int
+
+const (
+	// ConflictReject fails the requested sequence with `ErrLocked`.
+	ConflictReject ConflictAction = iota
+	// ConflictQueue runs the requested sequence once the running sequence
+	// completes.
+	ConflictQueue
+	// ConflictPreempt cancels the running sequence, and runs the requested
+	// sequence. A sequence past its point of no return is not canceled, so
+	// the requested sequence is queued behind it instead.
+	ConflictPreempt
+)
+
+// String returns the string representation of a `ConflictAction`.
+func (a ConflictAction) String() string {
+	return [...]string{"reject", "queue", "preempt"}[a]
+}
+
+// preemptedByShutdown are the sequences that a shutdown or reboot preempts:
+// all of them, but another shutdown or reboot, which is already taking the
+// machine down.
+var preemptedByShutdown = map[Sequence]ConflictAction{
+	SequenceBoot:        ConflictPreempt,
+	SequenceInitialize:  ConflictPreempt,
+	SequenceInstall:     ConflictPreempt,
+	SequenceUpgrade:     ConflictPreempt,
+	SequenceReset:       ConflictPreempt,
+	SequenceNoop:        ConflictPreempt,
+	SequenceReload:      ConflictPreempt,
+	SequenceRollback:    ConflictPreempt,
+	SequenceRecover:     ConflictPreempt,
+	SequenceMaintenance: ConflictPreempt,
+	SequenceResume:      ConflictPreempt,
+}
+
+// conflictPolicy maps a requested sequence to the actions taken while the
+// other sequences run. The sequences missing from the policy are rejected.
+var conflictPolicy = map[Sequence]map[Sequence]ConflictAction{
+	// The startup sequences run one after the other, so a sequence of the
+	// startup plan requested early waits for its turn.
+	SequenceBoot: {
+		SequenceInitialize: ConflictQueue,
+		SequenceInstall:    ConflictQueue,
+	},
+	SequenceInstall: {
+		SequenceInitialize: ConflictQueue,
+		SequenceBoot:       ConflictQueue,
+	},
+	SequenceShutdown: preemptedByShutdown,
+	SequenceReboot:   preemptedByShutdown,
+}
+
+// Conflict returns the action taken when the requested sequence is run while
+// the running sequence runs.
+func Conflict(requested, running Sequence) ConflictAction {
+	return conflictPolicy[requested][running]
+}
@@ -0,0 +1,68 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// nolint: scopelint
+package runtime
+
+import "testing"
+
+func TestConflict(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested Sequence
+		running   Sequence
+		want      ConflictAction
+	}{
+		{
+			name:      "install during boot",
+			requested: SequenceInstall,
+			running:   SequenceBoot,
+			want:      ConflictQueue,
+		},
+		{
+			name:      "boot during initialize",
+			requested: SequenceBoot,
+			running:   SequenceInitialize,
+			want:      ConflictQueue,
+		},
+		{
+			name:      "shutdown during boot",
+			requested: SequenceShutdown,
+			running:   SequenceBoot,
+			want:      ConflictPreempt,
+		},
+		{
+			name:      "reboot during upgrade",
+			requested: SequenceReboot,
+			running:   SequenceUpgrade,
+			want:      ConflictPreempt,
+		},
+		{
+			name:      "shutdown during reboot",
+			requested: SequenceShutdown,
+			running:   SequenceReboot,
+			want:      ConflictReject,
+		},
+		{
+			name:      "upgrade during boot",
+			requested: SequenceUpgrade,
+			running:   SequenceBoot,
+			want:      ConflictReject,
+		},
+		{
+			name:      "install during install",
+			requested: SequenceInstall,
+			running:   SequenceInstall,
+			want:      ConflictReject,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Conflict(tt.requested, tt.running); got != tt.want {
+				t.Errorf("Conflict() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
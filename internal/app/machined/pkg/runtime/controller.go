@@ -6,7 +6,13 @@ package runtime
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"reflect"
+	"time"
+
+	"github.com/talos-systems/talos/pkg/retry"
 )
 
 // TaskSetupFunc defines the function that a task will execute for a specific runtime
@@ -17,13 +23,242 @@ type TaskSetupFunc func(seq Sequence, data interface{}) TaskExecutionFunc
 // mode.
 type TaskExecutionFunc func(context.Context, *log.Logger, Runtime) error
 
+// WithTimeout wraps the task so that it is canceled once the timeout elapses.
+// The task is expected to observe the cancellation of its context, but the
+// wrapper returns on the deadline even if it does not, so that a hung task
+// can not block the sequence forever. The timeout replaces the built-in
+// timeouts of the task, see TaskTimeout.
+func WithTimeout(f TaskSetupFunc, timeout time.Duration) TaskSetupFunc {
+	return func(seq Sequence, data interface{}) TaskExecutionFunc {
+		task := f(seq, data)
+		if task == nil {
+			return nil
+		}
+
+		return func(ctx context.Context, logger *log.Logger, r Runtime) error {
+			ctx, cancel := context.WithTimeout(context.WithValue(ctx, taskTimeoutKey{}, timeout), timeout)
+			defer cancel()
+
+			errCh := make(chan error, 1)
+
+			go func() {
+				errCh <- task(ctx, logger, r)
+			}()
+
+			select {
+			case err := <-errCh:
+				return err
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded {
+					return fmt.Errorf("timed out after %s: %w", timeout, ctx.Err())
+				}
+
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+type taskTimeoutKey struct{}
+
+// TaskTimeout returns the timeout the task is wrapped with by WithTimeout, or
+// the built-in timeout d of the task if it is not wrapped. Tasks use it for
+// the built-in timeouts that bound the whole task, so that a longer timeout
+// set by the config is not cut short by them.
+func TaskTimeout(ctx context.Context, d time.Duration) time.Duration {
+	if timeout, ok := ctx.Value(taskTimeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+
+	return d
+}
+
+// WithRetry wraps the task so that it is attempted up to the specified number
+// of times, waiting between attempts for the exponential backoff of
+// `retry.Backoff` in units of baseDelay, starting at baseDelay. Tasks
+// requesting a reboot or maintenance mode are not retried, and retrying stops
+// once the context of the task is canceled.
+func WithRetry(f TaskSetupFunc, attempts int, baseDelay time.Duration) TaskSetupFunc {
+	return func(seq Sequence, data interface{}) TaskExecutionFunc {
+		task := f(seq, data)
+		if task == nil {
+			return nil
+		}
+
+		return func(ctx context.Context, logger *log.Logger, r Runtime) error {
+			backoff := retry.NewBackoff(retry.WithUnits(baseDelay))
+
+			// The first interval of the backoff is zero.
+			backoff.Next()
+
+			for attempt := 1; ; attempt++ {
+				err := task(ctx, logger, r)
+
+				switch {
+				case err == nil:
+					return nil
+				case errors.Is(err, ErrReboot), errors.Is(err, ErrMaintenance), errors.Is(err, ErrSkipped):
+					return err
+				case attempt >= attempts:
+					return fmt.Errorf("failed after %d attempt(s): %w", attempt, err)
+				}
+
+				delay := backoff.Next()
+
+				logger.Printf("attempt %d/%d failed, retrying in %s: %v", attempt, attempts, delay, err)
+
+				select {
+				case <-ctx.Done():
+					return fmt.Errorf("canceled after %d attempt(s): %w", attempt, err)
+				case <-time.After(delay):
+				}
+			}
+		}
+	}
+}
+
 // Phase represents a collection of tasks to be performed concurrently.
+//
+// Besides tasks, a phase can include directives, which set the options of the
+// phase (e.g. `OverlapWithNext` or `PhaseTimeout`) and are never run as tasks.
 type Phase []TaskSetupFunc
 
+// PhaseTimeoutAction represents what happens when a phase times out.
+type PhaseTimeoutAction int
+
+const (
+	// PhaseTimeoutAbort fails the phase, which aborts the sequence according
+	// to its error policy.
+	PhaseTimeoutAbort PhaseTimeoutAction = iota
+	// PhaseTimeoutContinue logs the timeout, and continues with the next
+	// phase.
+	PhaseTimeoutContinue
+)
+
+// String returns the string representation of a `PhaseTimeoutAction`.
+func (a PhaseTimeoutAction) String() string {
+	return [...]string{"abort", "continue"}[a]
+}
+
+// PhaseOptions represents the options of a phase.
+type PhaseOptions struct {
+	// Overlap indicates that the phase runs concurrently with the next phase.
+	Overlap bool
+	// Timeout is the time the tasks of the phase are given to complete, and
+	// is zero for no timeout.
+	Timeout time.Duration
+	// TimeoutAction is what happens when the phase times out.
+	TimeoutAction PhaseTimeoutAction
+	// NoReturn indicates that the sequence can not be aborted once the phase
+	// starts.
+	NoReturn bool
+}
+
+// directive returns a directive that applies f to the options of the phase.
+// All directives are closures of the same function, which is how they are
+// told apart from tasks.
+func directive(f func(*PhaseOptions)) TaskSetupFunc {
+	return func(seq Sequence, data interface{}) TaskExecutionFunc {
+		if opts, ok := data.(*PhaseOptions); ok {
+			f(opts)
+		}
+
+		return nil
+	}
+}
+
+var directivePointer = reflect.ValueOf(directive(nil)).Pointer()
+
+func isDirective(f TaskSetupFunc) bool {
+	return reflect.ValueOf(f).Pointer() == directivePointer
+}
+
+// OverlapWithNext marks the phase it is part of as independent of the next
+// phase, so that both run concurrently.
+var OverlapWithNext = directive(func(opts *PhaseOptions) {
+	opts.Overlap = true
+})
+
+// PointOfNoReturn marks the phase it is part of as the point of no return of
+// the sequence: it is the first phase that changes the machine in a way that
+// aborting the sequence would not undo (e.g. leaving etcd, or writing to the
+// disk), so that once it starts, the sequence can no longer be aborted.
+var PointOfNoReturn = directive(func(opts *PhaseOptions) {
+	opts.NoReturn = true
+})
+
+// PhaseTimeout limits the time the tasks of the phase it is part of are given
+// to complete. Once the timeout expires, the context of the tasks is
+// canceled, and the phase completes without waiting for them: the tasks that
+// ignore the context keep running in the background until they return.
+func PhaseTimeout(timeout time.Duration, action PhaseTimeoutAction) TaskSetupFunc {
+	return directive(func(opts *PhaseOptions) {
+		opts.Timeout = timeout
+		opts.TimeoutAction = action
+	})
+}
+
+// Tasks returns the tasks of the phase, without its directives.
+func (p Phase) Tasks() []TaskSetupFunc {
+	tasks := make([]TaskSetupFunc, 0, len(p))
+
+	for _, f := range p {
+		if !isDirective(f) {
+			tasks = append(tasks, f)
+		}
+	}
+
+	return tasks
+}
+
+// Options returns the options set by the directives of the phase.
+func (p Phase) Options() PhaseOptions {
+	var opts PhaseOptions
+
+	for _, f := range p {
+		if isDirective(f) {
+			f(SequenceNoop, &opts)
+		}
+	}
+
+	return opts
+}
+
+// OverlapsWithNext reports whether the phase can run concurrently with the
+// next phase.
+func (p Phase) OverlapsWithNext() bool {
+	return p.Options().Overlap
+}
+
 // Controller represents the controller responsible for managing the execution
 // of sequences.
 type Controller interface {
 	Runtime() Runtime
 	Sequencer() Sequencer
 	Run(Sequence, interface{}) error
+	RunWait(Sequence, interface{}, time.Duration) error
+	RunPhase(Sequence, string, interface{}) error
+	Events() Events
+	CurrentSequence() (SequenceStatus, bool)
+	LastResult() (SequenceResult, bool)
+	History() []SequenceResult
+	Inhibit(name string) (release func())
+	Abort() (Sequence, error)
+	SetSingleStep(enabled bool) error
+	Step() error
+	Describe() ([]*Plan, error)
+	Preflight(Sequence, interface{}) ([]PreflightResult, error)
+}
+
+// SequenceStatus describes the progress of a running sequence.
+type SequenceStatus struct {
+	Sequence Sequence
+	// Phase is the human friendly number of the latest phase to start, and is
+	// zero until the first phase starts.
+	Phase      int
+	PhaseTotal int
+	Start      time.Time
+	// NoReturn indicates that the sequence is past its point of no return,
+	// and can no longer be aborted.
+	NoReturn bool
 }
@@ -4,7 +4,10 @@
 
 package runtime
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrLocked indicates that the sequencer is currently locked, and processing
@@ -14,10 +17,150 @@ var (
 	// ErrReboot indicates that a task is requesting a reboot.
 	ErrReboot = errors.New("reboot")
 
+	// ErrMaintenance indicates that a task is requesting that the sequence be
+	// halted, leaving the machine running in maintenance mode.
+	ErrMaintenance = errors.New("maintenance")
+
 	// ErrInvalidSequenceData indicates that the sequencer got data the wrong
 	// data type for a sequence.
 	ErrInvalidSequenceData = errors.New("invalid sequence data")
 
+	// ErrPhaseTimeout indicates that the tasks of a phase did not complete
+	// within the timeout of the phase.
+	ErrPhaseTimeout = errors.New("phase timed out")
+
+	// ErrSequenceTimeout indicates that a sequence did not complete within its
+	// maximum total duration, and was aborted.
+	ErrSequenceTimeout = errors.New("sequence timed out")
+
+	// ErrUnknownSequence indicates that the sequencer does not implement a
+	// sequence.
+	ErrUnknownSequence = errors.New("unknown sequence")
+
 	// ErrUndefinedRuntime indicates that the sequencer's runtime is not defined.
 	ErrUndefinedRuntime = errors.New("undefined runtime")
+
+	// ErrUnconfigured indicates that a sequence requires the config, which is
+	// not loaded yet.
+	ErrUnconfigured = errors.New("config not loaded")
+
+	// ErrDebugRequired indicates that an operation is only allowed with debug
+	// enabled in the config.
+	ErrDebugRequired = errors.New("debug must be enabled in the config")
+
+	// ErrNotForceable indicates that a sequence can not be forced to run while
+	// another sequence is running.
+	ErrNotForceable = errors.New("sequence can not be forced")
+
+	// ErrBootInProgress indicates that a sequence can only run before the boot
+	// sequence starts, e.g. since it repairs the partitions that boot mounts.
+	ErrBootInProgress = errors.New("boot sequence already started")
+
+	// ErrSkipped indicates that a task does not apply, and was intentionally
+	// skipped.
+	ErrSkipped = errors.New("skipped")
+
+	// ErrDegraded indicates that a task completed without reaching the state
+	// it waits for, e.g. services that are not healthy in time.
+	ErrDegraded = errors.New("degraded")
+
+	// ErrNotAbortable indicates that a sequence can not be aborted, either
+	// since the sequence is never abortable, or since it is past its point of
+	// no return.
+	ErrNotAbortable = errors.New("sequence can not be aborted")
+
+	// ErrNotRunning indicates that no sequence is running.
+	ErrNotRunning = errors.New("no sequence is running")
+
+	// ErrNotPaused indicates that no sequence is paused by the single-step
+	// mode.
+	ErrNotPaused = errors.New("no sequence is paused")
+
+	// ErrRecursiveSequence indicates that a task tried to run a sequence
+	// nested in itself.
+	ErrRecursiveSequence = errors.New("sequence is already running")
+
+	// ErrInMaintenance indicates that the maintenance sequence was requested
+	// while the machine is already in maintenance.
+	ErrInMaintenance = errors.New("machine is in maintenance")
+
+	// ErrNotInMaintenance indicates that the resume sequence was requested
+	// while the machine is not in maintenance.
+	ErrNotInMaintenance = errors.New("machine is not in maintenance")
+
+	// ErrPreflight indicates that a sequence did not run, since some of its
+	// pre-flight checks failed.
+	ErrPreflight = errors.New("pre-flight checks failed")
 )
+
+// skipError is returned by a task that was skipped.
+type skipError struct {
+	reason string
+}
+
+func (e *skipError) Error() string {
+	return fmt.Sprintf("%s (%s)", ErrSkipped, e.reason)
+}
+
+func (e *skipError) Is(target error) bool {
+	return target == ErrSkipped
+}
+
+// Skip returns an error wrapping ErrSkipped, which a task returns when it
+// does not apply, e.g. to the platform or to the state left by an earlier
+// sequence. The controller logs the task as skipped for the reason, rather
+// than done, and does not treat it as a failure.
+func Skip(reason string) error {
+	return &skipError{reason: reason}
+}
+
+// SkipReason returns the reason a task was skipped, and whether the error
+// denotes a skipped task.
+func SkipReason(err error) (reason string, ok bool) {
+	var e *skipError
+
+	if errors.As(err, &e) {
+		return e.reason, true
+	}
+
+	return "", errors.Is(err, ErrSkipped)
+}
+
+// degradedError is returned by a task that completed degraded.
+type degradedError struct {
+	reason string
+}
+
+func (e *degradedError) Error() string {
+	return fmt.Sprintf("%s (%s)", ErrDegraded, e.reason)
+}
+
+func (e *degradedError) Is(target error) bool {
+	return target == ErrDegraded
+}
+
+// Degrade returns an error wrapping ErrDegraded, which a task returns when the
+// sequence should complete even though the task did not reach its goal. The
+// controller logs a warning with the reason, records it in the result of the
+// sequence, and does not treat the task as a failure.
+func Degrade(reason string) error {
+	return &degradedError{reason: reason}
+}
+
+// DegradedReason returns the reason a task completed degraded, and whether the
+// error denotes a degraded task.
+func DegradedReason(err error) (reason string, ok bool) {
+	var e *degradedError
+
+	if errors.As(err, &e) {
+		return e.reason, true
+	}
+
+	return "", errors.Is(err, ErrDegraded)
+}
+
+// InvalidSequenceData returns an error wrapping ErrInvalidSequenceData, which
+// reports the data type the sequence expects and the one it got.
+func InvalidSequenceData(expected, actual interface{}) error {
+	return fmt.Errorf("%w: expected %T, got %T", ErrInvalidSequenceData, expected, actual)
+}
@@ -0,0 +1,74 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestInvalidSequenceData(t *testing.T) {
+	var in *ConfigReload
+
+	err := InvalidSequenceData(in, "config")
+
+	if !errors.Is(err, ErrInvalidSequenceData) {
+		t.Errorf("InvalidSequenceData() = %v, want it to wrap %v", err, ErrInvalidSequenceData)
+	}
+
+	if want := "invalid sequence data: expected *runtime.ConfigReload, got string"; err.Error() != want {
+		t.Errorf("InvalidSequenceData() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		err     error
+		reason  string
+		skipped bool
+	}{
+		{name: "nil", err: nil},
+		{name: "failure", err: errors.New("failed")},
+		{name: "skip", err: Skip("not on metal"), reason: "not on metal", skipped: true},
+		{name: "wrapped", err: fmt.Errorf("task: %w", Skip("not on metal")), reason: "not on metal", skipped: true},
+		{name: "sentinel", err: ErrSkipped, skipped: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, skipped := SkipReason(tt.err)
+			if reason != tt.reason || skipped != tt.skipped {
+				t.Errorf("SkipReason() = (%q, %v), want (%q, %v)", reason, skipped, tt.reason, tt.skipped)
+			}
+		})
+	}
+
+	if err := Skip("not on metal"); !errors.Is(err, ErrSkipped) {
+		t.Errorf("Skip() = %v, want it to wrap %v", err, ErrSkipped)
+	}
+}
+
+func TestDegradedReason(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		err      error
+		reason   string
+		degraded bool
+	}{
+		{name: "nil", err: nil},
+		{name: "failure", err: errors.New("failed")},
+		{name: "skip", err: Skip("not on metal")},
+		{name: "degrade", err: Degrade("kubelet not healthy"), reason: "kubelet not healthy", degraded: true},
+		{name: "wrapped", err: fmt.Errorf("task: %w", Degrade("kubelet not healthy")), reason: "kubelet not healthy", degraded: true},
+		{name: "sentinel", err: ErrDegraded, degraded: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, degraded := DegradedReason(tt.err)
+			if reason != tt.reason || degraded != tt.degraded {
+				t.Errorf("DegradedReason() = (%q, %v), want (%q, %v)", reason, degraded, tt.reason, tt.degraded)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import "context"
+
+// SequenceRequest asks the controller to run a sequence in response to an
+// event.
+type SequenceRequest struct {
+	Sequence Sequence
+	Data     interface{}
+	// Reason describes the event, for the logs, e.g. `SIGTERM`.
+	Reason string
+	// Force preempts the running sequence. Only the shutdown and reboot
+	// sequences can be forced.
+	Force bool
+}
+
+// EventSource defines the requirements for a source of events that trigger
+// sequences, such as signals, ACPI, GPIO or a watchdog.
+type EventSource interface {
+	// Name identifies the source in the logs.
+	Name() string
+	// Listen sends a request for every event until the context is canceled,
+	// and returns the error that stopped the source, if any. Sends must also
+	// select on the context, since requests are no longer received once it is
+	// canceled.
+	Listen(ctx context.Context, requests chan<- SequenceRequest) error
+}
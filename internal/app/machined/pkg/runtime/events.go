@@ -0,0 +1,62 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import "time"
+
+// EventType represents the type of a sequence lifecycle event.
+type EventType int
+
+const (
+	// EventSequenceStarted is published when a sequence starts.
+	EventSequenceStarted EventType = iota
+	// EventPhaseStarted is published when a phase of a sequence starts.
+	EventPhaseStarted
+	// EventTaskStarted is published when a task of a phase starts.
+	EventTaskStarted
+	// EventTaskFinished is published when a task of a phase returns.
+	EventTaskFinished
+	// EventSequenceFinished is published when a sequence completes.
+	EventSequenceFinished
+	// EventTaskProgress is published when a task reports its progress.
+	EventTaskProgress
+	// EventTaskMessage is published when a task reports a message, e.g. the
+	// result of a verification that operators audit.
+	EventTaskMessage
+)
+
+// String returns the string representation of an `EventType`.
+func (t EventType) String() string {
+	return [...]string{"SequenceStarted", "PhaseStarted", "TaskStarted", "TaskFinished", "SequenceFinished", "TaskProgress", "TaskMessage"}[t]
+}
+
+// Event is a sequence lifecycle event. Phase and task numbers start at 1, and
+// are only set for the event types they apply to.
+type Event struct {
+	Type       EventType
+	Sequence   Sequence
+	Phase      int
+	PhaseTotal int
+	Task       int
+	TaskTotal  int
+	TaskName   string
+	// Elapsed is the duration of the task or sequence, for the finished
+	// events.
+	Elapsed time.Duration
+	Error   string
+	// Progress is the progress reported by the task, for the progress events.
+	Progress Progress
+	// Message is the message reported by the task, for the message events.
+	Message string
+}
+
+// Events defines the requirements for a publisher of sequence lifecycle
+// events.
+type Events interface {
+	// Subscribe returns a channel that receives the events published from now
+	// on, and a function that cancels the subscription. Events are dropped
+	// for subscribers that do not keep up.
+	Subscribe() (<-chan Event, func())
+}
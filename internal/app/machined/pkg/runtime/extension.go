@@ -0,0 +1,67 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ExtensionPoint represents a point in the sequences at which the phases of
+// extensions are run.
+type ExtensionPoint int
+
+const (
+	// ExtensionPointAfterNetwork is in the boot sequence, once the network is
+	// up, the config is saved, and the hostname is set.
+	ExtensionPointAfterNetwork ExtensionPoint = iota
+	// ExtensionPointBeforeServices is in the boot sequence, right before the
+	// services, including the kubelet, are started.
+	ExtensionPointBeforeServices
+	// ExtensionPointAfterServices is in the boot sequence, right after the
+	// services are started.
+	ExtensionPointAfterServices
+	// ExtensionPointBeforeShutdown is in the shutdown and reboot sequences,
+	// right before the services are stopped.
+	ExtensionPointBeforeShutdown
+)
+
+// String returns the string representation of an `ExtensionPoint`.
+func (p ExtensionPoint) String() string {
+	return [...]string{"after-network", "before-services", "after-services", "before-shutdown"}[p]
+}
+
+// ParseExtensionPoint returns the `ExtensionPoint` that matches the specified
+// string.
+func ParseExtensionPoint(s string) (ExtensionPoint, error) {
+	for p := ExtensionPointAfterNetwork; p <= ExtensionPointBeforeShutdown; p++ {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: unknown extension point %q", ErrInvalidExtension, s)
+}
+
+// ErrInvalidExtension indicates that an extension can not be registered.
+var ErrInvalidExtension = errors.New("invalid extension")
+
+// ExtensionError is the error of a task contributed by an extension. The
+// failures of extensions are isolated: they are reported, and do not fail the
+// sequence.
+type ExtensionError struct {
+	Extension string
+	Point     ExtensionPoint
+	Err       error
+}
+
+func (e *ExtensionError) Error() string {
+	return fmt.Sprintf("extension %q at %s: %s", e.Extension, e.Point, e.Err)
+}
+
+// Unwrap returns the error of the task.
+func (e *ExtensionError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,14 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+// HostnameSource defines the requirements for a source of the machine's
+// hostname.
+type HostnameSource interface {
+	Name() string
+	// Hostname returns the hostname provided by the source. An empty string
+	// indicates that the source does not provide a hostname.
+	Hostname(Runtime) (string, error)
+}
@@ -0,0 +1,14 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+// InstallRequest is the data of the install sequence. The install sequences
+// run at startup have no data, which is the same as an empty request.
+type InstallRequest struct {
+	// Force reinstalls even if the system is already installed, wiping the
+	// install disk. Otherwise, the install sequence is skipped once the
+	// system is installed, so that a repeated install sequence is a no-op.
+	Force bool
+}
@@ -0,0 +1,116 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Level represents the severity of a log entry.
+type Level int
+
+const (
+	// LevelDebug is for the details only useful when debugging.
+	LevelDebug Level = iota
+	// LevelInfo is for progress, e.g. a phase starting.
+	LevelInfo
+	// LevelWarn is for problems that do not stop the sequence.
+	LevelWarn
+	// LevelError is for failures, e.g. a task returning an error.
+	LevelError
+)
+
+// String returns the string representation of a Level.
+func (l Level) String() string {
+	return [...]string{"debug", "info", "warn", "error"}[l]
+}
+
+// Logger defines the requirements for a leveled logger. The key-value pairs
+// annotate the entry, e.g. `"sequence", "boot", "phase", "1/3"`.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// StandardLogger is a `Logger` that writes to a `log.Logger`, or to the
+// standard logger if there is none. Entries above the info level are prefixed
+// with the level, and the key-value pairs follow the message as `key=value`.
+type StandardLogger struct {
+	Logger *log.Logger
+}
+
+// Debug implements the Logger interface.
+func (l StandardLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.output(LevelDebug, msg, keysAndValues)
+}
+
+// Info implements the Logger interface.
+func (l StandardLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.output(LevelInfo, msg, keysAndValues)
+}
+
+// Warn implements the Logger interface.
+func (l StandardLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.output(LevelWarn, msg, keysAndValues)
+}
+
+// Error implements the Logger interface.
+func (l StandardLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.output(LevelError, msg, keysAndValues)
+}
+
+func (l StandardLogger) output(level Level, msg string, keysAndValues []interface{}) {
+	line := FormatLogEntry(level, msg, keysAndValues...)
+
+	if l.Logger == nil {
+		// nolint: errcheck
+		log.Output(3, line)
+
+		return
+	}
+
+	// nolint: errcheck
+	l.Logger.Output(3, line)
+}
+
+// FormatLogEntry formats an entry as `[LEVEL: ]msg key=value ...`, where info
+// entries have no level. Values with spaces are quoted, and a key without a
+// value is paired with `MISSING`.
+func FormatLogEntry(level Level, msg string, keysAndValues ...interface{}) string {
+	var sb strings.Builder
+
+	switch level {
+	case LevelDebug:
+		sb.WriteString("DEBUG: ")
+	case LevelWarn:
+		sb.WriteString("WARNING: ")
+	case LevelError:
+		sb.WriteString("ERROR: ")
+	case LevelInfo:
+	}
+
+	sb.WriteString(msg)
+
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value interface{} = "MISSING"
+
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+
+		s := fmt.Sprint(value)
+		if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+			s = fmt.Sprintf("%q", s)
+		}
+
+		fmt.Fprintf(&sb, " %v=%s", keysAndValues[i], s)
+	}
+
+	return sb.String()
+}
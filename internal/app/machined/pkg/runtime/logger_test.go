@@ -0,0 +1,42 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"bytes"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestFormatLogEntry(t *testing.T) {
+	for _, tt := range []struct {
+		level         Level
+		msg           string
+		keysAndValues []interface{}
+		want          string
+	}{
+		{LevelInfo, "phase done", []interface{}{"sequence", SequenceBoot, "phase", "1/3", "duration", time.Second}, "phase done sequence=boot phase=1/3 duration=1s"},
+		{LevelWarn, "continuing", nil, "WARNING: continuing"},
+		{LevelError, "task failed", []interface{}{"error", "no such file"}, `ERROR: task failed error="no such file"`},
+		{LevelDebug, "odd", []interface{}{"key"}, "DEBUG: odd key=MISSING"},
+	} {
+		if got := FormatLogEntry(tt.level, tt.msg, tt.keysAndValues...); got != tt.want {
+			t.Errorf("FormatLogEntry() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestStandardLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := StandardLogger{Logger: log.New(&buf, "", 0)}
+
+	logger.Error("task failed", "task", "2/5")
+
+	if want := "ERROR: task failed task=2/5\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,32 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+)
+
+// MessageFunc receives the messages of a task.
+type MessageFunc func(string)
+
+type messageKey struct{}
+
+// WithMessages returns a context that carries the receiver of the messages
+// reported with ReportMessage. The controller sets it for the tasks it runs,
+// and publishes the messages as events.
+func WithMessages(ctx context.Context, f MessageFunc) context.Context {
+	return context.WithValue(ctx, messageKey{}, f)
+}
+
+// ReportMessage reports a message of the running task, formatted like
+// fmt.Sprintf. Unlike the log of the task, the messages are published in the
+// events of the sequence. It does nothing if the context does not carry a
+// receiver.
+func ReportMessage(ctx context.Context, format string, args ...interface{}) {
+	if f, ok := ctx.Value(messageKey{}).(MessageFunc); ok && f != nil {
+		f(fmt.Sprintf(format, args...))
+	}
+}
@@ -0,0 +1,46 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"context"
+	"time"
+)
+
+// OutcomeResult represents the result of a sequence.
+type OutcomeResult string
+
+const (
+	// OutcomeResultSuccess indicates that all phases of the sequence succeeded.
+	OutcomeResultSuccess OutcomeResult = "success"
+	// OutcomeResultFailure indicates that the sequence failed.
+	OutcomeResultFailure OutcomeResult = "failure"
+)
+
+// Outcome is the structured result of a sequence.
+type Outcome struct {
+	Sequence Sequence
+	Result   OutcomeResult
+	Duration time.Duration
+	// FailedTask is the name of the first task that failed, if any.
+	FailedTask string
+	Error      string
+}
+
+// OutcomeSink defines the requirements for a receiver of sequence outcomes,
+// e.g. a central fleet management service.
+type OutcomeSink interface {
+	// Report delivers the outcome. It is called in the background, and must
+	// return once the context is canceled.
+	Report(context.Context, Outcome) error
+}
+
+// NoopOutcomeSink is an `OutcomeSink` that discards all outcomes.
+type NoopOutcomeSink struct{}
+
+// Report implements the OutcomeSink interface.
+func (NoopOutcomeSink) Report(context.Context, Outcome) error {
+	return nil
+}
@@ -0,0 +1,26 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+// Plan describes the phases and tasks that a sequence would run, in order.
+type Plan struct {
+	Sequence string      `json:"sequence"`
+	Phases   []PlanPhase `json:"phases"`
+}
+
+// PlanPhase describes a phase. The tasks of a phase run concurrently, and an
+// overlapping phase runs concurrently with the next phase.
+type PlanPhase struct {
+	Number  int        `json:"number"`
+	Tasks   []PlanTask `json:"tasks"`
+	Overlap bool       `json:"overlap,omitempty"`
+}
+
+// PlanTask describes a task by the name of its function, and its registered
+// label, if any.
+type PlanTask struct {
+	Name  string `json:"name"`
+	Label string `json:"label,omitempty"`
+}
@@ -0,0 +1,109 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PreflightCheck is a precondition of a sequence, e.g. that the disk has
+// enough space for an upgrade. The checks run before the sequence, so that it
+// fails before changing anything rather than partway through. A check must
+// not change the state of the machine.
+type PreflightCheck struct {
+	Name  string
+	Check func(ctx context.Context, r Runtime) error
+}
+
+// PreflightResult is the outcome of a pre-flight check.
+type PreflightResult struct {
+	Name     string
+	Duration time.Duration
+	// Err is the reason the check failed, nil if it passed.
+	Err error
+}
+
+// PreflightTimeout is the time allowed to each of the pre-flight checks.
+const PreflightTimeout = 30 * time.Second
+
+// RunPreflight runs the checks concurrently, each within PreflightTimeout, and
+// returns their results in the order of the checks.
+func RunPreflight(ctx context.Context, r Runtime, checks []PreflightCheck) []PreflightResult {
+	results := make([]PreflightResult, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i, check := range checks {
+		i, check := i, check
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, PreflightTimeout)
+			defer cancel()
+
+			start := time.Now()
+
+			err := check.Check(ctx, r)
+
+			results[i] = PreflightResult{
+				Name:     check.Name,
+				Duration: time.Since(start),
+				Err:      err,
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// PreflightError is the consolidated report of the pre-flight checks of a
+// sequence which failed. It wraps ErrPreflight.
+type PreflightError struct {
+	Sequence Sequence
+	// Failed are the results of the checks which failed.
+	Failed []PreflightResult
+}
+
+func (e *PreflightError) Error() string {
+	reasons := make([]string, 0, len(e.Failed))
+
+	for _, result := range e.Failed {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", result.Name, result.Err))
+	}
+
+	return fmt.Sprintf("%s sequence: %s: %s", e.Sequence, ErrPreflight, strings.Join(reasons, "; "))
+}
+
+// Is implements errors.Is.
+func (e *PreflightError) Is(target error) bool {
+	return target == ErrPreflight
+}
+
+// CheckPreflight returns a *PreflightError if any of the checks failed, or
+// else nil.
+func CheckPreflight(seq Sequence, results []PreflightResult) error {
+	var failed []PreflightResult
+
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &PreflightError{Sequence: seq, Failed: failed}
+}
@@ -0,0 +1,78 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunPreflight(t *testing.T) {
+	errSpace := errors.New("8 MiB available")
+
+	check := func(name string, delay time.Duration, err error) PreflightCheck {
+		return PreflightCheck{
+			Name: name,
+			Check: func(ctx context.Context, r Runtime) error {
+				if _, ok := ctx.Deadline(); !ok {
+					t.Errorf("check %q has no deadline", name)
+				}
+
+				time.Sleep(delay)
+
+				return err
+			},
+		}
+	}
+
+	results := RunPreflight(context.Background(), nil, []PreflightCheck{
+		check("installer image", 20*time.Millisecond, nil),
+		check("disk space", 0, errSpace),
+		check("cluster endpoint", 10*time.Millisecond, nil),
+	})
+
+	if len(results) != 3 {
+		t.Fatalf("RunPreflight() returned %d results, want 3", len(results))
+	}
+
+	for i, name := range []string{"installer image", "disk space", "cluster endpoint"} {
+		if results[i].Name != name {
+			t.Errorf("RunPreflight() result %d is %q, want %q", i, results[i].Name, name)
+		}
+	}
+
+	if results[0].Err != nil || results[2].Err != nil || results[1].Err != errSpace {
+		t.Errorf("RunPreflight() errors = %v, %v, %v", results[0].Err, results[1].Err, results[2].Err)
+	}
+
+	if results[0].Duration < 20*time.Millisecond {
+		t.Errorf("RunPreflight() duration = %s, want at least 20ms", results[0].Duration)
+	}
+
+	err := CheckPreflight(SequenceUpgrade, results)
+	if !errors.Is(err, ErrPreflight) {
+		t.Fatalf("CheckPreflight() error = %v, want %v", err, ErrPreflight)
+	}
+
+	if want := "upgrade sequence: pre-flight checks failed: disk space: 8 MiB available"; err.Error() != want {
+		t.Errorf("CheckPreflight() error = %q, want %q", err, want)
+	}
+
+	var preflightErr *PreflightError
+
+	if !errors.As(err, &preflightErr) || len(preflightErr.Failed) != 1 {
+		t.Errorf("CheckPreflight() error = %#v, want the failed check only", err)
+	}
+
+	if err = CheckPreflight(SequenceUpgrade, results[2:]); err != nil {
+		t.Errorf("CheckPreflight() error = %v, want nil", err)
+	}
+
+	if err = CheckPreflight(SequenceUpgrade, nil); err != nil {
+		t.Errorf("CheckPreflight() without checks error = %v, want nil", err)
+	}
+}
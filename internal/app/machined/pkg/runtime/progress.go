@@ -0,0 +1,46 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import "context"
+
+// Progress is the progress of a long running task, e.g. the bytes of an image
+// downloaded so far.
+type Progress struct {
+	Current int64
+	// Total is zero if it is not known.
+	Total int64
+	// Unit describes the values, e.g. `bytes`.
+	Unit string
+}
+
+// Percent returns the completed percentage, or -1 if the total is not known.
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+
+	return float64(p.Current) * 100 / float64(p.Total)
+}
+
+// ProgressFunc receives the progress of a task.
+type ProgressFunc func(Progress)
+
+type progressKey struct{}
+
+// WithProgress returns a context that carries the receiver of the progress
+// reported with ReportProgress. The controller sets it for the tasks it runs.
+func WithProgress(ctx context.Context, f ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, f)
+}
+
+// ReportProgress reports the progress of the running task. It does nothing if
+// the context does not carry a receiver, so that the code shared with e.g. the
+// API can report progress unconditionally.
+func ReportProgress(ctx context.Context, p Progress) {
+	if f, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && f != nil {
+		f(p)
+	}
+}
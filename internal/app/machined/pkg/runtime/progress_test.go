@@ -0,0 +1,35 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReportProgress(t *testing.T) {
+	// Without a receiver, the progress is dropped.
+	ReportProgress(context.Background(), Progress{Current: 1})
+
+	var reported []Progress
+
+	ctx := WithProgress(context.Background(), func(p Progress) {
+		reported = append(reported, p)
+	})
+
+	ReportProgress(ctx, Progress{Current: 50, Total: 200, Unit: "bytes"})
+
+	if len(reported) != 1 || reported[0].Current != 50 {
+		t.Fatalf("reported = %v, want one report", reported)
+	}
+
+	if got := reported[0].Percent(); got != 25 {
+		t.Errorf("Percent() = %v, want 25", got)
+	}
+
+	if got := (Progress{Current: 50}).Percent(); got != -1 {
+		t.Errorf("Percent() = %v, want -1 without a total", got)
+	}
+}
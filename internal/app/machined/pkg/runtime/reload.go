@@ -0,0 +1,13 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+// ConfigReload is the data of the reload sequence.
+type ConfigReload struct {
+	// Config is the validated new config.
+	Config []byte
+	// Services are the IDs of the services restarted to apply the changes.
+	Services []string
+}
@@ -0,0 +1,75 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/talos-systems/talos/pkg/constants"
+)
+
+// resetPartitions are the labels of the partitions of the system disk, and
+// their mount points, in the order they are wiped.
+var resetPartitions = []struct {
+	label      string
+	mountpoint string
+}{
+	{constants.BootPartitionLabel, constants.BootMountPoint},
+	{constants.EphemeralPartitionLabel, constants.EphemeralMountPoint},
+}
+
+// PartitionsToWipe returns the labels of the partitions of the system disk
+// wiped by a reset which preserves the partitions given by label or by mount
+// point. Without partitions to preserve, it returns nil, and the whole disk is
+// wiped, partition table included. Preserving every partition is an error, as
+// the reset would not wipe anything, and so is preserving the ephemeral
+// partition without the boot partition, as the system could not boot from the
+// wiped boot partition, and would not be installed anew over the preserved
+// ephemeral partition.
+func PartitionsToWipe(preserve []string) (wipe []string, err error) {
+	if len(preserve) == 0 {
+		return nil, nil
+	}
+
+	preserved := map[string]bool{}
+
+	for _, p := range preserve {
+		found := false
+
+		for _, part := range resetPartitions {
+			if p == part.label || p == part.mountpoint {
+				preserved[part.label] = true
+				found = true
+			}
+		}
+
+		if !found {
+			names := make([]string, 0, 2*len(resetPartitions))
+
+			for _, part := range resetPartitions {
+				names = append(names, part.label, part.mountpoint)
+			}
+
+			return nil, fmt.Errorf("unknown partition %q to preserve, valid partitions are: %s", p, strings.Join(names, ", "))
+		}
+	}
+
+	if preserved[constants.EphemeralPartitionLabel] && !preserved[constants.BootPartitionLabel] {
+		return nil, fmt.Errorf("preserving the %s partition requires preserving the %s partition", constants.EphemeralPartitionLabel, constants.BootPartitionLabel)
+	}
+
+	for _, part := range resetPartitions {
+		if !preserved[part.label] {
+			wipe = append(wipe, part.label)
+		}
+	}
+
+	if len(wipe) == 0 {
+		return nil, fmt.Errorf("the reset would preserve every partition of the system disk")
+	}
+
+	return wipe, nil
+}
@@ -0,0 +1,67 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// nolint: scopelint
+package runtime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPartitionsToWipe(t *testing.T) {
+	tests := []struct {
+		name     string
+		preserve []string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "wipe the disk",
+		},
+		{
+			name:     "preserve by mount point",
+			preserve: []string{"/boot"},
+			want:     []string{"EPHEMERAL"},
+		},
+		{
+			name:     "preserve by label",
+			preserve: []string{"ESP"},
+			want:     []string{"EPHEMERAL"},
+		},
+		{
+			name:     "preserve twice",
+			preserve: []string{"ESP", "/boot"},
+			want:     []string{"EPHEMERAL"},
+		},
+		{
+			name:     "preserve ephemeral without boot",
+			preserve: []string{"/var"},
+			wantErr:  true,
+		},
+		{
+			name:     "preserve everything",
+			preserve: []string{"/boot", "EPHEMERAL"},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown partition",
+			preserve: []string{"/home"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PartitionsToWipe(tt.preserve)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PartitionsToWipe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PartitionsToWipe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import "time"
+
+// SequenceResult is the timing breakdown of a sequence that ran to
+// completion, successfully or not. The start times of the phases and tasks
+// are relative to the start of the sequence.
+type SequenceResult struct {
+	Sequence Sequence
+	Start    time.Time
+	Duration time.Duration
+	Error    string
+	Phases   []PhaseResult
+}
+
+// PhaseResult is the timing of a phase, from the start of its first task to
+// the end of its last one.
+type PhaseResult struct {
+	Start    time.Duration
+	Duration time.Duration
+	Tasks    []TaskResult
+}
+
+// TaskResult is the timing and outcome of a task. A degraded task has the
+// reason it completed degraded, and no error.
+type TaskResult struct {
+	Name     string
+	Start    time.Duration
+	Duration time.Duration
+	Error    string
+	Degraded string
+}
+
+// Slowest returns the task that took the longest, and false if no task ran.
+func (r SequenceResult) Slowest() (TaskResult, bool) {
+	var (
+		slowest TaskResult
+		found   bool
+	)
+
+	for _, phase := range r.Phases {
+		for _, task := range phase.Tasks {
+			if !found || task.Duration > slowest.Duration {
+				slowest, found = task, true
+			}
+		}
+	}
+
+	return slowest, found
+}
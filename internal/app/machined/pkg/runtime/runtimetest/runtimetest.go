@@ -0,0 +1,113 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package runtimetest provides a fake runtime, and a sequencer of fabricated
+// phases, to exercise the controller of the sequences in unit tests, without
+// the hardware, or the state of a real machine.
+package runtimetest
+
+import (
+	"errors"
+	"net"
+
+	"github.com/talos-systems/go-procfs/procfs"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+// Runtime is a fake runtime.
+type Runtime struct {
+	Configurator runtime.Configurator
+	Platform     *Platform
+	Machine      runtime.MachineState
+	Cluster      runtime.ClusterState
+}
+
+// NewRuntime returns a fake runtime with the config, on a fake platform in
+// the mode.
+func NewRuntime(cfg runtime.Configurator, mode runtime.Mode) *Runtime {
+	return &Runtime{
+		Configurator: cfg,
+		Platform:     &Platform{PlatformMode: mode},
+	}
+}
+
+// Config implements the Runtime interface.
+func (r *Runtime) Config() runtime.Configurator {
+	return r.Configurator
+}
+
+// SetConfig implements the Runtime interface. The fake runtime does not parse
+// configs, so it always fails.
+func (r *Runtime) SetConfig([]byte) error {
+	return errors.New("the fake runtime does not support setting the config")
+}
+
+// State implements the Runtime interface.
+func (r *Runtime) State() runtime.State {
+	return (*state)(r)
+}
+
+// state implements the State interface for a fake runtime.
+type state Runtime
+
+func (s *state) Platform() runtime.Platform {
+	return s.Runtime().Platform
+}
+
+func (s *state) Machine() runtime.MachineState {
+	return s.Runtime().Machine
+}
+
+func (s *state) Cluster() runtime.ClusterState {
+	return s.Runtime().Cluster
+}
+
+func (s *state) Runtime() *Runtime {
+	return (*Runtime)(s)
+}
+
+// Platform is a fake platform.
+type Platform struct {
+	PlatformName  string
+	PlatformMode  runtime.Mode
+	Config        []byte
+	HostnameBytes []byte
+	IPs           []net.IP
+	Args          procfs.Parameters
+}
+
+// Name implements the Platform interface.
+func (p *Platform) Name() string {
+	if p.PlatformName == "" {
+		return "fake"
+	}
+
+	return p.PlatformName
+}
+
+// Configuration implements the Platform interface.
+func (p *Platform) Configuration() ([]byte, error) {
+	return p.Config, nil
+}
+
+// Hostname implements the Platform interface.
+func (p *Platform) Hostname() ([]byte, error) {
+	return p.HostnameBytes, nil
+}
+
+// Mode implements the Platform interface.
+func (p *Platform) Mode() runtime.Mode {
+	return p.PlatformMode
+}
+
+// ExternalIPs implements the Platform interface.
+func (p *Platform) ExternalIPs() ([]net.IP, error) {
+	return p.IPs, nil
+}
+
+// KernelArgs implements the Platform interface.
+func (p *Platform) KernelArgs() procfs.Parameters {
+	return p.Args
+}
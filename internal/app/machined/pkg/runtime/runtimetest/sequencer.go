@@ -0,0 +1,217 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtimetest
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/talos-systems/talos/api/machine"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+// Sequencer is a sequencer of fabricated phases: each sequence runs the
+// phases set for it, whatever the runtime and the request.
+type Sequencer struct {
+	mu        sync.Mutex
+	phases    map[runtime.Sequence][]runtime.Phase
+	deferred  map[runtime.Sequence]runtime.Phase
+	policies  map[runtime.Sequence]runtime.ErrorPolicy
+	preflight map[runtime.Sequence][]runtime.PreflightCheck
+}
+
+// NewSequencer returns a sequencer with no phases.
+func NewSequencer() *Sequencer {
+	return &Sequencer{
+		phases:    map[runtime.Sequence][]runtime.Phase{},
+		deferred:  map[runtime.Sequence]runtime.Phase{},
+		policies:  map[runtime.Sequence]runtime.ErrorPolicy{},
+		preflight: map[runtime.Sequence][]runtime.PreflightCheck{},
+	}
+}
+
+// SetPhases sets the phases of the sequence.
+func (s *Sequencer) SetPhases(seq runtime.Sequence, phases ...runtime.Phase) *Sequencer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.phases[seq] = phases
+
+	return s
+}
+
+// SetDeferred sets the deferred tasks of the sequence.
+func (s *Sequencer) SetDeferred(seq runtime.Sequence, phase runtime.Phase) *Sequencer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deferred[seq] = phase
+
+	return s
+}
+
+// SetErrorPolicy sets the error policy of the sequence, which defaults to
+// `ErrorPolicyFailFast`.
+func (s *Sequencer) SetErrorPolicy(seq runtime.Sequence, policy runtime.ErrorPolicy) *Sequencer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.policies[seq] = policy
+
+	return s
+}
+
+// SetPreflight sets the pre-flight checks of the sequence.
+func (s *Sequencer) SetPreflight(seq runtime.Sequence, checks ...runtime.PreflightCheck) *Sequencer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.preflight[seq] = checks
+
+	return s
+}
+
+func (s *Sequencer) sequence(seq runtime.Sequence) []runtime.Phase {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.phases[seq]
+}
+
+// Boot implements the Sequencer interface.
+func (s *Sequencer) Boot(runtime.Runtime) []runtime.Phase {
+	return s.sequence(runtime.SequenceBoot)
+}
+
+// Deferred implements the Sequencer interface.
+func (s *Sequencer) Deferred(seq runtime.Sequence, _ runtime.Runtime) runtime.Phase {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.deferred[seq]
+}
+
+// ErrorPolicy implements the Sequencer interface.
+func (s *Sequencer) ErrorPolicy(seq runtime.Sequence) runtime.ErrorPolicy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.policies[seq]
+}
+
+// Initialize implements the Sequencer interface.
+func (s *Sequencer) Initialize(runtime.Runtime) []runtime.Phase {
+	return s.sequence(runtime.SequenceInitialize)
+}
+
+// Install implements the Sequencer interface.
+func (s *Sequencer) Install(runtime.Runtime, *runtime.InstallRequest) []runtime.Phase {
+	return s.sequence(runtime.SequenceInstall)
+}
+
+// Maintenance implements the Sequencer interface.
+func (s *Sequencer) Maintenance(runtime.Runtime) []runtime.Phase {
+	return s.sequence(runtime.SequenceMaintenance)
+}
+
+// Preflight implements the Sequencer interface.
+func (s *Sequencer) Preflight(seq runtime.Sequence, _ runtime.Runtime, _ interface{}) []runtime.PreflightCheck {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.preflight[seq]
+}
+
+// Reboot implements the Sequencer interface.
+func (s *Sequencer) Reboot(runtime.Runtime) []runtime.Phase {
+	return s.sequence(runtime.SequenceReboot)
+}
+
+// Recover implements the Sequencer interface.
+func (s *Sequencer) Recover(runtime.Runtime) []runtime.Phase {
+	return s.sequence(runtime.SequenceRecover)
+}
+
+// RegisterExtension implements the Sequencer interface. The phases of the
+// sequences are fabricated, so extensions are accepted, and never run.
+func (s *Sequencer) RegisterExtension(runtime.ExtensionPoint, string, runtime.Phase) error {
+	return nil
+}
+
+// Reload implements the Sequencer interface.
+func (s *Sequencer) Reload(runtime.Runtime, *runtime.ConfigReload) []runtime.Phase {
+	return s.sequence(runtime.SequenceReload)
+}
+
+// Reset implements the Sequencer interface.
+func (s *Sequencer) Reset(runtime.Runtime, *machine.ResetRequest) []runtime.Phase {
+	return s.sequence(runtime.SequenceReset)
+}
+
+// Resume implements the Sequencer interface.
+func (s *Sequencer) Resume(runtime.Runtime) []runtime.Phase {
+	return s.sequence(runtime.SequenceResume)
+}
+
+// Rollback implements the Sequencer interface.
+func (s *Sequencer) Rollback(runtime.Runtime) []runtime.Phase {
+	return s.sequence(runtime.SequenceRollback)
+}
+
+// Shutdown implements the Sequencer interface.
+func (s *Sequencer) Shutdown(runtime.Runtime) []runtime.Phase {
+	return s.sequence(runtime.SequenceShutdown)
+}
+
+// Upgrade implements the Sequencer interface.
+func (s *Sequencer) Upgrade(runtime.Runtime, *machine.UpgradeRequest) []runtime.Phase {
+	return s.sequence(runtime.SequenceUpgrade)
+}
+
+// Recorder fabricates deterministic tasks, and records the order in which
+// they run. It is safe for concurrent use, by the tasks of overlapping
+// phases.
+type Recorder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+// Func returns a task which records its name, and then runs f, if not nil.
+func (rec *Recorder) Func(name string, f runtime.TaskExecutionFunc) runtime.TaskSetupFunc {
+	return func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			rec.mu.Lock()
+			rec.order = append(rec.order, name)
+			rec.mu.Unlock()
+
+			if f == nil {
+				return nil
+			}
+
+			return f(ctx, logger, r)
+		}
+	}
+}
+
+// Task returns a task which records its name, and succeeds.
+func (rec *Recorder) Task(name string) runtime.TaskSetupFunc {
+	return rec.Func(name, nil)
+}
+
+// Fail returns a task which records its name, and fails with the error.
+func (rec *Recorder) Fail(name string, err error) runtime.TaskSetupFunc {
+	return rec.Func(name, func(context.Context, *log.Logger, runtime.Runtime) error {
+		return err
+	})
+}
+
+// Order returns the names of the tasks, in the order they ran.
+func (rec *Recorder) Order() []string {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return append([]string(nil), rec.order...)
+}
@@ -30,6 +30,16 @@ const (
 	SequenceReboot
 	// SequenceNoop is the noop sequence.
 	SequenceNoop
+	// SequenceReload is the config reload sequence.
+	SequenceReload
+	// SequenceRollback is the rollback sequence.
+	SequenceRollback
+	// SequenceRecover is the recover sequence.
+	SequenceRecover
+	// SequenceMaintenance is the maintenance sequence.
+	SequenceMaintenance
+	// SequenceResume is the sequence resuming from maintenance.
+	SequenceResume
 )
 
 const (
@@ -41,11 +51,49 @@ const (
 	reset      = "reset"
 	reboot     = "reboot"
 	noop       = "noop"
+	reload     = "reload"
+	rollback   = "rollback"
+	// recovery is not named after the sequence, which would shadow the
+	// builtin.
+	recovery    = "recover"
+	maintenance = "maintenance"
+	resume      = "resume"
 )
 
 // String returns the string representation of a `Sequence`.
 func (s Sequence) String() string {
-	return [...]string{boot, initialize, install, shutdown, upgrade, reset, reboot, noop}[s]
+	return [...]string{boot, initialize, install, shutdown, upgrade, reset, reboot, noop, reload, rollback, recovery, maintenance, resume}[s]
+}
+
+// Abortable reports whether the sequence can be aborted while it runs, until
+// it reaches its point of no return (see `PointOfNoReturn`): install,
+// upgrade, reset, rollback, recover, maintenance, resume, and noop.
+// The machine can not run without the initialize and boot sequences, and
+// aborting a shutdown or a reboot would leave the machine with its services
+// stopped, so these are never abortable. The reload sequence applies the
+// config in its first phase, which is its point of no return, so it is not
+// abortable either. An aborted maintenance sequence is resumed from like a
+// completed one.
+func (s Sequence) Abortable() bool {
+	switch s {
+	case SequenceInitialize, SequenceBoot, SequenceShutdown, SequenceReboot, SequenceReload:
+		return false
+	default:
+		return true
+	}
+}
+
+// RequiresConfig reports whether the sequence can only run once the config is
+// loaded. The initialize sequence loads it, and the recover sequence restores
+// it, while a shutdown or a reboot must be possible without it, e.g. once
+// loading it failed. The noop sequence runs no tasks.
+func (s Sequence) RequiresConfig() bool {
+	switch s {
+	case SequenceInitialize, SequenceShutdown, SequenceReboot, SequenceRecover, SequenceNoop:
+		return false
+	default:
+		return true
+	}
 }
 
 // ParseSequence returns a `Sequence` that matches the specified string.
@@ -67,6 +115,16 @@ func ParseSequence(s string) (seq Sequence, err error) {
 		seq = SequenceReboot
 	case noop:
 		seq = SequenceNoop
+	case reload:
+		seq = SequenceReload
+	case rollback:
+		seq = SequenceRollback
+	case recovery:
+		seq = SequenceRecover
+	case maintenance:
+		seq = SequenceMaintenance
+	case resume:
+		seq = SequenceResume
 	default:
 		return seq, fmt.Errorf("unknown runtime sequence: %q", s)
 	}
@@ -74,14 +132,39 @@ func ParseSequence(s string) (seq Sequence, err error) {
 	return seq, nil
 }
 
+// ErrorPolicy represents how a sequence handles a failing phase.
+type ErrorPolicy int
+
+const (
+	// ErrorPolicyFailFast aborts the sequence on the first phase error.
+	ErrorPolicyFailFast ErrorPolicy = iota
+	// ErrorPolicyCollect runs all phases of the sequence, and reports the
+	// aggregated errors once the sequence completes.
+	ErrorPolicyCollect
+)
+
+// String returns the string representation of an `ErrorPolicy`.
+func (p ErrorPolicy) String() string {
+	return [...]string{"fail-fast", "collect"}[p]
+}
+
 // Sequencer describes the set of sequences required for the lifecycle
 // management of the operating system.
 type Sequencer interface {
 	Boot(Runtime) []Phase
+	Deferred(Sequence, Runtime) Phase
+	ErrorPolicy(Sequence) ErrorPolicy
 	Initialize(Runtime) []Phase
-	Install(Runtime) []Phase
+	Install(Runtime, *InstallRequest) []Phase
+	Maintenance(Runtime) []Phase
+	Preflight(Sequence, Runtime, interface{}) []PreflightCheck
 	Reboot(Runtime) []Phase
+	Recover(Runtime) []Phase
+	RegisterExtension(ExtensionPoint, string, Phase) error
+	Reload(Runtime, *ConfigReload) []Phase
 	Reset(Runtime, *machine.ResetRequest) []Phase
+	Resume(Runtime) []Phase
+	Rollback(Runtime) []Phase
 	Shutdown(Runtime) []Phase
 	Upgrade(Runtime, *machine.UpgradeRequest) []Phase
 }
@@ -43,6 +43,31 @@ func TestSequence_String(t *testing.T) {
 			s:    SequenceReset,
 			want: "reset",
 		},
+		{
+			name: "reload",
+			s:    SequenceReload,
+			want: "reload",
+		},
+		{
+			name: "rollback",
+			s:    SequenceRollback,
+			want: "rollback",
+		},
+		{
+			name: "recover",
+			s:    SequenceRecover,
+			want: "recover",
+		},
+		{
+			name: "maintenance",
+			s:    SequenceMaintenance,
+			want: "maintenance",
+		},
+		{
+			name: "resume",
+			s:    SequenceResume,
+			want: "resume",
+		},
 	}
 
 	for _, tt := range tests {
@@ -101,6 +126,36 @@ func TestParseSequence(t *testing.T) {
 			wantSeq: SequenceReset,
 			wantErr: false,
 		},
+		{
+			name:    "reload",
+			args:    args{"reload"},
+			wantSeq: SequenceReload,
+			wantErr: false,
+		},
+		{
+			name:    "rollback",
+			args:    args{"rollback"},
+			wantSeq: SequenceRollback,
+			wantErr: false,
+		},
+		{
+			name:    "recover",
+			args:    args{"recover"},
+			wantSeq: SequenceRecover,
+			wantErr: false,
+		},
+		{
+			name:    "maintenance",
+			args:    args{"maintenance"},
+			wantSeq: SequenceMaintenance,
+			wantErr: false,
+		},
+		{
+			name:    "resume",
+			args:    args{"resume"},
+			wantSeq: SequenceResume,
+			wantErr: false,
+		},
 		{
 			name:    "invalid",
 			args:    args{"invalid"},
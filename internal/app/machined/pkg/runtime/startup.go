@@ -0,0 +1,108 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StartupPlan represents the ordered list of sequences run at startup.
+type StartupPlan []Sequence
+
+// DefaultStartupPlan is the startup plan used when one is not specified.
+var DefaultStartupPlan = StartupPlan{SequenceInitialize, SequenceInstall, SequenceBoot}
+
+// ParseStartupPlan returns a `StartupPlan` from a comma separated list of
+// sequence names.
+func ParseStartupPlan(s string) (StartupPlan, error) {
+	plan := StartupPlan{}
+
+	for _, name := range strings.Split(s, ",") {
+		seq, err := ParseSequence(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+
+		plan = append(plan, seq)
+	}
+
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// Validate ensures that the startup plan starts with the initialize sequence,
+// includes the install and boot sequences (in that order), and contains only
+// sequences that can run without request data, and that do not shut down or
+// reboot the machine, as the sequences after them would never run. The
+// recover sequence, if any, must come before the boot sequence, which mounts
+// the partitions it repairs.
+func (p StartupPlan) Validate() error {
+	if len(p) == 0 || p[0] != SequenceInitialize {
+		return fmt.Errorf("startup plan must begin with the %s sequence", SequenceInitialize)
+	}
+
+	seen := map[Sequence]int{}
+
+	for i, seq := range p {
+		switch seq {
+		case SequenceUpgrade, SequenceReset, SequenceReload:
+			return fmt.Errorf("%s sequence is not allowed in the startup plan", seq)
+		case SequenceShutdown, SequenceReboot, SequenceRollback:
+			return fmt.Errorf("%s sequence is not allowed in the startup plan, as it ends the startup", seq)
+		case SequenceInitialize, SequenceInstall, SequenceBoot, SequenceRecover:
+			if _, ok := seen[seq]; ok {
+				return fmt.Errorf("%s sequence is specified more than once in the startup plan", seq)
+			}
+		}
+
+		seen[seq] = i
+	}
+
+	install, ok := seen[SequenceInstall]
+	if !ok {
+		return fmt.Errorf("startup plan is missing the %s sequence", SequenceInstall)
+	}
+
+	boot, ok := seen[SequenceBoot]
+	if !ok {
+		return fmt.Errorf("startup plan is missing the %s sequence", SequenceBoot)
+	}
+
+	if boot < install {
+		return fmt.Errorf("%s sequence must come before the %s sequence in the startup plan", SequenceInstall, SequenceBoot)
+	}
+
+	if recovery, ok := seen[SequenceRecover]; ok && boot < recovery {
+		return fmt.Errorf("%s sequence must come before the %s sequence in the startup plan", SequenceRecover, SequenceBoot)
+	}
+
+	return nil
+}
+
+// Contains returns whether the startup plan runs the sequence.
+func (p StartupPlan) Contains(seq Sequence) bool {
+	for _, s := range p {
+		if s == seq {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns the string representation of a `StartupPlan`.
+func (p StartupPlan) String() string {
+	names := make([]string, 0, len(p))
+
+	for _, seq := range p {
+		names = append(names, seq.String())
+	}
+
+	return strings.Join(names, ",")
+}
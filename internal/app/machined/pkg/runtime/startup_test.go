@@ -0,0 +1,150 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// nolint: scopelint
+package runtime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStartupPlan(t *testing.T) {
+	type args struct {
+		s string
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    StartupPlan
+		wantErr bool
+	}{
+		{
+			name:    "default",
+			args:    args{"initialize,install,boot"},
+			want:    DefaultStartupPlan,
+			wantErr: false,
+		},
+		{
+			name:    "custom",
+			args:    args{"initialize, noop, install, boot"},
+			want:    StartupPlan{SequenceInitialize, SequenceNoop, SequenceInstall, SequenceBoot},
+			wantErr: false,
+		},
+		{
+			name:    "recover",
+			args:    args{"initialize,recover,install,boot"},
+			want:    StartupPlan{SequenceInitialize, SequenceRecover, SequenceInstall, SequenceBoot},
+			wantErr: false,
+		},
+		{
+			name:    "recover after boot",
+			args:    args{"initialize,install,boot,recover"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "not initialize first",
+			args:    args{"install,initialize,boot"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "missing boot",
+			args:    args{"initialize,install"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "missing install",
+			args:    args{"initialize,boot"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "boot before install",
+			args:    args{"initialize,boot,install"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "duplicate",
+			args:    args{"initialize,install,boot,boot"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "shutdown",
+			args:    args{"initialize,install,boot,shutdown"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "reboot",
+			args:    args{"initialize,reboot,install,boot"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "requires data",
+			args:    args{"initialize,install,upgrade,boot"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			args:    args{"initialize,invalid,install,boot"},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStartupPlan(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseStartupPlan() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStartupPlan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartupPlan_String(t *testing.T) {
+	tests := []struct {
+		name string
+		p    StartupPlan
+		want string
+	}{
+		{
+			name: "default",
+			p:    DefaultStartupPlan,
+			want: "initialize,install,boot",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.String(); got != tt.want {
+				t.Errorf("StartupPlan.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartupPlan_Contains(t *testing.T) {
+	plan := StartupPlan{SequenceInitialize, SequenceRecover, SequenceInstall, SequenceBoot}
+
+	if !plan.Contains(SequenceRecover) {
+		t.Errorf("StartupPlan.Contains(%s) = false, want true", SequenceRecover)
+	}
+
+	if DefaultStartupPlan.Contains(SequenceRecover) {
+		t.Errorf("DefaultStartupPlan.Contains(%s) = true, want false", SequenceRecover)
+	}
+}
@@ -0,0 +1,43 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"context"
+)
+
+// SubsequenceRunner runs a sequence nested in the sequence that the context
+// belongs to.
+type SubsequenceRunner func(ctx context.Context, seq Sequence, data interface{}) error
+
+type subsequenceRunnerKey struct{}
+
+// WithSubsequenceRunner returns a context that carries the runner used by
+// RunSubsequence. The controller sets it for the sequences it runs.
+func WithSubsequenceRunner(ctx context.Context, f SubsequenceRunner) context.Context {
+	return context.WithValue(ctx, subsequenceRunnerKey{}, f)
+}
+
+// RunSubsequence runs a sequence from within a task of the running sequence,
+// e.g. an upgrade that reboots once it is done. The running sequence holds
+// the lock until the task returns, so the controller rejects the calls of
+// `Controller.RunContext`, `RunWaitContext` and `RunForcedContext` made with
+// the context of a task with ErrLocked, instead of queueing the sequence
+// behind the task, or preempting the task itself. The calls made without it
+// can not be told apart from those of the API, and must not be made.
+//
+// The nested sequence runs in the context of the task, so that aborting the
+// running sequence aborts it too. It is bounded by its timeout, and a nested
+// shutdown or reboot falls back to a hard poweroff, like a requested one. It returns ErrNotRunning if the context does
+// not belong to a running sequence, and ErrRecursiveSequence if the sequence
+// is already running, at any level of nesting.
+func RunSubsequence(ctx context.Context, seq Sequence, data interface{}) error {
+	f, ok := ctx.Value(subsequenceRunnerKey{}).(SubsequenceRunner)
+	if !ok || f == nil {
+		return ErrNotRunning
+	}
+
+	return f(ctx, seq, data)
+}
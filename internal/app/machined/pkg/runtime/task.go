@@ -0,0 +1,39 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"reflect"
+	"sync"
+)
+
+var taskLabels = struct {
+	sync.RWMutex
+	m map[uintptr]string
+}{
+	m: map[uintptr]string{},
+}
+
+// RegisterTaskLabel registers a human readable description of the task, e.g.
+// "Wipe the system disk", for listing the tasks of a sequence.
+//
+// Tasks are identified by their function, so only tasks declared as top level
+// functions can be labeled: all tasks returned by a wrapper such as
+// `WithRetry` share the label of the wrapper.
+func RegisterTaskLabel(f TaskSetupFunc, label string) {
+	taskLabels.Lock()
+	defer taskLabels.Unlock()
+
+	taskLabels.m[reflect.ValueOf(f).Pointer()] = label
+}
+
+// TaskLabel returns the label registered for the task, or an empty string if
+// there is none.
+func TaskLabel(f TaskSetupFunc) string {
+	taskLabels.RLock()
+	defer taskLabels.RUnlock()
+
+	return taskLabels.m[reflect.ValueOf(f).Pointer()]
+}
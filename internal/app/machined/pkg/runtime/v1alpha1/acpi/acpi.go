@@ -5,41 +5,350 @@
 package acpi
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/mdlayher/genetlink"
 	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
 )
 
 const (
 	// PowerButtonEvent is the ACPI event name associated with the power off
 	// button.
 	PowerButtonEvent = "button/power"
+	// SleepButtonEvent is the ACPI event name associated with the sleep
+	// button.
+	SleepButtonEvent = "button/sleep"
+	// LidEvent is the ACPI event name associated with opening and closing the
+	// lid.
+	LidEvent = "button/lid"
 	// See https://github.com/torvalds/linux/blob/master/drivers/acpi/event.c
 	acpiGenlFamilyName     = "acpi_event"
 	acpiGenlMcastGroupName = "acpi_mc_group"
+	acpiGenlAttrEvent      = 1
+)
+
+// Action represents the action to take in response to an ACPI event.
+type Action int
+
+const (
+	// ActionIgnore indicates that the event should be logged and otherwise
+	// ignored.
+	ActionIgnore Action = iota
+	// ActionShutdown indicates that the event should trigger a shutdown.
+	ActionShutdown
+	// ActionReboot indicates that the event should trigger a reboot.
+	ActionReboot
+	// ActionMaintenance indicates that the event should trigger the
+	// maintenance sequence, which stops the workloads.
+	ActionMaintenance
+	// ActionResume indicates that the event should trigger the resume
+	// sequence, which starts the workloads again.
+	ActionResume
+)
+
+// Actions maps ACPI event names to the action taken when they are received by
+// default. Events that are not present in the map are logged and ignored.
+// Talos does not support suspending, so the sleep button and the lid are
+// ignored.
+var Actions = map[string]Action{
+	PowerButtonEvent: ActionShutdown,
+	SleepButtonEvent: ActionIgnore,
+	LidEvent:         ActionIgnore,
+}
+
+// String returns the string representation of the action.
+func (a Action) String() string {
+	return [...]string{"ignore", "shutdown", "reboot", "maintenance", "resume"}[a]
+}
+
+// Event is an ACPI event, see struct acpi_genl_event in the kernel.
+type Event struct {
+	// DeviceClass is the name of the event, e.g. `button/power`.
+	DeviceClass string
+	// BusID is the ACPI device of the event, e.g. `PNP0C0D:00`.
+	BusID string
+	// Type is the notification of the device, e.g. 0x80 for a status change.
+	Type uint32
+	// Data is specific to the device, e.g. the state of a lid.
+	Data uint32
+}
+
+// String returns the event in the format used by acpid, e.g.
+// `button/power LNXPWRBN:00 00000080 00000001`.
+func (e Event) String() string {
+	return fmt.Sprintf("%s %s %08x %08x", e.DeviceClass, e.BusID, e.Type, e.Data)
+}
+
+// The layout of struct acpi_genl_event.
+const (
+	eventDeviceClassLen = 20
+	eventBusIDLen       = 15
+	eventTypeOffset     = 36
+	eventDataOffset     = 40
+	eventLen            = 44
 )
 
-// StartACPIListener starts listening for ACPI netlink events.
+func decodeEvent(b []byte) (Event, error) {
+	if len(b) < eventLen {
+		return Event{}, fmt.Errorf("ACPI event too short: %d bytes", len(b))
+	}
+
+	return Event{
+		DeviceClass: cstring(b[:eventDeviceClassLen]),
+		BusID:       cstring(b[eventDeviceClassLen : eventDeviceClassLen+eventBusIDLen]),
+		Type:        nlenc.Uint32(b[eventTypeOffset:eventDataOffset]),
+		Data:        nlenc.Uint32(b[eventDataOffset:eventLen]),
+	}, nil
+}
+
+// cstring returns the contents of a NUL padded fixed size string.
+func cstring(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+
+	return string(b)
+}
+
+// Decision is an actionable ACPI event, along with the action decided for it.
+type Decision struct {
+	Event  Event
+	Action Action
+}
+
+// StartACPIListener starts listening for ACPI netlink events. The action taken
+// in response to each event is decided by the decide function. It blocks until
+// an event is decided to require an action other than `ActionIgnore`, and
+// returns that event and action.
+func StartACPIListener(decide func(Event) Action) (Event, Action, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	decisions := make(chan Decision)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- ListenACPI(ctx, decide, 0, decisions)
+	}()
+
+	select {
+	case d := <-decisions:
+		return d.Event, d.Action, nil
+	case err := <-errCh:
+		return Event{}, ActionIgnore, err
+	}
+}
+
+// ListenACPI listens for the ACPI events of the netlink channel, and of the
+// input devices of the ACPI buttons, until the context is canceled, and sends
+// the events decided to require an action other than `ActionIgnore` to the
+// channel. An actionable event received within the debounce window of the
+// last one sent is logged and suppressed, so that a bouncing or repeatedly
+// pressed button acts once, even if it is reported by both the netlink
+// channel and its input device. It fails once none of the event devices is
+// available, or all of them failed.
 //
-//nolint: gocyclo
-func StartACPIListener() (err error) {
+// nolint: gocyclo
+func ListenACPI(ctx context.Context, decide func(Event) Action, debounce time.Duration, decisions chan<- Decision) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		readers     []reader
+		unavailable *multierror.Error
+	)
+
+	if conn, err := dial(); err != nil {
+		unavailable = multierror.Append(unavailable, err)
+	} else {
+		readers = append(readers, &netlinkReader{conn: conn})
+	}
+
+	devices, err := inputDevices()
+	if err != nil {
+		unavailable = multierror.Append(unavailable, err)
+	}
+
+	for _, device := range devices {
+		var f *os.File
+
+		if f, err = os.Open(device.path); err != nil {
+			unavailable = multierror.Append(unavailable, err)
+
+			continue
+		}
+
+		readers = append(readers, &inputReader{device: device, f: f})
+	}
+
+	if len(readers) == 0 {
+		return fmt.Errorf("no ACPI event device available: %w", unavailable.ErrorOrNil())
+	}
+
+	if err = unavailable.ErrorOrNil(); err != nil {
+		log.Printf("some ACPI event devices are not available: %v", err)
+	}
+
+	candidates := make(chan Decision)
+	errCh := make(chan error, len(readers))
+
+	for _, r := range readers {
+		go func(r reader) {
+			errCh <- read(ctx, r, decide, candidates)
+		}(r)
+	}
+
+	var result *multierror.Error
+
+	d := debouncer{window: debounce}
+
+	for remaining := len(readers); remaining > 0; {
+		select {
+		case e := <-errCh:
+			remaining--
+
+			if e != nil {
+				log.Printf("ACPI event device failed: %v", e)
+
+				result = multierror.Append(result, e)
+			}
+		case c := <-candidates:
+			if !d.allow(time.Now()) {
+				log.Printf("suppressing ACPI event %q, repeated within %s", c.Event, debounce)
+
+				continue
+			}
+
+			select {
+			case decisions <- c:
+			case <-ctx.Done():
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return result.ErrorOrNil()
+}
+
+// reader reads the ACPI events of a device.
+type reader interface {
+	// Name returns the name of the device, for the errors.
+	Name() string
+	// Receive blocks until an event is read, and returns the event decided to
+	// require an action, if any.
+	Receive(decide func(Event) Action) (Event, Action, error)
+	// Close unblocks Receive.
+	Close() error
+}
+
+// read sends the actionable events of the reader to the channel, until the
+// context is canceled.
+func read(ctx context.Context, r reader, decide func(Event) Action, candidates chan<- Decision) error {
+	// Receive blocks, so the device is closed to stop reading.
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		}
+
+		// nolint: errcheck
+		r.Close()
+	}()
+
+	for {
+		event, action, err := r.Receive(decide)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			var parseErr *parseError
+			if errors.As(err, &parseErr) {
+				log.Printf("failed to parse the event of %s: %v", r.Name(), parseErr.err)
+
+				continue
+			}
+
+			return fmt.Errorf("error reading from %s: %w", r.Name(), err)
+		}
+
+		if action == ActionIgnore {
+			continue
+		}
+
+		select {
+		case candidates <- Decision{Event: event, Action: action}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// parseError is an event of a device that could not be parsed, which does
+// not stop reading the device.
+type parseError struct {
+	err error
+}
+
+func (e *parseError) Error() string {
+	return e.err.Error()
+}
+
+// netlinkReader reads the events of the ACPI netlink channel.
+type netlinkReader struct {
+	conn *genetlink.Conn
+}
+
+func (*netlinkReader) Name() string {
+	return "ACPI channel"
+}
+
+func (r *netlinkReader) Receive(decide func(Event) Action) (Event, Action, error) {
+	msgs, _, err := r.conn.Receive()
+	if err != nil {
+		return Event{}, ActionIgnore, err
+	}
+
+	event, action, err := parse(msgs, decide)
+	if err != nil {
+		return Event{}, ActionIgnore, &parseError{err: err}
+	}
+
+	return event, action, nil
+}
+
+func (r *netlinkReader) Close() error {
+	return r.conn.Close()
+}
+
+// dial connects to the ACPI event multicast group.
+func dial() (*genetlink.Conn, error) {
 	// Get the acpi_event family.
 	conn, err := genetlink.Dial(nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	f, err := conn.GetFamily(acpiGenlFamilyName)
 	if errors.Is(err, os.ErrNotExist) {
 		// nolint: errcheck
 		conn.Close()
-		return fmt.Errorf(acpiGenlFamilyName+" not available: %w", err)
+		return nil, fmt.Errorf(acpiGenlFamilyName+" not available: %w", err)
 	}
 
 	var id uint32
@@ -53,36 +362,31 @@ func StartACPIListener() (err error) {
 	if err = conn.JoinGroup(id); err != nil {
 		// nolint: errcheck
 		conn.Close()
-		return err
+		return nil, err
 	}
 
-	// nolint: errcheck
-	defer conn.Close()
-
-	for {
-		msgs, _, err := conn.Receive()
-		if err != nil {
-			return fmt.Errorf("error reading from ACPI channel: %w", err)
-		}
+	return conn, nil
+}
 
-		if len(msgs) > 0 {
-			ok, err := parse(msgs, PowerButtonEvent)
-			if err != nil {
-				log.Printf("failed to parse netlink message: %v", err)
+// debouncer suppresses the events received within the window of the last
+// allowed one. The suppressed events do not extend the window.
+type debouncer struct {
+	window time.Duration
+	last   time.Time
+}
 
-				continue
-			}
+// allow reports whether the event received at the time is allowed.
+func (d *debouncer) allow(now time.Time) bool {
+	if d.window > 0 && !d.last.IsZero() && now.Sub(d.last) < d.window {
+		return false
+	}
 
-			if !ok {
-				continue
-			}
+	d.last = now
 
-			return nil
-		}
-	}
+	return true
 }
 
-func parse(msgs []genetlink.Message, event string) (bool, error) {
+func parse(msgs []genetlink.Message, decide func(Event) Action) (Event, Action, error) {
 	var result *multierror.Error
 
 	for _, msg := range msgs {
@@ -93,13 +397,44 @@ func parse(msgs []genetlink.Message, event string) (bool, error) {
 		}
 
 		for ad.Next() {
-			if strings.HasPrefix(ad.String(), event) {
-				return true, nil
+			if ad.Type() != acpiGenlAttrEvent {
+				continue
 			}
 
-			log.Printf("ignoring ACPI event: %q", ad.String())
+			event, err := decodeEvent(ad.Bytes())
+			if err != nil {
+				result = multierror.Append(result, err)
+				continue
+			}
+
+			if action := decide(event); action != ActionIgnore {
+				return event, action, nil
+			}
+
+			log.Printf("ignoring ACPI event: %q", event)
+		}
+	}
+
+	return Event{}, ActionIgnore, result.ErrorOrNil()
+}
+
+// Lookup returns the action mapped to the event. An event name in the map
+// matches the events that start with it, so that the actions may be mapped
+// for a device class (`button/lid`) or a single device (`button/lid PNP0C0D:00`).
+// The longest match wins, and unmatched events are ignored.
+func Lookup(event Event, actions map[string]Action) Action {
+	var (
+		match  string
+		action = ActionIgnore
+	)
+
+	raw := event.String()
+
+	for name, a := range actions {
+		if strings.HasPrefix(raw, name) && len(name) > len(match) {
+			match, action = name, a
 		}
 	}
 
-	return false, result.ErrorOrNil()
+	return action
 }
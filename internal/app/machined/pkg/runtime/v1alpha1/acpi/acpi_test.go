@@ -2,26 +2,30 @@
 // License, v. 2.0. If a copy of the MPL was not distributed with this
 // file, You can obtain one at http://mozilla.org/MPL/2.0/.
 
-//nolint: scopelint
+// nolint: scopelint
 package acpi
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/mdlayher/genetlink"
 )
 
 func Test_parse(t *testing.T) {
 	type args struct {
-		msgs  []genetlink.Message
-		event string
+		msgs    []genetlink.Message
+		actions map[string]Action
 	}
 
 	tests := []struct {
-		name    string
-		args    args
-		want    bool
-		wantErr bool
+		name      string
+		args      args
+		want      Action
+		wantEvent string
+		wantErr   bool
 	}{
 		{
 			name: PowerButtonEvent,
@@ -35,10 +39,11 @@ func Test_parse(t *testing.T) {
 						Data: []byte{48, 0, 1, 0, 98, 117, 116, 116, 111, 110, 47, 112, 111, 119, 101, 114, 0, 0, 0, 0, 0, 0, 0, 0, 76, 78, 88, 80, 87, 82, 66, 78, 58, 48, 48, 0, 0, 0, 0, 0, 128, 0, 0, 0, 1, 0, 0, 0},
 					},
 				},
-				event: PowerButtonEvent,
+				actions: Actions,
 			},
-			want:    true,
-			wantErr: false,
+			want:      ActionShutdown,
+			wantEvent: "button/power LNXPWRBN:00 00000080 00000001",
+			wantErr:   false,
 		},
 		{
 			name: "battery",
@@ -52,16 +57,50 @@ func Test_parse(t *testing.T) {
 						Data: []byte{48, 0, 1, 0, 98, 117, 116, 116, 111, 110, 47, 112, 111, 119, 101, 114, 0, 0, 0, 0, 0, 0, 0, 0, 76, 78, 88, 80, 87, 82, 66, 78, 58, 48, 48, 0, 0, 0, 0, 0, 128, 0, 0, 0, 1, 0, 0, 0},
 					},
 				},
-				event: "battery",
+				actions: map[string]Action{"battery": ActionShutdown},
+			},
+			want:    ActionIgnore,
+			wantErr: false,
+		},
+		{
+			name: "ignored",
+			args: args{
+				msgs: []genetlink.Message{
+					{
+						Header: genetlink.Header{
+							Command: 1,
+							Version: 1,
+						},
+						Data: []byte{48, 0, 1, 0, 98, 117, 116, 116, 111, 110, 47, 112, 111, 119, 101, 114, 0, 0, 0, 0, 0, 0, 0, 0, 76, 78, 88, 80, 87, 82, 66, 78, 58, 48, 48, 0, 0, 0, 0, 0, 128, 0, 0, 0, 1, 0, 0, 0},
+					},
+				},
+				actions: map[string]Action{PowerButtonEvent: ActionIgnore},
 			},
-			want:    false,
+			want:    ActionIgnore,
 			wantErr: false,
 		},
+		{
+			name: "truncated",
+			args: args{
+				msgs: []genetlink.Message{
+					{
+						Header: genetlink.Header{
+							Command: 1,
+							Version: 1,
+						},
+						Data: []byte{16, 0, 1, 0, 98, 117, 116, 116, 111, 110, 47, 112, 111, 119, 101, 114},
+					},
+				},
+				actions: Actions,
+			},
+			want:    ActionIgnore,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parse(tt.args.msgs, tt.args.event)
+			event, got, err := parse(tt.args.msgs, func(e Event) Action { return Lookup(e, tt.args.actions) })
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parse() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -69,6 +108,171 @@ func Test_parse(t *testing.T) {
 			if got != tt.want {
 				t.Errorf("parse() = %v, want %v", got, tt.want)
 			}
+			if tt.wantEvent != "" && event.String() != tt.wantEvent {
+				t.Errorf("parse() event = %q, want %q", event, tt.wantEvent)
+			}
 		})
 	}
 }
+
+func TestLookup(t *testing.T) {
+	lid := Event{DeviceClass: LidEvent, BusID: "PNP0C0D:00", Type: 0x80, Data: 1}
+
+	tests := []struct {
+		name    string
+		event   Event
+		actions map[string]Action
+		want    Action
+	}{
+		{
+			name:    "lid ignored by default",
+			event:   lid,
+			actions: Actions,
+			want:    ActionIgnore,
+		},
+		{
+			name:    "sleep ignored by default",
+			event:   Event{DeviceClass: SleepButtonEvent, BusID: "PNP0C0E:00", Type: 0x80, Data: 1},
+			actions: Actions,
+			want:    ActionIgnore,
+		},
+		{
+			name:    "unknown",
+			event:   Event{DeviceClass: "ac_adapter", BusID: "ACPI0003:00", Type: 0x80, Data: 1},
+			actions: Actions,
+			want:    ActionIgnore,
+		},
+		{
+			name:    "lid mapped to shutdown",
+			event:   lid,
+			actions: map[string]Action{LidEvent: ActionShutdown},
+			want:    ActionShutdown,
+		},
+		{
+			name:    "longest match wins",
+			event:   lid,
+			actions: map[string]Action{LidEvent: ActionShutdown, LidEvent + " PNP0C0D:00": ActionReboot, "button": ActionIgnore},
+			want:    ActionReboot,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lookup(tt.event, tt.actions); got != tt.want {
+				t.Errorf("Lookup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_debouncer(t *testing.T) {
+	start := time.Date(2020, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	d := debouncer{window: 2 * time.Second}
+
+	for _, tt := range []struct {
+		after time.Duration
+		want  bool
+	}{
+		{after: 0, want: true},
+		{after: 100 * time.Millisecond, want: false},
+		// The suppressed events do not extend the window.
+		{after: 1900 * time.Millisecond, want: false},
+		{after: 2 * time.Second, want: true},
+		{after: 3 * time.Second, want: false},
+		{after: 5 * time.Second, want: true},
+	} {
+		if got := d.allow(start.Add(tt.after)); got != tt.want {
+			t.Errorf("allow() after %s = %v, want %v", tt.after, got, tt.want)
+		}
+	}
+
+	d = debouncer{}
+
+	for i := 0; i < 3; i++ {
+		if !d.allow(start) {
+			t.Errorf("allow() without a window = false, want true")
+		}
+	}
+}
+
+// fakeReader returns its events, or errors, in order, and then blocks until
+// it is closed.
+type fakeReader struct {
+	events []interface{}
+	closed chan struct{}
+}
+
+func (*fakeReader) Name() string {
+	return "fake"
+}
+
+func (r *fakeReader) Receive(decide func(Event) Action) (Event, Action, error) {
+	if len(r.events) == 0 {
+		<-r.closed
+
+		return Event{}, ActionIgnore, errors.New("closed")
+	}
+
+	next := r.events[0]
+	r.events = r.events[1:]
+
+	switch next := next.(type) {
+	case error:
+		return Event{}, ActionIgnore, next
+	case Event:
+		return next, decide(next), nil
+	}
+
+	panic(next)
+}
+
+func (r *fakeReader) Close() error {
+	close(r.closed)
+
+	return nil
+}
+
+func Test_read(t *testing.T) {
+	power := Event{DeviceClass: PowerButtonEvent, BusID: "LNXPWRBN:00", Type: 0x80, Data: 1}
+	lid := Event{DeviceClass: LidEvent, BusID: "PNP0C0D:00", Type: 0x80, Data: 1}
+
+	decide := func(e Event) Action { return Lookup(e, Actions) }
+
+	// The ignored events, and those that can not be parsed, are skipped.
+	r := &fakeReader{
+		events: []interface{}{lid, &parseError{err: errors.New("garbage")}, power},
+		closed: make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	candidates := make(chan Decision)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- read(ctx, r, decide, candidates)
+	}()
+
+	if d := <-candidates; d.Event != power || d.Action != ActionShutdown {
+		t.Errorf("read() = %+v, want the power button", d)
+	}
+
+	// The reader is closed once the context is canceled.
+	cancel()
+
+	if err := <-errCh; err != nil {
+		t.Errorf("read() error = %v, want nil once canceled", err)
+	}
+
+	// Other errors stop reading.
+	r = &fakeReader{
+		events: []interface{}{errors.New("device gone")},
+		closed: make(chan struct{}),
+	}
+
+	if err := read(context.Background(), r, decide, candidates); err == nil {
+		t.Errorf("read() error = nil, want the error of the device")
+	}
+}
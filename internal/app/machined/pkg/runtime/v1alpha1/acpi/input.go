@@ -0,0 +1,163 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package acpi
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// inputClassPath is the sysfs class of the input devices, which tests
+	// override.
+	inputClassPath = "/sys/class/input"
+	// inputDevicePath is the directory of the input device nodes, which tests
+	// override.
+	inputDevicePath = "/dev/input"
+)
+
+// The ACPI button driver registers an input device for each button and lid,
+// along with the netlink event, see drivers/acpi/button.c in the kernel.
+const acpiButtonPhys = "/button/input"
+
+// The input event types and codes of the ACPI buttons, see
+// include/uapi/linux/input-event-codes.h in the kernel.
+const (
+	evKey    = 0x01
+	evSw     = 0x05
+	keyPower = 116
+	keySleep = 142
+	swLid    = 0x00
+
+	// buttonEventType is the type of the netlink events of the ACPI buttons,
+	// which the events of their input devices are reported with.
+	buttonEventType = 0x80
+)
+
+// The layout of struct input_event, which starts with a struct timeval.
+var (
+	inputEventTimeLen = int(unsafe.Sizeof(unix.Timeval{}))
+	inputEventLen     = inputEventTimeLen + 8
+)
+
+// inputDevice is the input device of an ACPI button.
+type inputDevice struct {
+	// path is the path of the device node, e.g. `/dev/input/event0`.
+	path string
+	// busID is the ACPI device, e.g. `LNXPWRBN:00`.
+	busID string
+}
+
+// inputDevices returns the input devices of the ACPI buttons, by the name of
+// their device node.
+func inputDevices() ([]inputDevice, error) {
+	matches, err := filepath.Glob(filepath.Join(inputClassPath, "event*"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(matches)
+
+	var devices []inputDevice
+
+	for _, match := range matches {
+		phys, e := ioutil.ReadFile(filepath.Join(match, "device", "phys"))
+		if e != nil || !strings.Contains(string(phys), acpiButtonPhys) {
+			continue
+		}
+
+		// The bus ID is the name of the ACPI device of the input device, or
+		// else the hardware ID it is named after.
+		busID := strings.SplitN(strings.TrimSpace(string(phys)), "/", 2)[0]
+
+		if target, e := os.Readlink(filepath.Join(match, "device", "device")); e == nil {
+			busID = filepath.Base(target)
+		}
+
+		devices = append(devices, inputDevice{
+			path:  filepath.Join(inputDevicePath, filepath.Base(match)),
+			busID: busID,
+		})
+	}
+
+	return devices, nil
+}
+
+// decodeInputEvent returns the ACPI event of a struct input_event of the
+// device. Only the button presses and the lid switches are events, so that
+// the releases, the repeats and the synchronization events are not.
+func decodeInputEvent(b []byte, busID string) (Event, bool, error) {
+	if len(b) < inputEventLen {
+		return Event{}, false, fmt.Errorf("input event too short: %d bytes", len(b))
+	}
+
+	typ := nlenc.Uint16(b[inputEventTimeLen : inputEventTimeLen+2])
+	code := nlenc.Uint16(b[inputEventTimeLen+2 : inputEventTimeLen+4])
+	value := nlenc.Uint32(b[inputEventTimeLen+4 : inputEventLen])
+
+	event := Event{BusID: busID, Type: buttonEventType, Data: value}
+
+	switch {
+	case typ == evKey && code == keyPower && value == 1:
+		event.DeviceClass = PowerButtonEvent
+	case typ == evKey && code == keySleep && value == 1:
+		event.DeviceClass = SleepButtonEvent
+	case typ == evSw && code == swLid:
+		event.DeviceClass = LidEvent
+	default:
+		return Event{}, false, nil
+	}
+
+	return event, true, nil
+}
+
+// inputReader reads the events of the input device of an ACPI button.
+type inputReader struct {
+	device inputDevice
+	f      *os.File
+}
+
+func (r *inputReader) Name() string {
+	return r.device.path
+}
+
+func (r *inputReader) Receive(decide func(Event) Action) (Event, Action, error) {
+	b := make([]byte, inputEventLen)
+
+	for {
+		if _, err := io.ReadFull(r.f, b); err != nil {
+			return Event{}, ActionIgnore, err
+		}
+
+		event, ok, err := decodeInputEvent(b, r.device.busID)
+		if err != nil {
+			return Event{}, ActionIgnore, &parseError{err: err}
+		}
+
+		if !ok {
+			continue
+		}
+
+		if action := decide(event); action != ActionIgnore {
+			return event, action, nil
+		}
+
+		log.Printf("ignoring ACPI event: %q", event)
+	}
+}
+
+func (r *inputReader) Close() error {
+	return r.f.Close()
+}
@@ -0,0 +1,132 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// nolint: scopelint
+package acpi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+func inputEvent(typ, code uint16, value uint32) []byte {
+	b := make([]byte, inputEventLen)
+
+	nlenc.PutUint16(b[inputEventTimeLen:inputEventTimeLen+2], typ)
+	nlenc.PutUint16(b[inputEventTimeLen+2:inputEventTimeLen+4], code)
+	nlenc.PutUint32(b[inputEventTimeLen+4:inputEventLen], value)
+
+	return b
+}
+
+func Test_decodeInputEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       []byte
+		want    Event
+		wantOK  bool
+		wantErr bool
+	}{
+		{
+			name:   "power button pressed",
+			b:      inputEvent(evKey, keyPower, 1),
+			want:   Event{DeviceClass: PowerButtonEvent, BusID: "LNXPWRBN:00", Type: buttonEventType, Data: 1},
+			wantOK: true,
+		},
+		{
+			name: "power button released",
+			b:    inputEvent(evKey, keyPower, 0),
+		},
+		{
+			name:   "sleep button pressed",
+			b:      inputEvent(evKey, keySleep, 1),
+			want:   Event{DeviceClass: SleepButtonEvent, BusID: "LNXPWRBN:00", Type: buttonEventType, Data: 1},
+			wantOK: true,
+		},
+		{
+			name:   "lid opened",
+			b:      inputEvent(evSw, swLid, 0),
+			want:   Event{DeviceClass: LidEvent, BusID: "LNXPWRBN:00", Type: buttonEventType, Data: 0},
+			wantOK: true,
+		},
+		{
+			name: "synchronization",
+			b:    inputEvent(0, 0, 0),
+		},
+		{
+			name:    "truncated",
+			b:       inputEvent(evKey, keyPower, 1)[:inputEventLen-1],
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := decodeInputEvent(tt.b, "LNXPWRBN:00")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeInputEvent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if ok != tt.wantOK {
+				t.Errorf("decodeInputEvent() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("decodeInputEvent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_inputDevices(t *testing.T) {
+	dir, err := ioutil.TempDir("", "talos")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir) //nolint: errcheck
+
+	defer func(class, dev string) {
+		inputClassPath, inputDevicePath = class, dev
+	}(inputClassPath, inputDevicePath)
+
+	inputClassPath, inputDevicePath = dir, "/dev/input"
+
+	for event, phys := range map[string]string{
+		"event0": "LNXPWRBN/button/input0\n",
+		"event1": "isa0060/serio0/input0\n",
+		"event2": "PNP0C0D/button/input0\n",
+	} {
+		if err = os.MkdirAll(filepath.Join(dir, event, "device"), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = ioutil.WriteFile(filepath.Join(dir, event, "device", "phys"), []byte(phys), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The bus ID is the name of the ACPI device, when it is linked.
+	if err = os.Symlink("../../../LNXSYSTM:00/LNXPWRBN:00", filepath.Join(dir, "event0", "device", "device")); err != nil {
+		t.Fatal(err)
+	}
+
+	devices, err := inputDevices()
+	if err != nil {
+		t.Fatalf("inputDevices() error = %v", err)
+	}
+
+	want := []inputDevice{
+		{path: "/dev/input/event0", busID: "LNXPWRBN:00"},
+		{path: "/dev/input/event2", busID: "PNP0C0D"},
+	}
+
+	if !reflect.DeepEqual(devices, want) {
+		t.Errorf("inputDevices() = %+v, want %+v", devices, want)
+	}
+}
@@ -0,0 +1,40 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package syslinux
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"text/template"
+)
+
+func Test_parseLabel(t *testing.T) {
+	want := &Label{
+		Root:   BootB,
+		Kernel: "/boot-b/vmlinuz",
+		Initrd: "/boot-b/initramfs.xz",
+		Append: "page_poison=1 slab_nomerge talos.platform=metal",
+	}
+
+	var wr bytes.Buffer
+
+	if err := template.Must(template.New("syslinux").Parse(syslinuxLabelTpl)).Execute(&wr, want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseLabel(wr.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLabel() = %+v, want %+v", got, want)
+	}
+
+	if _, err = parseLabel([]byte("LABEL boot-a\n  APPEND talos.platform=metal\n")); err == nil {
+		t.Error("parseLabel() of a label without a kernel error = nil, want error")
+	}
+}
@@ -0,0 +1,89 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// nolint: scopelint
+package syslinux
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_repair(t *testing.T) {
+	const both = "DEFAULT boot-b\nPROMPT 1\nTIMEOUT 50\nINCLUDE /boot-a/include.cfg\nINCLUDE /boot-b/include.cfg"
+
+	tests := []struct {
+		name      string
+		cfg       string
+		installed map[string]bool
+		wantLabel string
+		wantCfg   string
+		errIs     error
+	}{
+		{
+			name:      "intact",
+			cfg:       both,
+			installed: map[string]bool{BootA: true, BootB: true},
+			wantLabel: BootB,
+		},
+		{
+			name:      "missing",
+			installed: map[string]bool{BootA: true},
+			wantLabel: BootA,
+			wantCfg:   "DEFAULT boot-a\nPROMPT 1\nTIMEOUT 50\nINCLUDE /boot-a/include.cfg",
+		},
+		{
+			name:      "corrupted",
+			cfg:       "\x00\x00\x00",
+			installed: map[string]bool{BootA: true, BootB: true},
+			wantLabel: BootA,
+			wantCfg:   "DEFAULT boot-a\nPROMPT 1\nTIMEOUT 50\nINCLUDE /boot-a/include.cfg\nINCLUDE /boot-b/include.cfg",
+		},
+		{
+			name:      "default not installed",
+			cfg:       both,
+			installed: map[string]bool{BootA: true},
+			wantLabel: BootA,
+			wantCfg:   "DEFAULT boot-a\nPROMPT 1\nTIMEOUT 50\nINCLUDE /boot-a/include.cfg",
+		},
+		{
+			name:      "default not included",
+			cfg:       "DEFAULT boot-b\nPROMPT 1\nTIMEOUT 50\nINCLUDE /boot-a/include.cfg",
+			installed: map[string]bool{BootA: true, BootB: true},
+			wantLabel: BootB,
+			wantCfg:   "DEFAULT boot-b\nPROMPT 1\nTIMEOUT 50\nINCLUDE /boot-a/include.cfg\nINCLUDE /boot-b/include.cfg",
+		},
+		{
+			name:  "no installation",
+			cfg:   both,
+			errIs: ErrNoInstallation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, out, err := repair([]byte(tt.cfg), tt.installed)
+
+			if tt.errIs != nil {
+				if !errors.Is(err, tt.errIs) {
+					t.Fatalf("repair() error = %v, want %v", err, tt.errIs)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("repair() error = %v", err)
+			}
+
+			if label != tt.wantLabel {
+				t.Errorf("repair() label = %q, want %q", label, tt.wantLabel)
+			}
+
+			if string(out) != tt.wantCfg {
+				t.Errorf("repair() cfg = %q, want %q", out, tt.wantCfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// nolint: scopelint
+package syslinux
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_rollback(t *testing.T) {
+	const (
+		both = "DEFAULT boot-b\nPROMPT 1\nTIMEOUT 50\nINCLUDE /boot-a/include.cfg\nINCLUDE /boot-b/include.cfg"
+		one  = "DEFAULT boot-a\nPROMPT 1\nTIMEOUT 50\nINCLUDE /boot-a/include.cfg"
+	)
+
+	tests := []struct {
+		name      string
+		cfg       string
+		wantLabel string
+		wantCfg   string
+		wantErr   bool
+		errIs     error
+	}{
+		{
+			name:      "previous installation",
+			cfg:       both,
+			wantLabel: BootA,
+			wantCfg:   "DEFAULT boot-a\nPROMPT 1\nTIMEOUT 50\nINCLUDE /boot-a/include.cfg\nINCLUDE /boot-b/include.cfg",
+		},
+		{
+			name:    "single installation",
+			cfg:     one,
+			wantErr: true,
+			errIs:   ErrNoPreviousLabel,
+		},
+		{
+			name:    "unknown label",
+			cfg:     "DEFAULT boot-c\nINCLUDE /boot-a/include.cfg",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, out, err := rollback([]byte(tt.cfg))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("rollback() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf("rollback() error = %v, want %v", err, tt.errIs)
+			}
+
+			if label != tt.wantLabel {
+				t.Errorf("rollback() label = %q, want %q", label, tt.wantLabel)
+			}
+
+			if string(out) != tt.wantCfg {
+				t.Errorf("rollback() cfg = %q, want %q", out, tt.wantCfg)
+			}
+		})
+	}
+}
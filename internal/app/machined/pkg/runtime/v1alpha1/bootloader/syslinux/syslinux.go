@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"text/template"
 
 	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
@@ -219,6 +220,221 @@ func Revert() (err error) {
 	return nil
 }
 
+// ErrNoPreviousLabel indicates that there is no previous installation to roll
+// back to.
+var ErrNoPreviousLabel = errors.New("no previous boot entry to roll back to")
+
+// Rollback sets the default syslinux label to the previous installation, and
+// returns it. The syslinux config is left untouched if there is no previous
+// installation.
+func Rollback() (label string, err error) {
+	var b []byte
+
+	if b, err = ioutil.ReadFile(SyslinuxConfig); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", ErrNoPreviousLabel
+		}
+
+		return "", err
+	}
+
+	if label, b, err = rollback(b); err != nil {
+		return "", err
+	}
+
+	if _, err = os.Stat(filepath.Join(constants.BootMountPoint, label, "include.cfg")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", ErrNoPreviousLabel
+		}
+
+		return "", err
+	}
+
+	log.Printf("rolling back default boot to %q", label)
+
+	if err = ioutil.WriteFile(SyslinuxConfig, b, 0600); err != nil {
+		return "", err
+	}
+
+	return label, nil
+}
+
+// rollback returns the previous label of the syslinux config, and the config
+// with the previous label as the default.
+func rollback(b []byte) (label string, out []byte, err error) {
+	re := regexp.MustCompile(`^DEFAULT\s(.*)`)
+	matches := re.FindSubmatch(b)
+
+	if len(matches) != 2 {
+		return "", nil, fmt.Errorf("expected 2 matches, got %d", len(matches))
+	}
+
+	switch current := string(matches[1]); current {
+	case BootA:
+		label = BootB
+	case BootB:
+		label = BootA
+	default:
+		return "", nil, fmt.Errorf("unknown syslinux label: %q", current)
+	}
+
+	if !bytes.Contains(b, []byte(fmt.Sprintf("INCLUDE /%s/include.cfg", label))) {
+		return "", nil, ErrNoPreviousLabel
+	}
+
+	return label, re.ReplaceAll(b, []byte(fmt.Sprintf("DEFAULT %s", label))), nil
+}
+
+// ErrNoInstallation indicates that the boot partition has no installation to
+// boot.
+var ErrNoInstallation = errors.New("no installation on the boot partition")
+
+// Repair regenerates the syslinux config from the installations on the boot
+// partition if it is missing, unparseable, or if its default label has no
+// installation. The default label is kept if it has one, or else the other
+// installation becomes the default. It returns the default label, and whether
+// the config was regenerated.
+func Repair() (label string, repaired bool, err error) {
+	b, err := ioutil.ReadFile(SyslinuxConfig)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", false, err
+	}
+
+	installed := map[string]bool{}
+
+	for _, l := range []string{BootA, BootB} {
+		if _, err = os.Stat(filepath.Join(constants.BootMountPoint, l, "include.cfg")); err == nil {
+			installed[l] = true
+		}
+	}
+
+	var out []byte
+
+	if label, out, err = repair(b, installed); err != nil {
+		return "", false, err
+	}
+
+	if out == nil {
+		return label, false, nil
+	}
+
+	log.Printf("repairing %s with the default boot %q", SyslinuxConfig, label)
+
+	if err = os.MkdirAll(filepath.Dir(SyslinuxConfig), os.ModeDir); err != nil {
+		return "", false, err
+	}
+
+	if err = ioutil.WriteFile(SyslinuxConfig, out, 0600); err != nil {
+		return "", false, err
+	}
+
+	return label, true, nil
+}
+
+// repair returns the default label of the syslinux config, and the
+// regenerated config, or nil if the config boots one of the installations.
+func repair(b []byte, installed map[string]bool) (label string, out []byte, err error) {
+	var current string
+
+	if matches := regexp.MustCompile(`^DEFAULT\s(.*)`).FindSubmatch(b); len(matches) == 2 {
+		current = string(matches[1])
+	}
+
+	if installed[current] && bytes.Contains(b, []byte(fmt.Sprintf("INCLUDE /%s/include.cfg", current))) {
+		return current, nil, nil
+	}
+
+	syslinuxcfg := &Cfg{}
+
+	for _, l := range []string{BootA, BootB} {
+		if installed[l] {
+			syslinuxcfg.Labels = append(syslinuxcfg.Labels, &Label{Root: l})
+		}
+	}
+
+	if len(syslinuxcfg.Labels) == 0 {
+		return "", nil, ErrNoInstallation
+	}
+
+	syslinuxcfg.Default = syslinuxcfg.Labels[0].Root
+
+	if installed[current] {
+		syslinuxcfg.Default = current
+	}
+
+	var wr bytes.Buffer
+
+	if err = template.Must(template.New("syslinux").Parse(syslinuxCfgTpl)).Execute(&wr, syslinuxcfg); err != nil {
+		return "", nil, err
+	}
+
+	return syslinuxcfg.Default, wr.Bytes(), nil
+}
+
+// Default returns the default label of the syslinux config, with the paths
+// of its kernel and initrd on the mounted boot partition, e.g. to kexec into
+// the installation the machine would boot next.
+func Default() (label *Label, err error) {
+	var b []byte
+
+	if b, err = ioutil.ReadFile(SyslinuxConfig); err != nil {
+		return nil, err
+	}
+
+	matches := regexp.MustCompile(`^DEFAULT\s(.*)`).FindSubmatch(b)
+	if len(matches) != 2 {
+		return nil, fmt.Errorf("expected 2 matches, got %d", len(matches))
+	}
+
+	root := string(matches[1])
+
+	if b, err = ioutil.ReadFile(filepath.Join(constants.BootMountPoint, root, "include.cfg")); err != nil {
+		return nil, err
+	}
+
+	if label, err = parseLabel(b); err != nil {
+		return nil, err
+	}
+
+	if label.Root != root {
+		return nil, fmt.Errorf("expected label %q, got %q", root, label.Root)
+	}
+
+	label.Kernel = filepath.Join(constants.BootMountPoint, label.Kernel)
+	label.Initrd = filepath.Join(constants.BootMountPoint, label.Initrd)
+
+	return label, nil
+}
+
+// parseLabel parses a label written by `writeCfg`.
+func parseLabel(b []byte) (label *Label, err error) {
+	label = &Label{}
+
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "LABEL":
+			label.Root = fields[1]
+		case "KERNEL":
+			label.Kernel = fields[1]
+		case "INITRD":
+			label.Initrd = fields[1]
+		case "APPEND":
+			label.Append = fields[1]
+		}
+	}
+
+	if label.Root == "" || label.Kernel == "" || label.Initrd == "" {
+		return nil, fmt.Errorf("incomplete syslinux label: %q", b)
+	}
+
+	return label, nil
+}
+
 func writeCfg(base, path string, syslinuxcfg *Cfg) (err error) {
 	b := []byte{}
 	wr := bytes.NewBuffer(b)
@@ -0,0 +1,54 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// writeClockFile saves the specified time to the file at path.
+func writeClockFile(path string, t time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+
+	if err := ioutil.WriteFile(tmp, []byte(t.UTC().Format(time.RFC3339Nano)+"\n"), 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// readClockFile returns the time saved to the file at path.
+func readClockFile(path string) (time.Time, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(b)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("corrupt clock file %q: %w", path, err)
+	}
+
+	return t, nil
+}
+
+// restoredClock returns the later of the current and the saved time, and
+// whether the clock needs to be set to it.
+func restoredClock(now, saved time.Time) (time.Time, bool) {
+	if saved.After(now) {
+		return saved, true
+	}
+
+	return now, false
+}
@@ -0,0 +1,65 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_clockFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir) //nolint: errcheck
+
+	path := filepath.Join(dir, "system", "clock")
+
+	if _, err = readClockFile(path); !os.IsNotExist(err) {
+		t.Fatalf("readClockFile() error = %v, want not exist", err)
+	}
+
+	saved := time.Date(2020, time.April, 1, 12, 0, 0, 123, time.UTC)
+
+	if err = writeClockFile(path, saved); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readClockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(saved) {
+		t.Errorf("readClockFile() = %s, want %s", got, saved)
+	}
+
+	if err = ioutil.WriteFile(path, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = readClockFile(path); err == nil {
+		t.Error("readClockFile() with a corrupt file error = nil, want error")
+	}
+}
+
+func Test_restoredClock(t *testing.T) {
+	saved := time.Date(2020, time.April, 1, 12, 0, 0, 0, time.UTC)
+
+	if got, restore := restoredClock(saved.Add(-time.Hour), saved); !restore || !got.Equal(saved) {
+		t.Errorf("restoredClock() with an older system time = (%s, %v), want (%s, true)", got, restore, saved)
+	}
+
+	now := saved.Add(time.Hour)
+
+	if got, restore := restoredClock(now, saved); restore || !got.Equal(now) {
+		t.Errorf("restoredClock() with a newer system time = (%s, %v), want (%s, false)", got, restore, now)
+	}
+}
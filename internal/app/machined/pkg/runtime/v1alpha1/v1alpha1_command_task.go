@@ -0,0 +1,179 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/armon/circbuf"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/pkg/cmd"
+	"github.com/talos-systems/talos/pkg/constants"
+	"github.com/talos-systems/talos/pkg/proc/reaper"
+)
+
+// CommandOptions describes the environment a command task runs in.
+type CommandOptions struct {
+	// Path is the value of the PATH environment variable.
+	Path string
+	// Env is the list of additional environment variables in the format
+	// <key>=<value>. The environment of machined is never inherited.
+	Env []string
+	// Dir is the working directory of the command.
+	Dir string
+	// Chroot is the root directory of the command.
+	Chroot string
+	// Cloneflags is the set of CLONE_NEW* flags for the namespaces the command
+	// is isolated in.
+	Cloneflags uintptr
+}
+
+// CommandOption is the functional option func.
+type CommandOption func(*CommandOptions)
+
+// DefaultCommandOptions describes the default options to a command task.
+func DefaultCommandOptions() *CommandOptions {
+	return &CommandOptions{
+		Path: constants.PATH,
+		Dir:  "/",
+	}
+}
+
+// WithCommandPath sets the PATH of the command.
+func WithCommandPath(o string) CommandOption {
+	return func(opts *CommandOptions) {
+		opts.Path = o
+	}
+}
+
+// WithCommandEnv sets the environment variables of the command.
+func WithCommandEnv(o ...string) CommandOption {
+	return func(opts *CommandOptions) {
+		opts.Env = o
+	}
+}
+
+// WithCommandDir sets the working directory of the command.
+func WithCommandDir(o string) CommandOption {
+	return func(opts *CommandOptions) {
+		opts.Dir = o
+	}
+}
+
+// WithCommandChroot runs the command in the specified root directory.
+func WithCommandChroot(o string) CommandOption {
+	return func(opts *CommandOptions) {
+		opts.Chroot = o
+	}
+}
+
+// WithCommandNamespaces runs the command in new namespaces of the specified
+// types (e.g. syscall.CLONE_NEWNS|syscall.CLONE_NEWUTS).
+func WithCommandNamespaces(o uintptr) CommandOption {
+	return func(opts *CommandOptions) {
+		opts.Cloneflags = o
+	}
+}
+
+// CommandTask returns a task that runs the specified command in an isolated
+// environment. A name without a slash is looked up in the PATH of the
+// options, inside the chroot if one is set, rather than in the PATH of
+// machined.
+func CommandTask(name string, args []string, setters ...CommandOption) runtime.TaskSetupFunc {
+	opts := DefaultCommandOptions()
+
+	for _, setter := range setters {
+		setter(opts)
+	}
+
+	return func(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+			out, err := runCommand(ctx, name, args, opts)
+			if out != "" {
+				logger.Printf("%s: %s", name, out)
+			}
+
+			return err
+		}
+	}
+}
+
+func runCommand(ctx context.Context, name string, args []string, opts *CommandOptions) (string, error) {
+	path, err := lookCommandPath(name, opts)
+	if err != nil {
+		return "", err
+	}
+
+	c := exec.CommandContext(ctx, path, args...)
+
+	// Never inherit the environment of machined.
+	c.Env = append([]string{"PATH=" + opts.Path}, opts.Env...)
+	c.Dir = opts.Dir
+	c.SysProcAttr = &syscall.SysProcAttr{
+		Chroot:     opts.Chroot,
+		Cloneflags: opts.Cloneflags,
+	}
+
+	stdout, err := circbuf.NewBuffer(cmd.MaxStderrLen)
+	if err != nil {
+		return "", err
+	}
+
+	stderr, err := circbuf.NewBuffer(cmd.MaxStderrLen)
+	if err != nil {
+		return "", err
+	}
+
+	c.Stdout = stdout
+	c.Stderr = stderr
+
+	notifyCh := make(chan reaper.ProcessInfo, 8)
+	usingReaper := reaper.Notify(notifyCh)
+
+	if usingReaper {
+		defer reaper.Stop(notifyCh)
+	}
+
+	if err = c.Start(); err != nil {
+		return stdout.String(), fmt.Errorf("%s: %s", err, stderr.String())
+	}
+
+	if err = reaper.WaitWrapper(usingReaper, notifyCh, c); err != nil {
+		return stdout.String(), fmt.Errorf("%s: %s", err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
+// lookCommandPath returns the path of the command, looked up in the PATH of
+// the options, inside the chroot if one is set. A name with a slash is used
+// as is.
+func lookCommandPath(name string, opts *CommandOptions) (string, error) {
+	if strings.Contains(name, "/") {
+		return name, nil
+	}
+
+	for _, dir := range filepath.SplitList(opts.Path) {
+		if dir == "" {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+
+		if fi, err := os.Stat(filepath.Join(opts.Chroot, path)); err == nil && fi.Mode().IsRegular() && fi.Mode()&0111 != 0 {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: %w in PATH %q", name, exec.ErrNotFound, opts.Path)
+}
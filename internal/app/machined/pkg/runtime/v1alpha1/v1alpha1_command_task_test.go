@@ -0,0 +1,145 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// nolint: scopelint
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func Test_runCommand(t *testing.T) {
+	env, err := exec.LookPath("env")
+	if err != nil {
+		t.Skip("env is not available")
+	}
+
+	if err = os.Setenv("TALOS_TEST_INHERITED", "true"); err != nil {
+		t.Fatal(err)
+	}
+
+	// nolint: errcheck
+	defer os.Unsetenv("TALOS_TEST_INHERITED")
+
+	tests := []struct {
+		name    string
+		setters []CommandOption
+		want    []string
+	}{
+		{
+			name: "default",
+			want: []string{"PATH=" + DefaultCommandOptions().Path},
+		},
+		{
+			name:    "path",
+			setters: []CommandOption{WithCommandPath("/bin")},
+			want:    []string{"PATH=/bin"},
+		},
+		{
+			name:    "env",
+			setters: []CommandOption{WithCommandEnv("FOO=bar", "BAZ=qux")},
+			want:    []string{"BAZ=qux", "FOO=bar", "PATH=" + DefaultCommandOptions().Path},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultCommandOptions()
+
+			for _, setter := range tt.setters {
+				setter(opts)
+			}
+
+			out, err := runCommand(context.Background(), env, nil, opts)
+			if err != nil {
+				t.Fatalf("runCommand() error = %v", err)
+			}
+
+			got := strings.Fields(out)
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runCommand_Dir(t *testing.T) {
+	pwd, err := exec.LookPath("pwd")
+	if err != nil {
+		t.Skip("pwd is not available")
+	}
+
+	opts := DefaultCommandOptions()
+
+	WithCommandDir(os.TempDir())(opts)
+
+	out, err := runCommand(context.Background(), pwd, nil, opts)
+	if err != nil {
+		t.Fatalf("runCommand() error = %v", err)
+	}
+
+	if got := strings.TrimSpace(out); got != os.TempDir() {
+		t.Errorf("runCommand() = %v, want %v", got, os.TempDir())
+	}
+}
+
+func Test_lookCommandPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nolint: errcheck
+	defer os.RemoveAll(dir)
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "tool"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "data"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    *CommandOptions
+		want    string
+		wantErr bool
+	}{
+		{name: "tool", opts: &CommandOptions{Path: dir}, want: filepath.Join(dir, "tool")},
+		{name: "tool", opts: &CommandOptions{Path: "/", Chroot: dir}, want: "/tool"},
+		{name: "/bin/tool", opts: &CommandOptions{Path: dir}, want: "/bin/tool"},
+		{name: "data", opts: &CommandOptions{Path: dir}, wantErr: true},
+		// The PATH of machined is never searched.
+		{name: "env", opts: &CommandOptions{Path: dir}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lookCommandPath(tt.name, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("lookCommandPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && !errors.Is(err, exec.ErrNotFound) {
+				t.Errorf("lookCommandPath() error = %v, want %v", err, exec.ErrNotFound)
+			}
+
+			if got != tt.want {
+				t.Errorf("lookCommandPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,198 +6,1479 @@ package v1alpha1
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
-	"golang.org/x/sync/errgroup"
+	"github.com/talos-systems/talos/api/machine"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/v1alpha1/acpi"
+	"github.com/talos-systems/talos/internal/pkg/kmsg"
+	"github.com/talos-systems/talos/pkg/config"
+	"github.com/talos-systems/talos/pkg/constants"
+)
+
+// Controller represents the controller responsible for managing the execution
+// of sequences.
+type Controller struct {
+	r runtime.Runtime
+	s runtime.Sequencer
+
+	semaphore int32
+
+	// waiters are the sequences waiting for the lock in RunWait, in order of
+	// arrival. Unlock hands the lock to the first one, unless a forced
+	// sequence is preempting.
+	waitersMu  sync.Mutex
+	waiters    []chan struct{}
+	preempting int32
+
+	// booted is set once the boot sequence starts, after which the recover
+	// sequence is refused.
+	booted int32
+
+	// cancel cancels the context of the running sequence.
+	cancelMu sync.Mutex
+	cancel   context.CancelFunc
+	running  runtime.Sequence
+	// status is the status of the running sequence, or nil if there is none.
+	status *runtime.SequenceStatus
+	// generation identifies the sequence that registered cancel, so that a
+	// sequence only clears its own registration.
+	generation uint64
+
+	deferred deferredTasks
+
+	inhibitors inhibitors
+
+	stepper stepper
+
+	// preflightEnabled runs the pre-flight checks of the sequences before
+	// them, set with SetPreflight.
+	preflightEnabled bool
+
+	// replaying is set on the controllers replaying traces, which discard the
+	// logs.
+	replaying bool
+
+	// logger is the logger of the controller, set with SetLogger.
+	logger        runtime.Logger
+	defaultLogger kmsgLogger
+
+	traceMu    sync.Mutex
+	lastTrace  *Trace
+	lastResult *runtime.SequenceResult
+	// history holds the results of the most recent sequences, the oldest
+	// first, up to historySize.
+	history     []runtime.SequenceResult
+	historySize int
+
+	outcomeSink runtime.OutcomeSink
+	outcomes    outcomeQueue
+
+	// maxParallel is the limit of concurrently running tasks of a phase for
+	// the sequences without a limit in the config.
+	maxParallel int
+
+	events eventBus
+
+	metrics metrics
+
+	// sources are the event sources registered in addition to the built-in
+	// ones.
+	sources []runtime.EventSource
+
+	// sigtermGrace is the delay between a SIGTERM and the shutdown it
+	// requests, set with SetSIGTERMGracePeriod.
+	sigtermGrace time.Duration
+}
+
+// NewController intializes and returns a controller. If the config is not
+// passed as bytes, the initialize sequence loads it.
+func NewController(b []byte) (*Controller, error) {
+	var (
+		cfg runtime.Configurator
+		err error
+	)
+
+	if b != nil {
+		cfg, err = config.NewFromBytes(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+	}
+
+	s, err := NewState()
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for USB storage in the case that the machine is installed to a disk
+	// attached over USB, and it is not found yet. If we don't wait, there is the
+	// chance that we will fail to detect the installation. The config on that
+	// disk is not loaded yet, the LoadConfig task waits for the install disk as
+	// set by the config once it is.
+	if s.machine.disk == nil {
+		if err = waitForUSBDelay(context.Background(), cfg); err != nil {
+			return nil, err
+		}
+
+		if s, err = NewState(); err != nil {
+			return nil, err
+		}
+	}
+
+	ctlr := &Controller{
+		r: NewRuntime(cfg, s),
+		s: NewSequencer(),
+	}
+
+	return ctlr, nil
+}
+
+// NewControllerWithRuntime returns a controller running the sequences of the
+// sequencer against the runtime. Unlike NewController, it does not probe the
+// state of the machine nor load a config, which lets the sequences run with
+// a fake runtime, and fabricated phases.
+func NewControllerWithRuntime(r runtime.Runtime, s runtime.Sequencer) *Controller {
+	return &Controller{
+		r: r,
+		s: s,
+	}
+}
+
+// Run executes all phases known to the controller in serial. `Controller`
+// aborts immediately if any phase fails, or once the timeout of the sequence
+// set by the config elapses. A sequence requested while another runs is
+// rejected, queued, or preempts it, as set by `runtime.Conflict`. The
+// pre-flight checks of the sequence run first, if set with SetPreflight.
+func (c *Controller) Run(seq runtime.Sequence, data interface{}) error {
+	return c.RunContext(context.Background(), seq, data)
+}
+
+// RunContext executes the sequence like Run, on behalf of the caller that the
+// context belongs to. A task of the running sequence always gets ErrLocked
+// (see `runtime.RunSubsequence`), as it would wait for, or preempt, the
+// sequence it is part of.
+func (c *Controller) RunContext(ctx context.Context, seq runtime.Sequence, data interface{}) error {
+	// We must ensure that the runtime is configured since all sequences depend
+	// on the runtime.
+	if c.r == nil {
+		return runtime.ErrUndefinedRuntime
+	}
+
+	if err := c.checkConfigured(seq); err != nil {
+		return err
+	}
+
+	if calledFromTask(ctx) {
+		return errCalledFromTask(seq)
+	}
+
+	// Allow only one sequence to run at a time, the policy deciding what
+	// happens to a sequence requested while another runs.
+	if c.TryLock() {
+		return c.runConflicting(seq, data)
+	}
+
+	defer c.Unlock()
+
+	return c.runLocked(seq, data)
+}
+
+// maxQueueWait is the time a sequence queued behind the running sequence waits
+// for it to complete.
+const maxQueueWait = 10 * time.Minute
+
+// runConflicting runs the sequence requested while another sequence holds the
+// lock, as set by `runtime.Conflict`. The running sequence is the one that
+// registered its context last, which can briefly be the previous one while a
+// sequence starts.
+func (c *Controller) runConflicting(seq runtime.Sequence, data interface{}) error {
+	c.cancelMu.Lock()
+	running, noReturn := c.running, c.status != nil && c.status.NoReturn
+	c.cancelMu.Unlock()
+
+	action := runtime.Conflict(seq, running)
+	if action == runtime.ConflictPreempt && noReturn {
+		action = runtime.ConflictQueue
+	}
+
+	switch action {
+	case runtime.ConflictQueue:
+		c.log().Info("sequence queued", "sequence", seq, "running", running)
+
+		return c.RunWaitContext(context.Background(), seq, data, maxQueueWait)
+	case runtime.ConflictPreempt:
+		return c.runPreempting(seq, data)
+	case runtime.ConflictReject:
+	}
+
+	return runtime.ErrLocked
+}
+
+// RunWait executes the sequence like Run, but if another sequence is running,
+// it waits up to `maxWait` for the lock instead of failing. Waiting sequences
+// acquire the lock in the order they called RunWait. `ErrLocked` is returned
+// if the wait times out.
+func (c *Controller) RunWait(seq runtime.Sequence, data interface{}, maxWait time.Duration) error {
+	return c.RunWaitContext(context.Background(), seq, data, maxWait)
+}
+
+// RunWaitContext executes the sequence like RunWait, and rejects the calls of
+// the tasks of the running sequence as RunContext does.
+func (c *Controller) RunWaitContext(ctx context.Context, seq runtime.Sequence, data interface{}, maxWait time.Duration) error {
+	if c.r == nil {
+		return runtime.ErrUndefinedRuntime
+	}
+
+	if err := c.checkConfigured(seq); err != nil {
+		return err
+	}
+
+	if calledFromTask(ctx) {
+		return errCalledFromTask(seq)
+	}
+
+	if !c.lockWait(maxWait) {
+		return runtime.ErrLocked
+	}
+
+	defer c.Unlock()
+
+	return c.runLocked(seq, data)
+}
+
+// lockWait acquires the lock, waiting up to `maxWait` behind the sequences
+// already waiting. It reports whether the lock was acquired.
+func (c *Controller) lockWait(maxWait time.Duration) bool {
+	c.waitersMu.Lock()
+
+	if len(c.waiters) == 0 && !c.TryLock() {
+		c.waitersMu.Unlock()
+
+		return true
+	}
+
+	ch := make(chan struct{})
+	c.waiters = append(c.waiters, ch)
+
+	c.waitersMu.Unlock()
+
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+
+	select {
+	case <-ch:
+		return true
+	case <-timer.C:
+	}
+
+	c.waitersMu.Lock()
+	defer c.waitersMu.Unlock()
+
+	for i, waiter := range c.waiters {
+		if waiter == ch {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+
+			return false
+		}
+	}
+
+	// The lock was handed over as the wait timed out.
+	return true
+}
+
+// RunForced executes the shutdown or reboot sequence even if another sequence
+// is running. The running sequence is canceled, and given
+// `preemptionTimeout` to return. If it does not, the sequence is not run, and
+// `ErrLocked` is returned, as its tasks would run alongside the tasks of the
+// preempted sequence still running.
+func (c *Controller) RunForced(seq runtime.Sequence, data interface{}) error {
+	return c.RunForcedContext(context.Background(), seq, data)
+}
+
+// RunForcedContext executes the sequence like RunForced, and rejects the calls
+// of the tasks of the running sequence as RunContext does.
+func (c *Controller) RunForcedContext(ctx context.Context, seq runtime.Sequence, data interface{}) error {
+	if c.r == nil {
+		return runtime.ErrUndefinedRuntime
+	}
+
+	switch seq {
+	case runtime.SequenceShutdown, runtime.SequenceReboot:
+	default:
+		return fmt.Errorf("%s sequence: %w", seq.String(), runtime.ErrNotForceable)
+	}
+
+	if calledFromTask(ctx) {
+		return errCalledFromTask(seq)
+	}
+
+	if !c.TryLock() {
+		defer c.Unlock()
+
+		return c.runLocked(seq, data)
+	}
+
+	return c.runPreempting(seq, data)
+}
+
+// runPreempting runs the sequence in place of the sequence holding the lock.
+func (c *Controller) runPreempting(seq runtime.Sequence, data interface{}) error {
+	if !c.preempt(seq) {
+		c.log().Warn("preempted sequence did not stop in time", "sequence", seq, "timeout", preemptionTimeout)
+
+		return fmt.Errorf("%s sequence: preempted sequence did not stop in %s: %w", seq, preemptionTimeout, runtime.ErrLocked)
+	}
+
+	defer c.Unlock()
+
+	return c.runLocked(seq, data)
+}
+
+// preemptionTimeout is the time a canceled sequence is given to return before
+// a forced sequence gives up.
+const preemptionTimeout = 30 * time.Second
+
+// preempt cancels the running sequence, and waits for it to release the lock.
+// It reports whether the lock was acquired.
+func (c *Controller) preempt(seq runtime.Sequence) bool {
+	// The lock must not be handed to a waiting sequence.
+	atomic.StoreInt32(&c.preempting, 1)
+	defer atomic.StoreInt32(&c.preempting, 0)
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	timeout := time.After(preemptionTimeout)
+
+	logged := false
+
+	for {
+		// The running sequence might not have registered its context yet, so
+		// it is canceled on every attempt.
+		c.cancelMu.Lock()
+
+		if c.cancel != nil {
+			if !logged {
+				c.log().Warn("sequence preempted by forced sequence", "sequence", c.running, "forced", seq)
+
+				logged = true
+			}
+
+			c.cancel()
+		}
+
+		c.cancelMu.Unlock()
+
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return false
+		}
+
+		if !c.TryLock() {
+			return true
+		}
+	}
+}
+
+// runLocked executes the sequence, and must only be called with the lock held.
+func (c *Controller) runLocked(seq runtime.Sequence, data interface{}) error {
+	if err := c.checkConfigured(seq); err != nil {
+		return err
+	}
+
+	switch seq {
+	case runtime.SequenceBoot:
+		atomic.StoreInt32(&c.booted, 1)
+	case runtime.SequenceRecover:
+		if atomic.LoadInt32(&c.booted) == 1 {
+			return fmt.Errorf("%s sequence: %w", seq.String(), runtime.ErrBootInProgress)
+		}
+	}
+
+	if c.preflightEnabled {
+		if err := runtime.CheckPreflight(seq, c.preflight(seq, data)); err != nil {
+			return err
+		}
+	}
+
+	// A shutdown or reboot waits for the shutdown inhibitors, whether it is
+	// requested by an event or the API.
+	if terminates(seq) {
+		c.waitForInhibitors(inhibitTimeout)
+	}
+
+	ctx, cancel := c.sequenceContext(seq)
+	defer cancel()
+
+	cleanups := &runtime.Cleanups{}
+	ctx = runtime.WithCleanups(ctx, cleanups)
+	ctx = c.withSubsequences(ctx, seq)
+
+	// Deferred tasks must not outlive the machine.
+	switch seq {
+	case runtime.SequenceShutdown, runtime.SequenceReboot, runtime.SequenceReset, runtime.SequenceUpgrade, runtime.SequenceRollback:
+		if !c.deferred.Cancel(10 * time.Second) {
+			c.log().Warn("timed out waiting for deferred tasks to stop", "sequence", seq)
+		}
+	}
+
+	phases, err := c.phases(seq, data)
+	if err != nil {
+		return err
+	}
+
+	c.warnUnknownTaskTimeouts(seq, phases)
+
+	stopWatchdog := c.startWatchdog(ctx, seq)
+
+	start := time.Now()
+
+	err = c.runWithTimeout(ctx, seq, c.sequenceTimeout(seq), func(ctx context.Context) error {
+		return c.run(ctx, seq, phases, data)
+	})
+
+	stopWatchdog()
+
+	duration := time.Since(start)
+
+	if err != nil {
+		c.runCleanups(seq, cleanups)
+	}
+
+	c.metrics.observeSequence(seq, duration, err)
+
+	trace := c.LastTrace()
+
+	c.pushResult(newSequenceResult(seq, start, duration, err, trace))
+
+	c.outcomes.push(c.sink(), newOutcome(seq, duration, err, trace))
+
+	if err != nil {
+		return err
+	}
+
+	c.runDeferred(seq, data)
+
+	return nil
+}
+
+// cleanupTimeout is the time the cleanups of a failed or aborted sequence are
+// given. It is shorter than preemptionTimeout, so that the cleanups of a
+// preempted sequence complete before the forced sequence proceeds.
+const cleanupTimeout = 20 * time.Second
+
+// runCleanups runs the cleanups registered by the tasks of the sequence, with
+// a context of their own, since the context of the sequence may be canceled.
+func (c *Controller) runCleanups(seq runtime.Sequence, cleanups *runtime.Cleanups) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	// The errors are reported as the cleanups run.
+	//
+	// nolint: errcheck
+	cleanups.Run(ctx, func(name string, err error) {
+		if err != nil {
+			c.log().Error("cleanup failed", "sequence", seq, "cleanup", name, "error", err)
+
+			return
+		}
+
+		c.log().Info("cleanup done", "sequence", seq, "cleanup", name)
+	})
+}
+
+// SetOutcomeSink sets the sink that the outcome of each sequence is reported
+// to, overriding the webhook in the config.
+func (c *Controller) SetOutcomeSink(sink runtime.OutcomeSink) {
+	c.outcomeSink = sink
+}
+
+// SetLogger sets the logger of the progress of the sequences, phases and
+// tasks, overriding the default one, which writes to the kernel log.
+func (c *Controller) SetLogger(logger runtime.Logger) {
+	c.logger = logger
+}
+
+// log returns the logger of the controller. In container mode, the kernel log
+// is the one of the host, so the default logger is the standard one.
+func (c *Controller) log() runtime.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+
+	if c.r != nil && c.r.State() != nil && c.r.State().Platform().Mode() == runtime.ModeContainer {
+		return runtime.StandardLogger{}
+	}
+
+	c.defaultLogger.init(c.setupLogger())
+
+	return &c.defaultLogger
+}
+
+// setupLogger returns the setup of the loggers.
+func (c *Controller) setupLogger(opts ...kmsg.Option) func(logger *log.Logger, prefix string, level runtime.Level) error {
+	if c.replaying {
+		return func(logger *log.Logger, prefix string, level runtime.Level) error {
+			logger.SetOutput(ioutil.Discard)
+
+			return nil
+		}
+	}
+
+	return func(logger *log.Logger, prefix string, level runtime.Level) error {
+		return setupTaskLogger(logger, prefix, level, opts...)
+	}
+}
+
+// SetMaxParallelTasks sets the maximum number of tasks of a phase that run
+// concurrently, for the sequences that the config sets no limit for. This
+// includes the initialize sequence, which runs before the config is loaded.
+// Zero, the default, means no limit.
+func (c *Controller) SetMaxParallelTasks(n int) {
+	c.maxParallel = n
+}
+
+// SetSIGTERMGracePeriod sets the delay between the first SIGTERM and the
+// shutdown it requests. A second SIGTERM within the delay forces the shutdown
+// right away. Zero, the default, shuts down on the first SIGTERM. It must be
+// called before ListenForEvents.
+func (c *Controller) SetSIGTERMGracePeriod(d time.Duration) {
+	c.sigtermGrace = d
+}
+
+// sink returns the sink for sequence outcomes. The config is not available
+// early in the initialize sequence, in which case outcomes are discarded.
+func (c *Controller) sink() runtime.OutcomeSink {
+	if c.outcomeSink != nil {
+		return c.outcomeSink
+	}
+
+	if c.r.Config() == nil {
+		return runtime.NoopOutcomeSink{}
+	}
+
+	if url := c.r.Config().Machine().Outcomes().Webhook(); url != "" {
+		return NewWebhookOutcomeSink(url)
+	}
+
+	return runtime.NoopOutcomeSink{}
+}
+
+// RunPhase executes a single phase of the sequence in isolation, identified by
+// its number (starting at 1) or by the name of one of its tasks (e.g.
+// "StartAllServices").
+//
+// This is a debugging aid only, and requires debug to be enabled in the
+// config. The phase runs against the live runtime without any of the phases
+// it normally depends on, or that normally clean up after it, so it can leave
+// the machine in an inconsistent state (e.g. with partitions mounted or
+// services started out of order).
+func (c *Controller) RunPhase(seq runtime.Sequence, name string, data interface{}) error {
+	if c.r == nil {
+		return runtime.ErrUndefinedRuntime
+	}
+
+	if c.r.Config() == nil || !c.r.Config().Debug() {
+		return runtime.ErrDebugRequired
+	}
+
+	if c.TryLock() {
+		return runtime.ErrLocked
+	}
+
+	defer c.Unlock()
+
+	phases, err := c.phases(seq, data)
+	if err != nil {
+		return err
+	}
+
+	number, err := findPhase(phases, name)
+	if err != nil {
+		return fmt.Errorf("%s sequence: %w", seq.String(), err)
+	}
+
+	phase := phases[number-1]
+
+	c.log().Warn("running phase in isolation (debug)", "sequence", seq, "phase", fmt.Sprintf("%d/%d", number, len(phases)), "tasks", len(phase.Tasks()))
+
+	ctx, cancel := c.sequenceContext(seq)
+	defer cancel()
+
+	start := time.Now()
+
+	// An isolated phase is not part of a sequence trace, as its context
+	// carries no recorder.
+	if err = c.runPhase(withPhaseProgress(ctx, number, len(phases)), phase, seq, data); err != nil {
+		return fmt.Errorf("error running phase %d in %s sequence: %w", number, seq.String(), err)
+	}
+
+	c.log().Info("phase done", "sequence", seq, "phase", fmt.Sprintf("%d/%d", number, len(phases)), "duration", time.Since(start))
+
+	return nil
+}
+
+// findPhase returns the human friendly number of the phase identified by
+// name, which is either the phase number or the name of one of its tasks.
+func findPhase(phases []runtime.Phase, name string) (int, error) {
+	if number, err := strconv.Atoi(name); err == nil {
+		if number < 1 || number > len(phases) {
+			return 0, fmt.Errorf("phase %d out of range, sequence has %d phase(s)", number, len(phases))
+		}
+
+		return number, nil
+	}
+
+	for i, phase := range phases {
+		for _, task := range phase.Tasks() {
+			if taskName(task) == name {
+				return i + 1, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("no phase with task %q", name)
+}
+
+// Runtime implements the controller interface.
+func (c *Controller) Runtime() runtime.Runtime {
+	return c.r
+}
+
+// Sequencer implements the controller interface.
+func (c *Controller) Sequencer() runtime.Sequencer {
+	return c.s
+}
+
+// ListenForEvents starts the event listener. The listener will trigger a
+// config reload in response to a SIGHUP signal, and the sequences requested by
+// the event sources: a shutdown in response to a SIGTERM signal, the action
+// mapped to an ACPI event (by default, a shutdown for the button/power event),
+// and those of the sources added with RegisterEventSource.
+func (c *Controller) ListenForEvents() error {
+	hups := make(chan os.Signal, 1)
+
+	signal.Notify(hups, syscall.SIGHUP)
+
+	go func() {
+		for range hups {
+			c.log().Info("config reload via SIGHUP received")
+
+			if err := c.Reload(); err != nil {
+				c.log().Error("config reload failed", "error", err)
+			}
+		}
+	}()
+
+	return listen(context.Background(), c.eventSources(), c.handleRequest)
+}
+
+// acpiAction returns the action taken in response to an ACPI event. The
+// actions of the config, once loaded, override the defaults.
+func (c *Controller) acpiAction(event acpi.Event) acpi.Action {
+	if c.r.Config() == nil {
+		return acpi.Lookup(event, acpi.Actions)
+	}
+
+	actions := make(map[string]acpi.Action, len(acpi.Actions))
+
+	for name, action := range acpi.Actions {
+		actions[name] = action
+	}
+
+	for name, action := range c.r.Config().Machine().ACPI().Actions() {
+		switch action {
+		case runtime.ACPIActionShutdown:
+			actions[name] = acpi.ActionShutdown
+		case runtime.ACPIActionReboot:
+			actions[name] = acpi.ActionReboot
+		case runtime.ACPIActionMaintenance:
+			actions[name] = acpi.ActionMaintenance
+		case runtime.ACPIActionResume:
+			actions[name] = acpi.ActionResume
+		default:
+			actions[name] = acpi.ActionIgnore
+		}
+	}
+
+	return acpi.Lookup(event, actions)
+}
+
+// Metrics returns a handler that serves the durations and outcomes of the
+// sequences, phases and tasks for Prometheus to scrape.
+func (c *Controller) Metrics() http.Handler {
+	return &c.metrics
+}
+
+// Events implements the controller interface.
+func (c *Controller) Events() runtime.Events {
+	return &c.events
+}
+
+// LastTrace returns the trace of the most recently run sequence, or nil if no
+// sequence has run yet.
+func (c *Controller) LastTrace() *Trace {
+	c.traceMu.Lock()
+	defer c.traceMu.Unlock()
+
+	return c.lastTrace
+}
+
+// sequenceContext returns the context of a sequence about to run. It is
+// canceled by the returned function, which must be called once the sequence
+// completes.
+func (c *Controller) sequenceContext(seq runtime.Sequence) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	status := &runtime.SequenceStatus{Sequence: seq, Start: time.Now()}
+
+	ctx = context.WithValue(ctx, sequenceStatusKey{}, status)
+
+	c.cancelMu.Lock()
+	c.generation++
+	generation := c.generation
+	c.cancel = cancel
+	c.running = seq
+	c.status = status
+	c.cancelMu.Unlock()
+
+	return ctx, func() {
+		c.cancelMu.Lock()
+		if c.generation == generation {
+			c.cancel = nil
+			c.status = nil
+		}
+
+		c.cancelMu.Unlock()
+
+		cancel()
+	}
+}
+
+type sequenceStatusKey struct{}
+
+// updateStatus updates the status of the sequence the context belongs to. The
+// status of a preempted sequence is no longer reported, so updating it is
+// harmless.
+func (c *Controller) updateStatus(ctx context.Context, f func(*runtime.SequenceStatus)) {
+	status, ok := ctx.Value(sequenceStatusKey{}).(*runtime.SequenceStatus)
+	if !ok || status == nil {
+		return
+	}
+
+	c.cancelMu.Lock()
+	defer c.cancelMu.Unlock()
+
+	f(status)
+}
+
+// Abort cancels the running sequence, and returns it. Only the sequences that
+// are abortable (see `Sequence.Abortable`) can be aborted, and only until they
+// reach their point of no return. The aborted sequence returns once its
+// running tasks observe the cancellation.
+func (c *Controller) Abort() (runtime.Sequence, error) {
+	c.cancelMu.Lock()
+	defer c.cancelMu.Unlock()
+
+	if c.cancel == nil || c.status == nil {
+		return runtime.SequenceNoop, runtime.ErrNotRunning
+	}
+
+	seq := c.status.Sequence
+
+	switch {
+	case !seq.Abortable():
+		return seq, fmt.Errorf("%s sequence: %w", seq, runtime.ErrNotAbortable)
+	case c.status.NoReturn:
+		return seq, fmt.Errorf("%s sequence is past its point of no return: %w", seq, runtime.ErrNotAbortable)
+	}
+
+	c.log().Warn("sequence aborted", "sequence", seq, "phase", fmt.Sprintf("%d/%d", c.status.Phase, c.status.PhaseTotal))
+
+	c.cancel()
+
+	return seq, nil
+}
+
+// markNoReturn marks the sequence as past its point of no return if one of the
+// phases of the group about to run is. The context is checked with the status
+// locked, so that the sequence is either aborted before the group runs, or can
+// no longer be aborted.
+func (c *Controller) markNoReturn(ctx context.Context, phases []runtime.Phase, group []int) {
+	noReturn := false
+
+	for _, i := range group {
+		noReturn = noReturn || phases[i].Options().NoReturn
+	}
+
+	if !noReturn {
+		return
+	}
+
+	c.updateStatus(ctx, func(status *runtime.SequenceStatus) {
+		if ctx.Err() == nil {
+			status.NoReturn = true
+		}
+	})
+}
+
+// CurrentSequence returns the status of the running sequence, and false if no
+// sequence is running. It is safe to call while a sequence runs.
+func (c *Controller) CurrentSequence() (runtime.SequenceStatus, bool) {
+	c.cancelMu.Lock()
+	defer c.cancelMu.Unlock()
+
+	if c.status == nil {
+		return runtime.SequenceStatus{}, false
+	}
+
+	return *c.status, true
+}
+
+// TryLock attempts to set a lock that prevents multiple sequences from running
+// at once. If currently locked, a value of true will be returned. If not
+// currently locked, a value of false will be returned.
+func (c *Controller) TryLock() bool {
+	return !atomic.CompareAndSwapInt32(&c.semaphore, 0, 1)
+}
+
+// Unlock removes the lock set by `TryLock`. If a sequence is waiting for the
+// lock in RunWait, the lock is handed to it instead.
+func (c *Controller) Unlock() bool {
+	c.waitersMu.Lock()
+	defer c.waitersMu.Unlock()
+
+	if len(c.waiters) > 0 && atomic.LoadInt32(&c.semaphore) == 1 && atomic.LoadInt32(&c.preempting) == 0 {
+		close(c.waiters[0])
+		c.waiters = c.waiters[1:]
+
+		return true
+	}
+
+	return atomic.CompareAndSwapInt32(&c.semaphore, 1, 0)
+}
+
+func (c *Controller) run(ctx context.Context, seq runtime.Sequence, phases []runtime.Phase, data interface{}) (err error) {
+	start := time.Now()
+
+	c.log().Info("sequence starting", "sequence", seq, "phases", len(phases))
+
+	defer func() {
+		if err != nil {
+			c.log().Error("sequence failed", "sequence", seq, "duration", time.Since(start), "error", err)
+
+			return
+		}
+
+		c.log().Info("sequence done", "sequence", seq, "duration", time.Since(start))
+	}()
+
+	c.events.publish(runtime.Event{Type: runtime.EventSequenceStarted, Sequence: seq, PhaseTotal: len(phases)})
+
+	defer func() {
+		e := runtime.Event{
+			Type:       runtime.EventSequenceFinished,
+			Sequence:   seq,
+			PhaseTotal: len(phases),
+			Elapsed:    time.Since(start),
+		}
+
+		if err != nil {
+			e.Error = err.Error()
+		}
+
+		c.events.publish(e)
+	}()
+
+	result := errorList{failed: "phases"}
+
+	policy := c.s.ErrorPolicy(seq)
+
+	c.updateStatus(ctx, func(status *runtime.SequenceStatus) {
+		status.PhaseTotal = len(phases)
+	})
+
+	// The trace of a nested sequence is not recorded, as the last trace is the
+	// one of the sequence running it.
+	var recorder *traceRecorder
+
+	if !nested(ctx) {
+		recorder = newTraceRecorder(seq)
+
+		defer func() {
+			c.traceMu.Lock()
+			c.lastTrace = recorder.trace
+			c.traceMu.Unlock()
+		}()
+	}
+
+	ctx = withTraceRecorder(ctx, recorder)
+
+	for _, group := range overlapGroups(phases) {
+		// Make the phase number human friendly.
+		number := group[0] + 1
+
+		c.pauseBeforePhase(ctx, seq, number, len(phases))
+
+		c.markNoReturn(ctx, phases, group)
+
+		// A canceled sequence is aborted regardless of the error policy.
+		if ctx.Err() != nil {
+			err = fmt.Errorf("%s sequence aborted before phase %d: %w", seq.String(), number, ctx.Err())
+
+			if policy == runtime.ErrorPolicyFailFast {
+				return err
+			}
+
+			result.errs = append(result.errs, err)
+
+			return result.err()
+		}
+
+		for _, e := range c.runPhases(ctx, seq, phases, group, policy, data) {
+			if policy == runtime.ErrorPolicyFailFast {
+				return e
+			}
+
+			c.log().Warn("phase failed, continuing", "sequence", seq, "error", e)
+
+			result.errs = append(result.errs, e)
+		}
+	}
+
+	return result.err()
+}
+
+// overlapGroups orders the phases by their dependencies: a phase depends on
+// all the phases before it, unless the phase right before it overlaps with it.
+// It returns the indexes of the phases in groups: the phases of a group run
+// concurrently, and each group starts once the previous group is done.
+func overlapGroups(phases []runtime.Phase) [][]int {
+	var groups [][]int
+
+	for i := range phases {
+		if i == 0 || !phases[i-1].OverlapsWithNext() {
+			groups = append(groups, nil)
+		}
+
+		groups[len(groups)-1] = append(groups[len(groups)-1], i)
+	}
+
+	return groups
+}
+
+// runPhases runs a group of phases concurrently, and returns the errors of
+// the phases that failed, in the order they failed. With the fail-fast
+// policy, the first phase to fail cancels the others.
+func (c *Controller) runPhases(ctx context.Context, seq runtime.Sequence, phases []runtime.Phase, group []int, policy runtime.ErrorPolicy, data interface{}) []error {
+	// Phases are recorded in order, before any of them starts.
+	for range group {
+		traceRecorderFrom(ctx).beginPhase()
+	}
+
+	if len(group) == 1 {
+		if err := c.runNumberedPhase(ctx, seq, phases, group[0]+1, data); err != nil {
+			return []error{err}
+		}
+
+		return nil
+	}
+
+	c.log().Info("phases running concurrently", "sequence", seq, "phases", fmt.Sprintf("%d-%d/%d", group[0]+1, group[len(group)-1]+1, len(phases)))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+
+	for _, i := range group {
+		number := i + 1
 
-	"github.com/talos-systems/talos/api/machine"
-	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
-	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/v1alpha1/acpi"
-	"github.com/talos-systems/talos/internal/pkg/kmsg"
-	"github.com/talos-systems/talos/pkg/config"
-)
+		wg.Add(1)
 
-// Controller represents the controller responsible for managing the execution
-// of sequences.
-type Controller struct {
-	r *Runtime
-	s *Sequencer
+		go func() {
+			defer wg.Done()
 
-	semaphore int32
+			if err := c.runNumberedPhase(ctx, seq, phases, number, data); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+
+				if policy == runtime.ErrorPolicyFailFast {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errs
 }
 
-// NewController intializes and returns a controller.
-func NewController(b []byte) (*Controller, error) {
-	// Wait for USB storage in the case that the install disk is supplied over
-	// USB. If we don't wait, there is the chance that we will fail to detect the
-	// install disk.
-	err := waitForUSBDelay()
+func (c *Controller) runNumberedPhase(ctx context.Context, seq runtime.Sequence, phases []runtime.Phase, number int, data interface{}) error {
+	phase := phases[number-1]
+
+	start := time.Now()
+
+	progress := fmt.Sprintf("%d/%d", number, len(phases))
+
+	c.updateStatus(ctx, func(status *runtime.SequenceStatus) {
+		if number > status.Phase {
+			status.Phase = number
+		}
+	})
+
+	c.log().Info("phase starting", "sequence", seq, "phase", progress, "tasks", len(phase.Tasks()))
+
+	c.events.publish(runtime.Event{Type: runtime.EventPhaseStarted, Sequence: seq, Phase: number, PhaseTotal: len(phases), TaskTotal: len(phase.Tasks())})
+
+	err := c.runPhase(withPhaseProgress(ctx, number, len(phases)), phase, seq, data)
+
+	c.metrics.observePhase(seq, number, time.Since(start))
+
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("error running phase %d in %s sequence: %w", number, seq.String(), err)
 	}
 
-	s, err := NewState()
-	if err != nil {
-		return nil, err
+	c.log().Info("phase done", "sequence", seq, "phase", progress, "duration", time.Since(start))
+
+	return nil
+}
+
+func (c *Controller) runPhase(ctx context.Context, phase runtime.Phase, seq runtime.Sequence, data interface{}) error {
+	opts := phase.Options()
+
+	// The timeout of the phase cancels the context of its tasks.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The first task to fail cancels the context of the remaining tasks in the
+	// phase, and the errors of all the tasks that failed are returned.
+	var (
+		wg   sync.WaitGroup
+		errs taskErrors
+	)
+
+	phaseNumber, phaseTotal := phaseProgress(ctx)
+
+	var sem chan struct{}
+
+	if limit := c.maxParallelTasks(seq); limit > 0 {
+		sem = make(chan struct{}, limit)
 	}
 
-	var cfg runtime.Configurator
+	tasks := phase.Tasks()
 
-	if b != nil {
-		cfg, err = config.NewFromBytes(b)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse config: %w", err)
+	// The phase timeout must not cut short a longer task timeout of the
+	// config, e.g. one raising the unmount timeout for a slow disk.
+	if opts.Timeout > 0 {
+		for _, task := range tasks {
+			if timeout := c.taskTimeout(taskName(task)); timeout > opts.Timeout {
+				opts.Timeout = timeout
+			}
 		}
 	}
 
-	ctlr := &Controller{
-		r: NewRuntime(cfg, s),
-		s: NewSequencer(),
+	for number, task := range tasks {
+		// Make the task number human friendly.
+		number := number
+
+		number++
+
+		task := task
+
+		run := func() error {
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+
+				defer func() { <-sem }()
+			}
+
+			start := time.Now()
+
+			progress := fmt.Sprintf("%d/%d", number, len(tasks))
+
+			name := tracedTaskName(ctx, phaseNumber, number, task)
+
+			defer runningTasksFrom(ctx).start(phaseNumber, phaseTotal, name)()
+
+			if timeout := c.taskTimeout(name); timeout > 0 {
+				task = runtime.WithTimeout(task, timeout)
+			}
+
+			fields := []interface{}{"sequence", seq, "phase", fmt.Sprintf("%d/%d", phaseNumber, phaseTotal), "task", progress, "name", name}
+
+			c.log().Info("task starting", fields...)
+
+			e := runtime.Event{
+				Type:       runtime.EventTaskStarted,
+				Sequence:   seq,
+				Phase:      phaseNumber,
+				PhaseTotal: phaseTotal,
+				Task:       number,
+				TaskTotal:  len(tasks),
+				TaskName:   name,
+			}
+
+			c.events.publish(e)
+
+			taskCtx := runtime.WithProgress(ctx, c.events.progressReporter(e))
+			taskCtx = runtime.WithMessages(taskCtx, c.events.messageReporter(e))
+
+			err := c.runTask(taskCtx, number, task, seq, data)
+
+			reason, skipped := runtime.SkipReason(err)
+			if skipped {
+				err = nil
+			}
+
+			traceRecorderFrom(ctx).recordTask(phaseNumber, number, name, start, err)
+
+			degradation, degraded := runtime.DegradedReason(err)
+			if degraded {
+				err = nil
+			}
+
+			c.metrics.observeTask(seq, name, time.Since(start), err)
+
+			e.Type = runtime.EventTaskFinished
+			e.Elapsed = time.Since(start)
+
+			if err != nil {
+				e.Error = err.Error()
+			}
+
+			c.events.publish(e)
+
+			var extErr *runtime.ExtensionError
+
+			if errors.As(err, &extErr) {
+				c.log().Warn("extension task failed, continuing", append(fields, "extension", extErr.Extension, "duration", time.Since(start), "error", extErr.Err)...)
+
+				return nil
+			}
+
+			if err != nil {
+				c.log().Error("task failed", append(fields, "duration", time.Since(start), "error", err)...)
+
+				return fmt.Errorf("task %s: failed, %w", progress, err)
+			}
+
+			if skipped {
+				c.log().Info("task skipped", append(fields, "reason", reason)...)
+
+				return nil
+			}
+
+			if degraded {
+				c.log().Warn("task degraded", append(fields, "duration", time.Since(start), "reason", degradation)...)
+
+				return nil
+			}
+
+			c.log().Info("task done", append(fields, "duration", time.Since(start))...)
+
+			return nil
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := run(); err != nil {
+				errs.add(number, err)
+
+				cancel()
+			}
+		}()
 	}
 
-	return ctlr, nil
-}
+	wait := func() error {
+		wg.Wait()
 
-// Run executes all phases known to the controller in serial. `Controller`
-// aborts immediately if any phase fails.
-func (c *Controller) Run(seq runtime.Sequence, data interface{}) error {
-	// We must ensure that the runtime is configured since all sequences depend
-	// on the runtime.
-	if c.r == nil {
-		return runtime.ErrUndefinedRuntime
+		return errs.err()
 	}
 
-	// Allow only one sequence to run at a time.
-	if c.TryLock() {
-		return runtime.ErrLocked
+	if opts.Timeout == 0 {
+		return wait()
 	}
 
-	defer c.Unlock()
+	done := make(chan error, 1)
 
-	phases, err := c.phases(seq, data)
-	if err != nil {
+	go func() {
+		done <- wait()
+	}()
+
+	timer := time.NewTimer(opts.Timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
 		return err
+	case <-timer.C:
+	}
+
+	// Tasks stuck in a call that ignores the context (e.g. an unmount) can
+	// not be waited for, so they are left behind, along with the goroutine
+	// waiting for them. Both exit once the stuck calls return, and done is
+	// buffered so that the goroutine does not block on it. Phase timeouts are
+	// only used by the sequences that end with the machine going down, which
+	// bounds the leak.
+	cancel()
+
+	err := fmt.Errorf("%w after %s", runtime.ErrPhaseTimeout, opts.Timeout)
+
+	if opts.TimeoutAction == runtime.PhaseTimeoutContinue {
+		c.log().Warn("phase timed out, continuing", "sequence", seq, "phase", fmt.Sprintf("%d/%d", phaseNumber, phaseTotal), "error", err)
+
+		return nil
 	}
 
-	return c.run(seq, phases, data)
+	return err
 }
 
-// Runtime implements the controller interface.
-func (c *Controller) Runtime() runtime.Runtime {
-	return c.r
+// taskErrors collects the errors of the tasks of a phase.
+type taskErrors struct {
+	mu   sync.Mutex
+	errs []taskError
 }
 
-// Sequencer implements the controller interface.
-func (c *Controller) Sequencer() runtime.Sequencer {
-	return c.s
+type taskError struct {
+	number int
+	err    error
 }
 
-// ListenForEvents starts the event listener. The listener will trigger a
-// shutdown in response to a SIGTERM signal and ACPI button/power event.
-func (c *Controller) ListenForEvents() error {
-	sigs := make(chan os.Signal, 1)
+func (e *taskErrors) add(number int, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-	signal.Notify(sigs, syscall.SIGTERM)
+	// The tasks canceled because another task failed did not fail by
+	// themselves.
+	if len(e.errs) > 0 && errors.Is(err, context.Canceled) {
+		return
+	}
 
-	errCh := make(chan error, 2)
+	e.errs = append(e.errs, taskError{number: number, err: err})
+}
 
-	go func() {
-		<-sigs
-		signal.Stop(sigs)
+// err returns the error of the task, if a single one failed, or else the
+// errors of all the tasks that failed, ordered by task number.
+func (e *taskErrors) err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-		log.Printf("shutdown via SIGTERM received")
+	sort.Slice(e.errs, func(i, j int) bool { return e.errs[i].number < e.errs[j].number })
 
-		if err := c.Run(runtime.SequenceShutdown, nil); err != nil {
-			log.Printf("shutdown failed: %v", err)
-		}
+	errs := errorList{failed: "tasks", errs: make([]error, len(e.errs))}
 
-		errCh <- nil
-	}()
+	for i := range e.errs {
+		errs.errs[i] = e.errs[i].err
+	}
+
+	return errs.err()
+}
+
+// errorList is the error of several failures, of the tasks of a phase, or of
+// the phases of a sequence which collects the errors. Any of the errors
+// matches errors.Is and errors.As.
+type errorList struct {
+	failed string
+	errs   []error
+}
 
-	if c.r.State().Platform().Mode() == runtime.ModeContainer {
+// err returns the error, if there is a single one, or else the list.
+func (e errorList) err() error {
+	switch len(e.errs) {
+	case 0:
 		return nil
+	case 1:
+		return e.errs[0]
 	}
 
-	go func() {
-		if err := acpi.StartACPIListener(); err != nil {
-			errCh <- err
+	return e
+}
 
-			return
+// Error implements the error interface.
+func (e errorList) Error() string {
+	msgs := make([]string, len(e.errs))
+
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d %s failed: %s", len(e.errs), e.failed, strings.Join(msgs, "; "))
+}
+
+// Is reports whether any of the errors matches the target.
+func (e errorList) Is(target error) bool {
+	for _, err := range e.errs {
+		if errors.Is(err, target) {
+			return true
 		}
+	}
 
-		log.Printf("shutdown via ACPI received")
+	return false
+}
 
-		// TODO: The sequencer lock will prevent this. We need a way to force the
-		// shutdown.
-		if err := c.Run(runtime.SequenceShutdown, nil); err != nil {
-			log.Printf("shutdown failed: %v", err)
+// As finds the first of the errors that matches the target.
+func (e errorList) As(target interface{}) bool {
+	for _, err := range e.errs {
+		if errors.As(err, target) {
+			return true
 		}
+	}
 
-		errCh <- nil
-	}()
+	return false
+}
 
-	err := <-errCh
+// maxParallelTasks returns the limit of concurrently running tasks for the
+// sequence: the one of the config, if any, or else the one of the controller.
+// The config is not available early in the initialize sequence.
+func (c *Controller) maxParallelTasks(seq runtime.Sequence) int {
+	if c.r == nil || c.r.Config() == nil {
+		return c.maxParallel
+	}
 
-	return err
+	if limit := c.r.Config().Machine().Sequences().MaxParallelTasks(seq); limit > 0 {
+		return limit
+	}
+
+	return c.maxParallel
 }
 
-// TryLock attempts to set a lock that prevents multiple sequences from running
-// at once. If currently locked, a value of true will be returned. If not
-// currently locked, a value of false will be returned.
-func (c *Controller) TryLock() bool {
-	return !atomic.CompareAndSwapInt32(&c.semaphore, 0, 1)
+// taskLogOptions returns the options of the loggers of the tasks set by the
+// config. The logs of the tasks which run before the config is loaded are
+// not limited.
+func (c *Controller) taskLogOptions() []kmsg.Option {
+	if c.r == nil || c.r.Config() == nil {
+		return nil
+	}
+
+	logs := c.r.Config().Machine().TaskLogs()
+
+	return []kmsg.Option{kmsg.WithRateLimit(logs.RateLimit()), kmsg.WithStderr(logs.Stderr())}
 }
 
-// Unlock removes the lock set by `TryLock`.
-func (c *Controller) Unlock() bool {
-	return atomic.CompareAndSwapInt32(&c.semaphore, 1, 0)
+// taskTimeout returns the timeout of the named task set by the config, or zero
+// if there is none. The timeout replaces the built-in timeouts of the task
+// (see `runtime.TaskTimeout`), and extends the timeout of its phase.
+func (c *Controller) taskTimeout(name string) time.Duration {
+	if c.r == nil || c.r.Config() == nil {
+		return 0
+	}
+
+	return c.r.Config().Machine().TaskTimeouts()[name]
 }
 
-func (c *Controller) run(seq runtime.Sequence, phases []runtime.Phase, data interface{}) error {
-	start := time.Now()
+// warnUnknownTaskTimeouts logs the task timeouts of the config which do not
+// name a known task, e.g. because of a typo, as they never apply. The known
+// tasks are the labeled ones, which every sequence is built from, and the
+// tasks of the phases of the sequence. They do not fail the sequence, so that
+// a config can be shared across versions.
+func (c *Controller) warnUnknownTaskTimeouts(seq runtime.Sequence, phases []runtime.Phase) {
+	if c.r == nil || c.r.Config() == nil {
+		return
+	}
 
-	log.Printf("%s sequence: %d phase(s)", seq.String(), len(phases))
-	defer log.Printf("%s sequence: done: %s", seq.String(), time.Since(start))
+	running := map[string]bool{}
 
-	var (
-		number int
-		phase  runtime.Phase
-		err    error
-	)
+	for _, phase := range phases {
+		for _, task := range phase.Tasks() {
+			running[taskName(task)] = true
+		}
+	}
 
-	for number, phase = range phases {
-		// Make the phase number human friendly.
-		number++
+	for name := range c.r.Config().Machine().TaskTimeouts() {
+		if !knownTasks[name] && !running[name] {
+			c.log().Warn("ignoring the timeout of an unknown task", "sequence", seq, "name", name)
+		}
+	}
+}
 
-		start := time.Now()
+// runTask runs the task numbered n in the phase that the context carries the
+// progress of.
+func (c *Controller) runTask(ctx context.Context, n int, f runtime.TaskSetupFunc, seq runtime.Sequence, data interface{}) error {
+	phase, phases := phaseProgress(ctx)
 
-		progress := fmt.Sprintf("%d/%d", number, len(phases))
+	return c.runTaskWithContext(withTask(ctx), taskPrefix(seq, phase, phases, n), f, seq, data)
+}
 
-		log.Printf("phase %s: %d tasks(s)", progress, len(phase))
+// taskPrefix returns the prefix of the kernel log lines of a task. The lines
+// are grepped for, so the format is kept stable:
+//
+//	[talos] <sequence> <phase>/<phases> task <task>:
+//
+// e.g. `[talos] upgrade 3/7 task 2:`. The phase is omitted if it is not known,
+// which is the case for deferred tasks:
+//
+//	[talos] <sequence> deferred task <task>:
+func taskPrefix(seq runtime.Sequence, phase, phases, task int) string {
+	if phase == 0 {
+		return fmt.Sprintf("[talos] %s task %d:", seq, task)
+	}
 
-		if err = c.runPhase(phase, seq, data); err != nil {
-			return fmt.Errorf("error running phase %d in %s sequence: %w", number, seq.String(), err)
-		}
+	return fmt.Sprintf("[talos] %s %d/%d task %d:", seq, phase, phases, task)
+}
 
-		log.Printf("phase %s: done, %s", progress, time.Since(start))
+// setupTaskLogger configures a logger to write at the level, which maps to the
+// priority of the kernel log. It is a variable so that tests can run tasks
+// without access to /dev/kmsg.
+var setupTaskLogger = func(logger *log.Logger, prefix string, level runtime.Level, opts ...kmsg.Option) error {
+	return kmsg.SetupLoggerWithPriority(logger, prefix, kmsgPriority(level), true, opts...)
+}
+
+func (c *Controller) runTaskWithContext(ctx context.Context, prefix string, f runtime.TaskSetupFunc, seq runtime.Sequence, data interface{}) error {
+	logger := &log.Logger{}
+
+	setup := c.setupLogger(c.taskLogOptions()...)
+
+	if err := setup(logger, prefix, runtime.LevelInfo); err != nil {
+		return err
 	}
 
-	return nil
+	// The lines dropped at the end of the task are reported once it returns.
+	// nolint: errcheck
+	defer kmsg.Flush(logger)
+
+	task := f(seq, data)
+	if task == nil {
+		return runtime.Skip("not applicable")
+	}
+
+	return task(ctx, logger, c.r)
 }
 
-func (c *Controller) runPhase(phase runtime.Phase, seq runtime.Sequence, data interface{}) error {
-	var eg errgroup.Group
+// runDeferred starts the deferred tasks of the sequence in the background.
+// Failures are logged, and do not affect the outcome of the sequence.
+func (c *Controller) runDeferred(seq runtime.Sequence, data interface{}) {
+	tasks := c.s.Deferred(seq, c.r).Tasks()
 
-	for number, task := range phase {
+	for number, task := range tasks {
 		// Make the task number human friendly.
 		number := number
 
@@ -205,53 +1486,83 @@ func (c *Controller) runPhase(phase runtime.Phase, seq runtime.Sequence, data in
 
 		task := task
 
-		eg.Go(func() error {
+		c.deferred.Go(func(ctx context.Context) {
 			start := time.Now()
 
-			progress := fmt.Sprintf("%d/%d", number, len(phase))
+			progress := fmt.Sprintf("%d/%d", number, len(tasks))
 
-			log.Printf("task %s: starting", progress)
-			defer log.Printf("task %s: done, %s", progress, time.Since(start))
+			c.log().Info("deferred task starting", "sequence", seq, "task", progress)
 
-			if err := c.runTask(number, task, seq, data); err != nil {
-				return fmt.Errorf("task %s: failed, %w", progress, err)
-			}
+			err := c.runTaskWithContext(ctx, fmt.Sprintf("[talos] %s deferred task %d:", seq, number), task, seq, data)
 
-			return nil
-		})
-	}
+			if reason, ok := runtime.SkipReason(err); ok {
+				c.log().Info("deferred task skipped", "sequence", seq, "task", progress, "reason", reason)
 
-	return eg.Wait()
-}
+				return
+			}
 
-func (c *Controller) runTask(n int, f runtime.TaskSetupFunc, seq runtime.Sequence, data interface{}) error {
-	logger := &log.Logger{}
+			if err != nil {
+				c.log().Error("deferred task failed", "sequence", seq, "task", progress, "duration", time.Since(start), "error", err)
 
-	if err := kmsg.SetupLogger(logger, fmt.Sprintf("[talos] task %d:", n), true); err != nil {
-		return err
+				return
+			}
+
+			c.log().Info("deferred task done", "sequence", seq, "task", progress, "duration", time.Since(start))
+		})
 	}
+}
 
-	if task := f(seq, data); task != nil {
-		return task(context.TODO(), logger, c.r)
+// checkConfigured returns `runtime.ErrUnconfigured` if the sequence requires
+// the config, and it is not loaded, e.g. since the controller was created
+// without it, and the initialize sequence did not run yet. This is checked
+// before the sequencer builds the phases, as it, like the tasks, expects the
+// config of such a sequence to be loaded.
+func (c *Controller) checkConfigured(seq runtime.Sequence) error {
+	if c.r == nil || c.r.Config() != nil || !seq.RequiresConfig() {
+		return nil
 	}
 
-	return nil
+	return fmt.Errorf("%s sequence: %w", seq.String(), runtime.ErrUnconfigured)
 }
 
 func (c *Controller) phases(seq runtime.Sequence, data interface{}) ([]runtime.Phase, error) {
+	return sequencePhases(c.s, c.r, seq, data)
+}
+
+// sequencePhases returns the phases of the sequence built by the sequencer
+// for the runtime, with the data of the request starting the sequence.
+func sequencePhases(s runtime.Sequencer, r runtime.Runtime, seq runtime.Sequence, data interface{}) ([]runtime.Phase, error) {
 	var phases []runtime.Phase
 
 	switch seq {
 	case runtime.SequenceBoot:
-		phases = c.s.Boot(c.r)
+		phases = s.Boot(r)
 	case runtime.SequenceInitialize:
-		phases = c.s.Initialize(c.r)
+		phases = s.Initialize(r)
 	case runtime.SequenceInstall:
-		phases = c.s.Install(c.r)
+		in := &runtime.InstallRequest{}
+
+		if data != nil {
+			var ok bool
+
+			if in, ok = data.(*runtime.InstallRequest); !ok {
+				return nil, runtime.InvalidSequenceData(in, data)
+			}
+		}
+
+		phases = s.Install(r, in)
 	case runtime.SequenceShutdown:
-		phases = c.s.Shutdown(c.r)
+		phases = s.Shutdown(r)
 	case runtime.SequenceReboot:
-		phases = c.s.Reboot(c.r)
+		phases = s.Reboot(r)
+	case runtime.SequenceRollback:
+		phases = s.Rollback(r)
+	case runtime.SequenceRecover:
+		phases = s.Recover(r)
+	case runtime.SequenceMaintenance:
+		phases = s.Maintenance(r)
+	case runtime.SequenceResume:
+		phases = s.Resume(r)
 	case runtime.SequenceUpgrade:
 		var (
 			in *machine.UpgradeRequest
@@ -259,10 +1570,10 @@ func (c *Controller) phases(seq runtime.Sequence, data interface{}) ([]runtime.P
 		)
 
 		if in, ok = data.(*machine.UpgradeRequest); !ok {
-			return nil, runtime.ErrInvalidSequenceData
+			return nil, runtime.InvalidSequenceData(in, data)
 		}
 
-		phases = c.s.Upgrade(c.r, in)
+		phases = s.Upgrade(r, in)
 	case runtime.SequenceReset:
 		var (
 			in *machine.ResetRequest
@@ -270,50 +1581,97 @@ func (c *Controller) phases(seq runtime.Sequence, data interface{}) ([]runtime.P
 		)
 
 		if in, ok = data.(*machine.ResetRequest); !ok {
-			return nil, runtime.ErrInvalidSequenceData
+			return nil, runtime.InvalidSequenceData(in, data)
 		}
 
-		phases = c.s.Reset(c.r, in)
+		phases = s.Reset(r, in)
+	case runtime.SequenceReload:
+		var (
+			in *runtime.ConfigReload
+			ok bool
+		)
+
+		if in, ok = data.(*runtime.ConfigReload); !ok {
+			return nil, runtime.InvalidSequenceData(in, data)
+		}
+
+		phases = s.Reload(r, in)
+	default:
+		return nil, fmt.Errorf("%w: %d", runtime.ErrUnknownSequence, seq)
 	}
 
 	return phases, nil
 }
 
-func waitForUSBDelay() (err error) {
-	wait := true
+// usbDelayFile is the parameter of the usb-storage module holding the number
+// of seconds to wait for USB storage to settle.
+const usbDelayFile = "/sys/module/usb_storage/parameters/delay_use"
 
-	file := "/sys/module/usb_storage/parameters/delay_use"
+// usbWaited is the time waited for USB storage so far.
+var usbWaited time.Duration
 
-	_, err = os.Stat(file)
+// waitForUSBDelay waits for USB storage to settle, so that a disk attached
+// over USB is detected. It only waits for what is left of the delay once
+// NewController waited for it already.
+func waitForUSBDelay(ctx context.Context, cfg runtime.Configurator) error {
+	delay, err := usbDelay(cfg, usbDelayFile)
 	if err != nil {
-		if os.IsNotExist(err) {
-			wait = false
-		} else {
-			return err
-		}
+		return err
+	}
+
+	if delay -= usbWaited; delay <= 0 {
+		return nil
+	}
+
+	log.Printf("waiting %s for USB storage", delay)
+
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 
-	if wait {
-		var b []byte
+	usbWaited += delay
 
-		b, err = ioutil.ReadFile(file)
+	return nil
+}
+
+// usbDelay returns the time to wait for USB storage. The environment takes
+// precedence over the config, and the delay of the usb-storage module is used
+// when neither sets one.
+func usbDelay(cfg runtime.Configurator, file string) (time.Duration, error) {
+	if val, ok := os.LookupEnv(constants.USBDelayEnvVar); ok {
+		delay, err := time.ParseDuration(val)
 		if err != nil {
-			return err
+			return 0, fmt.Errorf("invalid %s: %w", constants.USBDelayEnvVar, err)
 		}
 
-		val := strings.TrimSuffix(string(b), "\n")
+		return delay, nil
+	}
 
-		var i int
+	if cfg != nil {
+		if !cfg.Machine().Install().WaitForUSB() {
+			return 0, nil
+		}
 
-		i, err = strconv.Atoi(val)
-		if err != nil {
-			return err
+		if delay := cfg.Machine().Install().USBDelay(); delay != 0 {
+			return delay, nil
+		}
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
 		}
 
-		log.Printf("waiting %d second(s) for USB storage", i)
+		return 0, err
+	}
 
-		time.Sleep(time.Duration(i) * time.Second)
+	i, err := strconv.Atoi(strings.TrimSuffix(string(b), "\n"))
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	return time.Duration(i) * time.Second, nil
 }
@@ -6,10 +6,27 @@
 package v1alpha1
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/runtimetest"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/v1alpha1/acpi"
+	"github.com/talos-systems/talos/internal/pkg/kmsg"
+	"github.com/talos-systems/talos/pkg/config/types/v1alpha1"
+	"github.com/talos-systems/talos/pkg/constants"
 )
 
 func TestNewController(t *testing.T) {
@@ -75,6 +92,65 @@ func TestController_Run(t *testing.T) {
 	}
 }
 
+func TestController_RunPhase_RequiresDebug(t *testing.T) {
+	c := &Controller{
+		r: NewRuntime(&v1alpha1.Config{}, nil),
+		s: &Sequencer{},
+	}
+
+	if err := c.RunPhase(runtime.SequenceBoot, "1", nil); !errors.Is(err, runtime.ErrDebugRequired) {
+		t.Errorf("Controller.RunPhase() error = %v, want %v", err, runtime.ErrDebugRequired)
+	}
+}
+
+func TestController_Run_RecoverAfterBoot(t *testing.T) {
+	c := &Controller{
+		r:      NewRuntime(&v1alpha1.Config{}, nil),
+		s:      &Sequencer{},
+		booted: 1,
+	}
+
+	if err := c.Run(runtime.SequenceRecover, nil); !errors.Is(err, runtime.ErrBootInProgress) {
+		t.Errorf("Controller.Run() error = %v, want %v", err, runtime.ErrBootInProgress)
+	}
+}
+
+func Test_findPhase(t *testing.T) {
+	phases := PhaseList{}.Append(
+		MountBootPartition,
+	).Append(
+		ValidateConfig,
+		SaveConfig,
+	)
+
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+	}{
+		{name: "1", want: 1},
+		{name: "2", want: 2},
+		{name: "3", wantErr: true},
+		{name: "0", wantErr: true},
+		{name: "SaveConfig", want: 2},
+		{name: "MountBootPartition", want: 1},
+		{name: "StartAllServices", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findPhase(phases, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findPhase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("findPhase() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestController_Runtime(t *testing.T) {
 	type fields struct {
 		r         *Runtime
@@ -162,6 +238,50 @@ func TestController_ListenForEvents(t *testing.T) {
 	}
 }
 
+func TestController_acpiAction(t *testing.T) {
+	power := acpi.Event{DeviceClass: acpi.PowerButtonEvent, BusID: "LNXPWRBN:00", Type: 0x80, Data: 1}
+	lid := acpi.Event{DeviceClass: acpi.LidEvent, BusID: "PNP0C0D:00", Type: 0x80, Data: 1}
+	thermal := acpi.Event{DeviceClass: "thermal_zone", BusID: "LNXTHERM:00", Type: 0x81, Data: 0}
+
+	// Before the config is loaded, the defaults apply.
+	c := &Controller{
+		r: NewRuntime(nil, nil),
+	}
+
+	if got := c.acpiAction(power); got != acpi.ActionShutdown {
+		t.Errorf("Controller.acpiAction() = %v, want %v", got, acpi.ActionShutdown)
+	}
+
+	if got := c.acpiAction(lid); got != acpi.ActionIgnore {
+		t.Errorf("Controller.acpiAction() = %v, want %v", got, acpi.ActionIgnore)
+	}
+
+	c = &Controller{
+		r: NewRuntime(&v1alpha1.Config{
+			MachineConfig: &v1alpha1.MachineConfig{
+				MachineACPI: &v1alpha1.ACPIConfig{
+					ACPIActions: map[string]string{
+						acpi.LidEvent:  "reboot",
+						"thermal_zone": "maintenance",
+					},
+				},
+			},
+		}, nil),
+	}
+
+	if got := c.acpiAction(power); got != acpi.ActionShutdown {
+		t.Errorf("Controller.acpiAction() = %v, want %v", got, acpi.ActionShutdown)
+	}
+
+	if got := c.acpiAction(lid); got != acpi.ActionReboot {
+		t.Errorf("Controller.acpiAction() = %v, want %v", got, acpi.ActionReboot)
+	}
+
+	if got := c.acpiAction(thermal); got != acpi.ActionMaintenance {
+		t.Errorf("Controller.acpiAction() = %v, want %v", got, acpi.ActionMaintenance)
+	}
+}
+
 func TestController_TryLock(t *testing.T) {
 	type fields struct {
 		r         *Runtime
@@ -249,13 +369,78 @@ func TestController_run(t *testing.T) {
 				s:         tt.fields.s,
 				semaphore: tt.fields.semaphore,
 			}
-			if err := c.run(tt.args.seq, tt.args.phases, tt.args.data); (err != nil) != tt.wantErr {
+			if err := c.run(context.Background(), tt.args.seq, tt.args.phases, tt.args.data); (err != nil) != tt.wantErr {
 				t.Errorf("Controller.run() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+func TestController_run_ErrorPolicy(t *testing.T) {
+	defer discardTaskLogs()()
+
+	var ran int32
+
+	failing := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			atomic.AddInt32(&ran, 1)
+
+			return errors.New("failed")
+		}
+	}
+
+	phases := []runtime.Phase{{failing}, {failing}, {failing}}
+
+	tests := []struct {
+		name     string
+		seq      runtime.Sequence
+		wantRan  int32
+		wantErrs int
+	}{
+		{
+			name:     "fail fast",
+			seq:      runtime.SequenceBoot,
+			wantRan:  1,
+			wantErrs: 1,
+		},
+		{
+			name:     "collect",
+			seq:      runtime.SequenceShutdown,
+			wantRan:  3,
+			wantErrs: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&ran, 0)
+
+			c := &Controller{
+				s: &Sequencer{},
+			}
+
+			err := c.run(context.Background(), tt.seq, phases, nil)
+			if err == nil {
+				t.Fatal("Controller.run() error = nil, want error")
+			}
+
+			if got := atomic.LoadInt32(&ran); got != tt.wantRan {
+				t.Errorf("Controller.run() ran %d phase(s), want %d", got, tt.wantRan)
+			}
+
+			errs := 1
+
+			if list, ok := err.(errorList); ok {
+				errs = len(list.errs)
+			}
+
+			if errs != tt.wantErrs {
+				t.Errorf("Controller.run() returned %d error(s), want %d", errs, tt.wantErrs)
+			}
+		})
+	}
+}
+
 func TestController_runPhase(t *testing.T) {
 	type fields struct {
 		r         *Runtime
@@ -285,13 +470,462 @@ func TestController_runPhase(t *testing.T) {
 				s:         tt.fields.s,
 				semaphore: tt.fields.semaphore,
 			}
-			if err := c.runPhase(tt.args.phase, tt.args.seq, tt.args.data); (err != nil) != tt.wantErr {
+			if err := c.runPhase(context.Background(), tt.args.phase, tt.args.seq, tt.args.data); (err != nil) != tt.wantErr {
 				t.Errorf("Controller.runPhase() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+func TestController_runPhase_Cancel(t *testing.T) {
+	defer discardTaskLogs()()
+
+	canceled := make(chan struct{})
+
+	phase := runtime.Phase{
+		func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+			return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+				select {
+				case <-ctx.Done():
+					close(canceled)
+
+					return ctx.Err()
+				case <-time.After(10 * time.Second):
+					return nil
+				}
+			}
+		},
+		func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+			return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+				return errors.New("failed")
+			}
+		},
+	}
+
+	c := &Controller{}
+
+	start := time.Now()
+
+	if err := c.runPhase(context.Background(), phase, runtime.SequenceNoop, nil); err == nil {
+		t.Fatal("Controller.runPhase() error = nil, want error")
+	}
+
+	select {
+	case <-canceled:
+	default:
+		t.Fatal("long running task was not canceled")
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Controller.runPhase() took %s, want prompt cancellation", elapsed)
+	}
+}
+
+func TestController_runPhase_Errors(t *testing.T) {
+	defer discardTaskLogs()()
+
+	errMount := errors.New("mount failed")
+	errDisk := errors.New("disk not found")
+
+	// Both tasks fail at once, for the same root cause.
+	var started sync.WaitGroup
+
+	started.Add(2)
+
+	failing := func(err error) runtime.TaskSetupFunc {
+		return func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+			return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+				started.Done()
+				started.Wait()
+
+				return err
+			}
+		}
+	}
+
+	blocking := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			<-ctx.Done()
+
+			return ctx.Err()
+		}
+	}
+
+	c := &Controller{}
+
+	err := c.runPhase(context.Background(), runtime.Phase{failing(errDisk), blocking, failing(errMount)}, runtime.SequenceNoop, nil)
+	if err == nil {
+		t.Fatal("Controller.runPhase() error = nil, want error")
+	}
+
+	if !errors.Is(err, errDisk) || !errors.Is(err, errMount) {
+		t.Errorf("Controller.runPhase() error = %v, want both of the task errors", err)
+	}
+
+	// The task canceled by the failures is not reported.
+	if want := "2 tasks failed: task 1/3: failed, disk not found; task 3/3: failed, mount failed"; err.Error() != want {
+		t.Errorf("Controller.runPhase() error = %q, want %q", err, want)
+	}
+
+	// A single failure is returned as is.
+	err = c.runPhase(context.Background(), runtime.Phase{blocking, func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			return errDisk
+		}
+	}}, runtime.SequenceNoop, nil)
+	if want := "task 2/2: failed, disk not found"; err == nil || err.Error() != want {
+		t.Errorf("Controller.runPhase() error = %v, want %q", err, want)
+	}
+}
+
+func TestController_runPhase_MaxParallelTasks(t *testing.T) {
+	defer discardTaskLogs()()
+
+	var running, max int32
+
+	task := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+
+			time.Sleep(50 * time.Millisecond)
+
+			return nil
+		}
+	}
+
+	phase := runtime.Phase{task, task, task, task}
+
+	cfg := &v1alpha1.Config{
+		MachineConfig: &v1alpha1.MachineConfig{
+			MachineSequences: v1alpha1.SequencesConfig{
+				"install": {SequenceMaxParallelTasks: 1},
+			},
+		},
+	}
+
+	c := &Controller{
+		r: NewRuntime(cfg, nil),
+	}
+
+	for _, tt := range []struct {
+		seq   runtime.Sequence
+		limit int
+		want  int32
+	}{
+		{seq: runtime.SequenceInstall, want: 1},
+		{seq: runtime.SequenceBoot, want: int32(len(phase))},
+		{seq: runtime.SequenceInstall, limit: 3, want: 1},
+		{seq: runtime.SequenceBoot, limit: 2, want: 2},
+	} {
+		c.SetMaxParallelTasks(tt.limit)
+
+		atomic.StoreInt32(&max, 0)
+
+		if err := c.runPhase(context.Background(), phase, tt.seq, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := atomic.LoadInt32(&max); got != tt.want {
+			t.Errorf("%s (limit %d): %d task(s) ran concurrently, want %d", tt.seq, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestController_runPhase_Timeout(t *testing.T) {
+	defer discardTaskLogs()()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	// The task ignores the cancellation of its context.
+	hung := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			<-release
+
+			return nil
+		}
+	}
+
+	c := &Controller{}
+
+	err := c.runPhase(context.Background(), runtime.Phase{runtime.WithTimeout(hung, 10*time.Millisecond)}, runtime.SequenceNoop, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Controller.runPhase() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if !strings.Contains(err.Error(), "task 1/1") {
+		t.Errorf("Controller.runPhase() error = %q, want the task number", err)
+	}
+}
+
+// hungTask never returns, whatever its context. It is declared at the top
+// level, so that it has a stable name to set the timeout of.
+func hungTask(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+		select {}
+	}
+}
+
+func TestController_runPhase_TaskTimeout(t *testing.T) {
+	defer discardTaskLogs()()
+
+	cfg := &v1alpha1.Config{
+		MachineConfig: &v1alpha1.MachineConfig{
+			MachineTaskTimeouts: map[string]time.Duration{"hungTask": 10 * time.Millisecond},
+		},
+	}
+
+	c := &Controller{
+		r: NewRuntime(cfg, nil),
+	}
+
+	err := c.runPhase(context.Background(), runtime.Phase{hungTask}, runtime.SequenceNoop, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Controller.runPhase() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// Unknown tasks are warned about, and ignored.
+	cfg.MachineConfig.MachineTaskTimeouts = map[string]time.Duration{
+		"Install":    time.Minute,
+		"hungTask":   time.Minute,
+		"NoSuchTask": time.Minute,
+	}
+
+	logger := &recordingLogger{}
+	c.SetLogger(logger)
+
+	c.warnUnknownTaskTimeouts(runtime.SequenceInstall, []runtime.Phase{{hungTask}})
+
+	var warned []interface{}
+
+	for _, e := range logger.entries {
+		if e.level == runtime.LevelWarn {
+			warned = append(warned, e.fields["name"])
+		}
+	}
+
+	if !reflect.DeepEqual(warned, []interface{}{"NoSuchTask"}) {
+		t.Errorf("warned about the timeouts of %v, want [NoSuchTask]", warned)
+	}
+}
+
+// slowTask takes longer than the built-in timeout it reports the override of.
+// It is declared at the top level, so that it has a stable name to set the
+// timeout of.
+func slowTask(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+		if timeout := runtime.TaskTimeout(ctx, time.Millisecond); timeout != time.Second {
+			return fmt.Errorf("task timeout = %s, want %s", timeout, time.Second)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+
+		return ctx.Err()
+	}
+}
+
+func TestController_runPhase_TaskTimeoutOverrides(t *testing.T) {
+	defer discardTaskLogs()()
+
+	cfg := &v1alpha1.Config{
+		MachineConfig: &v1alpha1.MachineConfig{
+			MachineTaskTimeouts: map[string]time.Duration{"slowTask": time.Second},
+		},
+	}
+
+	c := &Controller{
+		r: NewRuntime(cfg, nil),
+	}
+
+	// The task timeout replaces the built-in timeout of the task, and extends
+	// that of the phase.
+	phase := runtime.Phase{slowTask, runtime.PhaseTimeout(10*time.Millisecond, runtime.PhaseTimeoutAbort)}
+
+	if err := c.runPhase(context.Background(), phase, runtime.SequenceNoop, nil); err != nil {
+		t.Fatalf("Controller.runPhase() error = %v", err)
+	}
+}
+
+func TestController_run_Canceled(t *testing.T) {
+	defer discardTaskLogs()()
+
+	var ran int32
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	phases := []runtime.Phase{
+		{
+			func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+				return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+					cancel()
+
+					return nil
+				}
+			},
+		},
+		{
+			func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+				return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+					atomic.AddInt32(&ran, 1)
+
+					return nil
+				}
+			},
+		},
+	}
+
+	c := &Controller{
+		s: NewSequencer(),
+	}
+
+	if err := c.run(ctx, runtime.SequenceNoop, phases, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Controller.run() error = %v, want %v", err, context.Canceled)
+	}
+
+	if atomic.LoadInt32(&ran) != 0 {
+		t.Error("phase after cancellation was run")
+	}
+}
+
+func TestController_runTask_Retry(t *testing.T) {
+	defer discardTaskLogs()()
+
+	flaky := func(failures int32, err error) (runtime.TaskSetupFunc, *int32) {
+		var calls int32
+
+		return func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+			return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+				if atomic.AddInt32(&calls, 1) <= failures {
+					return err
+				}
+
+				return nil
+			}
+		}, &calls
+	}
+
+	c := &Controller{}
+
+	for _, tt := range []struct {
+		name      string
+		failures  int32
+		err       error
+		wantErr   bool
+		wantCalls int32
+	}{
+		{name: "succeeds after retries", failures: 2, err: errors.New("flaky"), wantErr: false, wantCalls: 3},
+		{name: "exhausts attempts", failures: 5, err: errors.New("flaky"), wantErr: true, wantCalls: 3},
+		{name: "reboot is not retried", failures: 5, err: runtime.ErrReboot, wantErr: true, wantCalls: 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			task, calls := flaky(tt.failures, tt.err)
+
+			err := c.runTask(context.Background(), 1, runtime.WithRetry(task, 3, time.Millisecond), runtime.SequenceNoop, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Controller.runTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := atomic.LoadInt32(calls); got != tt.wantCalls {
+				t.Errorf("task was attempted %d time(s), want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestController_preempt(t *testing.T) {
+	c := &Controller{}
+
+	if c.TryLock() {
+		t.Fatal("Controller.TryLock() = true, want false")
+	}
+
+	ctx, cancel := c.sequenceContext(runtime.SequenceUpgrade)
+
+	go func() {
+		<-ctx.Done()
+
+		cancel()
+		c.Unlock()
+	}()
+
+	if !c.preempt(runtime.SequenceShutdown) {
+		t.Fatal("Controller.preempt() = false, want true")
+	}
+
+	if !c.Unlock() {
+		t.Error("Controller.Unlock() after preempt = false, want true")
+	}
+}
+
+func Test_taskPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		phase, phases int
+		want          string
+	}{
+		{phase: 3, phases: 7, want: "[talos] upgrade 3/7 task 2:"},
+		{want: "[talos] upgrade task 2:"},
+	} {
+		if got := taskPrefix(runtime.SequenceUpgrade, tt.phase, tt.phases, 2); got != tt.want {
+			t.Errorf("taskPrefix() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestController_run_TaskPrefix(t *testing.T) {
+	setup := setupTaskLogger
+
+	defer func() { setupTaskLogger = setup }()
+
+	var (
+		mu       sync.Mutex
+		prefixes []string
+	)
+
+	setupTaskLogger = func(logger *log.Logger, prefix string, level runtime.Level, _ ...kmsg.Option) error {
+		logger.SetOutput(ioutil.Discard)
+
+		mu.Lock()
+		prefixes = append(prefixes, prefix)
+		mu.Unlock()
+
+		return nil
+	}
+
+	noop := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			return nil
+		}
+	}
+
+	c := &Controller{
+		s: &Sequencer{},
+	}
+
+	c.SetLogger(&recordingLogger{})
+
+	phases := PhaseList{}.Append(noop).Append(noop, noop)
+
+	if err := c.run(context.Background(), runtime.SequenceReboot, phases, nil); err != nil {
+		t.Fatalf("Controller.run() error = %v", err)
+	}
+
+	sort.Strings(prefixes)
+
+	want := []string{"[talos] reboot 1/2 task 1:", "[talos] reboot 2/2 task 1:", "[talos] reboot 2/2 task 2:"}
+
+	if !reflect.DeepEqual(prefixes, want) {
+		t.Errorf("task prefixes = %q, want %q", prefixes, want)
+	}
+}
+
 func TestController_runTask(t *testing.T) {
 	type fields struct {
 		r         *Runtime
@@ -322,7 +956,7 @@ func TestController_runTask(t *testing.T) {
 				s:         tt.fields.s,
 				semaphore: tt.fields.semaphore,
 			}
-			if err := c.runTask(tt.args.n, tt.args.f, tt.args.seq, tt.args.data); (err != nil) != tt.wantErr {
+			if err := c.runTask(context.Background(), tt.args.n, tt.args.f, tt.args.seq, tt.args.data); (err != nil) != tt.wantErr {
 				t.Errorf("Controller.runTask() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -347,8 +981,43 @@ func TestController_phases(t *testing.T) {
 		args    args
 		want    []runtime.Phase
 		wantErr bool
+		errIs   error
 	}{
-		// TODO: Add test cases.
+		{
+			name:    "unknown sequence",
+			fields:  fields{s: &Sequencer{}},
+			args:    args{seq: runtime.Sequence(42)},
+			wantErr: true,
+			errIs:   runtime.ErrUnknownSequence,
+		},
+		{
+			name:    "upgrade with wrong data",
+			fields:  fields{s: &Sequencer{}},
+			args:    args{seq: runtime.SequenceUpgrade, data: &runtime.ConfigReload{}},
+			wantErr: true,
+			errIs:   runtime.ErrInvalidSequenceData,
+		},
+		{
+			name:    "reset without data",
+			fields:  fields{s: &Sequencer{}},
+			args:    args{seq: runtime.SequenceReset},
+			wantErr: true,
+			errIs:   runtime.ErrInvalidSequenceData,
+		},
+		{
+			name:    "reload with wrong data",
+			fields:  fields{s: &Sequencer{}},
+			args:    args{seq: runtime.SequenceReload, data: "config"},
+			wantErr: true,
+			errIs:   runtime.ErrInvalidSequenceData,
+		},
+		{
+			name:    "install with wrong data",
+			fields:  fields{s: &Sequencer{}},
+			args:    args{seq: runtime.SequenceInstall, data: "force"},
+			wantErr: true,
+			errIs:   runtime.ErrInvalidSequenceData,
+		},
 	}
 
 	for _, tt := range tests {
@@ -363,6 +1032,9 @@ func TestController_phases(t *testing.T) {
 				t.Errorf("Controller.phases() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf("Controller.phases() error = %v, want %v", err, tt.errIs)
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Controller.phases() = %v, want %v", got, tt.want)
 			}
@@ -370,19 +1042,808 @@ func TestController_phases(t *testing.T) {
 	}
 }
 
-func Test_waitForUSBDelay(t *testing.T) {
+func Test_usbDelay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "usb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nolint: errcheck
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "delay_use")
+
+	if err = ioutil.WriteFile(file, []byte("5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	disabled := false
+
 	tests := []struct {
 		name    string
+		env     string
+		install *v1alpha1.InstallConfig
+		file    string
+		want    time.Duration
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "sysfs",
+			file: file,
+			want: 5 * time.Second,
+		},
+		{
+			name: "no usb-storage",
+			file: filepath.Join(dir, "missing"),
+			want: 0,
+		},
+		{
+			name:    "config",
+			install: &v1alpha1.InstallConfig{InstallUSBDelay: time.Second},
+			file:    file,
+			want:    time.Second,
+		},
+		{
+			name:    "disabled",
+			install: &v1alpha1.InstallConfig{InstallWaitForUSB: &disabled, InstallUSBDelay: time.Second},
+			file:    file,
+			want:    0,
+		},
+		{
+			name:    "env",
+			env:     "0",
+			install: &v1alpha1.InstallConfig{InstallUSBDelay: time.Second},
+			file:    file,
+			want:    0,
+		},
+		{
+			name:    "invalid env",
+			env:     "soon",
+			file:    file,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := waitForUSBDelay(); (err != nil) != tt.wantErr {
-				t.Errorf("waitForUSBDelay() error = %v, wantErr %v", err, tt.wantErr)
+			if tt.env != "" {
+				if err := os.Setenv(constants.USBDelayEnvVar, tt.env); err != nil {
+					t.Fatal(err)
+				}
+
+				// nolint: errcheck
+				defer os.Unsetenv(constants.USBDelayEnvVar)
 			}
-		})
+
+			cfg := &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{MachineInstall: tt.install}}
+
+			got, err := usbDelay(cfg, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("usbDelay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("usbDelay() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if got, err := usbDelay(nil, file); err != nil || got != 5*time.Second {
+		t.Errorf("usbDelay() without a config = %s, %v, want 5s", got, err)
+	}
+}
+
+func Test_waitForUSBDelay(t *testing.T) {
+	if err := os.Setenv(constants.USBDelayEnvVar, "1h"); err != nil {
+		t.Fatal(err)
+	}
+
+	// nolint: errcheck
+	defer os.Unsetenv(constants.USBDelayEnvVar)
+
+	defer func(waited time.Duration) { usbWaited = waited }(usbWaited)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	usbWaited = 0
+
+	if err := waitForUSBDelay(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("waitForUSBDelay() error = %v, want %v", err, context.Canceled)
+	}
+
+	usbWaited = time.Hour
+
+	if err := waitForUSBDelay(ctx, nil); err != nil {
+		t.Errorf("waitForUSBDelay() once waited error = %v, want none", err)
+	}
+}
+
+func Test_overlapGroups(t *testing.T) {
+	task := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc { return nil }
+
+	tests := []struct {
+		name   string
+		phases PhaseList
+		want   [][]int
+	}{
+		{
+			name:   "serial",
+			phases: PhaseList{}.Append(task).Append(task).Append(task),
+			want:   [][]int{{0}, {1}, {2}},
+		},
+		{
+			name:   "overlap with next",
+			phases: PhaseList{}.AppendOverlapping(task).Append(task).Append(task),
+			want:   [][]int{{0, 1}, {2}},
+		},
+		{
+			name:   "chained overlaps",
+			phases: PhaseList{}.Append(task).AppendOverlapping(task).AppendOverlapping(task).Append(task),
+			want:   [][]int{{0}, {1, 2, 3}},
+		},
+		{
+			name:   "overlapping last phase",
+			phases: PhaseList{}.Append(task).AppendOverlapping(task),
+			want:   [][]int{{0}, {1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overlapGroups(tt.phases); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("overlapGroups() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_run_Overlap(t *testing.T) {
+	defer discardTaskLogs()()
+
+	started := make(chan struct{})
+
+	// The first phase only completes once the second one has started.
+	waiting := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			select {
+			case <-started:
+				return nil
+			case <-time.After(time.Second):
+				return errors.New("next phase did not start")
+			}
+		}
+	}
+
+	starting := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			close(started)
+
+			return nil
+		}
+	}
+
+	c := &Controller{
+		s: &Sequencer{},
+	}
+
+	phases := PhaseList{}.AppendOverlapping(waiting).Append(starting)
+
+	if err := c.run(context.Background(), runtime.SequenceBoot, phases, nil); err != nil {
+		t.Fatalf("Controller.run() error = %v", err)
+	}
+
+	trace := c.LastTrace()
+
+	if len(trace.Phases) != 2 {
+		t.Fatalf("trace has %d phase(s), want 2", len(trace.Phases))
+	}
+
+	for i, phase := range trace.Phases {
+		if len(phase.Tasks) != 1 {
+			t.Errorf("trace phase %d has %d task(s), want 1", i+1, len(phase.Tasks))
+		}
+	}
+}
+
+func TestController_run_OverlapFailFast(t *testing.T) {
+	defer discardTaskLogs()()
+
+	failing := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			return errors.New("failed")
+		}
+	}
+
+	blocking := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+				return nil
+			}
+		}
+	}
+
+	c := &Controller{
+		s: &Sequencer{},
+	}
+
+	phases := PhaseList{}.AppendOverlapping(blocking).Append(failing)
+
+	start := time.Now()
+
+	err := c.run(context.Background(), runtime.SequenceBoot, phases, nil)
+	if err == nil || !strings.Contains(err.Error(), "phase 2") {
+		t.Fatalf("Controller.run() error = %v, want the error of phase 2", err)
+	}
+
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("Controller.run() took %s, want the overlapping phase to be canceled", elapsed)
+	}
+}
+
+func TestController_CurrentSequence(t *testing.T) {
+	defer discardTaskLogs()()
+
+	c := &Controller{
+		s: &Sequencer{},
+	}
+
+	if _, ok := c.CurrentSequence(); ok {
+		t.Fatal("Controller.CurrentSequence() reported a sequence before any ran")
+	}
+
+	var (
+		status runtime.SequenceStatus
+		ok     bool
+	)
+
+	noop := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			return nil
+		}
+	}
+
+	query := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			status, ok = c.CurrentSequence()
+
+			return nil
+		}
+	}
+
+	ctx, cancel := c.sequenceContext(runtime.SequenceBoot)
+
+	if err := c.run(ctx, runtime.SequenceBoot, []runtime.Phase{{noop}, {query}, {noop}}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+
+	if !ok {
+		t.Fatal("Controller.CurrentSequence() reported no sequence while one ran")
+	}
+
+	if status.Sequence != runtime.SequenceBoot || status.Phase != 2 || status.PhaseTotal != 3 || status.Start.IsZero() {
+		t.Errorf("Controller.CurrentSequence() = %+v, want phase 2/3 of the boot sequence", status)
+	}
+
+	if _, ok = c.CurrentSequence(); ok {
+		t.Error("Controller.CurrentSequence() reported a sequence after it completed")
+	}
+}
+
+func TestController_runPhase_PhaseTimeout(t *testing.T) {
+	defer discardTaskLogs()()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	// stuck ignores the cancellation of its context.
+	stuck := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			<-release
+
+			return nil
+		}
+	}
+
+	c := &Controller{
+		s: &Sequencer{},
+	}
+
+	for _, tt := range []struct {
+		action  runtime.PhaseTimeoutAction
+		wantErr error
+	}{
+		{action: runtime.PhaseTimeoutAbort, wantErr: runtime.ErrPhaseTimeout},
+		{action: runtime.PhaseTimeoutContinue},
+	} {
+		phase := runtime.Phase{stuck, runtime.PhaseTimeout(10*time.Millisecond, tt.action)}
+
+		if len(phase.Tasks()) != 1 {
+			t.Fatalf("phase has %d task(s), want 1", len(phase.Tasks()))
+		}
+
+		err := c.runPhase(context.Background(), phase, runtime.SequenceShutdown, nil)
+
+		if tt.wantErr == nil && err != nil {
+			t.Errorf("%s: Controller.runPhase() error = %v, want nil", tt.action, err)
+		}
+
+		if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: Controller.runPhase() error = %v, want %v", tt.action, err, tt.wantErr)
+		}
+	}
+}
+
+func TestController_lockWait(t *testing.T) {
+	c := &Controller{}
+
+	if c.TryLock() {
+		t.Fatal("expected the lock to be acquired")
+	}
+
+	if c.lockWait(10 * time.Millisecond) {
+		t.Fatal("lockWait() should time out while the lock is held")
+	}
+
+	order := make(chan int, 3)
+
+	for i := 0; i < 3; i++ {
+		i := i
+
+		go func() {
+			if !c.lockWait(time.Second) {
+				order <- -1
+
+				return
+			}
+
+			order <- i
+
+			c.Unlock()
+		}()
+
+		// Wait for the waiter to be queued, so that the order is known.
+		for {
+			c.waitersMu.Lock()
+			n := len(c.waiters)
+			c.waitersMu.Unlock()
+
+			if n == i+1 {
+				break
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	c.Unlock()
+
+	for i := 0; i < 3; i++ {
+		if got := <-order; got != i {
+			t.Fatalf("waiter %d acquired the lock, want %d", got, i)
+		}
+	}
+
+	if c.TryLock() {
+		t.Error("the lock should be released once all waiters are done")
+	}
+}
+
+// configuredRuntime returns a fake runtime with an empty config, for the
+// sequences which require one.
+func configuredRuntime() *runtimetest.Runtime {
+	return runtimetest.NewRuntime(&v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{}}, runtime.ModeMetal)
+}
+
+// discardTaskLogs discards the output of the loggers of the tasks, rather than
+// writing it to the kernel log, and returns the func restoring the setup.
+func discardTaskLogs() func() {
+	setup := setupTaskLogger
+
+	setupTaskLogger = func(logger *log.Logger, prefix string, level runtime.Level, _ ...kmsg.Option) error {
+		logger.SetOutput(ioutil.Discard)
+
+		return nil
+	}
+
+	return func() { setupTaskLogger = setup }
+}
+
+func TestNewControllerWithRuntime(t *testing.T) {
+	defer discardTaskLogs()()
+
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name      string
+		policy    runtime.ErrorPolicy
+		wantOrder []string
+		wantErrs  int
+	}{
+		{
+			name:      "fail fast",
+			policy:    runtime.ErrorPolicyFailFast,
+			wantOrder: []string{"first", "failing"},
+			wantErrs:  1,
+		},
+		{
+			name:      "collect",
+			policy:    runtime.ErrorPolicyCollect,
+			wantOrder: []string{"first", "failing", "last"},
+			wantErrs:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &runtimetest.Recorder{}
+
+			s := runtimetest.NewSequencer().
+				SetPhases(runtime.SequenceBoot,
+					runtime.Phase{rec.Task("first")},
+					runtime.Phase{rec.Fail("failing", failed)},
+					runtime.Phase{rec.Task("last")},
+				).
+				SetErrorPolicy(runtime.SequenceBoot, tt.policy)
+
+			c := NewControllerWithRuntime(configuredRuntime(), s)
+
+			c.SetLogger(&recordingLogger{})
+
+			err := c.Run(runtime.SequenceBoot, nil)
+			if err == nil {
+				t.Fatal("Controller.Run() error = nil, want error")
+			}
+
+			// The collected errors match as the errors of the phases do.
+			if !errors.Is(err, failed) {
+				t.Errorf("Controller.Run() error = %v, want %v", err, failed)
+			}
+
+			errs := []error{err}
+
+			if list, ok := err.(errorList); ok {
+				errs = list.errs
+			}
+
+			if len(errs) != tt.wantErrs {
+				t.Errorf("Controller.Run() returned %d error(s), want %d", len(errs), tt.wantErrs)
+			}
+
+			for _, e := range errs {
+				if !errors.Is(e, failed) {
+					t.Errorf("Controller.Run() error = %v, want %v", e, failed)
+				}
+			}
+
+			if got := rec.Order(); !reflect.DeepEqual(got, tt.wantOrder) {
+				t.Errorf("Controller.Run() ran %v, want %v", got, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestNewControllerWithRuntime_Locked(t *testing.T) {
+	defer discardTaskLogs()()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	rec := &runtimetest.Recorder{}
+
+	s := runtimetest.NewSequencer().
+		SetPhases(runtime.SequenceBoot,
+			runtime.Phase{rec.Func("blocking", func(context.Context, *log.Logger, runtime.Runtime) error {
+				close(started)
+				<-release
+
+				return nil
+			})},
+		).
+		SetPhases(runtime.SequenceRollback, runtime.Phase{rec.Task("rollback")})
+
+	c := NewControllerWithRuntime(configuredRuntime(), s)
+
+	c.SetLogger(&recordingLogger{})
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- c.Run(runtime.SequenceBoot, nil)
+	}()
+
+	<-started
+
+	// A rollback is rejected while another sequence runs.
+	if err := c.Run(runtime.SequenceRollback, nil); !errors.Is(err, runtime.ErrLocked) {
+		t.Errorf("Controller.Run() while locked error = %v, want %v", err, runtime.ErrLocked)
+	}
+
+	close(release)
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("Controller.Run() error = %v", err)
+	}
+
+	if err := c.Run(runtime.SequenceRollback, nil); err != nil {
+		t.Fatalf("Controller.Run() after unlock error = %v", err)
+	}
+
+	if got, want := rec.Order(), []string{"blocking", "rollback"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Controller.Run() ran %v, want %v", got, want)
+	}
+}
+
+func TestController_RunConflicting(t *testing.T) {
+	defer discardTaskLogs()()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	rec := &runtimetest.Recorder{}
+
+	s := runtimetest.NewSequencer().
+		SetPhases(runtime.SequenceBoot,
+			runtime.Phase{rec.Func("boot", func(ctx context.Context, _ *log.Logger, _ runtime.Runtime) error {
+				close(started)
+
+				select {
+				case <-release:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})},
+		).
+		SetPhases(runtime.SequenceInstall, runtime.Phase{rec.Task("install")}).
+		SetPhases(runtime.SequenceShutdown, runtime.Phase{rec.Task("shutdown")})
+
+	c := NewControllerWithRuntime(configuredRuntime(), s)
+
+	c.SetLogger(&recordingLogger{})
+
+	bootErr := make(chan error, 1)
+
+	go func() {
+		bootErr <- c.Run(runtime.SequenceBoot, nil)
+	}()
+
+	<-started
+
+	// An install requested during the boot waits for it.
+	installErr := make(chan error, 1)
+
+	go func() {
+		installErr <- c.Run(runtime.SequenceInstall, &runtime.InstallRequest{})
+	}()
+
+	select {
+	case err := <-installErr:
+		t.Fatalf("Controller.Run() of the install returned %v during the boot, want it queued", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+
+	if err := <-bootErr; err != nil {
+		t.Fatalf("Controller.Run() of the boot error = %v", err)
+	}
+
+	if err := <-installErr; err != nil {
+		t.Fatalf("Controller.Run() of the install error = %v", err)
+	}
+
+	if got, want := rec.Order(), []string{"boot", "install"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Controller.Run() ran %v, want %v", got, want)
+	}
+
+	// A shutdown requested during the boot preempts it.
+	started = make(chan struct{})
+	release = make(chan struct{})
+
+	go func() {
+		bootErr <- c.Run(runtime.SequenceBoot, nil)
+	}()
+
+	<-started
+
+	if err := c.Run(runtime.SequenceShutdown, nil); err != nil {
+		t.Fatalf("Controller.Run() of the shutdown error = %v", err)
+	}
+
+	if err := <-bootErr; err == nil {
+		t.Error("Controller.Run() of the preempted boot error = nil, want the boot canceled")
+	}
+
+	if got, want := rec.Order(), []string{"boot", "install", "boot", "shutdown"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Controller.Run() ran %v, want %v", got, want)
+	}
+}
+
+func TestController_Abort(t *testing.T) {
+	defer discardTaskLogs()()
+
+	tests := []struct {
+		name    string
+		seq     runtime.Sequence
+		phases  func(blocking runtime.TaskSetupFunc, rec *runtimetest.Recorder) []runtime.Phase
+		wantErr error
+		wantRan []string
+	}{
+		{
+			name: "abortable",
+			seq:  runtime.SequenceInstall,
+			phases: func(blocking runtime.TaskSetupFunc, rec *runtimetest.Recorder) []runtime.Phase {
+				return []runtime.Phase{{blocking}, {rec.Task("install"), runtime.PointOfNoReturn}}
+			},
+			wantRan: []string{"blocking"},
+		},
+		{
+			name: "not abortable",
+			seq:  runtime.SequenceBoot,
+			phases: func(blocking runtime.TaskSetupFunc, rec *runtimetest.Recorder) []runtime.Phase {
+				return []runtime.Phase{{blocking}, {rec.Task("services")}}
+			},
+			wantErr: runtime.ErrNotAbortable,
+			wantRan: []string{"blocking", "services"},
+		},
+		{
+			name: "past the point of no return",
+			seq:  runtime.SequenceInstall,
+			phases: func(blocking runtime.TaskSetupFunc, rec *runtimetest.Recorder) []runtime.Phase {
+				return []runtime.Phase{{rec.Task("validate")}, {blocking, runtime.PointOfNoReturn}, {rec.Task("install")}}
+			},
+			wantErr: runtime.ErrNotAbortable,
+			wantRan: []string{"validate", "blocking", "install"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			release := make(chan struct{})
+
+			rec := &runtimetest.Recorder{}
+
+			blocking := rec.Func("blocking", func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+				close(started)
+
+				select {
+				case <-ctx.Done():
+				case <-release:
+				}
+
+				return nil
+			})
+
+			s := runtimetest.NewSequencer().SetPhases(tt.seq, tt.phases(blocking, rec)...)
+
+			c := NewControllerWithRuntime(configuredRuntime(), s)
+
+			c.SetLogger(&recordingLogger{})
+
+			if _, err := c.Abort(); !errors.Is(err, runtime.ErrNotRunning) {
+				t.Fatalf("Controller.Abort() with no sequence running error = %v, want %v", err, runtime.ErrNotRunning)
+			}
+
+			errCh := make(chan error, 1)
+
+			go func() {
+				errCh <- c.Run(tt.seq, nil)
+			}()
+
+			<-started
+
+			seq, err := c.Abort()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Controller.Abort() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if seq != tt.seq {
+				t.Errorf("Controller.Abort() = %s, want %s", seq, tt.seq)
+			}
+
+			close(release)
+
+			err = <-errCh
+
+			switch {
+			case tt.wantErr == nil && !errors.Is(err, context.Canceled):
+				t.Errorf("Controller.Run() of an aborted sequence error = %v, want %v", err, context.Canceled)
+			case tt.wantErr != nil && err != nil:
+				t.Errorf("Controller.Run() error = %v", err)
+			}
+
+			if got := rec.Order(); !reflect.DeepEqual(got, tt.wantRan) {
+				t.Errorf("Controller.Run() ran %v, want %v", got, tt.wantRan)
+			}
+		})
+	}
+}
+
+func TestController_RunCleanups(t *testing.T) {
+	defer discardTaskLogs()()
+
+	cleaned := 0
+
+	register := func(ctx context.Context, _ *log.Logger, _ runtime.Runtime) error {
+		runtime.RegisterCleanup(ctx, "count", func(context.Context) error {
+			cleaned++
+
+			return nil
+		})
+
+		return nil
+	}
+
+	rec := &runtimetest.Recorder{}
+
+	s := runtimetest.NewSequencer().
+		SetPhases(runtime.SequenceShutdown,
+			runtime.Phase{rec.Func("unmount", register)},
+		).
+		SetPhases(runtime.SequenceReboot,
+			runtime.Phase{rec.Func("unmount", register)},
+			runtime.Phase{rec.Fail("reboot", errors.New("operation not permitted"))},
+		)
+
+	c := NewControllerWithRuntime(runtimetest.NewRuntime(nil, runtime.ModeMetal), s)
+
+	c.SetLogger(&recordingLogger{})
+
+	if err := c.Run(runtime.SequenceShutdown, nil); err != nil {
+		t.Fatalf("Controller.Run() error = %v", err)
+	}
+
+	if cleaned != 0 {
+		t.Errorf("cleanups ran %d times after a successful sequence, want none", cleaned)
+	}
+
+	if err := c.Run(runtime.SequenceReboot, nil); err == nil {
+		t.Fatal("Controller.Run() error = nil, want the error of the reboot")
+	}
+
+	if cleaned != 1 {
+		t.Errorf("cleanups ran %d times after a failed sequence, want once", cleaned)
+	}
+}
+
+func TestController_Run_Unconfigured(t *testing.T) {
+	defer discardTaskLogs()()
+
+	rec := &runtimetest.Recorder{}
+
+	s := runtimetest.NewSequencer().
+		SetPhases(runtime.SequenceBoot, runtime.Phase{rec.Task("boot")}).
+		SetPhases(runtime.SequenceShutdown, runtime.Phase{rec.Task("shutdown")})
+
+	c := NewControllerWithRuntime(runtimetest.NewRuntime(nil, runtime.ModeContainer), s)
+
+	c.SetLogger(&recordingLogger{})
+
+	if err := c.Run(runtime.SequenceBoot, nil); !errors.Is(err, runtime.ErrUnconfigured) {
+		t.Errorf("Controller.Run() of the boot error = %v, want %v", err, runtime.ErrUnconfigured)
+	}
+
+	if err := c.RunWait(runtime.SequenceBoot, nil, time.Second); !errors.Is(err, runtime.ErrUnconfigured) {
+		t.Errorf("Controller.RunWait() of the boot error = %v, want %v", err, runtime.ErrUnconfigured)
+	}
+
+	if _, err := c.DryRun(runtime.SequenceBoot, nil); !errors.Is(err, runtime.ErrUnconfigured) {
+		t.Errorf("Controller.DryRun() of the boot error = %v, want %v", err, runtime.ErrUnconfigured)
+	}
+
+	// A shutdown does not require the config.
+	if err := c.Run(runtime.SequenceShutdown, nil); err != nil {
+		t.Errorf("Controller.Run() of the shutdown error = %v", err)
+	}
+
+	if got, want := rec.Order(), []string{"shutdown"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Controller.Run() ran %v, want %v", got, want)
 	}
 }
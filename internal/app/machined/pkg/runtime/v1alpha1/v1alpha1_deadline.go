@@ -0,0 +1,184 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+// sequenceTimeout returns the maximum total duration of the sequence set by
+// the config, or zero if there is none. The sequences which are not abortable
+// (see `Sequence.Abortable`) have none, as the config validation rejects it.
+func (c *Controller) sequenceTimeout(seq runtime.Sequence) time.Duration {
+	if c.r == nil || c.r.Config() == nil || !seq.Abortable() {
+		return 0
+	}
+
+	return c.r.Config().Machine().Sequences().Timeout(seq)
+}
+
+// runWithTimeout runs the sequence, and aborts it once the timeout elapses:
+// the context of the sequence is canceled, and the sequence returns once the
+// running tasks observe it, so that no task outlives the lock of the sequence.
+// The error names the phases and tasks that were running. A sequence past its
+// point of no return is not aborted, since it would leave the machine in a
+// state it can not recover from.
+func (c *Controller) runWithTimeout(ctx context.Context, seq runtime.Sequence, timeout time.Duration, run func(context.Context) error) error {
+	if timeout <= 0 {
+		return run(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tasks := &runningTasks{}
+	ctx = withRunningTasks(ctx, tasks)
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- run(ctx)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+	}
+
+	running := tasks.String()
+
+	if !c.cancelBeforeNoReturn(ctx, cancel) {
+		c.log().Warn("sequence timed out past its point of no return, waiting for it", "sequence", seq, "timeout", timeout, "running", running)
+
+		return <-done
+	}
+
+	c.log().Error("sequence timed out, aborting", "sequence", seq, "timeout", timeout, "running", running)
+
+	err := fmt.Errorf("%s sequence: %w after %s, running %s", seq, runtime.ErrSequenceTimeout, timeout, running)
+
+	slow := time.NewTimer(preemptionTimeout)
+	defer slow.Stop()
+
+	select {
+	case <-done:
+	case <-slow.C:
+		c.log().Warn("timed out sequence did not stop in time, waiting for its tasks", "sequence", seq, "timeout", preemptionTimeout, "running", tasks.String())
+
+		<-done
+	}
+
+	return err
+}
+
+// cancelBeforeNoReturn cancels the context, unless the sequence it belongs
+// to is past its point of no return, and reports whether it did. The status is
+// checked with it locked, as markNoReturn checks the context, so that the
+// sequence is either canceled before the point of no return, or not at all.
+func (c *Controller) cancelBeforeNoReturn(ctx context.Context, cancel context.CancelFunc) bool {
+	noReturn := false
+
+	c.updateStatus(ctx, func(status *runtime.SequenceStatus) {
+		if noReturn = status.NoReturn; !noReturn {
+			cancel()
+		}
+	})
+
+	if noReturn {
+		return false
+	}
+
+	// The contexts without a status are not marked.
+	cancel()
+
+	return true
+}
+
+// runningTasks tracks the tasks of a sequence that are running, including
+// those of its subsequences, to report where a sequence that timed out was
+// stuck. A nil tracker tracks nothing.
+type runningTasks struct {
+	mu    sync.Mutex
+	next  int
+	tasks map[int]string
+}
+
+type runningTasksKey struct{}
+
+// withRunningTasks returns a context in which the running tasks are tracked.
+func withRunningTasks(ctx context.Context, t *runningTasks) context.Context {
+	return context.WithValue(ctx, runningTasksKey{}, t)
+}
+
+// runningTasksFrom returns the tracker of the context, or nil.
+func runningTasksFrom(ctx context.Context) *runningTasks {
+	t, _ := ctx.Value(runningTasksKey{}).(*runningTasks)
+
+	return t
+}
+
+// start tracks the task of the phase as running, until the returned function
+// is called.
+func (t *runningTasks) start(phase, phaseTotal int, name string) func() {
+	if t == nil {
+		return func() {}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.tasks == nil {
+		t.tasks = map[int]string{}
+	}
+
+	id := t.next
+	t.next++
+
+	t.tasks[id] = fmt.Sprintf("%s (phase %d/%d)", name, phase, phaseTotal)
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		delete(t.tasks, id)
+	}
+}
+
+// String returns the running tasks, in the order they started.
+func (t *runningTasks) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.tasks) == 0 {
+		return "no task"
+	}
+
+	ids := make([]int, 0, len(t.tasks))
+
+	for id := range t.tasks {
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+
+	tasks := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		tasks = append(tasks, t.tasks[id])
+	}
+
+	return strings.Join(tasks, ", ")
+}
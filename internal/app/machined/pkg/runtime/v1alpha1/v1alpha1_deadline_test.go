@@ -0,0 +1,157 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// nolint: scopelint
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/runtimetest"
+	"github.com/talos-systems/talos/pkg/config/types/v1alpha1"
+)
+
+// cancelableTask returns once its context is canceled. It is declared at the
+// top level, so that it has a stable name to report.
+func cancelableTask(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}
+}
+
+func TestController_Run_SequenceTimeout(t *testing.T) {
+	defer discardTaskLogs()()
+
+	for _, tt := range []struct {
+		name      string
+		timeout   time.Duration
+		phases    func(rec *runtimetest.Recorder) []runtime.Phase
+		wantErr   string
+		wantOrder []string
+	}{
+		{
+			name:    "timed out",
+			timeout: 50 * time.Millisecond,
+			phases: func(rec *runtimetest.Recorder) []runtime.Phase {
+				return []runtime.Phase{{rec.Task("first")}, {rec.Task("second"), cancelableTask}, {rec.Task("last")}}
+			},
+			wantErr:   "install sequence: sequence timed out after 50ms, running cancelableTask (phase 2/3)",
+			wantOrder: []string{"first", "second"},
+		},
+		{
+			name:    "in time",
+			timeout: time.Minute,
+			phases: func(rec *runtimetest.Recorder) []runtime.Phase {
+				return []runtime.Phase{{rec.Task("first")}, {rec.Task("last")}}
+			},
+			wantOrder: []string{"first", "last"},
+		},
+		{
+			name:    "past the point of no return",
+			timeout: 50 * time.Millisecond,
+			phases: func(rec *runtimetest.Recorder) []runtime.Phase {
+				return []runtime.Phase{{rec.Task("first")}, {rec.Func("install", func(context.Context, *log.Logger, runtime.Runtime) error {
+					time.Sleep(100 * time.Millisecond)
+
+					return nil
+				}), runtime.PointOfNoReturn}}
+			},
+			wantOrder: []string{"first", "install"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &runtimetest.Recorder{}
+
+			s := runtimetest.NewSequencer().SetPhases(runtime.SequenceInstall, tt.phases(rec)...)
+
+			r := runtimetest.NewRuntime(&v1alpha1.Config{
+				MachineConfig: &v1alpha1.MachineConfig{
+					MachineSequences: v1alpha1.SequencesConfig{
+						"install": {SequenceTimeout: tt.timeout},
+					},
+				},
+			}, runtime.ModeMetal)
+
+			c := NewControllerWithRuntime(r, s)
+
+			c.SetLogger(&recordingLogger{})
+
+			err := c.Run(runtime.SequenceInstall, nil)
+
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("Controller.Run() error = %v", err)
+			case tt.wantErr != "" && !errors.Is(err, runtime.ErrSequenceTimeout):
+				t.Fatalf("Controller.Run() error = %v, want %v", err, runtime.ErrSequenceTimeout)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("Controller.Run() error = %q, want %q", err, tt.wantErr)
+			}
+
+			if got := rec.Order(); !reflect.DeepEqual(got, tt.wantOrder) {
+				t.Errorf("Controller.Run() ran %v, want %v", got, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestController_sequenceTimeout(t *testing.T) {
+	r := runtimetest.NewRuntime(&v1alpha1.Config{
+		MachineConfig: &v1alpha1.MachineConfig{
+			MachineSequences: v1alpha1.SequencesConfig{
+				"boot":    {SequenceTimeout: time.Minute},
+				"install": {SequenceTimeout: time.Minute},
+			},
+		},
+	}, runtime.ModeMetal)
+
+	c := NewControllerWithRuntime(r, runtimetest.NewSequencer())
+
+	if got := c.sequenceTimeout(runtime.SequenceInstall); got != time.Minute {
+		t.Errorf("Controller.sequenceTimeout() = %s, want %s", got, time.Minute)
+	}
+
+	// The sequences which are not abortable are never timed out.
+	if got := c.sequenceTimeout(runtime.SequenceBoot); got != 0 {
+		t.Errorf("Controller.sequenceTimeout() = %s, want none", got)
+	}
+}
+
+func Test_runningTasks(t *testing.T) {
+	tasks := &runningTasks{}
+
+	if got := tasks.String(); got != "no task" {
+		t.Errorf("runningTasks.String() = %q, want %q", got, "no task")
+	}
+
+	stopFirst := tasks.start(1, 2, "first")
+	stopSecond := tasks.start(1, 2, "second")
+	stopThird := tasks.start(2, 2, "third")
+
+	stopSecond()
+
+	if want := "first (phase 1/2), third (phase 2/2)"; tasks.String() != want {
+		t.Errorf("runningTasks.String() = %q, want %q", tasks.String(), want)
+	}
+
+	stopFirst()
+	stopThird()
+
+	if got := tasks.String(); got != "no task" {
+		t.Errorf("runningTasks.String() = %q, want %q", got, "no task")
+	}
+
+	// A nil tracker tracks nothing.
+	var none *runningTasks
+
+	none.start(1, 1, "first")()
+}
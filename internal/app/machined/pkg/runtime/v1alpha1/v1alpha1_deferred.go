@@ -0,0 +1,67 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// deferredTasks tracks the tasks running in the background after a sequence
+// has completed. The zero value is ready to use.
+type deferredTasks struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Go runs f in the background. The context passed to f is canceled by
+// `Cancel`.
+func (d *deferredTasks) Go(f func(context.Context)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.ctx == nil {
+		d.ctx, d.cancel = context.WithCancel(context.Background())
+	}
+
+	d.wg.Add(1)
+
+	go func(ctx context.Context) {
+		defer d.wg.Done()
+
+		f(ctx)
+	}(d.ctx)
+}
+
+// Cancel cancels all running tasks and waits up to the specified timeout for
+// them to return. It reports whether all tasks returned in time.
+func (d *deferredTasks) Cancel(timeout time.Duration) bool {
+	d.mu.Lock()
+
+	if d.cancel != nil {
+		d.cancel()
+	}
+
+	d.ctx, d.cancel = nil, nil
+
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
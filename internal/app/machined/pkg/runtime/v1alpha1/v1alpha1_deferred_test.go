@@ -0,0 +1,52 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_deferredTasks_Cancel(t *testing.T) {
+	var d deferredTasks
+
+	if !d.Cancel(time.Second) {
+		t.Fatal("deferredTasks.Cancel() with no tasks = false, want true")
+	}
+
+	canceled := make(chan struct{})
+
+	d.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		close(canceled)
+	})
+
+	if !d.Cancel(time.Second) {
+		t.Fatal("deferredTasks.Cancel() = false, want true")
+	}
+
+	select {
+	case <-canceled:
+	default:
+		t.Fatal("deferred task was not canceled")
+	}
+
+	release := make(chan struct{})
+
+	d.Go(func(ctx context.Context) {
+		<-release
+	})
+
+	if d.Cancel(10 * time.Millisecond) {
+		t.Fatal("deferredTasks.Cancel() with a blocked task = true, want false")
+	}
+
+	close(release)
+
+	if !d.Cancel(time.Second) {
+		t.Fatal("deferredTasks.Cancel() after release = false, want true")
+	}
+}
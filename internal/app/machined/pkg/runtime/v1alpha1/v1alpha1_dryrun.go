@@ -0,0 +1,113 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"github.com/talos-systems/talos/api/machine"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+// DryRun returns the plan of the sequence for the current runtime, without
+// running any of its tasks.
+func (c *Controller) DryRun(seq runtime.Sequence, data interface{}) (*runtime.Plan, error) {
+	if c.r == nil {
+		return nil, runtime.ErrUndefinedRuntime
+	}
+
+	if err := c.checkConfigured(seq); err != nil {
+		return nil, err
+	}
+
+	phases, err := c.phases(seq, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPlan(seq, phases), nil
+}
+
+// describedSequences are the sequences of the catalog, in the order of the
+// lifecycle of a machine, along with the requests they are described for: the
+// phases of some of the sequences depend on the request, e.g. a staged
+// upgrade, so the catalog describes the requests with the default options.
+var describedSequences = []struct {
+	seq  runtime.Sequence
+	data interface{}
+}{
+	{runtime.SequenceInitialize, nil},
+	{runtime.SequenceInstall, &runtime.InstallRequest{}},
+	{runtime.SequenceBoot, nil},
+	{runtime.SequenceUpgrade, &machine.UpgradeRequest{}},
+	{runtime.SequenceReload, &runtime.ConfigReload{}},
+	{runtime.SequenceRollback, nil},
+	{runtime.SequenceRecover, nil},
+	{runtime.SequenceMaintenance, nil},
+	{runtime.SequenceResume, nil},
+	{runtime.SequenceReset, &machine.ResetRequest{Graceful: true}},
+	{runtime.SequenceReboot, nil},
+	{runtime.SequenceShutdown, nil},
+}
+
+// Describe returns the plans of all of the sequences for the runtime, which
+// is the catalog of what each sequence does.
+func (s *Sequencer) Describe(r runtime.Runtime) []*runtime.Plan {
+	return describe(s, r)
+}
+
+// Describe returns the plans of all of the sequences for the current runtime.
+// The config must be loaded, as most of the sequences depend on it.
+func (c *Controller) Describe() ([]*runtime.Plan, error) {
+	if c.r == nil {
+		return nil, runtime.ErrUndefinedRuntime
+	}
+
+	if c.r.Config() == nil {
+		return nil, runtime.ErrUnconfigured
+	}
+
+	return describe(c.s, c.r), nil
+}
+
+func describe(s runtime.Sequencer, r runtime.Runtime) []*runtime.Plan {
+	plans := make([]*runtime.Plan, 0, len(describedSequences))
+
+	for _, d := range describedSequences {
+		// The data matches the sequence, so the phases are always built.
+		phases, err := sequencePhases(s, r, d.seq, d.data)
+		if err != nil {
+			continue
+		}
+
+		plans = append(plans, newPlan(d.seq, phases))
+	}
+
+	return plans
+}
+
+func newPlan(seq runtime.Sequence, phases []runtime.Phase) *runtime.Plan {
+	plan := &runtime.Plan{
+		Sequence: seq.String(),
+		Phases:   make([]runtime.PlanPhase, 0, len(phases)),
+	}
+
+	for i, phase := range phases {
+		p := runtime.PlanPhase{
+			Number:  i + 1,
+			Tasks:   make([]runtime.PlanTask, 0, len(phase)),
+			Overlap: phase.OverlapsWithNext(),
+		}
+
+		for _, task := range phase.Tasks() {
+			p.Tasks = append(p.Tasks, runtime.PlanTask{
+				Name:  taskName(task),
+				Label: runtime.TaskLabel(task),
+			})
+		}
+
+		plan.Phases = append(plan.Phases, p)
+	}
+
+	return plan
+}
@@ -0,0 +1,81 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/runtimetest"
+	"github.com/talos-systems/talos/pkg/config/types/v1alpha1"
+)
+
+func Test_newPlan(t *testing.T) {
+	phases := []runtime.Phase{
+		{MountBootPartition},
+		{ResetSystemDisk, Reboot},
+	}
+
+	expected := &runtime.Plan{
+		Sequence: "reset",
+		Phases: []runtime.PlanPhase{
+			{Number: 1, Tasks: []runtime.PlanTask{{Name: "MountBootPartition", Label: "Mount the boot partition"}}},
+			{Number: 2, Tasks: []runtime.PlanTask{{Name: "ResetSystemDisk", Label: "Wipe the system disk"}, {Name: "Reboot", Label: "Reboot"}}},
+		},
+	}
+
+	if plan := newPlan(runtime.SequenceReset, phases); !reflect.DeepEqual(plan, expected) {
+		t.Errorf("newPlan() = %+v, want %+v", plan, expected)
+	}
+}
+
+func TestController_Describe(t *testing.T) {
+	s := runtimetest.NewSequencer().
+		SetPhases(runtime.SequenceBoot, runtime.Phase{MountBootPartition}, runtime.Phase{StartAllServices}).
+		SetPhases(runtime.SequenceShutdown, runtime.Phase{StopAllServices})
+
+	c := &Controller{r: runtimetest.NewRuntime(nil, runtime.ModeMetal), s: s}
+
+	if _, err := c.Describe(); !errors.Is(err, runtime.ErrUnconfigured) {
+		t.Fatalf("Controller.Describe() error = %v, want %v", err, runtime.ErrUnconfigured)
+	}
+
+	c.r = runtimetest.NewRuntime(&v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{}}, runtime.ModeMetal)
+
+	plans, err := c.Describe()
+	if err != nil {
+		t.Fatalf("Controller.Describe() error = %v", err)
+	}
+
+	var sequences []string
+
+	for _, plan := range plans {
+		sequences = append(sequences, plan.Sequence)
+	}
+
+	// The sequences are in the order of the lifecycle of a machine.
+	expected := []string{"initialize", "install", "boot", "upgrade", "reload", "rollback", "recover", "maintenance", "resume", "reset", "reboot", "shutdown"}
+	if !reflect.DeepEqual(sequences, expected) {
+		t.Fatalf("Controller.Describe() sequences = %v, want %v", sequences, expected)
+	}
+
+	boot := &runtime.Plan{
+		Sequence: "boot",
+		Phases: []runtime.PlanPhase{
+			{Number: 1, Tasks: []runtime.PlanTask{{Name: "MountBootPartition", Label: "Mount the boot partition"}}},
+			{Number: 2, Tasks: []runtime.PlanTask{{Name: "StartAllServices", Label: "Start all services"}}},
+		},
+	}
+
+	if !reflect.DeepEqual(plans[2], boot) {
+		t.Errorf("Controller.Describe() boot = %+v, want %+v", plans[2], boot)
+	}
+
+	if got := len(plans[len(plans)-1].Phases); got != 1 {
+		t.Errorf("Controller.Describe() shutdown has %d phase(s), want 1", got)
+	}
+}
@@ -0,0 +1,407 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/sys/unix"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/v1alpha1/acpi"
+	"github.com/talos-systems/talos/pkg/constants"
+)
+
+// RegisterEventSource adds a source of events to the ones ListenForEvents
+// handles. Sources must be registered before ListenForEvents is called.
+func (c *Controller) RegisterEventSource(source runtime.EventSource) {
+	c.sources = append(c.sources, source)
+}
+
+// eventSources returns the built-in sources, followed by the registered ones.
+func (c *Controller) eventSources() []runtime.EventSource {
+	sources := []runtime.EventSource{&signalEventSource{grace: c.sigtermGrace}}
+
+	if c.r.State().Platform().Mode() != runtime.ModeContainer {
+		sources = append(sources, &acpiEventSource{
+			decide:   c.acpiAction,
+			debounce: acpiDebounce,
+			grace:    acpiEscalationGrace,
+			escalate: hardPoweroff,
+		})
+	}
+
+	return append(sources, c.sources...)
+}
+
+// listen fans in the requests of the sources, and handles each of them
+// concurrently, so that a forced request can preempt a running sequence. It
+// returns once a shutdown or reboot request is handled, with the error of that
+// sequence, or once all of the sources stopped. A failing source is logged,
+// and does not stop the others.
+func listen(ctx context.Context, sources []runtime.EventSource, handle func(runtime.SequenceRequest) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	requests := make(chan runtime.SequenceRequest)
+	errCh := make(chan error, len(sources))
+	terminated := make(chan error, 1)
+
+	for _, source := range sources {
+		go func(source runtime.EventSource) {
+			err := source.Listen(ctx, requests)
+			if err != nil {
+				err = fmt.Errorf("%s: %w", source.Name(), err)
+
+				log.Printf("event source failed: %v", err)
+			}
+
+			errCh <- err
+		}(source)
+	}
+
+	var result *multierror.Error
+
+	for remaining := len(sources); remaining > 0; {
+		select {
+		case err := <-errCh:
+			remaining--
+
+			if err != nil {
+				result = multierror.Append(result, err)
+			}
+		case req := <-requests:
+			log.Printf("%s via %s received", req.Sequence, req.Reason)
+
+			go func() {
+				err := handle(req)
+				if err != nil {
+					err = fmt.Errorf("%s via %s failed: %w", req.Sequence, req.Reason, err)
+
+					log.Print(err)
+				}
+
+				if terminates(req.Sequence) {
+					select {
+					case terminated <- err:
+					default:
+					}
+				}
+			}()
+		case err := <-terminated:
+			return err
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// terminates returns true if the sequence ends with the machine going down.
+func terminates(seq runtime.Sequence) bool {
+	return seq == runtime.SequenceShutdown || seq == runtime.SequenceReboot
+}
+
+// gracefulShutdownTimeout is the time a shutdown or reboot requested by an
+// event is given, before the machine is powered off or restarted without it.
+const gracefulShutdownTimeout = 10 * time.Minute
+
+const (
+	// acpiDebounce is the window in which the repeated ACPI events of a
+	// bouncing button are suppressed.
+	acpiDebounce = 2 * time.Second
+	// acpiEscalationGrace is the time a sequence requested by an ACPI event
+	// is given, before another event escalates it to a hard poweroff or
+	// restart.
+	acpiEscalationGrace = 10 * time.Second
+)
+
+// hardPoweroff powers off the machine, or restarts it for a reboot, after
+// syncing the filesystems.
+var hardPoweroff = func(seq runtime.Sequence) error {
+	SyncNonVolatileStorageBuffers(constants.DefaultShutdownSyncTimeout)
+
+	if seq == runtime.SequenceReboot {
+		return unix.Reboot(unix.LINUX_REBOOT_CMD_RESTART)
+	}
+
+	return unix.Reboot(unix.LINUX_REBOOT_CMD_POWER_OFF)
+}
+
+// handleRequest runs the requested sequence. A shutdown or reboot that
+// starts, and then fails or does not complete in time, falls back to a hard
+// poweroff, except in container mode.
+func (c *Controller) handleRequest(req runtime.SequenceRequest) error {
+	run := func() error {
+		if req.Force {
+			return c.RunForced(req.Sequence, req.Data)
+		}
+
+		return c.Run(req.Sequence, req.Data)
+	}
+
+	if !terminates(req.Sequence) || c.r.State().Platform().Mode() == runtime.ModeContainer {
+		return run()
+	}
+
+	started := func() (time.Time, bool) {
+		status, ok := c.CurrentSequence()
+		if !ok || status.Sequence != req.Sequence {
+			return time.Time{}, false
+		}
+
+		return status.Start, true
+	}
+
+	return withFallback(req.Sequence, gracefulShutdownTimeout, run, started, hardPoweroff)
+}
+
+// fallbackPollInterval is the interval at which withFallback checks whether a
+// sequence waiting for the lock started.
+const fallbackPollInterval = time.Second
+
+// withFallback runs the sequence, and calls the fallback if it started, and
+// then fails or does not complete within the timeout. The timeout counts from
+// the start of the sequence, which started reports, so that the time spent
+// waiting for the lock is not counted. A sequence that does not start (e.g.
+// since another sequence holds the lock, or it is not configured) is not
+// fallen back from, as the machine is not on its way down. The error of the
+// sequence is returned, along with that of the fallback, since a fallback that
+// returns did not work either.
+func withFallback(seq runtime.Sequence, timeout time.Duration, run func() error, started func() (time.Time, bool), fallback func(runtime.Sequence) error) error {
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- run()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var err error
+
+	for err == nil {
+		select {
+		case err = <-errCh:
+			if err == nil {
+				return nil
+			}
+
+			if !sequenceStarted(err) {
+				return err
+			}
+		case <-timer.C:
+			start, ok := started()
+
+			switch remaining := timeout - time.Since(start); {
+			case !ok:
+				timer.Reset(fallbackPollInterval)
+			case remaining > 0:
+				timer.Reset(remaining)
+			default:
+				err = fmt.Errorf("%s did not complete within %s", seq, timeout)
+			}
+		}
+	}
+
+	log.Printf("falling back to a hard %s: %v", seq, err)
+
+	if e := fallback(seq); e != nil {
+		return multierror.Append(err, fmt.Errorf("hard %s: %w", seq, e))
+	}
+
+	return err
+}
+
+// sequenceStarted returns false if the error of a sequence denotes that it did
+// not start running its tasks.
+func sequenceStarted(err error) bool {
+	for _, target := range []error{
+		runtime.ErrLocked,
+		runtime.ErrUnconfigured,
+		runtime.ErrPreflight,
+		runtime.ErrUndefinedRuntime,
+		runtime.ErrNotForceable,
+		runtime.ErrInvalidSequenceData,
+	} {
+		if errors.Is(err, target) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// signalEventSource requests a shutdown on SIGTERM. With a grace period, the
+// shutdown is requested once the period elapses, or forced by a second SIGTERM
+// within it.
+type signalEventSource struct {
+	grace time.Duration
+}
+
+func (*signalEventSource) Name() string {
+	return "SIGTERM"
+}
+
+func (s *signalEventSource) Listen(ctx context.Context, requests chan<- runtime.SequenceRequest) error {
+	sigs := make(chan os.Signal, 1)
+
+	signal.Notify(sigs, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	return s.relay(ctx, sigs, requests)
+}
+
+// relay turns the signals into the shutdown request.
+func (s *signalEventSource) relay(ctx context.Context, sigs <-chan os.Signal, requests chan<- runtime.SequenceRequest) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-sigs:
+	}
+
+	req := runtime.SequenceRequest{Sequence: runtime.SequenceShutdown, Reason: "SIGTERM"}
+
+	if s.grace > 0 {
+		log.Printf("SIGTERM received, shutting down in %s, send SIGTERM again to shut down now", s.grace)
+
+		timer := time.NewTimer(s.grace)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+			log.Printf("SIGTERM grace period of %s elapsed, shutting down", s.grace)
+		case <-sigs:
+			log.Printf("second SIGTERM received within the grace period, forcing the shutdown")
+
+			req = runtime.SequenceRequest{Sequence: runtime.SequenceShutdown, Reason: "second SIGTERM", Force: true}
+		}
+	}
+
+	select {
+	case requests <- req:
+	case <-ctx.Done():
+	}
+
+	return nil
+}
+
+// acpiSequences are the sequences requested by the actions of the ACPI events.
+var acpiSequences = map[acpi.Action]runtime.Sequence{
+	acpi.ActionShutdown:    runtime.SequenceShutdown,
+	acpi.ActionReboot:      runtime.SequenceReboot,
+	acpi.ActionMaintenance: runtime.SequenceMaintenance,
+	acpi.ActionResume:      runtime.SequenceResume,
+}
+
+// acpiEventSource requests the sequence the first actionable ACPI event is
+// mapped to. A shutdown or reboot preempts the running sequence, since the
+// event usually means that someone is waiting in front of the machine. The
+// events repeated within the debounce window are suppressed, and those
+// received while the requested shutdown or reboot runs are ignored, unless the
+// grace period elapsed, in which case it is escalated to a hard poweroff or
+// restart. The maintenance and resume sequences do not preempt the running
+// sequence, and are requested again by each event.
+type acpiEventSource struct {
+	decide   func(acpi.Event) acpi.Action
+	debounce time.Duration
+	grace    time.Duration
+	escalate func(runtime.Sequence) error
+}
+
+func (*acpiEventSource) Name() string {
+	return "ACPI"
+}
+
+func (s *acpiEventSource) Listen(ctx context.Context, requests chan<- runtime.SequenceRequest) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	decisions := make(chan acpi.Decision)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(decisions)
+
+		errCh <- acpi.ListenACPI(ctx, s.decide, s.debounce, decisions)
+	}()
+
+	s.relay(ctx, decisions, requests)
+
+	cancel()
+
+	return <-errCh
+}
+
+// relay turns the decided events into the request, and escalates it once the
+// grace period elapses. It returns once the context is canceled, or the
+// decisions are closed.
+func (s *acpiEventSource) relay(ctx context.Context, decisions <-chan acpi.Decision, requests chan<- runtime.SequenceRequest) {
+	var (
+		requested runtime.Sequence
+		since     time.Time
+	)
+
+	for {
+		var (
+			d  acpi.Decision
+			ok bool
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case d, ok = <-decisions:
+			if !ok {
+				return
+			}
+		}
+
+		seq := acpiSequences[d.Action]
+
+		if !terminates(seq) {
+			select {
+			case requests <- runtime.SequenceRequest{Sequence: seq, Reason: fmt.Sprintf("ACPI event %q", d.Event)}:
+			case <-ctx.Done():
+				return
+			}
+
+			continue
+		}
+
+		if since.IsZero() {
+			select {
+			case requests <- runtime.SequenceRequest{Sequence: seq, Reason: fmt.Sprintf("ACPI event %q", d.Event), Force: true}:
+			case <-ctx.Done():
+				return
+			}
+
+			requested, since = seq, time.Now()
+
+			continue
+		}
+
+		if elapsed := time.Since(since); elapsed < s.grace {
+			log.Printf("ignoring ACPI event %q, %s in progress, repeat it in %s to force a hard %s", d.Event, requested, (s.grace - elapsed).Round(time.Second), requested)
+
+			continue
+		}
+
+		log.Printf("ACPI event %q received after the grace period of %s, forcing a hard %s", d.Event, s.grace, requested)
+
+		if err := s.escalate(requested); err != nil {
+			log.Printf("hard %s failed: %v", requested, err)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/v1alpha1/acpi"
+)
+
+// fakeEventSource sends its requests, and then either fails or waits for the
+// context to be canceled.
+type fakeEventSource struct {
+	name     string
+	requests []runtime.SequenceRequest
+	err      error
+}
+
+func (s *fakeEventSource) Name() string {
+	return s.name
+}
+
+func (s *fakeEventSource) Listen(ctx context.Context, requests chan<- runtime.SequenceRequest) error {
+	for _, req := range s.requests {
+		select {
+		case requests <- req:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	if s.err != nil {
+		return s.err
+	}
+
+	<-ctx.Done()
+
+	return nil
+}
+
+func Test_listen(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		handled []runtime.SequenceRequest
+	)
+
+	handle := func(req runtime.SequenceRequest) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		handled = append(handled, req)
+
+		return nil
+	}
+
+	gpio := &fakeEventSource{
+		name: "GPIO",
+		requests: []runtime.SequenceRequest{
+			{Sequence: runtime.SequenceReboot, Reason: "GPIO pin 17", Force: true},
+		},
+	}
+
+	broken := &fakeEventSource{
+		name: "broken",
+		err:  errors.New("no device"),
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- listen(context.Background(), []runtime.EventSource{broken, gpio}, handle)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("listen() = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("listen() did not return after the reboot")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(handled) != 1 || handled[0] != gpio.requests[0] {
+		t.Fatalf("handled = %v, want %v", handled, gpio.requests)
+	}
+}
+
+func Test_listen_SourcesStopped(t *testing.T) {
+	handled := make(chan runtime.SequenceRequest, 1)
+
+	handle := func(req runtime.SequenceRequest) error {
+		handled <- req
+
+		return errors.New("sequence failed")
+	}
+
+	// A request other than a shutdown or reboot does not stop listen, even if
+	// it fails.
+	source := &fakeEventSource{
+		name: "button",
+		requests: []runtime.SequenceRequest{
+			{Sequence: runtime.SequenceUpgrade, Reason: "button"},
+		},
+		err: errors.New("unplugged"),
+	}
+
+	err := listen(context.Background(), []runtime.EventSource{source}, handle)
+	if err == nil || !strings.Contains(err.Error(), "button: unplugged") {
+		t.Fatalf("listen() = %v, want the error of the source", err)
+	}
+
+	select {
+	case req := <-handled:
+		if req.Sequence != runtime.SequenceUpgrade {
+			t.Fatalf("handled %s, want %s", req.Sequence, runtime.SequenceUpgrade)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request was not handled")
+	}
+}
+
+func Test_listen_ShutdownFailed(t *testing.T) {
+	handle := func(req runtime.SequenceRequest) error {
+		return errors.New("unmount failed")
+	}
+
+	source := &fakeEventSource{
+		name: "SIGTERM",
+		requests: []runtime.SequenceRequest{
+			{Sequence: runtime.SequenceShutdown, Reason: "SIGTERM"},
+		},
+	}
+
+	err := listen(context.Background(), []runtime.EventSource{source}, handle)
+	if err == nil || !strings.Contains(err.Error(), "unmount failed") {
+		t.Fatalf("listen() = %v, want the error of the shutdown", err)
+	}
+}
+
+func Test_withFallback(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		run      func() error
+		waiting  bool
+		fallback error
+		called   bool
+		err      string
+	}{
+		{
+			name: "graceful",
+			run:  func() error { return nil },
+		},
+		{
+			name:   "failed",
+			run:    func() error { return errors.New("unmount failed") },
+			called: true,
+			err:    "unmount failed",
+		},
+		{
+			name: "timeout",
+			run: func() error {
+				select {}
+			},
+			called: true,
+			err:    "did not complete within",
+		},
+		{
+			name: "locked",
+			run: func() error {
+				return fmt.Errorf("shutdown sequence: preempted sequence did not stop: %w", runtime.ErrLocked)
+			},
+			err: "locked",
+		},
+		{
+			name: "unconfigured",
+			run:  func() error { return runtime.ErrUnconfigured },
+			err:  "config not loaded",
+		},
+		{
+			name: "waiting for the lock",
+			run: func() error {
+				time.Sleep(300 * time.Millisecond)
+
+				return runtime.ErrLocked
+			},
+			waiting: true,
+			err:     "locked",
+		},
+		{
+			name:     "fallback failed",
+			run:      func() error { return errors.New("unmount failed") },
+			fallback: errors.New("operation not permitted"),
+			called:   true,
+			err:      "operation not permitted",
+		},
+	} {
+		var called bool
+
+		fallback := func(seq runtime.Sequence) error {
+			called = true
+
+			return tt.fallback
+		}
+
+		start := time.Now()
+
+		started := func() (time.Time, bool) {
+			return start, !tt.waiting
+		}
+
+		err := withFallback(runtime.SequenceShutdown, 100*time.Millisecond, tt.run, started, fallback)
+
+		if called != tt.called {
+			t.Errorf("%s: fallback called = %v, want %v", tt.name, called, tt.called)
+		}
+
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func Test_signalEventSource(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		grace   time.Duration
+		signals int
+		want    runtime.SequenceRequest
+	}{
+		{
+			name:    "no grace period",
+			signals: 1,
+			want:    runtime.SequenceRequest{Sequence: runtime.SequenceShutdown, Reason: "SIGTERM"},
+		},
+		{
+			name:    "grace period elapsed",
+			grace:   100 * time.Millisecond,
+			signals: 1,
+			want:    runtime.SequenceRequest{Sequence: runtime.SequenceShutdown, Reason: "SIGTERM"},
+		},
+		{
+			name:    "second signal",
+			grace:   time.Hour,
+			signals: 2,
+			want:    runtime.SequenceRequest{Sequence: runtime.SequenceShutdown, Reason: "second SIGTERM", Force: true},
+		},
+	} {
+		sigs := make(chan os.Signal, tt.signals)
+
+		for i := 0; i < tt.signals; i++ {
+			sigs <- syscall.SIGTERM
+		}
+
+		requests := make(chan runtime.SequenceRequest, 1)
+
+		source := &signalEventSource{grace: tt.grace}
+
+		if err := source.relay(context.Background(), sigs, requests); err != nil {
+			t.Fatalf("%s: relay() = %v", tt.name, err)
+		}
+
+		if req := <-requests; req != tt.want {
+			t.Errorf("%s: request = %+v, want %+v", tt.name, req, tt.want)
+		}
+	}
+}
+
+func Test_signalEventSource_Canceled(t *testing.T) {
+	sigs := make(chan os.Signal, 1)
+	sigs <- syscall.SIGTERM
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	requests := make(chan runtime.SequenceRequest, 1)
+
+	source := &signalEventSource{grace: time.Hour}
+
+	if err := source.relay(ctx, sigs, requests); err != nil {
+		t.Fatalf("relay() = %v", err)
+	}
+
+	select {
+	case req := <-requests:
+		t.Errorf("request = %+v, want none once canceled within the grace period", req)
+	default:
+	}
+}
+
+func Test_acpiEventSource_relay(t *testing.T) {
+	var escalated []runtime.Sequence
+
+	source := &acpiEventSource{
+		grace: 100 * time.Millisecond,
+		escalate: func(seq runtime.Sequence) error {
+			escalated = append(escalated, seq)
+
+			return errors.New("no hardware")
+		},
+	}
+
+	event := acpi.Event{DeviceClass: acpi.PowerButtonEvent, BusID: "LNXPWRBN:00", Type: 0x80, Data: 1}
+
+	decisions := make(chan acpi.Decision)
+	requests := make(chan runtime.SequenceRequest, 2)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		source.relay(context.Background(), decisions, requests)
+	}()
+
+	decisions <- acpi.Decision{Event: event, Action: acpi.ActionReboot}
+
+	want := runtime.SequenceRequest{Sequence: runtime.SequenceReboot, Reason: fmt.Sprintf("ACPI event %q", event), Force: true}
+	if req := <-requests; req != want {
+		t.Errorf("request = %+v, want %+v", req, want)
+	}
+
+	// Within the grace period, the event is ignored.
+	decisions <- acpi.Decision{Event: event, Action: acpi.ActionShutdown}
+
+	time.Sleep(source.grace)
+
+	// After it, the requested sequence is escalated, as often as the event
+	// is repeated, since a failed escalation does not stop the machine.
+	decisions <- acpi.Decision{Event: event, Action: acpi.ActionShutdown}
+	decisions <- acpi.Decision{Event: event, Action: acpi.ActionShutdown}
+
+	close(decisions)
+	<-done
+
+	select {
+	case req := <-requests:
+		t.Errorf("request = %+v, want a single request", req)
+	default:
+	}
+
+	if want := []runtime.Sequence{runtime.SequenceReboot, runtime.SequenceReboot}; !reflect.DeepEqual(escalated, want) {
+		t.Errorf("escalated = %v, want %v", escalated, want)
+	}
+}
+
+func Test_acpiEventSource_relay_maintenance(t *testing.T) {
+	source := &acpiEventSource{
+		grace: time.Hour,
+		escalate: func(seq runtime.Sequence) error {
+			t.Errorf("escalated %s, want no escalation", seq)
+
+			return nil
+		},
+	}
+
+	thermal := acpi.Event{DeviceClass: "thermal_zone", BusID: "LNXTHERM:00", Type: 0x81, Data: 0}
+	power := acpi.Event{DeviceClass: acpi.PowerButtonEvent, BusID: "LNXPWRBN:00", Type: 0x80, Data: 1}
+
+	decisions := make(chan acpi.Decision)
+	requests := make(chan runtime.SequenceRequest, 3)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		source.relay(context.Background(), decisions, requests)
+	}()
+
+	// The maintenance sequence does not preempt the running sequence, and
+	// does not start the grace period of a shutdown.
+	decisions <- acpi.Decision{Event: thermal, Action: acpi.ActionMaintenance}
+	decisions <- acpi.Decision{Event: thermal, Action: acpi.ActionMaintenance}
+	decisions <- acpi.Decision{Event: power, Action: acpi.ActionShutdown}
+
+	close(decisions)
+	<-done
+
+	for _, want := range []runtime.SequenceRequest{
+		{Sequence: runtime.SequenceMaintenance, Reason: fmt.Sprintf("ACPI event %q", thermal)},
+		{Sequence: runtime.SequenceMaintenance, Reason: fmt.Sprintf("ACPI event %q", thermal)},
+		{Sequence: runtime.SequenceShutdown, Reason: fmt.Sprintf("ACPI event %q", power), Force: true},
+	} {
+		if req := <-requests; req != want {
+			t.Errorf("request = %+v, want %+v", req, want)
+		}
+	}
+}
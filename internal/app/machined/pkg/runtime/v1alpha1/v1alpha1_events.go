@@ -0,0 +1,130 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+// eventBufferSize is the number of events buffered for each subscriber.
+const eventBufferSize = 64
+
+// eventBus is an in-process publisher of sequence lifecycle events. Publishing
+// never blocks: events are dropped for subscribers with a full buffer. The
+// zero value is ready to use.
+type eventBus struct {
+	mu          sync.Mutex
+	subscribers map[chan runtime.Event]struct{}
+}
+
+// Subscribe implements the runtime.Events interface.
+func (b *eventBus) Subscribe() (<-chan runtime.Event, func()) {
+	ch := make(chan runtime.Event, eventBufferSize)
+
+	b.mu.Lock()
+
+	if b.subscribers == nil {
+		b.subscribers = map[chan runtime.Event]struct{}{}
+	}
+
+	b.subscribers[ch] = struct{}{}
+
+	b.mu.Unlock()
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subscribers, ch)
+			close(ch)
+		})
+	}
+}
+
+func (b *eventBus) publish(e runtime.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+type phaseProgressKey struct{}
+
+type phaseProgressValue struct {
+	number, total int
+}
+
+// withPhaseProgress returns a context carrying the number of the running
+// phase, so that the events of its tasks identify the phase.
+func withPhaseProgress(ctx context.Context, number, total int) context.Context {
+	return context.WithValue(ctx, phaseProgressKey{}, phaseProgressValue{number, total})
+}
+
+// phaseProgress returns the number of the running phase and the total number
+// of phases, or zeros if the context does not carry them.
+func phaseProgress(ctx context.Context) (number, total int) {
+	if p, ok := ctx.Value(phaseProgressKey{}).(phaseProgressValue); ok {
+		return p.number, p.total
+	}
+
+	return 0, 0
+}
+
+// progressInterval is the minimum interval between the progress events of a
+// task, since a task may report its progress e.g. for every chunk it writes.
+const progressInterval = time.Second
+
+// progressReporter returns the receiver of the progress of the task that the
+// started event is for. It publishes the progress as events, at most one per
+// interval, except for the completion, which is always published.
+func (b *eventBus) progressReporter(started runtime.Event) runtime.ProgressFunc {
+	var (
+		mu   sync.Mutex
+		last time.Time
+	)
+
+	return func(p runtime.Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		done := p.Total > 0 && p.Current >= p.Total
+
+		if time.Since(last) < progressInterval && !done {
+			return
+		}
+
+		last = time.Now()
+
+		e := started
+		e.Type = runtime.EventTaskProgress
+		e.Progress = p
+
+		b.publish(e)
+	}
+}
+
+// messageReporter returns the receiver of the messages of the task that the
+// started event is for. Unlike the progress, the messages are not throttled.
+func (b *eventBus) messageReporter(started runtime.Event) runtime.MessageFunc {
+	return func(message string) {
+		e := started
+		e.Type = runtime.EventTaskMessage
+		e.Message = message
+
+		b.publish(e)
+	}
+}
@@ -0,0 +1,137 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+func TestController_run_Events(t *testing.T) {
+	defer discardTaskLogs()()
+
+	task := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			return nil
+		}
+	}
+
+	c := &Controller{
+		s: NewSequencer(),
+	}
+
+	events, cancel := c.Events().Subscribe()
+	defer cancel()
+
+	if err := c.run(context.Background(), runtime.SequenceBoot, []runtime.Phase{{task}, {task}}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []runtime.EventType{
+		runtime.EventSequenceStarted,
+		runtime.EventPhaseStarted,
+		runtime.EventTaskStarted,
+		runtime.EventTaskFinished,
+		runtime.EventPhaseStarted,
+		runtime.EventTaskStarted,
+		runtime.EventTaskFinished,
+		runtime.EventSequenceFinished,
+	}
+
+	for i, typ := range expected {
+		e := <-events
+
+		if e.Type != typ {
+			t.Fatalf("event %d: type = %s, want %s", i, e.Type, typ)
+		}
+
+		if e.Sequence != runtime.SequenceBoot || e.PhaseTotal != 2 {
+			t.Errorf("event %d: %+v, want sequence boot with 2 phases", i, e)
+		}
+	}
+
+	select {
+	case e := <-events:
+		t.Errorf("unexpected event %+v", e)
+	default:
+	}
+}
+
+func Test_eventBus_Subscribe(t *testing.T) {
+	var b eventBus
+
+	events, cancel := b.Subscribe()
+
+	for i := 0; i < eventBufferSize+1; i++ {
+		b.publish(runtime.Event{Task: i})
+	}
+
+	if len(events) != eventBufferSize {
+		t.Errorf("%d event(s) buffered, want %d", len(events), eventBufferSize)
+	}
+
+	// Canceling is idempotent, and the bus no longer publishes to the
+	// subscriber.
+	cancel()
+	cancel()
+
+	b.publish(runtime.Event{})
+}
+
+func Test_eventBus_progressReporter(t *testing.T) {
+	var b eventBus
+
+	events, cancel := b.Subscribe()
+	defer cancel()
+
+	report := b.progressReporter(runtime.Event{Type: runtime.EventTaskStarted, Sequence: runtime.SequenceInstall, Task: 1, TaskTotal: 2})
+
+	// The second report is throttled, but the completion is not.
+	report(runtime.Progress{Current: 10, Total: 100, Unit: "bytes"})
+	report(runtime.Progress{Current: 20, Total: 100, Unit: "bytes"})
+	report(runtime.Progress{Current: 100, Total: 100, Unit: "bytes"})
+
+	if len(events) != 2 {
+		t.Fatalf("%d event(s) published, want 2", len(events))
+	}
+
+	for _, current := range []int64{10, 100} {
+		e := <-events
+
+		if e.Type != runtime.EventTaskProgress || e.Sequence != runtime.SequenceInstall || e.Task != 1 || e.Progress.Current != current {
+			t.Errorf("event = %+v, want the progress %d of task 1", e, current)
+		}
+	}
+}
+
+func Test_eventBus_messageReporter(t *testing.T) {
+	var b eventBus
+
+	events, cancel := b.Subscribe()
+	defer cancel()
+
+	ctx := runtime.WithMessages(context.Background(), b.messageReporter(runtime.Event{Type: runtime.EventTaskStarted, Sequence: runtime.SequenceUpgrade, Task: 1, TaskTotal: 1}))
+
+	runtime.ReportMessage(ctx, "verified %s", "sha256:abc")
+	runtime.ReportMessage(ctx, "pinned")
+
+	if len(events) != 2 {
+		t.Fatalf("%d event(s) published, want 2", len(events))
+	}
+
+	for _, message := range []string{"verified sha256:abc", "pinned"} {
+		e := <-events
+
+		if e.Type != runtime.EventTaskMessage || e.Sequence != runtime.SequenceUpgrade || e.Task != 1 || e.Message != message {
+			t.Errorf("event = %+v, want the message %q of task 1", e, message)
+		}
+	}
+
+	// Without a receiver, the messages are dropped.
+	runtime.ReportMessage(context.Background(), "dropped")
+}
@@ -0,0 +1,162 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+// extension is a phase contributed to the sequences at an extension point.
+type extension struct {
+	name  string
+	phase runtime.Phase
+}
+
+// RegisterExtension registers the phase of the named extension, to be run at
+// the extension point of the sequences. The phases registered at a point run
+// in the order of registration, each after the previous one completed.
+//
+// The tasks of an extension are isolated from the sequence: a task that
+// fails, or panics, is reported with the name of the extension, and the
+// sequence continues. Of the directives of the phase, only the timeout is
+// kept.
+func (s *Sequencer) RegisterExtension(point runtime.ExtensionPoint, name string, phase runtime.Phase) error {
+	switch {
+	case point < runtime.ExtensionPointAfterNetwork || point > runtime.ExtensionPointBeforeShutdown:
+		return fmt.Errorf("%w: unknown extension point %d", runtime.ErrInvalidExtension, point)
+	case name == "":
+		return fmt.Errorf("%w: a name is required", runtime.ErrInvalidExtension)
+	case len(phase.Tasks()) == 0:
+		return fmt.Errorf("%w: %q has no tasks", runtime.ErrInvalidExtension, name)
+	}
+
+	s.extensionsMu.Lock()
+	defer s.extensionsMu.Unlock()
+
+	for _, ext := range s.extensions[point] {
+		if ext.name == name {
+			return fmt.Errorf("%w: %q is already registered at %s", runtime.ErrInvalidExtension, name, point)
+		}
+	}
+
+	if s.extensions == nil {
+		s.extensions = map[runtime.ExtensionPoint][]extension{}
+	}
+
+	isolated := make(runtime.Phase, 0, len(phase))
+
+	for _, task := range phase.Tasks() {
+		isolated = append(isolated, isolateExtensionTask(point, name, task))
+	}
+
+	// An extension must not change how the phases around it run, so it can
+	// not overlap with the next phase, and its timeout never aborts the
+	// sequence.
+	if timeout := phase.Options().Timeout; timeout > 0 {
+		isolated = append(isolated, runtime.PhaseTimeout(timeout, runtime.PhaseTimeoutContinue))
+	}
+
+	s.extensions[point] = append(s.extensions[point], extension{name: name, phase: isolated})
+
+	return nil
+}
+
+// RegisterConfigExtensions registers the extensions set by the config, each
+// running its command in the isolated environment of a `CommandTask`. It is
+// called once the initialize sequence loaded the config.
+func (c *Controller) RegisterConfigExtensions() error {
+	if c.r.Config() == nil {
+		return nil
+	}
+
+	for _, ext := range c.r.Config().Machine().Extensions() {
+		point, err := runtime.ParseExtensionPoint(ext.Point())
+		if err != nil {
+			return err
+		}
+
+		if len(ext.Command()) == 0 {
+			return fmt.Errorf("%w: %q has no command", runtime.ErrInvalidExtension, ext.Name())
+		}
+
+		env := make([]string, 0, len(ext.Env()))
+
+		for key, val := range ext.Env() {
+			env = append(env, key+"="+val)
+		}
+
+		// Sort the variables so that the environment is the same on every boot.
+		sort.Strings(env)
+
+		phase := runtime.Phase{CommandTask(ext.Command()[0], ext.Command()[1:], WithCommandEnv(env...))}
+
+		if ext.Timeout() > 0 {
+			phase = append(phase, runtime.PhaseTimeout(ext.Timeout(), runtime.PhaseTimeoutContinue))
+		}
+
+		if err = c.s.RegisterExtension(point, ext.Name(), phase); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extensionPhases returns the phases registered at the extension point.
+func (s *Sequencer) extensionPhases(point runtime.ExtensionPoint) []runtime.Phase {
+	s.extensionsMu.RLock()
+	defer s.extensionsMu.RUnlock()
+
+	phases := make([]runtime.Phase, 0, len(s.extensions[point]))
+
+	for _, ext := range s.extensions[point] {
+		phases = append(phases, ext.phase)
+	}
+
+	return phases
+}
+
+// isolateExtensionTask wraps the task of an extension, so that its errors and
+// panics are returned as an `ExtensionError`, which the controller reports
+// without failing the sequence.
+func isolateExtensionTask(point runtime.ExtensionPoint, name string, f runtime.TaskSetupFunc) runtime.TaskSetupFunc {
+	return func(seq runtime.Sequence, data interface{}) (task runtime.TaskExecutionFunc) {
+		defer func() {
+			if p := recover(); p != nil {
+				task = func(context.Context, *log.Logger, runtime.Runtime) error {
+					return &runtime.ExtensionError{Extension: name, Point: point, Err: fmt.Errorf("panic in task setup: %v", p)}
+				}
+			}
+		}()
+
+		task = f(seq, data)
+		if task == nil {
+			return nil
+		}
+
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					err = &runtime.ExtensionError{Extension: name, Point: point, Err: fmt.Errorf("panic: %v", p)}
+				}
+			}()
+
+			if err = task(ctx, logger, r); err != nil {
+				if _, skipped := runtime.SkipReason(err); skipped {
+					return err
+				}
+
+				return &runtime.ExtensionError{Extension: name, Point: point, Err: err}
+			}
+
+			return nil
+		}
+	}
+}
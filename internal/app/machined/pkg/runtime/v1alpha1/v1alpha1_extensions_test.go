@@ -0,0 +1,176 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/v1alpha1/platform/metal"
+	"github.com/talos-systems/talos/pkg/config/types/v1alpha1"
+)
+
+func TestSequencer_RegisterExtension(t *testing.T) {
+	ok := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			return nil
+		}
+	}
+
+	s := NewSequencer()
+
+	for _, tt := range []struct {
+		name  string
+		point runtime.ExtensionPoint
+		phase runtime.Phase
+	}{
+		{name: "", point: runtime.ExtensionPointAfterNetwork, phase: runtime.Phase{ok}},
+		{name: "unknown", point: runtime.ExtensionPoint(42), phase: runtime.Phase{ok}},
+		{name: "empty", point: runtime.ExtensionPointAfterNetwork, phase: runtime.Phase{runtime.OverlapWithNext}},
+	} {
+		if err := s.RegisterExtension(tt.point, tt.name, tt.phase); !errors.Is(err, runtime.ErrInvalidExtension) {
+			t.Errorf("RegisterExtension(%q) error = %v, want %v", tt.name, err, runtime.ErrInvalidExtension)
+		}
+	}
+
+	if err := s.RegisterExtension(runtime.ExtensionPointBeforeServices, "firmware", runtime.Phase{ok, runtime.OverlapWithNext}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.RegisterExtension(runtime.ExtensionPointBeforeServices, "firmware", runtime.Phase{ok}); !errors.Is(err, runtime.ErrInvalidExtension) {
+		t.Errorf("RegisterExtension() of a duplicate error = %v, want %v", err, runtime.ErrInvalidExtension)
+	}
+
+	if err := s.RegisterExtension(runtime.ExtensionPointBeforeShutdown, "firmware", runtime.Phase{ok}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRuntime(&v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{MachineType: "worker"}}, &State{
+		platform: &metal.Metal{},
+		machine:  &MachineState{},
+	})
+
+	phases := s.Boot(r)
+
+	for i, phase := range phases {
+		if taskNames([]runtime.Phase{phase})[0] != "StartAllServices" {
+			continue
+		}
+
+		ext := phases[i-1]
+		if names := taskNames([]runtime.Phase{ext}); len(names) != 1 || names[0] != "isolateExtensionTask.func1" {
+			t.Errorf("phase before StartAllServices = %v, want the extension", names)
+		}
+
+		if ext.Options().Overlap {
+			t.Error("extension phase overlaps with the next phase")
+		}
+	}
+
+	for _, phases := range [][]runtime.Phase{s.Shutdown(r), s.Reboot(r)} {
+		if names := taskNames(phases); !contains(names, "isolateExtensionTask.func1") {
+			t.Errorf("sequence = %v, want the extension", names)
+		}
+	}
+}
+
+func TestController_run_ExtensionFailure(t *testing.T) {
+	defer discardTaskLogs()()
+
+	failed := errors.New("failed")
+
+	fail := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			return failed
+		}
+	}
+
+	panics := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			panic("boom")
+		}
+	}
+
+	var ran bool
+
+	next := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			ran = true
+
+			return nil
+		}
+	}
+
+	s := NewSequencer()
+
+	if err := s.RegisterExtension(runtime.ExtensionPointAfterNetwork, "failing", runtime.Phase{fail}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.RegisterExtension(runtime.ExtensionPointAfterNetwork, "panicking", runtime.Phase{panics}); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := &recordingLogger{}
+
+	c := &Controller{s: s}
+
+	c.SetLogger(logger)
+
+	phases := append(s.extensionPhases(runtime.ExtensionPointAfterNetwork), runtime.Phase{next})
+
+	if err := c.run(context.Background(), runtime.SequenceBoot, phases, nil); err != nil {
+		t.Fatalf("Controller.run() error = %v, want extension failures to be isolated", err)
+	}
+
+	if !ran {
+		t.Error("phase after the failed extensions was not run")
+	}
+
+	extensions := map[interface{}]interface{}{}
+
+	for _, e := range logger.entries {
+		if e.msg == "extension task failed, continuing" {
+			extensions[e.fields["extension"]] = e.fields["error"]
+		}
+	}
+
+	if extensions["failing"] != failed || extensions["panicking"] == nil {
+		t.Errorf("extension failures = %v, want both extensions reported", extensions)
+	}
+}
+
+func TestController_RegisterConfigExtensions(t *testing.T) {
+	s := NewSequencer()
+
+	cfg := &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{
+		MachineType: "worker",
+		MachineExtensions: []*v1alpha1.ExtensionConfig{
+			{ExtensionName: "firmware", ExtensionPoint: "before-shutdown", ExtensionCommand: []string{"/bin/true"}},
+		},
+	}}
+
+	r := NewRuntime(cfg, &State{
+		platform: &metal.Metal{},
+		machine:  &MachineState{},
+	})
+
+	c := NewControllerWithRuntime(r, s)
+
+	if err := c.RegisterConfigExtensions(); err != nil {
+		t.Fatal(err)
+	}
+
+	if names := taskNames(s.Shutdown(r)); !contains(names, "isolateExtensionTask.func1") {
+		t.Errorf("shutdown sequence = %v, want the extension of the config", names)
+	}
+
+	if err := c.RegisterConfigExtensions(); !errors.Is(err, runtime.ErrInvalidExtension) {
+		t.Errorf("RegisterConfigExtensions() again error = %v, want %v", err, runtime.ErrInvalidExtension)
+	}
+}
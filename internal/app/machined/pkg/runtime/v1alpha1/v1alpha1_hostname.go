@@ -0,0 +1,136 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/talos-systems/go-procfs/procfs"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/pkg/constants"
+)
+
+// HostnameSources is the list of sources consulted by the `SetHostname` task,
+// in order of precedence. The order matches the one used by networkd.
+var HostnameSources = []runtime.HostnameSource{
+	ConfigHostnameSource{},
+	KernelHostnameSource{},
+	PlatformHostnameSource{},
+	NetworkHostnameSource{},
+}
+
+// ConfigHostnameSource provides the hostname specified in the machine config.
+type ConfigHostnameSource struct{}
+
+// Name implements the runtime.HostnameSource interface.
+func (ConfigHostnameSource) Name() string {
+	return "config"
+}
+
+// Hostname implements the runtime.HostnameSource interface.
+func (ConfigHostnameSource) Hostname(r runtime.Runtime) (string, error) {
+	return r.Config().Machine().Network().Hostname(), nil
+}
+
+// KernelHostnameSource provides the hostname specified with the
+// `talos.hostname` kernel parameter.
+type KernelHostnameSource struct{}
+
+// Name implements the runtime.HostnameSource interface.
+func (KernelHostnameSource) Name() string {
+	return "kernel"
+}
+
+// Hostname implements the runtime.HostnameSource interface.
+func (KernelHostnameSource) Hostname(r runtime.Runtime) (string, error) {
+	if hostname := procfs.ProcCmdline().Get(constants.KernelParamHostname).First(); hostname != nil {
+		return *hostname, nil
+	}
+
+	return "", nil
+}
+
+// PlatformHostnameSource provides the hostname found in the platform metadata.
+type PlatformHostnameSource struct{}
+
+// Name implements the runtime.HostnameSource interface.
+func (PlatformHostnameSource) Name() string {
+	return "platform"
+}
+
+// Hostname implements the runtime.HostnameSource interface.
+func (PlatformHostnameSource) Hostname(r runtime.Runtime) (string, error) {
+	hostname, err := r.State().Platform().Hostname()
+	if err != nil {
+		return "", err
+	}
+
+	return string(hostname), nil
+}
+
+// NetworkHostnameSource provides the hostname assigned while configuring the
+// discovery network (e.g. by DHCP).
+type NetworkHostnameSource struct{}
+
+// Name implements the runtime.HostnameSource interface.
+func (NetworkHostnameSource) Name() string {
+	return "network"
+}
+
+// Hostname implements the runtime.HostnameSource interface.
+func (NetworkHostnameSource) Hostname(r runtime.Runtime) (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+
+	if !isHostnameSet(hostname) {
+		return "", nil
+	}
+
+	return hostname, nil
+}
+
+// decideHostname returns the hostname provided by the first source that
+// provides one, along with the name of that source.
+func decideHostname(r runtime.Runtime, sources []runtime.HostnameSource) (hostname, source string, err error) {
+	for _, s := range sources {
+		if hostname, err = s.Hostname(r); err != nil {
+			return "", "", fmt.Errorf("failed to get hostname from %s: %w", s.Name(), err)
+		}
+
+		if hostname != "" {
+			return hostname, s.Name(), nil
+		}
+	}
+
+	return "", "", nil
+}
+
+// requireHostname returns an error if the hostname of the machine has not
+// been set.
+func requireHostname() error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+
+	if !isHostnameSet(hostname) {
+		return fmt.Errorf("hostname is not set (current: %q), the SetHostname task must run first", hostname)
+	}
+
+	return nil
+}
+
+func isHostnameSet(hostname string) bool {
+	switch hostname {
+	case "", "localhost", "(none)":
+		return false
+	default:
+		return true
+	}
+}
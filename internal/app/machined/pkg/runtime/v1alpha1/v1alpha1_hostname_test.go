@@ -0,0 +1,83 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// nolint: scopelint
+package v1alpha1
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+type staticHostnameSource struct {
+	name     string
+	hostname string
+	err      error
+}
+
+func (s staticHostnameSource) Name() string {
+	return s.name
+}
+
+func (s staticHostnameSource) Hostname(runtime.Runtime) (string, error) {
+	return s.hostname, s.err
+}
+
+func Test_decideHostname(t *testing.T) {
+	tests := []struct {
+		name         string
+		sources      []runtime.HostnameSource
+		wantHostname string
+		wantSource   string
+		wantErr      bool
+	}{
+		{
+			name: "first source wins",
+			sources: []runtime.HostnameSource{
+				staticHostnameSource{name: "a", hostname: "first"},
+				staticHostnameSource{name: "b", hostname: "second"},
+			},
+			wantHostname: "first",
+			wantSource:   "a",
+		},
+		{
+			name: "empty source is skipped",
+			sources: []runtime.HostnameSource{
+				staticHostnameSource{name: "a"},
+				staticHostnameSource{name: "b", hostname: "second"},
+			},
+			wantHostname: "second",
+			wantSource:   "b",
+		},
+		{
+			name: "no hostname",
+			sources: []runtime.HostnameSource{
+				staticHostnameSource{name: "a"},
+			},
+		},
+		{
+			name: "error",
+			sources: []runtime.HostnameSource{
+				staticHostnameSource{name: "a", err: errors.New("unavailable")},
+				staticHostnameSource{name: "b", hostname: "second"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostname, source, err := decideHostname(nil, tt.sources)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decideHostname() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if hostname != tt.wantHostname || source != tt.wantSource {
+				t.Errorf("decideHostname() = (%q, %q), want (%q, %q)", hostname, source, tt.wantHostname, tt.wantSource)
+			}
+		})
+	}
+}
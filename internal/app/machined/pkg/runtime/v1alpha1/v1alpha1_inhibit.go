@@ -0,0 +1,120 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// inhibitTimeout is the longest a shutdown or reboot waits for the inhibitors
+// to be released.
+const inhibitTimeout = time.Minute
+
+// inhibitors tracks the components that delay a shutdown or reboot, e.g.
+// while they write to disk.
+type inhibitors struct {
+	mu   sync.Mutex
+	held map[uint64]string
+	next uint64
+	// released is closed, and replaced, whenever an inhibitor is released.
+	released chan struct{}
+}
+
+// add registers an inhibitor, and returns the function that releases it.
+func (i *inhibitors) add(name string) (release func()) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.held == nil {
+		i.held = map[uint64]string{}
+		i.released = make(chan struct{})
+	}
+
+	id := i.next
+	i.next++
+
+	i.held[id] = name
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			i.mu.Lock()
+			defer i.mu.Unlock()
+
+			delete(i.held, id)
+
+			close(i.released)
+			i.released = make(chan struct{})
+		})
+	}
+}
+
+// names returns the sorted names of the inhibitors that are held, and the
+// channel closed once one of them is released.
+func (i *inhibitors) names() ([]string, <-chan struct{}) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	names := make([]string, 0, len(i.held))
+
+	for _, name := range i.held {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names, i.released
+}
+
+// wait waits until all of the inhibitors are released, or the timeout
+// expires. It returns the names of the inhibitors that were held when it was
+// called, and of those still held once it returns.
+func (i *inhibitors) wait(timeout time.Duration) (delayed, remaining []string) {
+	delayed, released := i.names()
+	if len(delayed) == 0 {
+		return nil, nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for remaining = delayed; len(remaining) > 0; remaining, released = i.names() {
+		select {
+		case <-released:
+		case <-timer.C:
+			return delayed, remaining
+		}
+	}
+
+	return delayed, nil
+}
+
+// Inhibit registers a shutdown inhibitor: a shutdown or reboot, requested by
+// an event or the API, waits with the lock held, up to a deadline, for all of
+// the inhibitors to be released before its phases run. The returned function releases the inhibitor, and can be
+// called more than once.
+func (c *Controller) Inhibit(name string) (release func()) {
+	c.log().Debug("shutdown inhibitor registered", "name", name)
+
+	return c.inhibitors.add(name)
+}
+
+// waitForInhibitors waits for the inhibitors of a shutdown or reboot, logging
+// the ones that delayed it.
+func (c *Controller) waitForInhibitors(timeout time.Duration) {
+	start := time.Now()
+
+	delayed, remaining := c.inhibitors.wait(timeout)
+
+	switch {
+	case len(remaining) > 0:
+		c.log().Warn("shutdown inhibitors not released in time, proceeding", "inhibitors", remaining, "timeout", timeout)
+	case len(delayed) > 0:
+		c.log().Info("shutdown delayed by inhibitors", "inhibitors", delayed, "duration", time.Since(start))
+	}
+}
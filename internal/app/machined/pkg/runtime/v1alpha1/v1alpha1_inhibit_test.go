@@ -0,0 +1,86 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/runtimetest"
+)
+
+func Test_inhibitors(t *testing.T) {
+	var i inhibitors
+
+	if delayed, remaining := i.wait(time.Minute); delayed != nil || remaining != nil {
+		t.Fatalf("wait() without inhibitors = (%v, %v), want nothing", delayed, remaining)
+	}
+
+	releaseWrite := i.add("write")
+	releaseUpgrade := i.add("upgrade")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		releaseWrite()
+		// A second release is a no-op.
+		releaseWrite()
+		releaseUpgrade()
+	}()
+
+	delayed, remaining := i.wait(time.Minute)
+	if !reflect.DeepEqual(delayed, []string{"upgrade", "write"}) || remaining != nil {
+		t.Errorf("wait() = (%v, %v), want both inhibitors released", delayed, remaining)
+	}
+
+	release := i.add("stuck")
+	defer release()
+
+	delayed, remaining = i.wait(10 * time.Millisecond)
+	if !reflect.DeepEqual(delayed, []string{"stuck"}) || !reflect.DeepEqual(remaining, []string{"stuck"}) {
+		t.Errorf("wait() = (%v, %v), want the deadline to expire", delayed, remaining)
+	}
+}
+
+func TestController_Run_Inhibited(t *testing.T) {
+	defer discardTaskLogs()()
+
+	var released int32
+
+	rec := &runtimetest.Recorder{}
+
+	seq := runtimetest.NewSequencer().SetPhases(runtime.SequenceShutdown, runtime.Phase{
+		rec.Func("shutdown", func(context.Context, *log.Logger, runtime.Runtime) error {
+			if atomic.LoadInt32(&released) == 0 {
+				t.Error("shutdown ran while an inhibitor was held")
+			}
+
+			return nil
+		}),
+	})
+
+	c := NewControllerWithRuntime(configuredRuntime(), seq)
+
+	release := c.Inhibit("write")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		atomic.StoreInt32(&released, 1)
+		release()
+	}()
+
+	// The API requests the shutdown with Run, rather than through an event.
+	if err := c.Run(runtime.SequenceShutdown, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if order := rec.Order(); !reflect.DeepEqual(order, []string{"shutdown"}) {
+		t.Errorf("order = %v, want the shutdown to run once released", order)
+	}
+}
@@ -0,0 +1,96 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+func init() {
+	for _, task := range []struct {
+		f     runtime.TaskSetupFunc
+		label string
+	}{
+		{SetupLogger, "Set up logging to the kernel log"},
+		{EnforceKSPPRequirements, "Enforce the KSPP kernel parameters"},
+		{WriteRequiredSysctls, "Write the required sysctls"},
+		{WriteRequiredSysctlsForContainer, "Write the required sysctls for a container"},
+		{SetupSystemDirectory, "Create the system directory"},
+		{MountBPFFS, "Mount the BPF filesystem"},
+		{MountCgroups, "Mount the cgroup filesystems"},
+		{MountPseudoFilesystems, "Mount the pseudo filesystems"},
+		{SetRLimit, "Set the resource limits"},
+		{WriteIMAPolicy, "Write the IMA policy"},
+		{CreateEtcNetworkFiles, "Create the network files in /etc"},
+		{CreateOSReleaseFile, "Create /etc/os-release"},
+		{SetupDiscoveryNetwork, "Configure the network for fetching the config"},
+		{LoadConfig, "Load the config"},
+		{ResetNetwork, "Reset the network configuration"},
+		{ValidateConfig, "Validate the config"},
+		{CheckExistingInstallation, "Check the install disk for an existing installation"},
+		{SetUserEnvVars, "Set the user environment variables"},
+		{StartContainerd, "Start containerd"},
+		{Install, "Install to the install disk"},
+		{MountBootPartition, "Mount the boot partition"},
+		{UnmountBootPartition, "Unmount the boot partition"},
+		{MountEphermeralPartition, "Mount the ephemeral partition"},
+		{UnmountEphemeralPartition, "Unmount the ephemeral partition"},
+		{SaveConfig, "Save the config"},
+		{SetHostname, "Set the hostname"},
+		{SetupSharedFilesystems, "Set up the shared filesystems"},
+		{VerifyInstallation, "Verify the installation"},
+		{RestoreClock, "Restore the saved clock"},
+		{SaveClock, "Save the clock"},
+		{SetupVarDirectory, "Create the /var directories"},
+		{MountOverlayFilesystems, "Mount the overlay filesystems"},
+		{UnmountOverlayFilesystems, "Unmount the overlay filesystems"},
+		{MountUserDisks, "Mount the user disks"},
+		{WriteUserFiles, "Write the user files"},
+		{WriteUserSysctls, "Write the user sysctls"},
+		{WaitForEndpoints, "Wait for the configured endpoints"},
+		{StartAllServices, "Start all services"},
+		{WaitForHealthy, "Wait for the services to become healthy"},
+		{WaitForTimeSync, "Wait for the initial time sync"},
+		{StopAllServices, "Stop all services"},
+		{StopServicesForUpgrade, "Stop the services for the upgrade"},
+		{UpdateBootloader, "Update the bootloader"},
+		{RollbackBootloader, "Make the previous installation the default boot entry"},
+		{RepairBootloader, "Repair the bootloader config"},
+		{RestoreConfig, "Restore the last known good config"},
+		{UnmountPodMounts, "Unmount the pod mounts"},
+		{UnmountSystemDiskBindMounts, "Unmount the system disk bind mounts"},
+		{CordonAndDrainNode, "Cordon and drain the node"},
+		{LeaveEtcd, "Leave the etcd cluster"},
+		{RemoveAllPods, "Remove all pods"},
+		{EnterMaintenance, "Mark the machine as in maintenance"},
+		{StopMaintenanceServices, "Stop the services for maintenance"},
+		{StartMaintenanceServices, "Start the services stopped for maintenance"},
+		{UncordonNode, "Uncordon the node"},
+		{ExitMaintenance, "Mark the machine as out of maintenance"},
+		{ResetSystemDisk, "Wipe the system disk"},
+		{WipeSystemPartitions, "Wipe the partitions of the system disk which are not preserved"},
+		{VerifyDiskAvailability, "Verify that the system disk is not in use"},
+		{Upgrade, "Upgrade to the new installer image"},
+		{StageUpgrade, "Stage the upgrade for the next boot"},
+		{LoadStagedUpgrade, "Load the upgrade staged for this boot"},
+		{ConsumeStagedUpgrade, "Remove the staged upgrade"},
+		{PerformStagedUpgrade, "Upgrade to the staged installer image"},
+		{LabelNodeAsMaster, "Label the node as a master"},
+		{ApplyConfig, "Apply the reloaded config"},
+		{RestartReloadedServices, "Restart the services of the reloaded config"},
+		{KexecPrepare, "Load the default kernel for a kexec reboot"},
+		{WriteInstalledMarker, "Mark the system as installed"},
+		{Reboot, "Reboot"},
+		{Shutdown, "Power off"},
+	} {
+		runtime.RegisterTaskLabel(task.f, task.label)
+
+		knownTasks[taskName(task.f)] = true
+	}
+}
+
+// knownTasks are the names of the labeled tasks, which the task timeouts of
+// the config are checked against.
+var knownTasks = map[string]bool{}
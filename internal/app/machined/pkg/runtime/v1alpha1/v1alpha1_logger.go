@@ -0,0 +1,75 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"log"
+	"sync"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/pkg/kmsg"
+)
+
+// kmsgPriority maps a level to the priority of the kernel log, so that e.g.
+// `dmesg --level err` lists the failed tasks.
+func kmsgPriority(level runtime.Level) kmsg.Priority {
+	switch level {
+	case runtime.LevelDebug:
+		return kmsg.PriorityDebug
+	case runtime.LevelWarn:
+		return kmsg.PriorityWarning
+	case runtime.LevelError:
+		return kmsg.PriorityErr
+	case runtime.LevelInfo:
+	}
+
+	return kmsg.PriorityInfo
+}
+
+// kmsgLogger is the default logger of the controller. It has a logger per
+// level, each writing to the kernel log with the priority of the level. A
+// level whose logger cannot be set up falls back to the standard logger.
+type kmsgLogger struct {
+	once    sync.Once
+	loggers map[runtime.Level]runtime.Logger
+}
+
+func (l *kmsgLogger) init(setup func(logger *log.Logger, prefix string, level runtime.Level) error) {
+	l.once.Do(func() {
+		l.loggers = map[runtime.Level]runtime.Logger{}
+
+		for _, level := range []runtime.Level{runtime.LevelDebug, runtime.LevelInfo, runtime.LevelWarn, runtime.LevelError} {
+			logger := &log.Logger{}
+
+			if err := setup(logger, "[talos]", level); err != nil {
+				l.loggers[level] = runtime.StandardLogger{}
+
+				continue
+			}
+
+			l.loggers[level] = runtime.StandardLogger{Logger: logger}
+		}
+	})
+}
+
+// Debug implements the runtime.Logger interface.
+func (l *kmsgLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.loggers[runtime.LevelDebug].Debug(msg, keysAndValues...)
+}
+
+// Info implements the runtime.Logger interface.
+func (l *kmsgLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.loggers[runtime.LevelInfo].Info(msg, keysAndValues...)
+}
+
+// Warn implements the runtime.Logger interface.
+func (l *kmsgLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.loggers[runtime.LevelWarn].Warn(msg, keysAndValues...)
+}
+
+// Error implements the runtime.Logger interface.
+func (l *kmsgLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.loggers[runtime.LevelError].Error(msg, keysAndValues...)
+}
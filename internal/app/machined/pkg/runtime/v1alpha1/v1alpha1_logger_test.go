@@ -0,0 +1,231 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"sync"
+	"testing"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/pkg/kmsg"
+)
+
+type recordedEntry struct {
+	level  runtime.Level
+	msg    string
+	fields map[string]interface{}
+}
+
+// recordingLogger records the entries, with the key-value pairs as a map.
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []recordedEntry
+}
+
+func (l *recordingLogger) record(level runtime.Level, msg string, keysAndValues []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fields := map[string]interface{}{}
+
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+
+	l.entries = append(l.entries, recordedEntry{level, msg, fields})
+}
+
+func (l *recordingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.record(runtime.LevelDebug, msg, keysAndValues)
+}
+
+func (l *recordingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.record(runtime.LevelInfo, msg, keysAndValues)
+}
+
+func (l *recordingLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.record(runtime.LevelWarn, msg, keysAndValues)
+}
+
+func (l *recordingLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.record(runtime.LevelError, msg, keysAndValues)
+}
+
+func TestController_SetLogger(t *testing.T) {
+	defer discardTaskLogs()()
+
+	failed := errors.New("failed")
+
+	ok := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			return nil
+		}
+	}
+
+	fail := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			return failed
+		}
+	}
+
+	logger := &recordingLogger{}
+
+	c := &Controller{
+		s: NewSequencer(),
+	}
+
+	c.SetLogger(logger)
+
+	if err := c.run(context.Background(), runtime.SequenceBoot, []runtime.Phase{{ok}, {ok, fail}}, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	var errs []recordedEntry
+
+	for _, e := range logger.entries {
+		if e.level == runtime.LevelError {
+			errs = append(errs, e)
+		}
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("error entries = %v, want the task and the sequence", errs)
+	}
+
+	task := errs[0]
+
+	if task.msg != "task failed" || task.fields["sequence"] != runtime.SequenceBoot || task.fields["phase"] != "2/2" || task.fields["task"] != "2/2" || task.fields["error"] != failed {
+		t.Errorf("task entry = %+v", task)
+	}
+
+	if _, ok := task.fields["duration"]; !ok {
+		t.Errorf("task entry = %+v, want a duration", task)
+	}
+
+	if errs[1].msg != "sequence failed" {
+		t.Errorf("sequence entry = %+v", errs[1])
+	}
+}
+
+func TestController_SkippedTask(t *testing.T) {
+	defer discardTaskLogs()()
+
+	skip := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			return runtime.Skip("not on metal")
+		}
+	}
+
+	none := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return nil
+	}
+
+	logger := &recordingLogger{}
+
+	c := &Controller{
+		s: NewSequencer(),
+	}
+
+	c.SetLogger(logger)
+
+	if err := c.run(context.Background(), runtime.SequenceBoot, []runtime.Phase{{skip}, {none}}, nil); err != nil {
+		t.Fatalf("Controller.run() error = %v, want skipped tasks to succeed", err)
+	}
+
+	reasons := map[string]interface{}{}
+
+	for _, e := range logger.entries {
+		switch e.msg {
+		case "task skipped":
+			reasons[e.fields["phase"].(string)] = e.fields["reason"]
+		case "task done":
+			t.Errorf("entry = %+v, want skipped tasks not logged as done", e)
+		}
+	}
+
+	if reasons["1/2"] != "not on metal" || reasons["2/2"] != "not applicable" {
+		t.Errorf("skip reasons = %v", reasons)
+	}
+}
+
+func TestController_DegradedTask(t *testing.T) {
+	defer discardTaskLogs()()
+
+	degrade := func(runtime.Sequence, interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			return runtime.Degrade("services not healthy: kubelet")
+		}
+	}
+
+	logger := &recordingLogger{}
+
+	c := &Controller{
+		s: NewSequencer(),
+	}
+
+	c.SetLogger(logger)
+
+	if err := c.run(context.Background(), runtime.SequenceBoot, []runtime.Phase{{degrade}}, nil); err != nil {
+		t.Fatalf("Controller.run() error = %v, want degraded tasks to succeed", err)
+	}
+
+	var warned bool
+
+	for _, e := range logger.entries {
+		if e.msg == "task degraded" {
+			warned = e.level == runtime.LevelWarn && e.fields["reason"] == "services not healthy: kubelet"
+		}
+	}
+
+	if !warned {
+		t.Errorf("entries = %+v, want a degraded task warning", logger.entries)
+	}
+
+	task := c.LastTrace().Phases[0].Tasks[0]
+
+	if task.Error != "" || task.Degraded != "services not healthy: kubelet" {
+		t.Errorf("traced task = %+v, want it degraded without an error", task)
+	}
+}
+
+func Test_kmsgLogger(t *testing.T) {
+	levels := map[runtime.Level]kmsg.Priority{}
+
+	var l kmsgLogger
+
+	l.init(func(logger *log.Logger, prefix string, level runtime.Level) error {
+		levels[level] = kmsgPriority(level)
+
+		if level == runtime.LevelDebug {
+			return errors.New("no /dev/kmsg")
+		}
+
+		logger.SetOutput(ioutil.Discard)
+
+		return nil
+	})
+
+	expected := map[runtime.Level]kmsg.Priority{
+		runtime.LevelDebug: kmsg.PriorityDebug,
+		runtime.LevelInfo:  kmsg.PriorityInfo,
+		runtime.LevelWarn:  kmsg.PriorityWarning,
+		runtime.LevelError: kmsg.PriorityErr,
+	}
+
+	for level, priority := range expected {
+		if levels[level] != priority {
+			t.Errorf("priority of %s = %d, want %d", level, levels[level], priority)
+		}
+	}
+
+	// A level that cannot be set up falls back to the standard logger.
+	if l.loggers[runtime.LevelDebug] != (runtime.StandardLogger{}) {
+		t.Errorf("debug logger = %#v, want the standard logger", l.loggers[runtime.LevelDebug])
+	}
+}
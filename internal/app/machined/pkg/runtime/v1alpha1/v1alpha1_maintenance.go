@@ -0,0 +1,186 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/system"
+	"github.com/talos-systems/talos/internal/pkg/conditions"
+	"github.com/talos-systems/talos/pkg/constants"
+	"github.com/talos-systems/talos/pkg/kubernetes"
+	"github.com/talos-systems/talos/pkg/retry"
+)
+
+// resumeTimeout is the time the services started by the resume sequence are
+// given to come up, and the node to be uncordoned, as the API server of a
+// control plane node is one of the pods that the kubelet starts again.
+const resumeTimeout = 5 * time.Minute
+
+// maintenanceServices are the operations of the system services that the
+// maintenance and resume sequences use, which tests fake.
+type maintenanceServices interface {
+	IsRunning(id string) (system.Service, bool, error)
+	Start(serviceIDs ...string) error
+	Stop(ctx context.Context, serviceIDs ...string) error
+}
+
+// EnterMaintenance represents the task for marking the machine as in
+// maintenance. It runs before any service is stopped, so that the resume
+// sequence starts the services again after a maintenance sequence that failed
+// or was aborted half way.
+func EnterMaintenance(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		if r.State().Machine().Maintenance() {
+			return runtime.ErrInMaintenance
+		}
+
+		r.State().Machine().SetMaintenance(true)
+
+		logger.Println("entering maintenance")
+
+		return nil
+	}
+}
+
+// StopMaintenanceServices represents the task for stopping the services of the
+// maintenance config, in order.
+func StopMaintenanceServices(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		return stopMaintenanceServices(ctx, logger, system.Services(r), r.Config().Machine().Maintenance().StopServices(), removePods)
+	}
+}
+
+// stopMaintenanceServices stops the services which are running. The pods are
+// removed once the kubelet is stopped, unless the CRI is already stopped,
+// which stops them along with it.
+func stopMaintenanceServices(ctx context.Context, logger *log.Logger, svcs maintenanceServices, ids []string, removePods func() error) error {
+	for _, id := range ids {
+		if _, running, err := svcs.IsRunning(id); err != nil || !running {
+			logger.Printf("skipping %s: not running", id)
+
+			continue
+		}
+
+		logger.Printf("stopping %s", id)
+
+		if err := svcs.Stop(ctx, id); err != nil {
+			return fmt.Errorf("failed to stop %s: %w", id, err)
+		}
+
+		if id != "kubelet" {
+			continue
+		}
+
+		if _, running, err := svcs.IsRunning("cri"); err != nil || !running {
+			continue
+		}
+
+		logger.Println("removing the pods")
+
+		if err := removePods(); err != nil {
+			return fmt.Errorf("failed to remove the pods: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// StartMaintenanceServices represents the task for starting the services of the
+// maintenance config again, which fails unless the machine is in maintenance.
+func StartMaintenanceServices(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		if !r.State().Machine().Maintenance() {
+			return runtime.ErrNotInMaintenance
+		}
+
+		started, err := startMaintenanceServices(logger, system.Services(r), r.Config().Machine().Maintenance().StopServices())
+		if err != nil {
+			return err
+		}
+
+		all := make([]conditions.Condition, 0, len(started))
+
+		for _, id := range started {
+			all = append(all, system.WaitForService(system.StateEventUp, id))
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, runtime.TaskTimeout(ctx, resumeTimeout))
+		defer cancel()
+
+		return conditions.WaitForAll(all...).Wait(ctx)
+	}
+}
+
+// startMaintenanceServices starts the services which are loaded, and not
+// running, and returns their IDs.
+func startMaintenanceServices(logger *log.Logger, svcs maintenanceServices, ids []string) ([]string, error) {
+	var started []string
+
+	for _, id := range ids {
+		if _, running, err := svcs.IsRunning(id); err != nil || running {
+			continue
+		}
+
+		logger.Printf("starting %s", id)
+
+		if err := svcs.Start(id); err != nil {
+			return nil, fmt.Errorf("failed to start %s: %w", id, err)
+		}
+
+		started = append(started, id)
+	}
+
+	return started, nil
+}
+
+// UncordonNode represents the task for making the node schedulable again,
+// once cordoned by the maintenance, shutdown or reboot sequence. The cordons
+// made outside of Talos are kept. A machine that has not joined Kubernetes,
+// i.e. has no kubelet kubeconfig, is skipped.
+func UncordonNode(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		if _, err = os.Stat(constants.KubeletKubeconfig); os.IsNotExist(err) {
+			return runtime.Skip("kubelet kubeconfig not found")
+		}
+
+		var hostname string
+
+		if hostname, err = os.Hostname(); err != nil {
+			return err
+		}
+
+		var kubeHelper *kubernetes.Client
+
+		if kubeHelper, err = kubernetes.NewClientFromKubeletKubeconfig(); err != nil {
+			return err
+		}
+
+		return retry.Constant(runtime.TaskTimeout(ctx, resumeTimeout), retry.WithUnits(5*time.Second), retry.WithJitter(time.Second)).Retry(func() error {
+			if ctx.Err() != nil {
+				return retry.UnexpectedError(ctx.Err())
+			}
+
+			return retry.ExpectedError(kubeHelper.Uncordon(hostname))
+		})
+	}
+}
+
+// ExitMaintenance represents the task for marking the machine as out of
+// maintenance, once the services are started again.
+func ExitMaintenance(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		r.State().Machine().SetMaintenance(false)
+
+		logger.Println("leaving maintenance")
+
+		return nil
+	}
+}
@@ -0,0 +1,219 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/v1alpha1/platform/container"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/v1alpha1/platform/metal"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/system"
+	"github.com/talos-systems/talos/pkg/config/types/v1alpha1"
+)
+
+// fakeServices are the system services of the maintenance tests, which only
+// track whether each of the loaded services is running.
+type fakeServices struct {
+	running map[string]bool
+	order   []string
+}
+
+func (s *fakeServices) IsRunning(id string) (system.Service, bool, error) {
+	running, ok := s.running[id]
+	if !ok {
+		return nil, false, fmt.Errorf("service %q not defined", id)
+	}
+
+	return nil, running, nil
+}
+
+func (s *fakeServices) Start(ids ...string) error {
+	for _, id := range ids {
+		s.running[id] = true
+		s.order = append(s.order, "start "+id)
+	}
+
+	return nil
+}
+
+func (s *fakeServices) Stop(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		s.running[id] = false
+		s.order = append(s.order, "stop "+id)
+	}
+
+	return nil
+}
+
+func TestSequencer_Maintenance(t *testing.T) {
+	cfg := &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{MachineType: "worker"}}
+
+	state := &State{
+		platform: &metal.Metal{},
+		machine:  &MachineState{},
+	}
+
+	r := NewRuntime(cfg, state)
+	s := &Sequencer{}
+
+	for _, tt := range []struct {
+		name        string
+		maintenance []string
+		resume      []string
+	}{
+		{
+			name:        "drain",
+			maintenance: []string{"EnterMaintenance", "CordonAndDrainNode", "StopMaintenanceServices"},
+			resume:      []string{"StartMaintenanceServices", "UncordonNode", "ExitMaintenance"},
+		},
+		{
+			name:        "skip drain",
+			maintenance: []string{"EnterMaintenance", "StopMaintenanceServices"},
+			resume:      []string{"StartMaintenanceServices", "ExitMaintenance"},
+		},
+		{
+			name:        "container",
+			maintenance: []string{"EnterMaintenance", "StopMaintenanceServices"},
+			resume:      []string{"StartMaintenanceServices", "ExitMaintenance"},
+		},
+	} {
+		switch tt.name {
+		case "skip drain":
+			cfg.MachineConfig.MachineMaintenance = &v1alpha1.MaintenanceConfig{MaintenanceSkipDrain: true}
+		case "container":
+			cfg.MachineConfig.MachineMaintenance = nil
+			state.platform = &container.Container{}
+		}
+
+		if got := taskNames(s.Maintenance(r)); !reflect.DeepEqual(got, tt.maintenance) {
+			t.Errorf("%s: maintenance sequence = %v, want %v", tt.name, got, tt.maintenance)
+		}
+
+		if got := taskNames(s.Resume(r)); !reflect.DeepEqual(got, tt.resume) {
+			t.Errorf("%s: resume sequence = %v, want %v", tt.name, got, tt.resume)
+		}
+	}
+}
+
+func TestMaintenanceState(t *testing.T) {
+	cfg := &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{MachineType: "worker"}}
+
+	r := NewRuntime(cfg, &State{
+		platform: &metal.Metal{},
+		machine:  &MachineState{},
+	})
+
+	logger := log.New(ioutil.Discard, "", 0)
+
+	// The services are only started in maintenance.
+	if err := StartMaintenanceServices(runtime.SequenceResume, nil)(context.Background(), logger, r); !errors.Is(err, runtime.ErrNotInMaintenance) {
+		t.Errorf("StartMaintenanceServices() error = %v, want %v", err, runtime.ErrNotInMaintenance)
+	}
+
+	if err := EnterMaintenance(runtime.SequenceMaintenance, nil)(context.Background(), logger, r); err != nil {
+		t.Fatalf("EnterMaintenance() error = %v", err)
+	}
+
+	if !r.State().Machine().Maintenance() {
+		t.Errorf("Maintenance() = false after EnterMaintenance")
+	}
+
+	if err := EnterMaintenance(runtime.SequenceMaintenance, nil)(context.Background(), logger, r); !errors.Is(err, runtime.ErrInMaintenance) {
+		t.Errorf("EnterMaintenance() error = %v, want %v", err, runtime.ErrInMaintenance)
+	}
+
+	if err := ExitMaintenance(runtime.SequenceResume, nil)(context.Background(), logger, r); err != nil {
+		t.Fatalf("ExitMaintenance() error = %v", err)
+	}
+
+	if r.State().Machine().Maintenance() {
+		t.Errorf("Maintenance() = true after ExitMaintenance")
+	}
+}
+
+func Test_stopMaintenanceServices(t *testing.T) {
+	logger := log.New(ioutil.Discard, "", 0)
+
+	for _, tt := range []struct {
+		name    string
+		ids     []string
+		order   []string
+		removed int
+	}{
+		{
+			name:    "default",
+			ids:     []string{"kubelet", "cri"},
+			order:   []string{"stop kubelet", "remove pods", "stop cri"},
+			removed: 1,
+		},
+		{
+			name:  "cri first",
+			ids:   []string{"cri", "kubelet"},
+			order: []string{"stop cri", "stop kubelet"},
+		},
+		{
+			name:  "not defined or not running",
+			ids:   []string{"etcd", "trustd", "cri"},
+			order: []string{"stop cri"},
+		},
+	} {
+		svcs := &fakeServices{running: map[string]bool{"kubelet": true, "cri": true, "trustd": false}}
+
+		removed := 0
+
+		removePods := func() error {
+			removed++
+
+			svcs.order = append(svcs.order, "remove pods")
+
+			return nil
+		}
+
+		if err := stopMaintenanceServices(context.Background(), logger, svcs, tt.ids, removePods); err != nil {
+			t.Fatalf("%s: stopMaintenanceServices() error = %v", tt.name, err)
+		}
+
+		if !reflect.DeepEqual(svcs.order, tt.order) {
+			t.Errorf("%s: stopMaintenanceServices() order = %v, want %v", tt.name, svcs.order, tt.order)
+		}
+
+		if removed != tt.removed {
+			t.Errorf("%s: stopMaintenanceServices() removed the pods %d time(s), want %d", tt.name, removed, tt.removed)
+		}
+	}
+
+	svcs := &fakeServices{running: map[string]bool{"kubelet": true, "cri": true}}
+
+	err := stopMaintenanceServices(context.Background(), logger, svcs, []string{"kubelet", "cri"}, func() error { return errors.New("cri unavailable") })
+	if err == nil || !reflect.DeepEqual(svcs.order, []string{"stop kubelet"}) {
+		t.Errorf("stopMaintenanceServices() error = %v, order = %v, want to fail once the kubelet is stopped", err, svcs.order)
+	}
+}
+
+func Test_startMaintenanceServices(t *testing.T) {
+	logger := log.New(ioutil.Discard, "", 0)
+
+	svcs := &fakeServices{running: map[string]bool{"kubelet": false, "cri": false, "trustd": true}}
+
+	started, err := startMaintenanceServices(logger, svcs, []string{"kubelet", "cri", "etcd", "trustd"})
+	if err != nil {
+		t.Fatalf("startMaintenanceServices() error = %v", err)
+	}
+
+	if expected := []string{"kubelet", "cri"}; !reflect.DeepEqual(started, expected) {
+		t.Errorf("startMaintenanceServices() = %v, want %v", started, expected)
+	}
+
+	if !svcs.running["kubelet"] || !svcs.running["cri"] {
+		t.Errorf("startMaintenanceServices() running = %v, want kubelet and cri running", svcs.running)
+	}
+}
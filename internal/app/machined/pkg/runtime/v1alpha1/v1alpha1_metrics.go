@@ -0,0 +1,131 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the duration
+// histograms. Tasks range from milliseconds to the minutes an image pull can
+// take.
+var durationBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600}
+
+// metrics collects the durations and outcomes of sequences, phases and tasks,
+// and exposes them in the Prometheus text format. The zero value is ready to
+// use.
+type metrics struct {
+	once sync.Once
+
+	registry *prometheus.Registry
+
+	sequenceDurations *prometheus.HistogramVec
+	phaseDurations    *prometheus.HistogramVec
+	taskDurations     *prometheus.HistogramVec
+
+	sequences *prometheus.CounterVec
+	tasks     *prometheus.CounterVec
+}
+
+func (m *metrics) init() {
+	m.once.Do(func() {
+		m.sequenceDurations = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "talos_sequence_duration_seconds",
+			Help:    "Duration of the sequences.",
+			Buckets: durationBuckets,
+		}, []string{"sequence"})
+		m.sequences = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "talos_sequences_total",
+			Help: "Number of completed sequences by result.",
+		}, []string{"sequence", "result"})
+		m.phaseDurations = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "talos_phase_duration_seconds",
+			Help:    "Duration of the phases.",
+			Buckets: durationBuckets,
+		}, []string{"sequence", "phase"})
+		m.taskDurations = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "talos_task_duration_seconds",
+			Help:    "Duration of the tasks.",
+			Buckets: durationBuckets,
+		}, []string{"sequence", "task"})
+		m.tasks = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "talos_tasks_total",
+			Help: "Number of completed tasks by result.",
+		}, []string{"sequence", "task", "result"})
+
+		m.registry = prometheus.NewRegistry()
+		m.registry.MustRegister(m.sequenceDurations, m.sequences, m.phaseDurations, m.taskDurations, m.tasks)
+	})
+}
+
+func (m *metrics) observeSequence(seq runtime.Sequence, d time.Duration, err error) {
+	m.init()
+
+	m.sequenceDurations.WithLabelValues(seq.String()).Observe(d.Seconds())
+	m.sequences.WithLabelValues(seq.String(), result(err)).Inc()
+}
+
+func (m *metrics) observePhase(seq runtime.Sequence, number int, d time.Duration) {
+	m.init()
+
+	m.phaseDurations.WithLabelValues(seq.String(), strconv.Itoa(number)).Observe(d.Seconds())
+}
+
+func (m *metrics) observeTask(seq runtime.Sequence, name string, d time.Duration, err error) {
+	m.init()
+
+	m.taskDurations.WithLabelValues(seq.String(), name).Observe(d.Seconds())
+	m.tasks.WithLabelValues(seq.String(), name, result(err)).Inc()
+}
+
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+
+	return "success"
+}
+
+// WriteTo writes the metrics in the Prometheus text format.
+func (m *metrics) WriteTo(w io.Writer) (int64, error) {
+	m.init()
+
+	families, err := m.registry.Gather()
+	if err != nil {
+		return 0, err
+	}
+
+	var written int64
+
+	for _, family := range families {
+		var n int
+
+		n, err = expfmt.MetricFamilyToText(w, family)
+		written += int64(n)
+
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// ServeHTTP serves the metrics for Prometheus to scrape.
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.init()
+
+	promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
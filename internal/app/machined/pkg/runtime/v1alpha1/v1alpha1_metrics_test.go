@@ -0,0 +1,74 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// nolint: scopelint
+package v1alpha1
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+func Test_metrics_WriteTo(t *testing.T) {
+	var m metrics
+
+	m.observeTask(runtime.SequenceBoot, "StartContainerd", 200*time.Millisecond, nil)
+	m.observeTask(runtime.SequenceBoot, "StartContainerd", 2*time.Second, errors.New("failed"))
+	m.observeTask(runtime.SequenceBoot, `Task"With\Quotes`, time.Millisecond, nil)
+	m.observePhase(runtime.SequenceBoot, 0, 3*time.Second)
+	m.observeSequence(runtime.SequenceBoot, 5*time.Second, nil)
+
+	var buf bytes.Buffer
+
+	n, err := m.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo() = %d, wrote %d bytes", n, buf.Len())
+	}
+
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE talos_task_duration_seconds histogram\n",
+		`talos_task_duration_seconds_bucket{sequence="boot",task="StartContainerd",le="0.1"} 0` + "\n",
+		`talos_task_duration_seconds_bucket{sequence="boot",task="StartContainerd",le="0.5"} 1` + "\n",
+		`talos_task_duration_seconds_bucket{sequence="boot",task="StartContainerd",le="5"} 2` + "\n",
+		`talos_task_duration_seconds_bucket{sequence="boot",task="StartContainerd",le="+Inf"} 2` + "\n",
+		`talos_task_duration_seconds_sum{sequence="boot",task="StartContainerd"} 2.2` + "\n",
+		`talos_task_duration_seconds_count{sequence="boot",task="StartContainerd"} 2` + "\n",
+		`talos_tasks_total{result="success",sequence="boot",task="StartContainerd"} 1` + "\n",
+		`talos_tasks_total{result="failure",sequence="boot",task="StartContainerd"} 1` + "\n",
+		`talos_tasks_total{result="success",sequence="boot",task="Task\"With\\Quotes"} 1` + "\n",
+		`talos_phase_duration_seconds_count{phase="0",sequence="boot"} 1` + "\n",
+		`talos_sequence_duration_seconds_bucket{sequence="boot",le="10"} 1` + "\n",
+		`talos_sequences_total{result="success",sequence="boot"} 1` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func Test_metrics_WriteTo_Empty(t *testing.T) {
+	var (
+		m   metrics
+		buf bytes.Buffer
+	)
+
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+
+	if strings.Contains(buf.String(), "{") {
+		t.Errorf("expected no samples, got:\n%s", buf.String())
+	}
+}
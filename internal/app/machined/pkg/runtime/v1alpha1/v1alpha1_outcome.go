@@ -0,0 +1,143 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+// outcomeQueueSize is the number of outcomes waiting for delivery after which
+// new outcomes are dropped.
+const outcomeQueueSize = 16
+
+// outcomeDeliveryTimeout bounds the delivery of a single outcome.
+const outcomeDeliveryTimeout = 30 * time.Second
+
+// WebhookOutcomeSink is an `OutcomeSink` that posts each outcome as JSON to
+// a URL.
+type WebhookOutcomeSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookOutcomeSink initializes and returns a `WebhookOutcomeSink`.
+func NewWebhookOutcomeSink(url string) *WebhookOutcomeSink {
+	return &WebhookOutcomeSink{
+		URL:    url,
+		Client: &http.Client{},
+	}
+}
+
+type webhookOutcome struct {
+	Sequence   string  `json:"sequence"`
+	Result     string  `json:"result"`
+	Duration   float64 `json:"duration"`
+	FailedTask string  `json:"failedTask,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Report implements the OutcomeSink interface.
+func (w *WebhookOutcomeSink) Report(ctx context.Context, o runtime.Outcome) error {
+	b, err := json.Marshal(&webhookOutcome{
+		Sequence:   o.Sequence.String(),
+		Result:     string(o.Result),
+		Duration:   o.Duration.Seconds(),
+		FailedTask: o.FailedTask,
+		Error:      o.Error,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	//nolint: errcheck
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", w.URL, resp.Status)
+	}
+
+	return nil
+}
+
+type outcomeDelivery struct {
+	sink    runtime.OutcomeSink
+	outcome runtime.Outcome
+}
+
+// outcomeQueue delivers outcomes in the background, in order, so that a slow
+// sink never delays a sequence. Delivery is best-effort: outcomes are dropped
+// if the queue is full, and failures are only logged. The zero value is ready
+// to use.
+type outcomeQueue struct {
+	once sync.Once
+	ch   chan outcomeDelivery
+}
+
+func (q *outcomeQueue) push(sink runtime.OutcomeSink, o runtime.Outcome) {
+	q.once.Do(func() {
+		q.ch = make(chan outcomeDelivery, outcomeQueueSize)
+
+		go q.deliver()
+	})
+
+	select {
+	case q.ch <- outcomeDelivery{sink: sink, outcome: o}:
+	default:
+		log.Printf("dropping outcome of %s sequence: delivery queue is full", o.Sequence.String())
+	}
+}
+
+func (q *outcomeQueue) deliver() {
+	for d := range q.ch {
+		ctx, cancel := context.WithTimeout(context.Background(), outcomeDeliveryTimeout)
+
+		if err := d.sink.Report(ctx, d.outcome); err != nil {
+			log.Printf("failed to report outcome of %s sequence: %v", d.outcome.Sequence.String(), err)
+		}
+
+		cancel()
+	}
+}
+
+// newOutcome builds the outcome of a sequence from its error and trace.
+func newOutcome(seq runtime.Sequence, duration time.Duration, err error, trace *Trace) runtime.Outcome {
+	o := runtime.Outcome{
+		Sequence: seq,
+		Result:   runtime.OutcomeResultSuccess,
+		Duration: duration,
+	}
+
+	if err != nil {
+		o.Result = runtime.OutcomeResultFailure
+		o.Error = err.Error()
+	}
+
+	if trace != nil {
+		o.FailedTask = trace.FailedTask()
+	}
+
+	return o
+}
@@ -0,0 +1,114 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+type channelOutcomeSink chan runtime.Outcome
+
+func (c channelOutcomeSink) Report(ctx context.Context, o runtime.Outcome) error {
+	c <- o
+
+	return nil
+}
+
+func TestWebhookOutcomeSink(t *testing.T) {
+	received := make(chan webhookOutcome, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var o webhookOutcome
+
+		if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		received <- o
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookOutcomeSink(srv.URL)
+
+	err := sink.Report(context.Background(), runtime.Outcome{
+		Sequence:   runtime.SequenceUpgrade,
+		Result:     runtime.OutcomeResultFailure,
+		Duration:   1500 * time.Millisecond,
+		FailedTask: "Upgrade",
+		Error:      "failed",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := webhookOutcome{
+		Sequence:   "upgrade",
+		Result:     "failure",
+		Duration:   1.5,
+		FailedTask: "Upgrade",
+		Error:      "failed",
+	}
+
+	if o := <-received; o != expected {
+		t.Errorf("webhook received %+v, want %+v", o, expected)
+	}
+
+	failing := httptest.NewServer(http.NotFoundHandler())
+	defer failing.Close()
+
+	sink.URL = failing.URL
+
+	if err = sink.Report(context.Background(), runtime.Outcome{}); err == nil {
+		t.Error("WebhookOutcomeSink.Report() with a 404 response = nil, want error")
+	}
+}
+
+func Test_outcomeQueue(t *testing.T) {
+	var q outcomeQueue
+
+	sink := make(channelOutcomeSink)
+
+	q.push(sink, runtime.Outcome{Sequence: runtime.SequenceBoot})
+	q.push(sink, runtime.Outcome{Sequence: runtime.SequenceShutdown})
+
+	for _, expected := range []runtime.Sequence{runtime.SequenceBoot, runtime.SequenceShutdown} {
+		select {
+		case o := <-sink:
+			if o.Sequence != expected {
+				t.Errorf("outcome of %s sequence delivered, want %s", o.Sequence, expected)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("outcome was not delivered")
+		}
+	}
+}
+
+func Test_newOutcome(t *testing.T) {
+	trace := &Trace{
+		Phases: []TracePhase{
+			{Tasks: []TraceTask{{Name: "SaveConfig"}}},
+			{Tasks: []TraceTask{{Name: "StartAllServices", Error: "failed"}}},
+		},
+	}
+
+	o := newOutcome(runtime.SequenceBoot, time.Second, nil, trace)
+	if o.Result != runtime.OutcomeResultSuccess {
+		t.Errorf("newOutcome() result = %s, want %s", o.Result, runtime.OutcomeResultSuccess)
+	}
+
+	o = newOutcome(runtime.SequenceBoot, time.Second, context.Canceled, trace)
+	if o.Result != runtime.OutcomeResultFailure || o.FailedTask != "StartAllServices" || o.Error != context.Canceled.Error() {
+		t.Errorf("newOutcome() = %+v, want a failure of StartAllServices", o)
+	}
+}
@@ -0,0 +1,185 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/docker/distribution/reference"
+	"golang.org/x/sys/unix"
+
+	"github.com/talos-systems/talos/api/machine"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/pkg/constants"
+)
+
+// minBootFreeSpace is the space the installer needs on the boot partition, as
+// it writes the kernel and the initramfs of the upgrade next to the running
+// ones.
+const minBootFreeSpace = 128 * 1024 * 1024
+
+// Preflight returns the pre-flight checks of the destructive sequences. The
+// other sequences have none.
+func (*Sequencer) Preflight(seq runtime.Sequence, r runtime.Runtime, data interface{}) []runtime.PreflightCheck {
+	if r.State().Platform().Mode() == runtime.ModeContainer {
+		return nil
+	}
+
+	switch seq {
+	case runtime.SequenceUpgrade:
+		in, ok := data.(*machine.UpgradeRequest)
+		if !ok {
+			return nil
+		}
+
+		checks := []runtime.PreflightCheck{
+			checkInstallerImage(in.GetImage()),
+			checkFreeSpace(constants.BootMountPoint, minBootFreeSpace),
+		}
+
+		// A staged upgrade neither drains the node nor leaves etcd.
+		if !in.GetStage() {
+			checks = append(checks, checkClusterEndpoint())
+		}
+
+		return checks
+	case runtime.SequenceReset:
+		in, ok := data.(*machine.ResetRequest)
+		if !ok || !in.GetGraceful() {
+			return nil
+		}
+
+		return []runtime.PreflightCheck{
+			checkClusterEndpoint(),
+		}
+	}
+
+	return nil
+}
+
+// checkInstallerImage checks that the image is a valid reference.
+func checkInstallerImage(image string) runtime.PreflightCheck {
+	return runtime.PreflightCheck{
+		Name: "installer image",
+		Check: func(context.Context, runtime.Runtime) error {
+			return ValidateInstallerImage(image)
+		},
+	}
+}
+
+// ValidateInstallerImage checks that the image is a valid reference. The API
+// checks it before pulling the image, as well.
+func ValidateInstallerImage(image string) error {
+	if image == "" {
+		return fmt.Errorf("no installer image")
+	}
+
+	if _, err := reference.ParseNormalizedNamed(image); err != nil {
+		return fmt.Errorf("invalid installer image %q: %w", image, err)
+	}
+
+	return nil
+}
+
+// checkFreeSpace checks that the filesystem mounted at the path has the space
+// available.
+func checkFreeSpace(path string, space uint64) runtime.PreflightCheck {
+	return runtime.PreflightCheck{
+		Name: "disk space",
+		Check: func(context.Context, runtime.Runtime) error {
+			var stat unix.Statfs_t
+
+			if err := unix.Statfs(path, &stat); err != nil {
+				return fmt.Errorf("failed to stat %s: %w", path, err)
+			}
+
+			// nolint: unconvert
+			if available := stat.Bavail * uint64(stat.Bsize); available < space {
+				return fmt.Errorf("%d MiB available on %s, %d MiB required", available/1024/1024, path, space/1024/1024)
+			}
+
+			return nil
+		},
+	}
+}
+
+// checkClusterEndpoint checks that the control plane endpoint is reachable,
+// which draining the node and leaving etcd require.
+func checkClusterEndpoint() runtime.PreflightCheck {
+	return runtime.PreflightCheck{
+		Name: "cluster endpoint",
+		Check: func(ctx context.Context, r runtime.Runtime) error {
+			endpoint := r.Config().Cluster().Endpoint()
+			if endpoint == nil {
+				return fmt.Errorf("no control plane endpoint")
+			}
+
+			host := endpoint.Host
+			if endpoint.Port() == "" {
+				host = net.JoinHostPort(endpoint.Hostname(), "443")
+			}
+
+			var d net.Dialer
+
+			conn, err := d.DialContext(ctx, "tcp", host)
+			if err != nil {
+				return fmt.Errorf("control plane endpoint %s is unreachable: %w", endpoint, err)
+			}
+
+			return conn.Close()
+		},
+	}
+}
+
+// Preflight runs the pre-flight checks of the sequence without running it, so
+// that an operator can verify that the machine is ready for it.
+func (c *Controller) Preflight(seq runtime.Sequence, data interface{}) ([]runtime.PreflightResult, error) {
+	if c.r == nil {
+		return nil, runtime.ErrUndefinedRuntime
+	}
+
+	if err := c.checkConfigured(seq); err != nil {
+		return nil, err
+	}
+
+	// The phases are not run, but building them validates the data.
+	if _, err := c.phases(seq, data); err != nil {
+		return nil, err
+	}
+
+	return c.preflight(seq, data), nil
+}
+
+// SetPreflight makes Run run the pre-flight checks of a sequence before the
+// sequence, which fails with a `runtime.PreflightError` if any of the checks
+// fail. It is disabled by default.
+func (c *Controller) SetPreflight(enabled bool) {
+	c.preflightEnabled = enabled
+}
+
+func (c *Controller) preflight(seq runtime.Sequence, data interface{}) []runtime.PreflightResult {
+	checks := c.s.Preflight(seq, c.r, data)
+	if len(checks) == 0 {
+		return nil
+	}
+
+	c.log().Info("pre-flight checks starting", "sequence", seq, "checks", len(checks))
+
+	results := runtime.RunPreflight(context.Background(), c.r, checks)
+
+	for _, result := range results {
+		if result.Err != nil {
+			c.log().Error("pre-flight check failed", "sequence", seq, "check", result.Name, "duration", result.Duration, "error", result.Err)
+
+			continue
+		}
+
+		c.log().Info("pre-flight check passed", "sequence", seq, "check", result.Name, "duration", result.Duration)
+	}
+
+	return results
+}
@@ -0,0 +1,126 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/runtimetest"
+	"github.com/talos-systems/talos/pkg/config/types/v1alpha1"
+)
+
+func TestController_Preflight(t *testing.T) {
+	defer discardTaskLogs()()
+
+	errEndpoint := errors.New("connection refused")
+
+	rec := &runtimetest.Recorder{}
+
+	s := runtimetest.NewSequencer().
+		SetPhases(runtime.SequenceReboot,
+			runtime.Phase{rec.Task("stop services")},
+		).
+		SetPreflight(runtime.SequenceReboot,
+			runtime.PreflightCheck{Name: "disk space", Check: func(context.Context, runtime.Runtime) error { return nil }},
+			runtime.PreflightCheck{Name: "cluster endpoint", Check: func(context.Context, runtime.Runtime) error { return errEndpoint }},
+		)
+
+	c := NewControllerWithRuntime(runtimetest.NewRuntime(nil, runtime.ModeMetal), s)
+
+	c.SetLogger(&recordingLogger{})
+
+	results, err := c.Preflight(runtime.SequenceReboot, nil)
+	if err != nil {
+		t.Fatalf("Controller.Preflight() error = %v", err)
+	}
+
+	if len(results) != 2 || results[0].Err != nil || results[1].Err != errEndpoint {
+		t.Errorf("Controller.Preflight() = %+v, want the disk space check to pass and the endpoint check to fail", results)
+	}
+
+	if len(rec.Order()) != 0 {
+		t.Errorf("Controller.Preflight() ran the tasks %v", rec.Order())
+	}
+
+	// The checks only run before the sequences once enabled.
+	if err = c.Run(runtime.SequenceReboot, nil); err != nil {
+		t.Fatalf("Controller.Run() error = %v", err)
+	}
+
+	c.SetPreflight(true)
+
+	err = c.Run(runtime.SequenceReboot, nil)
+	if !errors.Is(err, runtime.ErrPreflight) {
+		t.Fatalf("Controller.Run() error = %v, want %v", err, runtime.ErrPreflight)
+	}
+
+	if want := "reboot sequence: pre-flight checks failed: cluster endpoint: connection refused"; err.Error() != want {
+		t.Errorf("Controller.Run() error = %q, want %q", err, want)
+	}
+
+	if len(rec.Order()) != 1 {
+		t.Errorf("tasks ran %v, want the tasks of the first sequence only", rec.Order())
+	}
+
+	if _, err = c.Preflight(runtime.SequenceUpgrade, nil); !errors.Is(err, runtime.ErrUnconfigured) {
+		t.Errorf("Controller.Preflight() of the upgrade sequence error = %v, want %v", err, runtime.ErrUnconfigured)
+	}
+}
+
+func Test_checkInstallerImage(t *testing.T) {
+	for image, valid := range map[string]bool{
+		"":                                       false,
+		"ghcr.io/talos-systems/installer:v0.6.0": true,
+		"docker.io/autonomy/installer:latest":    true,
+		"docker.io/autonomy/INSTALLER:latest":    false,
+	} {
+		err := checkInstallerImage(image).Check(context.Background(), nil)
+		if (err == nil) != valid {
+			t.Errorf("checkInstallerImage(%q) error = %v, want valid %v", image, err, valid)
+		}
+	}
+}
+
+func Test_checkFreeSpace(t *testing.T) {
+	if err := checkFreeSpace(os.TempDir(), 1).Check(context.Background(), nil); err != nil {
+		t.Errorf("checkFreeSpace() error = %v", err)
+	}
+
+	if err := checkFreeSpace(os.TempDir(), 1<<62).Check(context.Background(), nil); err == nil {
+		t.Error("checkFreeSpace() of 4 EiB error = nil")
+	}
+}
+
+func Test_checkClusterEndpoint(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reachable := runtimetest.NewRuntime(&v1alpha1.Config{
+		ClusterConfig: &v1alpha1.ClusterConfig{
+			ControlPlane: &v1alpha1.ControlPlaneConfig{
+				Endpoint: &v1alpha1.Endpoint{URL: &url.URL{Scheme: "https", Host: l.Addr().String()}},
+			},
+		},
+	}, runtime.ModeMetal)
+
+	if err = checkClusterEndpoint().Check(context.Background(), reachable); err != nil {
+		t.Errorf("checkClusterEndpoint() error = %v", err)
+	}
+
+	// nolint: errcheck
+	l.Close()
+
+	if err = checkClusterEndpoint().Check(context.Background(), reachable); err == nil {
+		t.Error("checkClusterEndpoint() of a closed endpoint error = nil")
+	}
+}
@@ -0,0 +1,190 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/pkg/config"
+)
+
+// reloadable maps the fields of the config that can be changed without a
+// reboot to the service that applies them. A field matches when its path is,
+// or starts with, the key.
+var reloadable = map[string]string{
+	"machine.time":                     "timed",
+	"machine.network.nameservers":      "networkd",
+	"machine.network.extraHostEntries": "networkd",
+}
+
+// Reload fetches the config from its source, the platform, again, and applies
+// the changes that do not require a reboot. The config is not read from the
+// boot partition, as it is the one saved by machined, even if persistence is
+// enabled. A config that fails to validate, or that changes fields which
+// require a reboot, is rejected, and the running config is kept.
+func (c *Controller) Reload() error {
+	if c.r.Config() == nil {
+		return errors.New("no config loaded")
+	}
+
+	b, err := fetchConfig(c.r)
+	if err != nil {
+		return fmt.Errorf("failed to fetch config: %w", err)
+	}
+
+	cfg, err := config.NewFromBytes(b)
+	if err != nil {
+		return fmt.Errorf("rejecting config: %w", err)
+	}
+
+	if err = cfg.Validate(c.r.State().Platform().Mode()); err != nil {
+		return fmt.Errorf("rejecting config: %w", err)
+	}
+
+	if err = applyPlatformSettings(log.New(log.Writer(), log.Prefix(), log.Flags()), c.r.State().Platform(), cfg); err != nil {
+		return err
+	}
+
+	changed := changedFields(c.r.Config(), cfg)
+	if len(changed) == 0 {
+		log.Printf("config reload: no changes")
+
+		return nil
+	}
+
+	services, pending := classifyChanges(changed)
+
+	log.Printf("config reload: changed fields: %s", strings.Join(changed, ", "))
+
+	if len(pending) > 0 {
+		return fmt.Errorf("rejecting config: changes to %s require a reboot", strings.Join(pending, ", "))
+	}
+
+	if b, err = cfg.Bytes(); err != nil {
+		return err
+	}
+
+	return c.Run(runtime.SequenceReload, &runtime.ConfigReload{
+		Config:   b,
+		Services: services,
+	})
+}
+
+// classifyChanges returns the services to restart for the changed fields that
+// are reloadable, and the changed fields that are not.
+func classifyChanges(changed []string) (services, pending []string) {
+	seen := map[string]struct{}{}
+
+	for _, field := range changed {
+		svc, ok := reloadableService(field)
+		if !ok {
+			pending = append(pending, field)
+
+			continue
+		}
+
+		if _, ok = seen[svc]; !ok {
+			seen[svc] = struct{}{}
+
+			services = append(services, svc)
+		}
+	}
+
+	sort.Strings(services)
+
+	return services, pending
+}
+
+func reloadableService(field string) (string, bool) {
+	for prefix, svc := range reloadable {
+		if field == prefix || strings.HasPrefix(field, prefix+".") {
+			return svc, true
+		}
+	}
+
+	return "", false
+}
+
+// changedFields returns the paths, made of the YAML keys, of the fields that
+// differ between two configs. Only the paths are returned, so that secrets
+// never end up in the logs.
+func changedFields(a, b interface{}) []string {
+	var fields []string
+
+	diff(reflect.ValueOf(a), reflect.ValueOf(b), "", &fields)
+
+	sort.Strings(fields)
+
+	return fields
+}
+
+func diff(a, b reflect.Value, path string, fields *[]string) {
+	for a.IsValid() && (a.Kind() == reflect.Ptr || a.Kind() == reflect.Interface) && !a.IsNil() {
+		a = a.Elem()
+	}
+
+	for b.IsValid() && (b.Kind() == reflect.Ptr || b.Kind() == reflect.Interface) && !b.IsNil() {
+		b = b.Elem()
+	}
+
+	if a.IsValid() && b.IsValid() && a.Kind() == reflect.Struct && b.Kind() == reflect.Struct && a.Type() == b.Type() {
+		for i := 0; i < a.NumField(); i++ {
+			name := yamlName(a.Type().Field(i))
+			if name == "" {
+				continue
+			}
+
+			if path != "" {
+				name = path + "." + name
+			}
+
+			diff(a.Field(i), b.Field(i), name, fields)
+		}
+
+		return
+	}
+
+	if !reflect.DeepEqual(interfaceOf(a), interfaceOf(b)) {
+		*fields = append(*fields, path)
+	}
+}
+
+// interfaceOf returns the value as an interface, treating nil pointers as
+// absent so that a missing section equals an empty one only when both are
+// missing.
+func interfaceOf(v reflect.Value) interface{} {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil
+	}
+
+	return v.Interface()
+}
+
+func yamlName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+
+	tag := strings.Split(f.Tag.Get("yaml"), ",")[0]
+	if tag == "-" {
+		return ""
+	}
+
+	if tag == "" {
+		return strings.ToLower(f.Name)
+	}
+
+	return tag
+}
@@ -0,0 +1,84 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// nolint: scopelint
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/talos-systems/talos/pkg/config/types/v1alpha1"
+)
+
+func Test_changedFields(t *testing.T) {
+	tests := []struct {
+		name string
+		a    *v1alpha1.Config
+		b    *v1alpha1.Config
+		want []string
+	}{
+		{
+			name: "equal",
+			a:    &v1alpha1.Config{ConfigVersion: "v1alpha1"},
+			b:    &v1alpha1.Config{ConfigVersion: "v1alpha1"},
+			want: nil,
+		},
+		{
+			name: "nested field",
+			a: &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{
+				MachineTime: &v1alpha1.TimeConfig{TimeServers: []string{"a"}},
+			}},
+			b: &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{
+				MachineTime: &v1alpha1.TimeConfig{TimeServers: []string{"b"}},
+			}},
+			want: []string{"machine.time.servers"},
+		},
+		{
+			name: "added section",
+			a:    &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{}},
+			b: &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{
+				MachineNetwork: &v1alpha1.NetworkConfig{NameServers: []string{"1.1.1.1"}},
+			}},
+			want: []string{"machine.network"},
+		},
+		{
+			name: "multiple fields",
+			a: &v1alpha1.Config{ConfigVersion: "v1alpha1", MachineConfig: &v1alpha1.MachineConfig{
+				MachineType: "worker",
+			}},
+			b: &v1alpha1.Config{ConfigVersion: "v1alpha2", MachineConfig: &v1alpha1.MachineConfig{
+				MachineType: "controlplane",
+			}},
+			want: []string{"machine.type", "version"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changedFields(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changedFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_classifyChanges(t *testing.T) {
+	services, pending := classifyChanges([]string{
+		"machine.install.image",
+		"machine.network.extraHostEntries",
+		"machine.network.nameservers",
+		"machine.network.networkInterfaces",
+		"machine.time",
+		"machine.timeout",
+	})
+
+	if want := []string{"networkd", "timed"}; !reflect.DeepEqual(services, want) {
+		t.Errorf("services = %v, want %v", services, want)
+	}
+
+	if want := []string{"machine.install.image", "machine.network.networkInterfaces", "machine.timeout"}; !reflect.DeepEqual(pending, want) {
+		t.Errorf("pending = %v, want %v", pending, want)
+	}
+}
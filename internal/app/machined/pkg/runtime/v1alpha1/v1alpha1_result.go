@@ -0,0 +1,127 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+// defaultHistorySize is the number of results of the most recent sequences
+// kept by default.
+const defaultHistorySize = 10
+
+// newSequenceResult returns the result of a sequence from its trace. Phases
+// that did not run, e.g. after a failed phase, have no tasks.
+func newSequenceResult(seq runtime.Sequence, start time.Time, duration time.Duration, err error, trace *Trace) runtime.SequenceResult {
+	result := runtime.SequenceResult{
+		Sequence: seq,
+		Start:    start,
+		Duration: duration,
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if trace == nil {
+		return result
+	}
+
+	for _, p := range trace.Phases {
+		phase := runtime.PhaseResult{}
+
+		var end time.Duration
+
+		for i, t := range p.Tasks {
+			if i == 0 || t.Start < phase.Start {
+				phase.Start = t.Start
+			}
+
+			if t.Start+t.Duration > end {
+				end = t.Start + t.Duration
+			}
+
+			phase.Tasks = append(phase.Tasks, runtime.TaskResult{
+				Name:     t.Name,
+				Start:    t.Start,
+				Duration: t.Duration,
+				Error:    t.Error,
+				Degraded: t.Degraded,
+			})
+		}
+
+		if len(p.Tasks) > 0 {
+			phase.Duration = end - phase.Start
+		}
+
+		result.Phases = append(result.Phases, phase)
+	}
+
+	return result
+}
+
+// LastResult returns the result of the most recent sequence that ran, and
+// false if no sequence ran yet.
+func (c *Controller) LastResult() (runtime.SequenceResult, bool) {
+	c.traceMu.Lock()
+	defer c.traceMu.Unlock()
+
+	if c.lastResult == nil {
+		return runtime.SequenceResult{}, false
+	}
+
+	return *c.lastResult, true
+}
+
+// History returns the results of the most recent sequences that ran, the
+// oldest first. The number of results kept is set with SetHistorySize.
+func (c *Controller) History() []runtime.SequenceResult {
+	c.traceMu.Lock()
+	defer c.traceMu.Unlock()
+
+	return append([]runtime.SequenceResult(nil), c.history...)
+}
+
+// SetHistorySize sets the number of results of the most recent sequences that
+// are kept, dropping the oldest results in excess. Zero, or a negative size,
+// restores the default of 10.
+func (c *Controller) SetHistorySize(n int) {
+	c.traceMu.Lock()
+	defer c.traceMu.Unlock()
+
+	c.historySize = n
+	c.history = trimHistory(c.history, c.historySizeLocked())
+}
+
+// pushResult records the result of a sequence as the last result, and in the
+// history.
+func (c *Controller) pushResult(result runtime.SequenceResult) {
+	c.traceMu.Lock()
+	defer c.traceMu.Unlock()
+
+	c.lastResult = &result
+	c.history = trimHistory(append(c.history, result), c.historySizeLocked())
+}
+
+// historySizeLocked must be called with traceMu held.
+func (c *Controller) historySizeLocked() int {
+	if c.historySize <= 0 {
+		return defaultHistorySize
+	}
+
+	return c.historySize
+}
+
+// trimHistory drops the oldest results in excess of the size. The results are
+// copied, so that the dropped ones are not retained by the backing array.
+func trimHistory(history []runtime.SequenceResult, size int) []runtime.SequenceResult {
+	if len(history) <= size {
+		return history
+	}
+
+	return append([]runtime.SequenceResult(nil), history[len(history)-size:]...)
+}
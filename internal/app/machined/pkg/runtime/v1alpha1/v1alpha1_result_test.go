@@ -0,0 +1,107 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+func Test_newSequenceResult(t *testing.T) {
+	trace := &Trace{
+		Sequence: "boot",
+		Phases: []TracePhase{
+			{Tasks: []TraceTask{
+				{Name: "MountBootPartition", Start: 0, Duration: time.Second},
+			}},
+			{Tasks: []TraceTask{
+				// Tasks of a phase run concurrently, and are recorded as
+				// they finish.
+				{Name: "SetUserEnvVars", Start: 2 * time.Second, Duration: time.Second},
+				{Name: "StartContainerd", Start: time.Second, Duration: 30 * time.Second, Error: "failed"},
+			}},
+			{},
+		},
+	}
+
+	start := time.Now()
+
+	result := newSequenceResult(runtime.SequenceBoot, start, 40*time.Second, errors.New("failed"), trace)
+
+	if result.Sequence != runtime.SequenceBoot || !result.Start.Equal(start) || result.Duration != 40*time.Second || result.Error != "failed" {
+		t.Fatalf("result = %+v", result)
+	}
+
+	if len(result.Phases) != 3 {
+		t.Fatalf("phases = %+v, want 3", result.Phases)
+	}
+
+	if p := result.Phases[1]; p.Start != time.Second || p.Duration != 30*time.Second || len(p.Tasks) != 2 {
+		t.Errorf("phase 2 = %+v, want it to span its tasks", p)
+	}
+
+	if p := result.Phases[2]; p.Duration != 0 || len(p.Tasks) != 0 {
+		t.Errorf("phase 3 = %+v, want no tasks", p)
+	}
+
+	if slowest, ok := result.Slowest(); !ok || slowest.Name != "StartContainerd" || slowest.Error != "failed" {
+		t.Errorf("slowest = %+v, want StartContainerd", slowest)
+	}
+}
+
+func TestController_LastResult(t *testing.T) {
+	c := &Controller{}
+
+	if _, ok := c.LastResult(); ok {
+		t.Error("LastResult() should report no result before a sequence runs")
+	}
+
+	c.lastResult = &runtime.SequenceResult{Sequence: runtime.SequenceBoot}
+
+	if result, ok := c.LastResult(); !ok || result.Sequence != runtime.SequenceBoot {
+		t.Errorf("LastResult() = %+v, %v", result, ok)
+	}
+}
+
+func TestController_History(t *testing.T) {
+	c := &Controller{}
+
+	if history := c.History(); len(history) != 0 {
+		t.Errorf("History() = %+v, want none before a sequence runs", history)
+	}
+
+	for i := 0; i < defaultHistorySize+2; i++ {
+		c.pushResult(runtime.SequenceResult{Duration: time.Duration(i)})
+	}
+
+	history := c.History()
+	if len(history) != defaultHistorySize {
+		t.Fatalf("History() has %d results, want %d", len(history), defaultHistorySize)
+	}
+
+	if history[0].Duration != 2 || history[len(history)-1].Duration != defaultHistorySize+1 {
+		t.Errorf("History() = %+v, want the most recent results, the oldest first", history)
+	}
+
+	if result, ok := c.LastResult(); !ok || result.Duration != defaultHistorySize+1 {
+		t.Errorf("LastResult() = %+v, %v, want the most recent result", result, ok)
+	}
+
+	c.SetHistorySize(3)
+
+	if history = c.History(); len(history) != 3 || history[0].Duration != defaultHistorySize-1 {
+		t.Errorf("History() = %+v, want the 3 most recent results", history)
+	}
+
+	// The returned results are a copy.
+	history[0].Duration = 0
+
+	if c.History()[0].Duration == 0 {
+		t.Error("History() should return a copy of the results")
+	}
+}
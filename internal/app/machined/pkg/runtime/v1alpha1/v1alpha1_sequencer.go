@@ -5,12 +5,17 @@
 package v1alpha1
 
 import (
+	"sync"
+
 	"github.com/talos-systems/talos/api/machine"
 	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
 )
 
 // Sequencer implements the sequencer interface.
-type Sequencer struct{}
+type Sequencer struct {
+	extensionsMu sync.RWMutex
+	extensions   map[runtime.ExtensionPoint][]extension
+}
 
 // NewSequencer intializes and returns a sequencer.
 func NewSequencer() *Sequencer {
@@ -36,6 +41,24 @@ func (p PhaseList) AppendWhen(when bool, tasks ...runtime.TaskSetupFunc) PhaseLi
 	return p
 }
 
+// AppendPhases appends whole phases, e.g. the ones of the extensions, to the
+// phase list.
+func (p PhaseList) AppendPhases(phases ...runtime.Phase) PhaseList {
+	return append(p, phases...)
+}
+
+// AppendOverlapping appends a phase that runs concurrently with the phase
+// appended after it. Only tasks that do not depend on the next phase, and
+// that the next phase does not depend on, belong in an overlapping phase.
+func (p PhaseList) AppendOverlapping(tasks ...runtime.TaskSetupFunc) PhaseList {
+	// Copy the tasks, as appending to the variadic slice would write to the
+	// backing array of the caller.
+	phase := make([]runtime.TaskSetupFunc, 0, len(tasks)+1)
+	phase = append(phase, tasks...)
+
+	return p.Append(append(phase, runtime.OverlapWithNext)...)
+}
+
 // Initialize is the initialize sequence. The primary goals of this sequence is
 // to load the config and enforce kernel security requirements.
 func (*Sequencer) Initialize(r runtime.Runtime) []runtime.Phase {
@@ -76,6 +99,9 @@ func (*Sequencer) Initialize(r runtime.Runtime) []runtime.Phase {
 			MountBootPartition,
 		).Append(
 			LoadConfig,
+		).AppendWhen(
+			r.State().Machine().Installed(),
+			LoadStagedUpgrade,
 			// We unmount the boot partition here to simplify subsequent sequences.
 			// If we leave it mounted, it becomes tricky trying to figure out if we
 			// need to mount the boot partition.
@@ -92,35 +118,55 @@ func (*Sequencer) Initialize(r runtime.Runtime) []runtime.Phase {
 	return phases
 }
 
-// Install is the install sequence.
-func (*Sequencer) Install(r runtime.Runtime) []runtime.Phase {
+// Install is the install sequence. It can be aborted until the installer
+// runs. Once the system is installed, i.e. once a previous install sequence
+// wrote the installed marker, it only reports that the install is skipped,
+// unless the install is forced, so that a repeated install does not wipe the
+// disk again. If the install
+// disk has the partitions of another installation, and the policy for
+// existing installations keeps them, only the check of the install disk
+// runs.
+func (*Sequencer) Install(r runtime.Runtime, in *runtime.InstallRequest) []runtime.Phase {
 	phases := PhaseList{}
 
 	switch r.State().Platform().Mode() {
 	case runtime.ModeContainer:
 		return nil
 	default:
-		if !r.State().Machine().Installed() {
-			phases = phases.Append(
-				ValidateConfig,
-			).Append(
-				SetUserEnvVars,
-			).Append(
-				StartContainerd,
-			).Append(
-				Install,
-			).Append(
-				MountBootPartition,
-			).Append(
-				SaveConfig,
-			).Append(
-				UnmountBootPartition,
-			).Append(
-				StopAllServices,
-			).Append(
-				Reboot,
-			)
+		if r.State().Machine().Installed() && !in.Force {
+			return phases.Append(SkipInstall)
 		}
+
+		phases = phases.Append(
+			ValidateConfig,
+		).Append(
+			CheckExistingInstallation,
+		)
+
+		if keepExistingInstallation(r, in) {
+			return phases
+		}
+
+		phases = phases.Append(
+			SetUserEnvVars,
+		).Append(
+			StartContainerd,
+		).Append(
+			Install,
+			runtime.PointOfNoReturn,
+		).Append(
+			MountBootPartition,
+		).Append(
+			SaveConfig,
+		).Append(
+			WriteInstalledMarker,
+		).Append(
+			UnmountBootPartition,
+		).Append(
+			StopAllServices,
+		).Append(
+			Reboot,
+		)
 	}
 
 	return phases
@@ -129,9 +175,39 @@ func (*Sequencer) Install(r runtime.Runtime) []runtime.Phase {
 // Boot is the boot sequence. This primary goal if this sequence is to apply
 // user supplied settings and start the services for the specific machine type.
 // This sequence should never be reached if an installation is not found.
-func (*Sequencer) Boot(r runtime.Runtime) []runtime.Phase {
+//
+// If an upgrade is staged, the boot sequence performs it instead, and reboots
+// into the new installation. The staged upgrade is consumed before the
+// installer runs, so if the staged image is no longer valid (e.g. it can not
+// be pulled anymore), the boot sequence fails, and the machine reboots into
+// the current installation, which then boots normally.
+func (s *Sequencer) Boot(r runtime.Runtime) []runtime.Phase {
 	phases := PhaseList{}
 
+	if r.State().Platform().Mode() != runtime.ModeContainer && r.State().Machine().StagedUpgrade() != nil {
+		return phases.Append(
+			MountBootPartition,
+		).Append(
+			ValidateConfig,
+		).Append(
+			ConsumeStagedUpgrade,
+		).Append(
+			UnmountBootPartition,
+		).Append(
+			SetUserEnvVars,
+		).Append(
+			StartContainerd,
+		).Append(
+			VerifyDiskAvailability,
+		).Append(
+			PerformStagedUpgrade,
+		).Append(
+			StopAllServices,
+		).Append(
+			Reboot,
+		)
+	}
+
 	phases = phases.AppendWhen(
 		r.State().Platform().Mode() != runtime.ModeContainer,
 		MountBootPartition,
@@ -139,6 +215,13 @@ func (*Sequencer) Boot(r runtime.Runtime) []runtime.Phase {
 		ValidateConfig,
 	).Append(
 		SaveConfig,
+		// The hostname must be set after the config is saved, since saving the
+		// config applies the platform hostname, and before any phase that
+		// starts services.
+	).Append(
+		SetHostname,
+	).AppendPhases(
+		s.extensionPhases(runtime.ExtensionPointAfterNetwork)...,
 	).Append(
 		SetUserEnvVars,
 	).Append(
@@ -152,6 +235,9 @@ func (*Sequencer) Boot(r runtime.Runtime) []runtime.Phase {
 	).AppendWhen(
 		r.State().Platform().Mode() != runtime.ModeContainer,
 		VerifyInstallation,
+	).AppendWhen(
+		r.State().Platform().Mode() != runtime.ModeContainer,
+		RestoreClock,
 	).Append(
 		SetupVarDirectory,
 	).AppendWhen(
@@ -164,40 +250,99 @@ func (*Sequencer) Boot(r runtime.Runtime) []runtime.Phase {
 		WriteUserFiles,
 		WriteUserSysctls,
 	).Append(
-		StartAllServices,
+		WaitForEndpoints,
 	).AppendWhen(
-		r.Config().Machine().Type() != runtime.MachineTypeJoin,
-		LabelNodeAsMaster,
+		r.State().Platform().Mode() != runtime.ModeContainer && r.Config().Machine().Time().WaitForSync(),
+		WaitForTimeSync,
+	).AppendPhases(
+		s.extensionPhases(runtime.ExtensionPointBeforeServices)...,
+	).Append(
+		StartAllServices,
+	).AppendPhases(
+		s.extensionPhases(runtime.ExtensionPointAfterServices)...,
 	).AppendWhen(
 		r.State().Platform().Mode() != runtime.ModeContainer,
 		UpdateBootloader,
+	).AppendWhen(
+		!r.Config().Machine().HealthCheck().Skip(),
+		WaitForHealthy,
 	)
 
 	return phases
 }
 
-// Reboot is the reboot sequence.
-func (*Sequencer) Reboot(r runtime.Runtime) []runtime.Phase {
+// Deferred returns the tasks that are run in the background once the specified
+// sequence completes. These are not required for the sequence to be considered
+// successful, and are canceled when the machine begins to shut down.
+func (*Sequencer) Deferred(seq runtime.Sequence, r runtime.Runtime) runtime.Phase {
+	var phase runtime.Phase
+
+	switch seq {
+	case runtime.SequenceBoot:
+		if r.Config().Machine().Type() != runtime.MachineTypeJoin {
+			phase = append(phase, LabelNodeAsMaster)
+		}
+
+		// The node is cordoned by the shutdown or reboot that preceded the
+		// boot, and is made schedulable once the services are up.
+		if r.State().Platform().Mode() != runtime.ModeContainer {
+			phase = append(phase, UncordonNode)
+		}
+	}
+
+	return phase
+}
+
+// ErrorPolicy returns the policy for handling phase errors in the specified
+// sequence. Teardown sequences run to completion, since stopping half way
+// leaves the machine in a worse state than attempting the remaining phases.
+func (*Sequencer) ErrorPolicy(seq runtime.Sequence) runtime.ErrorPolicy {
+	switch seq {
+	case runtime.SequenceShutdown, runtime.SequenceReboot:
+		return runtime.ErrorPolicyCollect
+	default:
+		return runtime.ErrorPolicyFailFast
+	}
+}
+
+// Reboot is the reboot sequence. With kexec enabled in the config, the kernel
+// of the default boot label is loaded once the services are stopped, and the
+// machine boots into it rather than through the firmware.
+func (s *Sequencer) Reboot(r runtime.Runtime) []runtime.Phase {
 	phases := PhaseList{}
 
 	switch r.State().Platform().Mode() {
 	case runtime.ModeContainer:
-		phases = phases.Append(
+		phases = phases.AppendPhases(
+			s.extensionPhases(runtime.ExtensionPointBeforeShutdown)...,
+		).Append(
 			StopAllServices,
 		).Append(
 			Reboot,
 		)
 	default:
-		phases = phases.Append(
+		phases = phases.AppendWhen(
+			!shutdownTimeouts(r).SkipDrain(),
+			CordonAndDrainNode,
+		).AppendPhases(
+			s.extensionPhases(runtime.ExtensionPointBeforeShutdown)...,
+		).Append(
+			SaveClock,
 			StopAllServices,
+		).AppendWhen(
+			shutdownTimeouts(r).Kexec(),
+			KexecPrepare,
 		).Append(
 			UnmountOverlayFilesystems,
 			UnmountPodMounts,
+			unmountPhaseTimeout(r),
 		).Append(
 			UnmountBootPartition,
 			UnmountEphemeralPartition,
+			unmountPhaseTimeout(r),
 		).Append(
 			UnmountSystemDiskBindMounts,
+			unmountPhaseTimeout(r),
 		).Append(
 			Reboot,
 		)
@@ -206,7 +351,9 @@ func (*Sequencer) Reboot(r runtime.Runtime) []runtime.Phase {
 	return phases
 }
 
-// Reset is the reset sequence.
+// Reset is the reset sequence. It can be aborted until the machine leaves
+// etcd, or the services are stopped, whichever comes first. The whole system
+// disk is wiped, unless the request preserves some of its partitions.
 func (*Sequencer) Reset(r runtime.Runtime, in *machine.ResetRequest) []runtime.Phase {
 	phases := PhaseList{}
 
@@ -224,11 +371,13 @@ func (*Sequencer) Reset(r runtime.Runtime, in *machine.ResetRequest) []runtime.P
 		).AppendWhen(
 			in.GetGraceful() && (r.Config().Machine().Type() != runtime.MachineTypeJoin),
 			LeaveEtcd,
+			runtime.PointOfNoReturn,
 		).AppendWhen(
 			in.GetGraceful(),
 			RemoveAllPods,
 		).Append(
 			StopAllServices,
+			runtime.PointOfNoReturn,
 		).Append(
 			UnmountOverlayFilesystems,
 			UnmountPodMounts,
@@ -237,8 +386,12 @@ func (*Sequencer) Reset(r runtime.Runtime, in *machine.ResetRequest) []runtime.P
 			UnmountEphemeralPartition,
 		).Append(
 			UnmountSystemDiskBindMounts,
-		).Append(
+		).AppendWhen(
+			len(in.GetPreserve()) == 0,
 			ResetSystemDisk,
+		).AppendWhen(
+			len(in.GetPreserve()) != 0,
+			WipeSystemPartitions,
 		).Append(
 			Reboot,
 		)
@@ -247,28 +400,91 @@ func (*Sequencer) Reset(r runtime.Runtime, in *machine.ResetRequest) []runtime.P
 	return phases
 }
 
-// Shutdown is the shutdown sequence.
-func (*Sequencer) Shutdown(r runtime.Runtime) []runtime.Phase {
+// Rollback is the rollback sequence. It makes the previous installation the
+// default boot entry, and reboots into it. It can be aborted until the boot
+// entry is changed.
+func (s *Sequencer) Rollback(r runtime.Runtime) []runtime.Phase {
+	phases := PhaseList{}
+
+	phases = phases.Append(
+		RollbackBootloader,
+		runtime.PointOfNoReturn,
+	)
+
+	return append(phases, s.Reboot(r)...)
+}
+
+// Recover is the recover sequence. It repairs the installation on the boot
+// partition, without reinstalling: the bootloader config is regenerated from
+// the installations found, and the config is replaced with the one of the last
+// healthy boot if the initialize sequence loaded no valid config (or if the
+// `talos.recover.config` kernel parameter is set). The sequence only runs when
+// it is in the startup plan, before the boot sequence. It can be aborted until
+// the bootloader config is repaired.
+//
+// Mounting the boot partition, validating and saving the config, and
+// unmounting the boot partition are the phases of the install sequence, and
+// behave the same. Repairing the bootloader config and restoring the config
+// are specific to this sequence.
+func (*Sequencer) Recover(r runtime.Runtime) []runtime.Phase {
 	phases := PhaseList{}
 
 	switch r.State().Platform().Mode() {
 	case runtime.ModeContainer:
+		return nil
+	default:
 		phases = phases.Append(
+			MountBootPartition,
+		).Append(
+			RepairBootloader,
+			runtime.PointOfNoReturn,
+		).Append(
+			RestoreConfig,
+		).Append(
+			ValidateConfig,
+		).Append(
+			SaveConfig,
+		).Append(
+			UnmountBootPartition,
+		)
+	}
+
+	return phases
+}
+
+// Shutdown is the shutdown sequence.
+func (s *Sequencer) Shutdown(r runtime.Runtime) []runtime.Phase {
+	phases := PhaseList{}
+
+	switch r.State().Platform().Mode() {
+	case runtime.ModeContainer:
+		phases = phases.AppendPhases(
+			s.extensionPhases(runtime.ExtensionPointBeforeShutdown)...,
+		).Append(
 			StopAllServices,
 		).Append(
 			Shutdown,
 		)
 	default:
-		phases = phases.Append(
+		phases = phases.AppendWhen(
+			!shutdownTimeouts(r).SkipDrain(),
+			CordonAndDrainNode,
+		).AppendPhases(
+			s.extensionPhases(runtime.ExtensionPointBeforeShutdown)...,
+		).Append(
+			SaveClock,
 			StopAllServices,
 		).Append(
 			UnmountOverlayFilesystems,
 			UnmountPodMounts,
+			unmountPhaseTimeout(r),
 		).Append(
 			UnmountBootPartition,
 			UnmountEphemeralPartition,
+			unmountPhaseTimeout(r),
 		).Append(
 			UnmountSystemDiskBindMounts,
+			unmountPhaseTimeout(r),
 		).Append(
 			Shutdown,
 		)
@@ -277,7 +493,10 @@ func (*Sequencer) Shutdown(r runtime.Runtime) []runtime.Phase {
 	return phases
 }
 
-// Upgrade is the upgrade sequence.
+// Upgrade is the upgrade sequence. Like the reset sequence, it can be aborted
+// until the machine leaves etcd, or its pods are removed. The API pulls
+// and verifies the installer image before the sequence runs, so that an image
+// that fails verification leaves the node untouched.
 func (*Sequencer) Upgrade(r runtime.Runtime, in *machine.UpgradeRequest) []runtime.Phase {
 	phases := PhaseList{}
 
@@ -285,13 +504,23 @@ func (*Sequencer) Upgrade(r runtime.Runtime, in *machine.UpgradeRequest) []runti
 	case runtime.ModeContainer:
 		return nil
 	default:
+		if in.GetStage() {
+			return phases.Append(
+				StageUpgrade,
+			)
+		}
+
 		phases = phases.Append(
 			CordonAndDrainNode,
 		).AppendWhen(
 			!in.GetPreserve() && (r.Config().Machine().Type() != runtime.MachineTypeJoin),
 			LeaveEtcd,
+			runtime.PointOfNoReturn,
 		).Append(
 			RemoveAllPods,
+			// Without leaving etcd, e.g. when preserving the data, removing
+			// the pods is the first change that is not undone.
+			runtime.PointOfNoReturn,
 		).Append(
 			StopServicesForUpgrade,
 		).Append(
@@ -315,3 +544,56 @@ func (*Sequencer) Upgrade(r runtime.Runtime, in *machine.UpgradeRequest) []runti
 
 	return phases
 }
+
+// Reload is the reload sequence. It applies the changes of a new config that
+// do not require a reboot. The config is applied first, so the sequence can
+// not be aborted once it runs.
+func (*Sequencer) Reload(r runtime.Runtime, in *runtime.ConfigReload) []runtime.Phase {
+	phases := PhaseList{}
+
+	phases = phases.Append(
+		ApplyConfig,
+		runtime.PointOfNoReturn,
+	).AppendWhen(
+		len(in.Services) > 0,
+		RestartReloadedServices,
+	)
+
+	return phases
+}
+
+// Maintenance is the maintenance sequence. It drains the node, and stops the
+// services of the maintenance config, while the machine API keeps running, so
+// that the machine can be serviced without shutting it down. The resume
+// sequence starts the services again.
+func (*Sequencer) Maintenance(r runtime.Runtime) []runtime.Phase {
+	phases := PhaseList{}
+
+	phases = phases.Append(
+		EnterMaintenance,
+	).AppendWhen(
+		r.State().Platform().Mode() != runtime.ModeContainer && !r.Config().Machine().Maintenance().SkipDrain(),
+		CordonAndDrainNode,
+	).Append(
+		StopMaintenanceServices,
+	)
+
+	return phases
+}
+
+// Resume is the sequence resuming from maintenance. It starts the services
+// that the maintenance sequence stopped, and uncordons the node.
+func (*Sequencer) Resume(r runtime.Runtime) []runtime.Phase {
+	phases := PhaseList{}
+
+	phases = phases.Append(
+		StartMaintenanceServices,
+	).AppendWhen(
+		r.State().Platform().Mode() != runtime.ModeContainer && !r.Config().Machine().Maintenance().SkipDrain(),
+		UncordonNode,
+	).Append(
+		ExitMaintenance,
+	)
+
+	return phases
+}
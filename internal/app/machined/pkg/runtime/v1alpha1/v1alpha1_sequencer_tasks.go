@@ -9,25 +9,32 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/hashicorp/go-multierror"
+	"github.com/talos-systems/go-procfs/procfs"
 	"golang.org/x/sys/unix"
+	"google.golang.org/grpc"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 
 	"github.com/talos-systems/talos/api/machine"
+	timeapi "github.com/talos-systems/talos/api/time"
 	installer "github.com/talos-systems/talos/cmd/installer/pkg/install"
 	"github.com/talos-systems/talos/internal/app/machined/internal/install"
 	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
@@ -38,14 +45,18 @@ import (
 	"github.com/talos-systems/talos/internal/pkg/conditions"
 	"github.com/talos-systems/talos/internal/pkg/cri"
 	"github.com/talos-systems/talos/internal/pkg/etcd"
+	"github.com/talos-systems/talos/internal/pkg/kernel/kexec"
 	"github.com/talos-systems/talos/internal/pkg/kernel/kspp"
 	"github.com/talos-systems/talos/internal/pkg/kmsg"
 	"github.com/talos-systems/talos/internal/pkg/mount"
+	"github.com/talos-systems/talos/pkg/blockdevice/filesystem/vfat"
+	"github.com/talos-systems/talos/pkg/blockdevice/filesystem/xfs"
 	"github.com/talos-systems/talos/pkg/blockdevice/probe"
 	"github.com/talos-systems/talos/pkg/blockdevice/util"
 	"github.com/talos-systems/talos/pkg/config"
 	"github.com/talos-systems/talos/pkg/config/types/v1alpha1"
 	"github.com/talos-systems/talos/pkg/constants"
+	"github.com/talos-systems/talos/pkg/grpc/dialer"
 	"github.com/talos-systems/talos/pkg/kubernetes"
 	"github.com/talos-systems/talos/pkg/retry"
 	"github.com/talos-systems/talos/pkg/sysctl"
@@ -403,14 +414,38 @@ func SetupDiscoveryNetwork(seq runtime.Sequence, data interface{}) runtime.TaskE
 	}
 }
 
-// LoadConfig represents the LoadConfig task.
+// LoadConfig represents the LoadConfig task. The config is validated in the
+// mode of the platform before it is set, so that an invalid config is rejected
+// before any sequence (e.g. a destructive install) runs. A machine which is
+// not installed waits for USB storage first, as set by the config, so that an
+// install disk attached over USB is found. The data is the startup plan, if
+// any: a config which fails to download is only left to the recover sequence
+// when the plan runs it.
 func LoadConfig(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		plan, _ := data.(runtime.StartupPlan)
+
 		download := func() error {
 			var b []byte
 
 			b, e := fetchConfig(r)
+			if e == nil {
+				e = validateLoadedConfig(ctx, r, b)
+			}
+
 			if e != nil {
+				// The recover sequence restores the last known good config,
+				// and the sequences which require the config fail until then.
+				if !plan.Contains(runtime.SequenceRecover) {
+					return e
+				}
+
+				if _, err = os.Stat(constants.LastKnownGoodConfigPath); err == nil {
+					logger.Printf("failed to download the config, leaving it to the recover sequence: %v", e)
+
+					return nil
+				}
+
 				return e
 			}
 
@@ -443,6 +478,10 @@ func LoadConfig(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFun
 			return err
 		}
 
+		if err = validateLoadedConfig(ctx, r, b); err != nil {
+			return err
+		}
+
 		if err = r.SetConfig(b); err != nil {
 			return err
 		}
@@ -451,31 +490,34 @@ func LoadConfig(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFun
 	}
 }
 
-// SaveConfig represents the SaveConfig task.
-func SaveConfig(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
-	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
-		hostname, err := r.State().Platform().Hostname()
-		if err != nil {
+// validateLoadedConfig validates the config in the mode of the platform, once
+// USB storage settled if the machine is not installed.
+func validateLoadedConfig(ctx context.Context, r runtime.Runtime, b []byte) error {
+	cfg, err := config.NewFromBytes(b)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	if r.State().Platform().Mode() != runtime.ModeContainer && !r.State().Machine().Installed() {
+		if err = waitForUSBDelay(ctx, cfg); err != nil {
 			return err
 		}
+	}
 
-		if hostname != nil {
-			r.Config().Machine().Network().SetHostname(string(hostname))
-		}
+	if err = cfg.Validate(r.State().Platform().Mode()); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
 
-		addrs, err := r.State().Platform().ExternalIPs()
-		if err != nil {
-			logger.Printf("certificates will be created without external IPs: %v", err)
-		}
+	return nil
+}
 
-		sans := make([]string, 0, len(addrs))
-		for _, addr := range addrs {
-			sans = append(sans, addr.String())
+// SaveConfig represents the SaveConfig task.
+func SaveConfig(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		if err = applyPlatformSettings(logger, r.State().Platform(), r.Config()); err != nil {
+			return err
 		}
 
-		r.Config().Machine().Security().SetCertSANs(sans)
-		r.Config().Cluster().SetCertSANs(sans)
-
 		var b []byte
 
 		b, err = r.Config().Bytes()
@@ -487,6 +529,34 @@ func SaveConfig(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFun
 	}
 }
 
+// applyPlatformSettings sets the hostname and certificate SANs of the config
+// from the platform.
+func applyPlatformSettings(logger *log.Logger, p runtime.Platform, cfg runtime.Configurator) error {
+	hostname, err := p.Hostname()
+	if err != nil {
+		return err
+	}
+
+	if hostname != nil {
+		cfg.Machine().Network().SetHostname(string(hostname))
+	}
+
+	addrs, err := p.ExternalIPs()
+	if err != nil {
+		logger.Printf("certificates will be created without external IPs: %v", err)
+	}
+
+	sans := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		sans = append(sans, addr.String())
+	}
+
+	cfg.Machine().Security().SetCertSANs(sans)
+	cfg.Cluster().SetCertSANs(sans)
+
+	return nil
+}
+
 func fetchConfig(r runtime.Runtime) (out []byte, err error) {
 	var b []byte
 
@@ -520,6 +590,56 @@ func fetchConfig(r runtime.Runtime) (out []byte, err error) {
 	return b, nil
 }
 
+// ApplyConfig swaps the config of a reload into the runtime, and saves it so
+// that the restarted services read it.
+func ApplyConfig(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		in, ok := data.(*runtime.ConfigReload)
+		if !ok {
+			return runtime.InvalidSequenceData(in, data)
+		}
+
+		if err = r.SetConfig(in.Config); err != nil {
+			return err
+		}
+
+		return ioutil.WriteFile(constants.ConfigPath, in.Config, 0600)
+	}
+}
+
+// RestartReloadedServices restarts the services that apply the changes of a
+// reload. Services that are not running are left alone.
+func RestartReloadedServices(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		in, ok := data.(*runtime.ConfigReload)
+		if !ok {
+			return runtime.InvalidSequenceData(in, data)
+		}
+
+		svcs := system.Services(r)
+
+		for _, id := range in.Services {
+			if _, running, e := svcs.IsRunning(id); e != nil || !running {
+				logger.Printf("skipping restart of %s: not running", id)
+
+				continue
+			}
+
+			logger.Printf("restarting %s", id)
+
+			if err = svcs.Stop(ctx, id); err != nil {
+				return fmt.Errorf("failed to stop %s: %w", id, err)
+			}
+
+			if err = svcs.Start(id); err != nil {
+				return fmt.Errorf("failed to start %s: %w", id, err)
+			}
+		}
+
+		return nil
+	}
+}
+
 // ValidateConfig validates the config.
 func ValidateConfig(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
@@ -563,16 +683,303 @@ func StartContainerd(seq runtime.Sequence, data interface{}) runtime.TaskExecuti
 
 		system.Services(r).LoadAndStart(svc)
 
-		ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		ctx, cancel := context.WithTimeout(ctx, runtime.TaskTimeout(ctx, 5*time.Minute))
 		defer cancel()
 
 		return system.WaitForService(system.StateEventUp, svc.ID(r)).Wait(ctx)
 	}
 }
 
+// WaitForEndpoints represents the task to wait for the configured endpoints to
+// become reachable. The timeout bounds the wait for all of the endpoints, each
+// of them being waited for with the time that the previous ones left.
+func WaitForEndpoints(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		waitFor := r.Config().Machine().WaitFor()
+
+		if len(waitFor.Endpoints()) == 0 {
+			return nil
+		}
+
+		deadline := time.Now().Add(runtime.TaskTimeout(ctx, waitFor.Timeout()))
+
+		for _, endpoint := range waitFor.Endpoints() {
+			logger.Printf("waiting for %s", endpoint)
+
+			// An endpoint is checked once, even if the previous ones used up
+			// the timeout.
+			err = retry.Constant(time.Until(deadline), retry.WithUnits(time.Second), retry.WithJitter(250*time.Millisecond)).Retry(func() error {
+				if ctx.Err() != nil {
+					return retry.UnexpectedError(ctx.Err())
+				}
+
+				return retry.ExpectedError(checkEndpoint(ctx, endpoint))
+			})
+
+			if err != nil {
+				err = fmt.Errorf("endpoint %s is not reachable: %w", endpoint, err)
+
+				if waitFor.OnTimeout() == runtime.WaitForActionMaintenance {
+					return fmt.Errorf("%w: %s", runtime.ErrMaintenance, err)
+				}
+
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func checkEndpoint(ctx context.Context, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+
+		// nolint: errcheck
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+
+		return nil
+	}
+
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", strings.TrimPrefix(endpoint, "tcp://"))
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// SaveClock represents the task for saving the current time, so that it can be
+// restored on the next boot by machines without an RTC.
+func SaveClock(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		// The clock is not critical to shutting down, so failures are only
+		// logged.
+		if err = writeClockFile(constants.ClockFilePath, time.Now()); err != nil {
+			logger.Printf("failed to save clock: %v", err)
+		}
+
+		return nil
+	}
+}
+
+// RestoreClock represents the task for restoring the time saved by
+// `SaveClock`, if the current time is older.
+func RestoreClock(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		saved, err := readClockFile(constants.ClockFilePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				logger.Printf("no saved clock found")
+			} else {
+				logger.Printf("ignoring saved clock: %v", err)
+			}
+
+			return nil
+		}
+
+		t, restore := restoredClock(time.Now(), saved)
+		if !restore {
+			return nil
+		}
+
+		logger.Printf("system time is older than the saved clock, setting time to %s", t)
+
+		timeval := unix.NsecToTimeval(t.UnixNano())
+
+		if err = unix.Settimeofday(&timeval); err != nil {
+			return fmt.Errorf("failed to restore clock: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// SetHostname represents the task for assigning the hostname of the machine
+// from the first of the `HostnameSources` that provides one.
+func SetHostname(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		hostname, source, err := decideHostname(r, HostnameSources)
+		if err != nil {
+			return err
+		}
+
+		if hostname == "" {
+			return errors.New("none of the hostname sources provided a hostname")
+		}
+
+		logger.Printf("using hostname %q from %s", hostname, source)
+
+		r.Config().Machine().Network().SetHostname(hostname)
+
+		// The hostname is managed by the container runtime in container mode.
+		if r.State().Platform().Mode() == runtime.ModeContainer {
+			return nil
+		}
+
+		parts := strings.SplitN(hostname, ".", 2)
+
+		if err = unix.Sethostname([]byte(parts[0])); err != nil {
+			return fmt.Errorf("failed to set hostname: %w", err)
+		}
+
+		if len(parts) > 1 {
+			if err = unix.Setdomainname([]byte(parts[1])); err != nil {
+				return fmt.Errorf("failed to set domainname: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// WaitForTimeSync represents the task to wait for timed to complete the
+// initial time sync. It runs before the other services are started, so it
+// starts timed, and networkd which timed depends on.
+func WaitForTimeSync(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		system.Services(r).LoadAndStart(
+			&services.Networkd{},
+			&services.Timed{},
+		)
+
+		conn, err := grpc.DialContext(
+			ctx,
+			fmt.Sprintf("%s://%s", "unix", constants.TimeSocketPath),
+			grpc.WithInsecure(),
+			grpc.WithContextDialer(dialer.DialUnix()),
+		)
+		if err != nil {
+			return err
+		}
+
+		// nolint: errcheck
+		defer conn.Close()
+
+		client := timeapi.NewTimeServiceClient(conn)
+
+		logger.Printf("waiting for the initial time sync")
+
+		err = retry.Constant(runtime.TaskTimeout(ctx, r.Config().Machine().Time().WaitForSyncTimeout()), retry.WithUnits(time.Second), retry.WithJitter(250*time.Millisecond)).Retry(func() error {
+			if ctx.Err() != nil {
+				return retry.UnexpectedError(ctx.Err())
+			}
+
+			resp, e := client.TimeReady(ctx, &empty.Empty{})
+			if e != nil {
+				return retry.ExpectedError(e)
+			}
+
+			if len(resp.Messages) == 0 {
+				return retry.ExpectedError(errors.New("no time sync status in the response"))
+			}
+
+			if !resp.Messages[0].Ready {
+				return retry.ExpectedError(errors.New("time is not synced"))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("initial time sync did not complete: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// WaitForHealthy represents the task to wait for the services of the health
+// check to become healthy, which completes the boot sequence degraded once the
+// timeout elapses, reporting the services which are not healthy. Once they are
+// healthy, the config is saved as the last known good one, for the recover
+// sequence to restore.
+func WaitForHealthy(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		healthCheck := r.Config().Machine().HealthCheck()
+
+		ids := healthCheck.Services()
+		if len(ids) == 0 {
+			ids = []string{"kubelet"}
+
+			if r.Config().Machine().Type() != runtime.MachineTypeJoin {
+				ids = append(ids, "etcd")
+			}
+		}
+
+		logger.Printf("waiting for %s to become healthy", strings.Join(ids, ", "))
+
+		ctx, cancel := context.WithTimeout(ctx, runtime.TaskTimeout(ctx, healthCheck.Timeout()))
+		defer cancel()
+
+		errs := make([]error, len(ids))
+
+		var wg sync.WaitGroup
+
+		for i, id := range ids {
+			wg.Add(1)
+
+			go func(i int, id string) {
+				defer wg.Done()
+
+				errs[i] = system.WaitForService(system.StateEventUp, id).Wait(ctx)
+			}(i, id)
+		}
+
+		wg.Wait()
+
+		var unhealthy []string
+
+		for i, id := range ids {
+			if errs[i] != nil {
+				logger.Printf("service %q is not healthy: %v", id, errs[i])
+
+				unhealthy = append(unhealthy, id)
+			}
+		}
+
+		if len(unhealthy) == 0 {
+			if r.State().Platform().Mode() == runtime.ModeContainer {
+				return nil
+			}
+
+			return saveLastKnownGoodConfig()
+		}
+
+		// A sequence canceled in the meantime fails rather than completes.
+		if ctx.Err() == context.Canceled {
+			return ctx.Err()
+		}
+
+		return runtime.Degrade("services not healthy: " + strings.Join(unhealthy, ", "))
+	}
+}
+
 // StartAllServices represents the task to start the system services.
 func StartAllServices(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		// Services such as the kubelet register with the hostname they start
+		// with.
+		if err = requireHostname(); err != nil {
+			return err
+		}
+
 		svcs := system.Services(r)
 
 		svcs.Load(
@@ -620,7 +1027,7 @@ func StartAllServices(seq runtime.Sequence, data interface{}) runtime.TaskExecut
 			all = append(all, cond)
 		}
 
-		ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		ctx, cancel := context.WithTimeout(ctx, runtime.TaskTimeout(ctx, 5*time.Minute))
 
 		defer cancel()
 
@@ -644,12 +1051,107 @@ func StopServicesForUpgrade(seq runtime.Sequence, data interface{}) runtime.Task
 // StopAllServices represents the StopAllServices task.
 func StopAllServices(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
-		system.Services(nil).Shutdown()
+		gracePeriod := runtime.TaskTimeout(ctx, shutdownTimeouts(r).GracePeriod())
+
+		err = withTimeout(ctx, gracePeriod, func() error {
+			system.Services(nil).Shutdown()
+
+			return nil
+		})
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			logger.Printf("services did not stop within %s, proceeding", gracePeriod)
+
+			return nil
+		}
+
+		return err
+	}
+}
+
+// RollbackBootloader represents the RollbackBootloader task.
+func RollbackBootloader(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		var label string
+
+		if label, err = syslinux.Rollback(); err != nil {
+			return err
+		}
+
+		logger.Printf("default boot entry set to %q", label)
+
+		return nil
+	}
+}
+
+// RepairBootloader represents the RepairBootloader task.
+func RepairBootloader(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		label, repaired, err := syslinux.Repair()
+		if err != nil {
+			return err
+		}
+
+		if repaired {
+			logger.Printf("bootloader config regenerated with the default boot entry %q", label)
+		} else {
+			logger.Printf("bootloader config is intact, with the default boot entry %q", label)
+		}
 
 		return nil
 	}
 }
 
+// RestoreConfig represents the RestoreConfig task. It replaces the config
+// with the one of the last healthy boot if no valid config is loaded, i.e. if
+// the initialize sequence could neither load it from disk, nor download it, or
+// if the kernel parameter requests it.
+func RestoreConfig(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		restore := false
+
+		if p := procfs.ProcCmdline().Get(constants.KernelParamRecoverConfig).First(); p != nil {
+			if restore, err = strconv.ParseBool(*p); err != nil {
+				return fmt.Errorf("invalid %s kernel parameter: %w", constants.KernelParamRecoverConfig, err)
+			}
+		}
+
+		if !restore {
+			if r.Config() == nil {
+				logger.Printf("config is not loaded")
+			} else {
+				if err = r.Config().Validate(r.State().Platform().Mode()); err == nil {
+					logger.Printf("config is valid, keeping it")
+
+					return nil
+				}
+
+				logger.Printf("config is invalid: %v", err)
+			}
+		}
+
+		b, err := ioutil.ReadFile(constants.LastKnownGoodConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to read the last known good config: %w", err)
+		}
+
+		logger.Printf("restoring the last known good config")
+
+		return r.SetConfig(b)
+	}
+}
+
+// saveLastKnownGoodConfig keeps a copy of the config of a healthy boot, for
+// the recover sequence to restore. The boot partition must be mounted.
+func saveLastKnownGoodConfig() error {
+	b, err := ioutil.ReadFile(constants.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(constants.LastKnownGoodConfigPath, b, 0600)
+}
+
 // VerifyInstallation represents the VerifyInstallation task.
 func VerifyInstallation(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
@@ -939,49 +1441,53 @@ func UnmountOverlayFilesystems(seq runtime.Sequence, data interface{}) runtime.T
 			return err
 		}
 
-		if err = mount.Unmount(mountpoints); err != nil {
-			return err
-		}
-
-		return nil
+		return withUnmountTimeout(ctx, r, func() error {
+			return mount.Unmount(mountpoints)
+		})
 	}
 }
 
 // UnmountPodMounts represents the UnmountPodMounts task.
 func UnmountPodMounts(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
-		var b []byte
-
-		if b, err = ioutil.ReadFile("/proc/self/mounts"); err != nil {
-			return err
-		}
+		return withUnmountTimeout(ctx, r, func() error {
+			return unmountPodMounts(logger)
+		})
+	}
+}
 
-		rdr := bytes.NewReader(b)
+func unmountPodMounts(logger *log.Logger) (err error) {
+	var b []byte
 
-		scanner := bufio.NewScanner(rdr)
-		for scanner.Scan() {
-			fields := strings.Fields(scanner.Text())
+	if b, err = ioutil.ReadFile("/proc/self/mounts"); err != nil {
+		return err
+	}
 
-			if len(fields) < 2 {
-				continue
-			}
+	rdr := bytes.NewReader(b)
 
-			mountpoint := fields[1]
-			if strings.HasPrefix(mountpoint, constants.EphemeralMountPoint+"/") {
-				logger.Printf("unmounting %s\n", mountpoint)
+	scanner := bufio.NewScanner(rdr)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
 
-				if err = unix.Unmount(mountpoint, 0); err != nil {
-					return fmt.Errorf("error unmounting %s: %w", mountpoint, err)
-				}
-			}
+		if len(fields) < 2 {
+			continue
 		}
 
-		if err = scanner.Err(); err != nil {
-			return err
+		mountpoint := fields[1]
+		if strings.HasPrefix(mountpoint, constants.EphemeralMountPoint+"/") {
+			logger.Printf("unmounting %s\n", mountpoint)
+
+			if err = unix.Unmount(mountpoint, 0); err != nil {
+				return fmt.Errorf("error unmounting %s: %w", mountpoint, err)
+			}
 		}
+	}
 
-		return nil
+	if err = scanner.Err(); err != nil {
+		return err
 	}
+
+	return nil
 }
 
 // UnmountSystemDiskBindMounts represents the UnmountSystemDiskBindMounts task.
@@ -989,41 +1495,52 @@ func UnmountSystemDiskBindMounts(seq runtime.Sequence, data interface{}) runtime
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
 		devname := r.State().Machine().Disk().BlockDevice.Device().Name()
 
-		f, err := os.Open("/proc/mounts")
-		if err != nil {
-			return err
-		}
+		return withUnmountTimeout(ctx, r, func() error {
+			return unmountSystemDiskBindMounts(logger, devname)
+		})
+	}
+}
 
-		defer f.Close() //nolint: errcheck
+func unmountSystemDiskBindMounts(logger *log.Logger, devname string) error {
+	f, err := os.Open("/proc/mounts")
+	if err != nil {
+		return err
+	}
 
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			fields := strings.Fields(scanner.Text())
+	defer f.Close() //nolint: errcheck
 
-			if len(fields) < 2 {
-				continue
-			}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
 
-			device := fields[0]
-			mountpoint := fields[1]
+		if len(fields) < 2 {
+			continue
+		}
 
-			if strings.HasPrefix(device, devname) {
-				logger.Printf("unmounting %s\n", mountpoint)
+		device := fields[0]
+		mountpoint := fields[1]
 
-				if err = unix.Unmount(mountpoint, 0); err != nil {
-					return fmt.Errorf("error unmounting %s: %w", mountpoint, err)
-				}
+		if strings.HasPrefix(device, devname) {
+			logger.Printf("unmounting %s\n", mountpoint)
+
+			if err = unix.Unmount(mountpoint, 0); err != nil {
+				return fmt.Errorf("error unmounting %s: %w", mountpoint, err)
 			}
 		}
-
-		return scanner.Err()
 	}
+
+	return scanner.Err()
 }
 
 // CordonAndDrainNode represents the task for stop all containerd tasks in the
-// k8s.io namespace.
+// k8s.io namespace. A machine that has not joined Kubernetes, i.e. has no
+// kubelet kubeconfig, is skipped.
 func CordonAndDrainNode(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		if _, err = os.Stat(constants.KubeletKubeconfig); os.IsNotExist(err) {
+			return runtime.Skip("kubelet kubeconfig not found")
+		}
+
 		var hostname string
 
 		if hostname, err = os.Hostname(); err != nil {
@@ -1036,11 +1553,43 @@ func CordonAndDrainNode(seq runtime.Sequence, data interface{}) runtime.TaskExec
 			return err
 		}
 
-		if err = kubeHelper.CordonAndDrain(hostname); err != nil {
-			return err
+		// Only the cordon made by the sequence is lifted, so the cleanup is of
+		// no effect if the node was not cordoned.
+		runtime.RegisterCleanup(ctx, "uncordon node", func(ctx context.Context) error {
+			return withTimeout(ctx, cleanupTimeout, func() error {
+				return kubeHelper.Uncordon(hostname)
+			})
+		})
+
+		drainTimeout := runtime.TaskTimeout(ctx, shutdownTimeouts(r).DrainTimeout())
+
+		err = withTimeout(ctx, drainTimeout, func() error {
+			return kubeHelper.CordonAndDrain(hostname)
+		})
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			logger.Printf("node was not drained within %s, proceeding", drainTimeout)
+
+			logRemainingPods(logger, kubeHelper, hostname)
+
+			return nil
 		}
 
-		return nil
+		return err
+	}
+}
+
+// logRemainingPods logs the pods that a drain has not evicted.
+func logRemainingPods(logger *log.Logger, kubeHelper *kubernetes.Client, hostname string) {
+	pods, err := kubeHelper.EvictablePodsOnNode(hostname)
+	if err != nil {
+		logger.Printf("failed to list the pods that were not evicted: %v", err)
+
+		return
+	}
+
+	for _, pod := range pods {
+		logger.Printf("pod %s/%s was not evicted", pod.GetNamespace(), pod.GetName())
 	}
 }
 
@@ -1105,38 +1654,100 @@ func RemoveAllPods(seq runtime.Sequence, data interface{}) runtime.TaskExecution
 			return err
 		}
 
-		client, err := cri.NewClient("unix://"+constants.ContainerdAddress, 10*time.Second)
-		if err != nil {
+		return removePods()
+	}
+}
+
+// removePods removes the pods through the CRI, once the kubelet is stopped.
+func removePods() error {
+	client, err := cri.NewClient("unix://"+constants.ContainerdAddress, 10*time.Second)
+	if err != nil {
+		return err
+	}
+
+	// nolint: errcheck
+	defer client.Close()
+
+	// We remove pods with POD network mode first so that the CNI can perform
+	// any cleanup tasks. If we don't do this, we run the risk of killing the
+	// CNI, preventing the CRI from cleaning up the pod's netwokring.
+
+	if err = client.RemovePodSandboxes(runtimeapi.NamespaceMode_POD, runtimeapi.NamespaceMode_CONTAINER); err != nil {
+		return err
+	}
+
+	// With the POD network mode pods out of the way, we kill the remaining
+	// pods.
+
+	return client.RemovePodSandboxes()
+}
+
+// ResetSystemDisk represents the task to reset the system disk.
+func ResetSystemDisk(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		disk := r.State().Machine().Disk().BlockDevice
+
+		logger.Printf("wiping the system disk %s", disk.Device().Name())
+
+		return disk.Reset()
+	}
+}
+
+// WipeSystemPartitions represents the task to wipe the partitions of the
+// system disk which the reset request does not preserve. The partition table
+// is kept, and the wiped partitions are formatted anew with their labels, so
+// that a preserved system can mount them on the next boot.
+func WipeSystemPartitions(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		in, ok := data.(*machine.ResetRequest)
+		if !ok {
+			return runtime.InvalidSequenceData(in, data)
+		}
+
+		var wipe []string
+
+		if wipe, err = runtime.PartitionsToWipe(in.GetPreserve()); err != nil {
 			return err
 		}
 
-		// nolint: errcheck
-		defer client.Close()
+		disk := r.State().Machine().Disk().BlockDevice.Device().Name()
 
-		// We remove pods with POD network mode first so that the CNI can perform
-		// any cleanup tasks. If we don't do this, we run the risk of killing the
-		// CNI, preventing the CRI from cleaning up the pod's netwokring.
+		// All of the partitions are looked up first, so that nothing is wiped
+		// unless every one of them is found.
+		partitions := make([]string, len(wipe))
 
-		if err = client.RemovePodSandboxes(runtimeapi.NamespaceMode_POD, runtimeapi.NamespaceMode_CONTAINER); err != nil {
-			return err
+		for i, label := range wipe {
+			var dev *probe.ProbedBlockDevice
+
+			if dev, err = probe.DevForFileSystemLabel(disk, label); err != nil {
+				return fmt.Errorf("failed to find the %s partition on %s: %w", label, disk, err)
+			}
+
+			partitions[i] = dev.Path
 		}
 
-		// With the POD network mode pods out of the way, we kill the remaining
-		// pods.
+		for i, label := range wipe {
+			logger.Printf("wiping the %s partition %s, preserving %s", label, partitions[i], strings.Join(in.GetPreserve(), ", "))
+		}
 
-		if err = client.RemovePodSandboxes(); err != nil {
-			return err
+		for i, label := range wipe {
+			if err = formatPartition(partitions[i], label); err != nil {
+				return fmt.Errorf("failed to wipe the %s partition %s: %w", label, partitions[i], err)
+			}
 		}
 
 		return nil
 	}
 }
 
-// ResetSystemDisk represents the task to reset the system disk.
-func ResetSystemDisk(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
-	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
-		return r.State().Machine().Disk().BlockDevice.Reset()
+// formatPartition creates the file system of the system partition with the
+// label, wiping its contents.
+func formatPartition(partname, label string) error {
+	if label == constants.BootPartitionLabel {
+		return vfat.MakeFS(partname, vfat.WithLabel(label))
 	}
+
+	return xfs.MakeFS(partname, xfs.WithForce(true), xfs.WithLabel(label))
 }
 
 // VerifyDiskAvailability represents the task for verifying that the system
@@ -1167,7 +1778,7 @@ func VerifyDiskAvailability(seq runtime.Sequence, data interface{}) runtime.Task
 
 		mountsReported := false
 
-		return retry.Constant(3*time.Minute, retry.WithUnits(500*time.Millisecond)).Retry(func() error {
+		return retry.Constant(runtime.TaskTimeout(ctx, 3*time.Minute), retry.WithUnits(500*time.Millisecond)).Retry(func() error {
 			if err = tryLock(partname); err != nil {
 				if err == unix.EBUSY {
 					if !mountsReported {
@@ -1218,37 +1829,135 @@ func Upgrade(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 		// to be safe.
 		in, ok := data.(*machine.UpgradeRequest)
 		if !ok {
-			return runtime.ErrInvalidSequenceData
+			return runtime.InvalidSequenceData(in, data)
 		}
 
-		devname := r.State().Machine().Disk().BlockDevice.Device().Name()
+		// We pull the installer image when we receive an upgrade request. No need
+		// to pull it again.
+		return upgrade(ctx, logger, r, in, false)
+	}
+}
+
+func upgrade(ctx context.Context, logger *log.Logger, r runtime.Runtime, in *machine.UpgradeRequest, pull bool) (err error) {
+	devname := r.State().Machine().Disk().BlockDevice.Device().Name()
+
+	logger.Printf("performing upgrade via %q", in.GetImage())
 
-		logger.Printf("performing upgrade via %q", in.GetImage())
+	c := r.Config()
+	if cfg, ok := c.(*v1alpha1.Config); ok {
+		cfg.MachineConfig.MachineInstall.InstallDisk = devname
+		cfg.MachineConfig.MachineInstall.InstallImage = in.GetImage()
 
-		c := r.Config()
-		if cfg, ok := c.(*v1alpha1.Config); ok {
-			cfg.MachineConfig.MachineInstall.InstallDisk = devname
-			cfg.MachineConfig.MachineInstall.InstallImage = in.GetImage()
+		r = NewRuntime(runtime.Configurator(cfg), r.State())
+	}
+
+	err = install.RunInstallerContainer(
+		devname, r.State().Platform().Name(),
+		in.GetImage(),
+		r.Config().Machine().Registries(),
+		install.WithPull(pull),
+		install.WithUpgrade(true),
+		install.WithForce(!in.GetPreserve()),
+		install.WithExtraKernelArgs(r.Config().Machine().Install().ExtraKernelArgs()),
+		install.WithProgress(func(p runtime.Progress) { runtime.ReportProgress(ctx, p) }),
+	)
+	if err != nil {
+		return err
+	}
+
+	logger.Println("upgrade successful")
+
+	return nil
+}
 
-			r = NewRuntime(runtime.Configurator(cfg), r.State())
+// StageUpgrade represents the task for staging an upgrade for the next boot.
+func StageUpgrade(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		in, ok := data.(*machine.UpgradeRequest)
+		if !ok {
+			return runtime.InvalidSequenceData(in, data)
 		}
 
-		// We pull the installer image when we receive an upgrade request. No need
-		// to pull it again.
-		err = install.RunInstallerContainer(
-			devname, r.State().Platform().Name(),
-			in.GetImage(),
-			r.Config().Machine().Registries(),
-			install.WithPull(false),
-			install.WithUpgrade(true),
-			install.WithForce(!in.GetPreserve()),
-			install.WithExtraKernelArgs(r.Config().Machine().Install().ExtraKernelArgs()),
-		)
-		if err != nil {
+		var b []byte
+
+		if b, err = json.Marshal(in); err != nil {
+			return err
+		}
+
+		tmp := constants.StagedUpgradePath + ".tmp"
+
+		if err = ioutil.WriteFile(tmp, b, 0600); err != nil {
+			return err
+		}
+
+		if err = os.Rename(tmp, constants.StagedUpgradePath); err != nil {
+			return err
+		}
+
+		logger.Printf("upgrade via %q staged for the next boot", in.GetImage())
+
+		return nil
+	}
+}
+
+// LoadStagedUpgrade represents the task for loading the upgrade staged for
+// this boot, if any. A corrupt staged upgrade is discarded, so that it can
+// not prevent the machine from booting.
+func LoadStagedUpgrade(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		var b []byte
+
+		if b, err = ioutil.ReadFile(constants.StagedUpgradePath); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		in := &machine.UpgradeRequest{}
+
+		if err = json.Unmarshal(b, in); err != nil || in.GetImage() == "" {
+			logger.Printf("discarding corrupt staged upgrade: %q", string(b))
+
+			return os.Remove(constants.StagedUpgradePath)
+		}
+
+		logger.Printf("found upgrade via %q staged for this boot", in.GetImage())
+
+		r.State().Machine().SetStagedUpgrade(in)
+
+		return nil
+	}
+}
+
+// ConsumeStagedUpgrade represents the task for removing the staged upgrade
+// before it is performed, so that a failed upgrade is not retried on every
+// boot.
+func ConsumeStagedUpgrade(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		if err = os.Remove(constants.StagedUpgradePath); err != nil && !errors.Is(err, os.ErrNotExist) {
 			return err
 		}
 
-		logger.Println("upgrade successful")
+		return nil
+	}
+}
+
+// PerformStagedUpgrade represents the task for performing the staged upgrade.
+// Unlike an immediate upgrade, the installer image is pulled at boot, which
+// reports its progress, and fails the task if the image is no longer
+// available.
+func PerformStagedUpgrade(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		in := r.State().Machine().StagedUpgrade()
+		if in == nil {
+			return errors.New("no staged upgrade")
+		}
+
+		if err = upgrade(ctx, logger, r, in, true); err != nil {
+			return fmt.Errorf("staged upgrade via %q failed: %w", in.GetImage(), err)
+		}
 
 		return nil
 	}
@@ -1268,6 +1977,10 @@ func LabelNodeAsMaster(seq runtime.Sequence, data interface{}) runtime.TaskExecu
 		}
 
 		err = retry.Constant(10*time.Minute, retry.WithUnits(3*time.Second)).Retry(func() error {
+			if ctx.Err() != nil {
+				return retry.UnexpectedError(ctx.Err())
+			}
+
 			if err = h.LabelNodeAsMaster(hostname); err != nil {
 				return retry.ExpectedError(err)
 			}
@@ -1311,10 +2024,44 @@ func UpdateBootloader(seq runtime.Sequence, data interface{}) runtime.TaskExecut
 	}
 }
 
-// Reboot represents the Reboot task.
+// KexecPrepare represents the task loading the kernel of the default boot
+// label with kexec, for the Reboot task to boot into it. It must run while the
+// boot partition is mounted. The reboot goes through the firmware if the
+// kernel can not be loaded, so failures are logged rather than returned.
+func KexecPrepare(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		label, err := syslinux.Default()
+		if err != nil {
+			logger.Printf("failed to read the default boot label, falling back to a full reboot: %v", err)
+
+			return nil
+		}
+
+		if err = kexec.Load(label.Kernel, label.Initrd, label.Append); err != nil {
+			logger.Printf("failed to load the kernel of %q, falling back to a full reboot: %v", label.Root, err)
+
+			return nil
+		}
+
+		logger.Printf("loaded the kernel of %q for kexec", label.Root)
+
+		return nil
+	}
+}
+
+// Reboot represents the Reboot task. It boots into the kernel loaded with
+// kexec if there is one, and reboots through the firmware otherwise.
 func Reboot(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
-		SyncNonVolatileStorageBuffers()
+		SyncNonVolatileStorageBuffers(shutdownTimeouts(r).SyncTimeout())
+
+		if shutdownTimeouts(r).Kexec() && kexec.Loaded() {
+			if err = unix.Reboot(unix.LINUX_REBOOT_CMD_KEXEC); err == nil {
+				return nil
+			}
+
+			logger.Printf("kexec failed, falling back to a full reboot: %v", err)
+		}
 
 		return unix.Reboot(unix.LINUX_REBOOT_CMD_RESTART)
 	}
@@ -1323,17 +2070,17 @@ func Reboot(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 // Shutdown represents the Shutdown task.
 func Shutdown(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
-		SyncNonVolatileStorageBuffers()
+		SyncNonVolatileStorageBuffers(shutdownTimeouts(r).SyncTimeout())
 
 		return unix.Reboot(unix.LINUX_REBOOT_CMD_POWER_OFF)
 	}
 }
 
-// SyncNonVolatileStorageBuffers invokes unix.Sync and waits up to 30 seconds
-// for it to finish.
+// SyncNonVolatileStorageBuffers invokes unix.Sync and waits up to the
+// specified timeout for it to finish.
 //
 // See http://man7.org/linux/man-pages/man2/reboot.2.html.
-func SyncNonVolatileStorageBuffers() {
+func SyncNonVolatileStorageBuffers(timeout time.Duration) {
 	syncdone := make(chan struct{})
 
 	go func() {
@@ -1344,20 +2091,28 @@ func SyncNonVolatileStorageBuffers() {
 
 	log.Printf("waiting for sync...")
 
-	for i := 29; i >= 0; i-- {
+	deadline := time.Now().Add(timeout)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
 		select {
 		case <-syncdone:
 			log.Printf("sync done")
 			return
-		case <-time.After(time.Second):
-		}
-
-		if i != 0 {
-			log.Printf("waiting %d more seconds for sync to finish", i)
+		case <-timer.C:
+			log.Printf("sync hasn't completed in time, aborting...")
+			return
+		case <-ticker.C:
+			if remaining := time.Until(deadline).Round(time.Second); remaining > 0 {
+				log.Printf("waiting %s more for sync to finish", remaining)
+			}
 		}
 	}
-
-	log.Printf("sync hasn't completed in time, aborting...")
 }
 
 // MountBootPartition mounts the boot partition.
@@ -1367,10 +2122,15 @@ func MountBootPartition(seq runtime.Sequence, data interface{}) runtime.TaskExec
 	}
 }
 
-// UnmountBootPartition unmounts the boot partition.
+// UnmountBootPartition unmounts the boot partition. If the sequence fails or
+// is aborted, the boot partition is remounted read-only if it is still mounted.
 func UnmountBootPartition(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
-		return unmountSystemPartition(constants.BootPartitionLabel)
+		runtime.RegisterCleanup(ctx, "remount "+constants.BootMountPoint+" read-only", remountReadOnly(r, constants.BootMountPoint))
+
+		return withUnmountTimeout(ctx, r, func() error {
+			return unmountSystemPartition(constants.BootPartitionLabel)
+		})
 	}
 }
 
@@ -1381,10 +2141,16 @@ func MountEphermeralPartition(seq runtime.Sequence, data interface{}) runtime.Ta
 	}
 }
 
-// UnmountEphemeralPartition unmounts the ephemeral partition.
+// UnmountEphemeralPartition unmounts the ephemeral partition. If the sequence
+// fails or is aborted, the ephemeral partition is remounted read-only if it is
+// still mounted.
 func UnmountEphemeralPartition(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
-		return unmountSystemPartition(constants.EphemeralPartitionLabel)
+		runtime.RegisterCleanup(ctx, "remount "+constants.EphemeralMountPoint+" read-only", remountReadOnly(r, constants.EphemeralMountPoint))
+
+		return withUnmountTimeout(ctx, r, func() error {
+			return unmountSystemPartition(constants.EphemeralPartitionLabel)
+		})
 	}
 }
 
@@ -1430,6 +2196,101 @@ func unmountSystemPartition(label string) (err error) {
 	return nil
 }
 
+// SkipInstall represents the task reporting that the install sequence is
+// skipped, as the system is already installed.
+func SkipInstall(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		return runtime.Skip("the system is already installed")
+	}
+}
+
+// CheckExistingInstallation represents the task for inspecting the install
+// disk for an existing installation, and applying the configured policy. The
+// installations completed by an install sequence are not found here, as the
+// install sequence is skipped once the system is installed, so the policy
+// applies to the partitions left by an interrupted install, or by another
+// system. It does not apply to a forced install. If the existing installation
+// is kept, this task is the only one of the install sequence.
+func CheckExistingInstallation(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		disk := r.Config().Machine().Install().Disk()
+
+		labels, err := r.State().Machine().ExistingInstallation(disk)
+		if err != nil {
+			return err
+		}
+
+		if len(labels) == 0 {
+			logger.Printf("no existing installation found on %s", disk)
+
+			return nil
+		}
+
+		if forceInstall(r, installRequest(data)) {
+			logger.Printf("install is forced, existing installation on %s will be overwritten", disk)
+
+			return nil
+		}
+
+		action := r.Config().Machine().Install().Existing()
+
+		logger.Printf("existing installation found on %s (partitions: %s), action: %s", disk, strings.Join(labels, ", "), action)
+
+		switch action {
+		case runtime.InstallExistingActionAbort:
+			return fmt.Errorf("refusing to install over existing installation on %s", disk)
+		case runtime.InstallExistingActionSkip:
+			return runtime.Skip("the existing installation is kept")
+		case runtime.InstallExistingActionForce:
+			logger.Println("existing installation will be overwritten")
+		default:
+			return fmt.Errorf("unknown install existing action: %q", action)
+		}
+
+		return nil
+	}
+}
+
+// keepExistingInstallation reports whether the install sequence keeps the
+// installation found on the install disk, as the policy for existing
+// installations is to skip them, and the install is not forced. The disk is
+// probed only as the sequence is built, the tasks get the probe of the
+// machine state.
+func keepExistingInstallation(r runtime.Runtime, in *runtime.InstallRequest) bool {
+	if forceInstall(r, in) || r.Config().Machine().Install().Existing() != runtime.InstallExistingActionSkip {
+		return false
+	}
+
+	labels, err := r.State().Machine().ExistingInstallation(r.Config().Machine().Install().Disk())
+
+	// The error is returned by CheckExistingInstallation.
+	return err == nil && len(labels) > 0
+}
+
+// forceInstall reports whether the install is forced, by the request or by
+// the config, which wins over the policy for existing installations.
+func forceInstall(r runtime.Runtime, in *runtime.InstallRequest) bool {
+	return in.Force || r.Config().Machine().Install().Force()
+}
+
+// installRequest returns the data of the install sequence, which is nil for
+// the install sequences run at startup.
+func installRequest(data interface{}) *runtime.InstallRequest {
+	if in, ok := data.(*runtime.InstallRequest); ok && in != nil {
+		return in
+	}
+
+	return &runtime.InstallRequest{}
+}
+
+// WriteInstalledMarker represents the task for marking the system as
+// installed, once the config is saved. The boot partition must be mounted.
+func WriteInstalledMarker(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
+		return ioutil.WriteFile(constants.InstalledMarkerPath, []byte(r.Config().Machine().Install().Image()+"\n"), 0600)
+	}
+}
+
 // Install mounts or installs the system partitions.
 func Install(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 	return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) (err error) {
@@ -1437,14 +2298,27 @@ func Install(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
 			return errors.New("an install image is required")
 		}
 
+		disk := r.Config().Machine().Install().Disk()
+
+		// An existing installation is only found here if it is overwritten,
+		// as the install is forced, or as CheckExistingInstallation applied
+		// the policy.
+		labels, err := r.State().Machine().ExistingInstallation(disk)
+		if err != nil {
+			return err
+		}
+
+		force := forceInstall(r, installRequest(data)) || len(labels) > 0
+
 		err = install.RunInstallerContainer(
-			r.Config().Machine().Install().Disk(),
+			disk,
 			r.State().Platform().Name(),
 			r.Config().Machine().Install().Image(),
 			r.Config().Machine().Registries(),
-			install.WithForce(r.Config().Machine().Install().Force()),
+			install.WithForce(force),
 			install.WithZero(r.Config().Machine().Install().Zero()),
 			install.WithExtraKernelArgs(r.Config().Machine().Install().ExtraKernelArgs()),
+			install.WithProgress(func(p runtime.Progress) { runtime.ReportProgress(ctx, p) }),
 		)
 		if err != nil {
 			return err
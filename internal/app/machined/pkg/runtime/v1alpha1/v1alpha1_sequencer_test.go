@@ -108,6 +108,21 @@ func TestPhaseList_AppendWhen(t *testing.T) {
 	}
 }
 
+func TestPhaseList_AppendOverlapping(t *testing.T) {
+	tasks := make([]runtime.TaskSetupFunc, 1, 2)
+	tasks[0] = MountBootPartition
+
+	p := PhaseList{}.AppendOverlapping(tasks...)
+
+	if len(p) != 1 || len(p[0]) != 2 {
+		t.Fatalf("PhaseList.AppendOverlapping() = %v, want one phase of two tasks", p)
+	}
+
+	if extra := tasks[:2][1]; extra != nil {
+		t.Errorf("PhaseList.AppendOverlapping() wrote to the backing array of the tasks")
+	}
+}
+
 func TestSequencer_Initialize(t *testing.T) {
 	type args struct {
 		r runtime.Runtime
@@ -149,7 +164,7 @@ func TestSequencer_Install(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Sequencer{}
-			if got := s.Install(tt.args.r); !reflect.DeepEqual(got, tt.want) {
+			if got := s.Install(tt.args.r, &runtime.InstallRequest{}); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Sequencer.Install() = %v, want %v", got, tt.want)
 			}
 		})
@@ -0,0 +1,100 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/pkg/config/types/v1alpha1"
+)
+
+// shutdownTimeouts returns the configured timeouts of the shutdown stages. The
+// defaults are used if the config has not been loaded, so that a machine that
+// failed early in boot can still be shut down.
+func shutdownTimeouts(r runtime.Runtime) runtime.Shutdown {
+	if r.Config() == nil {
+		return &v1alpha1.ShutdownConfig{}
+	}
+
+	return r.Config().Machine().Shutdown()
+}
+
+// withTimeout runs f, and waits up to the specified timeout for it to return.
+// If f does not return in time, `context.DeadlineExceeded` is returned and f
+// is left running in the background, since the stages it is used for can not
+// be interrupted.
+func withTimeout(ctx context.Context, timeout time.Duration, f func() error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- f()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// withUnmountTimeout runs the unmount f bounded by the configured unmount
+// timeout, or by the timeout of the task. An unmount left running, or interrupted, leaves the filesystems
+// dirty, so they are synced if the sequence fails or is aborted.
+func withUnmountTimeout(ctx context.Context, r runtime.Runtime, f func() error) error {
+	timeout := runtime.TaskTimeout(ctx, shutdownTimeouts(r).UnmountTimeout())
+
+	runtime.RegisterCleanup(ctx, "sync filesystems", func(ctx context.Context) error {
+		return withTimeout(ctx, timeout, func() error {
+			unix.Sync()
+
+			return nil
+		})
+	})
+
+	if err := withTimeout(ctx, timeout, f); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("unmount did not complete within %s: %w", timeout, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// unmountPhaseTimeout bounds an unmount phase of the reboot and shutdown
+// sequences by the configured unmount timeout, so that a stuck unmount does
+// not prevent the machine from going down.
+func unmountPhaseTimeout(r runtime.Runtime) runtime.TaskSetupFunc {
+	return runtime.PhaseTimeout(shutdownTimeouts(r).UnmountTimeout(), runtime.PhaseTimeoutContinue)
+}
+
+// remountReadOnly returns the cleanup of an interrupted unmount of the mount
+// point: if it is still mounted, it is remounted read-only, so that its
+// filesystem is consistent when the machine is powered off.
+func remountReadOnly(r runtime.Runtime, target string) runtime.CleanupFunc {
+	return func(ctx context.Context) error {
+		return withTimeout(ctx, shutdownTimeouts(r).UnmountTimeout(), func() error {
+			err := unix.Mount("", target, "", unix.MS_REMOUNT|unix.MS_RDONLY, "")
+
+			// The mount point is no longer mounted.
+			if errors.Is(err, unix.EINVAL) {
+				return nil
+			}
+
+			return err
+		})
+	}
+}
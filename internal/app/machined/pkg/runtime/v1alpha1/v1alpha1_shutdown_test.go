@@ -0,0 +1,44 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_withTimeout(t *testing.T) {
+	expected := errors.New("failed")
+
+	if err := withTimeout(context.Background(), time.Second, func() error { return expected }); err != expected {
+		t.Fatalf("withTimeout() error = %v, want %v", err, expected)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+
+	err := withTimeout(context.Background(), 10*time.Millisecond, func() error {
+		<-release
+
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("withTimeout() with a blocked stage error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = withTimeout(ctx, time.Second, func() error {
+		<-release
+
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("withTimeout() with a canceled context error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,316 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/talos-systems/talos/api/machine"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/v1alpha1/platform/metal"
+	"github.com/talos-systems/talos/pkg/blockdevice/probe"
+	"github.com/talos-systems/talos/pkg/config/types/v1alpha1"
+)
+
+func taskNames(phases []runtime.Phase) []string {
+	var names []string
+
+	for _, phase := range phases {
+		for _, task := range phase.Tasks() {
+			names = append(names, taskName(task))
+		}
+	}
+
+	return names
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestSequencer_StagedUpgrade(t *testing.T) {
+	cfg := &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{MachineType: "worker"}}
+
+	state := &State{
+		platform: &metal.Metal{},
+		machine:  &MachineState{},
+	}
+
+	r := NewRuntime(cfg, state)
+	s := &Sequencer{}
+
+	staged := taskNames(s.Upgrade(r, &machine.UpgradeRequest{Image: "installer", Stage: true}))
+	if len(staged) != 1 || staged[0] != "StageUpgrade" {
+		t.Errorf("staged upgrade sequence = %v, want [StageUpgrade]", staged)
+	}
+
+	if boot := taskNames(s.Boot(r)); contains(boot, "PerformStagedUpgrade") {
+		t.Errorf("boot sequence without a staged upgrade = %v, want no upgrade", boot)
+	}
+
+	state.machine.SetStagedUpgrade(&machine.UpgradeRequest{Image: "installer"})
+
+	boot := taskNames(s.Boot(r))
+
+	for _, name := range []string{"ConsumeStagedUpgrade", "PerformStagedUpgrade", "Reboot"} {
+		if !contains(boot, name) {
+			t.Errorf("boot sequence with a staged upgrade = %v, want %s", boot, name)
+		}
+	}
+
+	if contains(boot, "StartAllServices") {
+		t.Errorf("boot sequence with a staged upgrade = %v, want no services started", boot)
+	}
+}
+
+func TestSequencer_WaitForTimeSync(t *testing.T) {
+	cfg := &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{MachineType: "worker"}}
+
+	r := NewRuntime(cfg, &State{
+		platform: &metal.Metal{},
+		machine:  &MachineState{},
+	})
+	s := &Sequencer{}
+
+	if boot := taskNames(s.Boot(r)); contains(boot, "WaitForTimeSync") {
+		t.Errorf("boot sequence = %v, want no wait for time sync by default", boot)
+	}
+
+	cfg.MachineConfig.MachineTime = &v1alpha1.TimeConfig{TimeWaitForSync: true}
+
+	boot := taskNames(s.Boot(r))
+
+	var services, wait int
+
+	for i, name := range boot {
+		switch name {
+		case "StartAllServices":
+			services = i
+		case "WaitForTimeSync":
+			wait = i
+		}
+	}
+
+	if wait >= services {
+		t.Errorf("boot sequence = %v, want WaitForTimeSync before StartAllServices", boot)
+	}
+}
+
+func TestSequencer_Recover(t *testing.T) {
+	cfg := &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{MachineType: "worker"}}
+
+	r := NewRuntime(cfg, &State{
+		platform: &metal.Metal{},
+		machine:  &MachineState{},
+	})
+	s := &Sequencer{}
+
+	recovery := taskNames(s.Recover(r))
+
+	expected := []string{"MountBootPartition", "RepairBootloader", "RestoreConfig", "ValidateConfig", "SaveConfig", "UnmountBootPartition"}
+
+	if len(recovery) != len(expected) {
+		t.Fatalf("recover sequence = %v, want %v", recovery, expected)
+	}
+
+	for i := range expected {
+		if recovery[i] != expected[i] {
+			t.Fatalf("recover sequence = %v, want %v", recovery, expected)
+		}
+	}
+
+	// The last known good config is saved once the boot is healthy.
+	if boot := taskNames(s.Boot(r)); boot[len(boot)-1] != "WaitForHealthy" {
+		t.Errorf("boot sequence = %v, want WaitForHealthy last", boot)
+	}
+}
+
+func TestSequencer_ShutdownDrain(t *testing.T) {
+	cfg := &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{MachineType: "worker"}}
+
+	r := NewRuntime(cfg, &State{
+		platform: &metal.Metal{},
+		machine:  &MachineState{},
+	})
+	s := &Sequencer{}
+
+	for _, phases := range [][]runtime.Phase{s.Shutdown(r), s.Reboot(r)} {
+		if names := taskNames(phases); names[0] != "CordonAndDrainNode" {
+			t.Errorf("sequence = %v, want CordonAndDrainNode first", names)
+		}
+	}
+
+	cfg.MachineConfig.MachineShutdown = &v1alpha1.ShutdownConfig{ShutdownSkipDrain: true}
+
+	for _, phases := range [][]runtime.Phase{s.Shutdown(r), s.Reboot(r)} {
+		if names := taskNames(phases); contains(names, "CordonAndDrainNode") {
+			t.Errorf("sequence = %v, want no drain when it is skipped", names)
+		}
+	}
+}
+
+func TestSequencer_RebootKexec(t *testing.T) {
+	cfg := &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{MachineType: "worker"}}
+
+	r := NewRuntime(cfg, &State{
+		platform: &metal.Metal{},
+		machine:  &MachineState{},
+	})
+	s := &Sequencer{}
+
+	if names := taskNames(s.Reboot(r)); contains(names, "KexecPrepare") {
+		t.Errorf("reboot sequence = %v, want no kexec by default", names)
+	}
+
+	cfg.MachineConfig.MachineShutdown = &v1alpha1.ShutdownConfig{ShutdownKexec: true}
+
+	names := taskNames(s.Reboot(r))
+
+	index := func(name string) int {
+		for i, n := range names {
+			if n == name {
+				return i
+			}
+		}
+
+		return -1
+	}
+
+	// The kernel is loaded from the boot partition, once the services are
+	// stopped.
+	if kexec := index("KexecPrepare"); kexec < index("StopAllServices") || kexec > index("UnmountBootPartition") {
+		t.Errorf("reboot sequence = %v, want KexecPrepare between StopAllServices and UnmountBootPartition", names)
+	}
+
+	if names[len(names)-1] != "Reboot" {
+		t.Errorf("reboot sequence = %v, want Reboot last", names)
+	}
+}
+
+func TestSequencer_InstallForced(t *testing.T) {
+	cfg := &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{MachineType: "worker"}}
+
+	r := NewRuntime(cfg, &State{
+		platform: &metal.Metal{},
+		machine:  &MachineState{disk: &probe.ProbedBlockDevice{}, installed: true},
+	})
+	s := &Sequencer{}
+
+	if names := taskNames(s.Install(r, &runtime.InstallRequest{})); len(names) != 1 || names[0] != "SkipInstall" {
+		t.Errorf("install sequence = %v, want it skipped once installed", names)
+	}
+
+	names := taskNames(s.Install(r, &runtime.InstallRequest{Force: true}))
+
+	if !contains(names, "Install") {
+		t.Fatalf("install sequence = %v, want a forced install to reinstall", names)
+	}
+
+	saved, marked := -1, -1
+
+	for i, name := range names {
+		switch name {
+		case "SaveConfig":
+			saved = i
+		case "WriteInstalledMarker":
+			marked = i
+		}
+	}
+
+	if marked < saved || saved < 0 {
+		t.Errorf("install sequence = %v, want WriteInstalledMarker after SaveConfig", names)
+	}
+}
+
+func TestController_InstallSkipped(t *testing.T) {
+	defer discardTaskLogs()()
+
+	cfg := &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{MachineType: "worker"}}
+
+	r := NewRuntime(cfg, &State{
+		platform: &metal.Metal{},
+		machine:  &MachineState{disk: &probe.ProbedBlockDevice{}, installed: true},
+	})
+	s := &Sequencer{}
+
+	logger := &recordingLogger{}
+
+	c := &Controller{
+		r: r,
+		s: s,
+	}
+
+	c.SetLogger(logger)
+
+	if err := c.run(context.Background(), runtime.SequenceInstall, s.Install(r, &runtime.InstallRequest{}), nil); err != nil {
+		t.Fatalf("Controller.run() error = %v, want the skipped install to succeed", err)
+	}
+
+	var reason interface{}
+
+	for _, e := range logger.entries {
+		if e.msg == "task skipped" {
+			reason = e.fields["reason"]
+		}
+	}
+
+	if reason != "the system is already installed" {
+		t.Errorf("skip reason = %v, want the install reported as skipped", reason)
+	}
+}
+
+func TestSequencer_InstallExisting(t *testing.T) {
+	s := &Sequencer{}
+
+	for _, tt := range []struct {
+		name    string
+		install *v1alpha1.InstallConfig
+		want    bool
+	}{
+		{name: "skip", install: &v1alpha1.InstallConfig{InstallDisk: "/dev/sda"}},
+		{name: "force", install: &v1alpha1.InstallConfig{InstallDisk: "/dev/sda", InstallExisting: "force"}, want: true},
+		{name: "abort", install: &v1alpha1.InstallConfig{InstallDisk: "/dev/sda", InstallExisting: "abort"}, want: true},
+		{name: "forced skip", install: &v1alpha1.InstallConfig{InstallDisk: "/dev/sda", InstallForce: true}, want: true},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{MachineType: "worker", MachineInstall: tt.install}}
+
+			// The install disk has the partitions of another installation.
+			r := NewRuntime(cfg, &State{
+				platform: &metal.Metal{},
+				machine:  &MachineState{existing: map[string][]string{"/dev/sda": {"ESP", "EPHEMERAL"}}},
+			})
+
+			names := taskNames(s.Install(r, &runtime.InstallRequest{}))
+
+			if !contains(names, "CheckExistingInstallation") {
+				t.Fatalf("install sequence = %v, want the install disk checked", names)
+			}
+
+			if got := contains(names, "Install") || contains(names, "SaveConfig"); got != tt.want {
+				t.Errorf("install sequence = %v, want install %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func Test_installRequest(t *testing.T) {
+	if in := installRequest(nil); in == nil || in.Force {
+		t.Errorf("installRequest(nil) = %+v, want an empty request", in)
+	}
+
+	if in := installRequest(&runtime.InstallRequest{Force: true}); !in.Force {
+		t.Errorf("installRequest() = %+v, want the forced request", in)
+	}
+}
@@ -6,8 +6,11 @@ package v1alpha1
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"os"
 
+	"github.com/talos-systems/talos/api/machine"
 	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
 	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/v1alpha1/platform"
 	"github.com/talos-systems/talos/pkg/blockdevice/probe"
@@ -24,6 +27,13 @@ type State struct {
 // MachineState represents the machine's state.
 type MachineState struct {
 	disk *probe.ProbedBlockDevice
+
+	installed bool
+	existing  map[string][]string
+
+	stagedUpgrade *machine.UpgradeRequest
+
+	maintenance bool
 }
 
 // ClusterState represents the cluster's state.
@@ -47,8 +57,11 @@ func NewState() (s *State, err error) {
 		return nil, err
 	}
 
+	// The installation is probed before the boot partition is mounted by the
+	// initialize sequence.
 	machine := &MachineState{
-		disk: dev,
+		disk:      dev,
+		installed: dev != nil && installedMarker(),
 	}
 
 	cluster := &ClusterState{
@@ -102,16 +115,102 @@ func (s *MachineState) Close() error {
 	return nil
 }
 
-// Installed implements the machine state interface.
+// Installed implements the machine state interface. The system is installed
+// once it has the ephemeral partition, and the boot partition has the marker
+// written at the end of the install sequence, or the config saved by the
+// installations which predate the marker. It is probed once, by NewState.
 func (s *MachineState) Installed() bool {
-	if s.disk == nil {
+	return s.installed
+}
+
+// ExistingInstallation implements the machine state interface. It returns the
+// labels of the system partitions found on the disk, which is probed once.
+func (s *MachineState) ExistingInstallation(disk string) (labels []string, err error) {
+	if probed, ok := s.existing[disk]; ok {
+		return probed, nil
+	}
+
+	for _, label := range []string{constants.BootPartitionLabel, constants.EphemeralPartitionLabel} {
 		var dev *probe.ProbedBlockDevice
 
-		dev, err := probe.GetDevWithFileSystemLabel(constants.EphemeralPartitionLabel)
-		if err == nil {
-			s.disk = dev
+		dev, err = probe.DevForFileSystemLabel(disk, label)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to probe %s for label %q: %w", disk, label, err)
+		}
+
+		if dev.BlockDevice != nil {
+			// nolint: errcheck
+			dev.Close()
+		}
+
+		labels = append(labels, label)
+	}
+
+	if s.existing == nil {
+		s.existing = map[string][]string{}
+	}
+
+	s.existing[disk] = labels
+
+	return labels, nil
+}
+
+// installedMarker reports whether the boot partition has the installed
+// marker, or the config. The boot partition is mounted for the duration of
+// the check. If it can not be checked, the system is assumed to be installed,
+// so that it is not installed over.
+func installedMarker() bool {
+	dev, err := probe.GetDevWithFileSystemLabel(constants.BootPartitionLabel)
+	if err != nil {
+		return false
+	}
+
+	if dev.BlockDevice != nil {
+		// nolint: errcheck
+		dev.Close()
+	}
+
+	if err = mountSystemPartition(constants.BootPartitionLabel); err != nil {
+		log.Printf("failed to mount the boot partition to check the installation: %v", err)
+
+		return true
+	}
+
+	defer func() {
+		if e := unmountSystemPartition(constants.BootPartitionLabel); e != nil {
+			log.Printf("failed to unmount the boot partition: %v", e)
+		}
+	}()
+
+	for _, path := range []string{constants.InstalledMarkerPath, constants.ConfigPath} {
+		if _, err = os.Stat(path); err == nil || !errors.Is(err, os.ErrNotExist) {
+			return true
 		}
 	}
 
-	return s.disk != nil
+	return false
+}
+
+// StagedUpgrade implements the machine state interface.
+func (s *MachineState) StagedUpgrade() *machine.UpgradeRequest {
+	return s.stagedUpgrade
+}
+
+// SetStagedUpgrade implements the machine state interface.
+func (s *MachineState) SetStagedUpgrade(in *machine.UpgradeRequest) {
+	s.stagedUpgrade = in
+}
+
+// Maintenance implements the machine state interface.
+func (s *MachineState) Maintenance() bool {
+	return s.maintenance
+}
+
+// SetMaintenance implements the machine state interface.
+func (s *MachineState) SetMaintenance(maintenance bool) {
+	s.maintenance = maintenance
 }
@@ -0,0 +1,157 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+// stepper pauses the sequences before each of their phases while the
+// single-step mode is enabled, until they are stepped.
+type stepper struct {
+	mu      sync.Mutex
+	enabled bool
+	// waiting is the number of paused phases.
+	waiting int
+	// steps resumes a single paused phase.
+	steps chan struct{}
+	// disabled is closed, and replaced, whenever the mode is disabled, which
+	// resumes all of the paused phases.
+	disabled chan struct{}
+}
+
+func (s *stepper) init() {
+	if s.steps == nil {
+		s.steps = make(chan struct{}, 1)
+		s.disabled = make(chan struct{})
+	}
+}
+
+// set enables or disables the single-step mode.
+func (s *stepper) set(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.init()
+
+	if s.enabled && !enabled {
+		close(s.disabled)
+		s.disabled = make(chan struct{})
+	}
+
+	s.enabled = enabled
+}
+
+// step resumes a paused phase, and reports whether one was paused.
+func (s *stepper) step() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.waiting == 0 {
+		return false
+	}
+
+	// A step already pending resumes the phase all the same.
+	select {
+	case s.steps <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// wait pauses, if the single-step mode is enabled, until the phase is
+// stepped, the mode is disabled, or the context is canceled. The paused
+// function is called once the phase can be stepped.
+func (s *stepper) wait(ctx context.Context, paused func()) {
+	s.mu.Lock()
+
+	if !s.enabled {
+		s.mu.Unlock()
+
+		return
+	}
+
+	s.waiting++
+	steps, disabled := s.steps, s.disabled
+
+	s.mu.Unlock()
+
+	paused()
+
+	stepped := false
+
+	select {
+	case <-steps:
+		stepped = true
+	case <-disabled:
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.waiting--
+
+	// A step sent to the phases which are no longer paused must not resume
+	// the next phase to pause.
+	if !stepped && s.waiting == 0 {
+		select {
+		case <-steps:
+		default:
+		}
+	}
+}
+
+// SetSingleStep enables or disables the single-step mode, in which the
+// sequences pause before each of their phases until Step is called. Enabling
+// it requires debug to be enabled in the config, so that it never affects a
+// machine in production. Disabling it resumes the paused sequences.
+func (c *Controller) SetSingleStep(enabled bool) error {
+	if enabled && (c.r == nil || c.r.Config() == nil || !c.r.Config().Debug()) {
+		return runtime.ErrDebugRequired
+	}
+
+	c.stepper.set(enabled)
+
+	if enabled {
+		c.log().Warn("single-step mode enabled (debug)")
+	} else {
+		c.log().Info("single-step mode disabled")
+	}
+
+	return nil
+}
+
+// Step resumes the sequence paused before a phase by the single-step mode. It
+// returns `runtime.ErrNotPaused` if no sequence is paused.
+func (c *Controller) Step() error {
+	if !c.stepper.step() {
+		return runtime.ErrNotPaused
+	}
+
+	return nil
+}
+
+// pauseBeforePhase waits for the phase to be stepped in the single-step mode.
+// A sequence canceled while paused resumes, and is aborted before the phase.
+func (c *Controller) pauseBeforePhase(ctx context.Context, seq runtime.Sequence, number, total int) {
+	var start time.Time
+
+	c.stepper.wait(ctx, func() {
+		start = time.Now()
+
+		c.log().Info("paused before phase", "sequence", seq, "phase", fmt.Sprintf("%d/%d", number, total))
+	})
+
+	if !start.IsZero() {
+		c.log().Info("resumed before phase", "sequence", seq, "phase", fmt.Sprintf("%d/%d", number, total), "paused", time.Since(start))
+	}
+}
@@ -0,0 +1,159 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/runtimetest"
+	"github.com/talos-systems/talos/pkg/config/types/v1alpha1"
+)
+
+func TestController_SetSingleStep_RequiresDebug(t *testing.T) {
+	c := &Controller{
+		r: NewRuntime(&v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{}}, nil),
+	}
+
+	c.SetLogger(&recordingLogger{})
+
+	if err := c.SetSingleStep(true); !errors.Is(err, runtime.ErrDebugRequired) {
+		t.Errorf("Controller.SetSingleStep() error = %v, want %v", err, runtime.ErrDebugRequired)
+	}
+
+	if err := c.SetSingleStep(false); err != nil {
+		t.Errorf("Controller.SetSingleStep() of disabling error = %v", err)
+	}
+
+	if err := c.Step(); !errors.Is(err, runtime.ErrNotPaused) {
+		t.Errorf("Controller.Step() error = %v, want %v", err, runtime.ErrNotPaused)
+	}
+}
+
+// waitPaused waits for the sequence to pause before the phase.
+func waitPaused(t *testing.T, logger *recordingLogger, phase string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		logger.mu.Lock()
+
+		for _, e := range logger.entries {
+			if e.msg == "paused before phase" && e.fields["phase"] == phase {
+				logger.mu.Unlock()
+
+				return
+			}
+		}
+
+		logger.mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("the sequence did not pause before phase %s", phase)
+}
+
+func TestController_SingleStep(t *testing.T) {
+	defer discardTaskLogs()()
+
+	rec := &runtimetest.Recorder{}
+	logger := &recordingLogger{}
+
+	c := &Controller{
+		r: NewRuntime(&v1alpha1.Config{ConfigDebug: true, MachineConfig: &v1alpha1.MachineConfig{}}, nil),
+		s: NewSequencer(),
+	}
+
+	c.SetLogger(logger)
+
+	if err := c.SetSingleStep(true); err != nil {
+		t.Fatalf("Controller.SetSingleStep() error = %v", err)
+	}
+
+	phases := []runtime.Phase{{rec.Task("first")}, {rec.Task("second")}, {rec.Task("third")}}
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- c.run(context.Background(), runtime.SequenceBoot, phases, nil)
+	}()
+
+	waitPaused(t, logger, "1/3")
+
+	if got := rec.Order(); len(got) != 0 {
+		t.Fatalf("ran %v while paused before the first phase", got)
+	}
+
+	if err := c.Step(); err != nil {
+		t.Fatalf("Controller.Step() error = %v", err)
+	}
+
+	waitPaused(t, logger, "2/3")
+
+	if got, want := rec.Order(), []string{"first"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ran %v while paused before the second phase, want %v", got, want)
+	}
+
+	// Disabling the mode resumes the sequence, which then runs to completion.
+	if err := c.SetSingleStep(false); err != nil {
+		t.Fatalf("Controller.SetSingleStep() error = %v", err)
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("Controller.run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the sequence did not resume once the single-step mode was disabled")
+	}
+
+	if got, want := rec.Order(), []string{"first", "second", "third"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %v, want %v", got, want)
+	}
+}
+
+func TestController_SingleStep_Canceled(t *testing.T) {
+	rec := &runtimetest.Recorder{}
+	logger := &recordingLogger{}
+
+	c := &Controller{
+		r: NewRuntime(&v1alpha1.Config{ConfigDebug: true, MachineConfig: &v1alpha1.MachineConfig{}}, nil),
+		s: NewSequencer(),
+	}
+
+	c.SetLogger(logger)
+
+	if err := c.SetSingleStep(true); err != nil {
+		t.Fatalf("Controller.SetSingleStep() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- c.run(ctx, runtime.SequenceBoot, []runtime.Phase{{rec.Task("first")}}, nil)
+	}()
+
+	waitPaused(t, logger, "1/1")
+
+	cancel()
+
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Errorf("Controller.run() error = %v, want %v", err, context.Canceled)
+	}
+
+	if got := rec.Order(); len(got) != 0 {
+		t.Errorf("ran %v, want the sequence aborted while paused", got)
+	}
+}
@@ -0,0 +1,115 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+type subsequencesKey struct{}
+
+// subsequences returns the sequences that the context runs within, the
+// outermost first.
+func subsequences(ctx context.Context) []runtime.Sequence {
+	seqs, _ := ctx.Value(subsequencesKey{}).([]runtime.Sequence)
+
+	return seqs
+}
+
+// withSubsequences returns a context in which the tasks of the sequence can
+// run nested sequences with `runtime.RunSubsequence`.
+func (c *Controller) withSubsequences(ctx context.Context, seq runtime.Sequence) context.Context {
+	parents := subsequences(ctx)
+
+	seqs := make([]runtime.Sequence, 0, len(parents)+1)
+	seqs = append(seqs, parents...)
+	seqs = append(seqs, seq)
+
+	ctx = context.WithValue(ctx, subsequencesKey{}, seqs)
+
+	return runtime.WithSubsequenceRunner(ctx, c.runSubsequence)
+}
+
+// nested reports whether the context belongs to a nested sequence.
+func nested(ctx context.Context) bool {
+	return len(subsequences(ctx)) > 1
+}
+
+// runSubsequence runs a sequence from within a task of the running sequence.
+// The running sequence already holds the lock, so the nested sequence runs
+// without it, while the other callers of Run still get ErrLocked.
+//
+// The nested sequence is part of the task running it: it reports neither its
+// status nor its trace, which remain those of the running sequence, and its
+// cleanups run if the running sequence fails. It is bounded by its own
+// timeout, and a nested shutdown or reboot falls back to a hard poweroff, as
+// handleRequest does for the requested ones.
+func (c *Controller) runSubsequence(ctx context.Context, seq runtime.Sequence, data interface{}) error {
+	// The task might have leaked the context past the end of the sequence.
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s sequence: %w", seq.String(), err)
+	}
+
+	parents := subsequences(ctx)
+
+	for _, parent := range parents {
+		if parent == seq {
+			return fmt.Errorf("%s sequence: %w", seq.String(), runtime.ErrRecursiveSequence)
+		}
+	}
+
+	if err := c.checkConfigured(seq); err != nil {
+		return err
+	}
+
+	phases, err := c.phases(seq, data)
+	if err != nil {
+		return err
+	}
+
+	c.log().Info("nested sequence starting", "sequence", seq, "parent", parents[len(parents)-1])
+
+	ctx = context.WithValue(ctx, sequenceStatusKey{}, (*runtime.SequenceStatus)(nil))
+	ctx = c.withSubsequences(ctx, seq)
+
+	start := time.Now()
+
+	run := func() error {
+		return c.runWithTimeout(ctx, seq, c.sequenceTimeout(seq), func(ctx context.Context) error {
+			return c.run(ctx, seq, phases, data)
+		})
+	}
+
+	if !terminates(seq) || c.r.State().Platform().Mode() == runtime.ModeContainer {
+		return run()
+	}
+
+	return withFallback(seq, gracefulShutdownTimeout, run, func() (time.Time, bool) { return start, true }, hardPoweroff)
+}
+
+type taskKey struct{}
+
+// withTask marks the context of a task of a phase, which runs while the
+// sequence of the task holds the lock. The deferred tasks, which run once the
+// lock is released, are not marked.
+func withTask(ctx context.Context) context.Context {
+	return context.WithValue(ctx, taskKey{}, struct{}{})
+}
+
+// calledFromTask reports whether the context belongs to a task of a phase,
+// including the goroutines that the task starts with its context.
+func calledFromTask(ctx context.Context) bool {
+	return ctx.Value(taskKey{}) != nil
+}
+
+// errCalledFromTask returns the error of a sequence run by a task with
+// `Controller.RunContext`, instead of `runtime.RunSubsequence`.
+func errCalledFromTask(seq runtime.Sequence) error {
+	return fmt.Errorf("%s sequence: run by a task of the running sequence, which holds the lock: %w", seq, runtime.ErrLocked)
+}
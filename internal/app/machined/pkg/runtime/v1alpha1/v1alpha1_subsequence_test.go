@@ -0,0 +1,204 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime/runtimetest"
+)
+
+func TestController_RunSubsequence(t *testing.T) {
+	defer discardTaskLogs()()
+
+	poweroff := hardPoweroff
+
+	defer func() { hardPoweroff = poweroff }()
+
+	var fallback []runtime.Sequence
+
+	hardPoweroff = func(seq runtime.Sequence) error {
+		fallback = append(fallback, seq)
+
+		return nil
+	}
+
+	var c *Controller
+
+	var (
+		status                 runtime.SequenceStatus
+		runErr, concurrentErr  error
+		recursiveErr, outerErr error
+	)
+
+	errReboot := errors.New("operation not permitted")
+
+	rec := &runtimetest.Recorder{}
+
+	s := runtimetest.NewSequencer().
+		SetPhases(runtime.SequenceShutdown,
+			runtime.Phase{rec.Task("stop services")},
+			runtime.Phase{rec.Func("reboot", func(ctx context.Context, _ *log.Logger, _ runtime.Runtime) error {
+				return runtime.RunSubsequence(ctx, runtime.SequenceReboot, nil)
+			})},
+			runtime.Phase{rec.Task("unreachable")},
+		).
+		SetPhases(runtime.SequenceReboot,
+			runtime.Phase{rec.Func("query", func(ctx context.Context, _ *log.Logger, _ runtime.Runtime) error {
+				status, _ = c.CurrentSequence()
+
+				// Only the nested sequences run without the lock.
+				runErr = c.RunContext(ctx, runtime.SequenceReboot, nil)
+
+				done := make(chan struct{})
+
+				go func() {
+					defer close(done)
+
+					concurrentErr = c.Run(runtime.SequenceReboot, nil)
+				}()
+
+				<-done
+
+				recursiveErr = runtime.RunSubsequence(ctx, runtime.SequenceReboot, nil)
+				outerErr = runtime.RunSubsequence(ctx, runtime.SequenceShutdown, nil)
+
+				return nil
+			})},
+			runtime.Phase{rec.Fail("reboot", errReboot)},
+		)
+
+	c = NewControllerWithRuntime(runtimetest.NewRuntime(nil, runtime.ModeMetal), s)
+
+	c.SetLogger(&recordingLogger{})
+
+	if err := c.Run(runtime.SequenceShutdown, nil); !errors.Is(err, errReboot) {
+		t.Fatalf("Controller.Run() error = %v, want the error of the nested reboot", err)
+	}
+
+	if want := []string{"stop services", "reboot", "query", "reboot"}; !reflect.DeepEqual(rec.Order(), want) {
+		t.Errorf("tasks ran %v, want %v", rec.Order(), want)
+	}
+
+	// The nested reboot failed, and fell back to a hard one.
+	if want := []runtime.Sequence{runtime.SequenceReboot}; !reflect.DeepEqual(fallback, want) {
+		t.Errorf("fell back to a hard %v, want %v", fallback, want)
+	}
+
+	if status.Sequence != runtime.SequenceShutdown || status.Phase != 2 || status.PhaseTotal != 3 {
+		t.Errorf("Controller.CurrentSequence() = %+v, want phase 2/3 of the shutdown sequence", status)
+	}
+
+	if !errors.Is(runErr, runtime.ErrLocked) {
+		t.Errorf("Controller.Run() from a nested task error = %v, want %v", runErr, runtime.ErrLocked)
+	}
+
+	if !errors.Is(concurrentErr, runtime.ErrLocked) {
+		t.Errorf("concurrent Controller.Run() error = %v, want %v", concurrentErr, runtime.ErrLocked)
+	}
+
+	if !errors.Is(recursiveErr, runtime.ErrRecursiveSequence) {
+		t.Errorf("RunSubsequence() of the same sequence error = %v, want %v", recursiveErr, runtime.ErrRecursiveSequence)
+	}
+
+	if !errors.Is(outerErr, runtime.ErrRecursiveSequence) {
+		t.Errorf("RunSubsequence() of the outer sequence error = %v, want %v", outerErr, runtime.ErrRecursiveSequence)
+	}
+
+	trace := c.LastTrace()
+	if trace == nil || trace.Sequence != runtime.SequenceShutdown.String() || len(trace.Phases) != 2 {
+		t.Errorf("Controller.LastTrace() = %+v, want the 2 phases of the shutdown sequence which ran", trace)
+	}
+}
+
+func TestController_RunSubsequence_Outside(t *testing.T) {
+	if err := runtime.RunSubsequence(context.Background(), runtime.SequenceReboot, nil); !errors.Is(err, runtime.ErrNotRunning) {
+		t.Errorf("RunSubsequence() outside of a sequence error = %v, want %v", err, runtime.ErrNotRunning)
+	}
+
+	c := NewControllerWithRuntime(runtimetest.NewRuntime(nil, runtime.ModeMetal), runtimetest.NewSequencer())
+
+	c.SetLogger(&recordingLogger{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = c.withSubsequences(ctx, runtime.SequenceShutdown)
+
+	// A task leaking the context can not run a sequence once it is over.
+	cancel()
+
+	if err := runtime.RunSubsequence(ctx, runtime.SequenceReboot, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("RunSubsequence() after the sequence error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestController_Run_FromTask(t *testing.T) {
+	defer discardTaskLogs()()
+
+	var c *Controller
+
+	var runErr, forcedErr, waitErr error
+
+	rec := &runtimetest.Recorder{}
+
+	// A reboot requested while an upgrade runs preempts it, and an install
+	// requested while a boot runs is queued behind it: both would wait for
+	// the task requesting them.
+	s := runtimetest.NewSequencer().
+		SetPhases(runtime.SequenceUpgrade,
+			runtime.Phase{runtime.WithTimeout(rec.Func("reboot", func(ctx context.Context, _ *log.Logger, _ runtime.Runtime) error {
+				runErr = c.RunContext(ctx, runtime.SequenceReboot, nil)
+				forcedErr = c.RunForcedContext(ctx, runtime.SequenceReboot, nil)
+
+				// A goroutine of the task is detected by the context it is given.
+				done := make(chan struct{})
+
+				go func() {
+					defer close(done)
+
+					waitErr = c.RunWaitContext(ctx, runtime.SequenceReboot, nil, time.Minute)
+				}()
+
+				<-done
+
+				return nil
+			}), time.Minute)},
+			runtime.Phase{rec.Task("install")},
+		).
+		SetPhases(runtime.SequenceBoot,
+			runtime.Phase{rec.Func("install", func(ctx context.Context, _ *log.Logger, _ runtime.Runtime) error {
+				return c.RunContext(ctx, runtime.SequenceInstall, nil)
+			})},
+		)
+
+	c = NewControllerWithRuntime(configuredRuntime(), s)
+
+	c.SetLogger(&recordingLogger{})
+
+	if err := c.Run(runtime.SequenceUpgrade, nil); err != nil {
+		t.Fatalf("Controller.Run() error = %v, want the upgrade to complete", err)
+	}
+
+	for _, err := range []error{runErr, forcedErr, waitErr} {
+		if !errors.Is(err, runtime.ErrLocked) {
+			t.Errorf("Controller.Run() from a task error = %v, want %v", err, runtime.ErrLocked)
+		}
+	}
+
+	start := time.Now()
+
+	if err := c.Run(runtime.SequenceBoot, nil); !errors.Is(err, runtime.ErrLocked) {
+		t.Errorf("Controller.Run() from a task error = %v, want %v", err, runtime.ErrLocked)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Controller.Run() from a task waited %s", elapsed)
+	}
+}
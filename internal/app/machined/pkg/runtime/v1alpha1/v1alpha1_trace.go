@@ -0,0 +1,254 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"reflect"
+	goruntime "runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+// Trace is a record of the control flow of a sequence: the order of the
+// phases, and the timing and outcome of each task.
+type Trace struct {
+	Sequence string       `json:"sequence"`
+	Phases   []TracePhase `json:"phases"`
+}
+
+// TracePhase is the record of a phase.
+type TracePhase struct {
+	Tasks []TraceTask `json:"tasks"`
+}
+
+// TraceTask is the record of a task. The start time is relative to the start
+// of the sequence.
+type TraceTask struct {
+	Name     string        `json:"name"`
+	Start    time.Duration `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+	Degraded string        `json:"degraded,omitempty"`
+}
+
+// ReadTrace decodes a trace written by `Trace.Write`.
+func ReadTrace(r io.Reader) (*Trace, error) {
+	t := &Trace{}
+
+	if err := json.NewDecoder(r).Decode(t); err != nil {
+		return nil, fmt.Errorf("failed to decode trace: %w", err)
+	}
+
+	return t, nil
+}
+
+// Write encodes the trace as JSON.
+func (t *Trace) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(t)
+}
+
+// FailedTask returns the name of the first task that failed, or an empty
+// string if no task failed.
+func (t *Trace) FailedTask() string {
+	for _, phase := range t.Phases {
+		for _, task := range phase.Tasks {
+			if task.Error != "" {
+				return task.Name
+			}
+		}
+	}
+
+	return ""
+}
+
+// traceRecorder builds the trace of a running sequence. Overlapping phases run
+// concurrently, so tasks are recorded into the phase they belong to. A nil
+// recorder records nothing.
+type traceRecorder struct {
+	mu    sync.Mutex
+	start time.Time
+	trace *Trace
+	// numbers are the numbers of the recorded tasks of each phase, which
+	// keep them in the order of the phase rather than the one they completed
+	// in.
+	numbers [][]int
+}
+
+func newTraceRecorder(seq runtime.Sequence) *traceRecorder {
+	return &traceRecorder{
+		start: time.Now(),
+		trace: &Trace{Sequence: seq.String()},
+	}
+}
+
+type traceRecorderKey struct{}
+
+// withTraceRecorder returns a context in which the phases and tasks of the
+// sequence are recorded, nil recording nothing.
+func withTraceRecorder(ctx context.Context, t *traceRecorder) context.Context {
+	return context.WithValue(ctx, traceRecorderKey{}, t)
+}
+
+// traceRecorderFrom returns the recorder of the context, or nil.
+func traceRecorderFrom(ctx context.Context) *traceRecorder {
+	t, _ := ctx.Value(traceRecorderKey{}).(*traceRecorder)
+
+	return t
+}
+
+func (t *traceRecorder) beginPhase() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.trace.Phases = append(t.trace.Phases, TracePhase{})
+	t.numbers = append(t.numbers, nil)
+}
+
+func (t *traceRecorder) recordTask(phase, number int, name string, start time.Time, err error) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if phase < 1 || phase > len(t.trace.Phases) {
+		return
+	}
+
+	task := TraceTask{
+		Name:     name,
+		Start:    start.Sub(t.start),
+		Duration: time.Since(start),
+	}
+
+	if reason, ok := runtime.DegradedReason(err); ok {
+		task.Degraded = reason
+	} else if err != nil {
+		task.Error = err.Error()
+	}
+
+	numbers := t.numbers[phase-1]
+	i := sort.SearchInts(numbers, number)
+
+	t.numbers[phase-1] = append(numbers[:i], append([]int{number}, numbers[i:]...)...)
+
+	p := &t.trace.Phases[phase-1]
+	p.Tasks = append(p.Tasks[:i], append([]TraceTask{task}, p.Tasks[i:]...)...)
+}
+
+// taskName returns the name of the function implementing the task, without
+// the package path, e.g. "SaveConfig".
+func taskName(f runtime.TaskSetupFunc) string {
+	fn := goruntime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+
+	name := path.Base(fn.Name())
+
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return name
+}
+
+// tracedTaskName returns the name of the task of the phase, as recorded in
+// the trace being replayed, or else the name of the function implementing it.
+func tracedTaskName(ctx context.Context, phase, number int, f runtime.TaskSetupFunc) string {
+	if t, ok := ctx.Value(replayedTraceKey{}).(*Trace); ok && phase >= 1 && phase <= len(t.Phases) {
+		if tasks := t.Phases[phase-1].Tasks; number >= 1 && number <= len(tasks) {
+			return tasks[number-1].Name
+		}
+	}
+
+	return taskName(f)
+}
+
+type replayedTraceKey struct{}
+
+// NewReplayController returns a controller for replaying traces with
+// `Replay`. It has no runtime, and discards the logs of the tasks, so that it
+// runs without access to /dev/kmsg.
+//
+// This is a diagnostic feature only, meant for reproducing ordering and
+// concurrency issues observed in the field away from the real environment.
+func NewReplayController() *Controller {
+	return &Controller{
+		s:         NewSequencer(),
+		replaying: true,
+	}
+}
+
+// Replay runs the control flow recorded in the trace against stub tasks. Each
+// stub takes as long as the recorded task did, fails with the recorded error,
+// if any, and is reported with the recorded name. Phases are run as they
+// would be for the recorded sequence, including its error policy.
+//
+// This is a diagnostic feature only: the stubs do not touch the machine, but
+// a controller used for replaying should not be used to run real sequences.
+func (c *Controller) Replay(t *Trace) error {
+	seq, err := runtime.ParseSequence(t.Sequence)
+	if err != nil {
+		return err
+	}
+
+	phases := make([]runtime.Phase, 0, len(t.Phases))
+
+	for _, p := range t.Phases {
+		phase := make(runtime.Phase, 0, len(p.Tasks))
+
+		for _, task := range p.Tasks {
+			phase = append(phase, replayTask(task))
+		}
+
+		phases = append(phases, phase)
+	}
+
+	if c.TryLock() {
+		return runtime.ErrLocked
+	}
+
+	defer c.Unlock()
+
+	return c.run(context.WithValue(context.Background(), replayedTraceKey{}, t), seq, phases, nil)
+}
+
+func replayTask(task TraceTask) runtime.TaskSetupFunc {
+	return func(seq runtime.Sequence, data interface{}) runtime.TaskExecutionFunc {
+		return func(ctx context.Context, logger *log.Logger, r runtime.Runtime) error {
+			select {
+			case <-time.After(task.Duration):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			if task.Error != "" {
+				return errors.New(task.Error)
+			}
+
+			return nil
+		}
+	}
+}
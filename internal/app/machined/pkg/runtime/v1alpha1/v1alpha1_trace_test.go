@@ -0,0 +1,86 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestController_Replay(t *testing.T) {
+	recorded := &Trace{
+		Sequence: "shutdown",
+		Phases: []TracePhase{
+			{
+				Tasks: []TraceTask{
+					{Name: "StopAllServices", Duration: 10 * time.Millisecond},
+				},
+			},
+			{
+				Tasks: []TraceTask{
+					{Name: "UnmountOverlayFilesystems", Duration: 20 * time.Millisecond, Error: "device busy"},
+					{Name: "UnmountPodMounts", Duration: 5 * time.Millisecond},
+				},
+			},
+			{
+				Tasks: []TraceTask{
+					{Name: "Shutdown"},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	if err := recorded.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	trace, err := ReadTrace(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewReplayController()
+
+	if err = c.Replay(trace); err == nil {
+		t.Fatal("Controller.Replay() error = nil, want the recorded error")
+	}
+
+	replayed := c.LastTrace()
+	if replayed == nil {
+		t.Fatal("Controller.LastTrace() = nil")
+	}
+
+	if replayed.Sequence != recorded.Sequence {
+		t.Errorf("replayed sequence = %q, want %q", replayed.Sequence, recorded.Sequence)
+	}
+
+	// The shutdown sequence collects errors, so all phases are replayed.
+	if len(replayed.Phases) != len(recorded.Phases) {
+		t.Fatalf("replayed %d phase(s), want %d", len(replayed.Phases), len(recorded.Phases))
+	}
+
+	// The tasks keep the recorded names, and the order of their phase, even
+	// though the first task of the second phase completes last.
+	for i, phase := range replayed.Phases {
+		if len(phase.Tasks) != len(recorded.Phases[i].Tasks) {
+			t.Errorf("phase %d: replayed %d task(s), want %d", i+1, len(phase.Tasks), len(recorded.Phases[i].Tasks))
+
+			continue
+		}
+
+		for j, task := range phase.Tasks {
+			if want := recorded.Phases[i].Tasks[j]; task.Name != want.Name || task.Error != want.Error {
+				t.Errorf("phase %d task %d: replayed %q (%q), want %q (%q)", i+1, j+1, task.Name, task.Error, want.Name, want.Error)
+			}
+		}
+	}
+
+	if d := replayed.Phases[0].Tasks[0].Duration; d < 10*time.Millisecond {
+		t.Errorf("replayed task took %s, want at least %s", d, 10*time.Millisecond)
+	}
+}
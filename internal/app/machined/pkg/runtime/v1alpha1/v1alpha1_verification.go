@@ -0,0 +1,103 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/talos-systems/talos/api/machine"
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/pkg/containers/image"
+	"github.com/talos-systems/talos/internal/pkg/imagesig"
+)
+
+// cosignFetcher fetches the cosign signatures of an image, which tests
+// override.
+type cosignFetcher func(ctx context.Context, named reference.Named, dgst digest.Digest) ([]imagesig.CosignSignature, error)
+
+// VerifyUpgradeImage verifies the signature of the installer image of an
+// upgrade with the manifest digest. The API verifies the image before it runs
+// anything from it. The result is nil without a public key in the config or
+// the request. A staged upgrade is pinned to the digest verified, since the
+// image is only pulled on the next boot.
+func VerifyUpgradeImage(ctx context.Context, r runtime.Runtime, in *machine.UpgradeRequest, dgst digest.Digest) (*imagesig.Result, error) {
+	keys, err := UpgradeVerificationKeys(r.Config().Machine().Install(), in)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	resolver := image.NewResolver(r.Config().Machine().Registries())
+
+	fetch := func(ctx context.Context, named reference.Named, dgst digest.Digest) ([]imagesig.CosignSignature, error) {
+		return imagesig.FetchCosignSignatures(ctx, resolver, named, dgst)
+	}
+
+	return verifyUpgradeImage(ctx, keys, in, dgst, fetch)
+}
+
+// UpgradeVerificationKeys returns the keys that the image of an upgrade is
+// verified against. The keys of the config take precedence, and the key of
+// the request is only used without them.
+func UpgradeVerificationKeys(install runtime.Install, in *machine.UpgradeRequest) ([]*imagesig.PublicKey, error) {
+	if keys := install.ImageVerificationKeys(); len(keys) > 0 {
+		return imagesig.ParsePublicKeys(keys...)
+	}
+
+	if in.GetPublicKey() == "" {
+		return nil, nil
+	}
+
+	key, err := imagesig.ParsePublicKey([]byte(in.GetPublicKey()))
+	if err != nil {
+		return nil, fmt.Errorf("public key of the upgrade request: %w", err)
+	}
+
+	return []*imagesig.PublicKey{key}, nil
+}
+
+// verifyUpgradeImage verifies the image of the manifest digest with the
+// detached signature of the request, or else with the cosign signatures.
+func verifyUpgradeImage(ctx context.Context, keys []*imagesig.PublicKey, in *machine.UpgradeRequest, dgst digest.Digest, fetch cosignFetcher) (result *imagesig.Result, err error) {
+	named, err := reference.ParseNormalizedNamed(in.GetImage())
+	if err != nil {
+		return nil, fmt.Errorf("invalid image reference %q: %w", in.GetImage(), err)
+	}
+
+	if len(in.GetSignature()) > 0 {
+		result, err = imagesig.VerifyDetached(keys, dgst.String(), in.GetSignature())
+	} else {
+		var signatures []imagesig.CosignSignature
+
+		if signatures, err = fetch(ctx, named, dgst); err != nil {
+			return nil, err
+		}
+
+		result, err = imagesig.VerifyCosign(keys, reference.TrimNamed(named).String(), dgst.String(), signatures)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if in.GetStage() {
+		var pinned reference.Canonical
+
+		if pinned, err = reference.WithDigest(reference.TrimNamed(named), dgst); err != nil {
+			return nil, err
+		}
+
+		in.Image = pinned.String()
+	}
+
+	return result, nil
+}
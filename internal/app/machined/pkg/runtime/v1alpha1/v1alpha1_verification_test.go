@@ -0,0 +1,157 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/talos-systems/talos/api/machine"
+	"github.com/talos-systems/talos/internal/pkg/imagesig"
+	"github.com/talos-systems/talos/pkg/config/types/v1alpha1"
+)
+
+func Test_verifyUpgradeImage(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	keys, err := imagesig.ParsePublicKeys(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dgst := digest.FromString("manifest")
+
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"ghcr.io/talos-systems/installer"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, dgst))
+
+	cosign := func(ctx context.Context, named reference.Named, d digest.Digest) ([]imagesig.CosignSignature, error) {
+		if named.Name() != "ghcr.io/talos-systems/installer" || d != dgst {
+			t.Errorf("fetched the signatures of %s@%s", named.Name(), d)
+		}
+
+		return []imagesig.CosignSignature{{Payload: payload, Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(private, payload))}}, nil
+	}
+
+	unsigned := func(context.Context, reference.Named, digest.Digest) ([]imagesig.CosignSignature, error) {
+		return nil, imagesig.ErrNoSignature
+	}
+
+	image := "ghcr.io/talos-systems/installer:v0.6.0"
+
+	for _, tt := range []struct {
+		name   string
+		in     *machine.UpgradeRequest
+		fetch  cosignFetcher
+		method imagesig.Method
+		err    error
+		image  string
+	}{
+		{
+			name:   "detached",
+			in:     &machine.UpgradeRequest{Image: image, Signature: ed25519.Sign(private, []byte(dgst.String()))},
+			fetch:  unsigned,
+			method: imagesig.MethodDetached,
+			image:  image,
+		},
+		{
+			name:  "detached of another digest",
+			in:    &machine.UpgradeRequest{Image: image, Signature: ed25519.Sign(private, []byte("sha256:0000"))},
+			fetch: cosign,
+			err:   imagesig.ErrVerification,
+		},
+		{
+			name:   "cosign",
+			in:     &machine.UpgradeRequest{Image: image},
+			fetch:  cosign,
+			method: imagesig.MethodCosign,
+			image:  image,
+		},
+		{
+			name:   "staged",
+			in:     &machine.UpgradeRequest{Image: image, Stage: true},
+			fetch:  cosign,
+			method: imagesig.MethodCosign,
+			image:  "ghcr.io/talos-systems/installer@" + dgst.String(),
+		},
+		{
+			name:  "unsigned",
+			in:    &machine.UpgradeRequest{Image: image},
+			fetch: unsigned,
+			err:   imagesig.ErrNoSignature,
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := verifyUpgradeImage(context.Background(), keys, tt.in, dgst, tt.fetch)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("verifyUpgradeImage() error = %v, want %v", err, tt.err)
+				}
+
+				if tt.in.Image != image {
+					t.Errorf("verifyUpgradeImage() changed the image to %q", tt.in.Image)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("verifyUpgradeImage() error = %v", err)
+			}
+
+			if result.Method != tt.method || result.Digest != dgst.String() || result.Fingerprint != keys[0].Fingerprint {
+				t.Errorf("verifyUpgradeImage() = %+v, want a %s signature of %s", result, tt.method, dgst)
+			}
+
+			if tt.in.Image != tt.image {
+				t.Errorf("verifyUpgradeImage() image = %q, want %q", tt.in.Image, tt.image)
+			}
+		})
+	}
+}
+
+func TestUpgradeVerificationKeys(t *testing.T) {
+	key := "-----BEGIN PUBLIC KEY-----\nMCowBQYDK2VwAyEAcdzeScy3TxgyCTr0PIDQS69QPXRpaqTX2w3iWvYgAmc=\n-----END PUBLIC KEY-----\n"
+
+	keys, err := UpgradeVerificationKeys(&v1alpha1.InstallConfig{}, &machine.UpgradeRequest{})
+	if err != nil || len(keys) != 0 {
+		t.Errorf("UpgradeVerificationKeys() = %v, %v, want no keys", keys, err)
+	}
+
+	if keys, err = UpgradeVerificationKeys(&v1alpha1.InstallConfig{}, &machine.UpgradeRequest{PublicKey: key}); err != nil || len(keys) != 1 {
+		t.Errorf("UpgradeVerificationKeys() = %v, %v, want the key of the request", keys, err)
+	}
+
+	// The keys of the config take precedence over the key of the request.
+	install := &v1alpha1.InstallConfig{InstallImageVerificationKeys: []string{key}}
+
+	if keys, err = UpgradeVerificationKeys(install, &machine.UpgradeRequest{PublicKey: "invalid"}); err != nil || len(keys) != 1 {
+		t.Errorf("UpgradeVerificationKeys() = %v, %v, want the key of the config", keys, err)
+	}
+
+	if _, err = UpgradeVerificationKeys(&v1alpha1.InstallConfig{}, &machine.UpgradeRequest{PublicKey: "invalid"}); !errors.Is(err, imagesig.ErrInvalidKey) {
+		t.Errorf("UpgradeVerificationKeys() error = %v, want %v", err, imagesig.ErrInvalidKey)
+	}
+}
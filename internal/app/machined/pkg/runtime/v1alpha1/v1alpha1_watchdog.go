@@ -0,0 +1,117 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/pkg/watchdog"
+)
+
+// watchdogDevice is an armed hardware watchdog.
+type watchdogDevice interface {
+	Pet() error
+	Disarm() error
+}
+
+// openWatchdog arms the watchdog, and returns it with the interval it must be
+// pet at. Tests override it.
+var openWatchdog = func(cfg runtime.Watchdog) (watchdogDevice, time.Duration, error) {
+	w, err := watchdog.Open(cfg.Device())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	timeout, err := w.SetTimeout(cfg.Timeout())
+	if err != nil {
+		// Some drivers have a fixed timeout.
+		if timeout, err = w.Timeout(); err != nil {
+			// nolint: errcheck
+			w.Disarm()
+
+			return nil, 0, err
+		}
+	}
+
+	if timeout < time.Second {
+		timeout = time.Second
+	}
+
+	return w, timeout / 3, nil
+}
+
+// startWatchdog pets the hardware watchdog, if it is configured, until the
+// context is canceled or the returned function is called. The watchdog is
+// disarmed once the petting stops.
+func (c *Controller) startWatchdog(ctx context.Context, seq runtime.Sequence) (stop func()) {
+	stop = func() {}
+
+	if c.r.Config() == nil || !c.r.Config().Machine().Watchdog().Enabled() {
+		return stop
+	}
+
+	if c.r.State().Platform().Mode() == runtime.ModeContainer {
+		return stop
+	}
+
+	cfg := c.r.Config().Machine().Watchdog()
+
+	if _, err := os.Stat(cfg.Device()); err != nil {
+		c.log().Warn("watchdog device not found", "sequence", seq, "device", cfg.Device(), "error", err)
+
+		return stop
+	}
+
+	wd, interval, err := openWatchdog(cfg)
+	if err != nil {
+		c.log().Warn("failed to arm watchdog", "sequence", seq, "device", cfg.Device(), "error", err)
+
+		return stop
+	}
+
+	c.log().Debug("petting watchdog", "sequence", seq, "device", cfg.Device(), "interval", interval)
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		petWatchdog(ctx, wd, interval, c.log())
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// petWatchdog pets the watchdog at the interval until the context is
+// canceled, and then disarms it.
+func petWatchdog(ctx context.Context, wd watchdogDevice, interval time.Duration, logger runtime.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	defer func() {
+		if err := wd.Disarm(); err != nil {
+			logger.Warn("failed to disarm watchdog", "error", err)
+		}
+	}()
+
+	for {
+		if err := wd.Pet(); err != nil {
+			logger.Warn("failed to pet watchdog", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
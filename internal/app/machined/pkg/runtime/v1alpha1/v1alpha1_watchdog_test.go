@@ -0,0 +1,71 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package v1alpha1
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeWatchdog struct {
+	mu       sync.Mutex
+	pets     int
+	disarmed bool
+}
+
+func (w *fakeWatchdog) Pet() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.disarmed {
+		panic("pet after disarm")
+	}
+
+	w.pets++
+
+	return nil
+}
+
+func (w *fakeWatchdog) Disarm() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.disarmed = true
+
+	return nil
+}
+
+func Test_petWatchdog(t *testing.T) {
+	wd := &fakeWatchdog{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		petWatchdog(ctx, wd, time.Millisecond, &recordingLogger{})
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("petting did not stop when the context was canceled")
+	}
+
+	if wd.pets < 2 {
+		t.Errorf("pets = %d, want the watchdog pet repeatedly", wd.pets)
+	}
+
+	if !wd.disarmed {
+		t.Error("watchdog should be disarmed")
+	}
+}
@@ -0,0 +1,110 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// ConfigValidator defines the function type for additional config validation
+// rules. Errors should be reported using `ConfigValidationError` so that the
+// offending field can be identified.
+type ConfigValidator func(Configurator, Mode) error
+
+// ConfigValidationError describes a config field that failed validation.
+type ConfigValidationError struct {
+	Path  string
+	Value interface{}
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *ConfigValidationError) Error() string {
+	return fmt.Sprintf("[%s] %q: %s", e.Path, fmt.Sprint(e.Value), e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ConfigValidationError) Unwrap() error {
+	return e.Err
+}
+
+var validators = struct {
+	sync.RWMutex
+	m map[string]ConfigValidator
+}{
+	m: map[string]ConfigValidator{},
+}
+
+// RegisterConfigValidator registers a validator that is run in addition to the
+// built-in validation of the config. It is expected to be called from an
+// `init` function of the package providing the validator.
+func RegisterConfigValidator(name string, v ConfigValidator) error {
+	validators.Lock()
+	defer validators.Unlock()
+
+	if _, ok := validators.m[name]; ok {
+		return fmt.Errorf("config validator %q is already registered", name)
+	}
+
+	validators.m[name] = v
+
+	return nil
+}
+
+// UnregisterConfigValidator removes a previously registered validator.
+func UnregisterConfigValidator(name string) {
+	validators.Lock()
+	defer validators.Unlock()
+
+	delete(validators.m, name)
+}
+
+// RunConfigValidators runs all registered validators in order of name, and
+// aggregates their errors.
+func RunConfigValidators(c Configurator, mode Mode) error {
+	validators.RLock()
+	defer validators.RUnlock()
+
+	names := make([]string, 0, len(validators.m))
+
+	for name := range validators.m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var result *multierror.Error
+
+	for _, name := range names {
+		if err := validators.m[name](c, mode); err != nil {
+			result = multierror.Append(result, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// InstallDiskPrefixValidator returns a validator that requires the install
+// disk to begin with the specified prefix (e.g. "/dev/nvme").
+func InstallDiskPrefixValidator(prefix string) ConfigValidator {
+	return func(c Configurator, mode Mode) error {
+		disk := c.Machine().Install().Disk()
+
+		if !strings.HasPrefix(disk, prefix) {
+			return &ConfigValidationError{
+				Path:  "machine.install.disk",
+				Value: disk,
+				Err:   fmt.Errorf("install disk must begin with %q", prefix),
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+func TestRunConfigValidators(t *testing.T) {
+	errPolicy := errors.New("policy")
+
+	var called []string
+
+	if err := RegisterConfigValidator("b", func(Configurator, Mode) error {
+		called = append(called, "b")
+
+		return &ConfigValidationError{Path: "machine.type", Value: "join", Err: errPolicy}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	defer UnregisterConfigValidator("b")
+
+	if err := RegisterConfigValidator("a", func(Configurator, Mode) error {
+		called = append(called, "a")
+
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	defer UnregisterConfigValidator("a")
+
+	if err := RegisterConfigValidator("a", func(Configurator, Mode) error { return nil }); err == nil {
+		t.Error("RegisterConfigValidator() with duplicate name error = nil, want error")
+	}
+
+	err := RunConfigValidators(nil, ModeMetal)
+
+	merr, ok := err.(*multierror.Error)
+	if !ok || len(merr.Errors) != 1 {
+		t.Fatalf("RunConfigValidators() error = %v, want 1 error", err)
+	}
+
+	if !errors.Is(merr.Errors[0], errPolicy) {
+		t.Errorf("RunConfigValidators() error = %v, want %v", merr.Errors[0], errPolicy)
+	}
+
+	var verr *ConfigValidationError
+
+	if !errors.As(merr.Errors[0], &verr) || verr.Path != "machine.type" {
+		t.Errorf("RunConfigValidators() error = %v, want path %q", merr.Errors[0], "machine.type")
+	}
+
+	if len(called) != 2 || called[0] != "a" || called[1] != "b" {
+		t.Errorf("RunConfigValidators() called = %v, want %v", called, []string{"a", "b"})
+	}
+
+	UnregisterConfigValidator("b")
+
+	if err = RunConfigValidators(nil, ModeMetal); err != nil {
+		t.Errorf("RunConfigValidators() error = %v, want nil", err)
+	}
+}
@@ -48,7 +48,7 @@ func (b *Bootkube) PreFunc(ctx context.Context, r runtime.Runtime) (err error) {
 	// nolint: errcheck
 	defer client.Close()
 
-	err = retry.Exponential(3*time.Minute, retry.WithUnits(50*time.Millisecond), retry.WithJitter(25*time.Millisecond)).Retry(func() error {
+	err = retry.Exponential(3*time.Minute, retry.WithUnits(50*time.Millisecond), retry.WithJitter(25*time.Millisecond), retry.WithCap(5*time.Second)).Retry(func() error {
 		var resp *clientv3.GetResponse
 
 		// limit single attempt to 15 seconds to allow for 12 attempts at least
@@ -109,7 +109,7 @@ func (b *Bootkube) PostFunc(r runtime.Runtime, state events.ServiceState) (err e
 	// nolint: errcheck
 	defer client.Close()
 
-	err = retry.Exponential(15*time.Second, retry.WithUnits(50*time.Millisecond), retry.WithJitter(25*time.Millisecond)).Retry(func() error {
+	err = retry.Exponential(15*time.Second, retry.WithUnits(50*time.Millisecond), retry.WithJitter(25*time.Millisecond), retry.WithCap(5*time.Second)).Retry(func() error {
 		ctx := clientv3.WithRequireLeader(context.Background())
 		if _, err = client.Put(ctx, constants.InitializedKey, "true"); err != nil {
 			return retry.ExpectedError(err)
@@ -75,9 +75,36 @@ func (n *Timed) Runner(r runtime.Runtime) (runner.Runner, error) {
 		return nil, err
 	}
 
+	// Ensure drift file dir exists
+	driftDir := filepath.Dir(r.Config().Machine().Time().DriftFile())
+	if err := os.MkdirAll(driftDir, 0700); err != nil {
+		return nil, err
+	}
+
 	mounts := []specs.Mount{
 		{Type: "bind", Destination: constants.ConfigPath, Source: constants.ConfigPath, Options: []string{"rbind", "ro"}},
 		{Type: "bind", Destination: filepath.Dir(constants.TimeSocketPath), Source: filepath.Dir(constants.TimeSocketPath), Options: []string{"rbind", "rw"}},
+		{Type: "bind", Destination: driftDir, Source: driftDir, Options: []string{"rbind", "rw"}},
+		// The sanity check endpoint is resolved and verified as on the host.
+		{Type: "bind", Destination: "/etc/ssl", Source: "/etc/ssl", Options: []string{"bind", "ro"}},
+		{Type: "bind", Destination: "/etc/resolv.conf", Source: "/etc/resolv.conf", Options: []string{"rbind", "ro"}},
+	}
+
+	specOpts := []oci.SpecOpts{
+		containerd.WithMemoryLimit(int64(1000000 * 32)),
+		oci.WithCapabilities([]string{
+			strings.ToUpper("CAP_" + capability.CAP_SYS_TIME.String()),
+		}),
+		oci.WithHostNamespace(specs.NetworkNamespace),
+		oci.WithMounts(mounts),
+	}
+
+	// The device is only passed if it exists, as the spec can't be created
+	// otherwise, timed then uses the time of the servers.
+	if phc := r.Config().Machine().Time().PHC(); phc != "" {
+		if _, err := os.Stat(phc); err == nil {
+			specOpts = append(specOpts, oci.WithLinuxDevice(phc, "r"))
+		}
 	}
 
 	env := []string{}
@@ -91,14 +118,7 @@ func (n *Timed) Runner(r runtime.Runtime) (runner.Runner, error) {
 		runner.WithContainerdAddress(constants.SystemContainerdAddress),
 		runner.WithContainerImage(image),
 		runner.WithEnv(env),
-		runner.WithOCISpecOpts(
-			containerd.WithMemoryLimit(int64(1000000*32)),
-			oci.WithCapabilities([]string{
-				strings.ToUpper("CAP_" + capability.CAP_SYS_TIME.String()),
-			}),
-			oci.WithHostNamespace(specs.NetworkNamespace),
-			oci.WithMounts(mounts),
-		),
+		runner.WithOCISpecOpts(specOpts...),
 	),
 		restart.WithType(restart.Forever),
 	), nil
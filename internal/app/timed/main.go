@@ -35,14 +35,14 @@ func init() {
 	flag.Parse()
 }
 
-// New instantiates a new ntp instance against a given server
+// New instantiates a new ntp instance against the given servers
 // If no servers are specified, the default will be used
 func main() {
 	if err := startup.RandSeed(); err != nil {
 		log.Fatalf("startup: %v", err)
 	}
 
-	server := DefaultServer
+	servers := []string{DefaultServer}
 
 	config, err := config.NewFromFile(*configPath)
 	if err != nil {
@@ -50,14 +50,32 @@ func main() {
 	}
 
 	// Check if ntp servers are defined
-	// Support for only a single time server currently
 	if len(config.Machine().Time().Servers()) >= 1 {
-		server = config.Machine().Time().Servers()[0]
+		servers = config.Machine().Time().Servers()
 	}
 
-	n, err := ntp.NewNTPClient(
-		ntp.WithServer(server),
-	)
+	opts := []ntp.Option{
+		ntp.WithServers(servers...),
+		ntp.WithTolerance(config.Machine().Time().Tolerance()),
+		ntp.WithMinPoll(int(config.Machine().Time().MinPoll().Seconds())),
+		ntp.WithMaxPoll(int(config.Machine().Time().MaxPoll().Seconds())),
+		ntp.WithDriftFile(config.Machine().Time().DriftFile()),
+		ntp.WithDriftWarningThreshold(config.Machine().Time().DriftWarningThreshold()),
+		ntp.WithIBurst(constants.DefaultTimeIBurst),
+	}
+
+	if phc := config.Machine().Time().PHC(); phc != "" {
+		opts = append(opts, ntp.WithPHC(phc))
+	}
+
+	if u := config.Machine().Time().SanityCheckURL(); u != "" {
+		opts = append(opts,
+			ntp.WithSanityCheck(u, config.Machine().Time().SanityCheckThreshold()),
+			ntp.WithSanityCheckRequired(config.Machine().Time().SanityCheckRequired()),
+		)
+	}
+
+	n, err := ntp.NewNTPClient(opts...)
 	if err != nil {
 		log.Fatalf("failed to create ntp client: %v", err)
 	}
@@ -0,0 +1,136 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"syscall"
+	"time"
+)
+
+// The adjtimex modes and status bits, see linux/timex.h.
+const (
+	adjOffset = 0x0001
+	adjStatus = 0x0010
+	adjNano   = 0x2000
+
+	staPLL = 0x0001
+)
+
+// ErrMaxStep indicates that the clock offset measured against a server exceeds
+// the maximum step, so the clock is not adjusted.
+var ErrMaxStep = errors.New("clock offset exceeds the maximum step")
+
+// maxSlewOffset is the largest offset the kernel PLL accepts, larger offsets
+// are clamped.
+const maxSlewOffset = 500 * time.Millisecond
+
+// adjtimex and settimeofday are variables so that tests can run without
+// changing the system clock.
+var (
+	adjtimex     = syscall.Adjtimex
+	settimeofday = syscall.Settimeofday
+)
+
+// adjustMode represents the way the clock is corrected.
+type adjustMode int
+
+const (
+	// adjustStep sets the clock to the corrected time at once.
+	adjustStep adjustMode = iota
+	// adjustSlew hands the offset to the kernel PLL, which corrects the clock
+	// gradually by adjusting its frequency, so that time never jumps.
+	adjustSlew
+)
+
+// String returns the string representation of an `adjustMode`.
+func (m adjustMode) String() string {
+	return [...]string{"step", "slew"}[m]
+}
+
+// adjust corrects the clock for the offset. The leap second of the status
+// bits is armed when slewing, since the kernel status is set along with the
+// offset.
+func (m adjustMode) adjust(offset time.Duration, leap int32) error {
+	if m == adjustSlew {
+		return slewTime(offset, leap)
+	}
+
+	return adjustTime(offset)
+}
+
+// adjustMode returns the way to correct the clock for the offset: offsets
+// smaller than the step threshold are slewed, and larger ones are stepped.
+func (n *NTP) adjustMode(offset time.Duration) adjustMode {
+	if offset < 0 {
+		offset = -offset
+	}
+
+	if offset < n.StepThreshold {
+		return adjustSlew
+	}
+
+	return adjustStep
+}
+
+// slewTime hands the offset to the kernel PLL, along with the status bits of
+// the leap second, if any.
+func slewTime(offset time.Duration, leap int32) error {
+	timex := &syscall.Timex{
+		Modes:  adjOffset | adjStatus | adjNano,
+		Offset: int64(offset),
+		Status: staPLL | leap,
+	}
+
+	_, err := adjtimex(timex)
+
+	return err
+}
+
+// SetTime sets the system time based on the query response.
+func setTime(adjustedTime time.Time) error {
+	log.Printf("setting time to %s", adjustedTime)
+
+	timeval := syscall.NsecToTimeval(adjustedTime.UnixNano())
+
+	return settimeofday(&timeval)
+}
+
+// adjustTime adds an offset to the current time.
+func adjustTime(offset time.Duration) error {
+	return setTime(time.Now().Add(offset))
+}
+
+// ExceedsMaxStep returns true if the clock offset exceeds the maximum step.
+func (n *NTP) ExceedsMaxStep(offset time.Duration) bool {
+	if offset < 0 {
+		offset = -offset
+	}
+
+	return n.MaxStep > 0 && offset > n.MaxStep
+}
+
+// checkMaxStep refuses an offset exceeding the maximum step, in which case the
+// client is marked unsynced until a later sync measures a sane offset. The
+// clock of a machine without a battery backed RTC can start far off, so the
+// threshold only applies once the initial sync completed.
+func (n *NTP) checkMaxStep(offset time.Duration, server string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.syncedOnce || !n.ExceedsMaxStep(offset) {
+		return nil
+	}
+
+	log.Printf("refusing to adjust the clock by %s from %s, which exceeds the maximum step of %s", offset, server, n.MaxStep)
+
+	n.rejected = true
+	n.inBounds = false
+	n.pollAdapter().reset()
+
+	return fmt.Errorf("offset %s from %s: %w", offset, server, ErrMaxStep)
+}
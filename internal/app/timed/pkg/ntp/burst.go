@@ -0,0 +1,96 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"context"
+	"time"
+)
+
+// maxIBurst is the largest number of queries of a burst, as sent by ntpd.
+const maxIBurst = 8
+
+// iburstSpacing is the minimum time between two queries of a burst to the
+// same server. It is a variable so that tests can run without waiting.
+var iburstSpacing = 2 * time.Second
+
+// queryBurst queries each of the sources count times, at least iburstSpacing
+// apart, and merges the samples of each source. The burst stops early, with
+// the samples of the rounds completed so far, if the context is done, or if no
+// source responded to a round, so that the retries of unreachable servers are
+// not delayed.
+func (n *NTP) queryBurst(ctx context.Context, count int) []*Sample {
+	rounds := make([][]*Sample, 0, count)
+
+	for i := 0; i < count; i++ {
+		start := time.Now()
+
+		round := n.queryRound(ctx)
+		rounds = append(rounds, round)
+
+		if i == count-1 || !responded(round) {
+			break
+		}
+
+		select {
+		case <-time.After(iburstSpacing - time.Since(start)):
+		case <-ctx.Done():
+			return mergeBurst(rounds)
+		}
+	}
+
+	return mergeBurst(rounds)
+}
+
+// responded returns whether any of the samples succeeded.
+func responded(samples []*Sample) bool {
+	for _, s := range samples {
+		if s.Err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeBurst merges the samples of each source across the rounds of a burst.
+// The clock offset and round-trip delay of the merged sample are the averages
+// of those of the samples which succeeded, the rest of the response is that of
+// the last of them. A source which never responded keeps its last error.
+func mergeBurst(rounds [][]*Sample) []*Sample {
+	last := rounds[len(rounds)-1]
+	merged := make([]*Sample, len(last))
+
+	for i := range last {
+		var (
+			good        []*Sample
+			offset, rtt time.Duration
+		)
+
+		for _, round := range rounds {
+			if s := round[i]; s.Err == nil {
+				good = append(good, s)
+				offset += s.Response.ClockOffset
+				rtt += s.Response.RTT
+			}
+		}
+
+		if len(good) == 0 {
+			merged[i] = last[i]
+
+			continue
+		}
+
+		s := *good[len(good)-1]
+		resp := *s.Response
+		resp.ClockOffset = offset / time.Duration(len(good))
+		resp.RTT = rtt / time.Duration(len(good))
+		s.Response = &resp
+
+		merged[i] = &s
+	}
+
+	return merged
+}
@@ -0,0 +1,79 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	adjFrequency = 0x0002
+
+	// freqScale is the scale of the kernel frequency, which is in ppm with a
+	// 16 bit fractional part.
+	freqScale = 1 << 16
+
+	// maxFrequency is the largest frequency correction the kernel accepts, in
+	// ppm.
+	maxFrequency = 500
+)
+
+// driftSaveInterval is the minimum interval between saves of the drift file.
+const driftSaveInterval = time.Hour
+
+// LoadDrift sets the kernel frequency correction to the one saved in the
+// drift file, in ppm as written by ntpd. A missing file is not an error, and
+// a corrupt file leaves the frequency correction untouched.
+func (n *NTP) LoadDrift(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	ppm, err := strconv.ParseFloat(strings.TrimSpace(string(b)), 64)
+	if err != nil || math.IsNaN(ppm) || math.Abs(ppm) > maxFrequency {
+		return fmt.Errorf("corrupt drift file %q: %q", path, strings.TrimSpace(string(b)))
+	}
+
+	_, err = adjtimex(&syscall.Timex{
+		Modes: adjFrequency,
+		Freq:  int64(math.Round(ppm * freqScale)),
+	})
+
+	return err
+}
+
+// SaveDrift writes the current kernel frequency correction to the drift file.
+// The file is replaced atomically, so that it is never left corrupt.
+func (n *NTP) SaveDrift(path string) error {
+	ppm, err := readFrequency()
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+
+	if err = ioutil.WriteFile(tmp, []byte(fmt.Sprintf("%.3f\n", ppm)), 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,127 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"log"
+	"math"
+	"syscall"
+	"time"
+)
+
+const (
+	// frequencyWindow is the interval over which the change of the frequency
+	// correction is tracked.
+	frequencyWindow = 24 * time.Hour
+
+	// frequencySamples bounds the number of frequency corrections recorded
+	// over the window, since the clock is polled more often while it is
+	// unstable.
+	frequencySamples = 256
+)
+
+// Drift is the frequency correction the kernel applies to the clock, which
+// compensates for the drift rate of its oscillator.
+type Drift struct {
+	// PPM is the current frequency correction, in parts per million.
+	PPM float64
+	// Change is the change of the frequency correction over the window, in
+	// ppm. A healthy oscillator settles, while a failing one keeps drifting.
+	Change float64
+	// Window is the interval the change is measured over, which is shorter
+	// than the tracking window until it is filled.
+	Window time.Duration
+	// Warning indicates that the magnitude of the frequency correction
+	// exceeds the warning threshold.
+	Warning bool
+}
+
+type frequencySample struct {
+	at  time.Time
+	ppm float64
+}
+
+// frequencyTracker records the frequency correction after every sync. The
+// zero value is ready to use.
+type frequencyTracker struct {
+	samples []frequencySample
+}
+
+// update records the frequency correction read at the time.
+func (f *frequencyTracker) update(at time.Time, ppm float64) {
+	f.samples = append(f.samples, frequencySample{at: at, ppm: ppm})
+
+	i := 0
+	for i < len(f.samples)-1 && at.Sub(f.samples[i].at) > frequencyWindow {
+		i++
+	}
+
+	if len(f.samples)-i > frequencySamples {
+		i = len(f.samples) - frequencySamples
+	}
+
+	f.samples = f.samples[i:]
+}
+
+// value returns the drift, and false until a frequency correction was
+// recorded.
+func (f *frequencyTracker) value(threshold float64) (Drift, bool) {
+	if len(f.samples) == 0 {
+		return Drift{}, false
+	}
+
+	first, last := f.samples[0], f.samples[len(f.samples)-1]
+
+	return Drift{
+		PPM:     last.ppm,
+		Change:  last.ppm - first.ppm,
+		Window:  last.at.Sub(first.at),
+		Warning: threshold > 0 && math.Abs(last.ppm) > threshold,
+	}, true
+}
+
+// readFrequency returns the current kernel frequency correction, in ppm.
+func readFrequency() (float64, error) {
+	timex := &syscall.Timex{}
+
+	if _, err := adjtimex(timex); err != nil {
+		return 0, err
+	}
+
+	return float64(timex.Freq) / freqScale, nil
+}
+
+// recordFrequency records the current kernel frequency correction, and warns
+// once the magnitude exceeds the threshold.
+func (n *NTP) recordFrequency() {
+	ppm, err := readFrequency()
+	if err != nil {
+		log.Printf("failed to read the frequency correction: %v", err)
+
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	previous, ok := n.frequency.value(n.DriftWarningThreshold)
+
+	n.frequency.update(time.Now(), ppm)
+
+	drift, _ := n.frequency.value(n.DriftWarningThreshold)
+
+	if drift.Warning && (!ok || !previous.Warning) {
+		log.Printf("frequency correction of %.3f ppm exceeds the warning threshold of %g ppm, the oscillator may be failing", drift.PPM, n.DriftWarningThreshold)
+	}
+}
+
+// Drift returns the frequency correction of the clock, and false before it is
+// first read after a sync.
+func (n *NTP) Drift() (Drift, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.frequency.value(n.DriftWarningThreshold)
+}
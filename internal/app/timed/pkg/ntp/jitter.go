@@ -0,0 +1,48 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"math"
+	"time"
+)
+
+// jitterSamples is the number of recent clock offsets the jitter is estimated
+// from, which matches the size of the clock filter in ntpd.
+const jitterSamples = 8
+
+// jitterEstimator estimates the jitter as the root mean square of the
+// differences between successive clock offsets. The zero value is ready to
+// use.
+type jitterEstimator struct {
+	offsets []time.Duration
+}
+
+// update records the offset measured by a sync, and returns the jitter.
+func (j *jitterEstimator) update(offset time.Duration) time.Duration {
+	j.offsets = append(j.offsets, offset)
+
+	if len(j.offsets) > jitterSamples {
+		j.offsets = j.offsets[len(j.offsets)-jitterSamples:]
+	}
+
+	return j.value()
+}
+
+// value returns the jitter, or zero until at least two offsets were recorded.
+func (j *jitterEstimator) value() time.Duration {
+	if len(j.offsets) < 2 {
+		return 0
+	}
+
+	var sum float64
+
+	for i := 1; i < len(j.offsets); i++ {
+		d := float64(j.offsets[i] - j.offsets[i-1])
+		sum += d * d
+	}
+
+	return time.Duration(math.Sqrt(sum / float64(len(j.offsets)-1)))
+}
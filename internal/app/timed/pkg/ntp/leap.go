@@ -0,0 +1,58 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"syscall"
+	"time"
+
+	"github.com/beevik/ntp"
+)
+
+// The adjtimex status bits arming a leap second, see linux/timex.h.
+const (
+	staIns = 0x0010
+	staDel = 0x0020
+)
+
+// leapStatus returns the kernel status bits arming the leap second announced
+// by the leap indicator. Servers announce a leap second during the month at
+// the end of which it occurs, while the kernel applies an armed leap second
+// at the next midnight UTC, so it is only armed on the last day of the month.
+func leapStatus(leap ntp.LeapIndicator, now time.Time) int32 {
+	now = now.UTC()
+
+	if now.AddDate(0, 0, 1).Month() == now.Month() {
+		return 0
+	}
+
+	switch leap {
+	case ntp.LeapAddSecond:
+		return staIns
+	case ntp.LeapDelSecond:
+		return staDel
+	default:
+		return 0
+	}
+}
+
+// setLeapStatus arms the leap second of the status bits, or disarms it if
+// there are none, and keeps the kernel PLL enabled.
+func setLeapStatus(status int32) error {
+	_, err := adjtimex(&syscall.Timex{
+		Modes:  adjStatus,
+		Status: staPLL | status,
+	})
+
+	return err
+}
+
+// Leap returns the leap indicator of the response of the most recent sync.
+func (n *NTP) Leap() ntp.LeapIndicator {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.leap
+}
@@ -0,0 +1,37 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"time"
+
+	"github.com/beevik/ntp"
+)
+
+// phi is the frequency tolerance of the clocks, 15 PPM, which bounds how much
+// a clock drifts between two readings, see RFC 5905.
+const phi = 15e-6
+
+// drift returns the maximum drift of a clock over the interval.
+func drift(interval time.Duration) time.Duration {
+	return time.Duration(phi * float64(interval))
+}
+
+// RootDispersion returns the dispersion of the time of the response relative
+// to the primary reference: the root dispersion reported by the server, plus
+// the precision of the server, and the drift of the clocks over the round
+// trip. Unlike in RFC 5905, the jitter of the server is not included, as it
+// takes several samples to measure.
+func RootDispersion(resp *ntp.Response) time.Duration {
+	return resp.RootDispersion + resp.Precision + drift(resp.RTT)
+}
+
+// MaxError returns the maximum error of the time of the response, once
+// corrected for the clock offset: half of the round trips from the primary
+// reference, plus the root dispersion, which is the root synchronization
+// distance of RFC 5905. The time is accurate to within ±MaxError.
+func MaxError(resp *ntp.Response) time.Duration {
+	return resp.RootDelay/2 + resp.RTT/2 + RootDispersion(resp)
+}
@@ -5,10 +5,13 @@
 package ntp
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
-	"syscall"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/beevik/ntp"
@@ -17,21 +20,89 @@ import (
 	"github.com/talos-systems/talos/pkg/retry"
 )
 
-// NTP contains a server address
+// queryRetryUnits is the unit of the exponential backoff between the retries
+// of a query.
+const queryRetryUnits = time.Second
+
+// NTP contains the addresses of the servers to query.
 type NTP struct {
-	Server  string
-	MinPoll time.Duration
-	MaxPoll time.Duration
+	// Servers are the servers to query, which are set with SetServers once
+	// the client is in use.
+	Servers       []string
+	MinPoll       time.Duration
+	MaxPoll       time.Duration
+	Tolerance     time.Duration
+	StepThreshold time.Duration
+	DriftFile     string
+	// MaxStep is the clock offset above which the clock is not adjusted, zero
+	// disables the threshold.
+	MaxStep time.Duration
+	// QueryTimeout is the time given to each server to respond to a query.
+	QueryTimeout time.Duration
+	// PollJitter is the fraction of the poll interval up to which a random
+	// delay is added to each interval.
+	PollJitter float64
+	// IBurst is the number of queries sent to each server on the initial
+	// sync, which are averaged to establish the offset quickly.
+	IBurst int
+	// PHC is the device of a PTP hardware clock, which is preferred over the
+	// servers while it agrees with them.
+	PHC string
+	// SanityCheckURL is an HTTPS endpoint which the time of the servers is
+	// compared with, before the clock is adjusted.
+	SanityCheckURL string
+	// SanityCheckThreshold is the offset between the time of the servers and
+	// the time of the sanity check endpoint above which the clock is not
+	// adjusted.
+	SanityCheckThreshold time.Duration
+	// SanityCheckRequired indicates that the clock is not adjusted while the
+	// sanity check endpoint is unreachable either.
+	SanityCheckRequired bool
+	// DriftWarningThreshold is the magnitude of the frequency correction, in
+	// ppm, above which the drift of the clock is flagged, zero disables the
+	// warning.
+	DriftWarningThreshold float64
+
+	mu          sync.Mutex
+	offset      time.Duration
+	server      string
+	lastSync    time.Time
+	maxError    time.Duration
+	syncedOnce  bool
+	inBounds    bool
+	poll        *pollAdapter
+	rand        *rand.Rand
+	jitter      jitterEstimator
+	frequency   frequencyTracker
+	driftSave   time.Time
+	subscribers map[chan SyncState]struct{}
+	leap        ntp.LeapIndicator
+	// leapArmed holds the kernel status bits of the leap second armed by the
+	// most recent sync, if any.
+	leapArmed int32
+	// rejected indicates that the offset measured by the most recent sync
+	// exceeded the maximum step.
+	rejected bool
+	// sanityCheck is the result of the sanity check of the most recent sync.
+	sanityCheck *SanityCheck
 }
 
 // NewNTPClient instantiates a new ntp client for the
-// specified server.
+// specified servers.
 func NewNTPClient(opts ...Option) (*NTP, error) {
 	ntp := defaultOptions()
 
 	var result *multierror.Error
 	for _, setter := range opts {
-		result = multierror.Append(setter(ntp))
+		result = multierror.Append(result, setter(ntp))
+	}
+
+	if ntp.MinPoll > ntp.MaxPoll {
+		result = multierror.Append(result, fmt.Errorf("MinPoll(%s) is larger than MaxPoll(%s)", ntp.MinPoll, ntp.MaxPoll))
+	}
+
+	if ntp.MaxStep > 0 && ntp.MaxStep < ntp.StepThreshold {
+		result = multierror.Append(result, fmt.Errorf("MaxStep(%s) is smaller than StepThreshold(%s)", ntp.MaxStep, ntp.StepThreshold))
 	}
 
 	return ntp, result.ErrorOrNil()
@@ -41,16 +112,20 @@ func NewNTPClient(opts ...Option) (*NTP, error) {
 // We dont ever want the daemon to stop, so we only log
 // errors.
 func (n *NTP) Daemon() (err error) {
+	if n.DriftFile != "" {
+		if err = n.LoadDrift(n.DriftFile); err != nil {
+			log.Printf("starting without frequency correction: %v", err)
+		}
+	}
+
 	if err = n.QueryAndSetTime(); err != nil {
 		log.Println(err)
 	}
 
 	for {
-		// Set some variance with how frequently we poll ntp servers.
-		// This is based on rand(MaxPoll) + MinPoll so we wait at least
-		// MinPoll.
-		randSleep := time.Duration(rand.Intn(int(n.MaxPoll.Seconds()))) * time.Second
-		time.Sleep(randSleep + n.MinPoll)
+		// The poll interval widens while the clock is stable, and narrows
+		// when it is not.
+		time.Sleep(n.nextPoll())
 
 		if err = n.QueryAndSetTime(); err != nil {
 			log.Println(err)
@@ -58,27 +133,166 @@ func (n *NTP) Daemon() (err error) {
 	}
 }
 
-// Query polls the ntp server and verifies a successful response.
-func (n *NTP) Query() (resp *ntp.Response, err error) {
-	err = retry.Constant(n.MaxPoll, retry.WithUnits(n.MinPoll), retry.WithJitter(250*time.Millisecond)).Retry(func() error {
-		resp, err = ntp.Query(n.Server)
-		if err != nil {
-			log.Printf("query error: %v", err)
-			return retry.ExpectedError(err)
-		}
+// Query polls the ntp servers and returns the response of the best server.
+func (n *NTP) Query(ctx context.Context) (resp *ntp.Response, err error) {
+	var best *Sample
+
+	if best, _, err = n.QueryBest(ctx); err != nil {
+		return nil, err
+	}
+
+	return best.Response, nil
+}
+
+// QueryPacket is like Query, but additionally returns the details of the
+// response packet.
+func (n *NTP) QueryPacket(ctx context.Context) (resp *ntp.Response, packet *Packet, err error) {
+	var best *Sample
+
+	if best, _, err = n.QueryBest(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return best.Response, NewPacket(best.Response, best.Originate), nil
+}
+
+// QueryBest queries all of the servers and returns the selected sample, along
+// with the samples of every server in the order of the servers, followed by the
+// sample of the PTP hardware clock, if any. Samples with a clock offset outside
+// of the tolerance of the median offset are marked as outliers, and the sample
+// of the PHC, or else the one with the lowest round-trip delay among the
+// others, is selected. Queries are retried until at least one server responds, or the
+// context is done, in which case the error of the context is returned. The
+// retries back off exponentially, from an immediate retry up to the min poll
+// interval, and stop after the max poll interval.
+func (n *NTP) QueryBest(ctx context.Context) (best *Sample, samples []*Sample, err error) {
+	return n.queryBest(ctx, n.queryRound)
+}
+
+// queryBest implements QueryBest, querying the sources with the query function.
+func (n *NTP) queryBest(ctx context.Context, query func(context.Context) []*Sample) (best *Sample, samples []*Sample, err error) {
+	type result struct {
+		best    *Sample
+		samples []*Sample
+		err     error
+	}
+
+	// The retries can not be interrupted, so they are left to stop at the next
+	// attempt if the context is done first.
+	ch := make(chan result, 1)
+
+	go func() {
+		var r result
+
+		r.err = retry.Exponential(n.MaxPoll, retry.WithUnits(queryRetryUnits), retry.WithCap(n.MinPoll), retry.WithJitter(250*time.Millisecond)).Retry(func() error {
+			if err := ctx.Err(); err != nil {
+				return retry.UnexpectedError(err)
+			}
 
-		if err = resp.Validate(); err != nil {
-			return retry.UnexpectedError(err)
+			r.samples = query(ctx)
+
+			var errs *multierror.Error
+
+			for _, s := range r.samples {
+				if s.Err != nil {
+					if errors.Is(s.Err, ErrResolve) {
+						log.Printf("resolve error: %s: %v", s.Server, s.Err)
+					} else {
+						log.Printf("query error: %s: %v", s.Server, s.Err)
+					}
+
+					errs = multierror.Append(errs, fmt.Errorf("%s: %w", s.Server, s.Err))
+				}
+			}
+
+			if r.best = selectBest(r.samples, n.Tolerance); r.best == nil {
+				return retry.ExpectedError(errs.ErrorOrNil())
+			}
+
+			return nil
+		})
+
+		ch <- r
+	}()
+
+	var r result
+
+	select {
+	case r = <-ch:
+	case <-ctx.Done():
+		return nil, nil, fmt.Errorf("failed to query %s: %w", strings.Join(n.servers(), ", "), ctx.Err())
+	}
+
+	if r.err != nil {
+		if ctx.Err() != nil {
+			return nil, r.samples, fmt.Errorf("failed to query %s: %w", strings.Join(n.servers(), ", "), ctx.Err())
 		}
 
-		return nil
-	})
+		return nil, r.samples, fmt.Errorf("failed to query NTP servers: %w", r.err)
+	}
+
+	return r.best, r.samples, nil
+}
+
+// QueryOnce queries all of the servers once, without retrying, and returns
+// the samples of every server in the order of the servers, along with the
+// sample selected as QueryBest does. The selected sample is nil if no server
+// responded.
+func (n *NTP) QueryOnce(ctx context.Context) (best *Sample, samples []*Sample) {
+	samples = n.queryRound(ctx)
+
+	return selectBest(samples, n.Tolerance), samples
+}
+
+// querySync queries the sources for a sync. The initial sync sends a burst of
+// queries, while the queries of the time API do not, so that they are not
+// delayed by the burst.
+func (n *NTP) querySync(ctx context.Context) []*Sample {
+	n.mu.Lock()
+	initial := n.lastSync.IsZero()
+	n.mu.Unlock()
+
+	if initial && n.IBurst > 1 {
+		return n.queryBurst(ctx, n.IBurst)
+	}
+
+	return n.queryRound(ctx)
+}
+
+// queryRound queries each of the sources once.
+func (n *NTP) queryRound(ctx context.Context) []*Sample {
+	samples := queryAll(ctx, n.servers(), n.QueryTimeout)
+
+	if n.PHC != "" {
+		samples = append(samples, n.queryPHC())
+	}
+
+	return samples
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to query NTP server: %w", err)
+// SetServers replaces the servers to query, which are validated first. The
+// next query uses the new servers, while a query in flight completes against
+// the previous ones.
+func (n *NTP) SetServers(servers []string) error {
+	if err := validateServers(servers); err != nil {
+		return err
 	}
 
-	return resp, nil
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.Servers = append([]string(nil), servers...)
+
+	return nil
+}
+
+// servers returns the servers to query. The list is replaced rather than
+// modified by SetServers, so it can be used without the mutex held.
+func (n *NTP) servers() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.Servers
 }
 
 // GetTime returns the current system time.
@@ -86,31 +300,166 @@ func (n *NTP) GetTime() time.Time {
 	return time.Now()
 }
 
-// QueryAndSetTime queries the NTP server and sets the time.
+// QueryAndSetTime queries the NTP server and sets the time. The state is
+// published to the subscribers whether the sync succeeds or not.
 func (n *NTP) QueryAndSetTime() (err error) {
-	var resp *ntp.Response
+	defer n.publish()
+
+	var best *Sample
+
+	if best, _, err = n.queryBest(context.Background(), n.querySync); err != nil {
+		n.mu.Lock()
+		n.pollAdapter().reset()
+		n.mu.Unlock()
+
+		return fmt.Errorf("error querying %s for time, %s", strings.Join(n.servers(), ", "), err)
+	}
+
+	resp := best.Response
+
+	if err = n.checkMaxStep(resp.ClockOffset, best.Server); err != nil {
+		return err
+	}
+
+	if err = n.checkSanity(resp.ClockOffset, best.Server); err != nil {
+		return err
+	}
+
+	leap := leapStatus(resp.Leap, time.Now())
+
+	// Offsets at or above the step threshold are stepped even while a leap
+	// second is announced, since the kernel clamps slews to maxSlewOffset and
+	// would leave them uncorrected until the leap second.
+	mode := n.adjustMode(resp.ClockOffset)
+
+	n.mu.Lock()
+	armed := n.leapArmed
+	n.mu.Unlock()
+
+	if err = mode.adjust(resp.ClockOffset, leap); err != nil {
+		return fmt.Errorf("failed to %s time, %s", mode, err)
+	}
+
+	// Stepping leaves the kernel status as is, so the leap second is armed,
+	// or disarmed once it is no longer announced, separately.
+	if mode == adjustStep && leap != armed {
+		if err = setLeapStatus(leap); err != nil {
+			return fmt.Errorf("failed to set the leap second status, %s", err)
+		}
+	}
+
+	if leap != armed {
+		log.Printf("leap second armed: %t, leap indicator %d from %s", leap != 0, resp.Leap, best.Server)
+	}
+
+	n.mu.Lock()
+	n.offset = resp.ClockOffset
+	n.server = best.Server
+	n.lastSync = time.Now()
+	n.maxError = MaxError(resp)
+	n.leap = resp.Leap
+	n.leapArmed = leap
+	n.rejected = false
+
+	// Stepping corrects the whole offset at once, so the clock is within the
+	// tolerance right after a step, while a slewed offset is corrected
+	// gradually.
+	var residual time.Duration
 
-	if resp, err = n.Query(); err != nil {
-		return fmt.Errorf("error querying %s for time, %s", n.Server, err)
+	if mode == adjustSlew {
+		if residual = resp.ClockOffset; residual < 0 {
+			residual = -residual
+		}
 	}
 
-	if err = adjustTime(resp.ClockOffset); err != nil {
-		return fmt.Errorf("failed to set time, %s", err)
+	n.inBounds = residual <= n.Tolerance
+
+	if n.inBounds && !n.syncedOnce {
+		n.syncedOnce = true
+
+		log.Printf("initial time sync completed")
 	}
 
+	interval := n.pollAdapter().update(resp.ClockOffset)
+	jitter := n.jitter.update(resp.ClockOffset)
+
+	// The frequency correction is only estimated by the kernel PLL, so there
+	// is nothing worth saving after stepping the clock.
+	saveDrift := n.DriftFile != "" && mode == adjustSlew && time.Since(n.driftSave) >= driftSaveInterval
+	if saveDrift {
+		n.driftSave = time.Now()
+	}
+	n.mu.Unlock()
+
+	if saveDrift {
+		if err = n.SaveDrift(n.DriftFile); err != nil {
+			log.Printf("failed to save drift file: %v", err)
+		}
+	}
+
+	n.recordFrequency()
+
+	log.Printf("clock offset %s from %s (%s), rtt %s, jitter %s, next poll in %s", resp.ClockOffset, best.Server, mode, resp.RTT, jitter, interval)
+
 	return
 }
 
-// SetTime sets the system time based on the query response.
-func setTime(adjustedTime time.Time) error {
-	log.Printf("setting time to %s", adjustedTime)
+// Ready reports whether the initial time sync has completed, and the clock
+// was within the tolerance after the most recent sync. Unlike the sync state,
+// which reports the offset measured before the clock is corrected, stepping
+// the clock makes it ready at once.
+func (n *NTP) Ready() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.syncedOnce && n.inBounds
+}
+
+// PollInterval returns the current adaptive interval between syncs.
+func (n *NTP) PollInterval() time.Duration {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.pollAdapter().interval
+}
 
-	timeval := syscall.NsecToTimeval(adjustedTime.UnixNano())
+// nextPoll returns the time to wait before the next sync: the poll interval,
+// with the random delay of the poll jitter added.
+func (n *NTP) nextPoll() time.Duration {
+	n.mu.Lock()
+	defer n.mu.Unlock()
 
-	return syscall.Settimeofday(&timeval)
+	if n.rand == nil {
+		n.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return jittered(n.pollAdapter().interval, n.PollJitter, n.rand)
 }
 
-// adjustTime adds an offset to the current time.
-func adjustTime(offset time.Duration) error {
-	return setTime(time.Now().Add(offset))
+// Jitter returns the jitter of the clock offsets measured by the recent syncs.
+func (n *NTP) Jitter() time.Duration {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.jitter.value()
+}
+
+// pollAdapter returns the poll adapter, creating it on first use so that the
+// bounds reflect the configured options. Must be called with the mutex held.
+func (n *NTP) pollAdapter() *pollAdapter {
+	if n.poll == nil {
+		n.poll = newPollAdapter(n.MinPoll, n.MaxPoll, n.Tolerance)
+	}
+
+	return n.poll
+}
+
+// SyncStatus returns the clock offset measured by the most recent successful
+// sync, and whether that offset is within the configured tolerance. No query
+// is issued. The machine is never considered synced before the first
+// successful sync.
+func (n *NTP) SyncStatus() (offset time.Duration, synced bool) {
+	state := n.State()
+
+	return state.Offset, state.Synced
 }
@@ -5,8 +5,22 @@
 package ntp
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/beevik/ntp"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
@@ -27,27 +41,1060 @@ func (suite *NtpSuite) TestQuery() {
 	n, err := NewNTPClient(WithServer(testServer))
 	suite.Assert().NoError(err)
 
-	_, err = n.Query()
+	_, err = n.Query(context.Background())
 	suite.Assert().NoError(err)
 }
 
 func (suite *NtpSuite) TestNtpConfig() {
-	server := "time.cloudflare.com"
-
 	// Test unset config, single server config, multiple server config
 	for _, conf := range []runtime.Configurator{&v1alpha1.Config{MachineConfig: &v1alpha1.MachineConfig{}}, sampleConfigSingleServer(), sampleConfigMultipleServers()} {
+		servers := []string{"time.cloudflare.com"}
+
 		// Check if ntp servers are defined
-		// Support for only a single time server currently
 		if len(conf.Machine().Time().Servers()) >= 1 {
-			server = conf.Machine().Time().Servers()[0]
+			servers = conf.Machine().Time().Servers()
 		}
 
 		n, err := NewNTPClient(
-			WithServer(server),
+			WithServers(servers...),
 		)
 		suite.Assert().NoError(err)
-		suite.Assert().Equal(server, n.Server)
+		suite.Assert().Equal(servers, n.Servers)
+	}
+
+	_, err := NewNTPClient(WithServers())
+	suite.Assert().Error(err)
+}
+
+func (suite *NtpSuite) TestQueryBest() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+
+	responses := map[string]*ntp.Response{
+		"a": {Stratum: 2, ClockOffset: 10 * time.Millisecond, RTT: 30 * time.Millisecond},
+		"b": {Stratum: 2, ClockOffset: 12 * time.Millisecond, RTT: 20 * time.Millisecond},
+		"c": {Stratum: 2, ClockOffset: 11 * time.Millisecond, RTT: 40 * time.Millisecond},
+		// The fastest server is far off from the others.
+		"d": {Stratum: 2, ClockOffset: 5 * time.Second, RTT: 5 * time.Millisecond},
+	}
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		if resp, ok := responses[server]; ok {
+			return resp, time.Now(), nil
+		}
+
+		return nil, time.Time{}, fmt.Errorf("no response")
+	}
+
+	n, err := NewNTPClient(WithServers("a", "b", "c", "d", "e"), WithTolerance(time.Second))
+	suite.Require().NoError(err)
+
+	best, samples, err := n.QueryBest(context.Background())
+	suite.Require().NoError(err)
+	suite.Assert().Equal("b", best.Server)
+	suite.Require().Len(samples, 5)
+
+	for i, server := range []string{"a", "b", "c", "d", "e"} {
+		suite.Assert().Equal(server, samples[i].Server)
+		suite.Assert().Equal(server == "d", samples[i].Outlier)
+		suite.Assert().Equal(server == "e", samples[i].Err != nil)
+	}
+}
+
+func (suite *NtpSuite) TestQueryOnce() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		if server == "a" {
+			return &ntp.Response{Stratum: 2, ClockOffset: 10 * time.Millisecond}, time.Now(), nil
+		}
+
+		return nil, time.Time{}, fmt.Errorf("no response")
+	}
+
+	n, err := NewNTPClient(WithServers("a", "b"))
+	suite.Require().NoError(err)
+
+	best, samples := n.QueryOnce(context.Background())
+	suite.Require().NotNil(best)
+	suite.Assert().Equal("a", best.Server)
+	suite.Require().Len(samples, 2)
+	suite.Assert().Error(samples[1].Err)
+
+	// Unreachable servers are not retried.
+	n, err = NewNTPClient(WithServers("b", "c"))
+	suite.Require().NoError(err)
+
+	best, samples = n.QueryOnce(context.Background())
+	suite.Assert().Nil(best)
+	suite.Assert().Len(samples, 2)
+}
+
+func (suite *NtpSuite) TestQueryOriginate() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+
+	// The sample reports the time the request was sent at, not the one the
+	// query started at, which is before the name of the server is resolved.
+	originate := time.Now().Add(time.Second)
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		return &ntp.Response{Stratum: 2, ClockOffset: 10 * time.Millisecond}, originate, nil
+	}
+
+	n, err := NewNTPClient(WithServers("a"))
+	suite.Require().NoError(err)
+
+	best, _ := n.QueryOnce(context.Background())
+	suite.Require().NotNil(best)
+	suite.Assert().Equal(originate, best.Originate)
+}
+
+func (suite *NtpSuite) TestIBurst() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+	defer func(f func(*syscall.Timex) (int, error)) { adjtimex = f }(adjtimex)
+	defer func(f func(*syscall.Timeval) error) { settimeofday = f }(settimeofday)
+	defer func(d time.Duration) { iburstSpacing = d }(iburstSpacing)
+
+	adjtimex = func(*syscall.Timex) (int, error) { return 0, nil }
+	settimeofday = func(*syscall.Timeval) error { return nil }
+
+	iburstSpacing = 20 * time.Millisecond
+
+	var (
+		mu    sync.Mutex
+		sent  = map[string][]time.Time{}
+		calls int
+	)
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		sent[server] = append(sent[server], time.Now())
+		calls++
+
+		// The first query of "b" is lost.
+		if server == "b" && len(sent[server]) == 1 {
+			return nil, time.Time{}, fmt.Errorf("no response")
+		}
+
+		count := time.Duration(len(sent[server]))
+
+		return &ntp.Response{Stratum: 2, ClockOffset: count * 10 * time.Millisecond, RTT: count * time.Millisecond}, time.Now(), nil
+	}
+
+	n, err := NewNTPClient(WithServers("a", "b"), WithIBurst(3))
+	suite.Require().NoError(err)
+
+	// The queries of the time API do not burst, even before the initial sync.
+	_, _, err = n.QueryBest(context.Background())
+	suite.Require().NoError(err)
+
+	n.QueryOnce(context.Background())
+	suite.Assert().Equal(4, calls)
+
+	calls = 0
+	sent = map[string][]time.Time{}
+
+	_, samples, err := n.queryBest(context.Background(), n.querySync)
+	suite.Require().NoError(err)
+	suite.Require().Len(samples, 2)
+
+	// The offsets and delays of the good samples are averaged.
+	suite.Assert().Equal(20*time.Millisecond, samples[0].Response.ClockOffset)
+	suite.Assert().Equal(2*time.Millisecond, samples[0].Response.RTT)
+	suite.Assert().NoError(samples[1].Err)
+	suite.Assert().Equal(25*time.Millisecond, samples[1].Response.ClockOffset)
+
+	for _, server := range []string{"a", "b"} {
+		suite.Require().Len(sent[server], 3)
+
+		for i := 1; i < len(sent[server]); i++ {
+			suite.Assert().True(sent[server][i].Sub(sent[server][i-1]) >= iburstSpacing, "queries of %s %s apart", server, sent[server][i].Sub(sent[server][i-1]))
+		}
+	}
+
+	// Once synced, each server is queried once per poll.
+	suite.Require().NoError(n.QueryAndSetTime())
+
+	calls = 0
+
+	suite.Require().NoError(n.QueryAndSetTime())
+	suite.Assert().Equal(2, calls)
+
+	// A burst stops after a round no source responded to.
+	n, err = NewNTPClient(WithServers("c"), WithIBurst(3))
+	suite.Require().NoError(err)
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+
+		return nil, time.Time{}, fmt.Errorf("no response")
+	}
+
+	calls = 0
+
+	suite.Assert().Len(n.querySync(context.Background()), 1)
+	suite.Assert().Equal(1, calls)
+
+	_, err = NewNTPClient(WithIBurst(maxIBurst + 1))
+	suite.Assert().Error(err)
+
+	_, err = NewNTPClient(WithIBurst(-1))
+	suite.Assert().Error(err)
+}
+
+func (suite *NtpSuite) TestQueryCanceled() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		<-release
+
+		return nil, time.Time{}, fmt.Errorf("no response")
+	}
+
+	n, err := NewNTPClient(WithServer("a"), WithQueryTimeout(10*time.Millisecond))
+	suite.Require().NoError(err)
+
+	// A server that does not respond within the query timeout fails the
+	// sample.
+	best, samples := n.QueryOnce(context.Background())
+	suite.Assert().Nil(best)
+	suite.Require().Len(samples, 1)
+	suite.Assert().True(errors.Is(samples[0].Err, context.DeadlineExceeded), "error = %v", samples[0].Err)
+
+	// The retries stop once the context of the caller is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, _, err = n.QueryBest(ctx)
+	suite.Assert().True(errors.Is(err, context.DeadlineExceeded), "error = %v", err)
+	suite.Assert().Contains(err.Error(), "query a")
+	suite.Assert().Less(int64(time.Since(start)), int64(time.Second))
+
+	_, err = NewNTPClient(WithQueryTimeout(0))
+	suite.Assert().Error(err)
+}
+
+func (suite *NtpSuite) TestQueryResolveError() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		if server == "a" {
+			return nil, time.Time{}, &net.OpError{Op: "dial", Net: "udp", Err: &net.DNSError{Err: "no such host", Name: server, IsNotFound: true}}
+		}
+
+		return nil, time.Time{}, fmt.Errorf("no response")
+	}
+
+	n, err := NewNTPClient(WithServers("a", "b"))
+	suite.Require().NoError(err)
+
+	_, samples := n.QueryOnce(context.Background())
+	suite.Require().Len(samples, 2)
+
+	var dnsErr *net.DNSError
+
+	suite.Assert().True(errors.Is(samples[0].Err, ErrResolve), "error = %v", samples[0].Err)
+	suite.Assert().True(errors.As(samples[0].Err, &dnsErr), "error = %v", samples[0].Err)
+	suite.Assert().False(errors.Is(samples[1].Err, ErrResolve), "error = %v", samples[1].Err)
+}
+
+func (suite *NtpSuite) TestQueryBackoff() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+
+	var attempts int32
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return nil, time.Time{}, &net.DNSError{Err: "no such host", Name: server, IsNotFound: true}
+		}
+
+		return &ntp.Response{Stratum: 2, ClockOffset: time.Millisecond}, time.Now(), nil
+	}
+
+	n, err := NewNTPClient(WithServer("a"), WithMinPoll(16))
+	suite.Require().NoError(err)
+
+	// The first retry is immediate, rather than a poll interval later.
+	start := time.Now()
+
+	best, _, err := n.QueryBest(context.Background())
+	suite.Require().NoError(err)
+	suite.Assert().Equal("a", best.Server)
+	suite.Assert().EqualValues(2, atomic.LoadInt32(&attempts))
+	suite.Assert().Less(int64(time.Since(start)), int64(time.Second))
+}
+
+func (suite *NtpSuite) TestPHC() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+	defer func(f func(string) (time.Time, time.Duration, time.Duration, error)) { readPHC = f }(readPHC)
+
+	var serverErr, phcErr error
+
+	phcOffset := 5 * time.Millisecond
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		return &ntp.Response{Stratum: 2, ClockOffset: 10 * time.Millisecond, RTT: time.Millisecond}, time.Now(), serverErr
+	}
+
+	readPHC = func(device string) (time.Time, time.Duration, time.Duration, error) {
+		return time.Now(), phcOffset, time.Microsecond, phcErr
+	}
+
+	n, err := NewNTPClient(WithServer("a"), WithPHC("/dev/ptp0"), WithTolerance(100*time.Millisecond))
+	suite.Require().NoError(err)
+
+	// The PHC is preferred while it agrees with the servers.
+	best, samples := n.QueryOnce(context.Background())
+	suite.Require().Len(samples, 2)
+	suite.Require().NotNil(best)
+	suite.Assert().Equal(SourcePHC, best.Source)
+	suite.Assert().Equal("/dev/ptp0", best.Server)
+	suite.Assert().Equal(phcOffset, best.Response.ClockOffset)
+
+	// A PHC which disagrees with the servers is discarded.
+	phcOffset = time.Hour
+
+	best, samples = n.QueryOnce(context.Background())
+	suite.Require().NotNil(best)
+	suite.Assert().Equal(SourceNTP, best.Source)
+	suite.Assert().True(samples[1].Outlier)
+
+	// Without the servers, the PHC is used on its own.
+	serverErr = fmt.Errorf("no response")
+
+	best, _ = n.QueryOnce(context.Background())
+	suite.Require().NotNil(best)
+	suite.Assert().Equal(SourcePHC, best.Source)
+
+	// An unreadable PHC falls back to the servers.
+	serverErr = nil
+	phcErr = fmt.Errorf("no such device")
+
+	best, samples = n.QueryOnce(context.Background())
+	suite.Require().NotNil(best)
+	suite.Assert().Equal(SourceNTP, best.Source)
+	suite.Assert().Error(samples[1].Err)
+
+	_, err = NewNTPClient(WithPHC(""))
+	suite.Assert().Error(err)
+}
+
+func (suite *NtpSuite) TestKernelTAIOffset() {
+	defer func(f func(*syscall.Timex) (int, error)) { adjtimex = f }(adjtimex)
+
+	var tai int32
+
+	adjtimex = func(buf *syscall.Timex) (int, error) {
+		suite.Assert().Zero(buf.Modes)
+
+		buf.Tai = tai
+
+		return 0, nil
+	}
+
+	suite.Assert().Equal(taiOffset, kernelTAIOffset())
+
+	tai = 38
+	suite.Assert().Equal(38*time.Second, kernelTAIOffset())
+
+	adjtimex = func(buf *syscall.Timex) (int, error) {
+		return 0, syscall.EPERM
+	}
+
+	suite.Assert().Equal(taiOffset, kernelTAIOffset())
+}
+
+func (suite *NtpSuite) TestPHCAbsent() {
+	_, _, _, err := phcOffset(filepath.Join(os.TempDir(), "ptp-absent"))
+	suite.Assert().True(os.IsNotExist(err), "error = %v", err)
+}
+
+func (suite *NtpSuite) TestSetServers() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		if server == "a" {
+			close(started)
+			<-release
+		}
+
+		return &ntp.Response{Stratum: 2, ClockOffset: 10 * time.Millisecond}, time.Now(), nil
+	}
+
+	n, err := NewNTPClient(WithServer("a"))
+	suite.Require().NoError(err)
+
+	ch := make(chan *Sample, 1)
+
+	go func() {
+		best, _ := n.QueryOnce(context.Background())
+
+		ch <- best
+	}()
+
+	<-started
+
+	suite.Require().NoError(n.SetServers([]string{"b", "[2001:db8::1]:1123"}))
+
+	close(release)
+
+	// The query in flight completes against the previous server.
+	inFlight := <-ch
+	suite.Require().NotNil(inFlight)
+	suite.Assert().Equal("a", inFlight.Server)
+
+	best, samples := n.QueryOnce(context.Background())
+	suite.Require().NotNil(best)
+	suite.Assert().Equal("b", best.Server)
+	suite.Assert().Len(samples, 2)
+
+	suite.Assert().Error(n.SetServers(nil))
+	suite.Assert().Error(n.SetServers([]string{"[2001:db8::1"}))
+	suite.Assert().Equal([]string{"b", "[2001:db8::1]:1123"}, n.servers())
+}
+
+func (suite *NtpSuite) TestSelectBest() {
+	suite.Assert().Nil(selectBest([]*Sample{{Server: "a", Err: fmt.Errorf("timeout")}}, time.Second))
+
+	// With two samples the lower median is used, so the sample with the lower
+	// offset is always kept.
+	samples := []*Sample{
+		{Server: "a", Response: &ntp.Response{ClockOffset: 3 * time.Second, RTT: time.Millisecond}},
+		{Server: "b", Response: &ntp.Response{ClockOffset: 0, RTT: time.Second}},
+	}
+
+	best := selectBest(samples, time.Second)
+	suite.Require().NotNil(best)
+	suite.Assert().Equal("b", best.Server)
+	suite.Assert().True(samples[0].Outlier)
+}
+
+func (suite *NtpSuite) TestSyncStatus() {
+	n, err := NewNTPClient(WithTolerance(time.Second))
+	suite.Assert().NoError(err)
+
+	_, synced := n.SyncStatus()
+	suite.Assert().False(synced)
+
+	n.lastSync = time.Now()
+
+	for _, offset := range []time.Duration{-time.Second, 0, 500 * time.Millisecond} {
+		n.offset = offset
+
+		_, synced = n.SyncStatus()
+		suite.Assert().True(synced)
+	}
+
+	n.offset = -2 * time.Second
+
+	_, synced = n.SyncStatus()
+	suite.Assert().False(synced)
+}
+
+func (suite *NtpSuite) TestReady() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+	defer func(f func(*syscall.Timex) (int, error)) { adjtimex = f }(adjtimex)
+	defer func(f func(*syscall.Timeval) error) { settimeofday = f }(settimeofday)
+
+	adjtimex = func(*syscall.Timex) (int, error) { return 0, nil }
+	settimeofday = func(*syscall.Timeval) error { return nil }
+
+	var offset time.Duration
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		return &ntp.Response{Stratum: 2, ClockOffset: offset}, time.Now(), nil
+	}
+
+	n, err := NewNTPClient(WithServer("a"), WithTolerance(100*time.Millisecond), WithStepThreshold(400*time.Millisecond))
+	suite.Require().NoError(err)
+
+	suite.Assert().False(n.Ready())
+
+	// The clock is stepped, so it is ready even though the measured offset is
+	// outside of the tolerance.
+	offset = 5 * time.Second
+
+	suite.Require().NoError(n.QueryAndSetTime())
+	suite.Assert().True(n.Ready())
+	suite.Assert().False(n.State().Synced)
+
+	// A slewed offset outside of the tolerance is not corrected yet.
+	offset = 300 * time.Millisecond
+
+	suite.Require().NoError(n.QueryAndSetTime())
+	suite.Assert().False(n.Ready())
+
+	offset = 10 * time.Millisecond
+
+	suite.Require().NoError(n.QueryAndSetTime())
+	suite.Assert().True(n.Ready())
+}
+
+func (suite *NtpSuite) TestSubscribe() {
+	n, err := NewNTPClient(WithTolerance(time.Second))
+	suite.Require().NoError(err)
+
+	states, cancel := n.Subscribe()
+
+	n.publish()
+	suite.Assert().Equal(SyncState{}, <-states)
+
+	n.offset = 10 * time.Millisecond
+	n.server = "a"
+	n.lastSync = time.Now()
+
+	n.publish()
+
+	state := <-states
+	suite.Assert().True(state.Synced)
+	suite.Assert().Equal(10*time.Millisecond, state.Offset)
+	suite.Assert().Equal("a", state.Server)
+	suite.Assert().Equal(n.lastSync, state.LastSync)
+
+	cancel()
+	cancel()
+
+	_, ok := <-states
+	suite.Assert().False(ok)
+
+	// Publishing without subscribers, or to a full buffer, never blocks.
+	n.publish()
+
+	states, cancel = n.Subscribe()
+	defer cancel()
+
+	for i := 0; i < stateBufferSize+1; i++ {
+		n.publish()
+	}
+
+	suite.Assert().Len(states, stateBufferSize)
+}
+
+func (suite *NtpSuite) TestPollAdapter() {
+	const (
+		min       = 64 * time.Second
+		max       = 1024 * time.Second
+		threshold = 100 * time.Millisecond
+	)
+
+	stable := []time.Duration{time.Millisecond, -2 * time.Millisecond, 0, 3 * time.Millisecond}
+
+	p := newPollAdapter(min, max, threshold)
+	suite.Assert().Equal(min, p.interval)
+
+	// The interval doubles after each run of stable offsets, up to max.
+	for _, want := range []time.Duration{128 * time.Second, 256 * time.Second, 512 * time.Second, max, max} {
+		var got time.Duration
+
+		for _, offset := range stable {
+			got = p.update(offset)
+		}
+
+		suite.Assert().Equal(want, got)
+	}
+
+	// An unstable offset halves the interval immediately, and restarts the
+	// stable run.
+	suite.Assert().Equal(512*time.Second, p.update(-time.Second))
+	suite.Assert().Equal(512*time.Second, p.update(time.Millisecond))
+	suite.Assert().Equal(256*time.Second, p.update(time.Second))
+
+	// The interval never drops below min.
+	for i := 0; i < 10; i++ {
+		p.update(time.Second)
+	}
+
+	suite.Assert().Equal(min, p.interval)
+
+	// Alternating offsets never build a stable run.
+	for i := 0; i < 10; i++ {
+		p.update(time.Millisecond)
+		p.update(time.Second)
+	}
+
+	suite.Assert().Equal(min, p.interval)
+}
+
+func (suite *NtpSuite) TestPollInterval() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+	defer func(f func(*syscall.Timex) (int, error)) { adjtimex = f }(adjtimex)
+
+	adjtimex = func(*syscall.Timex) (int, error) { return 0, nil }
+
+	fail := false
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		if fail {
+			return nil, time.Time{}, fmt.Errorf("no response")
+		}
+
+		return &ntp.Response{Stratum: 2, ClockOffset: time.Millisecond}, time.Now(), nil
+	}
+
+	n, err := NewNTPClient(WithServer("a"), WithMinPoll(4), WithMaxPoll(16), WithTolerance(100*time.Millisecond))
+	suite.Require().NoError(err)
+
+	suite.Assert().Equal(4*time.Second, n.PollInterval())
+
+	// The interval grows after each run of successful syncs, up to max.
+	for _, want := range []time.Duration{8 * time.Second, 16 * time.Second, 16 * time.Second} {
+		for i := 0; i < pollStableSamples; i++ {
+			suite.Require().NoError(n.QueryAndSetTime())
+		}
+
+		suite.Assert().Equal(want, n.PollInterval())
+	}
+
+	// A failed sync returns to polling at min, with the retries of a query
+	// bounded by max.
+	fail = true
+
+	n.MaxPoll = time.Millisecond
+
+	suite.Assert().Error(n.QueryAndSetTime())
+	suite.Assert().Equal(4*time.Second, n.PollInterval())
+}
+
+func (suite *NtpSuite) TestPollOptions() {
+	_, err := NewNTPClient(WithMinPoll(64), WithMaxPoll(32))
+	suite.Assert().Error(err)
+
+	// An invalid option is reported even if later options are valid.
+	_, err = NewNTPClient(WithMaxPoll(2048), WithServer("a"))
+	suite.Assert().Error(err)
+
+	_, err = NewNTPClient(WithPollJitter(1))
+	suite.Assert().Error(err)
+}
+
+func (suite *NtpSuite) TestJittered() {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		got := jittered(time.Minute, 0.1, r)
+
+		suite.Assert().True(got >= time.Minute && got < time.Minute+6*time.Second, "jittered interval %s out of range", got)
+	}
+
+	suite.Assert().Equal(time.Minute, jittered(time.Minute, 0, r))
+}
+
+func (suite *NtpSuite) TestJitterEstimator() {
+	var j jitterEstimator
+
+	suite.Assert().Equal(time.Duration(0), j.update(time.Second))
+
+	// A constant offset has no jitter.
+	suite.Assert().Equal(time.Duration(0), j.update(time.Second))
+
+	// The differences are 0, 3ms and -3ms.
+	j.update(time.Second + 3*time.Millisecond)
+	suite.Assert().Equal(time.Duration(math.Sqrt(float64(2*9*time.Millisecond*time.Millisecond)/3)), j.update(time.Second))
+
+	// Only the most recent offsets are kept.
+	for i := 0; i < jitterSamples; i++ {
+		j.update(0)
+	}
+
+	suite.Assert().Len(j.offsets, jitterSamples)
+	suite.Assert().Equal(time.Duration(0), j.value())
+}
+
+func (suite *NtpSuite) TestAdjustMode() {
+	n, err := NewNTPClient(WithStepThreshold(128 * time.Millisecond))
+	suite.Require().NoError(err)
+
+	for offset, want := range map[time.Duration]adjustMode{
+		0:                       adjustSlew,
+		100 * time.Millisecond:  adjustSlew,
+		-100 * time.Millisecond: adjustSlew,
+		128 * time.Millisecond:  adjustStep,
+		-time.Second:            adjustStep,
+		time.Hour:               adjustStep,
+	} {
+		suite.Assert().Equal(want, n.adjustMode(offset), "offset %s", offset)
+	}
+
+	// A zero threshold always steps.
+	n, err = NewNTPClient(WithStepThreshold(0))
+	suite.Require().NoError(err)
+	suite.Assert().Equal(adjustStep, n.adjustMode(0))
+
+	_, err = NewNTPClient(WithStepThreshold(time.Second))
+	suite.Assert().Error(err)
+}
+
+func (suite *NtpSuite) TestAdjust() {
+	defer func(f func(*syscall.Timex) (int, error)) { adjtimex = f }(adjtimex)
+	defer func(f func(*syscall.Timeval) error) { settimeofday = f }(settimeofday)
+
+	var (
+		timex   *syscall.Timex
+		timeval *syscall.Timeval
+	)
+
+	adjtimex = func(buf *syscall.Timex) (int, error) {
+		timex = buf
+
+		return 0, nil
+	}
+
+	settimeofday = func(tv *syscall.Timeval) error {
+		timeval = tv
+
+		return nil
+	}
+
+	suite.Require().NoError(adjustSlew.adjust(-50*time.Millisecond, 0))
+	suite.Require().NotNil(timex)
+	suite.Assert().Nil(timeval)
+	suite.Assert().EqualValues(adjOffset|adjStatus|adjNano, timex.Modes)
+	suite.Assert().EqualValues(-50*time.Millisecond, timex.Offset)
+	suite.Assert().EqualValues(staPLL, timex.Status)
+
+	timex = nil
+
+	suite.Require().NoError(adjustStep.adjust(time.Hour, 0))
+	suite.Assert().Nil(timex)
+	suite.Require().NotNil(timeval)
+	suite.Assert().WithinDuration(time.Now().Add(time.Hour), time.Unix(timeval.Unix()), time.Minute)
+}
+
+func (suite *NtpSuite) TestLeapStatus() {
+	for _, tt := range []struct {
+		leap ntp.LeapIndicator
+		now  time.Time
+		want int32
+	}{
+		{ntp.LeapAddSecond, time.Date(2016, time.December, 31, 12, 0, 0, 0, time.UTC), staIns},
+		{ntp.LeapAddSecond, time.Date(2016, time.December, 30, 23, 59, 59, 0, time.UTC), 0},
+		{ntp.LeapDelSecond, time.Date(2016, time.June, 30, 0, 0, 0, 0, time.UTC), staDel},
+		{ntp.LeapNoWarning, time.Date(2016, time.June, 30, 0, 0, 0, 0, time.UTC), 0},
+		{ntp.LeapNotInSync, time.Date(2016, time.June, 30, 0, 0, 0, 0, time.UTC), 0},
+		// The last day of the month is the UTC one.
+		{ntp.LeapAddSecond, time.Date(2017, time.January, 1, 0, 30, 0, 0, time.FixedZone("CET", 3600)), staIns},
+	} {
+		suite.Assert().Equal(tt.want, leapStatus(tt.leap, tt.now), "%d at %s", tt.leap, tt.now)
+	}
+}
+
+func (suite *NtpSuite) TestLeap() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+	defer func(f func(*syscall.Timex) (int, error)) { adjtimex = f }(adjtimex)
+	defer func(f func(*syscall.Timeval) error) { settimeofday = f }(settimeofday)
+
+	var statuses []int32
+
+	adjtimex = func(buf *syscall.Timex) (int, error) {
+		if buf.Modes&adjStatus != 0 {
+			statuses = append(statuses, buf.Status)
+		}
+
+		return 0, nil
+	}
+
+	settimeofday = func(*syscall.Timeval) error { return nil }
+
+	resp := &ntp.Response{Stratum: 2, ClockOffset: 10 * time.Millisecond, Leap: ntp.LeapAddSecond}
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		return resp, time.Now(), nil
+	}
+
+	n, err := NewNTPClient(WithServer("a"), WithStepThreshold(100*time.Millisecond))
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(n.QueryAndSetTime())
+	suite.Assert().Equal(ntp.LeapAddSecond, n.Leap())
+	suite.Require().Len(statuses, 1)
+	suite.Assert().Equal(staPLL|leapStatus(ntp.LeapAddSecond, time.Now()), statuses[0])
+
+	// Offsets at or above the step threshold are stepped while a leap second
+	// is announced, and the leap second is armed separately.
+	n.leapArmed = 0
+	resp = &ntp.Response{Stratum: 2, ClockOffset: time.Second, Leap: ntp.LeapAddSecond}
+	statuses = nil
+
+	var stepped bool
+
+	settimeofday = func(*syscall.Timeval) error {
+		stepped = true
+
+		return nil
 	}
+
+	suite.Require().NoError(n.QueryAndSetTime())
+	suite.Assert().True(stepped)
+
+	if leap := leapStatus(ntp.LeapAddSecond, time.Now()); leap != 0 {
+		suite.Assert().Equal([]int32{staPLL | leap}, statuses)
+	} else {
+		suite.Assert().Empty(statuses)
+	}
+
+	// An armed leap second is disarmed once it is no longer announced, even
+	// if the clock is stepped.
+	n.leapArmed = staIns
+	resp = &ntp.Response{Stratum: 2, ClockOffset: time.Second}
+	statuses = nil
+
+	suite.Require().NoError(n.QueryAndSetTime())
+	suite.Assert().Equal(ntp.LeapNoWarning, n.Leap())
+	suite.Assert().Equal([]int32{staPLL}, statuses)
+}
+
+func (suite *NtpSuite) TestMaxStep() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+	defer func(f func(*syscall.Timex) (int, error)) { adjtimex = f }(adjtimex)
+	defer func(f func(*syscall.Timeval) error) { settimeofday = f }(settimeofday)
+
+	var steps int
+
+	adjtimex = func(*syscall.Timex) (int, error) { return 0, nil }
+	settimeofday = func(*syscall.Timeval) error {
+		steps++
+
+		return nil
+	}
+
+	var offset time.Duration
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		return &ntp.Response{Stratum: 2, ClockOffset: offset}, time.Now(), nil
+	}
+
+	n, err := NewNTPClient(WithServer("a"), WithMaxStep(time.Minute))
+	suite.Require().NoError(err)
+
+	// The initial sync may step the clock by any offset.
+	offset = 24 * time.Hour
+
+	suite.Require().NoError(n.QueryAndSetTime())
+	suite.Assert().Equal(1, steps)
+	suite.Assert().True(n.Ready())
+
+	offset = -2 * time.Hour
+
+	err = n.QueryAndSetTime()
+	suite.Assert().True(errors.Is(err, ErrMaxStep), "error = %v", err)
+	suite.Assert().Equal(1, steps)
+	suite.Assert().False(n.Ready())
+	suite.Assert().False(n.State().Synced)
+
+	offset = 10 * time.Millisecond
+
+	suite.Require().NoError(n.QueryAndSetTime())
+	suite.Assert().True(n.Ready())
+	suite.Assert().True(n.State().Synced)
+
+	suite.Assert().True(n.ExceedsMaxStep(-2 * time.Minute))
+	suite.Assert().False(n.ExceedsMaxStep(time.Minute))
+
+	n, err = NewNTPClient(WithMaxStep(0))
+	suite.Require().NoError(err)
+	suite.Assert().False(n.ExceedsMaxStep(24 * time.Hour))
+
+	_, err = NewNTPClient(WithMaxStep(-time.Minute))
+	suite.Assert().Error(err)
+
+	_, err = NewNTPClient(WithMaxStep(10 * time.Millisecond))
+	suite.Assert().Error(err)
+}
+
+func (suite *NtpSuite) TestDrift() {
+	defer func(f func(*syscall.Timex) (int, error)) { adjtimex = f }(adjtimex)
+
+	var freq int64
+
+	adjtimex = func(buf *syscall.Timex) (int, error) {
+		if buf.Modes&adjFrequency != 0 {
+			freq = buf.Freq
+		}
+
+		buf.Freq = freq
+
+		return 0, nil
+	}
+
+	dir, err := ioutil.TempDir("", "ntp")
+	suite.Require().NoError(err)
+
+	// nolint: errcheck
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "lib", "ntp.drift")
+
+	n, err := NewNTPClient(WithDriftFile(path))
+	suite.Require().NoError(err)
+
+	// A missing file starts from zero.
+	suite.Require().NoError(n.LoadDrift(path))
+	suite.Assert().Zero(freq)
+
+	freq = -12*freqScale - freqScale/4
+
+	suite.Require().NoError(n.SaveDrift(path))
+
+	b, err := ioutil.ReadFile(path)
+	suite.Require().NoError(err)
+	suite.Assert().Equal("-12.250\n", string(b))
+
+	freq = 0
+
+	suite.Require().NoError(n.LoadDrift(path))
+	suite.Assert().EqualValues(-12*freqScale-freqScale/4, freq)
+
+	// A corrupt file leaves the frequency untouched.
+	for _, contents := range []string{"", "garbage", "NaN", "501"} {
+		suite.Require().NoError(ioutil.WriteFile(path, []byte(contents), 0600))
+
+		freq = 0
+
+		suite.Assert().Error(n.LoadDrift(path))
+		suite.Assert().Zero(freq)
+	}
+}
+
+func (suite *NtpSuite) TestFrequencyTracker() {
+	var f frequencyTracker
+
+	_, ok := f.value(100)
+	suite.Assert().False(ok)
+
+	start := time.Now()
+
+	f.update(start, 20)
+	f.update(start.Add(time.Hour), 35)
+
+	drift, ok := f.value(100)
+	suite.Require().True(ok)
+	suite.Assert().Equal(Drift{PPM: 35, Change: 15, Window: time.Hour}, drift)
+
+	drift, _ = f.value(30)
+	suite.Assert().True(drift.Warning)
+
+	// The warning applies to the magnitude of the correction.
+	f.update(start.Add(2*time.Hour), -120)
+
+	drift, _ = f.value(100)
+	suite.Assert().True(drift.Warning)
+
+	drift, _ = f.value(0)
+	suite.Assert().False(drift.Warning)
+
+	// The corrections older than the window are dropped, but the latest is
+	// always kept.
+	f.update(start.Add(2*time.Hour+frequencyWindow), -110)
+
+	drift, _ = f.value(100)
+	suite.Assert().Equal(Drift{PPM: -110, Change: 10, Window: frequencyWindow, Warning: true}, drift)
+
+	f.update(start.Add(5*frequencyWindow), -100)
+
+	drift, _ = f.value(100)
+	suite.Assert().Equal(Drift{PPM: -100}, drift)
+
+	// The number of corrections is bounded within the window.
+	for i := 0; i < 2*frequencySamples; i++ {
+		f.update(start.Add(5*frequencyWindow+time.Duration(i)*time.Second), float64(i))
+	}
+
+	suite.Assert().Len(f.samples, frequencySamples)
+}
+
+func (suite *NtpSuite) TestRecordFrequency() {
+	defer func(f func(*syscall.Timex) (int, error)) { adjtimex = f }(adjtimex)
+
+	freq := int64(12 * freqScale)
+
+	adjtimex = func(buf *syscall.Timex) (int, error) {
+		buf.Freq = freq
+
+		return 0, nil
+	}
+
+	n, err := NewNTPClient(WithDriftWarningThreshold(50))
+	suite.Require().NoError(err)
+
+	_, ok := n.Drift()
+	suite.Assert().False(ok)
+
+	n.recordFrequency()
+
+	freq = -60 * freqScale
+
+	n.recordFrequency()
+
+	drift, ok := n.Drift()
+	suite.Require().True(ok)
+	suite.Assert().Equal(-60.0, drift.PPM)
+	suite.Assert().Equal(-72.0, drift.Change)
+	suite.Assert().True(drift.Warning)
+
+	for _, threshold := range []float64{-1, maxFrequency + 1} {
+		_, err = NewNTPClient(WithDriftWarningThreshold(threshold))
+		suite.Assert().Error(err)
+	}
+}
+
+func (suite *NtpSuite) TestNewPacket() {
+	originate := time.Date(2020, time.April, 1, 12, 0, 0, 0, time.UTC)
+
+	// The server clock is 1s ahead, the request and response each take 10ms,
+	// and the server spends 5ms processing the request.
+	resp := &ntp.Response{
+		Time:        originate.Add(time.Second + 15*time.Millisecond),
+		ClockOffset: time.Second,
+		RTT:         20 * time.Millisecond,
+	}
+
+	packet := NewPacket(resp, originate)
+	suite.Assert().Equal(originate, packet.Originate)
+	suite.Assert().Equal(originate.Add(time.Second+10*time.Millisecond), packet.Receive)
+	suite.Assert().Equal(resp.Time, packet.Transmit)
+}
+
+func (suite *NtpSuite) TestMaxError() {
+	resp := &ntp.Response{
+		RTT:            20 * time.Millisecond,
+		RootDelay:      30 * time.Millisecond,
+		RootDispersion: 5 * time.Millisecond,
+		Precision:      time.Microsecond,
+	}
+
+	// The clocks drift by up to 15 PPM over the round trip, 300ns.
+	suite.Assert().Equal(5*time.Millisecond+time.Microsecond+300*time.Nanosecond, RootDispersion(resp))
+	suite.Assert().Equal(15*time.Millisecond+10*time.Millisecond+RootDispersion(resp), MaxError(resp))
+
+	// A reference clock, such as a PHC, only has the error of its reading.
+	suite.Assert().Equal(time.Microsecond, MaxError(&ntp.Response{RTT: 2 * time.Microsecond}))
+}
+
+func (suite *NtpSuite) TestStateMaxError() {
+	n, err := NewNTPClient(WithTolerance(time.Second))
+	suite.Require().NoError(err)
+
+	suite.Assert().Zero(n.State().MaxError)
+
+	n.lastSync = time.Now().Add(-time.Hour)
+	n.maxError = 10 * time.Millisecond
+
+	// The clock drifts by up to 54ms an hour after the sync.
+	maxError := n.State().MaxError
+	suite.Assert().True(maxError >= 64*time.Millisecond, "max error %s", maxError)
+	suite.Assert().True(maxError < 65*time.Millisecond, "max error %s", maxError)
 }
 
 func sampleConfigSingleServer() runtime.Configurator {
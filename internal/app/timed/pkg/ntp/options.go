@@ -6,7 +6,10 @@ package ntp
 
 import (
 	"fmt"
+	"net/url"
 	"time"
+
+	"github.com/talos-systems/talos/pkg/constants"
 )
 
 // Option allows for the configuration of the ntp client
@@ -23,20 +26,70 @@ func defaultOptions() *NTP {
 	// defaults for minpoll + maxpoll
 	// http://www.ntp.org/ntpfaq/NTP-s-algo.htm#AEN2082
 	return &NTP{
-		Server:  "pool.ntp.org",
-		MaxPoll: MaxAllowablePoll * time.Second,
-		MinPoll: 64 * time.Second,
+		Servers:               []string{"pool.ntp.org"},
+		MaxPoll:               constants.DefaultTimeMaxPoll,
+		MinPoll:               constants.DefaultTimeMinPoll,
+		Tolerance:             constants.DefaultTimeSyncTolerance,
+		StepThreshold:         constants.DefaultTimeStepThreshold,
+		MaxStep:               constants.DefaultTimeMaxStep,
+		QueryTimeout:          defaultQueryTimeout,
+		PollJitter:            defaultPollJitter,
+		DriftWarningThreshold: constants.DefaultTimeDriftWarningThreshold,
 	}
 }
 
-// WithServer configures the ntp client to use the specified server
+// defaultQueryTimeout is the default time given to each server to respond to
+// a query.
+const defaultQueryTimeout = 5 * time.Second
+
+// defaultPollJitter is the default fraction of the poll interval up to which
+// a random delay is added.
+const defaultPollJitter = 0.1
+
+// WithServer configures the ntp client to use the specified server, given as
+// host, host:port or [IPv6]:port, with the port defaulting to 123
 func WithServer(o string) Option {
 	return func(n *NTP) (err error) {
-		n.Server = o
+		if _, _, err = parseServer(o, ntpPort); err != nil {
+			return err
+		}
+
+		n.Servers = []string{o}
+
+		return err
+	}
+}
+
+// WithServers configures the ntp client to query all of the specified servers
+// and to select the best sample
+func WithServers(o ...string) Option {
+	return func(n *NTP) (err error) {
+		if err = validateServers(o); err != nil {
+			return err
+		}
+
+		n.Servers = append([]string(nil), o...)
+
 		return err
 	}
 }
 
+// validateServers checks that there is at least one server, and that all of
+// them can be parsed.
+func validateServers(servers []string) error {
+	if len(servers) == 0 {
+		return fmt.Errorf("at least one server is required")
+	}
+
+	for _, server := range servers {
+		if _, _, err := parseServer(server, ntpPort); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // WithMaxPoll configures the ntp client MaxPoll interval
 func WithMaxPoll(o int) Option {
 	return func(n *NTP) (err error) {
@@ -63,3 +116,164 @@ func WithMinPoll(o int) Option {
 		return err
 	}
 }
+
+// WithPollJitter configures the fraction of the poll interval up to which a
+// random delay is added to each interval, zero disables the delay
+func WithPollJitter(o float64) Option {
+	return func(n *NTP) (err error) {
+		if o < 0 || o >= 1 {
+			return fmt.Errorf("PollJitter(%g) must be at least 0 and less than 1", o)
+		}
+
+		n.PollJitter = o
+
+		return err
+	}
+}
+
+// WithQueryTimeout configures the time given to each server to respond to a
+// query
+func WithQueryTimeout(o time.Duration) Option {
+	return func(n *NTP) (err error) {
+		if o <= 0 {
+			return fmt.Errorf("QueryTimeout(%s) must be greater than zero", o)
+		}
+
+		n.QueryTimeout = o
+
+		return err
+	}
+}
+
+// WithIBurst configures the number of queries sent to each server on the
+// initial sync, zero or one disables the burst
+func WithIBurst(o int) Option {
+	return func(n *NTP) (err error) {
+		if o < 0 || o > maxIBurst {
+			return fmt.Errorf("IBurst(%d) must be between 0 and %d", o, maxIBurst)
+		}
+
+		n.IBurst = o
+
+		return err
+	}
+}
+
+// WithTolerance configures the maximum clock offset at which the ntp client
+// considers the time to be in sync
+func WithTolerance(o time.Duration) Option {
+	return func(n *NTP) (err error) {
+		if o <= 0 {
+			return fmt.Errorf("Tolerance(%s) must be greater than zero", o)
+		}
+
+		n.Tolerance = o
+
+		return err
+	}
+}
+
+// WithStepThreshold configures the clock offset below which the ntp client
+// slews the clock gradually instead of stepping it, zero disables slewing
+func WithStepThreshold(o time.Duration) Option {
+	return func(n *NTP) (err error) {
+		if o < 0 || o > maxSlewOffset {
+			return fmt.Errorf("StepThreshold(%s) must be between 0 and %s", o, maxSlewOffset)
+		}
+
+		n.StepThreshold = o
+
+		return err
+	}
+}
+
+// WithMaxStep configures the clock offset above which the ntp client refuses
+// to adjust the clock, zero disables the threshold
+func WithMaxStep(o time.Duration) Option {
+	return func(n *NTP) (err error) {
+		if o < 0 {
+			return fmt.Errorf("MaxStep(%s) must not be negative", o)
+		}
+
+		n.MaxStep = o
+
+		return err
+	}
+}
+
+// WithDriftFile configures the file the ntp client persists the frequency
+// correction of the clock to, an empty path disables persistence
+func WithDriftFile(o string) Option {
+	return func(n *NTP) (err error) {
+		n.DriftFile = o
+		return err
+	}
+}
+
+// WithDriftWarningThreshold configures the magnitude of the frequency
+// correction, in ppm, above which the ntp client flags the drift of the clock,
+// zero disables the warning
+func WithDriftWarningThreshold(o float64) Option {
+	return func(n *NTP) (err error) {
+		if o < 0 || o > maxFrequency {
+			return fmt.Errorf("DriftWarningThreshold(%g) must be between 0 and %d", o, maxFrequency)
+		}
+
+		n.DriftWarningThreshold = o
+
+		return err
+	}
+}
+
+// WithPHC configures the ntp client to read the PTP hardware clock of the
+// specified device, such as /dev/ptp0, alongside the servers. The time of the
+// PHC is used while it agrees with the servers, and the time of the servers
+// when the device is absent or unreadable
+func WithPHC(o string) Option {
+	return func(n *NTP) (err error) {
+		if o == "" {
+			return fmt.Errorf("a PHC device is required")
+		}
+
+		n.PHC = o
+
+		return err
+	}
+}
+
+// WithSanityCheck configures the ntp client to cross-check the time of the
+// servers against the Date header of the specified HTTPS endpoint, and to
+// refuse to adjust the clock when they disagree by more than the threshold,
+// which must be at least the second of resolution of the header
+func WithSanityCheck(o string, threshold time.Duration) Option {
+	return func(n *NTP) (err error) {
+		u, err := url.Parse(o)
+		if err != nil {
+			return fmt.Errorf("invalid sanity check URL %q: %w", o, err)
+		}
+
+		if u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("sanity check URL %q must be an HTTPS URL", o)
+		}
+
+		if threshold < minSanityCheckThreshold {
+			return fmt.Errorf("SanityCheckThreshold(%s) must be at least %s", threshold, minSanityCheckThreshold)
+		}
+
+		n.SanityCheckURL = o
+		n.SanityCheckThreshold = threshold
+
+		return err
+	}
+}
+
+// WithSanityCheckRequired configures the ntp client to refuse to adjust the
+// clock while the sanity check endpoint is unreachable, rather than to trust
+// the time of the servers
+func WithSanityCheckRequired(o bool) Option {
+	return func(n *NTP) (err error) {
+		n.SanityCheckRequired = o
+
+		return err
+	}
+}
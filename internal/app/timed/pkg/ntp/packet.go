@@ -0,0 +1,53 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"time"
+
+	"github.com/beevik/ntp"
+)
+
+// Packet holds the details of an NTP response that are useful for
+// troubleshooting.
+type Packet struct {
+	// Originate is the local time the request was sent at.
+	Originate time.Time
+	// Receive is the server time the request was received at.
+	Receive time.Time
+	// Transmit is the server time the response was sent at.
+	Transmit time.Time
+	// Reference is the server time the server clock was last set or
+	// corrected at.
+	Reference time.Time
+
+	Precision      time.Duration
+	RootDelay      time.Duration
+	RootDispersion time.Duration
+	Poll           time.Duration
+}
+
+// NewPacket builds the packet details from a response, and the local time the
+// request was sent at.
+//
+// The response does not carry the receive timestamp, so it is derived from the
+// clock offset and round trip time:
+//
+//	offset = ((receive - originate) + (transmit - destination)) / 2
+//	rtt    = (destination - originate) - (transmit - receive)
+//
+// which gives receive = originate + offset + rtt/2.
+func NewPacket(resp *ntp.Response, originate time.Time) *Packet {
+	return &Packet{
+		Originate:      originate,
+		Receive:        originate.Add(resp.ClockOffset + resp.RTT/2),
+		Transmit:       resp.Time,
+		Reference:      resp.ReferenceTime,
+		Precision:      resp.Precision,
+		RootDelay:      resp.RootDelay,
+		RootDispersion: resp.RootDispersion,
+		Poll:           resp.Poll,
+	}
+}
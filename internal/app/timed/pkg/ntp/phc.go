@@ -0,0 +1,103 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/beevik/ntp"
+	"golang.org/x/sys/unix"
+)
+
+// clockFD is the type of the dynamic clocks, such as the PTP hardware clocks,
+// in their clock IDs, see linux/posix-timers.h.
+const clockFD = 3
+
+// taiOffset is the offset of TAI from UTC since the leap second at the end of
+// 2016. It is used while the kernel does not know the offset.
+const taiOffset = 37 * time.Second
+
+// readPHC is the function used to read a PTP hardware clock. It is a variable
+// so that tests can run without the hardware.
+var readPHC = phcOffset
+
+// phcOffset measures the clock offset of the system clock from the PTP
+// hardware clock of the device. PHCs run in TAI, so the time of the PHC is
+// converted to UTC. The PHC is read between two reads of the system clock, the
+// offset is measured from their midpoint, and the delay is the time between
+// them.
+func phcOffset(device string) (phc time.Time, offset, delay time.Duration, err error) {
+	f, err := os.Open(device)
+	if err != nil {
+		return phc, 0, 0, err
+	}
+
+	tai := kernelTAIOffset()
+
+	// nolint: errcheck
+	defer f.Close()
+
+	clock := int32((^f.Fd())<<3 | clockFD)
+
+	var ts unix.Timespec
+
+	before := time.Now()
+	err = unix.ClockGettime(clock, &ts)
+	after := time.Now()
+
+	if err != nil {
+		return phc, 0, 0, fmt.Errorf("failed to read the clock of %s: %w", device, err)
+	}
+
+	phc = time.Unix(ts.Unix()).Add(-tai)
+	delay = after.Sub(before)
+	offset = phc.Sub(before.Add(delay / 2))
+
+	return phc, offset, delay, nil
+}
+
+// kernelTAIOffset returns the offset of TAI from UTC known to the kernel, or
+// taiOffset if it is not set.
+func kernelTAIOffset() time.Duration {
+	timex := &syscall.Timex{}
+
+	if _, err := adjtimex(timex); err != nil || timex.Tai == 0 {
+		return taiOffset
+	}
+
+	return time.Duration(timex.Tai) * time.Second
+}
+
+// queryPHC reads the PTP hardware clock. The sample of a device that is absent
+// or unreadable fails like the sample of a server that does not respond, so
+// the time of the servers is used instead.
+func (n *NTP) queryPHC() *Sample {
+	s := &Sample{
+		Server:    n.PHC,
+		Source:    SourcePHC,
+		Originate: time.Now(),
+	}
+
+	phc, offset, delay, err := readPHC(n.PHC)
+	if err != nil {
+		s.Err = err
+
+		return s
+	}
+
+	// The PHC is a reference clock, it has no stratum, dispersion or leap
+	// second warning of its own.
+	s.Response = &ntp.Response{
+		Time:          phc,
+		ReferenceTime: phc,
+		ClockOffset:   offset,
+		RTT:           delay,
+	}
+
+	return s
+}
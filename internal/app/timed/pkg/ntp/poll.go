@@ -0,0 +1,90 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"math/rand"
+	"time"
+)
+
+// pollStableSamples is the number of consecutive stable offsets required
+// before the poll interval is widened.
+const pollStableSamples = 4
+
+// pollAdapter adapts the poll interval to the stability of the measured clock
+// offsets, in the spirit of the poll interval control in ntpd: the interval
+// is doubled after a run of offsets within the threshold, and halved as soon
+// as an offset exceeds it. The interval is kept within [min, max].
+type pollAdapter struct {
+	min       time.Duration
+	max       time.Duration
+	threshold time.Duration
+
+	interval time.Duration
+	stable   int
+}
+
+func newPollAdapter(min, max, threshold time.Duration) *pollAdapter {
+	return &pollAdapter{
+		min:       min,
+		max:       max,
+		threshold: threshold,
+		interval:  min,
+	}
+}
+
+// update records the offset measured by a sync, and returns the interval to
+// wait before the next one.
+func (p *pollAdapter) update(offset time.Duration) time.Duration {
+	if offset < 0 {
+		offset = -offset
+	}
+
+	if offset > p.threshold {
+		p.stable = 0
+		p.interval /= 2
+	} else {
+		p.stable++
+
+		if p.stable >= pollStableSamples {
+			p.stable = 0
+			p.interval *= 2
+		}
+	}
+
+	return p.clamp()
+}
+
+// reset returns to the min interval after a failed sync, so that the clock is
+// polled frequently until it syncs again.
+func (p *pollAdapter) reset() time.Duration {
+	p.stable = 0
+	p.interval = p.min
+
+	return p.interval
+}
+
+func (p *pollAdapter) clamp() time.Duration {
+	if p.interval < p.min {
+		p.interval = p.min
+	}
+
+	if p.interval > p.max {
+		p.interval = p.max
+	}
+
+	return p.interval
+}
+
+// jittered returns the interval with a random delay of up to the fraction of
+// it added, so that clients started at the same time do not keep querying a
+// shared server in lockstep.
+func jittered(interval time.Duration, fraction float64, r *rand.Rand) time.Duration {
+	if max := int64(float64(interval) * fraction); max > 0 {
+		interval += time.Duration(r.Int63n(max))
+	}
+
+	return interval
+}
@@ -0,0 +1,204 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/beevik/ntp"
+)
+
+// Source is the kind of a time source.
+type Source int
+
+const (
+	// SourceNTP is an NTP server.
+	SourceNTP Source = iota
+	// SourcePHC is a PTP hardware clock.
+	SourcePHC
+)
+
+// Sample is the result of querying a single server.
+type Sample struct {
+	// Server is the server, or the device of the PTP hardware clock.
+	Server   string
+	Source   Source
+	Response *ntp.Response
+	// Originate is the local time the request was sent at.
+	Originate time.Time
+	Err       error
+	// Outlier indicates that the clock offset of the sample disagrees with the
+	// other servers, and that the sample was discarded.
+	Outlier bool
+}
+
+// ErrResolve is matched by the errors of the servers whose name could not be
+// resolved, as opposed to the servers which did not respond, or responded with
+// an invalid packet. Names may not resolve at boot, until the network is
+// configured.
+var ErrResolve = errors.New("failed to resolve the server")
+
+// resolveError is the error of a failed name resolution.
+type resolveError struct {
+	err error
+}
+
+func (e *resolveError) Error() string {
+	return e.err.Error()
+}
+
+func (e *resolveError) Unwrap() error {
+	return e.err
+}
+
+func (e *resolveError) Is(target error) bool {
+	return target == ErrResolve
+}
+
+// queryServer is the function used to query a single server, which returns
+// the response along with the local time the request was sent at, giving the
+// server up to the timeout to respond. It is a variable so that tests can run
+// without network access.
+var queryServer = query
+
+// withContext runs the query, and returns the error of the context if it is
+// done first. The query is left to complete in the background.
+func withContext(ctx context.Context, f func() (*ntp.Response, time.Time, error)) (*ntp.Response, time.Time, error) {
+	type result struct {
+		resp      *ntp.Response
+		originate time.Time
+		err       error
+	}
+
+	ch := make(chan result, 1)
+
+	go func() {
+		resp, originate, err := f()
+
+		ch <- result{resp, originate, err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.resp, r.originate, r.err
+	case <-ctx.Done():
+		return nil, time.Time{}, ctx.Err()
+	}
+}
+
+// queryAll queries all servers concurrently, giving each of them up to the
+// timeout. The samples are returned in the order of the servers.
+func queryAll(ctx context.Context, servers []string, timeout time.Duration) []*Sample {
+	samples := make([]*Sample, len(servers))
+
+	var wg sync.WaitGroup
+
+	for i, server := range servers {
+		wg.Add(1)
+
+		go func(i int, server string) {
+			defer wg.Done()
+
+			s := &Sample{Server: server}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			s.Response, s.Originate, s.Err = withContext(ctx, func() (*ntp.Response, time.Time, error) {
+				return queryServer(server, timeout)
+			})
+
+			var dnsErr *net.DNSError
+
+			switch {
+			case s.Err == nil:
+				s.Err = s.Response.Validate()
+			case errors.Is(s.Err, context.DeadlineExceeded):
+				s.Err = fmt.Errorf("no response within %s: %w", timeout, s.Err)
+			case errors.As(s.Err, &dnsErr):
+				s.Err = &resolveError{err: s.Err}
+			}
+
+			samples[i] = s
+		}(i, server)
+	}
+
+	wg.Wait()
+
+	return samples
+}
+
+// selectBest marks the samples whose clock offset differs from the median
+// offset by more than the tolerance as outliers, and returns the remaining
+// sample with the lowest round-trip delay. The lower median is used, so that
+// the median sample itself is always a candidate. It returns nil if no server
+// responded.
+//
+// A PTP hardware clock is preferred over the servers, as long as it agrees
+// with them: the median is that of the servers, unless none responded.
+func selectBest(samples []*Sample, tolerance time.Duration) *Sample {
+	valid := make([]*Sample, 0, len(samples))
+	offsets := make([]time.Duration, 0, len(samples))
+
+	for _, s := range samples {
+		if s.Err != nil {
+			continue
+		}
+
+		valid = append(valid, s)
+
+		if s.Source == SourceNTP {
+			offsets = append(offsets, s.Response.ClockOffset)
+		}
+	}
+
+	if len(valid) == 0 {
+		return nil
+	}
+
+	if len(offsets) == 0 {
+		for _, s := range valid {
+			offsets = append(offsets, s.Response.ClockOffset)
+		}
+	}
+
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+	median := offsets[(len(offsets)-1)/2]
+
+	var best *Sample
+
+	for _, s := range valid {
+		deviation := s.Response.ClockOffset - median
+		if deviation < 0 {
+			deviation = -deviation
+		}
+
+		if deviation > tolerance {
+			s.Outlier = true
+
+			continue
+		}
+
+		switch {
+		case best == nil:
+			best = s
+		case (s.Source == SourcePHC) != (best.Source == SourcePHC):
+			if s.Source == SourcePHC {
+				best = s
+			}
+		case s.Response.RTT < best.Response.RTT:
+			best = s
+		}
+	}
+
+	return best
+}
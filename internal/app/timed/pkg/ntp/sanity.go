@@ -0,0 +1,157 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// ErrSanityCheck is returned when the time of the servers disagrees with the
+// time of the sanity check endpoint.
+var ErrSanityCheck = errors.New("time disagrees with the sanity check endpoint")
+
+// minSanityCheckThreshold is the resolution of the Date header, below which
+// the sanity check would fail for the right time.
+const minSanityCheckThreshold = time.Second
+
+// sanityCheckRootCAs are the CAs that the certificate of the sanity check
+// endpoint is verified with, nil meaning the roots of the system. It is a
+// variable so that tests can trust their own endpoint.
+var sanityCheckRootCAs *x509.CertPool
+
+// SanityCheck is the result of cross-checking the time of the servers against
+// the time of the sanity check endpoint.
+type SanityCheck struct {
+	URL       string
+	Threshold time.Duration
+	// Offset is the time of the endpoint minus the time of the servers, and
+	// is zero if the endpoint did not respond.
+	Offset time.Duration
+	// Err is the error querying the endpoint, or wraps ErrSanityCheck if the
+	// offset exceeds the threshold.
+	Err error
+}
+
+// SanityCheck cross-checks the time of the servers, which is the local time
+// corrected by the clock offset, against the Date header of the sanity check
+// endpoint. It returns nil if there is no endpoint to check against.
+//
+// The certificate of the endpoint is verified at the time of the servers, as
+// the local clock may be far off, e.g. on the first boot of a machine without
+// a battery backed RTC.
+func (n *NTP) SanityCheck(ctx context.Context, offset time.Duration) *SanityCheck {
+	if n.SanityCheckURL == "" {
+		return nil
+	}
+
+	check := &SanityCheck{
+		URL:       n.SanityCheckURL,
+		Threshold: n.SanityCheckThreshold,
+	}
+
+	client := &http.Client{
+		Timeout: n.QueryTimeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				RootCAs: sanityCheckRootCAs,
+				Time:    func() time.Time { return time.Now().Add(offset) },
+			},
+		},
+	}
+
+	defer client.CloseIdleConnections()
+
+	req, err := http.NewRequest(http.MethodHead, n.SanityCheckURL, nil)
+	if err != nil {
+		check.Err = err
+
+		return check
+	}
+
+	start := time.Now()
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		check.Err = err
+
+		return check
+	}
+
+	// nolint: errcheck
+	resp.Body.Close()
+
+	rtt := time.Since(start)
+
+	// Any response is dated, whatever its status.
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		check.Err = fmt.Errorf("invalid Date header from %s: %w", n.SanityCheckURL, err)
+
+		return check
+	}
+
+	// The Date header is truncated to the second, so the middle of the second
+	// is compared with the time of the servers in the middle of the round trip.
+	check.Offset = date.Add(minSanityCheckThreshold / 2).Sub(start.Add(rtt / 2).Add(offset))
+
+	abs := check.Offset
+	if abs < 0 {
+		abs = -abs
+	}
+
+	if abs > check.Threshold {
+		check.Err = fmt.Errorf("offset %s from %s exceeds %s: %w", check.Offset, n.SanityCheckURL, check.Threshold, ErrSanityCheck)
+	}
+
+	return check
+}
+
+// LastSanityCheck returns the result of the sanity check of the most recent
+// sync, nil if there is no endpoint to check against or before the first sync.
+func (n *NTP) LastSanityCheck() *SanityCheck {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.sanityCheck
+}
+
+// checkSanity refuses an offset for which the time of the servers fails the
+// sanity check, as it does for one exceeding the maximum step. While the
+// endpoint cannot be reached, the time is only refused if the sanity check is
+// required.
+func (n *NTP) checkSanity(offset time.Duration, server string) error {
+	check := n.SanityCheck(context.Background(), offset)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.sanityCheck = check
+
+	if check == nil || check.Err == nil {
+		return nil
+	}
+
+	if !errors.Is(check.Err, ErrSanityCheck) && !n.SanityCheckRequired {
+		log.Printf("adjusting the clock by %s from %s without the sanity check: %v", offset, server, check.Err)
+
+		return nil
+	}
+
+	log.Printf("refusing to adjust the clock by %s from %s, which fails the sanity check: %v", offset, server, check.Err)
+
+	n.rejected = true
+	n.inBounds = false
+	n.pollAdapter().reset()
+
+	return fmt.Errorf("sanity check of the offset %s from %s: %w", offset, server, check.Err)
+}
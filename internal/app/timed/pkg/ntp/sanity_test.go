@@ -0,0 +1,166 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/beevik/ntp"
+)
+
+// sanityCheckServer starts an HTTPS endpoint whose Date header is the local
+// time plus the skew, and trusts its certificate.
+func sanityCheckServer(skew *int64) (srv *httptest.Server, stop func()) {
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		date := time.Now().Add(time.Duration(atomic.LoadInt64(skew)))
+
+		w.Header().Set("Date", date.UTC().Format(http.TimeFormat))
+	}))
+
+	saved := sanityCheckRootCAs
+
+	sanityCheckRootCAs = x509.NewCertPool()
+	sanityCheckRootCAs.AddCert(srv.Certificate())
+
+	return srv, func() {
+		sanityCheckRootCAs = saved
+
+		srv.Close()
+	}
+}
+
+func (suite *NtpSuite) TestSanityCheck() {
+	var skew int64
+
+	srv, stop := sanityCheckServer(&skew)
+	defer stop()
+
+	n, err := NewNTPClient(WithSanityCheck(srv.URL, 5*time.Second))
+	suite.Require().NoError(err)
+
+	check := n.SanityCheck(context.Background(), 0)
+	suite.Require().NotNil(check)
+	suite.Assert().NoError(check.Err)
+	suite.Assert().Equal(srv.URL, check.URL)
+	suite.Assert().Equal(5*time.Second, check.Threshold)
+	suite.Assert().True(check.Offset < time.Second && check.Offset > -time.Second, "offset %s", check.Offset)
+
+	// The time of the servers is an hour ahead of the endpoint.
+	check = n.SanityCheck(context.Background(), time.Hour)
+	suite.Assert().True(errors.Is(check.Err, ErrSanityCheck), "error = %v", check.Err)
+	suite.Assert().True(check.Offset < -time.Hour+time.Second, "offset %s", check.Offset)
+
+	// Both of the times are an hour ahead of the local clock.
+	atomic.StoreInt64(&skew, int64(time.Hour))
+
+	check = n.SanityCheck(context.Background(), time.Hour)
+	suite.Assert().NoError(check.Err)
+
+	stop()
+
+	check = n.SanityCheck(context.Background(), time.Hour)
+	suite.Assert().Error(check.Err)
+	suite.Assert().False(errors.Is(check.Err, ErrSanityCheck))
+
+	// There is nothing to check against without an endpoint.
+	n, err = NewNTPClient()
+	suite.Require().NoError(err)
+	suite.Assert().Nil(n.SanityCheck(context.Background(), 0))
+}
+
+func (suite *NtpSuite) TestSanityCheckRefusesTime() {
+	defer func(f func(string, time.Duration) (*ntp.Response, time.Time, error)) { queryServer = f }(queryServer)
+	defer func(f func(*syscall.Timex) (int, error)) { adjtimex = f }(adjtimex)
+	defer func(f func(*syscall.Timeval) error) { settimeofday = f }(settimeofday)
+
+	var skew int64
+
+	srv, stop := sanityCheckServer(&skew)
+	defer stop()
+
+	var steps int
+
+	adjtimex = func(*syscall.Timex) (int, error) { return 0, nil }
+	settimeofday = func(*syscall.Timeval) error {
+		steps++
+
+		return nil
+	}
+
+	var offset time.Duration
+
+	queryServer = func(server string, _ time.Duration) (*ntp.Response, time.Time, error) {
+		return &ntp.Response{Stratum: 2, ClockOffset: offset}, time.Now(), nil
+	}
+
+	n, err := NewNTPClient(WithServer("a"), WithSanityCheck(srv.URL, time.Minute))
+	suite.Require().NoError(err)
+
+	// The servers are a day off from the endpoint, even on the initial sync.
+	offset = 24 * time.Hour
+
+	err = n.QueryAndSetTime()
+	suite.Assert().True(errors.Is(err, ErrSanityCheck), "error = %v", err)
+	suite.Assert().Equal(0, steps)
+	suite.Assert().False(n.Ready())
+
+	// The local clock is a day behind, as the endpoint tells.
+	atomic.StoreInt64(&skew, int64(24*time.Hour))
+
+	suite.Require().NoError(n.QueryAndSetTime())
+	suite.Assert().Equal(1, steps)
+	suite.Assert().True(n.Ready())
+	suite.Require().NotNil(n.LastSanityCheck())
+	suite.Assert().NoError(n.LastSanityCheck().Err)
+
+	// The time of the servers is trusted while the endpoint is unreachable,
+	// unless the sanity check is required.
+	offset = time.Second
+
+	stop()
+
+	suite.Require().NoError(n.QueryAndSetTime())
+	suite.Assert().Equal(2, steps)
+	suite.Assert().Error(n.LastSanityCheck().Err)
+
+	n.SanityCheckRequired = true
+
+	err = n.QueryAndSetTime()
+	suite.Assert().Error(err)
+	suite.Assert().False(errors.Is(err, ErrSanityCheck), "error = %v", err)
+	suite.Assert().Equal(2, steps)
+}
+
+func (suite *NtpSuite) TestSanityCheckOptions() {
+	for _, tt := range []struct {
+		url       string
+		threshold time.Duration
+	}{
+		{"http://example.com", time.Minute},
+		{"https://", time.Minute},
+		{"example.com", time.Minute},
+		{"https://example.com", 500 * time.Millisecond},
+	} {
+		_, err := NewNTPClient(WithSanityCheck(tt.url, tt.threshold))
+		suite.Assert().Error(err, tt.url)
+	}
+
+	n, err := NewNTPClient(WithSanityCheck("https://example.com", time.Second))
+	suite.Require().NoError(err)
+	suite.Assert().Equal("https://example.com", n.SanityCheckURL)
+	suite.Assert().Equal(time.Second, n.SanityCheckThreshold)
+	suite.Assert().False(n.SanityCheckRequired)
+
+	n, err = NewNTPClient(WithSanityCheckRequired(true))
+	suite.Require().NoError(err)
+	suite.Assert().True(n.SanityCheckRequired)
+}
@@ -0,0 +1,243 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beevik/ntp"
+)
+
+// ErrInvalidServer indicates that the address of a server can not be parsed.
+var ErrInvalidServer = errors.New("invalid NTP server address")
+
+const ntpPort = 123
+
+// parseServer splits the address of a server into the host and the port,
+// which defaults to the specified one. The host is a hostname or an IP
+// address. An IPv6 address may be enclosed in brackets, and must be when a
+// port is given, e.g. `[2001:db8::1]:123`.
+func parseServer(server string, defaultPort int) (host string, port int, err error) {
+	invalid := func(reason string, args ...interface{}) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidServer, server, fmt.Sprintf(reason, args...))
+	}
+
+	host, portString, hasPort := server, "", false
+
+	switch {
+	case strings.HasPrefix(server, "["):
+		end := strings.Index(server, "]")
+		if end < 0 {
+			return "", 0, invalid("missing closing bracket")
+		}
+
+		host = server[1:end]
+
+		switch rest := server[end+1:]; {
+		case rest == "":
+		case strings.HasPrefix(rest, ":"):
+			portString, hasPort = rest[1:], true
+		default:
+			return "", 0, invalid("unexpected %q after the closing bracket", rest)
+		}
+
+		if !strings.Contains(host, ":") || net.ParseIP(host) == nil {
+			return "", 0, invalid("only IPv6 addresses can be enclosed in brackets")
+		}
+	case strings.Count(server, ":") > 1:
+		if net.ParseIP(server) == nil {
+			return "", 0, invalid("invalid IPv6 address, which must be enclosed in brackets to add a port")
+		}
+	case strings.Contains(server, ":"):
+		i := strings.Index(server, ":")
+
+		host, portString, hasPort = server[:i], server[i+1:], true
+	}
+
+	switch {
+	case host == "":
+		return "", 0, invalid("missing host")
+	case net.ParseIP(host) == nil && !validHostname(host):
+		return "", 0, invalid("invalid hostname")
+	}
+
+	if !hasPort {
+		return host, defaultPort, nil
+	}
+
+	if port, err = strconv.Atoi(portString); err != nil || port < 1 || port > 65535 {
+		return "", 0, invalid("invalid port %q", portString)
+	}
+
+	return host, port, nil
+}
+
+// validHostname returns true if the name consists of dot separated labels of
+// letters, digits, hyphens and underscores.
+func validHostname(name string) bool {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+
+		for _, r := range label {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			default:
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// query queries the server, which is validated first, and returns the
+// response along with the local time the request was sent at. The name of the
+// server is resolved before, so that the time of the request does not include
+// the time of the resolution. The ntp package always queries port 123, so
+// servers on another port are queried directly. The server is given up to the
+// timeout to respond.
+func query(server string, timeout time.Duration) (*ntp.Response, time.Time, error) {
+	host, port, err := parseServer(server, ntpPort)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if port == ntpPort {
+		ip := addr.IP.String()
+		if addr.Zone != "" {
+			ip += "%" + addr.Zone
+		}
+
+		var resp *ntp.Response
+
+		originate := time.Now()
+		resp, err = ntp.QueryWithOptions(ip, ntp.QueryOptions{Timeout: timeout})
+
+		return resp, originate, err
+	}
+
+	return queryAddress(addr.String(), timeout)
+}
+
+// queryAddress sends an unauthenticated client request to the address, and
+// returns the response along with the local time the request was sent at.
+// Like the ntp package, it waits for the response for the timeout at most.
+func queryAddress(addr string, timeout time.Duration) (*ntp.Response, time.Time, error) {
+	conn, err := net.DialTimeout("udp", addr, timeout)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	// nolint: errcheck
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	originate := time.Now()
+
+	req := make([]byte, 48)
+	// LI = 0, VN = 4, Mode = 3 (client)
+	req[0] = 4<<3 | 3
+	binary.BigEndian.PutUint64(req[40:], toNTPTime(originate))
+
+	if _, err = conn.Write(req); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	buf := make([]byte, 1024)
+
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	destination := time.Now()
+
+	switch {
+	case n < 48:
+		return nil, time.Time{}, fmt.Errorf("short response from %s: %d bytes", addr, n)
+	case buf[0]&0x7 != 4:
+		return nil, time.Time{}, fmt.Errorf("response from %s is not a server response", addr)
+	case !bytes.Equal(buf[24:32], req[40:48]):
+		// The origin timestamp of the response is the transmit timestamp of
+		// the request.
+		return nil, time.Time{}, fmt.Errorf("response from %s does not match the request", addr)
+	}
+
+	return parseResponse(buf[:48], originate, destination), originate, nil
+}
+
+// parseResponse builds the response from the header of the server packet, in
+// the same way the ntp package does.
+func parseResponse(hdr []byte, originate, destination time.Time) *ntp.Response {
+	receive := fromNTPTime(binary.BigEndian.Uint64(hdr[32:]))
+	transmit := fromNTPTime(binary.BigEndian.Uint64(hdr[40:]))
+
+	resp := &ntp.Response{
+		Time:           transmit,
+		ClockOffset:    (receive.Sub(originate) + transmit.Sub(destination)) / 2,
+		RTT:            destination.Sub(originate) - transmit.Sub(receive),
+		Precision:      log2Duration(int8(hdr[3])),
+		Stratum:        hdr[1],
+		ReferenceID:    binary.BigEndian.Uint32(hdr[12:]),
+		ReferenceTime:  fromNTPTime(binary.BigEndian.Uint64(hdr[16:])),
+		RootDelay:      fromNTPShort(binary.BigEndian.Uint32(hdr[4:])),
+		RootDispersion: fromNTPShort(binary.BigEndian.Uint32(hdr[8:])),
+		Leap:           ntp.LeapIndicator(hdr[0] >> 6),
+		Poll:           log2Duration(int8(hdr[2])),
+	}
+
+	if resp.RTT < 0 {
+		resp.RTT = 0
+	}
+
+	resp.RootDistance = resp.RTT/2 + resp.RootDelay/2 + resp.RootDispersion
+
+	return resp
+}
+
+// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and
+// the Unix epoch (1970).
+const ntpEpochOffset = 2208988800
+
+func toNTPTime(t time.Time) uint64 {
+	nsec := uint64(t.Sub(time.Unix(-ntpEpochOffset, 0)))
+	sec := nsec / 1e9
+	frac := (nsec % 1e9) << 32 / 1e9
+
+	return sec<<32 | frac
+}
+
+func fromNTPTime(v uint64) time.Time {
+	sec := v >> 32
+	frac := (v & 0xffffffff) * 1e9 >> 32
+
+	return time.Unix(int64(sec)-ntpEpochOffset, int64(frac))
+}
+
+func fromNTPShort(v uint32) time.Duration {
+	return time.Duration(uint64(v) * 1e9 >> 16)
+}
+
+func log2Duration(exp int8) time.Duration {
+	return time.Duration(math.Pow(2, float64(exp)) * float64(time.Second))
+}
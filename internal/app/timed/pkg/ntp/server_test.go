@@ -0,0 +1,106 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"time"
+)
+
+func (suite *NtpSuite) TestParseServer() {
+	for _, tt := range []struct {
+		server string
+		host   string
+		port   int
+	}{
+		{"time.cloudflare.com", "time.cloudflare.com", 123},
+		{"time.cloudflare.com:1123", "time.cloudflare.com", 1123},
+		{"192.0.2.1", "192.0.2.1", 123},
+		{"192.0.2.1:1123", "192.0.2.1", 1123},
+		{"2001:db8::1", "2001:db8::1", 123},
+		{"[2001:db8::1]", "2001:db8::1", 123},
+		{"[2001:db8::1]:123", "2001:db8::1", 123},
+		{"[2001:db8::1]:1123", "2001:db8::1", 1123},
+	} {
+		host, port, err := parseServer(tt.server, ntpPort)
+		suite.Require().NoError(err, tt.server)
+		suite.Assert().Equal(tt.host, host, tt.server)
+		suite.Assert().Equal(tt.port, port, tt.server)
+	}
+
+	for _, server := range []string{
+		"",
+		":123",
+		"time.cloudflare.com:",
+		"time.cloudflare.com:ntp",
+		"time.cloudflare.com:65536",
+		"time cloudflare com",
+		"[2001:db8::1",
+		"[2001:db8::1]123",
+		"[2001:db8::1]:",
+		"[192.0.2.1]:123",
+		"2001:db8::1:123:abcde",
+		"https://time.cloudflare.com",
+	} {
+		_, _, err := parseServer(server, ntpPort)
+		suite.Assert().True(errors.Is(err, ErrInvalidServer), "server %q: error = %v", server, err)
+	}
+
+	_, err := NewNTPClient(WithServers("time.cloudflare.com", "[2001:db8::1"))
+	suite.Assert().Error(err)
+}
+
+func (suite *NtpSuite) TestQueryAddress() {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+
+	// nolint: errcheck
+	defer conn.Close()
+
+	now := time.Now()
+
+	go func() {
+		buf := make([]byte, 48)
+
+		_, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		resp := make([]byte, 48)
+		// LI = 0, VN = 4, Mode = 4 (server)
+		resp[0] = 4<<3 | 4
+		resp[1] = 2
+		binary.BigEndian.PutUint64(resp[16:], toNTPTime(now))
+		copy(resp[24:32], buf[40:48])
+		binary.BigEndian.PutUint64(resp[32:], toNTPTime(now))
+		binary.BigEndian.PutUint64(resp[40:], toNTPTime(now))
+
+		// nolint: errcheck
+		conn.WriteTo(resp, addr)
+	}()
+
+	resp, _, err := queryServer(conn.LocalAddr().String(), time.Second)
+	suite.Require().NoError(err)
+	suite.Assert().EqualValues(2, resp.Stratum)
+	suite.Assert().NoError(resp.Validate())
+}
+
+func (suite *NtpSuite) TestQueryAddressTimeout() {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+
+	// nolint: errcheck
+	defer conn.Close()
+
+	// The server never responds, so the query gives up after the timeout.
+	start := time.Now()
+
+	_, _, err = queryServer(conn.LocalAddr().String(), 100*time.Millisecond)
+	suite.Require().Error(err)
+	suite.Assert().Less(int64(time.Since(start)), int64(defaultQueryTimeout))
+}
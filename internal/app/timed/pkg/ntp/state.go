@@ -0,0 +1,100 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ntp
+
+import (
+	"sync"
+	"time"
+)
+
+// stateBufferSize is the number of states buffered for each subscriber.
+const stateBufferSize = 16
+
+// SyncState is the state of the clock synchronization after a sync.
+type SyncState struct {
+	// Synced indicates that the offset is within the tolerance.
+	Synced bool
+	// Offset is the clock offset measured by the last successful sync.
+	Offset time.Duration
+	// Server is the server of the last successful sync.
+	Server string
+	// LastSync is the time of the last successful sync, and is zero before
+	// the first one.
+	LastSync time.Time
+	// MaxError is the maximum error of the time of the last successful sync,
+	// plus the drift of the clock since, and is zero before the first one.
+	MaxError time.Duration
+}
+
+// State returns the state of the clock synchronization.
+func (n *NTP) State() SyncState {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.state()
+}
+
+// state must be called with the mutex held.
+func (n *NTP) state() SyncState {
+	if n.lastSync.IsZero() {
+		return SyncState{}
+	}
+
+	abs := n.offset
+	if abs < 0 {
+		abs = -abs
+	}
+
+	return SyncState{
+		Synced:   abs <= n.Tolerance && !n.rejected,
+		Offset:   n.offset,
+		Server:   n.server,
+		LastSync: n.lastSync,
+		MaxError: n.maxError + drift(time.Since(n.lastSync)),
+	}
+}
+
+// Subscribe returns a channel that receives the state after every sync, and a
+// function that cancels the subscription. States are dropped for subscribers
+// that do not keep up.
+func (n *NTP) Subscribe() (<-chan SyncState, func()) {
+	ch := make(chan SyncState, stateBufferSize)
+
+	n.mu.Lock()
+
+	if n.subscribers == nil {
+		n.subscribers = map[chan SyncState]struct{}{}
+	}
+
+	n.subscribers[ch] = struct{}{}
+
+	n.mu.Unlock()
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+
+			delete(n.subscribers, ch)
+			close(ch)
+		})
+	}
+}
+
+func (n *NTP) publish() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	state := n.state()
+
+	for ch := range n.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
@@ -6,8 +6,13 @@ package reg
 
 import (
 	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
 	"time"
 
+	beevikntp "github.com/beevik/ntp"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/empty"
 	"google.golang.org/grpc"
@@ -34,37 +39,294 @@ func (r *Registrator) Register(s *grpc.Server) {
 	timeapi.RegisterTimeServiceServer(s, r)
 }
 
-// Time issues a query to the configured ntp server and displays the results
-func (r *Registrator) Time(ctx context.Context, in *empty.Empty) (reply *timeapi.TimeResponse, err error) {
+// Time issues a query to the configured ntp servers and displays the results
+// of every server, starting with the selected one. A server which does not
+// respond is reported with its error, and the time of the others is selected
+// instead. Before the first sync, the servers are queried once, and if none of
+// them responds, their messages are marked as retrying rather than an error
+// returned. After it, the queries are retried until one of the servers
+// responds, and fail once the context is done, or the retries give up after
+// the max poll interval
+func (r *Registrator) Time(ctx context.Context, in *timeapi.TimeFormatRequest) (reply *timeapi.TimeResponse, err error) {
 	reply = &timeapi.TimeResponse{}
 
-	rt, err := r.Timed.Query()
+	// The client retries the servers by itself until the first sync, so the
+	// servers are queried once, and a failure is reported as retrying.
+	if r.Timed.State().LastSync.IsZero() {
+		return r.timeRetrying(ctx, in)
+	}
+
+	best, samples, err := r.Timed.QueryBest(ctx)
 	if err != nil {
 		return reply, err
 	}
 
-	return genProtobufTimeResponse(r.Timed.GetTime(), rt.Time, r.Timed.Server)
+	if reply, err = genProtobufSamplesResponse(r.Timed.GetTime(), best, samples, r.Timed.Jitter(), in.GetFormats()); err != nil {
+		return reply, err
+	}
+
+	markMaxStep(reply, r.Timed)
+	markSanityCheck(reply, best, r.Timed)
+	markDrift(reply, best, r.Timed)
+
+	return reply, nil
 }
 
-// TimeCheck issues a query to the specified ntp server and displays the results
+// timeRetrying queries the servers of a client which has not synced yet. Until
+// one of the servers responds, the messages of the servers are marked as
+// retrying, and no error is returned.
+func (r *Registrator) timeRetrying(ctx context.Context, in *timeapi.TimeFormatRequest) (reply *timeapi.TimeResponse, err error) {
+	best, samples := r.Timed.QueryOnce(ctx)
+
+	if reply, err = genProtobufSamplesResponse(r.Timed.GetTime(), best, samples, r.Timed.Jitter(), in.GetFormats()); err != nil {
+		return reply, err
+	}
+
+	if best == nil {
+		for _, msg := range reply.Messages {
+			msg.Retrying = true
+		}
+	}
+
+	markMaxStep(reply, r.Timed)
+	markSanityCheck(reply, best, r.Timed)
+	markDrift(reply, best, r.Timed)
+
+	return reply, nil
+}
+
+// TimeCheck issues a query to the specified ntp server and displays the results.
+// When several servers are specified, each of them is queried once and
+// compared with the others
 func (r *Registrator) TimeCheck(ctx context.Context, in *timeapi.TimeRequest) (reply *timeapi.TimeResponse, err error) {
 	reply = &timeapi.TimeResponse{}
 
+	if len(in.GetServers()) > 0 {
+		if reply, err = compareServers(ctx, in); err == nil {
+			markMaxStep(reply, r.Timed)
+		}
+
+		return reply, err
+	}
+
 	tc, err := ntp.NewNTPClient(ntp.WithServer(in.Server))
 	if err != nil {
 		return reply, err
 	}
 
-	rt, err := tc.Query()
+	best, _, err := tc.QueryBest(ctx)
 	if err != nil {
 		return reply, err
 	}
 
-	return genProtobufTimeResponse(tc.GetTime(), rt.Time, in.Server)
+	reply, err = genProtobufTimeResponse(tc.GetTime(), best.Response, in.Server, in.GetFormats())
+	if err != nil {
+		return reply, err
+	}
+
+	markMaxStep(reply, r.Timed)
+
+	if in.GetVerbose() {
+		if reply.Messages[0].Packet, err = genProtobufNTPPacket(ntp.NewPacket(best.Response, best.Originate)); err != nil {
+			return reply, err
+		}
+	}
+
+	return reply, nil
 }
 
-func genProtobufTimeResponse(local, remote time.Time, server string) (*timeapi.TimeResponse, error) {
+// compareServers queries the servers once, and returns a message per server.
+// Servers that cannot be reached are reported with an error instead of
+// failing the query.
+func compareServers(ctx context.Context, in *timeapi.TimeRequest) (reply *timeapi.TimeResponse, err error) {
+	reply = &timeapi.TimeResponse{}
+
+	servers := in.GetServers()
+	if in.GetServer() != "" {
+		servers = append([]string{in.GetServer()}, servers...)
+	}
+
+	tc, err := ntp.NewNTPClient(ntp.WithServers(servers...))
+	if err != nil {
+		return reply, err
+	}
+
+	best, samples := tc.QueryOnce(ctx)
+
+	if reply, err = genProtobufSamplesResponse(tc.GetTime(), best, samples, 0, in.GetFormats()); err != nil {
+		return reply, err
+	}
+
+	// There is a single query, so there is no jitter to report.
+	if best != nil {
+		reply.Messages[0].Jitter = nil
+	}
+
+	if in.GetVerbose() {
+		for _, msg := range reply.Messages {
+			for _, sample := range samples {
+				if sample.Err != nil || sample.Server != msg.Server {
+					continue
+				}
+
+				if msg.Packet, err = genProtobufNTPPacket(ntp.NewPacket(sample.Response, sample.Originate)); err != nil {
+					return reply, err
+				}
+			}
+		}
+	}
+
+	return reply, nil
+}
+
+// markMaxStep marks the messages of the servers whose clock offset exceeds the
+// maximum step of the client, so the time of these servers would be rejected.
+func markMaxStep(reply *timeapi.TimeResponse, n *ntp.NTP) {
+	for _, msg := range reply.Messages {
+		if msg.Offset == nil {
+			continue
+		}
+
+		if offset, err := ptypes.Duration(msg.Offset); err == nil {
+			msg.ExceedsMaxStep = n.ExceedsMaxStep(offset)
+		}
+	}
+}
+
+// markSanityCheck reports the result of the sanity check of the most recent
+// sync, if any, in the message of the selected server. The endpoint is not
+// queried again, so that the time API does not depend on it.
+func markSanityCheck(reply *timeapi.TimeResponse, best *ntp.Sample, n *ntp.NTP) {
+	if best == nil {
+		return
+	}
+
+	reply.Messages[0].SanityCheck = genProtobufSanityCheck(n.LastSanityCheck())
+}
+
+// markDrift reports the frequency correction of the local clock in the message
+// of the selected server, once the client has read it after a sync.
+func markDrift(reply *timeapi.TimeResponse, best *ntp.Sample, n *ntp.NTP) {
+	if best == nil {
+		return
+	}
+
+	if drift, ok := n.Drift(); ok {
+		reply.Messages[0].Drift = genProtobufDrift(drift, n.DriftWarningThreshold)
+	}
+}
+
+func genProtobufDrift(drift ntp.Drift, threshold float64) *timeapi.Drift {
+	return &timeapi.Drift{
+		Ppm:       drift.PPM,
+		Change:    drift.Change,
+		Window:    ptypes.DurationProto(drift.Window),
+		Warning:   drift.Warning,
+		Threshold: threshold,
+	}
+}
+
+func genProtobufSanityCheck(check *ntp.SanityCheck) *timeapi.SanityCheck {
+	if check == nil {
+		return nil
+	}
+
+	msg := &timeapi.SanityCheck{
+		Url:       check.URL,
+		Threshold: ptypes.DurationProto(check.Threshold),
+		Passed:    check.Err == nil,
+	}
+
+	if check.Err != nil {
+		msg.Error = check.Err.Error()
+	}
+
+	// The offset is only measured if the endpoint responded.
+	if check.Err == nil || errors.Is(check.Err, ntp.ErrSanityCheck) {
+		msg.Offset = ptypes.DurationProto(check.Offset)
+	}
+
+	return msg
+}
+
+// SyncTolerance reports the configured sync tolerance along with the offset
+// measured by the most recent sync and the current poll interval, without
+// querying the ntp server
+func (r *Registrator) SyncTolerance(ctx context.Context, in *empty.Empty) (reply *timeapi.SyncToleranceResponse, err error) {
+	offset, synced := r.Timed.SyncStatus()
+
+	reply = &timeapi.SyncToleranceResponse{
+		Messages: []*timeapi.SyncTolerance{
+			{
+				Tolerance:    ptypes.DurationProto(r.Timed.Tolerance),
+				Offset:       ptypes.DurationProto(offset),
+				Synced:       synced,
+				PollInterval: ptypes.DurationProto(r.Timed.PollInterval()),
+			},
+		},
+	}
+
+	return reply, nil
+}
+
+// TimeReady reports whether the initial time sync completed, and the clock
+// was within the tolerance after the most recent sync
+func (r *Registrator) TimeReady(ctx context.Context, in *empty.Empty) (reply *timeapi.TimeReadyResponse, err error) {
+	reply = &timeapi.TimeReadyResponse{
+		Messages: []*timeapi.TimeReady{
+			{
+				Ready: r.Timed.Ready(),
+			},
+		},
+	}
+
+	return reply, nil
+}
+
+// WatchTimeSync streams the sync state, starting with the current state and
+// followed by the state after every sync, until the client disconnects
+func (r *Registrator) WatchTimeSync(in *empty.Empty, srv timeapi.TimeService_WatchTimeSyncServer) error {
+	states, cancel := r.Timed.Subscribe()
+	defer cancel()
+
+	state := r.Timed.State()
+
+	for {
+		if err := srv.Send(genProtobufTimeSyncState(state, time.Now())); err != nil {
+			return err
+		}
+
+		var ok bool
+
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case state, ok = <-states:
+			if !ok {
+				return nil
+			}
+		}
+	}
+}
+
+func genProtobufTimeSyncState(state ntp.SyncState, now time.Time) *timeapi.TimeSyncState {
+	msg := &timeapi.TimeSyncState{
+		Synchronized: state.Synced,
+		Offset:       ptypes.DurationProto(state.Offset),
+		Server:       state.Server,
+	}
+
+	if !state.LastSync.IsZero() {
+		msg.SinceLastSync = ptypes.DurationProto(now.Sub(state.LastSync))
+		msg.MaxError = ptypes.DurationProto(state.MaxError)
+	}
+
+	return msg
+}
+
+func genProtobufTimeResponse(local time.Time, rt *beevikntp.Response, server string, formats []timeapi.TimeFormat) (*timeapi.TimeResponse, error) {
 	resp := &timeapi.TimeResponse{}
+	remote := rt.Time
 
 	localpbts, err := ptypes.TimestampProto(local)
 	if err != nil {
@@ -76,15 +338,147 @@ func genProtobufTimeResponse(local, remote time.Time, server string) (*timeapi.T
 		return resp, err
 	}
 
+	msg := &timeapi.Time{
+		Server:      server,
+		Localtime:   localpbts,
+		Remotetime:  remotepbts,
+		Offset:      ptypes.DurationProto(rt.ClockOffset),
+		Rtt:         ptypes.DurationProto(rt.RTT),
+		Leap:        timeapi.LeapIndicator(rt.Leap),
+		Stratum:     uint32(rt.Stratum),
+		ReferenceId: referenceID(rt.Stratum, rt.ReferenceID),
+		MaxError:    ptypes.DurationProto(ntp.MaxError(rt)),
+	}
+
+	for _, format := range formats {
+		switch format {
+		case timeapi.TimeFormat_RFC3339:
+			msg.LocaltimeRfc3339 = local.Format(time.RFC3339Nano)
+			msg.RemotetimeRfc3339 = remote.Format(time.RFC3339Nano)
+		case timeapi.TimeFormat_UNIX:
+			msg.LocaltimeUnix = strconv.FormatInt(local.Unix(), 10)
+			msg.RemotetimeUnix = strconv.FormatInt(remote.Unix(), 10)
+		case timeapi.TimeFormat_TIMESTAMP:
+			// The protobuf timestamps are always returned.
+		}
+	}
+
 	resp = &timeapi.TimeResponse{
-		Messages: []*timeapi.Time{
-			{
-				Server:     server,
-				Localtime:  localpbts,
-				Remotetime: remotepbts,
-			},
-		},
+		Messages: []*timeapi.Time{msg},
+	}
+
+	return resp, nil
+}
+
+// referenceID formats the reference identifier of a response, which is four
+// ASCII characters for stratum 0 (the kiss code) and 1 (the reference clock),
+// and an IPv4 address, or the hash of an IPv6 address, for the other strata.
+func referenceID(stratum uint8, id uint32) string {
+	b := []byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id)}
+
+	if stratum > 1 {
+		return net.IP(b).String()
+	}
+
+	return strings.TrimRight(string(b), "\x00 ")
+}
+
+// genProtobufSamplesResponse returns the selected sample first, followed by
+// the other samples. The best sample is nil if no server responded.
+func genProtobufSamplesResponse(local time.Time, best *ntp.Sample, samples []*ntp.Sample, jitter time.Duration, formats []timeapi.TimeFormat) (*timeapi.TimeResponse, error) {
+	resp := &timeapi.TimeResponse{}
+
+	localpbts, err := ptypes.TimestampProto(local)
+	if err != nil {
+		return resp, err
+	}
+
+	if best != nil {
+		if resp, err = genProtobufTimeResponse(local, best.Response, best.Server, formats); err != nil {
+			return resp, err
+		}
+
+		selected := resp.Messages[0]
+		selected.Selected = true
+		selected.Source = timeapi.TimeSource(best.Source)
+		selected.Jitter = ptypes.DurationProto(jitter)
+
+		if len(samples) > 1 {
+			selected.RelativeOffset = ptypes.DurationProto(0)
+		}
+	}
+
+	for _, sample := range samples {
+		if sample == best {
+			continue
+		}
+
+		if sample.Err != nil {
+			// There is no remote time to report for a failed query.
+			msg := &timeapi.Time{
+				Server:    sample.Server,
+				Localtime: localpbts,
+				Error:     sample.Err.Error(),
+				Source:    timeapi.TimeSource(sample.Source),
+			}
+
+			// A response which failed validation tells why it was rejected:
+			// the kiss code, or an unsynchronized server.
+			if rt := sample.Response; rt != nil {
+				msg.Stratum = uint32(rt.Stratum)
+				msg.ReferenceId = referenceID(rt.Stratum, rt.ReferenceID)
+			}
+
+			resp.Messages = append(resp.Messages, msg)
+
+			continue
+		}
+
+		var r *timeapi.TimeResponse
+
+		if r, err = genProtobufTimeResponse(local, sample.Response, sample.Server, formats); err != nil {
+			return resp, err
+		}
+
+		msg := r.Messages[0]
+		msg.Outlier = sample.Outlier
+		msg.Source = timeapi.TimeSource(sample.Source)
+
+		if best != nil {
+			msg.RelativeOffset = ptypes.DurationProto(sample.Response.ClockOffset - best.Response.ClockOffset)
+		}
+
+		resp.Messages = append(resp.Messages, msg)
 	}
 
 	return resp, nil
 }
+
+func genProtobufNTPPacket(packet *ntp.Packet) (*timeapi.NTPPacket, error) {
+	msg := &timeapi.NTPPacket{
+		Precision:      ptypes.DurationProto(packet.Precision),
+		RootDelay:      ptypes.DurationProto(packet.RootDelay),
+		RootDispersion: ptypes.DurationProto(packet.RootDispersion),
+		Poll:           ptypes.DurationProto(packet.Poll),
+	}
+
+	var err error
+
+	if msg.Originate, err = ptypes.TimestampProto(packet.Originate); err != nil {
+		return nil, err
+	}
+
+	if msg.Receive, err = ptypes.TimestampProto(packet.Receive); err != nil {
+		return nil, err
+	}
+
+	if msg.Transmit, err = ptypes.TimestampProto(packet.Transmit); err != nil {
+		return nil, err
+	}
+
+	if msg.Reference, err = ptypes.TimestampProto(packet.Reference); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
@@ -6,13 +6,16 @@ package reg
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"os"
 	"testing"
+	"time"
 
-	"github.com/golang/protobuf/ptypes/empty"
+	beevikntp "github.com/beevik/ntp"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/stretchr/testify/suite"
 	"google.golang.org/grpc"
 
@@ -60,9 +63,88 @@ func (suite *TimedSuite) TestTime() {
 	suite.Assert().NoError(err)
 
 	nClient := timeapi.NewTimeServiceClient(conn)
-	reply, err := nClient.Time(context.Background(), &empty.Empty{})
+	reply, err := nClient.Time(context.Background(), &timeapi.TimeFormatRequest{})
 	suite.Assert().NoError(err)
 	suite.Assert().Equal(reply.Messages[0].Server, testServer)
+	suite.Assert().Empty(reply.Messages[0].LocaltimeRfc3339)
+	suite.Assert().Empty(reply.Messages[0].LocaltimeUnix)
+}
+
+func (suite *TimedSuite) TestTimeRetrying() {
+	n, err := ntp.NewNTPClient(ntp.WithServer("ntp.invalid"), ntp.WithQueryTimeout(time.Second))
+	suite.Require().NoError(err)
+
+	// Before the first sync, a server failing is not an error.
+	reply, err := NewRegistrator(n).Time(context.Background(), &timeapi.TimeFormatRequest{})
+	suite.Require().NoError(err)
+	suite.Require().Len(reply.Messages, 1)
+	suite.Assert().Equal("ntp.invalid", reply.Messages[0].Server)
+	suite.Assert().NotEmpty(reply.Messages[0].Error)
+	suite.Assert().True(reply.Messages[0].Retrying)
+}
+
+// fakeNTPServer answers the NTP queries on a local port with the current time,
+// and returns its address.
+func fakeNTPServer(suite *TimedSuite) (addr string, stop func()) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+
+	// ntpTime returns the NTP timestamp of the time, the seconds since 1900.
+	ntpTime := func(t time.Time) uint64 {
+		nsec := uint64(t.Sub(time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC)))
+
+		return (nsec/1e9)<<32 | (nsec%1e9)<<32/1e9
+	}
+
+	go func() {
+		buf := make([]byte, 48)
+
+		for {
+			_, peer, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			now := time.Now()
+
+			resp := make([]byte, 48)
+			// LI = 0, VN = 4, Mode = 4 (server)
+			resp[0] = 4<<3 | 4
+			resp[1] = 2
+			binary.BigEndian.PutUint64(resp[16:], ntpTime(now))
+			copy(resp[24:32], buf[40:48])
+			binary.BigEndian.PutUint64(resp[32:], ntpTime(now))
+			binary.BigEndian.PutUint64(resp[40:], ntpTime(now))
+
+			// nolint: errcheck
+			conn.WriteTo(resp, peer)
+		}
+	}()
+
+	// nolint: errcheck
+	return conn.LocalAddr().String(), func() { conn.Close() }
+}
+
+func (suite *TimedSuite) TestTimeFallback() {
+	addr, stop := fakeNTPServer(suite)
+	defer stop()
+
+	// The first server never responds, so the time of the second one is
+	// selected.
+	n, err := ntp.NewNTPClient(ntp.WithServers("ntp.invalid", addr), ntp.WithQueryTimeout(time.Second))
+	suite.Require().NoError(err)
+
+	reply, err := NewRegistrator(n).Time(context.Background(), &timeapi.TimeFormatRequest{})
+	suite.Require().NoError(err)
+	suite.Require().Len(reply.Messages, 2)
+
+	suite.Assert().Equal(addr, reply.Messages[0].Server)
+	suite.Assert().True(reply.Messages[0].Selected)
+	suite.Assert().Empty(reply.Messages[0].Error)
+	suite.Assert().False(reply.Messages[0].Retrying)
+
+	suite.Assert().Equal("ntp.invalid", reply.Messages[1].Server)
+	suite.Assert().NotEmpty(reply.Messages[1].Error)
 }
 
 func (suite *TimedSuite) TestTimeCheck() {
@@ -100,6 +182,208 @@ func (suite *TimedSuite) TestTimeCheck() {
 	suite.Assert().Equal(reply.Messages[0].Server, testServer)
 }
 
+func (suite *TimedSuite) TestGenProtobufTimeResponse() {
+	local := time.Date(2020, time.April, 1, 12, 0, 0, 0, time.UTC)
+	rt := &beevikntp.Response{
+		Time:        local.Add(time.Second),
+		ClockOffset: time.Second,
+		RTT:         20 * time.Millisecond,
+		Leap:        beevikntp.LeapAddSecond,
+		Stratum:     2,
+		ReferenceID: 0xc0000201,
+		RootDelay:   10 * time.Millisecond,
+	}
+
+	reply, err := genProtobufTimeResponse(local, rt, "test", []timeapi.TimeFormat{timeapi.TimeFormat_RFC3339, timeapi.TimeFormat_UNIX})
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("2020-04-01T12:00:00Z", reply.Messages[0].LocaltimeRfc3339)
+	suite.Assert().Equal("2020-04-01T12:00:01Z", reply.Messages[0].RemotetimeRfc3339)
+	suite.Assert().Equal("1585742400", reply.Messages[0].LocaltimeUnix)
+	suite.Assert().Equal("1585742401", reply.Messages[0].RemotetimeUnix)
+	suite.Assert().NotNil(reply.Messages[0].Localtime)
+	suite.Assert().NotNil(reply.Messages[0].Remotetime)
+
+	offset, err := ptypes.Duration(reply.Messages[0].Offset)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(time.Second, offset)
+
+	rtt, err := ptypes.Duration(reply.Messages[0].Rtt)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(20*time.Millisecond, rtt)
+
+	suite.Assert().Equal(timeapi.LeapIndicator_ADD_SECOND, reply.Messages[0].Leap)
+	suite.Assert().EqualValues(2, reply.Messages[0].Stratum)
+	suite.Assert().Equal("192.0.2.1", reply.Messages[0].ReferenceId)
+
+	maxError, err := ptypes.Duration(reply.Messages[0].MaxError)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(ntp.MaxError(rt), maxError)
+	suite.Assert().True(maxError > 15*time.Millisecond)
+}
+
+func (suite *TimedSuite) TestReferenceID() {
+	suite.Assert().Equal("192.0.2.1", referenceID(2, 0xc0000201))
+	suite.Assert().Equal("GPS", referenceID(1, 0x47505300))
+	suite.Assert().Equal("RATE", referenceID(0, 0x52415445))
+	suite.Assert().Equal("", referenceID(0, 0))
+}
+
+func (suite *TimedSuite) TestGenProtobufSamplesResponse() {
+	local := time.Date(2020, time.April, 1, 12, 0, 0, 0, time.UTC)
+
+	best := &ntp.Sample{Server: "b", Response: &beevikntp.Response{Time: local.Add(time.Second), ClockOffset: time.Second}}
+	samples := []*ntp.Sample{
+		{Server: "a", Response: &beevikntp.Response{Time: local.Add(time.Minute), ClockOffset: time.Minute}, Outlier: true},
+		best,
+		{Server: "c", Err: fmt.Errorf("i/o timeout")},
+	}
+
+	reply, err := genProtobufSamplesResponse(local, best, samples, 3*time.Millisecond, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(reply.Messages, 3)
+
+	suite.Assert().Equal("b", reply.Messages[0].Server)
+	suite.Assert().True(reply.Messages[0].Selected)
+
+	offset, err := ptypes.Duration(reply.Messages[0].Offset)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(time.Second, offset)
+
+	jitter, err := ptypes.Duration(reply.Messages[0].Jitter)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(3*time.Millisecond, jitter)
+
+	suite.Assert().Equal("a", reply.Messages[1].Server)
+	suite.Assert().Nil(reply.Messages[1].Jitter)
+	suite.Assert().True(reply.Messages[1].Outlier)
+	suite.Assert().False(reply.Messages[1].Selected)
+
+	relative, err := ptypes.Duration(reply.Messages[1].RelativeOffset)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(59*time.Second, relative)
+
+	suite.Assert().Equal("c", reply.Messages[2].Server)
+	suite.Assert().Equal("i/o timeout", reply.Messages[2].Error)
+	suite.Assert().Nil(reply.Messages[2].Remotetime)
+	suite.Assert().Nil(reply.Messages[2].RelativeOffset)
+
+	// No server responded.
+	reply, err = genProtobufSamplesResponse(local, nil, samples[2:], 0, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(reply.Messages, 1)
+	suite.Assert().Equal("c", reply.Messages[0].Server)
+	suite.Assert().Equal("i/o timeout", reply.Messages[0].Error)
+	suite.Assert().NotNil(reply.Messages[0].Localtime)
+	suite.Assert().Equal(timeapi.TimeSource_NTP, reply.Messages[0].Source)
+
+	// A rejected response reports its stratum and kiss code.
+	kiss := &ntp.Sample{Server: "d", Err: fmt.Errorf("kiss of death received: RATE"), Response: &beevikntp.Response{ReferenceID: 0x52415445}}
+
+	reply, err = genProtobufSamplesResponse(local, nil, []*ntp.Sample{kiss}, 0, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(reply.Messages, 1)
+	suite.Assert().Equal(uint32(0), reply.Messages[0].Stratum)
+	suite.Assert().Equal("RATE", reply.Messages[0].ReferenceId)
+	suite.Assert().Nil(reply.Messages[0].Remotetime)
+
+	// The source of the samples is reported.
+	phc := &ntp.Sample{Server: "/dev/ptp0", Source: ntp.SourcePHC, Response: &beevikntp.Response{Time: local, ClockOffset: time.Microsecond}}
+
+	reply, err = genProtobufSamplesResponse(local, phc, []*ntp.Sample{samples[1], phc}, 0, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(reply.Messages, 2)
+	suite.Assert().Equal("/dev/ptp0", reply.Messages[0].Server)
+	suite.Assert().Equal(timeapi.TimeSource_PHC, reply.Messages[0].Source)
+	suite.Assert().Equal(timeapi.TimeSource_NTP, reply.Messages[1].Source)
+}
+
+func (suite *TimedSuite) TestMarkMaxStep() {
+	n, err := ntp.NewNTPClient(ntp.WithMaxStep(time.Minute))
+	suite.Require().NoError(err)
+
+	reply := &timeapi.TimeResponse{
+		Messages: []*timeapi.Time{
+			{Offset: ptypes.DurationProto(time.Second)},
+			{Offset: ptypes.DurationProto(-time.Hour)},
+			{Error: "no response"},
+		},
+	}
+
+	markMaxStep(reply, n)
+
+	suite.Assert().False(reply.Messages[0].ExceedsMaxStep)
+	suite.Assert().True(reply.Messages[1].ExceedsMaxStep)
+	suite.Assert().False(reply.Messages[2].ExceedsMaxStep)
+}
+
+func (suite *TimedSuite) TestGenProtobufDrift() {
+	msg := genProtobufDrift(ntp.Drift{PPM: -120.5, Change: 3.25, Window: 2 * time.Hour, Warning: true}, 100)
+	suite.Assert().Equal(-120.5, msg.Ppm)
+	suite.Assert().Equal(3.25, msg.Change)
+	suite.Assert().True(msg.Warning)
+	suite.Assert().Equal(100.0, msg.Threshold)
+
+	window, err := ptypes.Duration(msg.Window)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(2*time.Hour, window)
+
+	// There is no frequency correction to report before the first sync.
+	n, err := ntp.NewNTPClient()
+	suite.Require().NoError(err)
+
+	reply := &timeapi.TimeResponse{Messages: []*timeapi.Time{{Selected: true}}}
+
+	markDrift(reply, &ntp.Sample{}, n)
+	suite.Assert().Nil(reply.Messages[0].Drift)
+}
+
+func (suite *TimedSuite) TestGenProtobufSanityCheck() {
+	suite.Assert().Nil(genProtobufSanityCheck(nil))
+
+	msg := genProtobufSanityCheck(&ntp.SanityCheck{URL: "https://example.com", Threshold: time.Minute, Offset: time.Second})
+	suite.Assert().True(msg.Passed)
+	suite.Assert().Equal("https://example.com", msg.Url)
+	suite.Assert().Empty(msg.Error)
+
+	offset, err := ptypes.Duration(msg.Offset)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(time.Second, offset)
+
+	msg = genProtobufSanityCheck(&ntp.SanityCheck{URL: "https://example.com", Threshold: time.Minute, Offset: time.Hour, Err: fmt.Errorf("offset 1h: %w", ntp.ErrSanityCheck)})
+	suite.Assert().False(msg.Passed)
+	suite.Assert().NotEmpty(msg.Error)
+	suite.Assert().NotNil(msg.Offset)
+
+	// No offset is measured if the endpoint does not respond.
+	msg = genProtobufSanityCheck(&ntp.SanityCheck{URL: "https://example.com", Threshold: time.Minute, Err: fmt.Errorf("connection refused")})
+	suite.Assert().False(msg.Passed)
+	suite.Assert().Nil(msg.Offset)
+}
+
+func (suite *TimedSuite) TestGenProtobufTimeSyncState() {
+	suite.Assert().Nil(genProtobufTimeSyncState(ntp.SyncState{}, time.Now()).SinceLastSync)
+
+	now := time.Date(2020, time.April, 1, 12, 0, 0, 0, time.UTC)
+
+	msg := genProtobufTimeSyncState(ntp.SyncState{
+		Synced:   true,
+		Offset:   time.Millisecond,
+		Server:   "a",
+		LastSync: now.Add(-time.Minute),
+		MaxError: 5 * time.Millisecond,
+	}, now)
+	suite.Assert().True(msg.Synchronized)
+	suite.Assert().Equal("a", msg.Server)
+
+	since, err := ptypes.Duration(msg.SinceLastSync)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(time.Minute, since)
+
+	maxError, err := ptypes.Duration(msg.MaxError)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(5*time.Millisecond, maxError)
+}
+
 func fakeTimedRPC() (net.Listener, error) {
 	tmpfile, err := ioutil.TempFile("", "timed")
 	if err != nil {
@@ -13,14 +13,25 @@ import (
 	"github.com/talos-systems/talos/pkg/retry"
 
 	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
 )
 
+// pullProgressInterval is the interval between the reports of the progress of
+// a pull.
+const pullProgressInterval = time.Second
+
 // Pull is a convenience function that wraps the containerd image pull func with
-// retry functionality.
+// retry functionality. The bytes downloaded are reported with
+// `runtime.ReportProgress`.
 func Pull(ctx context.Context, reg runtime.Registries, client *containerd.Client, ref string) (img containerd.Image, err error) {
 	resolver := NewResolver(reg)
 
-	err = retry.Exponential(1*time.Minute, retry.WithUnits(1*time.Second)).Retry(func() error {
+	progressCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go reportPullProgress(progressCtx, client.ContentStore())
+
+	err = retry.Exponential(1*time.Minute, retry.WithUnits(1*time.Second), retry.WithCap(10*time.Second)).Retry(func() error {
 		if img, err = client.Pull(ctx, ref, containerd.WithPullUnpack, containerd.WithResolver(resolver)); err != nil {
 			return retry.ExpectedError(fmt.Errorf("failed to pull image %q: %w", ref, err))
 		}
@@ -34,3 +45,46 @@ func Pull(ctx context.Context, reg runtime.Registries, client *containerd.Client
 
 	return img, nil
 }
+
+// reportPullProgress reports the bytes downloaded in the namespace until the
+// context is canceled. The blobs that are no longer being downloaded count as
+// complete. Pulls run concurrently in the namespace are included.
+func reportPullProgress(ctx context.Context, store content.Store) {
+	ticker := time.NewTicker(pullProgressInterval)
+	defer ticker.Stop()
+
+	blobs := map[string]content.Status{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		statuses, err := store.ListStatuses(ctx)
+		if err != nil {
+			continue
+		}
+
+		active := make(map[string]struct{}, len(statuses))
+
+		for _, status := range statuses {
+			blobs[status.Ref] = status
+			active[status.Ref] = struct{}{}
+		}
+
+		progress := runtime.Progress{Unit: "bytes"}
+
+		for ref, status := range blobs {
+			if _, ok := active[ref]; !ok {
+				status.Offset = status.Total
+			}
+
+			progress.Current += status.Offset
+			progress.Total += status.Total
+		}
+
+		runtime.ReportProgress(ctx, progress)
+	}
+}
@@ -0,0 +1,118 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package imagesig
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// CosignSignatureAnnotation is the annotation of the layers of a cosign
+	// signature manifest that holds the base64 encoded signature of the
+	// layer.
+	CosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+	// cosignPayloadType is the type of the simple signing payloads of cosign.
+	cosignPayloadType = "cosign container image signature"
+)
+
+// CosignSignature is a cosign signature, as stored in a layer of the signature
+// manifest of an image.
+type CosignSignature struct {
+	// Payload is the content of the layer, a simple signing payload.
+	Payload []byte
+	// Signature is the value of the signature annotation of the layer.
+	Signature string
+}
+
+// cosignPayload is the simple signing payload that cosign signs.
+type cosignPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// CosignSignatureTag returns the tag that cosign stores the signatures of the
+// manifest digest under, e.g. `sha256-<hex>.sig`.
+func CosignSignatureTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1) + ".sig"
+}
+
+// VerifyCosign verifies the cosign signatures of an image of the repository,
+// e.g. `ghcr.io/talos-systems/installer`, against the keys. A single signature
+// that verifies, and whose payload is for the repository and digest, is
+// enough.
+func VerifyCosign(keys []*PublicKey, repository, digest string, signatures []CosignSignature) (*Result, error) {
+	if len(signatures) == 0 {
+		return nil, ErrNoSignature
+	}
+
+	var errs []string
+
+	for i, sig := range signatures {
+		fingerprint, err := verifyCosignSignature(keys, repository, digest, sig)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("signature %d: %s", i, err))
+
+			continue
+		}
+
+		return &Result{Method: MethodCosign, Digest: digest, Fingerprint: fingerprint}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrVerification, strings.Join(errs, "; "))
+}
+
+func verifyCosignSignature(keys []*PublicKey, repository, digest string, sig CosignSignature) (fingerprint string, err error) {
+	signature, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	for _, key := range keys {
+		if key.Verify(sig.Payload, signature) {
+			fingerprint = key.Fingerprint
+
+			break
+		}
+	}
+
+	if fingerprint == "" {
+		return "", fmt.Errorf("does not verify with any of the %d key(s)", len(keys))
+	}
+
+	// The payload is only trusted once its signature verifies.
+	var payload cosignPayload
+
+	if err = json.Unmarshal(sig.Payload, &payload); err != nil {
+		return "", fmt.Errorf("invalid payload: %w", err)
+	}
+
+	switch {
+	case payload.Critical.Type != cosignPayloadType:
+		return "", fmt.Errorf("unexpected payload type %q", payload.Critical.Type)
+	case payload.Critical.Image.DockerManifestDigest != digest:
+		return "", fmt.Errorf("payload is for digest %q", payload.Critical.Image.DockerManifestDigest)
+	case normalizeRepository(payload.Critical.Identity.DockerReference) != normalizeRepository(repository):
+		return "", fmt.Errorf("payload is for repository %q", payload.Critical.Identity.DockerReference)
+	}
+
+	return fingerprint, nil
+}
+
+// normalizeRepository normalizes the name of the Docker Hub registry, which
+// cosign records as `index.docker.io`.
+func normalizeRepository(repository string) string {
+	return strings.Replace(repository, "index.docker.io/", "docker.io/", 1)
+}
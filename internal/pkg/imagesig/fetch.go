@@ -0,0 +1,108 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package imagesig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/remotes"
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// maxBlobSize limits the size of the signature manifests and payloads
+// fetched, which are a few KiB.
+const maxBlobSize = 1024 * 1024
+
+// FetchCosignSignatures fetches the cosign signatures of the manifest digest
+// of an image from the registry of the image. It returns ErrNoSignature if
+// the image is not signed.
+func FetchCosignSignatures(ctx context.Context, resolver remotes.Resolver, named reference.Named, dgst digest.Digest) ([]CosignSignature, error) {
+	ref := reference.TrimNamed(named).String() + ":" + CosignSignatureTag(dgst.String())
+
+	name, desc, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %q not found", ErrNoSignature, ref)
+		}
+
+		return nil, fmt.Errorf("failed to resolve %q: %w", ref, err)
+	}
+
+	fetcher, err := resolver.Fetcher(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := fetchBlob(ctx, fetcher, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %q: %w", ref, err)
+	}
+
+	var manifest ocispec.Manifest
+
+	if err = json.Unmarshal(b, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid signature manifest %q: %w", ref, err)
+	}
+
+	signatures := make([]CosignSignature, 0, len(manifest.Layers))
+
+	for _, layer := range manifest.Layers {
+		sig, ok := layer.Annotations[CosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+
+		payload, err := fetchBlob(ctx, fetcher, layer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the payload %s of %q: %w", layer.Digest, ref, err)
+		}
+
+		signatures = append(signatures, CosignSignature{Payload: payload, Signature: sig})
+	}
+
+	if len(signatures) == 0 {
+		return nil, fmt.Errorf("%w: %q has no signature layers", ErrNoSignature, ref)
+	}
+
+	return signatures, nil
+}
+
+// fetchBlob fetches a blob, and verifies it against the digest of its
+// descriptor.
+func fetchBlob(ctx context.Context, fetcher remotes.Fetcher, desc ocispec.Descriptor) ([]byte, error) {
+	if desc.Size > maxBlobSize {
+		return nil, fmt.Errorf("blob of %d bytes exceeds the limit of %d bytes", desc.Size, maxBlobSize)
+	}
+
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint: errcheck
+	defer rc.Close()
+
+	b, err := ioutil.ReadAll(io.LimitReader(rc, maxBlobSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) > maxBlobSize {
+		return nil, fmt.Errorf("blob exceeds the limit of %d bytes", maxBlobSize)
+	}
+
+	if actual := digest.FromBytes(b); actual != desc.Digest {
+		return nil, fmt.Errorf("blob digest %s does not match %s", actual, desc.Digest)
+	}
+
+	return b, nil
+}
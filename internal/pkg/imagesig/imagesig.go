@@ -0,0 +1,152 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package imagesig verifies the signatures of container images, either a
+// detached signature of the manifest digest of an image, or the signatures
+// that cosign stores in the registry next to the image.
+package imagesig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrInvalidKey indicates that a public key is not a PEM encoded ECDSA,
+	// Ed25519, or RSA public key.
+	ErrInvalidKey = errors.New("invalid public key")
+
+	// ErrVerification indicates that no signature of an image verifies with
+	// any of the public keys.
+	ErrVerification = errors.New("image signature verification failed")
+
+	// ErrNoSignature indicates that an image has no signature to verify.
+	ErrNoSignature = errors.New("image has no signature")
+)
+
+// Method is the way the signature of an image is provided.
+type Method string
+
+const (
+	// MethodDetached is a signature of the manifest digest of the image,
+	// provided along with the image reference.
+	MethodDetached Method = "detached"
+	// MethodCosign is a signature of a cosign payload, stored in the registry
+	// of the image.
+	MethodCosign Method = "cosign"
+)
+
+// PublicKey is a public key that signatures are verified against.
+type PublicKey struct {
+	key crypto.PublicKey
+	// Fingerprint identifies the key in the results, as the SHA-256 of its
+	// DER encoding.
+	Fingerprint string
+}
+
+// ParsePublicKey parses a PEM encoded PKIX public key.
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM data found", ErrInvalidKey)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %T", ErrInvalidKey, key)
+	}
+
+	sum := sha256.Sum256(block.Bytes)
+
+	return &PublicKey{key: key, Fingerprint: "SHA256:" + hex.EncodeToString(sum[:])}, nil
+}
+
+// ParsePublicKeys parses the PEM encoded keys.
+func ParsePublicKeys(keys ...string) ([]*PublicKey, error) {
+	result := make([]*PublicKey, 0, len(keys))
+
+	for i, key := range keys {
+		k, err := ParsePublicKey([]byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+
+		result = append(result, k)
+	}
+
+	return result, nil
+}
+
+// Verify verifies the signature of the message. ECDSA signatures are ASN.1
+// encoded, and RSA signatures are PKCS #1 v1.5, both of the SHA-256 of the
+// message, as produced by e.g. `openssl dgst -sha256 -sign`. Ed25519
+// signatures are of the message itself.
+func (k *PublicKey) Verify(message, signature []byte) bool {
+	sum := sha256.Sum256(message)
+
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		var sig struct {
+			R, S *big.Int
+		}
+
+		if rest, err := asn1.Unmarshal(signature, &sig); err != nil || len(rest) != 0 {
+			return false
+		}
+
+		return ecdsa.Verify(key, sum[:], sig.R, sig.S)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], signature) == nil
+	default:
+		return false
+	}
+}
+
+// Result is the result of a successful verification.
+type Result struct {
+	Method Method
+	// Digest is the manifest digest of the image verified.
+	Digest string
+	// Fingerprint is the fingerprint of the key that the signature verified
+	// with.
+	Fingerprint string
+}
+
+func (r *Result) String() string {
+	return fmt.Sprintf("verified %s signature of %s with key %s", r.Method, r.Digest, r.Fingerprint)
+}
+
+// VerifyDetached verifies a detached signature of the manifest digest of an
+// image, e.g. `sha256:...`, against the keys.
+func VerifyDetached(keys []*PublicKey, digest string, signature []byte) (*Result, error) {
+	if len(signature) == 0 {
+		return nil, ErrNoSignature
+	}
+
+	for _, key := range keys {
+		if key.Verify([]byte(digest), signature) {
+			return &Result{Method: MethodDetached, Digest: digest, Fingerprint: key.Fingerprint}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: detached signature of %s does not verify with any of the %d key(s)", ErrVerification, digest, len(keys))
+}
@@ -0,0 +1,216 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package imagesig_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/talos-systems/talos/internal/pkg/imagesig"
+)
+
+const (
+	digest     = "sha256:2f5b1e3b26ea2834ae8a3c5b2c0d5e2b6b6c0c7e80b1cf2f6f0f2b8e9c3b1a0d"
+	repository = "ghcr.io/talos-systems/installer"
+)
+
+// signer signs the messages like the keys verify them.
+type signer struct {
+	sign func([]byte) []byte
+	pem  string
+}
+
+func newSigners(t *testing.T) map[string]signer {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	encode := func(key crypto.PublicKey) string {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		assert.NoError(t, err)
+
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+
+	return map[string]signer{
+		"ecdsa": {
+			sign: func(message []byte) []byte {
+				sum := sha256.Sum256(message)
+
+				r, s, err := ecdsa.Sign(rand.Reader, ecdsaKey, sum[:])
+				assert.NoError(t, err)
+
+				sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+				assert.NoError(t, err)
+
+				return sig
+			},
+			pem: encode(&ecdsaKey.PublicKey),
+		},
+		"ed25519": {
+			sign: func(message []byte) []byte {
+				return ed25519.Sign(edPrivate, message)
+			},
+			pem: encode(edPublic),
+		},
+		"rsa": {
+			sign: func(message []byte) []byte {
+				sum := sha256.Sum256(message)
+
+				sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, sum[:])
+				assert.NoError(t, err)
+
+				return sig
+			},
+			pem: encode(&rsaKey.PublicKey),
+		},
+	}
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	signers := newSigners(t)
+
+	keys, err := imagesig.ParsePublicKeys(signers["ecdsa"].pem, signers["rsa"].pem)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+	assert.NotEqual(t, keys[0].Fingerprint, keys[1].Fingerprint)
+
+	_, err = imagesig.ParsePublicKeys(signers["ed25519"].pem, "not a key")
+	assert.True(t, errors.Is(err, imagesig.ErrInvalidKey))
+	assert.EqualError(t, err, "key 1: invalid public key: no PEM data found")
+}
+
+func TestVerifyDetached(t *testing.T) {
+	signers := newSigners(t)
+
+	for name, s := range signers {
+		s := s
+
+		t.Run(name, func(t *testing.T) {
+			keys, err := imagesig.ParsePublicKeys(signers["ed25519"].pem, s.pem)
+			assert.NoError(t, err)
+
+			result, err := imagesig.VerifyDetached(keys, digest, s.sign([]byte(digest)))
+			assert.NoError(t, err)
+			assert.Equal(t, imagesig.MethodDetached, result.Method)
+			assert.Equal(t, keys[1].Fingerprint, result.Fingerprint)
+
+			_, err = imagesig.VerifyDetached(keys[1:], "sha256:0000", s.sign([]byte(digest)))
+			assert.True(t, errors.Is(err, imagesig.ErrVerification))
+
+			_, err = imagesig.VerifyDetached(keys, digest, nil)
+			assert.True(t, errors.Is(err, imagesig.ErrNoSignature))
+		})
+	}
+}
+
+func TestVerifyCosign(t *testing.T) {
+	s := newSigners(t)["ecdsa"]
+
+	keys, err := imagesig.ParsePublicKeys(s.pem)
+	assert.NoError(t, err)
+
+	signature := func(payloadType, repository, digest string) imagesig.CosignSignature {
+		payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":%q},"optional":null}`, repository, digest, payloadType))
+
+		return imagesig.CosignSignature{Payload: payload, Signature: base64.StdEncoding.EncodeToString(s.sign(payload))}
+	}
+
+	valid := signature("cosign container image signature", repository, digest)
+
+	tampered := valid
+	tampered.Payload = append([]byte(nil), valid.Payload...)
+	tampered.Payload[len(tampered.Payload)-2] = ' '
+
+	for _, tt := range []struct {
+		name       string
+		signatures []imagesig.CosignSignature
+		repository string
+		err        error
+	}{
+		{
+			name:       "valid",
+			signatures: []imagesig.CosignSignature{valid},
+		},
+		{
+			name:       "one of several",
+			signatures: []imagesig.CosignSignature{signature("cosign container image signature", repository, "sha256:0000"), valid},
+		},
+		{
+			name:       "docker hub",
+			signatures: []imagesig.CosignSignature{signature("cosign container image signature", "index.docker.io/autonomy/installer", digest)},
+			repository: "docker.io/autonomy/installer",
+		},
+		{
+			name:       "unsigned",
+			signatures: nil,
+			err:        imagesig.ErrNoSignature,
+		},
+		{
+			name:       "tampered",
+			signatures: []imagesig.CosignSignature{tampered},
+			err:        imagesig.ErrVerification,
+		},
+		{
+			name:       "other repository",
+			signatures: []imagesig.CosignSignature{signature("cosign container image signature", "docker.io/autonomy/installer", digest)},
+			err:        imagesig.ErrVerification,
+		},
+		{
+			name:       "other type",
+			signatures: []imagesig.CosignSignature{signature("atomic container signature", repository, digest)},
+			err:        imagesig.ErrVerification,
+		},
+		{
+			name:       "invalid encoding",
+			signatures: []imagesig.CosignSignature{{Payload: valid.Payload, Signature: "!"}},
+			err:        imagesig.ErrVerification,
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository
+			if tt.repository != "" {
+				repo = tt.repository
+			}
+
+			result, err := imagesig.VerifyCosign(keys, repo, digest, tt.signatures)
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err), "error = %v, want %v", err, tt.err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, imagesig.MethodCosign, result.Method)
+			assert.Equal(t, digest, result.Digest)
+		})
+	}
+}
+
+func TestCosignSignatureTag(t *testing.T) {
+	assert.Equal(t, "sha256-abc.sig", imagesig.CosignSignatureTag("sha256:abc"))
+}
@@ -0,0 +1,64 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package kexec loads a kernel for the running kernel to boot into, skipping
+// the firmware.
+//
+// See http://man7.org/linux/man-pages/man2/kexec_load.2.html.
+package kexec
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// loadedPath is the file reporting whether a kernel is loaded.
+const loadedPath = "/sys/kernel/kexec_loaded"
+
+// Load loads the kernel and the initramfs, with the kernel command line. The
+// loaded kernel is booted by rebooting with `unix.LINUX_REBOOT_CMD_KEXEC`.
+func Load(kernel, initramfs, cmdline string) error {
+	k, err := os.Open(kernel)
+	if err != nil {
+		return err
+	}
+
+	// nolint: errcheck
+	defer k.Close()
+
+	i, err := os.Open(initramfs)
+	if err != nil {
+		return err
+	}
+
+	// nolint: errcheck
+	defer i.Close()
+
+	// The length of the command line includes its terminating null byte.
+	p, err := unix.BytePtrFromString(cmdline)
+	if err != nil {
+		return err
+	}
+
+	if _, _, errno := unix.Syscall6(unix.SYS_KEXEC_FILE_LOAD, k.Fd(), i.Fd(), uintptr(len(cmdline)+1), uintptr(unsafe.Pointer(p)), 0, 0); errno != 0 {
+		return fmt.Errorf("kexec_file_load of %s failed: %w", kernel, errno)
+	}
+
+	return nil
+}
+
+// Loaded reports whether a kernel is loaded.
+func Loaded() bool {
+	b, err := ioutil.ReadFile(loadedPath)
+	if err != nil {
+		return false
+	}
+
+	return string(bytes.TrimSpace(b)) == "1"
+}
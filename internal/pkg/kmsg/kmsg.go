@@ -19,29 +19,36 @@ import (
 	"github.com/talos-systems/talos/pkg/constants"
 )
 
-// Setup configures the log package to write to the kernel ring buffer via
-// /dev/kmsg.
-func Setup(prefix string, withLogFile bool) error {
-	kmsg, err := os.OpenFile("/dev/kmsg", os.O_RDWR|unix.O_CLOEXEC|unix.O_NONBLOCK|unix.O_NOCTTY, 0666)
-	if err != nil {
-		return fmt.Errorf("failed to open /dev/kmsg: %w", err)
-	}
-
-	var writer io.Writer = &Writer{KmsgWriter: kmsg}
+// Option configures the logging to the kernel ring buffer.
+type Option func(o *options)
 
-	if withLogFile {
-		if err := os.MkdirAll(constants.DefaultLogPath, 0700); err != nil {
-			return err
-		}
+type options struct {
+	linesPerSecond int
+	stderr         bool
+}
 
-		logPath := filepath.Join(constants.DefaultLogPath, "machined.log")
+// WithRateLimit limits the lines written to the kernel ring buffer to the
+// rate, see RateLimitedWriter. The log file and stderr get all of the lines.
+// Zero means no limit.
+func WithRateLimit(linesPerSecond int) Option {
+	return func(o *options) {
+		o.linesPerSecond = linesPerSecond
+	}
+}
 
-		f, err := os.OpenFile(logPath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-		if err != nil {
-			return fmt.Errorf("failed to open %s: %w", logPath, err)
-		}
+// WithStderr writes the messages to stderr too.
+func WithStderr(stderr bool) Option {
+	return func(o *options) {
+		o.stderr = stderr
+	}
+}
 
-		writer = io.MultiWriter(writer, f)
+// Setup configures the log package to write to the kernel ring buffer via
+// /dev/kmsg.
+func Setup(prefix string, withLogFile bool, opts ...Option) error {
+	writer, err := newWriter(prefix, 0, withLogFile, opts)
+	if err != nil {
+		return err
 	}
 
 	log.SetOutput(writer)
@@ -53,32 +60,92 @@ func Setup(prefix string, withLogFile bool) error {
 
 // SetupLogger configures the logger to write to the kernel ring buffer via
 // /dev/kmsg.
-func SetupLogger(logger *log.Logger, prefix string, withLogFile bool) error {
+func SetupLogger(logger *log.Logger, prefix string, withLogFile bool, opts ...Option) error {
+	return SetupLoggerWithPriority(logger, prefix, 0, withLogFile, opts...)
+}
+
+// SetupLoggerWithPriority configures the logger to write to the kernel ring
+// buffer via /dev/kmsg, with the messages at the priority. The log file, if
+// any, gets the messages without it.
+func SetupLoggerWithPriority(logger *log.Logger, prefix string, priority Priority, withLogFile bool, opts ...Option) error {
+	writer, err := newWriter(prefix, priority, withLogFile, opts)
+	if err != nil {
+		return err
+	}
+
+	logger.SetOutput(writer)
+	logger.SetPrefix(prefix + " ")
+	logger.SetFlags(0)
+
+	return nil
+}
+
+// Flush writes what the logger set up with a rate limit holds back, i.e. the
+// number of the lines it dropped. It does nothing for the other loggers.
+func Flush(logger *log.Logger) error {
+	if f, ok := logger.Writer().(*flushWriter); ok {
+		return f.flush()
+	}
+
+	return nil
+}
+
+// flushWriter is the output of a logger with a rate limit.
+type flushWriter struct {
+	io.Writer
+	flush func() error
+}
+
+func newWriter(prefix string, priority Priority, withLogFile bool, opts []Option) (io.Writer, error) {
+	o := options{}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	kmsg, err := os.OpenFile("/dev/kmsg", os.O_RDWR|unix.O_CLOEXEC|unix.O_NONBLOCK|unix.O_NOCTTY, 0666)
 	if err != nil {
-		return fmt.Errorf("failed to open /dev/kmsg: %w", err)
+		return nil, fmt.Errorf("failed to open /dev/kmsg: %w", err)
+	}
+
+	var (
+		writer  io.Writer = &Writer{KmsgWriter: kmsg, Priority: priority}
+		limiter *RateLimitedWriter
+	)
+
+	if o.linesPerSecond > 0 {
+		limiter = &RateLimitedWriter{W: writer, LinesPerSecond: o.linesPerSecond, Prefix: prefix}
+		writer = limiter
 	}
 
-	var writer io.Writer = &Writer{KmsgWriter: kmsg}
+	writers := []io.Writer{writer}
 
 	if withLogFile {
 		if err := os.MkdirAll(constants.DefaultLogPath, 0700); err != nil {
-			return err
+			return nil, err
 		}
 
 		logPath := filepath.Join(constants.DefaultLogPath, "machined.log")
 
 		f, err := os.OpenFile(logPath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
 		if err != nil {
-			return fmt.Errorf("failed to open %s: %w", logPath, err)
+			return nil, fmt.Errorf("failed to open %s: %w", logPath, err)
 		}
 
-		writer = io.MultiWriter(writer, f)
+		writers = append(writers, f)
 	}
 
-	logger.SetOutput(writer)
-	logger.SetPrefix(prefix + " ")
-	logger.SetFlags(0)
+	if o.stderr {
+		writers = append(writers, os.Stderr)
+	}
 
-	return nil
+	if len(writers) > 1 {
+		writer = io.MultiWriter(writers...)
+	}
+
+	if limiter != nil {
+		writer = &flushWriter{Writer: writer, flush: limiter.Flush}
+	}
+
+	return writer, nil
 }
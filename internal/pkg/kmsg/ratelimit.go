@@ -0,0 +1,135 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package kmsg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// RateLimitedWriter limits the rate of the lines written to the kernel ring
+// buffer, so that a burst of lines does not evict the other messages of the
+// kernel log. The lines over the limit are dropped, and the number of dropped
+// lines is written in their place, ahead of the next line written, or by
+// Flush.
+type RateLimitedWriter struct {
+	W io.Writer
+	// LinesPerSecond is the rate of the lines written, and the size of the
+	// bursts allowed.
+	LinesPerSecond int
+	// Prefix prefixes the notices of the dropped lines, which are not written
+	// through a logger.
+	Prefix string
+
+	mu      sync.Mutex
+	tokens  float64
+	last    time.Time
+	dropped int
+
+	// now is the clock, which tests override.
+	now func() time.Time
+}
+
+// Write implements io.Writer interface. The dropped lines are reported as
+// written.
+func (w *RateLimitedWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i == -1 {
+			i = len(p) - 1
+		}
+
+		line := p[:i+1]
+		p = p[i+1:]
+
+		if !w.take() {
+			w.dropped++
+			n += len(line)
+
+			continue
+		}
+
+		if err = w.flush(); err != nil {
+			return n, err
+		}
+
+		var nn int
+
+		nn, err = w.W.Write(line)
+		n += nn
+
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// Flush writes the number of the lines dropped since the last line written,
+// if any.
+func (w *RateLimitedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.flush()
+}
+
+func (w *RateLimitedWriter) flush() error {
+	if w.dropped == 0 {
+		return nil
+	}
+
+	notice := fmt.Sprintf("%d line(s) dropped, over the limit of %d per second\n", w.dropped, w.LinesPerSecond)
+	if w.Prefix != "" {
+		notice = w.Prefix + " " + notice
+	}
+
+	w.dropped = 0
+
+	_, err := w.W.Write([]byte(notice))
+
+	return err
+}
+
+// take refills the bucket since the last line, and takes a line from it. It
+// reports whether the line is within the limit.
+func (w *RateLimitedWriter) take() bool {
+	if w.LinesPerSecond <= 0 {
+		return true
+	}
+
+	now := time.Now
+	if w.now != nil {
+		now = w.now
+	}
+
+	t := now()
+
+	if w.last.IsZero() {
+		w.tokens = float64(w.LinesPerSecond)
+	} else {
+		w.tokens += t.Sub(w.last).Seconds() * float64(w.LinesPerSecond)
+		if w.tokens > float64(w.LinesPerSecond) {
+			w.tokens = float64(w.LinesPerSecond)
+		}
+	}
+
+	w.last = t
+
+	if w.tokens < 1 {
+		return false
+	}
+
+	w.tokens--
+
+	return true
+}
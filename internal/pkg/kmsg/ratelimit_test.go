@@ -0,0 +1,77 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package kmsg
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitedWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	now := time.Now()
+
+	w := &RateLimitedWriter{
+		W:              &buf,
+		LinesPerSecond: 2,
+		Prefix:         "[talos]",
+		now:            func() time.Time { return now },
+	}
+
+	n, err := w.Write([]byte("a\nb\nc\n"))
+	assert.Equal(t, 6, n)
+	assert.NoError(t, err)
+
+	n, err = w.Write([]byte("d\n"))
+	assert.Equal(t, 2, n)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "a\nb\n", buf.String())
+
+	// Half a second refills a single line.
+	now = now.Add(500 * time.Millisecond)
+
+	_, err = w.Write([]byte("e\nf\n"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "a\nb\n[talos] 2 line(s) dropped, over the limit of 2 per second\ne\n", buf.String())
+
+	buf.Reset()
+
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "[talos] 1 line(s) dropped, over the limit of 2 per second\n", buf.String())
+
+	buf.Reset()
+
+	assert.NoError(t, w.Flush())
+	assert.Empty(t, buf.String())
+
+	// The bucket does not fill past a second of lines.
+	now = now.Add(time.Hour)
+
+	_, err = w.Write([]byte("g\nh\ni\n"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "g\nh\n[talos] 1 line(s) dropped, over the limit of 2 per second\n", buf.String())
+}
+
+func TestRateLimitedWriterUnlimited(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := &RateLimitedWriter{W: &buf}
+
+	for i := 0; i < 100; i++ {
+		_, err := w.Write([]byte("a\n"))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, 200, buf.Len())
+}
@@ -6,17 +6,36 @@ package kmsg
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 )
 
 // MaxLineLength to be passed to kmsg, see https://github.com/torvalds/linux/blob/master/kernel/printk/printk.c#L450.
 const MaxLineLength = 1024 - 48
 
+// Priority is the syslog level of the messages written to the kernel ring
+// buffer, see syslog(2). The kernel records the messages from userspace with
+// the user facility.
+type Priority int
+
+// Priorities used by userspace. The more severe ones are left to the kernel,
+// which allows the zero value to stand for its default level.
+const (
+	PriorityErr     Priority = 3
+	PriorityWarning Priority = 4
+	PriorityNotice  Priority = 5
+	PriorityInfo    Priority = 6
+	PriorityDebug   Priority = 7
+)
+
 // Writer ensures writes by line and limits each line to maxLineLength characters.
 //
 // This workarounds kmsg limits.
 type Writer struct {
 	KmsgWriter io.Writer
+	// Priority prefixes each line with the level if set, or else the kernel
+	// default level applies.
+	Priority Priority
 }
 
 // Write implements io.Writer interface.
@@ -33,13 +52,23 @@ func (w *Writer) Write(p []byte) (n int, err error) {
 			line = append(line[:MaxLineLength-4], []byte("...\n")...)
 		}
 
+		out := line
+
+		if w.Priority != 0 {
+			out = append([]byte(fmt.Sprintf("<%d>", w.Priority)), line...)
+		}
+
 		var nn int
-		nn, err = w.KmsgWriter.Write(line)
+		nn, err = w.KmsgWriter.Write(out)
+
+		// The prefix is not part of p.
+		prefix := len(out) - len(line)
 
-		if nn == len(line) {
+		switch {
+		case nn == len(out):
 			n += i + 1
-		} else {
-			n += nn
+		case nn > prefix:
+			n += nn - prefix
 		}
 
 		if err != nil {
@@ -56,3 +56,16 @@ func TestWriter(t *testing.T) {
 	assert.Equal(t, fakeW.lines[5], append(bytes.Repeat([]byte{0xce}, kmsg.MaxLineLength-4), '.', '.', '.', '\n'))
 	assert.Equal(t, fakeW.lines[6], []byte("ab\n"))
 }
+
+func TestWriterPriority(t *testing.T) {
+	fakeW := &fakeWriter{}
+	kmsgW := &kmsg.Writer{KmsgWriter: fakeW, Priority: kmsg.PriorityErr}
+
+	n, err := kmsgW.Write([]byte("foo\nbar\n"))
+	assert.Equal(t, 8, n)
+	assert.NoError(t, err)
+
+	assert.Len(t, fakeW.lines, 2)
+	assert.Equal(t, fakeW.lines[0], []byte("<3>foo\n"))
+	assert.Equal(t, fakeW.lines[1], []byte("<3>bar\n"))
+}
@@ -0,0 +1,85 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package watchdog provides access to the Linux hardware watchdog API.
+//
+// See https://www.kernel.org/doc/Documentation/watchdog/watchdog-api.txt.
+package watchdog
+
+import (
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// The ioctl requests of linux/watchdog.h.
+const (
+	wdiocSetTimeout = 0xc0045706
+	wdiocGetTimeout = 0x80045707
+)
+
+// Watchdog represents an open watchdog device. The watchdog is armed while
+// the device is open, and resets the machine if it is not pet within its
+// timeout.
+type Watchdog struct {
+	f *os.File
+}
+
+// Open opens, and so arms, the watchdog device at the path.
+func Open(path string) (*Watchdog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watchdog{f: f}, nil
+}
+
+// SetTimeout sets the timeout of the watchdog. Drivers round the timeout to
+// what the hardware supports, so the actual timeout is returned.
+func (w *Watchdog) SetTimeout(d time.Duration) (time.Duration, error) {
+	seconds := int32(d / time.Second)
+
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, w.f.Fd(), wdiocSetTimeout, uintptr(unsafe.Pointer(&seconds))); errno != 0 {
+		return 0, fmt.Errorf("WDIOC_SETTIMEOUT failed: %w", errno)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// Timeout returns the timeout of the watchdog.
+func (w *Watchdog) Timeout() (time.Duration, error) {
+	var seconds int32
+
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, w.f.Fd(), wdiocGetTimeout, uintptr(unsafe.Pointer(&seconds))); errno != 0 {
+		return 0, fmt.Errorf("WDIOC_GETTIMEOUT failed: %w", errno)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// Pet resets the countdown of the watchdog.
+func (w *Watchdog) Pet() error {
+	_, err := w.f.Write([]byte{0})
+
+	return err
+}
+
+// Disarm stops the watchdog, and closes the device. It writes the magic
+// character first, since drivers that support magic close keep the watchdog
+// armed if the device is closed without it. A driver with the `nowayout`
+// option can not be disarmed.
+func (w *Watchdog) Disarm() error {
+	if _, err := w.f.Write([]byte("V")); err != nil {
+		// nolint: errcheck
+		w.f.Close()
+
+		return err
+	}
+
+	return w.f.Close()
+}
@@ -0,0 +1,58 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package watchdog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watchdog")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nolint: errcheck
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "watchdog")
+
+	if err = ioutil.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A regular file does not support the watchdog ioctls.
+	if _, err = w.SetTimeout(time.Minute); err == nil {
+		t.Error("SetTimeout() on a regular file should fail")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err = w.Pet(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = w.Disarm(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "\x00\x00V" {
+		t.Errorf("written = %q, want two keepalives and the magic character", b)
+	}
+}
@@ -245,12 +245,118 @@ func (c *Client) Reset(ctx context.Context, graceful, reboot bool) (err error) {
 	return
 }
 
+// ResetPreserving resets the node like Reset, preserving the partitions of the
+// system disk given by label or by mount point, and wiping the others.
+func (c *Client) ResetPreserving(ctx context.Context, graceful, reboot bool, preserve []string) (err error) {
+	_, err = c.MachineClient.Reset(ctx, &machineapi.ResetRequest{Graceful: graceful, Reboot: reboot, Preserve: preserve})
+	return
+}
+
 // Reboot implements the proto.OSClient interface.
 func (c *Client) Reboot(ctx context.Context) (err error) {
 	_, err = c.MachineClient.Reboot(ctx, &empty.Empty{})
 	return
 }
 
+// RunPhase runs a single phase of the specified sequence in isolation. This is
+// a debugging aid which requires debug to be enabled in the machine config.
+func (c *Client) RunPhase(ctx context.Context, sequence, phase string, callOptions ...grpc.CallOption) (resp *machineapi.RunPhaseResponse, err error) {
+	resp, err = c.MachineClient.RunPhase(
+		ctx,
+		&machineapi.RunPhaseRequest{Sequence: sequence, Phase: phase},
+		callOptions...,
+	)
+
+	var filtered interface{}
+	filtered, err = FilterMessages(resp, err)
+	resp, _ = filtered.(*machineapi.RunPhaseResponse) //nolint: errcheck
+
+	return
+}
+
+// RunSequence runs the sequence named by the request, with the params of the
+// sequence set in the request, if any.
+func (c *Client) RunSequence(ctx context.Context, req *machineapi.RunSequenceRequest, callOptions ...grpc.CallOption) (resp *machineapi.RunSequenceResponse, err error) {
+	resp, err = c.MachineClient.RunSequence(ctx, req, callOptions...)
+
+	var filtered interface{}
+	filtered, err = FilterMessages(resp, err)
+	resp, _ = filtered.(*machineapi.RunSequenceResponse) //nolint: errcheck
+
+	return
+}
+
+// AbortSequence aborts the sequence in progress, if it has not passed its
+// point of no return.
+func (c *Client) AbortSequence(ctx context.Context, callOptions ...grpc.CallOption) (resp *machineapi.AbortSequenceResponse, err error) {
+	resp, err = c.MachineClient.AbortSequence(ctx, &empty.Empty{}, callOptions...)
+
+	var filtered interface{}
+	filtered, err = FilterMessages(resp, err)
+	resp, _ = filtered.(*machineapi.AbortSequenceResponse) //nolint: errcheck
+
+	return
+}
+
+// StepSequence resumes the sequence paused before a phase, or enables or
+// disables the single-step mode, which requires debug to be enabled in the
+// config of the node.
+func (c *Client) StepSequence(ctx context.Context, action machineapi.StepSequenceAction, callOptions ...grpc.CallOption) (resp *machineapi.StepSequenceResponse, err error) {
+	resp, err = c.MachineClient.StepSequence(ctx, &machineapi.StepSequenceRequest{Action: action}, callOptions...)
+
+	var filtered interface{}
+	filtered, err = FilterMessages(resp, err)
+	resp, _ = filtered.(*machineapi.StepSequenceResponse) //nolint: errcheck
+
+	return
+}
+
+// SequenceResult returns the timing breakdown of the most recent sequence.
+func (c *Client) SequenceResult(ctx context.Context, callOptions ...grpc.CallOption) (resp *machineapi.SequenceResultResponse, err error) {
+	resp, err = c.MachineClient.SequenceResult(ctx, &empty.Empty{}, callOptions...)
+
+	var filtered interface{}
+	filtered, err = FilterMessages(resp, err)
+	resp, _ = filtered.(*machineapi.SequenceResultResponse) //nolint: errcheck
+
+	return
+}
+
+// SequenceHistory returns the timing breakdowns of the most recent sequences,
+// the oldest first.
+func (c *Client) SequenceHistory(ctx context.Context, callOptions ...grpc.CallOption) (resp *machineapi.SequenceHistoryResponse, err error) {
+	resp, err = c.MachineClient.SequenceHistory(ctx, &empty.Empty{}, callOptions...)
+
+	var filtered interface{}
+	filtered, err = FilterMessages(resp, err)
+	resp, _ = filtered.(*machineapi.SequenceHistoryResponse) //nolint: errcheck
+
+	return
+}
+
+// Sequences returns the phases and tasks of all the sequences of the node.
+func (c *Client) Sequences(ctx context.Context, callOptions ...grpc.CallOption) (resp *machineapi.SequencesResponse, err error) {
+	resp, err = c.MachineClient.Sequences(ctx, &empty.Empty{}, callOptions...)
+
+	var filtered interface{}
+	filtered, err = FilterMessages(resp, err)
+	resp, _ = filtered.(*machineapi.SequencesResponse) //nolint: errcheck
+
+	return
+}
+
+// Preflight runs the pre-flight checks of the sequence named by the request,
+// without running the sequence.
+func (c *Client) Preflight(ctx context.Context, req *machineapi.RunSequenceRequest, callOptions ...grpc.CallOption) (resp *machineapi.PreflightResponse, err error) {
+	resp, err = c.MachineClient.Preflight(ctx, req, callOptions...)
+
+	var filtered interface{}
+	filtered, err = FilterMessages(resp, err)
+	resp, _ = filtered.(*machineapi.PreflightResponse) //nolint: errcheck
+
+	return
+}
+
 // Shutdown implements the proto.OSClient interface.
 func (c *Client) Shutdown(ctx context.Context) (err error) {
 	_, err = c.MachineClient.Shutdown(ctx, &empty.Empty{})
@@ -278,6 +384,11 @@ func (c *Client) Logs(ctx context.Context, namespace string, driver common.Conta
 	return
 }
 
+// EventsStream implements the proto.OSClient interface.
+func (c *Client) EventsStream(ctx context.Context) (stream machineapi.MachineService_EventsStreamClient, err error) {
+	return c.MachineClient.EventsStream(ctx, &machineapi.EventsRequest{})
+}
+
 // Version implements the proto.OSClient interface.
 func (c *Client) Version(ctx context.Context, callOptions ...grpc.CallOption) (resp *machineapi.VersionResponse, err error) {
 	resp, err = c.MachineClient.Version(
@@ -388,7 +499,7 @@ func (c *Client) Copy(ctx context.Context, rootPath string) (io.ReadCloser, <-ch
 // Upgrade initiates a Talos upgrade ... and implements the proto.OSClient
 // interface
 func (c *Client) Upgrade(ctx context.Context, image string, preserve bool, callOptions ...grpc.CallOption) (resp *machineapi.UpgradeResponse, err error) {
-	resp, err = c.MachineClient.Upgrade(
+	return c.UpgradeWithRequest(
 		ctx,
 		&machineapi.UpgradeRequest{
 			Image:    image,
@@ -396,6 +507,30 @@ func (c *Client) Upgrade(ctx context.Context, image string, preserve bool, callO
 		},
 		callOptions...,
 	)
+}
+
+// StageUpgrade stages a Talos upgrade to perform it on the next reboot. The
+// data is preserved, since a staged upgrade can not drain the node.
+func (c *Client) StageUpgrade(ctx context.Context, image string, callOptions ...grpc.CallOption) (resp *machineapi.UpgradeResponse, err error) {
+	return c.UpgradeWithRequest(
+		ctx,
+		&machineapi.UpgradeRequest{
+			Image:    image,
+			Preserve: true,
+			Stage:    true,
+		},
+		callOptions...,
+	)
+}
+
+// UpgradeWithRequest initiates a Talos upgrade with all request options (e.g.
+// the signature of the image) applied
+func (c *Client) UpgradeWithRequest(ctx context.Context, req *machineapi.UpgradeRequest, callOptions ...grpc.CallOption) (resp *machineapi.UpgradeResponse, err error) {
+	resp, err = c.MachineClient.Upgrade(
+		ctx,
+		req,
+		callOptions...,
+	)
 
 	var filtered interface{}
 	filtered, err = FilterMessages(resp, err)
@@ -507,9 +642,15 @@ func (c *Client) ServiceRestart(ctx context.Context, id string, callOptions ...g
 
 // Time returns the time
 func (c *Client) Time(ctx context.Context, callOptions ...grpc.CallOption) (resp *timeapi.TimeResponse, err error) {
+	return c.TimeFormatted(ctx, nil, callOptions...)
+}
+
+// TimeFormatted returns the time, additionally rendered in the specified
+// formats
+func (c *Client) TimeFormatted(ctx context.Context, formats []timeapi.TimeFormat, callOptions ...grpc.CallOption) (resp *timeapi.TimeResponse, err error) {
 	resp, err = c.TimeClient.Time(
 		ctx,
-		&empty.Empty{},
+		&timeapi.TimeFormatRequest{Formats: formats},
 		callOptions...,
 	)
 
@@ -522,9 +663,22 @@ func (c *Client) Time(ctx context.Context, callOptions ...grpc.CallOption) (resp
 
 // TimeCheck returns the time compared to the specified ntp server
 func (c *Client) TimeCheck(ctx context.Context, server string, callOptions ...grpc.CallOption) (resp *timeapi.TimeResponse, err error) {
+	return c.TimeCheckFormatted(ctx, server, nil, callOptions...)
+}
+
+// TimeCheckFormatted returns the time compared to the specified ntp server,
+// additionally rendered in the specified formats
+func (c *Client) TimeCheckFormatted(ctx context.Context, server string, formats []timeapi.TimeFormat, callOptions ...grpc.CallOption) (resp *timeapi.TimeResponse, err error) {
+	return c.TimeCheckWithRequest(ctx, &timeapi.TimeRequest{Server: server, Formats: formats}, callOptions...)
+}
+
+// TimeCheckWithRequest returns the time compared to the ntp server specified
+// in the request, with all request options (e.g. verbose packet details)
+// applied
+func (c *Client) TimeCheckWithRequest(ctx context.Context, req *timeapi.TimeRequest, callOptions ...grpc.CallOption) (resp *timeapi.TimeResponse, err error) {
 	resp, err = c.TimeClient.TimeCheck(
 		ctx,
-		&timeapi.TimeRequest{Server: server},
+		req,
 		callOptions...,
 	)
 
@@ -535,6 +689,43 @@ func (c *Client) TimeCheck(ctx context.Context, server string, callOptions ...gr
 	return
 }
 
+// SyncTolerance returns the time sync tolerance, the last measured offset, and
+// whether the offset is within the tolerance
+func (c *Client) SyncTolerance(ctx context.Context, callOptions ...grpc.CallOption) (resp *timeapi.SyncToleranceResponse, err error) {
+	resp, err = c.TimeClient.SyncTolerance(
+		ctx,
+		&empty.Empty{},
+		callOptions...,
+	)
+
+	var filtered interface{}
+	filtered, err = FilterMessages(resp, err)
+	resp, _ = filtered.(*timeapi.SyncToleranceResponse) //nolint: errcheck
+
+	return
+}
+
+// TimeReady returns whether the initial time sync completed
+func (c *Client) TimeReady(ctx context.Context, callOptions ...grpc.CallOption) (resp *timeapi.TimeReadyResponse, err error) {
+	resp, err = c.TimeClient.TimeReady(
+		ctx,
+		&empty.Empty{},
+		callOptions...,
+	)
+
+	var filtered interface{}
+	filtered, err = FilterMessages(resp, err)
+	resp, _ = filtered.(*timeapi.TimeReadyResponse) //nolint: errcheck
+
+	return
+}
+
+// WatchTimeSync streams the time sync state, starting with the current state
+// and followed by the state after every sync
+func (c *Client) WatchTimeSync(ctx context.Context) (stream timeapi.TimeService_WatchTimeSyncClient, err error) {
+	return c.TimeClient.WatchTimeSync(ctx, &empty.Empty{})
+}
+
 // Read reads a file.
 func (c *Client) Read(ctx context.Context, path string) (io.ReadCloser, <-chan error, error) {
 	stream, err := c.MachineClient.Read(ctx, &machineapi.ReadRequest{Path: path})
@@ -176,6 +176,70 @@ func (m *MachineConfig) Registries() runtime.Registries {
 	return &m.MachineRegistries
 }
 
+// WaitFor implements the Configurator interface.
+func (m *MachineConfig) WaitFor() runtime.WaitFor {
+	if m.MachineWaitFor == nil {
+		return &WaitForConfig{}
+	}
+
+	return m.MachineWaitFor
+}
+
+// HealthCheck implements the Configurator interface.
+func (m *MachineConfig) HealthCheck() runtime.HealthCheck {
+	if m.MachineHealthCheck == nil {
+		return &HealthCheckConfig{}
+	}
+
+	return m.MachineHealthCheck
+}
+
+// Sequences implements the Configurator interface.
+func (m *MachineConfig) Sequences() runtime.Sequences {
+	return m.MachineSequences
+}
+
+// TaskTimeouts implements the Configurator interface.
+func (m *MachineConfig) TaskTimeouts() map[string]time.Duration {
+	return m.MachineTaskTimeouts
+}
+
+// Shutdown implements the Configurator interface.
+func (m *MachineConfig) Shutdown() runtime.Shutdown {
+	if m.MachineShutdown == nil {
+		return &ShutdownConfig{}
+	}
+
+	return m.MachineShutdown
+}
+
+// Outcomes implements the Configurator interface.
+func (m *MachineConfig) Outcomes() runtime.Outcomes {
+	if m.MachineOutcomes == nil {
+		return &OutcomesConfig{}
+	}
+
+	return m.MachineOutcomes
+}
+
+// MaxParallelTasks implements the Configurator interface.
+func (s SequencesConfig) MaxParallelTasks(seq runtime.Sequence) int {
+	if cfg, ok := s[seq.String()]; ok && cfg != nil {
+		return cfg.SequenceMaxParallelTasks
+	}
+
+	return 0
+}
+
+// Timeout implements the Configurator interface.
+func (s SequencesConfig) Timeout(seq runtime.Sequence) time.Duration {
+	if cfg, ok := s[seq.String()]; ok && cfg != nil {
+		return cfg.SequenceTimeout
+	}
+
+	return 0
+}
+
 // Image implements the Configurator interface.
 func (k *KubeletConfig) Image() string {
 	image := k.KubeletImage
@@ -514,6 +578,308 @@ func (t *TimeConfig) Servers() []string {
 	return t.TimeServers
 }
 
+// Tolerance implements the Configurator interface.
+func (t *TimeConfig) Tolerance() time.Duration {
+	if t.TimeTolerance == 0 {
+		return constants.DefaultTimeSyncTolerance
+	}
+
+	return t.TimeTolerance
+}
+
+// MinPoll implements the Configurator interface.
+func (t *TimeConfig) MinPoll() time.Duration {
+	if t.TimeMinPoll == 0 {
+		return constants.DefaultTimeMinPoll
+	}
+
+	return t.TimeMinPoll
+}
+
+// MaxPoll implements the Configurator interface.
+func (t *TimeConfig) MaxPoll() time.Duration {
+	if t.TimeMaxPoll == 0 {
+		return constants.DefaultTimeMaxPoll
+	}
+
+	return t.TimeMaxPoll
+}
+
+// DriftFile implements the Configurator interface.
+func (t *TimeConfig) DriftFile() string {
+	if t.TimeDriftFile == "" {
+		return constants.DefaultTimeDriftFile
+	}
+
+	return t.TimeDriftFile
+}
+
+// PHC implements the Configurator interface.
+func (t *TimeConfig) PHC() string {
+	return t.TimePHC
+}
+
+// SanityCheckURL implements the Configurator interface.
+func (t *TimeConfig) SanityCheckURL() string {
+	return t.TimeSanityCheckURL
+}
+
+// SanityCheckRequired implements the Configurator interface.
+func (t *TimeConfig) SanityCheckRequired() bool {
+	return t.TimeSanityCheckRequired
+}
+
+// DriftWarningThreshold implements the Configurator interface.
+func (t *TimeConfig) DriftWarningThreshold() float64 {
+	if t.TimeDriftWarningThreshold == nil {
+		return constants.DefaultTimeDriftWarningThreshold
+	}
+
+	return *t.TimeDriftWarningThreshold
+}
+
+// SanityCheckThreshold implements the Configurator interface.
+func (t *TimeConfig) SanityCheckThreshold() time.Duration {
+	if t.TimeSanityCheckThreshold == 0 {
+		return constants.DefaultTimeSanityCheckThreshold
+	}
+
+	return t.TimeSanityCheckThreshold
+}
+
+// WaitForSync implements the Configurator interface.
+func (t *TimeConfig) WaitForSync() bool {
+	return t.TimeWaitForSync
+}
+
+// WaitForSyncTimeout implements the Configurator interface.
+func (t *TimeConfig) WaitForSyncTimeout() time.Duration {
+	if t.TimeWaitForSyncTimeout == 0 {
+		return constants.DefaultTimeWaitForSyncTimeout
+	}
+
+	return t.TimeWaitForSyncTimeout
+}
+
+// GracePeriod implements the Configurator interface.
+func (s *ShutdownConfig) GracePeriod() time.Duration {
+	if s.ShutdownGracePeriod == 0 {
+		return constants.DefaultShutdownGracePeriod
+	}
+
+	return s.ShutdownGracePeriod
+}
+
+// SkipDrain implements the Configurator interface.
+func (s *ShutdownConfig) SkipDrain() bool {
+	return s.ShutdownSkipDrain
+}
+
+// DrainTimeout implements the Configurator interface.
+func (s *ShutdownConfig) DrainTimeout() time.Duration {
+	if s.ShutdownDrainTimeout == 0 {
+		return constants.DefaultShutdownDrainTimeout
+	}
+
+	return s.ShutdownDrainTimeout
+}
+
+// SyncTimeout implements the Configurator interface.
+func (s *ShutdownConfig) SyncTimeout() time.Duration {
+	if s.ShutdownSyncTimeout == 0 {
+		return constants.DefaultShutdownSyncTimeout
+	}
+
+	return s.ShutdownSyncTimeout
+}
+
+// UnmountTimeout implements the Configurator interface.
+func (s *ShutdownConfig) UnmountTimeout() time.Duration {
+	if s.ShutdownUnmountTimeout == 0 {
+		return constants.DefaultShutdownUnmountTimeout
+	}
+
+	return s.ShutdownUnmountTimeout
+}
+
+// Kexec implements the Configurator interface.
+func (s *ShutdownConfig) Kexec() bool {
+	return s.ShutdownKexec
+}
+
+// ACPI implements the Configurator interface.
+func (m *MachineConfig) ACPI() runtime.ACPI {
+	if m.MachineACPI == nil {
+		return &ACPIConfig{}
+	}
+
+	return m.MachineACPI
+}
+
+// Watchdog implements the Configurator interface.
+func (m *MachineConfig) Watchdog() runtime.Watchdog {
+	return m.MachineWatchdog
+}
+
+// Enabled implements the Configurator interface.
+func (w *WatchdogConfig) Enabled() bool {
+	return w != nil
+}
+
+// Device implements the Configurator interface.
+func (w *WatchdogConfig) Device() string {
+	if w == nil || w.WatchdogDevice == "" {
+		return constants.DefaultWatchdogDevice
+	}
+
+	return w.WatchdogDevice
+}
+
+// Timeout implements the Configurator interface.
+func (w *WatchdogConfig) Timeout() time.Duration {
+	if w == nil || w.WatchdogTimeout == 0 {
+		return constants.DefaultWatchdogTimeout
+	}
+
+	return w.WatchdogTimeout
+}
+
+// Maintenance implements the Configurator interface.
+func (m *MachineConfig) Maintenance() runtime.Maintenance {
+	if m.MachineMaintenance == nil {
+		return &MaintenanceConfig{}
+	}
+
+	return m.MachineMaintenance
+}
+
+// Extensions implements the Configurator interface.
+func (m *MachineConfig) Extensions() []runtime.Extension {
+	extensions := make([]runtime.Extension, 0, len(m.MachineExtensions))
+
+	for _, ext := range m.MachineExtensions {
+		extensions = append(extensions, ext)
+	}
+
+	return extensions
+}
+
+// TaskLogs implements the Configurator interface.
+func (m *MachineConfig) TaskLogs() runtime.TaskLogs {
+	if m.MachineTaskLogs == nil {
+		return &TaskLogsConfig{}
+	}
+
+	return m.MachineTaskLogs
+}
+
+// Name implements the Configurator interface.
+func (e *ExtensionConfig) Name() string {
+	return e.ExtensionName
+}
+
+// Point implements the Configurator interface.
+func (e *ExtensionConfig) Point() string {
+	return e.ExtensionPoint
+}
+
+// Command implements the Configurator interface.
+func (e *ExtensionConfig) Command() []string {
+	return e.ExtensionCommand
+}
+
+// Env implements the Configurator interface.
+func (e *ExtensionConfig) Env() runtime.Env {
+	return e.ExtensionEnv
+}
+
+// Timeout implements the Configurator interface.
+func (e *ExtensionConfig) Timeout() time.Duration {
+	return e.ExtensionTimeout
+}
+
+// StopServices implements the Configurator interface.
+func (m *MaintenanceConfig) StopServices() []string {
+	if len(m.MaintenanceStopServices) == 0 {
+		return []string{"kubelet", "cri"}
+	}
+
+	return m.MaintenanceStopServices
+}
+
+// SkipDrain implements the Configurator interface.
+func (m *MaintenanceConfig) SkipDrain() bool {
+	return m.MaintenanceSkipDrain
+}
+
+// RateLimit implements the Configurator interface.
+func (t *TaskLogsConfig) RateLimit() int {
+	return t.TaskLogsRateLimit
+}
+
+// Stderr implements the Configurator interface.
+func (t *TaskLogsConfig) Stderr() bool {
+	return t.TaskLogsStderr
+}
+
+// Webhook implements the Configurator interface.
+func (o *OutcomesConfig) Webhook() string {
+	return o.OutcomesWebhook
+}
+
+// Actions implements the Configurator interface.
+func (a *ACPIConfig) Actions() map[string]runtime.ACPIAction {
+	actions := make(map[string]runtime.ACPIAction, len(a.ACPIActions))
+
+	for event, action := range a.ACPIActions {
+		actions[event] = runtime.ACPIAction(action)
+	}
+
+	return actions
+}
+
+// Endpoints implements the Configurator interface.
+func (w *WaitForConfig) Endpoints() []string {
+	return w.WaitForEndpoints
+}
+
+// Timeout implements the Configurator interface.
+func (w *WaitForConfig) Timeout() time.Duration {
+	if w.WaitForTimeout == 0 {
+		return constants.DefaultWaitForTimeout
+	}
+
+	return w.WaitForTimeout
+}
+
+// OnTimeout implements the Configurator interface.
+func (w *WaitForConfig) OnTimeout() runtime.WaitForAction {
+	if w.WaitForOnTimeout == "" {
+		return runtime.WaitForActionFail
+	}
+
+	return runtime.WaitForAction(w.WaitForOnTimeout)
+}
+
+// Skip implements the Configurator interface.
+func (h *HealthCheckConfig) Skip() bool {
+	return h.HealthCheckSkip
+}
+
+// Services implements the Configurator interface.
+func (h *HealthCheckConfig) Services() []string {
+	return h.HealthCheckServices
+}
+
+// Timeout implements the Configurator interface.
+func (h *HealthCheckConfig) Timeout() time.Duration {
+	if h.HealthCheckTimeout == 0 {
+		return constants.DefaultHealthCheckTimeout
+	}
+
+	return h.HealthCheckTimeout
+}
+
 // Image implements the Configurator interface.
 func (i *InstallConfig) Image() string {
 	return i.InstallImage
@@ -544,6 +910,34 @@ func (i *InstallConfig) WithBootloader() bool {
 	return i.InstallBootloader
 }
 
+// Existing implements the Configurator interface.
+func (i *InstallConfig) Existing() runtime.InstallExistingAction {
+	if i.InstallExisting == "" {
+		return runtime.InstallExistingActionSkip
+	}
+
+	return runtime.InstallExistingAction(i.InstallExisting)
+}
+
+// WaitForUSB implements the Configurator interface.
+func (i *InstallConfig) WaitForUSB() bool {
+	if i.InstallWaitForUSB == nil {
+		return true
+	}
+
+	return *i.InstallWaitForUSB
+}
+
+// USBDelay implements the Configurator interface.
+func (i *InstallConfig) USBDelay() time.Duration {
+	return i.InstallUSBDelay
+}
+
+// ImageVerificationKeys implements the Configurator interface.
+func (i *InstallConfig) ImageVerificationKeys() []string {
+	return i.InstallImageVerificationKeys
+}
+
 // Image implements the Configurator interface.
 func (c *CoreDNS) Image() string {
 	coreDNSImage := asset.DefaultImages.CoreDNS
@@ -233,6 +233,120 @@ type MachineConfig struct {
 	//             auth: ...
 	//             identityToken: ...
 	MachineRegistries RegistriesConfig `yaml:"registries,omitempty"`
+	//   description: |
+	//     Used to require that endpoints are reachable before services are started.
+	//     Endpoints with an `http` or `https` scheme must respond with a 2xx status code, all other endpoints are checked with a TCP connect to `host:port`.
+	//     The value of `onTimeout` can be `fail` (the default), or `maintenance`, which leaves the machine API running without starting services.
+	//   examples:
+	//     - |
+	//       waitFor:
+	//         endpoints:
+	//           - 10.0.0.10:6443
+	//           - https://10.0.0.10:2379/health
+	//         timeout: 5m
+	//         onTimeout: maintenance
+	MachineWaitFor *WaitForConfig `yaml:"waitFor,omitempty"`
+	//   description: |
+	//     Used to wait for services to become healthy at the end of the boot sequence.
+	//     The boot sequence completes degraded if they are not healthy in time, reporting the services which are not.
+	//   examples:
+	//     - |
+	//       healthCheck:
+	//         services:
+	//           - kubelet
+	//           - etcd
+	//         timeout: 10m
+	MachineHealthCheck *HealthCheckConfig `yaml:"healthCheck,omitempty"`
+	//   description: |
+	//     Used to tune the execution of sequences, keyed by sequence name (e.g. `boot`, `install`).
+	//   examples:
+	//     - |
+	//       sequences:
+	//         install:
+	//           maxParallelTasks: 1
+	//         boot:
+	//           timeout: 30m
+	MachineSequences SequencesConfig `yaml:"sequences,omitempty"`
+	//   description: |
+	//     Used to bound the time of tasks, keyed by task name (e.g. `Install`), in every sequence that runs them.
+	//     A task which does not complete in time fails.
+	//     The timeout replaces the built-in timeout of the task, if any (e.g. `shutdown.unmountTimeout` of the unmount tasks), and may be longer or shorter.
+	//     Field format accepts any Go time.Duration format ('30s', '1m').
+	//   examples:
+	//     - |
+	//       taskTimeouts:
+	//         Install: 30m
+	//         UnmountEphemeralPartition: 5m
+	MachineTaskTimeouts map[string]time.Duration `yaml:"taskTimeouts,omitempty"`
+	//   description: |
+	//     Used to bound the time spent in each stage of a shutdown or reboot.
+	//   examples:
+	//     - |
+	//       shutdown:
+	//         drainTimeout: 15m
+	//         unmountTimeout: 10s
+	MachineShutdown *ShutdownConfig `yaml:"shutdown,omitempty"`
+	//   description: |
+	//     Used to report the outcome of each sequence (e.g. `boot`, `upgrade`) to an external system.
+	//     Reporting is best-effort, and never delays the machine.
+	//   examples:
+	//     - |
+	//       outcomes:
+	//         webhook: https://fleet.example.com/outcomes
+	MachineOutcomes *OutcomesConfig `yaml:"outcomes,omitempty"`
+	//   description: |
+	//     Used to configure the response to ACPI events.
+	//   examples:
+	//     - |
+	//       acpi:
+	//         actions:
+	//           button/lid: shutdown
+	//           thermal_zone: maintenance
+	MachineACPI *ACPIConfig `yaml:"acpi,omitempty"`
+	//   description: |
+	//     Used to keep a hardware watchdog from resetting the machine during long sequences (e.g. `install`, `upgrade`).
+	//     When set, the watchdog is pet while a sequence runs, and disarmed once it completes.
+	//   examples:
+	//     - |
+	//       watchdog:
+	//         device: /dev/watchdog0
+	//         timeout: 2m
+	MachineWatchdog *WatchdogConfig `yaml:"watchdog,omitempty"`
+	//   description: |
+	//     Used to choose the services that the `maintenance` sequence stops, and the `resume` sequence starts again.
+	//     The machine API (`apid`, `osd`), the network (`networkd`, `routerd`) and `containerd` keep running in maintenance.
+	//   examples:
+	//     - |
+	//       maintenance:
+	//         stopServices:
+	//           - kubelet
+	//           - cri
+	//           - etcd
+	MachineMaintenance *MaintenanceConfig `yaml:"maintenance,omitempty"`
+	//   description: |
+	//     Used to hook site specific commands into the sequences, without forking Talos.
+	//     Each command runs at its extension point, with an explicit `PATH` and none of the environment of `machined`.
+	//     A command that fails is reported with the name of the extension, and does not fail the sequence.
+	//     The extensions are registered once the config is loaded at boot, so the ones of a config applied later take effect on the next boot.
+	//   examples:
+	//     - |
+	//       extensions:
+	//         - name: firmware
+	//           point: before-services
+	//           command:
+	//             - /var/lib/firmware/update
+	//             - --quiet
+	//           timeout: 5m
+	MachineExtensions []*ExtensionConfig `yaml:"extensions,omitempty"`
+	//   description: |
+	//     Used to bound the output of the tasks in the kernel log, so that a chatty task does not evict the other messages from the ring buffer.
+	//     The lines over the limit are dropped from the kernel log only, and are still written to the log file.
+	//   examples:
+	//     - |
+	//       taskLogs:
+	//         rateLimit: 50
+	//         stderr: true
+	MachineTaskLogs *TaskLogsConfig `yaml:"taskLogs,omitempty"`
 }
 
 // ClusterConfig reperesents the cluster-wide config values
@@ -505,6 +619,46 @@ type InstallConfig struct {
 	//     - false
 	//     - no
 	InstallForce bool `yaml:"force"`
+	//   description: |
+	//     The action taken when the install disk already contains an installation.
+	//     Defaults to `skip`, unless `force` is set.
+	//   values:
+	//     - abort
+	//     - skip
+	//     - force
+	InstallExisting string `yaml:"existing,omitempty"`
+	//   description: |
+	//     Indicates if the boot waits for USB storage to settle, in case the install disk is
+	//     attached over USB.
+	//     It applies once the config is loaded, to the machines which are not installed yet.
+	//     The boot of an installed machine whose disk is not found waits for the delay of the
+	//     `usb-storage` kernel module, or the `TALOS_USB_DELAY` environment variable, as its config
+	//     is on that disk.
+	//     Defaults to `true`.
+	//   values:
+	//     - true
+	//     - yes
+	//     - false
+	//     - no
+	InstallWaitForUSB *bool `yaml:"waitForUSB,omitempty"`
+	//   description: |
+	//     The time to wait for USB storage to settle.
+	//     Defaults to the `delay_use` parameter of the `usb-storage` kernel module.
+	//     Field format accepts any Go time.Duration format ('5s', '500ms').
+	InstallUSBDelay time.Duration `yaml:"usbDelay,omitempty"`
+	//   description: |
+	//     The PEM encoded public keys that the installer images of the upgrades are verified against.
+	//     When set, an upgrade only proceeds if the image has a detached signature passed with the upgrade
+	//     request, or a cosign signature stored in its registry, that verifies with one of the keys.
+	//     ECDSA, Ed25519 and RSA keys are supported.
+	//   examples:
+	//     - |
+	//       imageVerificationKeys:
+	//         - |
+	//           -----BEGIN PUBLIC KEY-----
+	//           ...
+	//           -----END PUBLIC KEY-----
+	InstallImageVerificationKeys []string `yaml:"imageVerificationKeys,omitempty"`
 }
 
 // TimeConfig represents the options for configuring time on a node.
@@ -513,8 +667,245 @@ type TimeConfig struct {
 	//     Specifies time (ntp) servers to use for setting system time.
 	//     Defaults to `pool.ntp.org`
 	//
-	//     > Note: This parameter only supports a single time server
+	//     When several servers are specified, all of them are queried, servers whose clock offset
+	//     differs from the median by more than the tolerance are discarded, and the server with the
+	//     lowest round-trip delay among the others is used.
 	TimeServers []string `yaml:"servers,omitempty"`
+	//   description: |
+	//     The maximum clock offset from the time server for the machine to be considered in sync.
+	//     Defaults to `1s`.
+	//     Field format accepts any Go time.Duration format ('500ms', '1s').
+	TimeTolerance time.Duration `yaml:"tolerance,omitempty"`
+	//   description: |
+	//     The lower bound of the adaptive poll interval, in whole seconds.
+	//     Defaults to `64s`, and must be at least `4s`.
+	//     Field format accepts any Go time.Duration format ('64s', '1m').
+	TimeMinPoll time.Duration `yaml:"minPoll,omitempty"`
+	//   description: |
+	//     The upper bound of the adaptive poll interval, in whole seconds.
+	//     Defaults to `1024s`, which is also the maximum.
+	//     Field format accepts any Go time.Duration format ('1024s', '1h').
+	TimeMaxPoll time.Duration `yaml:"maxPoll,omitempty"`
+	//   description: |
+	//     The file the frequency correction of the clock is saved to, so that it is restored on boot.
+	//     It must be in `/var/lib/talos`, or below, since its directory is mounted read-write into `timed`.
+	//     Defaults to `/var/lib/talos/ntp.drift`.
+	TimeDriftFile string `yaml:"driftFile,omitempty"`
+	//   description: |
+	//     The magnitude of the frequency correction of the clock, in ppm, above which its oscillator
+	//     is flagged as drifting in the time API, which may indicate failing hardware.
+	//     Defaults to `100`, and `0` disables the warning.
+	//   examples:
+	//     - "driftWarningThreshold: 50"
+	TimeDriftWarningThreshold *float64 `yaml:"driftWarningThreshold,omitempty"`
+	//   description: |
+	//     The device of a PTP hardware clock to read the time from, alongside the time servers.
+	//     The time of the PHC is used while it agrees with the servers, and the time of the servers
+	//     otherwise, or when the device is absent or unreadable.
+	//     The PHC is expected to run in TAI, and its time is converted to UTC.
+	//   examples:
+	//     - "phc: /dev/ptp0"
+	TimePHC string `yaml:"phc,omitempty"`
+	//   description: |
+	//     An HTTPS endpoint to cross-check the time of the servers against, with the `Date` header of
+	//     its responses, as a defense against compromised time servers.
+	//     The clock is not adjusted while the time of the servers disagrees with the time of the
+	//     endpoint by more than the sanity check threshold.
+	//   examples:
+	//     - "sanityCheckURL: https://www.google.com"
+	TimeSanityCheckURL string `yaml:"sanityCheckURL,omitempty"`
+	//   description: |
+	//     Whether the clock is not adjusted either while the sanity check endpoint is unreachable.
+	//     Defaults to `false`, the time of the servers being trusted when the endpoint can't be
+	//     reached.
+	TimeSanityCheckRequired bool `yaml:"sanityCheckRequired,omitempty"`
+	//   description: |
+	//     The offset between the time of the servers and the time of the sanity check endpoint
+	//     above which the clock is not adjusted.
+	//     Defaults to `1m`, and must be at least `1s`, the resolution of the `Date` header.
+	//     Field format accepts any Go time.Duration format ('30s', '1m').
+	TimeSanityCheckThreshold time.Duration `yaml:"sanityCheckThreshold,omitempty"`
+	//   description: |
+	//     Whether the boot waits for the initial time sync to complete before it starts the
+	//     services, since Kubernetes components and etcd are sensitive to clock skew.
+	//     Defaults to `false`.
+	TimeWaitForSync bool `yaml:"waitForSync,omitempty"`
+	//   description: |
+	//     The maximum time the boot waits for the initial time sync, once elapsed the boot fails.
+	//     Defaults to `5m`.
+	//     Field format accepts any Go time.Duration format ('30s', '5m').
+	TimeWaitForSyncTimeout time.Duration `yaml:"waitForSyncTimeout,omitempty"`
+}
+
+// WaitForConfig represents the endpoints that must be reachable before services are started.
+type WaitForConfig struct {
+	//   description: |
+	//     The endpoints to wait for.
+	WaitForEndpoints []string `yaml:"endpoints,omitempty"`
+	//   description: |
+	//     The maximum time to wait for all of the endpoints to become reachable (default is 5 minutes).
+	//     Field format accepts any Go time.Duration format ('1h' for one hour, '10m' for ten minutes).
+	WaitForTimeout time.Duration `yaml:"timeout,omitempty"`
+	//   description: |
+	//     The action to take when the endpoints are not reachable in time.
+	//   values:
+	//     - "`fail`"
+	//     - "`maintenance`"
+	WaitForOnTimeout string `yaml:"onTimeout,omitempty"`
+}
+
+// HealthCheckConfig represents the services to wait for at the end of the boot
+// sequence.
+type HealthCheckConfig struct {
+	//   description: |
+	//     Completes the boot sequence without waiting for the services.
+	//     The config is then not saved as the last known good one.
+	HealthCheckSkip bool `yaml:"skip,omitempty"`
+	//   description: |
+	//     The IDs of the services to wait for.
+	//     Defaults to `kubelet`, and `etcd` on control plane nodes.
+	HealthCheckServices []string `yaml:"services,omitempty"`
+	//   description: |
+	//     The maximum time to wait for the services to become healthy (default is 5 minutes).
+	//     Field format accepts any Go time.Duration format ('1h' for one hour, '10m' for ten minutes).
+	HealthCheckTimeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// SequencesConfig represents the options for the execution of each sequence.
+type SequencesConfig map[string]*SequenceConfig
+
+// SequenceConfig represents the options for the execution of a sequence.
+type SequenceConfig struct {
+	//   description: |
+	//     The maximum number of tasks of a phase that are run concurrently.
+	//     Defaults to no limit.
+	SequenceMaxParallelTasks int `yaml:"maxParallelTasks,omitempty"`
+	//   description: |
+	//     The maximum total duration of the sequence.
+	//     Once it elapses, the running tasks are canceled, and the sequence fails once they return.
+	//     A sequence past its point of no return (e.g. an install writing to the disk) is not aborted.
+	//     Only the sequences which can be aborted accept it, i.e. not the initialize, boot, shutdown, and reboot sequences.
+	//     Defaults to no limit.
+	//     Field format accepts any Go time.Duration format ('30s', '1m').
+	SequenceTimeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// ShutdownConfig represents the timeouts of the stages of a shutdown or reboot.
+type ShutdownConfig struct {
+	//   description: |
+	//     The time services are given to stop.
+	//     Defaults to `30s`.
+	//     Field format accepts any Go time.Duration format ('30s', '1m').
+	ShutdownGracePeriod time.Duration `yaml:"gracePeriod,omitempty"`
+	//   description: |
+	//     Disables cordoning and draining the node before a shutdown or reboot.
+	//     Otherwise, the node is uncordoned once the machine boots again.
+	//     Set this for machines that do not run a Kubernetes node.
+	ShutdownSkipDrain bool `yaml:"skipDrain,omitempty"`
+	//   description: |
+	//     The time allowed to cordon and drain the node.
+	//     Once elapsed, the remaining stages proceed without waiting for the drain to complete.
+	//     Defaults to `5m`.
+	ShutdownDrainTimeout time.Duration `yaml:"drainTimeout,omitempty"`
+	//   description: |
+	//     The time allowed to flush filesystem buffers before the machine is powered off or rebooted.
+	//     Defaults to `30s`.
+	ShutdownSyncTimeout time.Duration `yaml:"syncTimeout,omitempty"`
+	//   description: |
+	//     The time allowed for each unmount task, and for each unmount stage of a shutdown or reboot.
+	//     Defaults to `1m`.
+	ShutdownUnmountTimeout time.Duration `yaml:"unmountTimeout,omitempty"`
+	//   description: |
+	//     Reboots into the kernel the machine boots by default with kexec, skipping the firmware.
+	//     The services are stopped and the filesystems unmounted as for a full reboot.
+	//     Falls back to a full reboot if the kernel can not be loaded, e.g. if the kernel does not support `kexec_file_load`.
+	//     Defaults to `false`.
+	ShutdownKexec bool `yaml:"kexec,omitempty"`
+}
+
+// OutcomesConfig represents the options for reporting sequence outcomes.
+type OutcomesConfig struct {
+	//   description: |
+	//     The URL that the outcome of each sequence is posted to as JSON.
+	//     The payload contains the `sequence`, the `result` (`success` or `failure`), the `duration` in seconds, and the `failedTask` and `error` of a failed sequence.
+	OutcomesWebhook string `yaml:"webhook,omitempty"`
+}
+
+// ACPIConfig represents the options for handling ACPI events.
+type ACPIConfig struct {
+	//   description: |
+	//     The action (`ignore`, `shutdown`, `reboot`, `maintenance` or `resume`) taken in response to each ACPI event, keyed by event name.
+	//     The `maintenance` and `resume` actions run the sequences of the same name, which stop the workloads and start them again.
+	//     A name matches the events that start with it, e.g. `button/lid` matches all lids, and `button/lid PNP0C0D:00` a single one.
+	//     By default `button/power` shuts the machine down, and all other events, including `button/sleep` and `button/lid`, are ignored.
+	//     The events are read from the ACPI netlink channel, and from the input devices of the ACPI buttons.
+	ACPIActions map[string]string `yaml:"actions,omitempty"`
+}
+
+// WatchdogConfig represents the options for petting the hardware watchdog.
+type WatchdogConfig struct {
+	//   description: |
+	//     The path of the watchdog device.
+	//     Defaults to `/dev/watchdog`.
+	WatchdogDevice string `yaml:"device,omitempty"`
+	//   description: |
+	//     The time without a keepalive after which the watchdog resets the machine.
+	//     The watchdog is pet at a third of it.
+	//     Defaults to `1m`.
+	//     Field format accepts any Go time.Duration format ('30s', '1m').
+	WatchdogTimeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// ExtensionConfig represents a command run at an extension point of the
+// sequences.
+type ExtensionConfig struct {
+	//   description: |
+	//     The name the extension is reported with.
+	//     It must be unique among the extensions of a point.
+	ExtensionName string `yaml:"name"`
+	//   description: |
+	//     The point of the sequences the command runs at.
+	//   values:
+	//     - after-network
+	//     - before-services
+	//     - after-services
+	//     - before-shutdown
+	ExtensionPoint string `yaml:"point"`
+	//   description: |
+	//     The absolute path of the command, followed by its arguments.
+	ExtensionCommand []string `yaml:"command"`
+	//   description: |
+	//     The environment variables of the command.
+	ExtensionEnv runtime.Env `yaml:"env,omitempty"`
+	//   description: |
+	//     The time after which the command is killed, and the sequence continues.
+	//     Defaults to no limit.
+	//     Field format accepts any Go time.Duration format ('30s', '5m').
+	ExtensionTimeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// MaintenanceConfig represents the services stopped in maintenance.
+type MaintenanceConfig struct {
+	//   description: |
+	//     The IDs of the services to stop, in order.
+	//     The pods are removed once the `kubelet` is stopped.
+	//     Defaults to `kubelet` and `cri`, so `etcd` keeps running on control plane nodes.
+	MaintenanceStopServices []string `yaml:"stopServices,omitempty"`
+	//   description: |
+	//     Disables draining the node before the services are stopped, and uncordoning it once they are started again.
+	MaintenanceSkipDrain bool `yaml:"skipDrain,omitempty"`
+}
+
+// TaskLogsConfig represents the options for the logs of the tasks.
+type TaskLogsConfig struct {
+	//   description: |
+	//     The maximum number of lines per second that each task writes to the kernel log.
+	//     The number of the lines dropped is logged once the rate allows it, and when the task returns.
+	//     Defaults to no limit.
+	TaskLogsRateLimit int `yaml:"rateLimit,omitempty"`
+	//   description: |
+	//     Writes the logs of the tasks to stderr too, e.g. to a serial console.
+	TaskLogsStderr bool `yaml:"stderr,omitempty"`
 }
 
 // RegistriesConfig represents the image pull options.
@@ -10,12 +10,18 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 
 	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+	"github.com/talos-systems/talos/internal/pkg/imagesig"
 	"github.com/talos-systems/talos/pkg/constants"
 )
 
@@ -48,6 +54,62 @@ var (
 	ErrBadAddressing = errors.New("invalid network device addressing method")
 	// ErrInvalidAddress denotes that a bad address was provided
 	ErrInvalidAddress = errors.New("invalid network address")
+
+	// Boot
+
+	// ErrInvalidWaitForAction denotes that the action taken when endpoints
+	// are unreachable is invalid
+	ErrInvalidWaitForAction = errors.New("invalid wait for action")
+	// ErrInvalidMaxParallelTasks denotes that the maximum number of parallel
+	// tasks of a sequence is invalid
+	ErrInvalidMaxParallelTasks = errors.New("max parallel tasks must be positive")
+	// ErrInvalidSequenceTimeout denotes that the maximum total duration of a
+	// sequence is invalid
+	ErrInvalidSequenceTimeout = errors.New("sequence timeout must not be negative")
+	// ErrUnabortableSequenceTimeout denotes that a sequence which can not be
+	// aborted has a maximum total duration
+	ErrUnabortableSequenceTimeout = errors.New("sequence timeout is only allowed for the sequences which can be aborted")
+	// ErrInvalidTaskTimeout denotes that the timeout of a task is invalid
+	ErrInvalidTaskTimeout = errors.New("task timeout must be positive")
+	// ErrInvalidShutdownTimeout denotes that the timeout of a shutdown stage
+	// is invalid
+	ErrInvalidShutdownTimeout = errors.New("shutdown timeout must not be negative")
+	// ErrInvalidOutcomeWebhook denotes that the URL that sequence outcomes are
+	// reported to is invalid
+	ErrInvalidOutcomeWebhook = errors.New("outcome webhook must be an http or https URL")
+	// ErrInvalidACPIAction denotes that the action taken in response to an
+	// ACPI event is invalid
+	ErrInvalidACPIAction = errors.New("invalid ACPI action")
+	// ErrInvalidWatchdogTimeout denotes that the timeout of the hardware
+	// watchdog is invalid
+	ErrInvalidWatchdogTimeout = errors.New("watchdog timeout must be at least a second")
+	// ErrPreservedService denotes that a service which keeps running in
+	// maintenance is configured to be stopped
+	ErrPreservedService = errors.New("service keeps running in maintenance")
+	// ErrInvalidExtensionName denotes that the name of an extension is empty,
+	// or not unique among the extensions of its point
+	ErrInvalidExtensionName = errors.New("extension name must be set, and unique at its point")
+	// ErrInvalidExtensionCommand denotes that the command of an extension is
+	// not an absolute path
+	ErrInvalidExtensionCommand = errors.New("extension command must be an absolute path")
+	// ErrInvalidExtensionTimeout denotes that the timeout of an extension is
+	// invalid
+	ErrInvalidExtensionTimeout = errors.New("extension timeout must not be negative")
+	// ErrInvalidTaskLogsRateLimit denotes that the maximum number of lines
+	// per second of the logs of a task is invalid
+	ErrInvalidTaskLogsRateLimit = errors.New("task logs rate limit must not be negative")
+
+	// Install
+
+	// ErrInvalidInstallExistingAction denotes that the action taken when an
+	// existing installation is found is invalid
+	ErrInvalidInstallExistingAction = errors.New("invalid install existing action")
+	// ErrInvalidUSBDelay denotes that the time to wait for USB storage is
+	// invalid
+	ErrInvalidUSBDelay = errors.New("USB delay must not be negative")
+	// ErrConflictingUSBDelay denotes that a time to wait for USB storage is
+	// set, while the wait is disabled
+	ErrConflictingUSBDelay = errors.New("USB delay is set, but waiting for USB storage is disabled")
 )
 
 // NetworkDeviceCheck defines the function type for checks.
@@ -67,21 +129,45 @@ func (c *Config) Validate(mode runtime.Mode) error {
 		result = multierror.Append(result, errors.New("cluster instructions are required"))
 	}
 
-	if c.Cluster().Endpoint() == nil || c.Cluster().Endpoint().String() == "" {
+	// The remaining checks go through the accessors of both sections.
+	if result != nil {
+		return result.ErrorOrNil()
+	}
+
+	if c.ClusterConfig.ControlPlane == nil || c.ClusterConfig.ControlPlane.Endpoint == nil || c.Cluster().Endpoint() == nil || c.Cluster().Endpoint().String() == "" {
 		result = multierror.Append(result, errors.New("a cluster endpoint is required"))
 	}
 
 	if mode == runtime.ModeMetal {
-		if c.MachineConfig.MachineInstall == nil {
+		switch {
+		case c.MachineConfig.MachineInstall == nil:
 			result = multierror.Append(result, fmt.Errorf("install instructions are required in %q mode", runtime.ModeMetal.String()))
+		case c.MachineConfig.MachineInstall.InstallDisk == "":
+			result = multierror.Append(result, fmt.Errorf("an install disk is required in %q mode", runtime.ModeMetal.String()))
+		default:
+			if _, err := os.Stat(c.MachineConfig.MachineInstall.InstallDisk); os.IsNotExist(err) {
+				result = multierror.Append(result, fmt.Errorf("specified install disk does not exist: %q", c.MachineConfig.MachineInstall.InstallDisk))
+			}
 		}
+	}
 
-		if c.MachineConfig.MachineInstall.InstallDisk == "" {
-			result = multierror.Append(result, fmt.Errorf("an install disk is required in %q mode", runtime.ModeMetal.String()))
+	if c.MachineConfig.MachineInstall != nil {
+		switch c.MachineConfig.MachineInstall.Existing() {
+		case runtime.InstallExistingActionAbort, runtime.InstallExistingActionSkip, runtime.InstallExistingActionForce:
+		default:
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", "machine.install.existing", c.MachineConfig.MachineInstall.Existing(), ErrInvalidInstallExistingAction))
+		}
+
+		if c.MachineConfig.MachineInstall.USBDelay() < 0 {
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", "machine.install.usbDelay", c.MachineConfig.MachineInstall.USBDelay(), ErrInvalidUSBDelay))
 		}
 
-		if _, err := os.Stat(c.MachineConfig.MachineInstall.InstallDisk); os.IsNotExist(err) {
-			result = multierror.Append(result, fmt.Errorf("specified install disk does not exist: %q", c.MachineConfig.MachineInstall.InstallDisk))
+		if !c.MachineConfig.MachineInstall.WaitForUSB() && c.MachineConfig.MachineInstall.InstallUSBDelay != 0 {
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", "machine.install.usbDelay", c.MachineConfig.MachineInstall.InstallUSBDelay, ErrConflictingUSBDelay))
+		}
+
+		if _, err := imagesig.ParsePublicKeys(c.MachineConfig.MachineInstall.ImageVerificationKeys()...); err != nil {
+			result = multierror.Append(result, fmt.Errorf("[%s] %w", "machine.install.imageVerificationKeys", err))
 		}
 	}
 
@@ -98,12 +184,180 @@ func (c *Config) Validate(mode runtime.Mode) error {
 		}
 	}
 
-	for _, device := range c.MachineConfig.MachineNetwork.NetworkInterfaces {
-		if err := ValidateNetworkDevices(device, CheckDeviceInterface, CheckDeviceAddressing); err != nil {
-			result = multierror.Append(result, err)
+	if c.Machine().Time().Tolerance() < 0 {
+		result = multierror.Append(result, fmt.Errorf("time sync tolerance must not be negative: %q", c.Machine().Time().Tolerance()))
+	}
+
+	if c.Machine().Time().MinPoll() > c.Machine().Time().MaxPoll() {
+		result = multierror.Append(result, fmt.Errorf("time min poll interval %q must not be greater than max poll interval %q", c.Machine().Time().MinPoll(), c.Machine().Time().MaxPoll()))
+	}
+
+	if c.Machine().Time().MinPoll() < constants.MinTimePoll {
+		result = multierror.Append(result, fmt.Errorf("time min poll interval must not be less than %s: %q", constants.MinTimePoll, c.Machine().Time().MinPoll()))
+	}
+
+	if c.Machine().Time().MaxPoll() > constants.MaxTimePoll {
+		result = multierror.Append(result, fmt.Errorf("time max poll interval must not be greater than %s: %q", constants.MaxTimePoll, c.Machine().Time().MaxPoll()))
+	}
+
+	// The poll intervals are passed to timed in whole seconds.
+	if poll := c.Machine().Time().MinPoll(); poll%time.Second != 0 {
+		result = multierror.Append(result, fmt.Errorf("time min poll interval must be a whole number of seconds: %q", poll))
+	}
+
+	if poll := c.Machine().Time().MaxPoll(); poll%time.Second != 0 {
+		result = multierror.Append(result, fmt.Errorf("time max poll interval must be a whole number of seconds: %q", poll))
+	}
+
+	if c.Machine().Time().WaitForSyncTimeout() < 0 {
+		result = multierror.Append(result, fmt.Errorf("time sync wait timeout must not be negative: %q", c.Machine().Time().WaitForSyncTimeout()))
+	}
+
+	if drift := filepath.Clean(c.Machine().Time().DriftFile()); !strings.HasPrefix(drift, constants.TimeDriftPath+string(filepath.Separator)) {
+		result = multierror.Append(result, fmt.Errorf("time drift file must be in %s: %q", constants.TimeDriftPath, c.Machine().Time().DriftFile()))
+	}
+
+	if threshold := c.Machine().Time().DriftWarningThreshold(); threshold < 0 || threshold > 500 {
+		result = multierror.Append(result, fmt.Errorf("time drift warning threshold must be between 0 and 500 ppm: %g", threshold))
+	}
+
+	if phc := c.Machine().Time().PHC(); phc != "" && !filepath.IsAbs(phc) {
+		result = multierror.Append(result, fmt.Errorf("time PHC device must be an absolute path: %q", phc))
+	}
+
+	if u := c.Machine().Time().SanityCheckURL(); u != "" {
+		if parsed, err := url.Parse(u); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			result = multierror.Append(result, fmt.Errorf("time sanity check URL must be an HTTPS URL: %q", u))
+		}
+
+		if c.Machine().Time().SanityCheckThreshold() < time.Second {
+			result = multierror.Append(result, fmt.Errorf("time sanity check threshold must be at least 1s: %q", c.Machine().Time().SanityCheckThreshold()))
+		}
+	}
+
+	if c.MachineConfig.MachineNetwork != nil {
+		for _, device := range c.MachineConfig.MachineNetwork.NetworkInterfaces {
+			if err := ValidateNetworkDevices(device, CheckDeviceInterface, CheckDeviceAddressing); err != nil {
+				result = multierror.Append(result, err)
+			}
+		}
+	}
+
+	switch c.Machine().WaitFor().OnTimeout() {
+	case runtime.WaitForActionFail, runtime.WaitForActionMaintenance:
+	default:
+		result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", "machine.waitFor.onTimeout", c.Machine().WaitFor().OnTimeout(), ErrInvalidWaitForAction))
+	}
+
+	for name, seq := range c.MachineConfig.MachineSequences {
+		sequence, err := runtime.ParseSequence(name)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", "machine.sequences", name, err))
+		}
+
+		if seq != nil && seq.SequenceMaxParallelTasks < 0 {
+			result = multierror.Append(result, fmt.Errorf("[%s] %d: %w", "machine.sequences."+name+".maxParallelTasks", seq.SequenceMaxParallelTasks, ErrInvalidMaxParallelTasks))
+		}
+
+		if seq != nil && seq.SequenceTimeout < 0 {
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", "machine.sequences."+name+".timeout", seq.SequenceTimeout, ErrInvalidSequenceTimeout))
+		}
+
+		if seq != nil && seq.SequenceTimeout > 0 && err == nil && !sequence.Abortable() {
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", "machine.sequences."+name+".timeout", seq.SequenceTimeout, ErrUnabortableSequenceTimeout))
+		}
+	}
+
+	for name, timeout := range c.MachineConfig.MachineTaskTimeouts {
+		if timeout <= 0 {
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", "machine.taskTimeouts."+name, timeout, ErrInvalidTaskTimeout))
 		}
 	}
 
+	if s := c.MachineConfig.MachineShutdown; s != nil {
+		for _, timeout := range []struct {
+			path  string
+			value time.Duration
+		}{
+			{"machine.shutdown.gracePeriod", s.ShutdownGracePeriod},
+			{"machine.shutdown.drainTimeout", s.ShutdownDrainTimeout},
+			{"machine.shutdown.syncTimeout", s.ShutdownSyncTimeout},
+			{"machine.shutdown.unmountTimeout", s.ShutdownUnmountTimeout},
+		} {
+			if timeout.value < 0 {
+				result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", timeout.path, timeout.value, ErrInvalidShutdownTimeout))
+			}
+		}
+	}
+
+	if webhook := c.Machine().Outcomes().Webhook(); webhook != "" {
+		if u, err := url.Parse(webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", "machine.outcomes.webhook", webhook, ErrInvalidOutcomeWebhook))
+		}
+	}
+
+	actions := c.Machine().ACPI().Actions()
+	events := make([]string, 0, len(actions))
+
+	for event := range actions {
+		events = append(events, event)
+	}
+
+	// Sort the events so that the errors are reported in a stable order.
+	sort.Strings(events)
+
+	for _, event := range events {
+		switch action := actions[event]; action {
+		case runtime.ACPIActionIgnore, runtime.ACPIActionShutdown, runtime.ACPIActionReboot, runtime.ACPIActionMaintenance, runtime.ACPIActionResume:
+		default:
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", "machine.acpi.actions."+event, action, ErrInvalidACPIAction))
+		}
+	}
+
+	if w := c.MachineConfig.MachineWatchdog; w != nil && w.WatchdogTimeout != 0 && w.WatchdogTimeout < time.Second {
+		result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", "machine.watchdog.timeout", w.WatchdogTimeout, ErrInvalidWatchdogTimeout))
+	}
+
+	if m := c.MachineConfig.MachineMaintenance; m != nil {
+		for _, id := range m.MaintenanceStopServices {
+			if runtime.MaintenancePreserved(id) {
+				result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", "machine.maintenance.stopServices", id, ErrPreservedService))
+			}
+		}
+	}
+
+	names := map[string]bool{}
+
+	for i, ext := range c.MachineConfig.MachineExtensions {
+		path := fmt.Sprintf("machine.extensions[%d]", i)
+
+		if _, err := runtime.ParseExtensionPoint(ext.ExtensionPoint); err != nil {
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", path+".point", ext.ExtensionPoint, err))
+		}
+
+		if key := ext.ExtensionPoint + "/" + ext.ExtensionName; ext.ExtensionName == "" || names[key] {
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", path+".name", ext.ExtensionName, ErrInvalidExtensionName))
+		} else {
+			names[key] = true
+		}
+
+		if len(ext.ExtensionCommand) == 0 || !filepath.IsAbs(ext.ExtensionCommand[0]) {
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", path+".command", ext.ExtensionCommand, ErrInvalidExtensionCommand))
+		}
+
+		if ext.ExtensionTimeout < 0 {
+			result = multierror.Append(result, fmt.Errorf("[%s] %q: %w", path+".timeout", ext.ExtensionTimeout, ErrInvalidExtensionTimeout))
+		}
+	}
+
+	if l := c.MachineConfig.MachineTaskLogs; l != nil && l.TaskLogsRateLimit < 0 {
+		result = multierror.Append(result, fmt.Errorf("[%s] %d: %w", "machine.taskLogs.rateLimit", l.TaskLogsRateLimit, ErrInvalidTaskLogsRateLimit))
+	}
+
+	if err := runtime.RunConfigValidators(c, mode); err != nil {
+		result = multierror.Append(result, err)
+	}
+
 	return result.ErrorOrNil()
 }
 
@@ -0,0 +1,644 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// nolint: scopelint
+package v1alpha1
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/talos-systems/talos/internal/app/machined/pkg/runtime"
+)
+
+func TestConfig_Validate_RegisteredValidators(t *testing.T) {
+	if err := runtime.RegisterConfigValidator("nvme", runtime.InstallDiskPrefixValidator("/dev/nvme")); err != nil {
+		t.Fatal(err)
+	}
+
+	defer runtime.UnregisterConfigValidator("nvme")
+
+	endpoint, err := url.Parse("https://localhost:6443")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		disk    string
+		wantErr bool
+	}{
+		{
+			name:    "nvme",
+			disk:    "/dev/nvme0n1",
+			wantErr: false,
+		},
+		{
+			name:    "not nvme",
+			disk:    "/dev/sda",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineInstall: &InstallConfig{
+						InstallDisk: tt.disk,
+					},
+					MachineNetwork: &NetworkConfig{},
+				},
+				ClusterConfig: &ClusterConfig{
+					ControlPlane: &ControlPlaneConfig{
+						Endpoint: &Endpoint{endpoint},
+					},
+				},
+			}
+
+			if err := c.Validate(runtime.ModeCloud); (err != nil) != tt.wantErr {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_InstallExisting(t *testing.T) {
+	endpoint, err := url.Parse("https://localhost:6443")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		existing string
+		wantErr  bool
+	}{
+		{
+			name:     "default",
+			existing: "",
+			wantErr:  false,
+		},
+		{
+			name:     "abort",
+			existing: "abort",
+			wantErr:  false,
+		},
+		{
+			name:     "force",
+			existing: "force",
+			wantErr:  false,
+		},
+		{
+			name:     "invalid",
+			existing: "wipe",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineInstall: &InstallConfig{
+						InstallDisk:     "/dev/sda",
+						InstallExisting: tt.existing,
+					},
+					MachineNetwork: &NetworkConfig{},
+				},
+				ClusterConfig: &ClusterConfig{
+					ControlPlane: &ControlPlaneConfig{
+						Endpoint: &Endpoint{endpoint},
+					},
+				},
+			}
+
+			if err := c.Validate(runtime.ModeCloud); (err != nil) != tt.wantErr {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_ImageVerificationKeys(t *testing.T) {
+	endpoint, err := url.Parse("https://localhost:6443")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "-----BEGIN PUBLIC KEY-----\nMCowBQYDK2VwAyEAcdzeScy3TxgyCTr0PIDQS69QPXRpaqTX2w3iWvYgAmc=\n-----END PUBLIC KEY-----\n"
+
+	tests := []struct {
+		name    string
+		keys    []string
+		wantErr bool
+	}{
+		{
+			name:    "default",
+			keys:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "valid",
+			keys:    []string{key},
+			wantErr: false,
+		},
+		{
+			name:    "invalid",
+			keys:    []string{key, "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHHc3knMt08YMgk69DyA0EuvUD10aWqk19sN4lr2IAJn"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineInstall: &InstallConfig{
+						InstallDisk:                  "/dev/sda",
+						InstallImageVerificationKeys: tt.keys,
+					},
+					MachineNetwork: &NetworkConfig{},
+				},
+				ClusterConfig: &ClusterConfig{
+					ControlPlane: &ControlPlaneConfig{
+						Endpoint: &Endpoint{endpoint},
+					},
+				},
+			}
+
+			if err := c.Validate(runtime.ModeCloud); (err != nil) != tt.wantErr {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_ACPIActions(t *testing.T) {
+	endpoint, err := url.Parse("https://localhost:6443")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		actions map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "default",
+			actions: nil,
+			wantErr: false,
+		},
+		{
+			name:    "valid",
+			actions: map[string]string{"button/lid": "shutdown", "button/sleep": "reboot", "button/power": "ignore"},
+			wantErr: false,
+		},
+		{
+			name:    "sequences",
+			actions: map[string]string{"thermal_zone LNXTHERM:00 00000081": "maintenance", "thermal_zone LNXTHERM:00 00000080": "resume"},
+			wantErr: false,
+		},
+		{
+			name:    "invalid",
+			actions: map[string]string{"button/lid": "suspend"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineInstall: &InstallConfig{
+						InstallDisk: "/dev/sda",
+					},
+					MachineNetwork: &NetworkConfig{},
+					MachineACPI: &ACPIConfig{
+						ACPIActions: tt.actions,
+					},
+				},
+				ClusterConfig: &ClusterConfig{
+					ControlPlane: &ControlPlaneConfig{
+						Endpoint: &Endpoint{endpoint},
+					},
+				},
+			}
+
+			if err := c.Validate(runtime.ModeCloud); (err != nil) != tt.wantErr {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_MaintenanceStopServices(t *testing.T) {
+	endpoint, err := url.Parse("https://localhost:6443")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		services []string
+		wantErr  bool
+	}{
+		{
+			name:     "default",
+			services: nil,
+			wantErr:  false,
+		},
+		{
+			name:     "valid",
+			services: []string{"kubelet", "cri", "etcd"},
+			wantErr:  false,
+		},
+		{
+			name:     "preserved",
+			services: []string{"kubelet", "apid"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineInstall: &InstallConfig{
+						InstallDisk: "/dev/sda",
+					},
+					MachineNetwork: &NetworkConfig{},
+					MachineMaintenance: &MaintenanceConfig{
+						MaintenanceStopServices: tt.services,
+					},
+				},
+				ClusterConfig: &ClusterConfig{
+					ControlPlane: &ControlPlaneConfig{
+						Endpoint: &Endpoint{endpoint},
+					},
+				},
+			}
+
+			err := c.Validate(runtime.ModeCloud)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && !strings.Contains(err.Error(), ErrPreservedService.Error()) {
+				t.Errorf("Config.Validate() error = %v, want %v", err, ErrPreservedService)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_Extensions(t *testing.T) {
+	endpoint, err := url.Parse("https://localhost:6443")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	firmware := func(point string, command ...string) *ExtensionConfig {
+		return &ExtensionConfig{ExtensionName: "firmware", ExtensionPoint: point, ExtensionCommand: command}
+	}
+
+	tests := []struct {
+		name       string
+		extensions []*ExtensionConfig
+		want       error
+	}{
+		{
+			name:       "valid",
+			extensions: []*ExtensionConfig{firmware("before-services", "/bin/update"), firmware("before-shutdown", "/bin/update")},
+		},
+		{
+			name:       "unknown point",
+			extensions: []*ExtensionConfig{firmware("after-kubelet", "/bin/update")},
+			want:       runtime.ErrInvalidExtension,
+		},
+		{
+			name:       "duplicate",
+			extensions: []*ExtensionConfig{firmware("before-services", "/bin/update"), firmware("before-services", "/bin/update")},
+			want:       ErrInvalidExtensionName,
+		},
+		{
+			name:       "relative command",
+			extensions: []*ExtensionConfig{firmware("before-services", "update")},
+			want:       ErrInvalidExtensionCommand,
+		},
+		{
+			name:       "no command",
+			extensions: []*ExtensionConfig{firmware("before-services")},
+			want:       ErrInvalidExtensionCommand,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineInstall: &InstallConfig{
+						InstallDisk: "/dev/sda",
+					},
+					MachineNetwork:    &NetworkConfig{},
+					MachineExtensions: tt.extensions,
+				},
+				ClusterConfig: &ClusterConfig{
+					ControlPlane: &ControlPlaneConfig{
+						Endpoint: &Endpoint{endpoint},
+					},
+				},
+			}
+
+			err := c.Validate(runtime.ModeCloud)
+			if (err != nil) != (tt.want != nil) {
+				t.Fatalf("Config.Validate() error = %v, want %v", err, tt.want)
+			}
+
+			if tt.want != nil && !strings.Contains(err.Error(), tt.want.Error()) {
+				t.Errorf("Config.Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_Sections(t *testing.T) {
+	endpoint, err := url.Parse("https://localhost:6443")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noWait := false
+
+	driftWarningThreshold, noDriftWarning := 600.0, 0.0
+
+	tests := []struct {
+		name    string
+		config  *Config
+		mode    runtime.Mode
+		wantErr string
+	}{
+		{
+			name:    "empty",
+			config:  &Config{},
+			mode:    runtime.ModeMetal,
+			wantErr: "machine instructions are required",
+		},
+		{
+			name: "no endpoint",
+			config: &Config{
+				MachineConfig: &MachineConfig{MachineType: "join"},
+				ClusterConfig: &ClusterConfig{},
+			},
+			mode:    runtime.ModeContainer,
+			wantErr: "a cluster endpoint is required",
+		},
+		{
+			name: "no install in metal mode",
+			config: &Config{
+				MachineConfig: &MachineConfig{MachineType: "join"},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeMetal,
+			wantErr: "install instructions are required",
+		},
+		{
+			name: "no install in container mode",
+			config: &Config{
+				MachineConfig: &MachineConfig{MachineType: "join"},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode: runtime.ModeContainer,
+		},
+		{
+			name: "USB delay without waiting",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineInstall: &InstallConfig{
+						InstallDisk:       "/dev/sda",
+						InstallWaitForUSB: &noWait,
+						InstallUSBDelay:   time.Second,
+					},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeCloud,
+			wantErr: ErrConflictingUSBDelay.Error(),
+		},
+		{
+			name: "zero task timeout",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType:         "join",
+					MachineTaskTimeouts: map[string]time.Duration{"Install": 0},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeContainer,
+			wantErr: ErrInvalidTaskTimeout.Error(),
+		},
+		{
+			name: "unknown task timeout",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType:         "join",
+					MachineTaskTimeouts: map[string]time.Duration{"NoSuchTask": time.Minute},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode: runtime.ModeContainer,
+		},
+		{
+			name: "negative task logs rate limit",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType:     "join",
+					MachineTaskLogs: &TaskLogsConfig{TaskLogsRateLimit: -1},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeContainer,
+			wantErr: ErrInvalidTaskLogsRateLimit.Error(),
+		},
+		{
+			name: "negative sequence timeout",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType:      "join",
+					MachineSequences: SequencesConfig{"upgrade": {SequenceTimeout: -time.Minute}},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeContainer,
+			wantErr: ErrInvalidSequenceTimeout.Error(),
+		},
+		{
+			name: "sequence timeout",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType:      "join",
+					MachineSequences: SequencesConfig{"upgrade": {SequenceTimeout: 30 * time.Minute}},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode: runtime.ModeContainer,
+		},
+		{
+			name: "unabortable sequence timeout",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType:      "join",
+					MachineSequences: SequencesConfig{"boot": {SequenceTimeout: 30 * time.Minute}},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeContainer,
+			wantErr: ErrUnabortableSequenceTimeout.Error(),
+		},
+		{
+			name: "max poll interval above the maximum",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineTime: &TimeConfig{TimeMaxPoll: time.Hour},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeContainer,
+			wantErr: "time max poll interval must not be greater than 17m4s",
+		},
+		{
+			name: "min poll interval below the minimum",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineTime: &TimeConfig{TimeMinPoll: time.Second},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeContainer,
+			wantErr: "time min poll interval must not be less than 4s",
+		},
+		{
+			name: "poll interval of a fraction of a second",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineTime: &TimeConfig{TimeMinPoll: 64500 * time.Millisecond},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeContainer,
+			wantErr: "time min poll interval must be a whole number of seconds",
+		},
+		{
+			name: "drift file outside of the state directory",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineTime: &TimeConfig{TimeDriftFile: "/var/lib/talos/../../../etc/ntp.drift"},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeContainer,
+			wantErr: "time drift file must be in /var/lib/talos",
+		},
+		{
+			name: "drift file below the state directory",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineTime: &TimeConfig{TimeDriftFile: "/var/lib/talos/timed/ntp.drift"},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode: runtime.ModeContainer,
+		},
+		{
+			name: "HTTP sanity check URL",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineTime: &TimeConfig{TimeSanityCheckURL: "http://example.com"},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeContainer,
+			wantErr: "time sanity check URL must be an HTTPS URL",
+		},
+		{
+			name: "sanity check threshold below the resolution of the Date header",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineTime: &TimeConfig{TimeSanityCheckURL: "https://example.com", TimeSanityCheckThreshold: 500 * time.Millisecond},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeContainer,
+			wantErr: "time sanity check threshold must be at least 1s",
+		},
+		{
+			name: "sanity check",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineTime: &TimeConfig{TimeSanityCheckURL: "https://example.com"},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode: runtime.ModeContainer,
+		},
+		{
+			name: "drift warning threshold above the largest frequency correction",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineTime: &TimeConfig{TimeDriftWarningThreshold: &driftWarningThreshold},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode:    runtime.ModeContainer,
+			wantErr: "time drift warning threshold must be between 0 and 500 ppm",
+		},
+		{
+			name: "drift warning disabled",
+			config: &Config{
+				MachineConfig: &MachineConfig{
+					MachineType: "join",
+					MachineTime: &TimeConfig{TimeDriftWarningThreshold: &noDriftWarning},
+				},
+				ClusterConfig: &ClusterConfig{ControlPlane: &ControlPlaneConfig{Endpoint: &Endpoint{endpoint}}},
+			},
+			mode: runtime.ModeContainer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate(tt.mode)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Config.Validate() error = %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Config.Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
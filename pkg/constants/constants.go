@@ -27,6 +27,31 @@ const (
 	// hostname.
 	KernelParamHostname = "talos.hostname"
 
+	// KernelParamStartup is the kernel parameter name for specifying the
+	// ordered list of sequences to run at startup.
+	KernelParamStartup = "talos.startup"
+
+	// KernelParamMaxParallelTasks is the kernel parameter name for limiting
+	// the number of tasks of a phase that run concurrently.
+	KernelParamMaxParallelTasks = "talos.maxparalleltasks"
+
+	// KernelParamSequenceHistory is the kernel parameter name for the number
+	// of results of the most recent sequences kept by machined.
+	KernelParamSequenceHistory = "talos.sequencehistory"
+
+	// KernelParamSIGTERMGracePeriod is the kernel parameter name for the delay
+	// between a SIGTERM and the shutdown it requests, e.g. `30s`.
+	KernelParamSIGTERMGracePeriod = "talos.sigtermgrace"
+
+	// KernelParamPreflight is the kernel parameter name for running the
+	// pre-flight checks of the sequences before them.
+	KernelParamPreflight = "talos.preflight"
+
+	// KernelParamRecoverConfig is the kernel parameter name for making the
+	// recover sequence restore the last known good config, even if the
+	// current one is valid.
+	KernelParamRecoverConfig = "talos.recover.config"
+
 	// KernelParamDefaultInterface is the kernel parameter for specifying the
 	// initial interface used to bootstrap the node
 	KernelParamDefaultInterface = "talos.interface"
@@ -52,6 +77,13 @@ const (
 	// the boot path.
 	BootMountPoint = "/boot"
 
+	// StagedUpgradePath is the path to the upgrade staged for the next boot.
+	StagedUpgradePath = BootMountPoint + "/staged-upgrade.json"
+
+	// InstalledMarkerPath is the path to the marker written once the install
+	// sequence completed.
+	InstalledMarkerPath = BootMountPoint + "/installed"
+
 	// EphemeralPartitionLabel is the label of the partition to use for
 	// mounting at the data path.
 	EphemeralPartitionLabel = "EPHEMERAL"
@@ -152,6 +184,13 @@ const (
 	// LabelNodeRoleMaster is the node label required by a control plane node.
 	LabelNodeRoleMaster = "node-role.kubernetes.io/master"
 
+	// AnnotationCordonedKey is the annotation set on a node that Talos
+	// cordoned, so that only the cordons it made are lifted.
+	AnnotationCordonedKey = "talos.dev/cordoned"
+
+	// AnnotationCordonedValue is the value of AnnotationCordonedKey.
+	AnnotationCordonedValue = "true"
+
 	// AssetsDirectory is the directory that contains all bootstrap assets.
 	AssetsDirectory = "/etc/kubernetes/assets"
 
@@ -176,6 +215,10 @@ const (
 	// ConfigPath is the path to the downloaded config.
 	ConfigPath = "/boot/config.yaml"
 
+	// LastKnownGoodConfigPath is the path to the config of the last healthy
+	// boot.
+	LastKnownGoodConfigPath = "/boot/config.last-known-good.yaml"
+
 	// MetalConfigISOLabel is the volume label for ISO based configuration.
 	MetalConfigISOLabel = "metal-iso"
 
@@ -200,6 +243,10 @@ const (
 	// TrustdPort is the port for the trustd service.
 	TrustdPort = 50001
 
+	// MachinedMetricsPort is the port machined serves its Prometheus metrics
+	// on, on the loopback interface only.
+	MachinedMetricsPort = 50003
+
 	// DefaultContainerdVersion is the default container runtime version
 	DefaultContainerdVersion = "1.3.3"
 
@@ -215,6 +262,11 @@ const (
 	// TalosConfigEnvVar is the environment variable for setting the Talos configuration file path.
 	TalosConfigEnvVar = "TALOSCONFIG"
 
+	// USBDelayEnvVar is the environment variable for overriding the time to
+	// wait for USB storage on boot, "0" skipping the wait. It can be set on the
+	// kernel command line.
+	USBDelayEnvVar = "TALOS_USB_DELAY"
+
 	// MachineSocketPath is the path to file socket of machine API.
 	MachineSocketPath = SystemRunPath + "/machined/machine.sock"
 
@@ -255,6 +307,10 @@ const (
 	// directories.
 	SystemVarPath = "/var/system"
 
+	// ClockFilePath is the path to the file the system time is saved to on
+	// shutdown, for machines without an RTC.
+	ClockFilePath = SystemVarPath + "/clock"
+
 	// SystemRunPath is the path to write temporary runtime system related files
 	// and directories.
 	SystemRunPath = "/run/system"
@@ -289,6 +345,91 @@ const (
 	// DefaultDNSDomain is the default DNS domain.
 	DefaultDNSDomain = "cluster.local"
 
+	// DefaultTimeSyncTolerance is the maximum clock offset at which the machine
+	// is considered to be in sync with the time server.
+	DefaultTimeSyncTolerance = time.Second
+
+	// DefaultTimeMinPoll is the default lower bound of the time server poll
+	// interval.
+	DefaultTimeMinPoll = 64 * time.Second
+
+	// DefaultTimeMaxPoll is the default upper bound of the time server poll
+	// interval.
+	DefaultTimeMaxPoll = 1024 * time.Second
+
+	// MaxTimePoll is the longest time server poll interval allowed, beyond
+	// which the clock would drift too far between the polls.
+	MaxTimePoll = 1024 * time.Second
+
+	// MinTimePoll is the shortest time server poll interval allowed, below
+	// which the servers may rate limit the queries.
+	MinTimePoll = 4 * time.Second
+
+	// DefaultTimeStepThreshold is the default clock offset below which the
+	// clock is slewed rather than stepped.
+	DefaultTimeStepThreshold = 128 * time.Millisecond
+
+	// DefaultTimeMaxStep is the default clock offset above which the clock is
+	// not adjusted, as the time of the server is not trusted.
+	DefaultTimeMaxStep = 15 * time.Minute
+
+	// DefaultTimeSanityCheckThreshold is the default offset between the time
+	// of the servers and the time of the sanity check endpoint above which the
+	// clock is not adjusted.
+	DefaultTimeSanityCheckThreshold = time.Minute
+
+	// DefaultTimeIBurst is the default number of queries sent to each time
+	// server on the initial sync.
+	DefaultTimeIBurst = 4
+
+	// DefaultTimeDriftFile is the default path of the file the frequency
+	// correction of the clock is persisted to.
+	DefaultTimeDriftFile = "/var/lib/talos/ntp.drift"
+
+	// TimeDriftPath is the directory the drift file must be in, since the
+	// directory of the file is mounted read-write into timed.
+	TimeDriftPath = "/var/lib/talos"
+
+	// DefaultTimeDriftWarningThreshold is the default magnitude of the
+	// frequency correction of the clock, in ppm, above which its oscillator is
+	// flagged as drifting.
+	DefaultTimeDriftWarningThreshold = 100
+
+	// DefaultTimeWaitForSyncTimeout is the default maximum time the boot
+	// waits for the initial time sync.
+	DefaultTimeWaitForSyncTimeout = 5 * time.Minute
+
+	// DefaultShutdownGracePeriod is the default time services are given to
+	// stop during a shutdown or reboot.
+	DefaultShutdownGracePeriod = 30 * time.Second
+
+	// DefaultShutdownDrainTimeout is the default time allowed to cordon and
+	// drain the node.
+	DefaultShutdownDrainTimeout = 5 * time.Minute
+
+	// DefaultShutdownSyncTimeout is the default time allowed to flush
+	// filesystem buffers before the machine is powered off or rebooted.
+	DefaultShutdownSyncTimeout = 30 * time.Second
+
+	// DefaultShutdownUnmountTimeout is the default time allowed for each
+	// unmount task of a shutdown or reboot.
+	DefaultShutdownUnmountTimeout = time.Minute
+
+	// DefaultWatchdogDevice is the default path of the hardware watchdog.
+	DefaultWatchdogDevice = "/dev/watchdog"
+
+	// DefaultWatchdogTimeout is the default time without a keepalive after
+	// which the hardware watchdog resets the machine.
+	DefaultWatchdogTimeout = time.Minute
+
+	// DefaultWaitForTimeout is the default time to wait for the endpoints
+	// required to start services.
+	DefaultWaitForTimeout = 5 * time.Minute
+
+	// DefaultHealthCheckTimeout is the default time the services are given to
+	// become healthy at the end of the boot sequence.
+	DefaultHealthCheckTimeout = 5 * time.Minute
+
 	// InitializedKey is the key used to indicate if the cluster has been
 	// initialized.
 	InitializedKey = "initialized"
@@ -77,7 +77,7 @@ func Download(endpoint string, opts ...Option) (b []byte, err error) {
 		req.Header.Set(k, v)
 	}
 
-	err = retry.Exponential(60*time.Second, retry.WithUnits(time.Second), retry.WithJitter(time.Second)).Retry(func() error {
+	err = retry.Exponential(60*time.Second, retry.WithUnits(time.Second), retry.WithJitter(time.Second), retry.WithCap(10*time.Second)).Retry(func() error {
 		b, err = download(req)
 		if err != nil {
 			return retry.ExpectedError(err)
@@ -206,9 +206,10 @@ func (h *Client) CordonAndDrain(node string) (err error) {
 	return h.Drain(node)
 }
 
-// Cordon marks a node as unschedulable.
+// Cordon marks a node as unschedulable. A node that is not already cordoned
+// is annotated as cordoned by Talos, so that Uncordon lifts the cordon.
 func (h *Client) Cordon(name string) error {
-	err := retry.Exponential(30*time.Second, retry.WithUnits(250*time.Millisecond), retry.WithJitter(50*time.Millisecond)).Retry(func() error {
+	err := retry.Exponential(30*time.Second, retry.WithUnits(250*time.Millisecond), retry.WithJitter(50*time.Millisecond), retry.WithCap(5*time.Second)).Retry(func() error {
 		node, err := h.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
 		if err != nil {
 			return retry.UnexpectedError(err)
@@ -220,6 +221,12 @@ func (h *Client) Cordon(name string) error {
 
 		node.Spec.Unschedulable = true
 
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+
+		node.Annotations[constants.AnnotationCordonedKey] = constants.AnnotationCordonedValue
+
 		if _, err := h.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{}); err != nil {
 			return retry.ExpectedError(err)
 		}
@@ -233,19 +240,24 @@ func (h *Client) Cordon(name string) error {
 	return nil
 }
 
-// Uncordon marks a node as schedulable.
+// Uncordon marks a node cordoned by Talos as schedulable. The cordons that
+// Talos did not make, e.g. those made with kubectl, are left in place.
 func (h *Client) Uncordon(name string) error {
-	err := retry.Exponential(30*time.Second, retry.WithUnits(250*time.Millisecond), retry.WithJitter(50*time.Millisecond)).Retry(func() error {
+	err := retry.Exponential(30*time.Second, retry.WithUnits(250*time.Millisecond), retry.WithJitter(50*time.Millisecond), retry.WithCap(5*time.Second)).Retry(func() error {
 		node, err := h.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
 		if err != nil {
 			return retry.UnexpectedError(err)
 		}
 
-		if node.Spec.Unschedulable {
-			node.Spec.Unschedulable = false
-			if _, err := h.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{}); err != nil {
-				return retry.ExpectedError(err)
-			}
+		if node.Annotations[constants.AnnotationCordonedKey] != constants.AnnotationCordonedValue {
+			return nil
+		}
+
+		node.Spec.Unschedulable = false
+		delete(node.Annotations, constants.AnnotationCordonedKey)
+
+		if _, err := h.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{}); err != nil {
+			return retry.ExpectedError(err)
 		}
 
 		return nil
@@ -259,30 +271,24 @@ func (h *Client) Uncordon(name string) error {
 
 // Drain evicts all pods on a given node.
 func (h *Client) Drain(node string) error {
-	opts := metav1.ListOptions{
-		FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": node}).String(),
-	}
-
-	pods, err := h.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), opts)
+	pods, err := h.PodsOnNode(node)
 	if err != nil {
-		return fmt.Errorf("cannot get pods for node %s: %w", node, err)
+		return err
 	}
 
 	var wg sync.WaitGroup
 
-	wg.Add(len(pods.Items))
+	wg.Add(len(pods))
 
 	// Evict each pod.
 
-	for _, pod := range pods.Items {
+	for _, pod := range pods {
 		go func(p corev1.Pod) {
 			defer wg.Done()
 
-			for _, ref := range p.ObjectMeta.OwnerReferences {
-				if ref.Kind == "DaemonSet" {
-					log.Printf("skipping DaemonSet pod %s\n", p.GetName())
-					return
-				}
+			if isDaemonSetPod(p) {
+				log.Printf("skipping DaemonSet pod %s\n", p.GetName())
+				return
 			}
 
 			if err := h.evict(p, int64(60)); err != nil {
@@ -296,6 +302,49 @@ func (h *Client) Drain(node string) error {
 	return nil
 }
 
+// PodsOnNode returns the pods scheduled on a node.
+func (h *Client) PodsOnNode(node string) ([]corev1.Pod, error) {
+	opts := metav1.ListOptions{
+		FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": node}).String(),
+	}
+
+	pods, err := h.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get pods for node %s: %w", node, err)
+	}
+
+	return pods.Items, nil
+}
+
+// EvictablePodsOnNode returns the pods scheduled on a node that a drain
+// evicts, i.e. all of them except the ones that belong to a DaemonSet.
+func (h *Client) EvictablePodsOnNode(node string) ([]corev1.Pod, error) {
+	pods, err := h.PodsOnNode(node)
+	if err != nil {
+		return nil, err
+	}
+
+	evictable := make([]corev1.Pod, 0, len(pods))
+
+	for _, pod := range pods {
+		if !isDaemonSetPod(pod) {
+			evictable = append(evictable, pod)
+		}
+	}
+
+	return evictable, nil
+}
+
+func isDaemonSetPod(p corev1.Pod) bool {
+	for _, ref := range p.ObjectMeta.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (h *Client) evict(p corev1.Pod, gracePeriod int64) error {
 	for {
 		pol := &policy.Eviction{
@@ -0,0 +1,52 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package retry
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff computes truncated exponential backoff intervals for retry loops
+// that manage their own timing. The interval doubles on each call to `Next`
+// until it reaches the cap, and a random jitter in [0, Jitter) is added to
+// every interval.
+type Backoff struct {
+	options *Options
+	rand    *rand.Rand
+
+	c float64
+}
+
+// NewBackoff initializes and returns a Backoff.
+func NewBackoff(setters ...Option) *Backoff {
+	return &Backoff{
+		options: NewDefaultOptions(setters...),
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		c:       1.0,
+	}
+}
+
+// Next returns the next interval to wait for.
+func (b *Backoff) Next() time.Duration {
+	d := truncate(exponential(b.c, b.options.Units), b.options.Cap)
+
+	// Stop growing once the cap has been reached.
+	if b.options.Cap == 0 || d < b.options.Cap {
+		b.c++
+	}
+
+	if b.options.Jitter > 0 {
+		d += time.Duration(b.rand.Int63n(int64(b.options.Jitter)))
+	}
+
+	return d
+}
+
+// Reset restarts the backoff from the initial interval. It should be called
+// after a successful attempt.
+func (b *Backoff) Reset() {
+	b.c = 1.0
+}
@@ -0,0 +1,114 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package retry
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// nolint: scopelint
+func TestBackoff_Next(t *testing.T) {
+	tests := []struct {
+		name    string
+		setters []Option
+		want    []time.Duration
+	}{
+		{
+			name:    "uncapped",
+			setters: []Option{WithUnits(time.Second)},
+			want:    []time.Duration{0, time.Second, 3 * time.Second, 7 * time.Second, 15 * time.Second, 31 * time.Second},
+		},
+		{
+			name:    "capped",
+			setters: []Option{WithUnits(time.Second), WithCap(5 * time.Second)},
+			want:    []time.Duration{0, time.Second, 3 * time.Second, 5 * time.Second, 5 * time.Second, 5 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBackoff(tt.setters...)
+
+			got := make([]time.Duration, 0, len(tt.want))
+
+			for range tt.want {
+				got = append(got, b.Next())
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Backoff.Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff_Jitter(t *testing.T) {
+	jitter := 500 * time.Millisecond
+	max := 2 * time.Second
+
+	b := NewBackoff(WithUnits(time.Second), WithCap(max), WithJitter(jitter))
+
+	// Skip the intervals below the cap.
+	for i := 0; i < 3; i++ {
+		b.Next()
+	}
+
+	for i := 0; i < 1000; i++ {
+		d := b.Next()
+		if d < max || d >= max+jitter {
+			t.Fatalf("Backoff.Next() = %v, want in [%v, %v)", d, max, max+jitter)
+		}
+	}
+}
+
+func TestBackoff_Reset(t *testing.T) {
+	b := NewBackoff(WithUnits(time.Second), WithCap(time.Minute))
+
+	for i := 0; i < 10; i++ {
+		b.Next()
+	}
+
+	b.Reset()
+
+	if got := b.Next(); got != 0 {
+		t.Errorf("Backoff.Next() after Reset() = %v, want %v", got, 0)
+	}
+
+	if got := b.Next(); got != time.Second {
+		t.Errorf("Backoff.Next() after Reset() = %v, want %v", got, time.Second)
+	}
+}
+
+func TestBackoff_Overflow(t *testing.T) {
+	b := NewBackoff(WithUnits(time.Hour), WithCap(time.Minute))
+
+	for i := 0; i < 1000; i++ {
+		if d := b.Next(); d < 0 || d > time.Minute {
+			t.Fatalf("Backoff.Next() = %v, want in [0, %v]", d, time.Minute)
+		}
+	}
+}
+
+func TestExponentialTicker_Cap(t *testing.T) {
+	tick := NewExponentialTicker(NewDefaultOptions(WithUnits(time.Second), WithCap(5*time.Second)))
+
+	for i := 0; i < 100; i++ {
+		if d := tick.Tick(); d < 0 || d > 5*time.Second {
+			t.Fatalf("ExponentialTicker.Tick() = %v, want in [0, %v]", d, 5*time.Second)
+		}
+	}
+}
+
+func TestLinearTicker_Cap(t *testing.T) {
+	tick := NewLinearTicker(NewDefaultOptions(WithUnits(time.Second), WithCap(5*time.Second)))
+
+	for i := 0; i < 100; i++ {
+		if d := tick.Tick(); d < 0 || d > 5*time.Second {
+			t.Fatalf("LinearTicker.Tick() = %v, want in [0, %v]", d, 5*time.Second)
+		}
+	}
+}
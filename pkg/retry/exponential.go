@@ -59,8 +59,19 @@ func (e exponentialRetryer) Retry(f RetryableFunc) error {
 
 // Tick implements the Ticker interface.
 func (e *ExponentialTicker) Tick() time.Duration {
-	d := time.Duration((math.Pow(2, e.c)-1)/2)*e.options.Units + e.Jitter()
+	d := e.truncate(exponential(e.c, e.options.Units)) + e.Jitter()
 	e.c++
 
 	return d
 }
+
+func exponential(c float64, units time.Duration) time.Duration {
+	n := math.Trunc((math.Pow(2, c) - 1) / 2)
+
+	// Guard against overflow on long running retries.
+	if n*float64(units) >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration(n) * units
+}
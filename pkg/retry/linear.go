@@ -56,7 +56,7 @@ func (l linearRetryer) Retry(f RetryableFunc) error {
 
 // Tick implements the Ticker interface.
 func (l *LinearTicker) Tick() time.Duration {
-	d := time.Duration(l.c)*l.options.Units + l.Jitter()
+	d := l.truncate(time.Duration(l.c)*l.options.Units) + l.Jitter()
 	l.c++
 
 	return d
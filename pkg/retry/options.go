@@ -10,6 +10,7 @@ import "time"
 type Options struct {
 	Units  time.Duration
 	Jitter time.Duration
+	Cap    time.Duration
 }
 
 // Option is the functional option func.
@@ -29,6 +30,14 @@ func WithJitter(o time.Duration) Option {
 	}
 }
 
+// WithCap is a functional option for setting the maximum interval between
+// ticks, excluding jitter. A value of zero means no limit.
+func WithCap(o time.Duration) Option {
+	return func(args *Options) {
+		args.Cap = o
+	}
+}
+
 // NewDefaultOptions initializes a Options struct with default values.
 func NewDefaultOptions(setters ...Option) *Options {
 	opts := &Options{
@@ -113,6 +113,10 @@ func (t ticker) Jitter() time.Duration {
 	return time.Duration(t.rand.Int63n(int64(t.options.Jitter)))
 }
 
+func (t ticker) truncate(d time.Duration) time.Duration {
+	return truncate(d, t.options.Cap)
+}
+
 func (t ticker) StopChan() <-chan struct{} {
 	return t.s
 }
@@ -121,6 +125,14 @@ func (t ticker) Stop() {
 	t.s <- struct{}{}
 }
 
+func truncate(d, max time.Duration) time.Duration {
+	if max > 0 && d > max {
+		return max
+	}
+
+	return d
+}
+
 // ExpectedError error represents an error that is expected by the retrying
 // function. This error is ignored.
 func ExpectedError(err error) error {